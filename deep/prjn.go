@@ -68,8 +68,8 @@ func (pj *Prjn) SendDeepCtxtGe(si int, dburst float32) {
 	scdb := dburst * pj.GScale
 	nc := pj.SConN[si]
 	st := pj.SConIdxSt[si]
-	syns := pj.Syns[st : st+nc]
-	scons := pj.SConIdx[st : st+nc]
+	syns := pj.Syns.Range(st, nc)
+	scons := pj.SConIdx[st : st+int64(nc)]
 	for ci := range syns {
 		ri := scons[ci]
 		pj.DeepCtxtGeInc[ri] += scdb * syns[ci].Wt
@@ -82,8 +82,8 @@ func (pj *Prjn) SendTRCBurstGeDelta(si int, delta float32) {
 	scdel := delta * pj.GScale
 	nc := pj.SConN[si]
 	st := pj.SConIdxSt[si]
-	syns := pj.Syns[st : st+nc]
-	scons := pj.SConIdx[st : st+nc]
+	syns := pj.Syns.Range(st, nc)
+	scons := pj.SConIdx[st : st+int64(nc)]
 	for ci := range syns {
 		ri := scons[ci]
 		pj.TRCBurstGeInc[ri] += scdel * syns[ci].Wt
@@ -96,8 +96,8 @@ func (pj *Prjn) SendAttnGeDelta(si int, delta float32) {
 	scdel := delta * pj.GScale
 	nc := pj.SConN[si]
 	st := pj.SConIdxSt[si]
-	syns := pj.Syns[st : st+nc]
-	scons := pj.SConIdx[st : st+nc]
+	syns := pj.Syns.Range(st, nc)
+	scons := pj.SConIdx[st : st+int64(nc)]
 	for ci := range syns {
 		ri := scons[ci]
 		pj.AttnGeInc[ri] += scdel * syns[ci].Wt
@@ -156,10 +156,10 @@ func (pj *Prjn) DWtDeepCtxt() {
 	rlay := pj.Recv.(DeepLayer).AsDeep()
 	for si := range slay.Neurons {
 		dsn := &slay.DeepNeurs[si]
-		nc := int(pj.SConN[si])
-		st := int(pj.SConIdxSt[si])
-		syns := pj.Syns[st : st+nc]
-		scons := pj.SConIdx[st : st+nc]
+		nc := pj.SConN[si]
+		st := pj.SConIdxSt[si]
+		syns := pj.Syns.Range(st, nc)
+		scons := pj.SConIdx[st : st+int64(nc)]
 		for ci := range syns {
 			sy := &syns[ci]
 			ri := scons[ci]
@@ -11,19 +11,26 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"math/rand"
+	"net/url"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/emer/emergent/emer"
 	"github.com/emer/emergent/env"
 	"github.com/emer/emergent/netview"
 	"github.com/emer/emergent/params"
-	"github.com/emer/emergent/patgen"
 	"github.com/emer/emergent/prjn"
 	"github.com/emer/emergent/relpos"
 	"github.com/emer/etable/agg"
@@ -33,6 +40,10 @@ import (
 	_ "github.com/emer/etable/etview" // include to get gui views
 	"github.com/emer/etable/split"
 	"github.com/emer/leabra/leabra"
+	"github.com/emer/leabra/leabra/batch"
+	lenv "github.com/emer/leabra/leabra/env"
+	"github.com/emer/leabra/leabra/export"
+	"github.com/emer/leabra/leabra/server"
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gimain"
 	"github.com/goki/gi/giv"
@@ -50,6 +61,10 @@ func main() {
 // LogPrec is precision for saving float values in logs
 const LogPrec = 4
 
+// RunBootN is the number of bootstrap resamples for the confidence intervals
+// of the RunStats
+const RunBootN = 1000
+
 // ParamSets is the default set of parameters -- Base is always applied, and others can be optionally
 // selected to apply on top of that
 var ParamSets = params.Sets{
@@ -65,10 +80,6 @@ var ParamSets = params.Sets{
 				Params: params.Params{
 					"Layer.Inhib.Layer.Gi": "1.8",
 				}},
-			{Sel: ".Back", Desc: "top-down back-projections MUST have lower relative weight scale, otherwise network hallucinates",
-				Params: params.Params{
-					"Prjn.WtScale.Rel": "0.2",
-				}},
 			{Sel: "#Output", Desc: "output definitely needs lower inhib -- true for smaller layers in general",
 				Params: params.Params{
 					"Layer.Inhib.Layer.Gi": "1.4",
@@ -132,108 +143,245 @@ var ParamSets = params.Sets{
 				}},
 		},
 	}},
-	{Name: "Sleep", Desc: "these are the sleep params", Sheets: params.Sheets{
+	{Name: "HighInhib", Desc: "higher inhibition, e.g., as a conditional set: -cond \"HighInhib: EpcSSE > 0.5\"", Sheets: params.Sheets{
 		"Network": &params.Sheet{
-			{Sel: "Prjn", Desc: "norm and momentum on works better, but wt bal is not better for smaller nets",
-				Params: params.Params{
-					"Prjn.Learn.Norm.On":     "true",
-					"Prjn.Learn.Momentum.On": "true",
-					"Prjn.Learn.WtBal.On":    "true",
-				}},
-			{Sel: ".Back", Desc: "top-down back-projections MUST have lower relative weight scale, otherwise network hallucinates",
+			{Sel: "Layer", Desc: "higher inhib for all of network",
 				Params: params.Params{
-					"Prjn.WtScale.Rel": "0.8",
+					"Layer.Inhib.Layer.Gi": "2.0",
 				}},
+		},
+	}},
+	{Name: "Sleep", Desc: "these are the sleep-only params -- params with different wake and sleep values are in ModeParams", Sheets: params.Sheets{
+		"Network": &params.Sheet{
 			{Sel: "Layer", Desc: "using higher inhib for all of network during sleep-- can explore",
 				Params: params.Params{
 					"Layer.Inhib.Layer.GiBase": "1.8",
 				}},
-			{Sel: "Layer", Desc: "using higher inhib for all of network during sleep-- can explore",
+			{Sel: "Prjn", Desc: "synaptic depression during sleep -- per projection, e.g., faster recovery with a higher CaDec",
 				Params: params.Params{
-					"Layer.Inhib.Layer.FB": "1.2",
+					"Prjn.SynDep.CaInc":  "0.6",
+					"Prjn.SynDep.CaDec":  "0.25",
+					"Prjn.SynDep.CaGain": "3",
 				}},
 		},
-		"Sim": &params.Sheet{ // sim params apply to sim object
-			{Sel: "Sim", Desc: "best params always finish in this time",
+	}},
+	{Name: "SleepWeakBLA", Desc: "sleep with weaker input from the BLA -- use as SlpParamSet", Sheets: params.Sheets{
+		"Network": &params.Sheet{
+			{Sel: ".FromBLA", Desc: "weaker BLA drive during sleep",
 				Params: params.Params{
-					"Sim.MaxEpcs": "50",
+					"Prjn.WtScale.Rel": "0.5",
 				}},
 		},
 	}},
 }
 
+// ParamExtends declares the ParamSets that extend another, only adding the
+// values that differ -- they are resolved to include all the values of the
+// sets they extend when applied (see leabra.ParamExtends)
+var ParamExtends = leabra.ParamExtends{
+	"SleepWeakBLA": "Sleep",
+}
+
+// ModeParams are params with different values in wake and sleep, applied
+// along with the Base and Sleep sets respectively -- declaring both values
+// here keeps them in sync, and ensures the wake value is restored on waking
+var ModeParams = leabra.ParamPairs{
+	{Sel: ".Back", Path: "Prjn.WtScale.Rel", Wake: "0.2", Sleep: "0.8",
+		Desc: "top-down back-projections MUST have lower relative weight scale in wake, otherwise network hallucinates"},
+	{Sel: "Layer", Path: "Layer.Inhib.Layer.FB", Wake: "1", Sleep: "1.2",
+		Desc: "using higher inhib for all of network during sleep-- can explore"},
+}
+
+// QtrParams are params with a different value in each quarter of the alpha
+// cycle, applied automatically at the start of each quarter, e.g., a stronger
+// soft clamp in the plus phase:
+// {Sel: "#Output", Path: "Layer.Act.Clamp.Gain", Vals: [4]string{"0.2", "0.2", "0.2", "1"}}
+var QtrParams = leabra.ParamQtrs{}
+
+// HyperParams are the default search ranges for tuning the sleep params
+// with -hyperopt, when the study file does not specify params
+var HyperParams = []leabra.HyperParam{
+	{Sel: "Layer", Path: "Layer.Inhib.Layer.GiOscMin", Min: 0.8, Max: 1, Sleep: true},
+	{Sel: "Layer", Path: "Layer.Inhib.Layer.FB", Min: 0.8, Max: 1.6, Sleep: true},
+	{Sel: ".Back", Path: "Prjn.WtScale.Rel", Min: 0.2, Max: 1, Sleep: true},
+}
+
 // Sim encapsulates the entire simulation model, and we define all the
 // functionality as methods on this struct.  This structure keeps all relevant
 // state information organized and available without having to pass everything around
 // as arguments to methods, and provides the core GUI interface (note the view tags
 // for the fields which provide hints to how things should be displayed).
 type Sim struct {
-	Net          *leabra.Network   `view:"no-inline"`
-	Pats         *etable.Table     `view:"no-inline" desc:"the training patterns to use"`
-	SlpCycLog    *etable.Table     `view:"no-inline" desc:"sleeping cycle-level log data"`
-	TrnEpcLog    *etable.Table     `view:"no-inline" desc:"training epoch-level log data"`
-	TstEpcLog    *etable.Table     `view:"no-inline" desc:"testing epoch-level log data"`
-	TstTrlLog    *etable.Table     `view:"no-inline" desc:"testing trial-level log data"`
-	TstErrLog    *etable.Table     `view:"no-inline" desc:"log of all test trials where errors were made"`
-	TstErrStats  *etable.Table     `view:"no-inline" desc:"stats on test trials where errors were made"`
-	TstCycLog    *etable.Table     `view:"no-inline" desc:"testing cycle-level log data"`
-	RunLog       *etable.Table     `view:"no-inline" desc:"summary log of each run"`
-	RunStats     *etable.Table     `view:"no-inline" desc:"aggregate stats on all runs"`
-	Params       params.Sets       `view:"no-inline" desc:"full collection of param sets"`
-	ParamSet     string            `desc:"which set of *additional* parameters to use -- always applies Base and optionaly this next if set"`
-	Tag          string            `desc:"extra tag string to add to any file names output from sim (e.g., weights files, log files)"`
-	MaxRuns      int               `desc:"maximum number of model runs to perform"`
-	MaxEpcs      int               `desc:"maximum number of epochs to run per model run"`
-	MaxSlpCyc    int               `desc:"maximum number of cycle to sleep for a trial"`
-	TrainEnv     env.FixedTable    `desc:"Training environment -- contains everything about iterating over input / output patterns over training"`
-	SleepEnv     env.FixedTable    `desc:"Sleep environment -- contains everything about iterating over sleep trials"` // added by DH
-	TestEnv      env.FixedTable    `desc:"Testing environment -- manages iterating over testing"`
-	Time         leabra.Time       `desc:"leabra timing parameters and state"`
-	ViewOn       bool              `desc:"whether to update the network view while running"`
-	Sleep        bool              `desc:"Sleep or not"`
-	LrnDrgSlp    bool              `desc:"Learning during sleep?"`
-	SlpPlusThr   float32           `desc:"The threshold for entering a sleep plus phase"`
-	SlpMinusThr  float32           `desc:"The threshold for entering a sleep minus phase"`
-	InhibOscil   bool              `desc:"whether to implement inhibition oscillation"`
-	TrainUpdt    leabra.TimeScales `desc:"at what time scale to update the display during training?  Anything longer than Epoch updates at Epoch in this model"`
-	SleepUpdt    leabra.TimeScales `desc:"at what time scale to update the display during sleep? Anything longer than Epoch updates at Epoch in this model"` // added by DH
-	TestUpdt     leabra.TimeScales `desc:"at what time scale to update the display during testing?  Anything longer than Epoch updates at Epoch in this model"`
-	TestInterval int               `desc:"how often to run through all the test patterns, in terms of training epochs"`
+	Net          *leabra.Network        `view:"no-inline"`
+	Pats         *etable.Table          `view:"no-inline" desc:"the training patterns to use"`
+	SlpCycLog    *etable.Table          `view:"no-inline" desc:"sleeping cycle-level log data"`
+	TrnEpcLog    *etable.Table          `view:"no-inline" desc:"training epoch-level log data, including the learning in each projection, separately for wake and sleep -- see DWtStats"`
+	TstEpcLog    *etable.Table          `view:"no-inline" desc:"testing epoch-level log data"`
+	TstTrlLog    *etable.Table          `view:"no-inline" desc:"testing trial-level log data, including the minus and plus phase activations of the PhaseLays"`
+	TstErrLog    *etable.Table          `view:"no-inline" desc:"log of all test trials where errors were made"`
+	TstErrStats  *etable.Table          `view:"no-inline" desc:"stats on test trials where errors were made"`
+	TstCycLog    *etable.Table          `view:"no-inline" desc:"testing cycle-level log data"`
+	TrnPhsLog    *etable.Table          `view:"no-inline" desc:"minus and plus phase activations of the PhaseLays (and any Train Trial items of the LogSpecFile) on each training trial of the current epoch"`
+	Logs         leabra.Logs            `view:"no-inline" desc:"declared items of all the logs, made with the logging framework -- see ConfigLogs"`
+	RunLog       *etable.Table          `view:"no-inline" desc:"summary log of each run"`
+	RunStats     *etable.Table          `view:"no-inline" desc:"aggregate stats on all runs"`
+	RunCmp       leabra.RunCompare      `view:"no-inline" desc:"comparison of the run stats across conditions: the ParamSets in the RunLog, or saved run logs -- see CompareRunLogs -- with t-tests and ANOVA in RunCmp.Tests"`
+	RepProj      *etable.Table          `view:"no-inline" desc:"2D projection of the Hidden1 representations of the test items at each test of the run -- see ProjectReps"`
+	ProfLog      *etable.Table          `view:"no-inline" desc:"time spent in each function for each layer, per epoch, when Net.Profile is on"`
+	Params       params.Sets            `view:"no-inline" desc:"full collection of param sets"`
+	Extends      leabra.ParamExtends    `view:"no-inline" desc:"param sets that extend another set, by name: set -> set it extends"`
+	ParamSet     string                 `desc:"which set of *additional* parameters to use -- always applies Base and optionaly this next if set"`
+	SlpParamSet  string                 `desc:"additional set of parameters to apply during sleep, on top of the Sleep params -- e.g., from a hyperparameter search"`
+	OverParamSet string                 `inactive:"+" desc:"set of params given on the command line with -set (see Overrides), applied after the ParamSet"`
+	RunParamSet  string                 `inactive:"+" desc:"set of per-run sampled params (see RunSample) applied on top of the ParamSet for the current run"`
+	ParamRecs    leabra.ParamRecs       `view:"-" desc:"record of all params applied since the last full SetParams, with the set that applied each and the default it overrode -- see SaveParams"`
+	Tag          string                 `desc:"extra tag string to add to any file names output from sim (e.g., weights files, log files)"`
+	MaxRuns      int                    `desc:"maximum number of model runs to perform"`
+	MaxEpcs      int                    `desc:"maximum number of epochs to run per model run"`
+	MaxSlpCyc    int                    `desc:"maximum number of cycle to sleep for a trial"`
+	TrainEnv     lenv.SeqEnv            `desc:"Training environment -- contains everything about iterating over input / output patterns over training -- presents the sequences of the SeqCol in order, if set"`
+	SleepEnv     env.FixedTable         `desc:"Sleep environment -- contains everything about iterating over sleep trials"` // added by DH
+	Salience     leabra.SalienceEnv     `desc:"salience channel of the patterns: the valence and arousal of each (from Valence and Arousal columns of the patterns, else from Ne and Po), which are the categories of the CatStats if there is no Cat column, and if On populate the Ne, Po, Ne_Out and Po_Out patterns and modulate the learning rate by arousal (AroLrate) -- see -salience"`
+	ReplayBuf    leabra.ReplayBuf       `desc:"if On, holds the most recent wake trials with salience weights from their error and Ne / Po values, and seeds the activity of each sleep trial with one of them, sampled by salience, instead of purely random activity -- see -replaybuf"`
+	TestEnv      lenv.SeqEnv            `desc:"Testing environment -- manages iterating over testing"`
+	PatsFile     string                 `desc:"file of the training patterns opened by OpenPats, unless GenPats -- see -pats"`
+	GenPats      bool                   `desc:"if true, the patterns are generated by PatGen in ConfigPats instead of opened from PatsFile -- see -genpats"`
+	PatGen       leabra.PatFamilies     `desc:"generates families of patterns with controlled overlap and prototype-plus-distortion structure, with the family of each as its Cat, if GenPats"`
+	SynthTarget  string                 `desc:"if set, file of a target similarity matrix of the patterns (see leabra.PatSimsTable) from which PatSynth synthesizes the patterns in place of PatGen -- see -synthpats"`
+	PatSynth     leabra.PatSynth        `desc:"synthesizes patterns that approximately realize the target similarity matrix in SynthTarget, with its categories as their Cat"`
+	ImageFiles   string                 `desc:"if set, glob pattern(s) of image files, separated by , that are the Input and Output patterns, preprocessed by Images, with the directory of each as its Cat -- see -images"`
+	Images       lenv.ImageEnv          `desc:"loads and preprocesses the ImageFiles to the size of the Input layer"`
+	CueTest      lenv.CueEnv            `desc:"if Cues are set, the tests are cued recall: of the Input, Ne and Po patterns, only the Cues (all or a fraction of the units of each) are presented, and the rest are blanked, while the recall of the full Output is scored -- see -cue"`
+	Modal        leabra.MultiModalEnv   `desc:"if there are several Mods, the patterns of the modalities other than the first (Input) are presented in their own input layers (added to the network), bound to each item with probability Contingency on each training trial and otherwise those of a random other item, to study the consolidation of cross-modal bindings -- testing presents the bound patterns -- see -modalities"`
+	Augment      leabra.AugmentEnv      `desc:"if its Spec is set, a pipeline of data-augmentation transforms (jitter of the active units, random masking, value scaling) applied in order to the Input patterns of TrainEnv at each training trial, as a regularization / generalization manipulation -- can be set by the Sim params as Sim.Augment.Spec -- see -augment"`
+	TestLists    leabra.TestLists       `desc:"named lists of test items (e.g., lure items, or subsets of the trained items), with optional noise and partial cues for each list or item, opened from a JSON file -- tested by TestList, and after each TestAll for the TestListNms -- see -testlists"`
+	TestListNms  []string               `desc:"names of the TestLists tested after each TestAll -- see -testlist"`
+	CurTestList  *leabra.TestList       `view:"-" desc:"the TestList currently tested, if any"`
+	GenTest      leabra.GenTest         `desc:"if On, the generalization test is run after each TestAll: the trained items, novel recombinations of their features and novel controls, made for each run, with the type of each item logged as GenType, to measure whether sleep improves generalization as opposed to rote retention -- see -gentest"`
+	GenTesting   bool                   `inactive:"+" desc:"true during the generalization test"`
+	TestNoise    lenv.NoisyEnv          `desc:"if Level > 0, noise of the given Type (bit flips, gaussian, or an occlusion mask) is added to the Input patterns of TestEnv at presentation time, with the Level logged as Noise in the test logs, to measure the robustness of recall -- see -testnoise"`
+	TrainSched   leabra.TrainSched      `desc:"if On, orders the training items of each epoch in a blocked, interleaved or weighted schedule of their CatStats categories, with the items of some categories introduced only from a given epoch on -- see -sched and -schedstart"`
+	Curriculum   leabra.Curriculum      `desc:"if On, training progresses through NTiers tiers of items of increasing difficulty (by default, their overlap with the other items), from one tier to the next once the PctCor of an epoch meets CritThr -- overrides TrainSched -- the tier of each epoch is logged as Tier -- see -curriculum"`
+	Stream       lenv.StreamEnv         `desc:"if its Source is set, training reads the items lazily from this stream (a large file or a network source), one chunk of StreamChunk items per epoch, instead of training on the patterns, which are still tested -- see -trainstream"`
+	StreamChunk  int                    `desc:"number of items of the Stream trained per epoch -- only one chunk is held in memory"`
+	SeqCol       string                 `desc:"if set, column of the patterns with the name of the sequence of each item: contiguous items with the same name are one sequence, presented in order by TrainEnv and TestEnv, with the order of the sequences shuffled for training -- see -seqcol"`
+	Context      lenv.SeqContext        `desc:"if On, the ActP of Hidden1 at the end of each trial is applied to a Context input layer (added to the network) on the next trial, reset at the start of each sequence, for sequence learning -- see -context"`
+	Split        leabra.DataSplit       `desc:"if Props are set, the patterns are split at random for each run into Train, Test and optionally Valid sets, stratified by the CatStats category of each item if Strat -- TrainEnv trains on the Train set and TestEnv tests SplitTest -- see -split"`
+	SplitTest    string                 `desc:"which split of the patterns TestEnv tests, if Split is used: Test or Valid"`
+	SetSize      leabra.SetSize         `desc:"if Sizes are set, each run trains on a random subsample of the (Train split of the) patterns of the size for the run, stratified by the CatStats category of each item if Strat, and tests the same items unless split -- generated patterns are made for the largest size -- see -setsizes"`
+	Time         leabra.Time            `desc:"leabra timing parameters and state"`
+	ViewOn       bool                   `desc:"whether to update the network view while running"`
+	Sleep        bool                   `desc:"Sleep or not"`
+	LrnDrgSlp    bool                   `desc:"Learning during sleep?"`
+	SlpPlusThr   float32                `desc:"The threshold for entering a sleep plus phase"`
+	SlpMinusThr  float32                `desc:"The threshold for entering a sleep minus phase"`
+	InhibOscil   bool                   `desc:"whether to implement inhibition oscillation"`
+	TrainUpdt    leabra.TimeScales      `desc:"at what time scale to update the display during training?  Anything longer than Epoch updates at Epoch in this model"`
+	SleepUpdt    leabra.TimeScales      `desc:"at what time scale to update the display during sleep? Anything longer than Epoch updates at Epoch in this model"` // added by DH
+	TestUpdt     leabra.TimeScales      `desc:"at what time scale to update the display during testing?  Anything longer than Epoch updates at Epoch in this model"`
+	TestInterval int                    `desc:"how often to run through all the test patterns, in terms of training epochs"`
+	Lesions      leabra.LesionSched     `desc:"schedule of lesion events, applied at the start of each Epoch, or after each SleepTrial"`
+	Seeds        leabra.Seeds           `desc:"named random number streams (weights-init, noise, env-shuffle, sleep-init) derived from RndSeed for each run -- any can be given a Fixed seed, e.g., to keep the same initial weights with different sleep noise"`
+	Overrides    leabra.ParamOverrides  `desc:"individual param values that override the ParamSet, e.g., from -set Layer.Inhib.Layer.Gi=1.7 on the command line -- call SetOverrides after changing"`
+	Anneal       leabra.ParamScheds     `desc:"time-varying params, e.g., Layer.Inhib.Layer.Gi: 2.0->1.6 over 0-20, applied on top of the ParamSet at the start of each Epoch"`
+	CondSets     leabra.CondParamSets   `desc:"param sets applied on top of the ParamSet while a condition on the Sim stats holds, e.g., HighInhib: EpcSSE > 0.5 -- evaluated at the start of each Epoch"`
+	RunSample    leabra.RunSampler      `desc:"params sampled independently for each run from given distributions, to simulate individual differences -- sampled values are recorded in the RunLog"`
+	Sleeping     bool                   `inactive:"+" desc:"true during a sleep trial"`
+	WakeParams   *leabra.ParamsSnapshot `view:"-" desc:"snapshot of the network params in effect before sleep, restored on waking"`
+	ActStream    leabra.ActStream       `view:"-" desc:"streams unit variables of selected layers to disk every cycle or quarter, for offline analysis of e.g., sleep dynamics -- see -recacts"`
+	ActDump      leabra.ActDump         `view:"-" desc:"dumps unit variables of selected layers every cycle or quarter to a memory-mapped binary file, with an index of the trials so that e.g., a given sleep trial can be read without loading everything (see leabra.OpenActDump) -- see -dumpacts"`
+	RepSpace     leabra.RepSpace        `desc:"Hidden1 ActM representations of the test items recorded at each test of the run, projected into 2D with PCA or MDS by ProjectReps, to show how sleep reorganizes the representational space"`
+	PatComp      leabra.PatComp         `desc:"pattern-completion test: the test items are presented with their Input cues degraded at each of the Levels, after each TestAll if any Levels are set, and the output scores for each level are logged in PatComp.Results"`
+	Confusion    leabra.Confusion       `desc:"confusion matrix of the test trials: which item the Output ActM is closest to for each item presented -- logged as probabilities in the TstEpcLog after each test"`
+	ReplayOut    leabra.ReplayDecoder   `desc:"decodes which training item the Output activity matches on each sleep cycle, logged in the SlpCycLog"`
+	ReplayHid    leabra.ReplayDecoder   `desc:"decodes which test item's Hidden1 ActM representation (from the last test) the Hidden1 activity matches on each sleep cycle, logged in the SlpCycLog"`
+	DWtStats     leabra.DWtStats        `desc:"sum of absolute weight changes and fraction of synapses changed in each projection, accumulated over each epoch separately for wake and sleep learning, logged in the TrnEpcLog"`
+	Dwell        leabra.AttractorDwell  `desc:"segments the Hidden1 activity of each sleep trial into attractor states at the dips of its LaySim, with the number of states, dwell times and transitions of each trial logged in Dwell.Results"`
+	SettleRT     leabra.SettleRT        `desc:"cycles for the Output to settle in the minus phase of each trial (max ActDel below Thr for K cycles), as a reaction time -- logged as RT"`
+	Watchdog     leabra.Watchdog        `desc:"if On, checks for NaN, Inf or exploding Act and Wt values at the end of every quarter (every CycPerQtr cycles in sleep), and if found, dumps the offending state and recent param changes to a _watchdog.txt file and stops the run, or only the sleep trial in sleep -- see -watchdog"`
+	SynInsp      leabra.SynInspect      `view:"no-inline" desc:"inspects a single synapse, set by InspectSynapse: its Vals are updated every sleep cycle and training trial, and traced in the SynInspPlot, for debugging the syndep dynamics"`
+	EarlyStop    leabra.EarlyStop       `desc:"if On, each quarter of the wake and test trials is stopped early once the max ActDel of all layers stays below Thr, with the cycles actually run logged as Cycles -- see -earlystop"`
+	CatStats     leabra.CatStats        `desc:"test stats computed separately for each category of items (Cat column of the patterns, or Neg / Pos / Neutral from the Ne and Po inputs if none), logged in the TstEpcLog as Stat:Cat columns"`
+	ABAC         leabra.ABAC            `desc:"if On, each run is the A-B / A-C interference paradigm: the patterns (AB) are trained for ABEpcs epochs, then the AC list (each Input with the Output of another item) for ACEpcs epochs, with both lists tested at each test, logged as List -- see -abac"`
+	OneShot      leabra.OneShot         `desc:"if On, each run is the episodic one-shot protocol: a random StudyProp of the items are studied Reps times in one epoch, followed by sleep (if Sleep) and a delayed test of all the items -- see -oneshot"`
+	StudyStats   leabra.CatStats        `desc:"test stats computed separately for the Studied and Unstudied items of the OneShot protocol, logged in the TstEpcLog as Stat:Studied and Stat:Unstudied columns"`
+	GenStats     leabra.CatStats        `desc:"test stats computed separately for the Trained, Recombined and Novel items of the GenTest, logged in the TstEpcLog as Stat:Trained, Stat:Recombined and Stat:Novel columns"`
+	ErrStats     []string               `desc:"additional error measures of the Output against its targets computed in TrialStats and logged for testing, from: CrossEnt, AUC, DPrime, Hit, FA (see leabra.ErrStats) -- reconfigure the test logs after changing"`
+	PhaseLays    []string               `desc:"layers whose minus and plus phase activations (ActM, ActP, ActDif) are logged on every training and testing trial, in the TrnPhsLog and TstTrlLog -- none if empty -- call ConfigLogs after changing"`
+	CueLevel     int                    `inactive:"+" desc:"index of the PatComp level at which ApplyInputs degrades the input cues -- -1 for the full patterns"`
 
 	// statistics: note use float64 as that is best for etable.Table
 	TrlSSE     float64 `inactive:"+" desc:"current trial's sum squared error"`
 	TrlAvgSSE  float64 `inactive:"+" desc:"current trial's average sum squared error"`
 	TrlCosDiff float64 `inactive:"+" desc:"current trial's cosine difference"`
+	TrlRT      float64 `inactive:"+" desc:"current trial's cycles for the output to settle in the minus phase (see SettleRT)"`
+	TrlCycs    float64 `inactive:"+" desc:"current trial's number of cycles actually run, over all quarters (see EarlyStop)"`
 	EpcSSE     float64 `inactive:"+" desc:"last epoch's total sum squared error"`
 	EpcAvgSSE  float64 `inactive:"+" desc:"last epoch's average sum squared error (average over trials, and over units within layer)"`
 	EpcPctErr  float64 `inactive:"+" desc:"last epoch's percent of trials that had SSE > 0 (subject to .5 unit-wise tolerance)"`
 	EpcPctCor  float64 `inactive:"+" desc:"last epoch's percent of trials that had SSE == 0 (subject to .5 unit-wise tolerance)"`
 	EpcCosDiff float64 `inactive:"+" desc:"last epoch's average cosine difference for output layer (a normalized error measure, maximum of 1 when the minus phase exactly matches the plus)"`
+	EpcRT      float64 `inactive:"+" desc:"last epoch's average cycles for the output to settle"`
+	EpcCycs    float64 `inactive:"+" desc:"last epoch's average number of cycles run per trial"`
 	FirstZero  int     `inactive:"+" desc:"epoch at when SSE first went to zero"`
 	AvgLaySim  float64 `inactive:"+" desc:"Average layer similarity between current cycle and previous cycle"`
 
+	TrlErrs []float64 `inactive:"+" desc:"current trial's additional error measures of the output, one for each of ErrStats"`
+
 	// internal state - view:"-"
-	SumSSE       float64          `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
-	SumAvgSSE    float64          `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
-	SumCosDiff   float64          `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
-	CntErr       int              `view:"-" inactive:"+" desc:"sum of errs to increment as we go through epoch"`
-	Win          *gi.Window       `view:"-" desc:"main GUI window"`
-	NetView      *netview.NetView `view:"-" desc:"the network viewer"`
-	ToolBar      *gi.ToolBar      `view:"-" desc:"the master toolbar"`
-	SlpCycPlot   *eplot.Plot2D    `view:"-" desc:"the sleeping cycle plot"`
-	TrnEpcPlot   *eplot.Plot2D    `view:"-" desc:"the training epoch plot"`
-	TstEpcPlot   *eplot.Plot2D    `view:"-" desc:"the testing epoch plot"`
-	TstTrlPlot   *eplot.Plot2D    `view:"-" desc:"the test-trial plot"`
-	TstCycPlot   *eplot.Plot2D    `view:"-" desc:"the test-cycle plot"`
-	RunPlot      *eplot.Plot2D    `view:"-" desc:"the run plot"`
-	TrnEpcFile   *os.File         `view:"-" desc:"log file"`
-	RunFile      *os.File         `view:"-" desc:"log file"`
-	SaveWts      bool             `view:"-" desc:"for command-line run only, auto-save final weights after each run"`
-	NoGui        bool             `view:"-" desc:"if true, runing in no GUI mode"`
-	LogSetParams bool             `view:"-" desc:"if true, print message for all params that are set"`
-	IsRunning    bool             `view:"-" desc:"true if sim is running"`
-	StopNow      bool             `view:"-" desc:"flag to stop running"`
-	RndSeed      int64            `view:"-" desc:"the current random seed"`
+	SumSSE        float64            `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	SumAvgSSE     float64            `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	SumCosDiff    float64            `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	SumRT         float64            `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	SumCycs       float64            `view:"-" inactive:"+" desc:"sum to increment as we go through epoch"`
+	CntErr        int                `view:"-" inactive:"+" desc:"sum of errs to increment as we go through epoch"`
+	Win           *gi.Window         `view:"-" desc:"main GUI window"`
+	NetView       *netview.NetView   `view:"-" desc:"the network viewer"`
+	ToolBar       *gi.ToolBar        `view:"-" desc:"the master toolbar"`
+	SlpCycPlot    *eplot.Plot2D      `view:"-" desc:"the sleeping cycle plot"`
+	SlpGiPlot     *eplot.Plot2D      `view:"-" desc:"the sleeping cycle plot of the Gi of each layer and the Gi oscillation"`
+	SynInspPlot   *eplot.Plot2D      `view:"-" desc:"the plot of the trace of the synapse inspected by SynInsp"`
+	TrnDWtPlot    *eplot.Plot2D      `view:"-" desc:"the plot of learning in each projection per training epoch"`
+	TrnEpcPlot    *eplot.Plot2D      `view:"-" desc:"the training epoch plot"`
+	TstEpcPlot    *eplot.Plot2D      `view:"-" desc:"the testing epoch plot"`
+	TstTrlPlot    *eplot.Plot2D      `view:"-" desc:"the test-trial plot"`
+	TstCycPlot    *eplot.Plot2D      `view:"-" desc:"the test-cycle plot"`
+	RunPlot       *eplot.Plot2D      `view:"-" desc:"the run plot"`
+	RunCmpPlot    *eplot.Plot2D      `view:"-" desc:"the plot of the comparison of the run stats across conditions, with confidence intervals"`
+	RepPlot       *eplot.Plot2D      `view:"-" desc:"the plot of the 2D projection of the hidden representations, with the trajectory of each test item"`
+	PatCompPlot   *eplot.Plot2D      `view:"-" desc:"the plot of pattern completion as a function of the degradation of the cues"`
+	Live          leabra.LiveParams  `view:"-" desc:"param values currently in effect, for live editing in the LiveParams tab, with a changelog of edits in the ParamLog tab"`
+	LiveView      *giv.TableView     `view:"-" desc:"the live params view"`
+	LiveLogView   *giv.TableView     `view:"-" desc:"the live params changelog view"`
+	PatCompFile   *leabra.LogFile    `view:"-" desc:"log file"`
+	DwellFile     *leabra.LogFile    `view:"-" desc:"log file"`
+	LogOpts       leabra.LogFileOpts `view:"-" desc:"gzip compression and size- or time-based rotation of all the log files and activity streams -- see -gzip, -logmaxmb, -logmaxage"`
+	LogSpecFile   string             `view:"-" desc:"if set, JSON or TOML file declaring additional items of the logs made with the logging framework (see leabra.LogSpecs), for any of the logs: Sleep Cycle, Test Cycle, Train Trial, Epoch and Run, and Test Trial and Epoch -- call ConfigLogs after changing"`
+	TBDir         string             `view:"-" desc:"if set, the train and test epoch logs are also written to TensorBoard event files in a subdirectory of this directory for each run: <TBDir>/<RunName>/run_<run>"`
+	TBWts         bool               `view:"-" desc:"if true, with TBDir, histograms of the weights of each projection are also written every epoch"`
+	TBoard        *export.TBWriter   `view:"-" desc:"TensorBoard writer for the current run"`
+	MetricsAddr   string             `view:"-" desc:"if set, address (e.g., :9090) at which the current counters and stats are served for monitoring, at /metrics in the Prometheus text format"`
+	Metrics       leabra.Metrics     `view:"-" desc:"the current counters and stats served at MetricsAddr"`
+	ServeAddr     string             `view:"-" desc:"if set, the sim runs headless as a server of its control API at this address (e.g., :8080) instead of running the runs -- see ConfigServer"`
+	GRPCAddr      string             `view:"-" desc:"if set, the sim runs headless as a server of its control API over gRPC at this address (e.g., :8081), as with ServeAddr, which it can be used along with -- see server.GRPCService"`
+	Server        server.Server      `view:"-" desc:"serves the control API at ServeAddr and GRPCAddr"`
+	DashAddr      string             `view:"-" desc:"if set, address (e.g., :8090) at which the Dash monitoring dashboard is served to a browser"`
+	Progress      leabra.Progress    `view:"-" desc:"for command-line run only, reports the progress of training after each epoch: the PctCor, elapsed time and ETA"`
+	Dash          server.Dashboard   `view:"-" desc:"browser-based monitoring dashboard served at DashAddr: plots of the train and test epoch stats and of the LaySim traces of the current sleep trial, and heatmaps of the activity of each layer"`
+	LogWindow     int                `view:"-" desc:"if > 0, logs that are written to a file only keep this many of their most recent rows in memory, for plotting -- bounds memory over long runs"`
+	SaveWts       bool               `view:"-" desc:"for command-line run only, auto-save final weights after each run"`
+	SaveReps      bool               `view:"-" desc:"for command-line run only, save the 2D projection of the hidden representations at the end of each run"`
+	SaveSplit     bool               `view:"-" desc:"for command-line run only, save the split of the patterns of each run (see Split) to a _split_<run>.csv file, for reproducibility"`
+	SaveSQLite    bool               `view:"-" desc:"for command-line run only, write all the log tables of each run into a single SQLite database at the end of the run, with the run metadata -- see SaveRunSQLite"`
+	NetConfigFile string             `view:"-" desc:"if set, network architecture is configured from this JSON or TOML file (see leabra.NetConfig) instead of the built-in ConfigNet"`
+	NoGui         bool               `view:"-" desc:"if true, runing in no GUI mode"`
+	LogSetParams  bool               `view:"-" desc:"if true, print message for all params that are set"`
+	IsRunning     bool               `view:"-" desc:"true if sim is running"`
+	SlpStepping   bool               `inactive:"+" desc:"true if in sleep, stepping through a sleep trial (see EnterSleep) -- the other run actions are disabled until ExitSleep"`
+	SlpCyc        int                `inactive:"+" desc:"next cycle of the sleep trial being stepped"`
+	StopNow       bool               `view:"-" desc:"flag to stop running"`
+	Interrupted   int32              `view:"-" desc:"for command-line run only, set to 1 when an interrupt or terminate signal is received, which stops training or sleep after the current trial -- accessed atomically, as it is set by the signal handler -- see NotifyInterrupt and SaveInterrupted"`
+	RndSeed       int64              `view:"-" desc:"the current random seed"`
 }
 
 // this registers this Sim Type and gives it properties that e.g.,
@@ -249,19 +397,46 @@ func (ss *Sim) New() {
 	ss.Pats = &etable.Table{}
 	ss.TrnEpcLog = &etable.Table{}
 	ss.SlpCycLog = &etable.Table{}
+	ss.TrnPhsLog = &etable.Table{}
 	ss.TstEpcLog = &etable.Table{}
 	ss.TstTrlLog = &etable.Table{}
 	ss.TstCycLog = &etable.Table{}
 	ss.RunLog = &etable.Table{}
+	ss.ProfLog = &etable.Table{}
 	ss.RunStats = &etable.Table{}
+	ss.RepProj = &etable.Table{}
 	ss.Params = ParamSets
+	ss.Extends = ParamExtends
 	ss.RndSeed = 1
+	ss.PatsFile = "summer_5x5_25.dat"
 	ss.ViewOn = true
 	ss.Sleep = true
 	ss.InhibOscil = true
 	ss.TrainUpdt = leabra.FastSpike
 	ss.TestUpdt = leabra.Cycle
 	ss.TestInterval = 5
+	ss.Split.Strat = true
+	ss.SplitTest = "Test"
+	ss.PatComp.Defaults()
+	ss.Context.Defaults()
+	ss.PatGen.Defaults()
+	ss.PatSynth.Defaults()
+	ss.Images.Defaults()
+	ss.TestNoise.Defaults()
+	ss.Augment.Defaults()
+	ss.Modal.Defaults()
+	ss.GenTest.Defaults()
+	ss.CueTest.Defaults()
+	ss.TrainSched.Defaults()
+	ss.Curriculum.Defaults()
+	ss.OneShot.Defaults()
+	ss.ABAC.Defaults()
+	ss.ReplayBuf.Defaults()
+	ss.Salience.Defaults()
+	ss.Stream.Loop = true
+	ss.StreamChunk = 100
+	ss.ErrStats = []string{"CrossEnt", "AUC", "DPrime"}
+	ss.CueLevel = -1
 }
 
 ////////////////////////////////////////////////////////////////////////////////////////////
@@ -269,16 +444,39 @@ func (ss *Sim) New() {
 
 // Config configures all the elements using the standard functions
 func (ss *Sim) Config() {
-	//ss.ConfigPats()
-	ss.OpenPats()
+	if ss.GenPats {
+		ss.ConfigPats()
+	} else {
+		ss.OpenPats()
+	}
 	ss.ConfigEnv()
+	ss.ConfigSalience()
+	ss.ConfigModal()
+	ss.Confusion.SetPats(ss.Pats, "Name", "Output")
+	ss.ConfigCatStats()
+	ss.ReplayOut.Defaults()
+	ss.ReplayOut.Layer = "Output"
+	ss.ReplayOut.SetPats(ss.Pats, "Name", "Output")
+	ss.ReplayHid.Defaults()
+	ss.ReplayHid.Layer = "Hidden1"
+	ss.Dwell.Defaults()
+	ss.ActDump.Defaults()
+	ss.SettleRT.Defaults()
+	ss.EarlyStop.Defaults()
+	ss.Watchdog.Defaults()
+	ss.SynInsp.Defaults()
+	ss.Progress.Defaults()
 	ss.ConfigNet(ss.Net)
-	ss.ConfigSlpCycLog(ss.SlpCycLog)
-	ss.ConfigTrnEpcLog(ss.TrnEpcLog)
-	ss.ConfigTstEpcLog(ss.TstEpcLog)
-	ss.ConfigTstTrlLog(ss.TstTrlLog)
-	ss.ConfigTstCycLog(ss.TstCycLog)
-	ss.ConfigRunLog(ss.RunLog)
+	ss.DWtStats.Defaults()
+	ss.DWtStats.Init(ss.Net)
+	ss.Net.ModeParams = ModeParams
+	ss.Net.QtrParams = QtrParams
+	ss.ConfigLogs()
+	ss.ConfigRunCmp()
+	ss.PatComp.ConfigResults()
+	ss.Dwell.ConfigResults()
+	ss.Lesions.ConfigLog()
+	ss.Net.ConfigProfileLog(ss.ProfLog)
 }
 
 func (ss *Sim) ConfigEnv() {
@@ -295,6 +493,7 @@ func (ss *Sim) ConfigEnv() {
 	ss.TrainEnv.Nm = "TrainEnv"
 	ss.TrainEnv.Dsc = "training params and state"
 	ss.TrainEnv.Table = etable.NewIdxView(ss.Pats)
+	ss.TrainEnv.SeqCol = ss.SeqCol
 	ss.TrainEnv.Validate()
 	ss.TrainEnv.Run.Max = ss.MaxRuns // note: we are not setting epoch max -- do that manually
 
@@ -307,14 +506,12 @@ func (ss *Sim) ConfigEnv() {
 	ss.TestEnv.Nm = "TestEnv"
 	ss.TestEnv.Dsc = "testing params and state"
 	ss.TestEnv.Table = etable.NewIdxView(ss.Pats)
+	ss.TestEnv.SeqCol = ss.SeqCol
 	ss.TestEnv.Sequential = true
 	ss.TestEnv.Validate()
 
-	// note: to create a train / test split of pats, do this:
-	// all := etable.NewIdxView(ss.Pats)
-	// splits, _ := split.Permuted(all, []float64{.8, .2}, []string{"Train", "Test"})
-	// ss.TrainEnv.Table = splits.Splits[0]
-	// ss.TestEnv.Table = splits.Splits[1]
+	// note: a train / test split of pats is made for each run by ApplySplit
+	// if Split.Props are set
 
 	ss.TrainEnv.Init(0)
 	ss.SleepEnv.Init(0)
@@ -322,6 +519,18 @@ func (ss *Sim) ConfigEnv() {
 }
 
 func (ss *Sim) ConfigNet(net *leabra.Network) {
+	if ss.NetConfigFile != "" {
+		nc, err := leabra.OpenNetConfig(gi.FileName(ss.NetConfigFile))
+		if err == nil {
+			err = nc.Config(net)
+		}
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		ss.BuildNet(net)
+		return
+	}
 	net.InitName(net, "SUMMER")
 	inLay := net.AddLayer2D("Input", 5, 5, emer.Input)
 	blaNeInLay := net.AddLayer2D("Ne", 3, 1, emer.Input)
@@ -369,7 +578,38 @@ func (ss *Sim) ConfigNet(net *leabra.Network) {
 	// that would mean that the output layer doesn't reflect target values in plus phase
 	// and thus removes error-driven learning -- but stats are still computed.
 
+	prv := "Input"
+	for _, mod := range ss.Modal.OtherMods() { // input layers of the other modalities
+		modLay := net.AddLayer2D(mod, 5, 5, emer.Input)
+		modLay.SetRelPos(relpos.Rel{Rel: relpos.LeftOf, Other: prv, YAlign: relpos.Front, Space: 2})
+		prv = mod
+		net.ConnectLayers(modLay, hid1Lay, prjn.NewFull(), emer.Forward)
+		net.ConnectLayers(hid1Lay, modLay, prjn.NewFull(), emer.Back)
+	}
+
+	if ss.Context.On { // context layer for sequence learning
+		ctxLay := net.AddLayer2D(ss.Context.To, 12, 12, emer.Input)
+		ctxLay.SetRelPos(relpos.Rel{Rel: relpos.RightOf, Other: "Hidden1", YAlign: relpos.Front, Space: 2})
+		net.ConnectLayers(ctxLay, hid1Lay, prjn.NewFull(), emer.Forward)
+	}
+
+	// layer groups can be targeted as classes in params, e.g., ".BLA", and
+	// support collective operations such as net.GroupSetLearn("BLA", false)
+	net.AddGroup("Cortex", append([]string{"Input", "Hidden1", "Output"}, ss.Modal.OtherMods()...)...)
+	net.AddGroup("BLA", "Ne", "Po", "Ne_Out", "Po_Out")
+
+	// prjns can also be tagged with classes for params, e.g., ".FromBLA"
+	net.AddPrjnClassFunc("FromBLA", func(pj *leabra.Prjn) bool {
+		return pj.Send.(leabra.LeabraLayer).AsLeabra().HasClass("BLA")
+	})
+
+	ss.BuildNet(net)
+}
+
+// BuildNet sets defaults and params, builds and initializes the configured network
+func (ss *Sim) BuildNet(net *leabra.Network) {
 	net.Defaults()
+	net.Stats.On = true                      // accumulate energy and weight-change stats for TrnEpcLog
 	ss.SetParams("Network", ss.LogSetParams) // only set Network params
 	err := net.Build()
 	if err != nil {
@@ -389,8 +629,12 @@ func (ss *Sim) Init() {
 	ss.ConfigEnv() // re-config env just in case a different set of patterns was
 	// selected or patterns have been modified etc
 	ss.StopNow = false
+	atomic.StoreInt32(&ss.Interrupted, 0)
+	ss.SlpStepping, ss.SlpCyc = false, 0
+	ss.Live.Reset()                   // live edits apply until Init
 	ss.SetParams("", ss.LogSetParams) // all sheets
 	ss.NewRun()
+	ss.UpdateLive()
 	ss.UpdateView("train")
 }
 
@@ -416,6 +660,20 @@ func (ss *Sim) Counters(state string) string {
 	return ""
 }
 
+// MarkActs marks the start of a trial in given state in the ActStream and
+// the index of the ActDump
+func (ss *Sim) MarkActs(state string) {
+	ss.ActStream.Mark(state + " " + strings.Join(strings.Fields(ss.Counters(state)), " "))
+	trl, name := ss.TrainEnv.Trial.Cur, ss.TrainEnv.TrialName
+	switch state {
+	case "test":
+		trl, name = ss.TestEnv.Trial.Cur, ss.TestEnv.TrialName
+	case "sleep":
+		trl, name = ss.SleepEnv.Trial.Cur, ""
+	}
+	ss.ActDump.Mark(state, ss.TrainEnv.Run.Cur, ss.TrainEnv.Epoch.Cur, trl, name)
+}
+
 func (ss *Sim) UpdateView(state string) {
 	if ss.NetView != nil {
 		// note: essential to use Go version of update when called from another goroutine
@@ -423,6 +681,50 @@ func (ss *Sim) UpdateView(state string) {
 	}
 }
 
+// ViewSynapses views the synapses of the unit with given 1D index of given
+// layer in the NetView: its received (r.) and sent (s.) synapse variables,
+// e.g., r.Cai, r.Effwt and r.SRAvgDp, on the units of the other layers --
+// switches the NetView to r.Cai if not already showing a synapse variable
+func (ss *Sim) ViewSynapses(layer string, unit int) error {
+	if err := ss.Net.SetSynView(layer, unit); err != nil {
+		return err
+	}
+	if ss.NetView != nil {
+		if !leabra.IsSynViewVar(ss.NetView.Var) {
+			ss.NetView.Var = "r.Cai"
+		}
+		ss.UpdateView("train")
+	}
+	return nil
+}
+
+// InspectSynapse inspects the synapse from the unit with given 1D index of
+// layer send to the unit of layer recv: its SynInsp Vals are updated every
+// sleep cycle and training trial, and traced in the SynInspPlot -- the
+// NetView also shows the r. synapse variables of the receiving unit (see
+// ViewSynapses)
+func (ss *Sim) InspectSynapse(send string, sendUnit int, recv string, recvUnit int) error {
+	if err := ss.SynInsp.Set(ss.Net, send, sendUnit, recv, recvUnit); err != nil {
+		return err
+	}
+	if plt := ss.SynInspPlot; plt != nil {
+		plt.Params.Title = "Synapse " + ss.SynInsp.Name()
+		plt.Params.XAxisCol = "Step"
+		plt.SetTable(ss.SynInsp.Trace)
+		// order of params: on, fixMin, min, fixMax, max
+		for _, v := range leabra.SynapseVars {
+			plt.SetColParams(v, false, true, 0, false, 0)
+		}
+		for _, v := range []string{"Wt", "LWt", "Cai", "Effwt"} {
+			plt.SetColParams(v, true, true, 0, true, 1)
+		}
+		plt.SetColParams("DWt", true, false, 0, false, 0)
+		plt.SetColParams("Cycle", false, true, 0, false, 0)
+		plt.Update()
+	}
+	return ss.ViewSynapses(recv, recvUnit)
+}
+
 // TODO SleepCycInit handles all initialization at start of new sleep trials, including computing
 // netinput scaling from running average activation etc.
 // Added by DH
@@ -434,7 +736,13 @@ func (ss *Sim) SleepCycInit() {
 	//ss.SetInBackPrjnOff(false)
 
 	// Set the parameters
+	ss.WakeParams = ss.Net.SnapshotParams()
+	ss.Sleeping = true
 	ss.SetParamsSet("Sleep", "", true)
+	if ss.SlpParamSet != "" {
+		ss.SetParamsSet(ss.SlpParamSet, "", true)
+	}
+	ss.Live.Reapply(ss.Net, ss, true, ss.LogSetParams)
 
 	ss.Net.Sleep(&ss.Time)
 
@@ -449,10 +757,15 @@ func (ss *Sim) SleepCycInit() {
 				continue
 			}
 			//	fmt.Println("Layer: %v, Neuron: %d, Original activation: %d", ly.Label(), ni, nrn.Act)
-			nrn.Act = rand.Float32()
+			nrn.Act = ss.Seeds.Rand(leabra.SeedSleepInit).Float32()
 			//fmt.Println("Layer: %v, Neuron: %d, Random activation: %d", ly.Label(), ni, nrn.Act)
 		}
 	}
+	if ss.ReplayBuf.On {
+		if err := ss.ReplayBuf.Seed(ss.Net, ss.Seeds.Rand(leabra.SeedSleepInit)); err == nil {
+			fmt.Printf("Sleep seeded with the wake trial: %v\n", ss.ReplayBuf.Seeded)
+		}
+	}
 	fmt.Println("I reset the network layers! Hope everything is still fine....")
 	// Set all the parameters to sleep mode - need to replicate the SRAvgCaiSynDepConSpec file from the older version
 	// TODO Not yet done.
@@ -482,8 +795,14 @@ func (ss *Sim) BackToWake() {
 	// Turn the back prjn from hidden to input off.
 	//ss.SetInBackPrjnOff(true)
 
-	// Set the parameters
-	ss.SetParamsSet("Base", "", true)
+	// Set the parameters: restoring the snapshot reverts any params set in
+	// sleep that the regular params do not set
+	ss.Sleeping = false
+	if ss.WakeParams != nil {
+		ss.Net.RestoreParams(ss.WakeParams)
+		ss.WakeParams = nil
+	}
+	ss.ApplyParamSets()
 
 	// If Inhibition oscillation is on, set it back to base
 	if ss.InhibOscil {
@@ -516,9 +835,17 @@ func (ss *Sim) AlphaCyc(state string) {
 	}
 	ss.Net.AlphaCycInit()
 	ss.Time.AlphaCycStart()
+	ss.SettleRT.Init()
+	ss.EarlyStop.Init()
+	ss.MarkActs(state)
 	for qtr := 0; qtr < 4; qtr++ {
 		for cyc := 0; cyc < ss.Time.CycPerQtr; cyc++ {
 			ss.Net.Cycle(&ss.Time, false)
+			ss.Net.SyncFromDevice() // for the cycle recorders and logs
+			ss.ActStream.RecordCycle(ss.Net, &ss.Time)
+			ss.ActDump.RecordCycle(ss.Net, &ss.Time)
+			ss.SettleRT.Cycle(ss.Net, &ss.Time)
+			stop := ss.EarlyStop.Cycle(ss.Net, &ss.Time)
 			//			ss.Net.Cycle(&ss.Time, true) // For syndep
 			if state == "test" {
 				ss.LogTstCyc(ss.TstCycLog, ss.Time.Cycle)
@@ -534,6 +861,13 @@ func (ss *Sim) AlphaCyc(state string) {
 					}
 				}
 			}
+			if stop {
+				break
+			}
+		}
+		if ss.Watchdog.On && ss.Watchdog.Check(ss.Net) {
+			ss.WatchdogTrip(state)
+			return
 		}
 		ss.Net.QuarterFinal(&ss.Time)
 		ss.Time.QuarterInc()
@@ -550,13 +884,22 @@ func (ss *Sim) AlphaCyc(state string) {
 	}
 
 	if state == "train" {
+		ss.DWtStats.Begin()
 		ss.Net.DWt()
 		ss.Net.WtFmDWt()
+		ss.DWtStats.End("Wake")
+		ss.SynInsp.Update("Train", ss.Time.Cycle)
+		if ss.SynInsp.On {
+			ss.SynInspPlot.GoUpdate()
+		}
 	}
 	if ss.ViewOn && viewUpdt == leabra.AlphaCycle {
 		ss.UpdateView(state)
 	}
 	if state == "test" {
+		if ss.TstCycLog.Rows > ss.Time.Cycle { // stopped early: drop the last trial's later cycles
+			ss.TstCycLog.SetNumRows(ss.Time.Cycle)
+		}
 		ss.TstCycPlot.GoUpdate() // make sure up-to-date at end
 	}
 }
@@ -600,53 +943,104 @@ func (ss *Sim) SleepCyc(WakeReplay bool) {
 	//	lrnAfrCyc := 0
 	//	lastCycSinCrit := 0
 
-	viewUpdt := ss.SleepUpdt
+	ss.SleepStart()
+	for cyc := 0; cyc < ss.MaxSlpCyc; cyc++ {
+		if !ss.SleepCycStep(cyc) {
+			break
+		}
+	}
+	ss.SleepEnd()
+}
+
+// SleepStart starts a sleep trial: the sleep params and random activations
+// of SleepCycInit, and the analyses of the trial
+func (ss *Sim) SleepStart() {
 	ss.SleepCycInit()
 	fmt.Println("Sleep mode officially starts here.")
 	ss.Time.SleepCycStart()
-	for cyc := 0; cyc < ss.MaxSlpCyc; cyc++ {
-		// Need to init the network here. How? Don't know yet. It was the SetToSleep program in Anna's version.
-		// Need to set the network to sleep mode, meaning set the input and output to be "hidden"
-		//	fmt.Println("%d real sleep cyc. Wish me luck!", cyc)
-		if (cyc+1)%10 == 0 {
-			ss.Net.InitGInc()
-		}
-		if ss.InhibOscil {
-			ss.Net.InhibOscil(&ss.Time, cyc)
-		}
+	ss.MarkActs("sleep")
+	ss.ReplayOut.Reset()
+	ss.ReplayHid.Reset()
+	ss.Dwell.Reset()
+	ss.DWtStats.Begin()
+	if ss.DashAddr != "" {
+		ss.Dash.ResetPlot("Sleep LaySim")
+		ss.Dash.ResetPlot("Sleep Gi")
+	}
+}
 
-		// Run one sleep cycle
-		ss.Net.Cycle(&ss.Time, true)
-		// Logging the SlpCycLog
-		ss.LogSlpCyc(ss.SlpCycLog, ss.Time.Cycle)
-		// Mark plus or minus phase
+// SleepCycStep runs given cycle of a sleep trial -- returns false if the
+// trial is to stop, because the Watchdog found an instability
+func (ss *Sim) SleepCycStep(cyc int) bool {
+	viewUpdt := ss.SleepUpdt
+	// Need to init the network here. How? Don't know yet. It was the SetToSleep program in Anna's version.
+	// Need to set the network to sleep mode, meaning set the input and output to be "hidden"
+	//	fmt.Println("%d real sleep cyc. Wish me luck!", cyc)
+	if (cyc+1)%10 == 0 {
+		ss.Net.InitGInc()
+	}
+	if ss.InhibOscil {
+		ss.Net.InhibOscil(&ss.Time, cyc)
+	}
 
-		// Forward the cycle timer
-		ss.Time.CycleInc()
-		if ss.ViewOn {
-			switch viewUpdt {
-			case leabra.Cycle:
-				//			fmt.Scanln()
+	// Run one sleep cycle
+	ss.Net.Cycle(&ss.Time, true)
+	ss.Net.SyncFromDevice() // for the cycle recorders and logs
+	ss.ActStream.RecordCycle(ss.Net, &ss.Time)
+	ss.ActDump.RecordCycle(ss.Net, &ss.Time)
+	ss.ReplayOut.Decode(ss.Net)
+	ss.ReplayHid.Decode(ss.Net)
+	ss.Dwell.Cycle(ss.Net)
+	ss.SynInsp.Update("Sleep", ss.Time.Cycle)
+	// Logging the SlpCycLog
+	ss.LogSlpCyc(ss.SlpCycLog, ss.Time.Cycle)
+	// Mark plus or minus phase
+
+	// Forward the cycle timer
+	ss.Time.CycleInc()
+	if ss.ViewOn {
+		switch viewUpdt {
+		case leabra.Cycle:
+			//			fmt.Scanln()
+			ss.UpdateView("sleep")
+		case leabra.FastSpike:
+			if (cyc+1)%10 == 0 {
+				//					fmt.Println("Should be seeing some flashing in the netview at this point.")
+				ss.UpdateView("sleep")
+				//ss.MonSlpCyc()
+			}
+		case leabra.Quarter:
+			if (cyc+1)%25 == 0 {
+				//				fmt.Println("Should be seeing some flashing in the netview at this point.")
 				ss.UpdateView("sleep")
-			case leabra.FastSpike:
-				if (cyc+1)%10 == 0 {
-					//					fmt.Println("Should be seeing some flashing in the netview at this point.")
-					ss.UpdateView("sleep")
-					//ss.MonSlpCyc()
-				}
-			case leabra.Quarter:
-				if (cyc+1)%25 == 0 {
-					//				fmt.Println("Should be seeing some flashing in the netview at this point.")
-					ss.UpdateView("sleep")
-				}
-			case leabra.Phase:
-				if (cyc+1)%100 == 0 {
-					//			fmt.Println("Should be seeing some flashing in the netview at this point.")
-					ss.UpdateView("sleep")
-				}
 			}
+		case leabra.Phase:
+			if (cyc+1)%100 == 0 {
+				//			fmt.Println("Should be seeing some flashing in the netview at this point.")
+				ss.UpdateView("sleep")
+			}
+		}
+	}
+	// In the AlphaCyc(), we have quarters, but during sleep, I did not add quarters - maybe later?
+	if ss.Watchdog.On && (cyc+1)%ss.Time.CycPerQtr == 0 && ss.Watchdog.Check(ss.Net) {
+		ss.WatchdogTrip("sleep")
+		return false
+	}
+	return true
+}
+
+// SleepEnd ends a sleep trial, logging the analyses of the trial
+func (ss *Sim) SleepEnd() {
+	ss.DWtStats.End("Sleep")
+	row := ss.Dwell.Log(ss.TrainEnv.Run.Cur, ss.TrainEnv.Epoch.Cur, ss.SleepEnv.Trial.Cur)
+	if ss.DwellFile != nil {
+		if row == 0 {
+			ss.Dwell.Results.WriteCSVHeaders(ss.DwellFile, etable.Tab)
+		}
+		ss.Dwell.Results.WriteCSVRow(ss.DwellFile, row, etable.Tab, true)
+		if ss.LogWindow > 0 {
+			leabra.TrimRows(ss.Dwell.Results, ss.LogWindow)
 		}
-		// In the AlphaCyc(), we have quarters, but during sleep, I did not add quarters - maybe later?
 	}
 	//ss.Net.MonChge(&ss.Time)
 	if ss.ViewOn {
@@ -656,6 +1050,41 @@ func (ss *Sim) SleepCyc(WakeReplay bool) {
 	}
 }
 
+// WatchdogTrip handles the instability found by the Watchdog in given state:
+// dumps the offending state and recent param changes to the _watchdog.txt
+// diagnostics file, and stops the run -- in sleep, only the current sleep
+// trial is halted, by the caller
+func (ss *Sim) WatchdogTrip(state string) {
+	ctxt := state + " " + strings.Join(strings.Fields(ss.Counters(state)), " ") + fmt.Sprintf(" Cycle: %d", ss.Time.Cycle)
+	fnm := strings.TrimSuffix(ss.LogFileName("watchdog"), ".csv") + ".txt"
+	fmt.Printf("Watchdog: %d unstable values in %v -- see: %v\n", ss.Watchdog.NProblems, ctxt, fnm)
+	ss.Watchdog.Dump(ss.Net, fnm, ctxt, ss.RecentParamChanges(20))
+	if state != "sleep" {
+		ss.Stop()
+	}
+}
+
+// RecentParamChanges returns up to n of the most recent param values
+// applied that changed a value (from ParamRecs), and up to n of the most
+// recent live edits, as strings
+func (ss *Sim) RecentParamChanges(n int) []string {
+	var chs []string
+	for i := len(ss.ParamRecs) - 1; i >= 0 && len(chs) < n; i-- {
+		rec := &ss.ParamRecs[i]
+		if rec.Val == rec.Prev {
+			continue
+		}
+		chs = append(chs, fmt.Sprintf("%v %v %v: %v -> %v", rec.Set, rec.Obj, rec.Path, rec.Prev, rec.Val))
+	}
+	nlive := 0
+	for i := len(ss.Live.Log) - 1; i >= 0 && nlive < n; i-- {
+		ed := &ss.Live.Log[i]
+		chs = append(chs, fmt.Sprintf("Live %v %v %v: %v -> %v (%v)", ed.Time, ed.Obj, ed.Path, ed.Prev, ed.Val, ed.Ctxt))
+		nlive++
+	}
+	return chs
+}
+
 // ApplyInputs applies input patterns from given environment.
 // It is good practice to have this be a separate method with appropriate
 // args so that it can be used for various different contexts
@@ -674,6 +1103,11 @@ func (ss *Sim) ApplyInputs(en env.Env) {
 	inPats_In := en.State(inLay.Nm)
 	inPats_Bla_Ne := en.State(blaNeInLay.Nm)
 	inPats_Bla_Po := en.State(blaPoInLay.Nm)
+	if ss.CueLevel >= 0 { // pattern-completion test
+		inPats_In = ss.CuePats(inLay.Nm, inPats_In)
+		inPats_Bla_Ne = ss.CuePats(blaNeInLay.Nm, inPats_Bla_Ne)
+		inPats_Bla_Po = ss.CuePats(blaPoInLay.Nm, inPats_Bla_Po)
+	}
 	if (inPats_In != nil) || (inPats_Bla_Ne != nil) || (inPats_Bla_Po != nil) {
 		inLay.ApplyExt(inPats_In)
 		blaNeInLay.ApplyExt(inPats_Bla_Ne)
@@ -687,6 +1121,83 @@ func (ss *Sim) ApplyInputs(en env.Env) {
 		blaNeOutLay.ApplyExt(outPats_Bla_Ne)
 		blaPoOutLay.ApplyExt(outPats_Bla_Po)
 	}
+	for _, mod := range ss.Modal.OtherMods() {
+		modPats := en.State(mod)
+		if ss.CueLevel >= 0 {
+			modPats = ss.CuePats(mod, modPats)
+		}
+		if modPats != nil {
+			ss.Net.LayerByName(mod).(*leabra.Layer).ApplyExt(modPats)
+		}
+	}
+	if ss.Context.On {
+		if se, ok := en.(*lenv.SeqEnv); ok && se.SeqStart {
+			ss.Context.Reset()
+		}
+		ss.Context.Apply(ss.Net)
+	}
+}
+
+// ConfigSalience sets the salience of each of the patterns, from their
+// Valence and Arousal columns, or else their Ne and Po inputs -- the
+// salience channel is turned On if the patterns have a Valence column
+func (ss *Sim) ConfigSalience() {
+	if ss.Pats.ColByName("Valence") != nil {
+		ss.Salience.On = true
+	}
+	ss.Salience.SetTable(ss.Pats, "Name")
+}
+
+// SalienceInputEnv returns the environment that the inputs of the item with
+// given name are applied from: given environment wrapped by the Salience
+// channel if it is On
+func (ss *Sim) SalienceInputEnv(en env.Env, name string) env.Env {
+	ss.Salience.SetCur(name)
+	if !ss.Salience.On {
+		return en
+	}
+	ss.Salience.Env = en
+	return &ss.Salience
+}
+
+// ConfigCatStats configures the per-category test stats: the category of
+// each item is in the Cat column of the patterns if present, or otherwise
+// its Salience category: Neg or Pos for items with a negative (Ne) or
+// positive (Po) valence, and Neutral for the rest
+func (ss *Sim) ConfigCatStats() {
+	emoFun := func(row int) string {
+		return ss.Salience.Of(ss.Pats.CellString("Name", row)).Cat()
+	}
+	ss.CatStats.SetTags(ss.Pats, "Name", "Cat", emoFun)
+	ss.CatStats.Stats = []leabra.CatStat{
+		{Name: "PctCor", Fun: func(ix *etable.IdxView) float64 {
+			return agg.PropIf(ix, "SSE", func(idx int, val float64) bool { return val == 0 })[0]
+		}},
+		{Name: "SSE", Col: "SSE", Agg: agg.AggMean},
+		{Name: "CosDiff", Col: "CosDiff", Agg: agg.AggMean},
+		{Name: "RT", Col: "RT", Agg: agg.AggMean},
+	}
+	ss.StudyStats.Stats = nil
+	ss.StudyStats.Tags = nil
+	if ss.OneShot.On {
+		ss.StudyStats.Stats = ss.CatStats.Stats
+		ss.StudyStats.Tags = []string{leabra.Studied, leabra.Unstudied}
+	}
+	ss.GenStats.Stats = nil
+	ss.GenStats.Tags = nil
+	if ss.GenTest.On {
+		ss.GenStats.Stats = ss.CatStats.Stats
+		ss.GenStats.Tags = leabra.GenTypes
+	}
+}
+
+// CuePats returns the input pattern for given layer degraded at the current
+// CueLevel, if it is one of the PatComp.Layers
+func (ss *Sim) CuePats(lnm string, pat etensor.Tensor) etensor.Tensor {
+	if pat == nil || !ss.PatComp.IsCue(lnm) {
+		return pat
+	}
+	return ss.PatComp.Degrade(ss.CueLevel, pat, ss.Seeds.Rand(leabra.SeedCueDegrade))
 }
 
 // TODO SleepTrial runs one trial of sleep
@@ -707,12 +1218,83 @@ func (ss *Sim) SleepTrial() {
 		return
 	}
 	//fmt.Println("I survived the mysterious counters... So what is next?")
-	ss.SleepCyc(true)        // Need to implement this
+	ss.SleepCyc(true) // Need to implement this
+	ss.SleepTrialEnd()
+}
+
+// SleepTrialEnd ends a sleep trial after its cycles: stats, and back to wake
+func (ss *Sim) SleepTrialEnd() {
 	ss.SlpCycPlot.GoUpdate() // make sure up-to-date at end
-	ss.TrialStats(true)      // I think this is necessary, but need to check.
+	ss.SlpGiPlot.GoUpdate()
+	ss.Lesions.Apply(ss.Net, "SleepTrial", ss.SleepEnv.Trial.Cur)
+	ss.TrialStats(true) // I think this is necessary, but need to check.
 	ss.BackToWake()
 }
 
+// EnterSleep enters sleep for stepping through a sleep trial one cycle at a
+// time with SleepStepCyc: steps the SleepEnv to the next trial, and starts
+// the trial (SleepStart) -- ExitSleep ends it
+func (ss *Sim) EnterSleep() {
+	if ss.SlpStepping {
+		return
+	}
+	ss.Net.InitExt()
+	ss.SleepEnv.Step()
+	if _, _, chg := ss.SleepEnv.Counter(env.Epoch); chg {
+		ss.SleepEnv.Step() // new sleep epoch: first trial of it
+	}
+	ss.SleepStart()
+	ss.SlpStepping = true
+	ss.SlpCyc = 0
+	if ss.ViewOn {
+		ss.UpdateView("sleep")
+	}
+}
+
+// SleepStepCyc runs the next cycle of the sleep trial being stepped,
+// entering sleep if needed -- exits sleep at the end of the trial (after
+// MaxSlpCyc cycles, or if the Watchdog stops it)
+func (ss *Sim) SleepStepCyc() {
+	if !ss.SlpStepping {
+		ss.EnterSleep()
+	}
+	ok := ss.SleepCycStep(ss.SlpCyc)
+	ss.SlpCyc++
+	if ss.ViewOn && ss.SleepUpdt != leabra.Cycle {
+		ss.UpdateView("sleep") // each step is shown
+	}
+	if !ok || ss.SlpCyc >= ss.MaxSlpCyc {
+		ss.ExitSleep()
+	}
+}
+
+// ExitSleep ends the sleep trial being stepped (SleepEnd and SleepTrialEnd),
+// going back to wake
+func (ss *Sim) ExitSleep() {
+	if !ss.SlpStepping {
+		return
+	}
+	ss.SleepEnd()
+	ss.SleepTrialEnd()
+	ss.SlpStepping = false
+	ss.SlpCyc = 0
+}
+
+// SleepStepTrial runs the rest of the sleep trial being stepped, or else a
+// full sleep trial -- for the toolbar, run in a goroutine
+func (ss *Sim) SleepStepTrial() {
+	if ss.SlpStepping {
+		for ss.SlpStepping && !ss.StopNow {
+			ss.SleepStepCyc()
+		}
+		ss.StopNow = false
+	} else {
+		ss.Net.InitExt()
+		ss.SleepTrial()
+	}
+	ss.Stopped()
+}
+
 // TrainTrial runs one trial of training using TrainEnv
 func (ss *Sim) TrainTrial() {
 	ss.TrainEnv.Step() // the Env encapsulates and manages all counter state
@@ -722,13 +1304,23 @@ func (ss *Sim) TrainTrial() {
 	epc, _, chg := ss.TrainEnv.Counter(env.Epoch)
 	if chg {
 		ss.LogTrnEpc(ss.TrnEpcLog)
+		if ss.ABAC.On && ss.ABAC.ListAt(epc) != ss.ABAC.List {
+			ss.ABAC.List = ss.ABAC.ListAt(epc)
+			ss.TrainEnv.Table = etable.NewIdxView(ss.ABAC.Table(ss.ABAC.List))
+			fmt.Printf("Run: %d\tEpoch: %d\ttraining list: %v\n", ss.TrainEnv.Run.Cur, epc, ss.ABAC.List)
+		}
+		if ss.Curriculum.On && ss.Curriculum.Update(ss.EpcPctCor) {
+			fmt.Printf("Run: %d\tEpoch: %d\tcurriculum advanced to tier: %d\n", ss.TrainEnv.Run.Cur, epc, ss.Curriculum.Tier)
+		}
 		if ss.ViewOn && ss.TrainUpdt > leabra.AlphaCycle {
 			ss.UpdateView("train")
 		}
-		if epc%ss.TestInterval == 0 { // note: epc is *next* so won't trigger first time
+		if ss.OneShot.On {
+			ss.OneShotTests()
+		} else if epc%ss.TestInterval == 0 { // note: epc is *next* so won't trigger first time
 			ss.TestAll()
 		}
-		if epc >= ss.MaxEpcs { // done with training..
+		if epc >= ss.MaxEpcs || ss.OneShot.On || (ss.ABAC.On && ss.ABAC.Done(epc)) { // done with training..
 			ss.RunEnd()
 			if ss.TrainEnv.Run.Incr() { // we are done!
 				ss.StopNow = true
@@ -738,6 +1330,10 @@ func (ss *Sim) TrainTrial() {
 				return
 			}
 		}
+		ss.ApplyCondSets(epc)
+		ss.ApplyAnneal(epc)
+		ss.Lesions.Apply(ss.Net, "Epoch", epc)
+		rand.Seed(ss.Seeds.EpochSeed(leabra.SeedEnvShuffle, epc)) // known state for SaveCheckpoint
 	}
 
 	// TODO Added by DH: Here should be the good place to check if we should start a sleep
@@ -746,25 +1342,57 @@ func (ss *Sim) TrainTrial() {
 			// Save trained weights first
 			fnm := ss.WeightsFileName()
 			fmt.Printf("Saving Weights to: %v\n", fnm)
-			ss.Net.SaveWtsJSON(gi.FileName(fnm))
+			ss.Net.SaveWts(gi.FileName(fnm))
 			ss.Net.InitExt() // clear any existing inputs -- not strictly necessary if always
 			//fmt.Println("I stepped into the sleeping black hole...")
 			ss.SleepTrial()
 		}
 	}
 
-	ss.ApplyInputs(&ss.TrainEnv)
+	ss.ApplyInputs(ss.TrainInputEnv())
+	restore := func() {}
+	if ss.Salience.On && ss.Salience.AroLrate != 0 {
+		restore = leabra.ScaleLrates(ss.Net, ss.Salience.LrateMod())
+	}
 	ss.AlphaCyc("train") // train
-	ss.TrialStats(true)  // accumulate
+	restore()
+	if ss.Context.On {
+		ss.Context.Update(ss.Net)
+	}
+	ss.TrialStats(true) // accumulate
+	if ss.ReplayBuf.On {
+		ss.ReplayBuf.Add(ss.TrainEnv.TrialName, &ss.TrainEnv, ss.TrlSSE)
+	}
+	if len(ss.TrnPhsLog.Cols) > 0 {
+		ss.Logs.LogRow("Train", leabra.Trial, ss.TrainEnv.Trial.Cur)
+	}
+	if ss.MetricsAddr != "" {
+		ss.Metrics.Set("run", float64(ss.TrainEnv.Run.Cur))
+		ss.Metrics.Set("epoch", float64(ss.TrainEnv.Epoch.Cur))
+		ss.Metrics.Set("trial", float64(ss.TrainEnv.Trial.Cur))
+		ss.Metrics.Set("trial_sse", ss.TrlSSE)
+	}
 }
 
 // RunEnd is called at the end of a run -- save weights, record final log, etc here
 func (ss *Sim) RunEnd() {
 	ss.LogRun(ss.RunLog)
+	if ss.TBoard != nil {
+		ss.TBoard.Close()
+		ss.TBoard = nil
+	}
+	if ss.SaveReps {
+		fnm := ss.LogFileName(fmt.Sprintf("reps_%03d", ss.TrainEnv.Run.Cur))
+		fmt.Printf("Saving hidden representation projection to: %v\n", fnm)
+		ss.RepSpace.SaveCSV(gi.FileName(fnm))
+	}
 	if ss.SaveWts {
 		fnm := ss.WeightsFileName()
 		fmt.Printf("Saving Weights to: %v\n", fnm)
-		ss.Net.SaveWtsJSON(gi.FileName(fnm))
+		ss.Net.SaveWts(gi.FileName(fnm))
+	}
+	if ss.SaveSQLite {
+		ss.SaveRunSQLite()
 	}
 }
 
@@ -772,14 +1400,269 @@ func (ss *Sim) RunEnd() {
 // for the new run value
 func (ss *Sim) NewRun() {
 	run := ss.TrainEnv.Run.Cur
+	ss.Seeds.Master = ss.RndSeed
+	ss.Seeds.Init(run)
+	rand.Seed(ss.Seeds.RunSeed(leabra.SeedEnvShuffle, run)) // envs shuffle with the global generator
+	ss.ApplySplit()
+	ss.ApplySetSize()
+	ss.ConfigABAC()
+	ss.ConfigTrainSched()
+	ss.ConfigCurriculum()
+	ss.ConfigOneShot()
+	ss.ConfigGenTest()
+	ss.ConfigStream()
 	ss.TrainEnv.Init(run)
 	ss.TestEnv.Init(run)
 	ss.SleepEnv.Init(run)
+	ss.SetEnvParams()
 	ss.Time.Reset()
+	ss.Lesions.Undo(ss.Net)
+	ss.Lesions.Reset()
+	ss.ReplayBuf.Reset()
+	ss.Net.SeedLayerRnd(ss.Seeds.RunSeed(leabra.SeedNoise, run)) // noise is reproducible regardless of threading
+	ss.Net.SeedPrjnRnd(ss.Seeds.RunSeed(leabra.SeedWtsInit, run))
+	if len(ss.RunSample.Params) > 0 {
+		ss.SampleRunParams(run)
+	} else if ss.RunParamSet != "" || len(ss.CondSets.ActiveSets()) > 0 { // revert to regular params + RunParamSet
+		ss.CondSets.Reset()
+		ss.ApplyParamSets()
+	}
 	ss.Net.InitWts()
+	ss.ApplyAnneal(0)
 	ss.InitStats()
 	ss.TrnEpcLog.SetNumRows(0)
 	ss.TstEpcLog.SetNumRows(0)
+	ss.RepSpace.Reset()
+	if ss.DashAddr != "" {
+		ss.Dash.ResetPlot("Train Epoch")
+		ss.Dash.ResetPlot("Test Epoch")
+	}
+	if ss.TBDir != "" {
+		ss.TBoard.Close()
+		ss.TBoard, _ = export.NewTBWriter(filepath.Join(ss.TBDir, ss.RunName(), fmt.Sprintf("run_%03d", run)))
+	}
+	rand.Seed(ss.Seeds.EpochSeed(leabra.SeedEnvShuffle, 0)) // known state for SaveCheckpoint
+}
+
+// ApplySplit splits the patterns for the current run if Split.Props are
+// set, with TrainEnv training on the Train split and TestEnv testing the
+// SplitTest split, stratified by the CatStats categories if Split.Strat --
+// the split is saved to a _split_<run>.csv file if SaveSplit
+func (ss *Sim) ApplySplit() {
+	if !ss.Split.On() {
+		return
+	}
+	tags := make([]string, ss.Pats.Rows)
+	nc := ss.Pats.ColByName("Name")
+	for row := range tags {
+		tags[row] = ss.CatStats.Tag(nc.StringVal1D(row))
+	}
+	if err := ss.Split.Split(ss.Pats, tags, ss.Seeds.Rand(leabra.SeedDataSplit)); err != nil {
+		return
+	}
+	tst := ss.Split.View(ss.SplitTest)
+	if tst == nil {
+		log.Printf("ApplySplit: no split named: %v -- testing the Test split\n", ss.SplitTest)
+		tst = ss.Split.View("Test")
+	}
+	ss.TrainEnv.Table = ss.Split.View("Train")
+	ss.TestEnv.Table = tst
+	if ss.SaveSplit {
+		fnm := ss.LogFileName(fmt.Sprintf("split_%03d", ss.TrainEnv.Run.Cur))
+		fmt.Printf("Saving split of the patterns to: %v\n", fnm)
+		ss.Split.SaveCSV("Name", gi.FileName(fnm))
+	}
+}
+
+// ApplySetSize subsamples the training patterns for the current run to its
+// SetSize if Sizes are set, stratified by the CatStats categories if
+// SetSize.Strat -- TestEnv tests the same subsample unless the patterns
+// are split
+func (ss *Sim) ApplySetSize() {
+	if !ss.SetSize.On() {
+		return
+	}
+	run := ss.TrainEnv.Run.Cur
+	trn := ss.TrainEnv.Table
+	if !ss.Split.On() {
+		trn = etable.NewIdxView(ss.Pats)
+	}
+	tags := make([]string, ss.Pats.Rows)
+	nc := ss.Pats.ColByName("Name")
+	for row := range tags {
+		tags[row] = ss.CatStats.Tag(nc.StringVal1D(row))
+	}
+	sub, err := ss.SetSize.Subsample(trn, tags, ss.SetSize.Of(run), ss.Seeds.Rand(leabra.SeedSetSize))
+	if err != nil {
+		return
+	}
+	ss.TrainEnv.Table = sub
+	if !ss.Split.On() {
+		ss.TestEnv.Table = etable.NewIdxView(ss.Pats)
+		ss.TestEnv.Table.Idxs = append([]int{}, sub.Idxs...)
+	}
+}
+
+// ConfigTrainSched sets the TrainEnv to order the trials of each epoch by
+// the TrainSched if it is On, with the CatStats category of each item as
+// its group
+func (ss *Sim) ConfigTrainSched() {
+	if !ss.TrainSched.On() {
+		ss.TrainEnv.EpochOrder = nil
+		return
+	}
+	ix := ss.TrainEnv.Table
+	nc := ix.Table.ColByName("Name")
+	groups := make([]string, ix.Len())
+	for i := range groups {
+		groups[i] = ss.CatStats.Tag(nc.StringVal1D(ix.Idxs[i]))
+	}
+	ss.TrainSched.SetGroups(groups)
+	ss.TrainEnv.EpochOrder = func(epc int) []int {
+		return ss.TrainSched.Order(epc, nil) // global generator, seeded for the run
+	}
+}
+
+// ConfigCurriculum sets the TrainEnv to train the items of the current
+// Curriculum tier each epoch if it is On, starting from the first tier,
+// in place of any TrainSched
+func (ss *Sim) ConfigCurriculum() {
+	if !ss.Curriculum.On {
+		return
+	}
+	ss.Curriculum.Init()
+	if err := ss.Curriculum.SetDifficulty(ss.TrainEnv.Table); err != nil {
+		ss.Curriculum.On = false
+		return
+	}
+	ss.TrainEnv.EpochOrder = func(epc int) []int {
+		return ss.Curriculum.Order(nil) // global generator, seeded for the run
+	}
+}
+
+// ConfigABAC makes the AB and AC lists of the ABAC paradigm for this run
+// from the patterns if it is On, starting training with the AB list
+func (ss *Sim) ConfigABAC() {
+	if !ss.ABAC.On {
+		return
+	}
+	if err := ss.ABAC.Config(ss.Pats, ss.Seeds.Rand(leabra.SeedLists)); err != nil {
+		ss.ABAC.On = false
+		return
+	}
+	ss.TrainEnv.Table = etable.NewIdxView(ss.ABAC.AB)
+	ss.TestEnv.Table = etable.NewIdxView(ss.ABAC.AB)
+}
+
+// ConfigOneShot chooses the items studied in this run of the OneShot
+// protocol if it is On, and sets the TrainEnv to present each of them Reps
+// times in the study epoch, in place of any TrainSched or Curriculum
+func (ss *Sim) ConfigOneShot() {
+	if !ss.OneShot.On {
+		return
+	}
+	ix := ss.TrainEnv.Table
+	nc := ix.Table.ColByName("Name")
+	names := make([]string, ix.Len())
+	for i := range names {
+		names[i] = nc.StringVal1D(ix.Idxs[i])
+	}
+	studied := ss.OneShot.Select(names, ss.Seeds.Rand(leabra.SeedStudy))
+	ss.StudyStats.SetTags(ss.Pats, "Name", "", func(row int) string {
+		return ss.OneShot.Status(nc.StringVal1D(row))
+	})
+	ss.StudyStats.Tags = []string{leabra.Studied, leabra.Unstudied} // always both, as in the log
+	ss.TrainEnv.EpochOrder = func(epc int) []int {
+		return ss.OneShot.Order(studied, nil) // global generator, seeded for the run
+	}
+}
+
+// ConfigGenTest makes the items of the generalization test of this run if
+// GenTest is On: the items trained by TrainEnv, recombinations of their
+// features and novel controls
+func (ss *Sim) ConfigGenTest() {
+	ss.GenTest.Table = nil
+	if !ss.GenTest.On {
+		return
+	}
+	ss.GenTest.Make(ss.TrainEnv.Table, ss.Seeds.Rand(leabra.SeedGenTest))
+}
+
+// OneShotTests ends the study phase of the OneShot protocol: all the items
+// are tested immediately if ImmTest, then after sleep if Sleep (the
+// delayed test)
+func (ss *Sim) OneShotTests() {
+	if ss.OneShot.ImmTest {
+		ss.OneShot.Phase = "Immediate"
+		ss.TestAll()
+	}
+	if ss.Sleep && !ss.StopNow {
+		ss.Net.InitExt()
+		ss.SleepTrial()
+	}
+	ss.OneShot.Phase = "Delayed"
+	ss.TestAll()
+	ss.OneShot.Phase = ""
+}
+
+// ConfigStream sets the TrainEnv to train on chunks of StreamChunk items
+// read in turn from the Stream, one chunk per epoch, if its Source is set,
+// in place of the patterns and of any TrainSched or Curriculum -- training
+// stops at the end of the stream unless Stream.Loop
+func (ss *Sim) ConfigStream() {
+	if ss.Stream.Source == "" {
+		return
+	}
+	ss.Stream.Nm = "TrainStream"
+	if ss.StreamChunk < 1 {
+		ss.StreamChunk = 1
+	}
+	ss.Stream.Schema = leabra.SchemaOf(ss.Pats)
+	ss.Stream.Init(ss.TrainEnv.Run.Cur)
+	buf := &etable.Table{}
+	buf.SetMetaData("name", "StreamPats")
+	buf.SetMetaData("desc", "Current chunk of the training stream")
+	buf.SetFromSchema(ss.Stream.Schema, ss.StreamChunk)
+	ix := etable.NewIdxView(buf)
+	if !ss.ReadStream(ix) {
+		return
+	}
+	ss.TrainEnv.Table = ix
+	first := true // the first chunk is read for TrainEnv.Init
+	ss.TrainEnv.EpochOrder = func(epc int) []int {
+		if !first {
+			if !ss.ReadStream(ix) {
+				ss.StopNow = true
+			}
+			ss.TrainEnv.ConfigSeqs()
+		}
+		first = false
+		if ss.TrainEnv.Sequential {
+			return append([]int{}, ix.Idxs...)
+		}
+		return rand.Perm(ix.Len()) // global generator, seeded for the run
+	}
+}
+
+// ReadStream reads the next chunk of the Stream into the table of given
+// view, setting the view to all of its rows -- returns false at the end of
+// the stream, when the table is left as is
+func (ss *Sim) ReadStream(ix *etable.IdxView) bool {
+	buf := ix.Table
+	nr := buf.Rows
+	buf.SetNumRows(ss.StreamChunk)
+	n, _ := ss.Stream.ReadRows(buf, ss.StreamChunk)
+	if n == 0 {
+		buf.SetNumRows(nr)
+		log.Printf("ReadStream: end of the training stream: %v after %d items\n", ss.Stream.Source, ss.Stream.NRead)
+		return false
+	}
+	buf.SetNumRows(n)
+	ix.Idxs = make([]int, n)
+	for i := range ix.Idxs {
+		ix.Idxs[i] = i
+	}
+	return true
 }
 
 // intializes the network properties
@@ -808,6 +1691,8 @@ func (ss *Sim) InitStats() {
 	ss.SumSSE = 0
 	ss.SumAvgSSE = 0
 	ss.SumCosDiff = 0
+	ss.SumRT = 0
+	ss.SumCycs = 0
 	ss.CntErr = 0
 	ss.FirstZero = -1
 	// clear rest just to make Sim look initialized
@@ -817,6 +1702,10 @@ func (ss *Sim) InitStats() {
 	ss.EpcAvgSSE = 0
 	ss.EpcPctErr = 0
 	ss.EpcCosDiff = 0
+	ss.TrlRT = 0
+	ss.EpcRT = 0
+	ss.TrlCycs = 0
+	ss.EpcCycs = 0
 	ss.AvgLaySim = 0
 }
 
@@ -829,10 +1718,21 @@ func (ss *Sim) TrialStats(accum bool) (sse, avgsse, cosdiff float64) {
 	outLay := ss.Net.LayerByName("Output").(*leabra.Layer)
 	ss.TrlCosDiff = float64(outLay.CosDiff.Cos)
 	ss.TrlSSE, ss.TrlAvgSSE = outLay.MSE(0.5) // 0.5 = per-unit tolerance -- right side of .5
+	ss.TrlRT = float64(ss.SettleRT.RT())
+	ss.TrlCycs = float64(ss.EarlyStop.NCyc)
+	if len(ss.ErrStats) > 0 {
+		es := outLay.ErrStats(0.5)
+		ss.TrlErrs = make([]float64, len(ss.ErrStats))
+		for i, nm := range ss.ErrStats {
+			ss.TrlErrs[i], _ = es.Stat(nm)
+		}
+	}
 	if accum {
 		ss.SumSSE += ss.TrlSSE
 		ss.SumAvgSSE += ss.TrlAvgSSE
 		ss.SumCosDiff += ss.TrlCosDiff
+		ss.SumRT += ss.TrlRT
+		ss.SumCycs += ss.TrlCycs
 		if ss.TrlSSE != 0 {
 			ss.CntErr++
 		}
@@ -872,7 +1772,7 @@ func (ss *Sim) Train() {
 	//ss.SetInBackPrjnOff(true)
 	for {
 		ss.TrainTrial()
-		if ss.StopNow {
+		if ss.StopNow || ss.IsInterrupted() {
 			break
 		}
 	}
@@ -898,11 +1798,271 @@ func (ss *Sim) Stopped() {
 	}
 }
 
-// SaveWeights saves the network weights -- when called with giv.CallMethod
-// it will auto-prompt for filename
-func (ss *Sim) SaveWeights(filename gi.FileName) {
-	ss.Net.SaveWtsJSON(filename)
-}
+// ConfigServer configures the commands of the control API served at
+// ServeAddr and GRPCAddr (see server.Server), so that experiments can be
+// orchestrated headless: init, train (epochs=N, default 1), sleep
+// (trials=N, default 1), test, which return the ServerStats after running,
+// stats, and weights, which returns the weights in the JSON format of SaveWts
+func (ss *Sim) ConfigServer() {
+	sv := &ss.Server
+	sv.Handle("init", func(args url.Values) (interface{}, error) {
+		ss.Init()
+		return ss.ServerStats(), nil
+	})
+	sv.Handle("train", func(args url.Values) (interface{}, error) {
+		n, err := server.ArgInt(args, "epochs", 1)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			ss.TrainEpoch()
+			if ss.StopNow { // all runs done
+				break
+			}
+		}
+		return ss.ServerStats(), nil
+	})
+	sv.Handle("sleep", func(args url.Values) (interface{}, error) {
+		n, err := server.ArgInt(args, "trials", 1)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			ss.Net.InitExt()
+			ss.SleepTrial()
+		}
+		return ss.ServerStats(), nil
+	})
+	sv.Handle("test", func(args url.Values) (interface{}, error) {
+		ss.TestAll()
+		return ss.ServerStats(), nil
+	})
+	sv.Handle("stats", func(args url.Values) (interface{}, error) {
+		return ss.ServerStats(), nil
+	})
+	sv.Handle("weights", func(args url.Values) (interface{}, error) {
+		var b bytes.Buffer
+		ss.Net.WriteWtsJSON(&b)
+		return json.RawMessage(b.Bytes()), nil
+	})
+}
+
+// ServerStats returns the current counters, the stats of the last training
+// epoch, and those of the last test (as Tst<Stat>), for the control API
+func (ss *Sim) ServerStats() map[string]interface{} {
+	st := map[string]interface{}{
+		"Run":        ss.TrainEnv.Run.Cur,
+		"Epoch":      ss.TrainEnv.Epoch.Cur,
+		"EpcSSE":     ss.EpcSSE,
+		"EpcPctCor":  ss.EpcPctCor,
+		"EpcCosDiff": ss.EpcCosDiff,
+		"FirstZero":  ss.FirstZero,
+	}
+	if dt := ss.TstEpcLog; dt.Rows > 0 {
+		for _, nm := range []string{"SSE", "PctCor", "CosDiff"} {
+			st["Tst"+nm] = dt.CellFloat(nm, dt.Rows-1)
+		}
+	}
+	return st
+}
+
+// SaveWeights saves the network weights -- when called with giv.CallMethod
+// it will auto-prompt for filename
+func (ss *Sim) SaveWeights(filename gi.FileName) {
+	ss.Net.SaveWts(filename)
+}
+
+// SaveProfile saves the profile log of time spent in each function for each
+// layer per epoch (recorded when Net.Profile is on) to a CSV file, and prints
+// a report of any times accumulated since the last epoch.
+func (ss *Sim) SaveProfile(filename gi.FileName) error {
+	fmt.Print(ss.Net.ProfileReport())
+	err := ss.ProfLog.SaveCSV(filename, etable.Tab, true)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	ss.SaveProvenance(string(filename))
+	return nil
+}
+
+// CheckpointFileName returns default current checkpoint file name
+func (ss *Sim) CheckpointFileName() string {
+	return ss.Net.Nm + "_" + ss.RunName() + "_" + ss.RunEpochName(ss.TrainEnv.Run.Cur, ss.TrainEnv.Epoch.Cur) + ".ckpt"
+}
+
+// SaveCheckpoint saves the complete training state (network, time, random
+// number streams, environments including their permutation orders, the
+// replay buffer, curriculum and list state, stats and train / run logs) so
+// that training can be resumed exactly with OpenCheckpoint.  Should be
+// called between trials.  The global random number generator is restored
+// to its state at the start of the epoch (see NewRun, TrainTrial), so the
+// resumed run is exact if nothing drew from it since, e.g., random lesions.
+func (ss *Sim) SaveCheckpoint(filename gi.FileName) error {
+	cp := leabra.NewCheckpoint(ss.Net, &ss.Time)
+	cp.RndSeed = ss.Seeds.EpochSeed(leabra.SeedEnvShuffle, ss.TrainEnv.Epoch.Cur)
+	lenv.AddSeqEnv(cp, &ss.TrainEnv)
+	cp.AddFixedTable(&ss.SleepEnv)
+	lenv.AddSeqEnv(cp, &ss.TestEnv)
+	cp.AddReplayBuf(&ss.ReplayBuf)
+	cp.AddSeeds(&ss.Seeds)
+	cp.Vals["CurTier"] = float64(ss.Curriculum.Tier)
+	cp.Vals["CurTierEpcs"] = float64(ss.Curriculum.TierEpcs)
+	cp.Vals["CurNCrit"] = float64(ss.Curriculum.NCrit)
+	cp.Strs["List"] = ss.ABAC.List
+	cp.Vals["SumSSE"] = ss.SumSSE
+	cp.Vals["SumAvgSSE"] = ss.SumAvgSSE
+	cp.Vals["SumCosDiff"] = ss.SumCosDiff
+	cp.Vals["SumRT"] = ss.SumRT
+	cp.Vals["SumCycs"] = ss.SumCycs
+	cp.Vals["CntErr"] = float64(ss.CntErr)
+	cp.Vals["FirstZero"] = float64(ss.FirstZero)
+	cp.Vals["EpcSSE"] = ss.EpcSSE
+	cp.Strs["RndSeed"] = strconv.FormatInt(ss.RndSeed, 10)
+	var b bytes.Buffer
+	ss.TrnEpcLog.WriteCSV(&b, etable.Tab, true)
+	cp.Strs["TrnEpcLog"] = b.String()
+	b.Reset()
+	ss.RunLog.WriteCSV(&b, etable.Tab, true)
+	cp.Strs["RunLog"] = b.String()
+	fmt.Printf("Saving Checkpoint to: %v\n", filename)
+	return cp.Save(filename)
+}
+
+// NotifyInterrupt sets Interrupted when an interrupt or terminate signal is
+// received, which stops the train or sleep command after the current trial
+// of what (e.g., "trial"), until the returned function is called -- a
+// second signal exits right away
+func (ss *Sim) NotifyInterrupt(what string) func() {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		if sig, ok := <-sigc; ok {
+			fmt.Printf("Received %v: stopping after the current %v -- again to exit now\n", sig, what)
+			signal.Stop(sigc)
+			atomic.StoreInt32(&ss.Interrupted, 1)
+		}
+	}()
+	return func() {
+		signal.Stop(sigc)
+		close(sigc)
+	}
+}
+
+// IsInterrupted returns true if an interrupt or terminate signal has been
+// received (see NotifyInterrupt)
+func (ss *Sim) IsInterrupted() bool {
+	return atomic.LoadInt32(&ss.Interrupted) != 0
+}
+
+// SaveInterrupted saves the weights and a checkpoint of the training state,
+// with an -interrupted suffix, when training is stopped by an interrupt or
+// terminate signal (see NotifyInterrupt), e.g., a cluster preemption, so
+// that it can be resumed with -checkpoint
+func (ss *Sim) SaveInterrupted() error {
+	if ss.ActDump.On {
+		ss.ActDump.WriteIndex()
+	}
+	wfnm := strings.TrimSuffix(ss.WeightsFileName(), ".wts") + "-interrupted.wts"
+	fmt.Printf("Saving Weights to: %v\n", wfnm)
+	if err := ss.Net.SaveWts(gi.FileName(wfnm)); err != nil {
+		log.Println(err)
+		return err
+	}
+	cfnm := strings.TrimSuffix(ss.CheckpointFileName(), ".ckpt") + "-interrupted.ckpt"
+	if err := ss.SaveCheckpoint(gi.FileName(cfnm)); err != nil {
+		return err
+	}
+	fmt.Printf("Resume training with: -checkpoint %v\n", cfnm)
+	return nil
+}
+
+// OpenCheckpoint restores the complete training state saved by SaveCheckpoint --
+// the network must already be configured with the same structure, and the
+// current params are applied as usual.
+func (ss *Sim) OpenCheckpoint(filename gi.FileName) error {
+	cp, err := leabra.OpenCheckpoint(filename)
+	if err != nil {
+		return err
+	}
+	if err = cp.Restore(ss.Net, &ss.Time); err != nil {
+		return err
+	}
+	ss.RndSeed, _ = strconv.ParseInt(cp.Strs["RndSeed"], 10, 64)
+	ss.Seeds.Master = ss.RndSeed
+	ss.RestoreEnvs(cp)
+	ss.SumSSE = cp.Vals["SumSSE"]
+	ss.SumAvgSSE = cp.Vals["SumAvgSSE"]
+	ss.SumCosDiff = cp.Vals["SumCosDiff"]
+	ss.SumRT = cp.Vals["SumRT"]
+	ss.SumCycs = cp.Vals["SumCycs"]
+	ss.CntErr = int(cp.Vals["CntErr"])
+	ss.FirstZero = int(cp.Vals["FirstZero"])
+	ss.EpcSSE = cp.Vals["EpcSSE"]
+	ss.Curriculum.Tier = int(cp.Vals["CurTier"])
+	ss.Curriculum.TierEpcs = int(cp.Vals["CurTierEpcs"])
+	ss.Curriculum.NCrit = int(cp.Vals["CurNCrit"])
+	if lg, has := cp.Strs["TrnEpcLog"]; has {
+		ss.TrnEpcLog.ReadCSV(strings.NewReader(lg), etable.Tab)
+	}
+	if lg, has := cp.Strs["RunLog"]; has {
+		ss.RunLog.ReadCSV(strings.NewReader(lg), etable.Tab)
+	}
+	fmt.Printf("Restored Checkpoint from: %v  Run: %d  Epoch: %d\n", filename, ss.TrainEnv.Run.Cur, ss.TrainEnv.Epoch.Cur)
+	ss.UpdateView("train")
+	return nil
+}
+
+// ResumeWts continues training from the weights in given file (e.g., saved
+// at the end of a run, or by SaveWeights) at given run and epoch: the
+// TrainEnv is set to them, with the ABAC list and Anneal params of the
+// epoch, while the rest of the training state (e.g., the env orders, replay
+// buffer, curriculum and logs) starts afresh -- OpenCheckpoint restores all
+// of it
+func (ss *Sim) ResumeWts(filename gi.FileName, run, epc int) error {
+	if run < 0 || run >= ss.MaxRuns || epc < 0 || epc >= ss.MaxEpcs {
+		err := fmt.Errorf("ResumeWts: run: %d must be < MaxRuns: %d and epoch: %d < MaxEpcs: %d", run, ss.MaxRuns, epc, ss.MaxEpcs)
+		log.Println(err)
+		return err
+	}
+	if run != ss.TrainEnv.Run.Cur {
+		ss.TrainEnv.Run.Cur = run
+		ss.NewRun()
+	}
+	if err := ss.Net.OpenWts(filename); err != nil {
+		return err
+	}
+	ss.TrainEnv.Epoch.Cur = epc
+	if ss.ABAC.On && ss.ABAC.ListAt(epc) != ss.ABAC.List {
+		ss.ABAC.List = ss.ABAC.ListAt(epc)
+		ss.TrainEnv.Table = etable.NewIdxView(ss.ABAC.Table(ss.ABAC.List))
+	}
+	ss.ApplyAnneal(epc)
+	fmt.Printf("Resuming from weights: %v  Run: %d  Epoch: %d\n", filename, run, epc)
+	return nil
+}
+
+// RestoreEnvs restores the state of all the environments, and the replay
+// buffer and the ABAC list trained, from given checkpoint -- the ABAC lists
+// are remade for the restored run, and then the random number streams of
+// the Seeds (e.g., sleep-init, augment, modal, test-noise, cue-degrade) are
+// restored to their saved state, while the env shuffling continues from the
+// global generator reseeded for the epoch (see SaveCheckpoint)
+func (ss *Sim) RestoreEnvs(cp *leabra.Checkpoint) {
+	if es, has := cp.Envs[ss.TrainEnv.Nm]; has {
+		ss.Seeds.Init(es.Run.Cur)
+	}
+	if ss.ABAC.On && cp.Strs["List"] != "" {
+		ss.ConfigABAC()
+		ss.ABAC.List = cp.Strs["List"]
+		ss.TrainEnv.Table = etable.NewIdxView(ss.ABAC.Table(ss.ABAC.List))
+	}
+	cp.RestoreSeeds(&ss.Seeds)
+	lenv.RestoreSeqEnv(cp, &ss.TrainEnv)
+	cp.RestoreFixedTable(&ss.SleepEnv)
+	lenv.RestoreSeqEnv(cp, &ss.TestEnv)
+	cp.RestoreReplayBuf(&ss.ReplayBuf)
+}
 
 ////////////////////////////////////////////////////////////////////////////////////////////
 // Testing
@@ -921,12 +2081,83 @@ func (ss *Sim) TestTrial() {
 		return
 	}
 
-	ss.ApplyInputs(&ss.TestEnv)
-	ss.AlphaCyc("test")  // !train
+	ss.ApplyInputs(ss.TestInputEnv())
+	ss.AlphaCyc("test") // !train
+	if ss.Context.On {
+		ss.Context.Update(ss.Net)
+	}
 	ss.TrialStats(false) // !accumulate
+	outLay := ss.Net.LayerByName("Output").(*leabra.Layer)
+	if ss.Confusion.ItemIdx(ss.TestEnv.TrialName) >= 0 { // not for novel items, e.g., lures
+		ss.Confusion.Incr(ss.TestEnv.TrialName, outLay.UnitValsTensor("ActM"))
+	}
 	ss.LogTstTrl(ss.TstTrlLog)
 }
 
+// ConfigModal checks that the patterns have the columns of the Modal
+// modalities if there are several -- if not, only the first is used
+func (ss *Sim) ConfigModal() {
+	if !ss.Modal.On() {
+		return
+	}
+	ss.Modal.Table = etable.NewIdxView(ss.Pats)
+	if err := ss.Modal.Validate(); err != nil {
+		log.Printf("ConfigModal: the patterns need a column for each modality (e.g., made with -genpats) -- using only: %v\n", ss.Modal.Mods[0])
+		ss.Modal.Mods = ss.Modal.Mods[:1]
+	}
+}
+
+// TrainInputEnv returns the environment that the training inputs are
+// applied from: the TrainEnv, wrapped by the Salience channel if On, the
+// Modal binding if there are several modalities, and then the Augment
+// transforms if set (by its Spec, which may be set by params)
+func (ss *Sim) TrainInputEnv() env.Env {
+	en := ss.SalienceInputEnv(&ss.TrainEnv, ss.TrainEnv.TrialName)
+	if ss.Modal.On() {
+		ss.Modal.Env = en
+		ss.Modal.Table = ss.TrainEnv.Table
+		ss.Modal.Rand = ss.Seeds.Rand(leabra.SeedModal)
+		ss.Modal.SetCur(ss.TrainEnv.TrialName)
+		en = &ss.Modal
+	}
+	ss.Augment.Update()
+	ss.Augment.Env = en
+	if ss.Augment.On() {
+		ss.Augment.Rand = ss.Seeds.Rand(leabra.SeedAugment)
+		en = &ss.Augment
+	}
+	return en
+}
+
+// TestInputEnv returns the environment that the test inputs are applied
+// from: the TestEnv, wrapped by the Salience channel if On, the CueTest if
+// it has Cues, and then by the TestNoise if it has a Level -- in a
+// TestList, the cue and noise are those of the current item of the list
+func (ss *Sim) TestInputEnv() env.Env {
+	en := ss.SalienceInputEnv(&ss.TestEnv, ss.TestEnv.TrialName)
+	if tl := ss.CurTestList; tl != nil {
+		ss.CueTest.Cues = nil
+		if spec := tl.ItemCue(ss.TestEnv.TrialName); spec != "" {
+			ss.CueTest.SetString(spec)
+		}
+		ss.TestNoise.Level = 0
+		if spec := tl.ItemNoise(ss.TestEnv.TrialName); spec != "" {
+			ss.TestNoise.SetString(spec)
+		}
+	}
+	ss.CueTest.Env = en
+	if ss.CueTest.On() {
+		ss.CueTest.Rand = ss.Seeds.Rand(leabra.SeedCueDegrade)
+		en = &ss.CueTest
+	}
+	ss.TestNoise.Env = en
+	if ss.TestNoise.On() {
+		ss.TestNoise.Rand = ss.Seeds.Rand(leabra.SeedTestNoise)
+		en = &ss.TestNoise
+	}
+	return en
+}
+
 // TestItem tests given item which is at given index in test item list
 func (ss *Sim) TestItem(idx int) {
 	cur := ss.TestEnv.Trial.Cur
@@ -938,9 +2169,144 @@ func (ss *Sim) TestItem(idx int) {
 	ss.TestEnv.Trial.Cur = cur
 }
 
-// TestAll runs through the full set of testing items
+// TestAll runs through the full set of testing items -- each of the lists
+// in turn for the ABAC paradigm -- and then the TestListNms lists and the
+// generalization test if GenTest is On
 func (ss *Sim) TestAll() {
+	if !ss.ABAC.On {
+		ss.TestEnvAll()
+	} else {
+		for _, list := range leabra.ABACLists {
+			if ss.StopNow {
+				break
+			}
+			ss.ABAC.TestList = list
+			ss.TestEnv.Table = etable.NewIdxView(ss.ABAC.Table(list))
+			ss.TestEnvAll()
+		}
+		ss.ABAC.TestList = ""
+	}
+	for _, name := range ss.TestListNms {
+		if ss.StopNow {
+			break
+		}
+		ss.TestList(name)
+	}
+	if ss.GenTest.On && !ss.StopNow {
+		ss.TestGen()
+	}
+}
+
+// TestList tests the items of the named list of the TestLists (e.g., lure
+// items, or a subset of the trained items), each with the noise and
+// partial cue of the list or the item, logged with the name of the list in
+// the List column of the test logs -- the TestEnv, CueTest and TestNoise
+// are restored at the end
+func (ss *Sim) TestList(name string) error {
+	tl, err := ss.TestLists.List(name)
+	if err != nil {
+		return err
+	}
+	ix, err := ss.TestLists.View(tl, ss.Pats, "Name")
+	if err != nil {
+		return err
+	}
+	prvTab, prvCues, prvNoise := ss.TestEnv.Table, ss.CueTest.Cues, ss.TestNoise
+	ss.CurTestList = tl
+	ss.SetTestTable(ix)
+	ss.TestEnv.Init(ss.TrainEnv.Run.Cur)
+	ss.Confusion.Reset()
+	for {
+		ss.TestTrial()
+		_, _, chg := ss.TestEnv.Counter(env.Epoch)
+		if chg || ss.StopNow {
+			break
+		}
+	}
+	ss.CurTestList = nil
+	ss.SetTestTable(prvTab)
+	ss.CueTest.Cues = prvCues
+	ss.TestNoise = prvNoise
+	ss.TestEnv.Init(ss.TrainEnv.Run.Cur)
+	return nil
+}
+
+// TestGen runs the generalization test of the GenTest items of this run:
+// the trained items, novel recombinations of their features and novel
+// controls, logged as the Gen list in the List column of the test logs,
+// with the type of each item in the GenType column of the TstTrlLog and
+// the stats of each type in the GenStats columns of the TstEpcLog -- the
+// TestEnv is restored at the end
+func (ss *Sim) TestGen() error {
+	if ss.GenTest.Table == nil {
+		err := fmt.Errorf("TestGen: no generalization test items for this run -- GenTest must be On")
+		log.Println(err)
+		return err
+	}
+	prvTab := ss.TestEnv.Table
+	ss.GenTesting = true
+	ss.SetTestTable(etable.NewIdxView(ss.GenTest.Table))
+	ss.TestEnv.Init(ss.TrainEnv.Run.Cur)
+	ss.Confusion.Reset()
+	for {
+		ss.TestTrial()
+		_, _, chg := ss.TestEnv.Counter(env.Epoch)
+		if chg || ss.StopNow {
+			break
+		}
+	}
+	ss.GenTesting = false
+	ss.SetTestTable(prvTab)
+	ss.TestEnv.Init(ss.TrainEnv.Run.Cur)
+	return nil
+}
+
+// RunTestGen runs the generalization test, has stop running = false at end
+// -- for gui
+func (ss *Sim) RunTestGen() {
+	ss.StopNow = false
+	ss.TestGen()
+	ss.Stopped()
+}
+
+// SetTestTable sets the items tested by the TestEnv to given view, with a
+// row of the TstTrlLog for each
+func (ss *Sim) SetTestTable(ix *etable.IdxView) {
+	ss.TestEnv.Table = ix
+	ss.TstTrlLog.SetNumRows(ix.Len())
+}
+
+// RunTestList tests the items of the named list, has stop running = false
+// at end -- for gui
+func (ss *Sim) RunTestList(name string) {
+	ss.StopNow = false
+	ss.TestList(name)
+	ss.Stopped()
+}
+
+// OpenTestLists opens the TestLists from given JSON file (see
+// leabra.TestLists)
+func (ss *Sim) OpenTestLists(filename gi.FileName) error {
+	return ss.TestLists.Open(filename)
+}
+
+// TestListName returns the name of the list currently tested, for the
+// List column of the test logs: the ABAC list, the TestList, or Gen for the
+// generalization test
+func (ss *Sim) TestListName() string {
+	if ss.GenTesting {
+		return "Gen"
+	}
+	if ss.CurTestList != nil {
+		return ss.CurTestList.Name
+	}
+	return ss.ABAC.TestList
+}
+
+// TestEnvAll runs through the full set of testing items of the TestEnv
+func (ss *Sim) TestEnvAll() {
 	ss.TestEnv.Init(ss.TrainEnv.Run.Cur)
+	ss.Confusion.Reset()
 	for {
 		ss.TestTrial()
 		_, _, chg := ss.TestEnv.Counter(env.Epoch)
@@ -948,6 +2314,61 @@ func (ss *Sim) TestAll() {
 			break
 		}
 	}
+	ss.RepSpace.AddTable(fmt.Sprintf("Epoch %d", ss.TrainEnv.Epoch.Cur), ss.TstTrlLog, "TrialName", "Hid1ActM")
+	ss.ReplayHid.SetPats(ss.TstTrlLog, "TrialName", "Hid1ActM")
+	if len(ss.PatComp.Levels) > 0 && !ss.StopNow {
+		ss.TestPatComp()
+	}
+}
+
+// TestPatComp runs the pattern-completion test: each test item is presented
+// PatComp.NReps times with its input cues degraded at each of the
+// PatComp.Levels, and the scores of the output for each level are added to
+// PatComp.Results
+func (ss *Sim) TestPatComp() {
+	pc := &ss.PatComp
+	pc.Init()
+	nitm := ss.TestEnv.Table.Len()
+	for li := range pc.Levels {
+		ss.CueLevel = li
+		for rep := 0; rep < pc.NReps && !ss.StopNow; rep++ {
+			for idx := 0; idx < nitm && !ss.StopNow; idx++ {
+				ss.TestItem(idx)
+				pc.AddTrial(li, ss.TrlSSE, ss.TrlCosDiff)
+			}
+		}
+	}
+	ss.CueLevel = -1
+	st := pc.Log(ss.TrainEnv.Run.Cur, ss.TrainEnv.Epoch.Cur)
+	if ss.PatCompFile != nil {
+		for row := st; row < pc.Results.Rows; row++ {
+			pc.Results.WriteCSVRow(ss.PatCompFile, row, etable.Tab, true)
+		}
+	}
+	if ss.PatCompPlot != nil {
+		ss.PatCompPlot.GoUpdate()
+	}
+}
+
+// RunTestPatComp runs the pattern-completion test, has stop running = false at end -- for gui
+func (ss *Sim) RunTestPatComp() {
+	ss.StopNow = false
+	ss.TestPatComp()
+	ss.Stopped()
+}
+
+// ProjectReps projects the Hidden1 representations of the test items at
+// each test of the run into 2D (see RepSpace), into RepProj, and updates
+// the RepPlot, which shows the trajectory of each item
+func (ss *Sim) ProjectReps() {
+	dt, err := ss.RepSpace.Project()
+	if err != nil {
+		return
+	}
+	*ss.RepProj = *dt
+	if ss.RepPlot != nil {
+		ss.RepPlot.GoUpdate()
+	}
 }
 
 // RunTestAll runs through the full set of testing items, has stop running = false at end -- for gui
@@ -975,190 +2396,944 @@ func (ss *Sim) ParamsName() string {
 func (ss *Sim) SetParams(sheet string, setMsg bool) error {
 	if sheet == "" {
 		// this is important for catching typos and ensuring that all sheets can be used
-		ss.Params.ValidateSheets([]string{"Network", "Sim"})
+		ss.Params.ValidateSheets([]string{"Network", "Sim", "TrainEnv", "SleepEnv", "TestEnv"})
+		ss.ParamRecs.Reset()
 	}
 	err := ss.SetParamsSet("Base", sheet, setMsg)
 	if ss.ParamSet != "" && ss.ParamSet != "Base" {
 		err = ss.SetParamsSet(ss.ParamSet, sheet, setMsg)
 	}
+	if ss.OverParamSet != "" {
+		err = ss.SetParamsSet(ss.OverParamSet, sheet, setMsg)
+	}
 	return err
 }
 
-// SetParamsSet sets the params for given params.Set name.
-// If sheet is empty, then it applies all avail sheets (e.g., Network, Sim)
+// SetParamsSet sets the params for given params.Set name, resolved to
+// include the params of any sets it extends (see Extends).
+// If sheet is empty, then it applies all avail sheets (e.g., Network, Sim,
+// TrainEnv, SleepEnv, TestEnv)
 // otherwise just the named sheet
 // if setMsg = true then we output a message for each param that was set.
 func (ss *Sim) SetParamsSet(setNm string, sheet string, setMsg bool) error {
-	pset, err := ss.Params.SetByNameTry(setNm)
+	pset, err := ss.Extends.Resolve(ss.Params, setNm)
 	if err != nil {
 		return err
 	}
 	if sheet == "" || sheet == "Network" {
 		netp, ok := pset.Sheets["Network"]
 		if ok {
+			ss.ParamRecs.RecordParams(ss.Net, setNm, netp)
 			ss.Net.ApplyParams(netp, setMsg)
 		}
+		if ss.Extends.Is(setNm, "Base") || ss.Extends.Is(setNm, "Sleep") { // paired wake / sleep values go with these
+			sleep := ss.Extends.Is(setNm, "Sleep")
+			ss.ParamRecs.RecordParams(ss.Net, setNm+"Mode", ss.Net.ModeParams.Sheet(sleep))
+			ss.Net.ApplyModeParams(sleep, setMsg)
+		}
 	}
 
 	if sheet == "" || sheet == "Sim" {
 		simp, ok := pset.Sheets["Sim"]
 		if ok {
+			ss.ParamRecs.RecordObj(setNm, "Sim", simp, ss, "Sim", "Sim", "", nil)
 			simp.Apply(ss, setMsg)
 		}
 	}
-	// note: if you have more complex environments with parameters, definitely add
-	// sheets for them, e.g., "TrainEnv", "TestEnv" etc
+
+	// env sheets are named for the env, with paths on the env type, e.g.,
+	// "SleepEnv": {Sel: "FixedTable", Params: {"FixedTable.Trial.Max": "10"}}
+	for _, en := range ss.Envs() {
+		if sheet != "" && sheet != en.Nm {
+			continue
+		}
+		envp, ok := pset.Sheets[en.Nm]
+		if ok {
+			ss.ParamRecs.RecordObj(setNm, en.Nm, envp, en, "FixedTable", en.Nm, "", nil)
+			envp.Apply(en, setMsg)
+		}
+	}
 	return err
 }
 
-func (ss *Sim) ConfigPats() {
-	dt := ss.Pats
-	dt.SetMetaData("name", "TrainPats")
-	dt.SetMetaData("desc", "Training patterns")
-	dt.SetFromSchema(etable.Schema{
-		{"Name", etensor.STRING, nil, nil},
-		{"Ne", etensor.FLOAT32, []int{1, 1}, []string{"Y", "X"}},
-		{"Po", etensor.FLOAT32, []int{1, 1}, []string{"Y", "X"}},
-		{"Input", etensor.FLOAT32, []int{5, 5}, []string{"Y", "X"}},
-		{"Output", etensor.FLOAT32, []int{5, 5}, []string{"Y", "X"}},
-		{"Ne_Out", etensor.FLOAT32, []int{1, 1}, []string{"Y", "X"}},
-		{"Po_Out", etensor.FLOAT32, []int{1, 1}, []string{"Y", "X"}},
-	}, 25)
+// Envs returns the environments, which have params sheets of the same name
+func (ss *Sim) Envs() []*env.FixedTable {
+	return []*env.FixedTable{&ss.TrainEnv.FixedTable, &ss.SleepEnv, &ss.TestEnv.FixedTable}
+}
 
-	patgen.PermutedBinaryRows(dt.Cols[1], 6, 1, 0)
-	patgen.PermutedBinaryRows(dt.Cols[2], 6, 1, 0)
-	dt.SaveCSV("summer_5x5_25_gen.dat", ',', true)
+// SetEnvParams applies the params for the env sheets (TrainEnv, SleepEnv,
+// TestEnv) -- called after the envs are initialized for each run, as Init
+// resets the Trial.Max counters to the number of patterns.  Trial.Max can be
+// set lower to run fewer trials (e.g., sleep trials) per epoch.
+func (ss *Sim) SetEnvParams() {
+	for _, en := range ss.Envs() {
+		ss.SetParams(en.Nm, ss.LogSetParams)
+	}
 }
 
-func (ss *Sim) OpenPats() {
-	dt := ss.Pats
-	dt.SetMetaData("name", "TrainPats")
-	dt.SetMetaData("desc", "Training patterns")
-	err := dt.OpenCSV("summer_5x5_25.dat", etable.Tab)
-	//	err := dt.OpenCSV("./examples/summer/summer_5x5_25.dat", etable.Tab)
-	if err != nil {
-		log.Println(err)
+// RunParamSearch runs each configuration of given param search for
+// ps.NSeeds runs, applying the configuration's params on top of Base,
+// and records FirstZero and PctCor from the RunLog for each run.
+// Configs are generated if not already.  The ParamSet and MaxRuns are
+// restored at the end.
+func (ss *Sim) RunParamSearch(ps *leabra.ParamSearch) {
+	if len(ps.Configs) == 0 {
+		ps.Generate()
+	}
+	prvSet := ss.ParamSet
+	prvRuns := ss.MaxRuns
+	ss.MaxRuns = ps.NSeeds
+	for ci := range ps.Configs {
+		pset := ps.Set(ci)
+		ss.SetParamSet(pset)
+		ss.ParamSet = pset.Name
+		fmt.Printf("ParamSearch config %d of %d: %v: %v\n", ci+1, len(ps.Configs), pset.Name, pset.Desc)
+		ss.RunLog.SetNumRows(0)
+		ss.Init()
+		ss.Train()
+		for row := 0; row < ss.RunLog.Rows; row++ {
+			ps.AddResult(ci, int(ss.RunLog.CellFloat("Run", row)), map[string]float64{
+				"FirstZero": ss.RunLog.CellFloat("FirstZero", row),
+				"PctCor":    ss.RunLog.CellFloat("PctCor", row),
+			})
+		}
 	}
+	ss.ParamSet = prvSet
+	ss.MaxRuns = prvRuns
 }
 
-////////////////////////////////////////////////////////////////////////////////////////////
-// 		Logging
+// RunSensitivity runs a sensitivity analysis of given params: the base
+// values are read with the current params in effect (with the Sleep params
+// for sleep params), and then each configuration is run for sa.NSeeds runs,
+// applying the perturbed param on top of the regular params as the
+// RunParamSet (or SlpParamSet for sleep params), and the sa.Stats from the
+// RunLog are recorded for each run.  If epcs > 0, each run is a short probe
+// of that many epochs.  The RunParamSet, SlpParamSet and MaxRuns are
+// restored at the end -- not to be combined with RunSample.
+func (ss *Sim) RunSensitivity(sa *leabra.Sensitivity, epcs int) {
+	prvRun, prvSlp, prvRuns := ss.RunParamSet, ss.SlpParamSet, ss.MaxRuns
+	ss.RunParamSet, ss.SlpParamSet = "", ""
+	ss.Init()
+	sa.SetBase(ss.Net, ss, false)
+	ss.SetParamsSet("Sleep", "", false)
+	sa.SetBase(ss.Net, ss, true)
+	ss.ApplyParamSets()
+	sa.Generate()
+	ss.MaxRuns = sa.NSeeds
+	for ci := range sa.Configs {
+		pset := sa.Set(ci)
+		if epcs > 0 {
+			pset.Sheets["Sim"] = &params.Sheet{{Sel: "Sim", Desc: "sensitivity probe",
+				Params: params.Params{"Sim.MaxEpcs": fmt.Sprint(epcs)}}}
+		}
+		ss.SetParamSet(pset)
+		ss.RunParamSet = pset.Name
+		ss.SlpParamSet = ""
+		if spset := sa.SleepSet(ci); spset != nil {
+			ss.SetParamSet(spset)
+			ss.SlpParamSet = spset.Name
+		}
+		fmt.Printf("Sensitivity config %d of %d: %v: %v\n", ci+1, len(sa.Configs), pset.Name, pset.Desc)
+		ss.RunLog.SetNumRows(0)
+		ss.Init()
+		ss.Train()
+		for row := 0; row < ss.RunLog.Rows; row++ {
+			stats := map[string]float64{}
+			for _, st := range sa.Stats {
+				stats[st] = ss.RunLog.CellFloat(st, row)
+			}
+			sa.AddResult(ci, int(ss.RunLog.CellFloat("Run", row)), stats)
+		}
+	}
+	ss.RunParamSet, ss.SlpParamSet, ss.MaxRuns = prvRun, prvSlp, prvRuns
+}
 
-// RunName returns a name for this run that combines Tag and Params -- add this to
-// any file names that are saved.
-func (ss *Sim) RunName() string {
-	if ss.Tag != "" {
-		return ss.Tag + "_" + ss.ParamsName()
-	} else {
-		return ss.ParamsName()
+// ValidateParams checks the Network sheets in all the param sets for
+// selectors and paths that do not apply to anything in the network
+func (ss *Sim) ValidateParams() error {
+	var rerr error
+	for _, pset := range ss.Params {
+		if netp, ok := pset.Sheets["Network"]; ok {
+			if err := ss.Net.ValidateParams(netp); err != nil {
+				fmt.Printf("ParamSet: %v has errors\n", pset.Name)
+				rerr = err
+			}
+		}
+	}
+	if err := ss.Net.ValidateParams(ss.Net.ModeParams.Sheet(false)); err != nil {
+		fmt.Printf("ModeParams has errors\n")
+		rerr = err
 	}
+	if err := ss.Net.ValidateParams(ss.Net.QtrParams.Sheet(0)); err != nil {
+		fmt.Printf("QtrParams has errors\n")
+		rerr = err
+	}
+	if err := ss.Extends.Validate(ss.Params); err != nil {
+		rerr = err
+	}
+	return rerr
 }
 
-// RunEpochName returns a string with the run and epoch numbers with leading zeros, suitable
-// for using in weights file names.  Uses 3, 5 digits for each.
-func (ss *Sim) RunEpochName(run, epc int) string {
-	return fmt.Sprintf("%03d_%05d", run, epc)
+// ResolvedParams returns a view of the current ParamSet (or Base) with the
+// final value of each param for each selector, after all the sets it extends
+func (ss *Sim) ResolvedParams() string {
+	str, err := ss.Extends.ResolvedString(ss.Params, ss.ParamsName())
+	if err != nil {
+		return err.Error()
+	}
+	return str
 }
 
-// WeightsFileName returns default current weights file name
-func (ss *Sim) WeightsFileName() string {
-	return ss.Net.Nm + "_" + ss.RunName() + "_" + ss.RunEpochName(ss.TrainEnv.Run.Cur, ss.TrainEnv.Epoch.Cur) + ".wts"
+// SaveParams saves a JSON record of every param applied since the last full
+// SetParams (i.e., Init), including the ParamSet that applied it, the value
+// it replaced, and its default value -- see leabra.ParamRecs.
+func (ss *Sim) SaveParams(filename gi.FileName) error {
+	return ss.ParamRecs.SaveJSON(filename)
 }
 
-// LogFileName returns default log file name
-func (ss *Sim) LogFileName(lognm string) string {
-	return ss.Net.Nm + "_" + ss.RunName() + "_" + lognm + ".csv"
+// OpenCppParams imports the Leabra specs from a C++ emergent .proj or .spec
+// file as the "Cpp" ParamSet, and makes it the current ParamSet -- each spec
+// becomes a class selector with the spec name (e.g., .HiddenLayer), so add
+// those classes to the layers and projections that used each spec.
+func (ss *Sim) OpenCppParams(filename gi.FileName) error {
+	pset, err := leabra.OpenCppParams(filename, "Cpp")
+	if err != nil {
+		return err
+	}
+	ss.SetParamSet(pset)
+	ss.ParamSet = pset.Name
+	return nil
 }
 
-//////////////////////////////////////////////
-//  SlpCycLog
+// SaveCppParams saves the params applied to each layer and projection since
+// the last Init as C++ emergent specs, one per layer and projection -- params
+// that have no C++ equivalent are listed in the log
+func (ss *Sim) SaveCppParams(filename gi.FileName) error {
+	netp, ok := ss.ParamRecs.Sheets()["Network"]
+	if !ok {
+		err := fmt.Errorf("SaveCppParams: no Network params have been applied -- Init first")
+		log.Println(err)
+		return err
+	}
+	return leabra.SaveCppParams(netp, filename)
+}
 
-// LogSlpCyc adds data from current sleep cycle to the SlpCycLog table.
-// computes cycle averages prior to logging.
-func (ss *Sim) LogSlpCyc(dt *etable.Table, cyc int) {
-	if dt.Rows <= cyc {
-		dt.SetNumRows(cyc + 1)
+// ApplyParamSets applies the Base params, then the ParamSet, then the
+// Overrides, then the per-run sampled params if any, and then any active
+// CondSets, to all sheets
+func (ss *Sim) ApplyParamSets() {
+	ss.SetParamsSet("Base", "", ss.LogSetParams)
+	if ss.ParamSet != "" && ss.ParamSet != "Base" {
+		ss.SetParamsSet(ss.ParamSet, "", ss.LogSetParams)
+	}
+	if ss.OverParamSet != "" {
+		ss.SetParamsSet(ss.OverParamSet, "", ss.LogSetParams)
+	}
+	if ss.RunParamSet != "" {
+		ss.SetParamsSet(ss.RunParamSet, "", ss.LogSetParams)
 	}
+	for _, set := range ss.CondSets.ActiveSets() {
+		ss.SetParamsSet(set, "", ss.LogSetParams)
+	}
+	ss.Live.Reapply(ss.Net, ss, false, ss.LogSetParams)
+}
 
-	inLay := ss.Net.LayerByName("Input").(*leabra.Layer)
-	blaNeInLay := ss.Net.LayerByName("Ne").(*leabra.Layer)
-	blaPoInLay := ss.Net.LayerByName("Po").(*leabra.Layer)
-	hid1Lay := ss.Net.LayerByName("Hidden1").(*leabra.Layer)
-	outLay := ss.Net.LayerByName("Output").(*leabra.Layer)
-	blaNeOutLay := ss.Net.LayerByName("Ne_Out").(*leabra.Layer)
-	blaPoOutLay := ss.Net.LayerByName("Po_Out").(*leabra.Layer)
+// ApplyLive applies any values edited in the LiveParams view to the running
+// network (or Sim) immediately, without Init -- they are recorded in the
+// ParamLog and the ParamRecs, and are re-applied on top of the regular params
+// in the same mode (sleep or wake) as they were made, until the next Init.
+func (ss *Sim) ApplyLive() {
+	state := "train"
+	if ss.Sleeping {
+		state = "sleep"
+	}
+	ctxt := strings.Join(strings.Fields(ss.Counters(state)), " ")
+	ss.Live.Apply(ss.Net, ss, ss.Sleeping, ctxt, &ss.ParamRecs, ss.LogSetParams)
+	ss.UpdateLive()
+}
+
+// UpdateLive updates the Live params to the values currently in effect,
+// and the views of them
+func (ss *Sim) UpdateLive() {
+	ss.Live.Update(ss.Net, ss, ss.ParamRecs)
+	if ss.LiveView != nil {
+		ss.LiveView.SetSlice(&ss.Live.Params)
+	}
+	if ss.LiveLogView != nil {
+		ss.LiveLogView.SetSlice(&ss.Live.Log)
+	}
+}
+
+// SaveParamLog saves the changelog of live param edits to a JSON file
+func (ss *Sim) SaveParamLog(filename gi.FileName) error {
+	return ss.Live.SaveLog(filename)
+}
+
+// SampleRunParams samples the RunSample params for given run, and applies
+// them on top of the regular params -- the Sleep params are applied during
+// sleep as the SlpParamSet
+func (ss *Sim) SampleRunParams(run int) {
+	ss.RunSample.Sample(run)
+	pset := ss.RunSample.Set()
+	ss.SetParamSet(pset)
+	ss.RunParamSet = pset.Name
+	if spset := ss.RunSample.SleepSet(); spset != nil {
+		ss.SetParamSet(spset)
+		ss.SlpParamSet = spset.Name
+	}
+	fmt.Printf("Run %d sampled params: %v\n", run, ss.RunSample.Desc())
+	ss.CondSets.Reset()
+	ss.ApplyParamSets()
+}
+
+// ApplyCondSets evaluates the CondSets conditions on the current Sim stats,
+// and if any set has become active or inactive, re-applies the params so
+// that exactly the active sets are in effect on top of the ParamSet
+func (ss *Sim) ApplyCondSets(epc int) {
+	if len(ss.CondSets) == 0 || !ss.CondSets.Update(ss) {
+		return
+	}
+	fmt.Printf("Epoch %d: active conditional param sets: %v\n", epc, ss.CondSets.ActiveSets())
+	ss.ApplyParamSets()
+}
+
+// ApplyAnneal applies the Anneal param schedules for given epoch, on top
+// of the current params
+func (ss *Sim) ApplyAnneal(epc int) {
+	if len(ss.Anneal) == 0 {
+		return
+	}
+	ss.Anneal.Apply(ss.Net, epc, ss.LogSetParams)
+	if simp := ss.Anneal.Sheet("Sim", epc); simp != nil {
+		simp.Apply(ss, ss.LogSetParams)
+	}
+}
+
+// RunHyperOpt runs ntrials trials of given hyperparameter study: for each,
+// the study suggests param values, which are applied on top of the Base
+// (and, for Sleep params, the Sleep) params, the model is run for MaxRuns
+// runs, and the mean of the study's objective column in the RunLog
+// (e.g., TstPctCor, for the last test of each run) is reported back.
+// If filename is not empty, the study is saved after each trial, so it can
+// be resumed.
+func (ss *Sim) RunHyperOpt(hs *leabra.HyperStudy, ntrials int, filename gi.FileName) {
+	hs.Init()
+	prvSet, prvSlp := ss.ParamSet, ss.SlpParamSet
+	for ti := 0; ti < ntrials; ti++ {
+		tr := hs.Suggest()
+		pset := hs.Set(tr)
+		ss.SetParamSet(pset)
+		ss.ParamSet = pset.Name
+		ss.SlpParamSet = ""
+		if spset := hs.SleepSet(tr); spset != nil {
+			ss.SetParamSet(spset)
+			ss.SlpParamSet = spset.Name
+		}
+		fmt.Printf("HyperOpt trial %d: %v\n", tr.Idx, hs.TrialDesc(tr))
+		ss.RunLog.SetNumRows(0)
+		ss.Init()
+		ss.Train()
+		obj := 0.0
+		for row := 0; row < ss.RunLog.Rows; row++ {
+			obj += ss.RunLog.CellFloat(hs.Obj, row)
+		}
+		if ss.RunLog.Rows > 0 {
+			obj /= float64(ss.RunLog.Rows)
+		}
+		hs.Report(tr.Idx, obj)
+		fmt.Printf("HyperOpt trial %d: %v = %g\n", tr.Idx, hs.Obj, obj)
+		if filename != "" {
+			hs.SaveJSON(filename)
+		}
+	}
+	ss.ParamSet, ss.SlpParamSet = prvSet, prvSlp
+	if best := hs.Best(); best != nil {
+		fmt.Printf("HyperOpt best: trial %d: %v = %g: %v\n", best.Idx, hs.Obj, best.Obj, hs.TrialDesc(best))
+	}
+}
+
+// SetOverrides adds the Overrides to Params as the "Overrides" set, which
+// is applied after the ParamSet -- call before Init
+func (ss *Sim) SetOverrides() {
+	if len(ss.Overrides) == 0 {
+		ss.OverParamSet = ""
+		return
+	}
+	pset := ss.Overrides.Set("Overrides")
+	ss.SetParamSet(pset)
+	ss.OverParamSet = pset.Name
+}
+
+// SetParamSet adds given params.Set to Params, replacing any existing set
+// with the same name
+func (ss *Sim) SetParamSet(pset *params.Set) {
+	for i, ps := range ss.Params {
+		if ps.Name == pset.Name {
+			ss.Params[i] = pset
+			return
+		}
+	}
+	ss.Params = append(ss.Params, pset)
+}
+
+// ConfigPats generates the patterns with PatGen, instead of opening them:
+// families of Input patterns with a controlled overlap, each with its
+// family as its Cat, reproduced on the Output, with the Ne input for the
+// first of every 3 families, the Po input for the second, and neither
+// (neutral) for the third -- the patterns are saved to summer_5x5_gen.dat,
+// and their similarities to summer_5x5_gen_sims.dat.  If SynthTarget is
+// set, the Input patterns are instead synthesized by PatSynth to realize
+// its similarities, with the categories of the target in place of the
+// families, and if ImageFiles are set, they are the Images preprocessed
+// to the size of the Input, with their directories as the categories.
+func (ss *Sim) ConfigPats() {
+	dt := ss.Pats
+	dt.SetMetaData("name", "TrainPats")
+	dt.SetMetaData("desc", "Training patterns")
+	var names, cats []string
+	var pats [][]int
+	var imgs []*etensor.Float32
+	rnd := rand.New(rand.NewSource(ss.RndSeed))
+	if ss.ImageFiles != "" {
+		ie := &ss.Images
+		ie.X, ie.Y = 5, 5
+		ie.DoG = false // the Input layer is 2D
+		if err := ie.SetFiles(ss.ImageFiles); err != nil {
+			return
+		}
+		for i := range ie.Files {
+			img, err := ie.Image(i)
+			if err != nil {
+				continue
+			}
+			names = append(names, ie.FileName(i))
+			cats = append(cats, ie.FileCat(i))
+			imgs = append(imgs, img)
+		}
+		pats = make([][]int, len(imgs))
+	} else if ss.SynthTarget != "" {
+		ps := &ss.PatSynth
+		if err := ps.OpenTarget(gi.FileName(ss.SynthTarget)); err != nil {
+			return
+		}
+		if err := ps.Synthesize(25, rnd); err != nil {
+			return
+		}
+		fmt.Printf("Synthesized %d patterns for: %v with RMS error of the similarities: %g\n", len(ps.Pats), ss.SynthTarget, ps.Err)
+		names, cats, pats = ps.Names, ps.Cats, ps.Pats
+		defer ps.SaveSims("summer_5x5_gen_sims.dat")
+	} else {
+		pg := &ss.PatGen
+		if mx := ss.SetSize.Max(); mx > pg.NFams*pg.NPer { // enough for every set size
+			pg.NPer = (mx + pg.NFams - 1) / pg.NFams
+		}
+		if err := pg.Generate(25, rnd); err != nil {
+			return
+		}
+		pats = pg.Pats
+		for pi := range pats {
+			names = append(names, pg.PatName(pi))
+			cats = append(cats, pg.FamName(pg.Fams[pi]))
+		}
+		defer pg.SaveSims("summer_5x5_gen_sims.dat")
+	}
+	np := len(pats)
+	sch := etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Cat", etensor.STRING, nil, nil},
+		{"Ne", etensor.FLOAT32, []int{3, 1}, []string{"Y", "X"}},
+		{"Po", etensor.FLOAT32, []int{3, 1}, []string{"Y", "X"}},
+		{"Input", etensor.FLOAT32, []int{5, 5}, []string{"Y", "X"}},
+		{"Output", etensor.FLOAT32, []int{5, 5}, []string{"Y", "X"}},
+		{"Ne_Out", etensor.FLOAT32, []int{3, 1}, []string{"Y", "X"}},
+		{"Po_Out", etensor.FLOAT32, []int{3, 1}, []string{"Y", "X"}},
+	}
+	for _, mod := range ss.Modal.OtherMods() {
+		sch = append(sch, etable.Column{mod, etensor.FLOAT32, []int{5, 5}, []string{"Y", "X"}})
+	}
+	dt.SetFromSchema(sch, np)
+	fams := map[string]int{} // index of each category in order of appearance
+	for pi := 0; pi < np; pi++ {
+		fam, has := fams[cats[pi]]
+		if !has {
+			fam = len(fams)
+			fams[cats[pi]] = fam
+		}
+		dt.SetCellString("Name", pi, names[pi])
+		dt.SetCellString("Cat", pi, cats[pi])
+		if imgs != nil {
+			in, out := dt.CellTensor("Input", pi), dt.CellTensor("Output", pi)
+			for i, v := range imgs[pi].Values {
+				in.SetFloat1D(i, float64(v))
+				out.SetFloat1D(i, float64(v))
+			}
+		} else {
+			leabra.SetPatUnits(dt.CellTensor("Input", pi), pats[pi])
+			leabra.SetPatUnits(dt.CellTensor("Output", pi), pats[pi])
+		}
+		switch fam % 3 {
+		case 0:
+			dt.CellTensor("Ne", pi).SetFloat1D(0, 1)
+			dt.CellTensor("Ne_Out", pi).SetFloat1D(0, 1)
+		case 1:
+			dt.CellTensor("Po", pi).SetFloat1D(0, 1)
+			dt.CellTensor("Po_Out", pi).SetFloat1D(0, 1)
+		}
+	}
+	ss.Modal.GenPats(dt, rnd)
+	dt.SaveCSV("summer_5x5_gen.dat", etable.Tab, true)
+}
+
+func (ss *Sim) OpenPats() {
+	dt := ss.Pats
+	dt.SetMetaData("name", "TrainPats")
+	dt.SetMetaData("desc", "Training patterns")
+	err := dt.OpenCSV(gi.FileName(ss.PatsFile), etable.Tab)
+	//	err := dt.OpenCSV("./examples/summer/summer_5x5_25.dat", etable.Tab)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////
+// 		Logging
+
+// RunName returns a name for this run that combines Tag and Params -- add this to
+// any file names that are saved.
+func (ss *Sim) RunName() string {
+	if ss.Tag != "" {
+		return ss.Tag + "_" + ss.ParamsName()
+	} else {
+		return ss.ParamsName()
+	}
+}
+
+// RunEpochName returns a string with the run and epoch numbers with leading zeros, suitable
+// for using in weights file names.  Uses 3, 5 digits for each.
+func (ss *Sim) RunEpochName(run, epc int) string {
+	return fmt.Sprintf("%03d_%05d", run, epc)
+}
+
+// WeightsFileName returns default current weights file name
+func (ss *Sim) WeightsFileName() string {
+	return ss.Net.Nm + "_" + ss.RunName() + "_" + ss.RunEpochName(ss.TrainEnv.Run.Cur, ss.TrainEnv.Epoch.Cur) + ".wts"
+}
+
+// LogFileName returns default log file name
+func (ss *Sim) LogFileName(lognm string) string {
+	return ss.Net.Nm + "_" + ss.RunName() + "_" + lognm + ".csv"
+}
+
+// Provenance returns the metadata for the current run: the param sets, the
+// final applied param values, the seeds and the tag
+func (ss *Sim) Provenance() *leabra.Provenance {
+	pv := leabra.NewProvenance(ss.Net, ss.ParamRecs)
+	pv.Tag = ss.Tag
+	pv.ParamSets = []string{"Base"}
+	for _, set := range append([]string{ss.ParamSet, ss.OverParamSet, ss.RunParamSet}, ss.CondSets.ActiveSets()...) {
+		if set != "" && set != "Base" {
+			pv.ParamSets = append(pv.ParamSets, set)
+		}
+	}
+	pv.SleepSets = []string{"Sleep"}
+	if ss.SlpParamSet != "" {
+		pv.SleepSets = append(pv.SleepSets, ss.SlpParamSet)
+	}
+	pv.SetSeeds(&ss.Seeds)
+	return pv
+}
+
+// LogTables returns all the log tables by name, e.g., for WriteSQLite
+func (ss *Sim) LogTables() map[string]*etable.Table {
+	logs := map[string]*etable.Table{
+		"TrnEpcLog":  ss.TrnEpcLog,
+		"TstEpcLog":  ss.TstEpcLog,
+		"TstTrlLog":  ss.TstTrlLog,
+		"TstCycLog":  ss.TstCycLog,
+		"RunLog":     ss.RunLog,
+		"RunStats":   ss.RunStats,
+		"SlpCycLog":  ss.SlpCycLog,
+		"TrnPhsLog":  ss.TrnPhsLog,
+		"PatCompLog": ss.PatComp.Results,
+		"DwellLog":   ss.Dwell.Results,
+	}
+	for nm, dt := range logs {
+		if dt == nil {
+			delete(logs, nm)
+		}
+	}
+	return logs
+}
+
+// SaveRunSQLite writes all the log tables (see LogTables) into a single
+// SQLite database for the current run, _run<N>.sqlite, with a meta table of
+// the Provenance and the Run -- requires building with -tags sqlite
+func (ss *Sim) SaveRunSQLite() error {
+	run := ss.TrainEnv.Run.Cur
+	fnm := strings.TrimSuffix(ss.LogFileName(fmt.Sprintf("run%03d", run)), ".csv") + ".sqlite"
+	meta := ss.Provenance().Meta()
+	meta["Run"] = strconv.Itoa(run)
+	meta["Params"] = ss.ParamsName()
+	fmt.Printf("Saving log tables to: %v\n", fnm)
+	return export.WriteSQLite(fnm, meta, ss.LogTables())
+}
+
+// SaveProvenance saves the Provenance for the current run to the sidecar
+// .meta.json file for given log file, so the log can be interpreted later
+func (ss *Sim) SaveProvenance(logfile string) {
+	ss.Provenance().SaveSidecar(logfile)
+}
+
+//////////////////////////////////////////////
+//  Logs
+
+// SlpLays are the names of the layers logged in the SlpCycLog, with their
+// names in the log
+var SlpLays = [][2]string{{"Input", "Input"}, {"Ne", "BlaNeIn"}, {"Po", "BlaPoIn"}, {"Hidden1", "Hidden1"},
+	{"Output", "Output"}, {"Ne_Out", "BlaNeOut"}, {"Po_Out", "BlaPoOut"}}
+
+// LogLays are the names of the layers whose activity is logged in the
+// TstCycLog, TrnEpcLog and TstTrlLog, with their names in the logs
+var LogLays = [][2]string{{"Hidden1", "Hid1"}, {"Output", "Out"}, {"Ne_Out", "BlaNeOut"}, {"Po_Out", "BlaPoOut"}}
+
+// ConfigLogs declares the items of all the logs, made with the logging
+// framework: one for each mode and time scale -- SlpCycLog (Sleep Cycle),
+// TstCycLog (Test Cycle), TrnPhsLog (Train Trial, if PhaseLays or a
+// LogSpecFile are set), TrnEpcLog (Train Epoch), TstTrlLog (Test Trial),
+// TstEpcLog (Test Epoch) and RunLog (Train Run) -- including those declared
+// in the LogSpecFile, and configures their tables -- returns an error for
+// any items not found.  Call after changing the ErrStats, PhaseLays,
+// RunSample or LogSpecFile.
+func (ss *Sim) ConfigLogs() error {
+	lg := &ss.Logs
+	lg.Items = nil
+	lg.Prec = LogPrec
+	lg.Tables = map[leabra.LogKey]*etable.Table{
+		{Mode: "Sleep", Time: leabra.Cycle}: ss.SlpCycLog,
+		{Mode: "Test", Time: leabra.Cycle}:  ss.TstCycLog,
+		{Mode: "Train", Time: leabra.Trial}: ss.TrnPhsLog,
+		{Mode: "Train", Time: leabra.Epoch}: ss.TrnEpcLog,
+		{Mode: "Test", Time: leabra.Trial}:  ss.TstTrlLog,
+		{Mode: "Test", Time: leabra.Epoch}:  ss.TstEpcLog,
+		{Mode: "Train", Time: leabra.Run}:   ss.RunLog,
+	}
+	// hide turns off the plotting of an item, e.g., after SetRange
+	hide := func(it *leabra.LogItem) { it.Plot = false }
+
+	cycFun := func(lg *leabra.Logs, row int) float64 { return float64(row) }
+	runFun := func(lg *leabra.Logs, row int) float64 { return float64(ss.TrainEnv.Run.Cur) }
+	epcFun := func(lg *leabra.Logs, row int) float64 { return float64(ss.TrainEnv.Epoch.Cur) }
+	prvFun := func(lg *leabra.Logs, row int) float64 { return float64(ss.TrainEnv.Epoch.Prv) } // logged after the increment
+	lg.AddFun("Sleep", leabra.Cycle, "Run", runFun).Type = etensor.INT64
+	lg.AddFun("Sleep", leabra.Cycle, "Epoch", epcFun).Type = etensor.INT64
+	lg.AddFun("Sleep", leabra.Cycle, "SleepTrial", func(lg *leabra.Logs, row int) float64 { return float64(ss.SleepEnv.Trial.Cur) }).Type = etensor.INT64
+	lg.AddFun("Sleep", leabra.Cycle, "Cycle", cycFun).Type = etensor.INT64
+	lg.AddFun("Sleep", leabra.Cycle, "AvgLaySim", func(lg *leabra.Logs, row int) float64 {
+		sum := 0.0
+		for _, ln := range SlpLays {
+			sum += ss.Net.LayerByName(ln[0]).(*leabra.Layer).Sim
+		}
+		ss.AvgLaySim = sum / float64(len(SlpLays))
+		return ss.AvgLaySim
+	}).SetRange(-1, 1)
+	for _, ln := range SlpLays {
+		lg.AddLayer("Sleep", leabra.Cycle, ln[1]+" LaySim", ln[0], "Sim").SetRange(-1, 1)
+	}
+	// instantaneous Gi of each layer and the Gi oscillation (of Hidden1), for the SlpGiPlot
+	for _, ln := range SlpLays {
+		lg.AddLayer("Sleep", leabra.Cycle, ln[1]+" Gi", ln[0], "Inhib.Gi")
+	}
+	lg.AddFun("Sleep", leabra.Cycle, "Osc Phase", func(lg *leabra.Logs, row int) float64 {
+		per := ss.Net.LayerByName("Hidden1").(*leabra.Layer).Inhib.Layer.GiOscPer
+		if !ss.InhibOscil || per <= 0 {
+			return 0
+		}
+		return float64(row%per) / float64(per)
+	})
+	lg.AddFun("Sleep", leabra.Cycle, "Osc Gain", func(lg *leabra.Logs, row int) float64 {
+		fb := &ss.Net.LayerByName("Hidden1").(*leabra.Layer).Inhib.Layer
+		if fb.GiBase == 0 {
+			return 1
+		}
+		return float64(fb.Gi / fb.GiBase)
+	})
+	replays := map[string]*leabra.ReplayDecoder{"Hid1": &ss.ReplayHid, "Out": &ss.ReplayOut}
+	for _, nm := range []string{"Hid1", "Out"} {
+		rd := replays[nm]
+		lg.AddStr("Sleep", leabra.Cycle, nm+" Replay", func(lg *leabra.Logs, row int) string { return rd.BestName() })
+		lg.AddFun("Sleep", leabra.Cycle, nm+" Match", func(lg *leabra.Logs, row int) float64 { return rd.Match }).SetRange(0, 1)
+		lg.AddFun("Sleep", leabra.Cycle, nm+" Trans", func(lg *leabra.Logs, row int) float64 {
+			if rd.Trans {
+				return 1
+			}
+			return 0
+		})
+	}
+
+	lg.AddFun("Test", leabra.Cycle, "Cycle", cycFun).Type = etensor.INT64
+	for _, vr := range []string{"Ge.Avg", "Act.Avg"} {
+		for _, ln := range LogLays {
+			lg.AddLayer("Test", leabra.Cycle, ln[1]+" "+vr, ln[0], vr).SetRange(0, .5)
+		}
+	}
+
+	lg.AddFun("Train", leabra.Epoch, "Run", runFun).Type = etensor.INT64
+	lg.AddFun("Train", leabra.Epoch, "Epoch", prvFun).Type = etensor.INT64
+	lg.AddFun("Train", leabra.Epoch, "SSE", func(lg *leabra.Logs, row int) float64 { return ss.EpcSSE })
+	lg.AddFun("Train", leabra.Epoch, "AvgSSE", func(lg *leabra.Logs, row int) float64 { return ss.EpcAvgSSE })
+	lg.AddFun("Train", leabra.Epoch, "PctErr", func(lg *leabra.Logs, row int) float64 { return ss.EpcPctErr }).SetRange(0, 1)
+	lg.AddFun("Train", leabra.Epoch, "PctCor", func(lg *leabra.Logs, row int) float64 { return ss.EpcPctCor }).SetRange(0, 1)
+	hide(lg.AddFun("Train", leabra.Epoch, "CosDiff", func(lg *leabra.Logs, row int) float64 { return ss.EpcCosDiff }).SetRange(0, 1))
+	lg.AddFun("Train", leabra.Epoch, "RT", func(lg *leabra.Logs, row int) float64 { return ss.EpcRT })
+	lg.AddFun("Train", leabra.Epoch, "Cycles", func(lg *leabra.Logs, row int) float64 { return ss.EpcCycs })
+	lg.AddFun("Train", leabra.Epoch, "Tier", func(lg *leabra.Logs, row int) float64 { return float64(ss.Curriculum.Tier) })
+	lg.AddStr("Train", leabra.Epoch, "List", func(lg *leabra.Logs, row int) string { return ss.ABAC.List })
+	for _, ln := range LogLays {
+		hide(lg.AddLayer("Train", leabra.Epoch, ln[1]+" ActAvg", ln[0], "ActAvg.ActPAvgEff").SetRange(0, .5))
+	}
+	ss.Net.Stats.AddLogItems(lg, "Train", leabra.Epoch, ss.Net)
+	ss.DWtStats.AddLogItems(lg, "Train", leabra.Epoch)
+
+	lg.AddFun("Test", leabra.Trial, "Run", runFun).Type = etensor.INT64
+	lg.AddFun("Test", leabra.Trial, "Epoch", prvFun).Type = etensor.INT64
+	lg.AddFun("Test", leabra.Trial, "Trial", func(lg *leabra.Logs, row int) float64 { return float64(row) }).Type = etensor.INT64
+	lg.AddStr("Test", leabra.Trial, "TrialName", func(lg *leabra.Logs, row int) string { return ss.TestEnv.TrialName })
+	lg.AddStr("Test", leabra.Trial, "Cat", func(lg *leabra.Logs, row int) string { return ss.CatStats.Tag(ss.TestEnv.TrialName) })
+	lg.AddStr("Test", leabra.Trial, "Studied", func(lg *leabra.Logs, row int) string { return ss.OneShot.Status(ss.TestEnv.TrialName) })
+	lg.AddStr("Test", leabra.Trial, "List", func(lg *leabra.Logs, row int) string { return ss.TestListName() })
+	lg.AddStr("Test", leabra.Trial, "GenType", func(lg *leabra.Logs, row int) string {
+		if !ss.GenTesting {
+			return ""
+		}
+		return ss.GenTest.Type(ss.TestEnv.TrialName)
+	})
+	lg.AddFun("Test", leabra.Trial, "Valence", func(lg *leabra.Logs, row int) float64 { return ss.Salience.Of(ss.TestEnv.TrialName).Valence })
+	lg.AddFun("Test", leabra.Trial, "Arousal", func(lg *leabra.Logs, row int) float64 { return ss.Salience.Of(ss.TestEnv.TrialName).Arousal })
+	lg.AddFun("Test", leabra.Trial, "SSE", func(lg *leabra.Logs, row int) float64 { return ss.TrlSSE })
+	lg.AddFun("Test", leabra.Trial, "AvgSSE", func(lg *leabra.Logs, row int) float64 { return ss.TrlAvgSSE }).Plot = true
+	lg.AddFun("Test", leabra.Trial, "CosDiff", func(lg *leabra.Logs, row int) float64 { return ss.TrlCosDiff }).SetRange(0, 1)
+	lg.AddFun("Test", leabra.Trial, "RT", func(lg *leabra.Logs, row int) float64 { return ss.TrlRT })
+	lg.AddFun("Test", leabra.Trial, "Cycles", func(lg *leabra.Logs, row int) float64 { return ss.TrlCycs })
+	lg.AddFun("Test", leabra.Trial, "Noise", func(lg *leabra.Logs, row int) float64 {
+		if !ss.TestNoise.On() {
+			return 0
+		}
+		return float64(ss.TestNoise.Level)
+	})
+	lg.AddStr("Test", leabra.Trial, "Cue", func(lg *leabra.Logs, row int) string {
+		if !ss.CueTest.On() {
+			return ""
+		}
+		return ss.CueTest.String()
+	})
+	for _, ln := range LogLays {
+		lg.AddLayer("Test", leabra.Trial, ln[1]+" ActM.Avg", ln[0], "ActM.Avg").SetRange(0, .5)
+	}
+	for _, tn := range [][3]string{{"InAct", "Input", "Act"}, {"BlaNeInAct", "Ne", "Act"}, {"BlaPoInAct", "Po", "Act"},
+		{"BlaNeOutAct", "Ne_Out", "Act"}, {"BlaPoOutAct", "Po_Out", "Act"}, {"OutActM", "Output", "ActM"},
+		{"OutActP", "Output", "ActP"}, {"Hid1ActM", "Hidden1", "ActM"}} {
+		hide(lg.AddTensor("Test", leabra.Trial, tn[0], tn[1], tn[2]).SetRange(0, 1))
+	}
+	for i, nm := range ss.ErrStats {
+		i := i
+		lg.AddFun("Test", leabra.Trial, nm, func(lg *leabra.Logs, row int) float64 {
+			if i >= len(ss.TrlErrs) {
+				return 0
+			}
+			return ss.TrlErrs[i]
+		})
+	}
+	lg.AddPhases("Test", leabra.Trial, ss.PhaseLays)
+
+	// PctErr and PctCor of the test trials
+	tstPct := func(cor bool) func(lg *leabra.Logs, row int) float64 {
+		return func(lg *leabra.Logs, row int) float64 {
+			return agg.PropIf(etable.NewIdxView(ss.TstTrlLog), "SSE", func(idx int, val float64) bool {
+				return (val == 0) == cor
+			})[0]
+		}
+	}
+	lg.AddFun("Test", leabra.Epoch, "Run", runFun).Type = etensor.INT64
+	lg.AddFun("Test", leabra.Epoch, "Epoch", prvFun).Type = etensor.INT64
+	lg.AddAgg("Test", leabra.Epoch, "SSE", leabra.Trial, agg.AggSum)
+	lg.AddAgg("Test", leabra.Epoch, "AvgSSE", leabra.Trial, agg.AggMean)
+	lg.AddFun("Test", leabra.Epoch, "PctErr", tstPct(false)).SetRange(0, 1)
+	lg.AddFun("Test", leabra.Epoch, "PctCor", tstPct(true)).SetRange(0, 1)
+	hide(lg.AddAgg("Test", leabra.Epoch, "CosDiff", leabra.Trial, agg.AggMean).SetRange(0, 1))
+	for _, nm := range []string{"RT", "Cycles", "Noise"} {
+		lg.AddAgg("Test", leabra.Epoch, nm, leabra.Trial, agg.AggMean)
+	}
+	lg.AddStr("Test", leabra.Epoch, "Phase", func(lg *leabra.Logs, row int) string { return ss.OneShot.Phase })
+	lg.AddStr("Test", leabra.Epoch, "List", func(lg *leabra.Logs, row int) string { return ss.TestListName() })
+	hide(lg.AddFun("Test", leabra.Epoch, "PctConfused", func(lg *leabra.Logs, row int) float64 { return ss.Confusion.PctConfused() }).SetRange(0, 1))
+	nitm := len(ss.Confusion.Names)
+	hide(lg.AddTenFun("Test", leabra.Epoch, "Confusion", []int{nitm, nitm}, []string{"Presented", "Closest"}, func(lg *leabra.Logs, row int) etensor.Tensor {
+		return ss.Confusion.Probs()
+	}).SetRange(0, 1))
+	for _, nm := range ss.ErrStats {
+		it := lg.AddAgg("Test", leabra.Epoch, nm, leabra.Trial, agg.AggMean)
+		if nm == "AUC" {
+			hide(it.SetRange(0, 1))
+		}
+	}
+	ss.CatStats.AddLogItems(lg, "Test", leabra.Epoch, leabra.Trial, "Cat")
+	ss.StudyStats.AddLogItems(lg, "Test", leabra.Epoch, leabra.Trial, "Studied")
+	ss.GenStats.AddLogItems(lg, "Test", leabra.Epoch, leabra.Trial, "GenType")
+
+	lg.AddFun("Train", leabra.Run, "Run", runFun).Type = etensor.INT64
+	lg.AddStr("Train", leabra.Run, "Params", func(lg *leabra.Logs, row int) string { return ss.RunParams() })
+	lg.AddStr("Train", leabra.Run, "Tag", func(lg *leabra.Logs, row int) string { return ss.Tag })
+	lg.AddFun("Train", leabra.Run, "NPats", func(lg *leabra.Logs, row int) float64 { return float64(ss.TrainEnv.Table.Len()) }).Type = etensor.INT64
+	lg.AddFun("Train", leabra.Run, "FirstZero", func(lg *leabra.Logs, row int) float64 { return float64(ss.FirstZero) }).Plot = true
+	for _, nm := range []string{"SSE", "AvgSSE", "PctErr", "PctCor", "CosDiff"} {
+		it := lg.AddAgg("Train", leabra.Run, nm, leabra.Epoch, agg.AggMean)
+		it.AggLast = 11 // mean over the last 10 epochs + 1
+		if nm == "PctErr" || nm == "PctCor" || nm == "CosDiff" {
+			hide(it.SetRange(0, 1))
+		}
+	}
+	lg.AddFun("Train", leabra.Run, "TstPctCor", func(lg *leabra.Logs, row int) float64 {
+		if ss.TstEpcLog.Rows == 0 {
+			return 0
+		}
+		return ss.TstEpcLog.CellFloat("PctCor", ss.TstEpcLog.Rows-1)
+	})
+	lg.AddFun("Train", leabra.Run, "SlpLaySim", func(lg *leabra.Logs, row int) float64 { return ss.AvgLaySim })
+	for i, nm := range ss.RunSample.Names() { // per-run sampled param values
+		i := i
+		lg.AddFun("Train", leabra.Run, nm, func(lg *leabra.Logs, row int) float64 { return ss.RunSample.Vals[i] })
+	}
+
+	if len(ss.PhaseLays) > 0 || ss.LogSpecFile != "" {
+		en := &ss.TrainEnv.FixedTable
+		lg.AddFun("Train", leabra.Trial, "Run", runFun).Type = etensor.INT64
+		lg.AddFun("Train", leabra.Trial, "Epoch", epcFun).Type = etensor.INT64
+		lg.AddFun("Train", leabra.Trial, "Trial", func(lg *leabra.Logs, row int) float64 { return float64(row) }).Type = etensor.INT64
+		lg.AddStr("Train", leabra.Trial, "TrialName", func(lg *leabra.Logs, row int) string { return en.TrialName })
+		lg.AddPhases("Train", leabra.Trial, ss.PhaseLays)
+	}
+
+	var specErr error
+	if ss.LogSpecFile != "" {
+		var specs *leabra.LogSpecs
+		specs, specErr = leabra.OpenLogSpecs(gi.FileName(ss.LogSpecFile))
+		if specErr == nil {
+			_, specErr = specs.Add(lg)
+		}
+	}
+
+	err := lg.Config(ss.Net)
+	if specErr != nil {
+		err = specErr
+	}
+	if ss.TestEnv.Table != nil {
+		ss.TstTrlLog.SetNumRows(ss.TestEnv.Table.Len()) // a row for each item tested
+	}
+	ss.SlpCycLog.SetMetaData("name", "SlpCycLog")
+	ss.SlpCycLog.SetMetaData("desc", "Record of activity etc over one sleep trial by cycle")
+	ss.TstCycLog.SetMetaData("name", "TstCycLog")
+	ss.TstCycLog.SetMetaData("desc", "Record of activity etc over one trial by cycle")
+	ss.TrnPhsLog.SetMetaData("name", "TrnPhsLog")
+	ss.TrnPhsLog.SetMetaData("desc", "Record of minus and plus phase activations by training trial")
+	ss.TrnEpcLog.SetMetaData("name", "TrnEpcLog")
+	ss.TrnEpcLog.SetMetaData("desc", "Record of performance and of learning in each projection over epochs of training")
+	ss.TstTrlLog.SetMetaData("name", "TstTrlLog")
+	ss.TstTrlLog.SetMetaData("desc", "Record of testing per input pattern")
+	ss.TstEpcLog.SetMetaData("name", "TstEpcLog")
+	ss.TstEpcLog.SetMetaData("desc", "Summary stats for testing trials")
+	ss.RunLog.SetMetaData("name", "RunLog")
+	ss.RunLog.SetMetaData("desc", "Record of performance at end of training")
+	return err
+}
+
+// ConfigLogPlot configures the columns of given plot from the items of the
+// log for given mode and time scale -- only those for which show returns
+// true are plotted, if non-nil
+func (ss *Sim) ConfigLogPlot(plt *eplot.Plot2D, mode string, tm leabra.TimeScales, show func(it *leabra.LogItem) bool) {
+	plt.SetTable(ss.Logs.Table(mode, tm))
+	// order of params: on, fixMin, min, fixMax, max
+	for _, it := range ss.Logs.ModeItems(mode, tm) {
+		on := it.Plot && (show == nil || show(it))
+		plt.SetColParams(it.Name, on, it.FixMin, it.Min, it.FixMax, it.Max)
+	}
+}
+
+// IsDWtItem returns whether given log item is one of the DWtStats, logged
+// in the TrnEpcLog and plotted in the TrnDWtPlot
+func IsDWtItem(it *leabra.LogItem) bool {
+	return strings.HasSuffix(it.Name, " SumDWt") || strings.HasSuffix(it.Name, " FracDWt")
+}
 
-	ss.AvgLaySim = (inLay.Sim + blaNeInLay.Sim + blaPoInLay.Sim + hid1Lay.Sim + outLay.Sim + blaPoOutLay.Sim + blaNeOutLay.Sim) / 7
+//////////////////////////////////////////////
+//  SlpCycLog
 
-	dt.SetCellFloat("Cycle", cyc, float64(cyc))
-	dt.SetCellFloat("AvgLaySim", cyc, float64(ss.AvgLaySim))
-	dt.SetCellFloat("Input LaySim", cyc, float64(inLay.Sim))
-	dt.SetCellFloat("BlaNeIn LaySim", cyc, float64(blaNeInLay.Sim))
-	dt.SetCellFloat("BlaPoIn LaySim", cyc, float64(blaPoInLay.Sim))
-	dt.SetCellFloat("Hidden1 LaySim", cyc, float64(hid1Lay.Sim))
-	dt.SetCellFloat("Output LaySim", cyc, float64(outLay.Sim))
-	dt.SetCellFloat("BlaNeOut LaySim", cyc, float64(blaNeOutLay.Sim))
-	dt.SetCellFloat("BlaPoOut LaySim", cyc, float64(blaPoOutLay.Sim))
+// LogSlpCyc adds data from current sleep cycle to the SlpCycLog table.
+func (ss *Sim) LogSlpCyc(dt *etable.Table, cyc int) {
+	ss.Logs.LogRow("Sleep", leabra.Cycle, cyc)
+	if ss.DashAddr != "" {
+		for _, ln := range SlpLays {
+			ss.Dash.AddPoint("Sleep LaySim", ln[1], float64(cyc), ss.Net.LayerByName(ln[0]).(*leabra.Layer).Sim)
+			ss.Dash.AddPoint("Sleep Gi", ln[1], float64(cyc), dt.CellFloat(ln[1]+" Gi", cyc))
+		}
+		if cyc%10 == 0 {
+			ss.DashLayers("sleep", "Act")
+		}
+	}
 
 	if cyc%10 == 0 { // too slow to do every cyc
 		// note: essential to use Go version of update when called from another goroutine
 		ss.SlpCycPlot.GoUpdate()
+		ss.SlpGiPlot.GoUpdate()
+		if ss.SynInsp.On {
+			ss.SynInspPlot.GoUpdate()
+		}
 	}
 }
 
-func (ss *Sim) ConfigSlpCycLog(dt *etable.Table) {
-	dt.SetMetaData("name", "SlpCycLog")
-	dt.SetMetaData("desc", "Record of activity etc over one sleep trial by cycle")
-	dt.SetMetaData("read-only", "true")
-	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
-
-	np := 330 // max cycles
-	dt.SetFromSchema(etable.Schema{
-		{"Cycle", etensor.INT64, nil, nil},
-		{"AvgLaySim", etensor.FLOAT64, nil, nil},
-		{"Input LaySim", etensor.FLOAT64, nil, nil},
-		{"BlaNeIn LaySim", etensor.FLOAT64, nil, nil},
-		{"BlaPoIn LaySim", etensor.FLOAT64, nil, nil},
-		{"Hidden1 LaySim", etensor.FLOAT64, nil, nil},
-		{"Output LaySim", etensor.FLOAT64, nil, nil},
-		{"BlaNeOut LaySim", etensor.FLOAT64, nil, nil},
-		{"BlaPoOut LaySim", etensor.FLOAT64, nil, nil},
-	}, np)
+func (ss *Sim) ConfigTrnDWtPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Learning per Projection by Training Epoch"
+	plt.Params.XAxisCol = "Epoch"
+	ss.ConfigLogPlot(plt, "Train", leabra.Epoch, IsDWtItem)
+	return plt
 }
 
 func (ss *Sim) ConfigSlpCycPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
 	plt.Params.Title = "Leabra Random Associator 25 Sleep Cycle Plot"
 	plt.Params.XAxisCol = "Cycle"
+	ss.ConfigLogPlot(plt, "Sleep", leabra.Cycle, nil)
+	return plt
+}
+
+// ConfigSlpGiPlot configures the plot of the instantaneous Gi of each layer
+// and the Gi oscillation (its phase and gain) by sleep cycle, from the same
+// SlpCycLog as the SlpCycPlot, and updated with it
+func (ss *Sim) ConfigSlpGiPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Sleep Gi Oscillation Plot"
+	plt.Params.XAxisCol = "Cycle"
 	plt.SetTable(dt)
 	// order of params: on, fixMin, min, fixMax, max
-	plt.SetColParams("Cycle", false, true, 0, false, 0)
-	plt.SetColParams("AvgLaySim", true, true, -1, true, 1)
-	plt.SetColParams("Input LaySim", true, true, -1, true, 1)
-	plt.SetColParams("BlaNeIn LaySim", true, true, -1, true, 1)
-	plt.SetColParams("BlaPoIn LaySim", true, true, -1, true, 1)
-	plt.SetColParams("Hidden1 LaySim", true, true, -1, true, 1)
-	plt.SetColParams("Output LaySim", true, true, -1, true, 1)
-	plt.SetColParams("BlaNeOut LaySim", true, true, -1, true, 1)
-	plt.SetColParams("BlaPoOut LaySim", true, true, -1, true, 1)
+	for _, it := range ss.Logs.ModeItems("Sleep", leabra.Cycle) {
+		plt.SetColParams(it.Name, false, true, 0, false, 0)
+	}
+	for _, ln := range SlpLays {
+		plt.SetColParams(ln[1]+" Gi", true, true, 0, false, 0)
+	}
+	plt.SetColParams("Osc Phase", true, true, 0, true, 2)
+	plt.SetColParams("Osc Gain", true, true, 0, true, 2)
 	return plt
 }
 
 //////////////////////////////////////////////
 //  TrnEpcLog
 
-// LogTrnEpc adds data from current epoch to the TrnEpcLog table.
-// computes epoch averages prior to logging.
+// LogTrnEpc computes the epoch averages of the training stats, and logs
+// them in the TrnEpcLog with the other items declared in ConfigLogs.
 func (ss *Sim) LogTrnEpc(dt *etable.Table) {
-	row := dt.Rows
-	ss.TrnEpcLog.SetNumRows(row + 1)
-
-	hid1Lay := ss.Net.LayerByName("Hidden1").(*leabra.Layer)
-	outLay := ss.Net.LayerByName("Output").(*leabra.Layer)
-	blaNeOutLay := ss.Net.LayerByName("Ne_Out").(*leabra.Layer)
-	blaPoOutLay := ss.Net.LayerByName("Po_Out").(*leabra.Layer)
-
 	epc := ss.TrainEnv.Epoch.Prv           // this is triggered by increment so use previous value
 	nt := float64(ss.TrainEnv.Table.Len()) // number of trials in view
+	if ss.TrainEnv.EpochOrder != nil {
+		nt = float64(ss.TrainEnv.NPrv) // number of trials in the epoch
+	}
 
 	ss.EpcSSE = ss.SumSSE / nt
 	ss.SumSSE = 0
@@ -1169,203 +3344,154 @@ func (ss *Sim) LogTrnEpc(dt *etable.Table) {
 	ss.EpcPctCor = 1 - ss.EpcPctErr
 	ss.EpcCosDiff = ss.SumCosDiff / nt
 	ss.SumCosDiff = 0
+	ss.EpcRT = ss.SumRT / nt
+	ss.SumRT = 0
+	ss.EpcCycs = ss.SumCycs / nt
+	ss.SumCycs = 0
 	if ss.FirstZero < 0 && ss.EpcPctErr == 0 {
 		ss.FirstZero = epc
 	}
 
-	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
-	dt.SetCellFloat("Epoch", row, float64(epc))
-	dt.SetCellFloat("SSE", row, ss.EpcSSE)
-	dt.SetCellFloat("AvgSSE", row, ss.EpcAvgSSE)
-	dt.SetCellFloat("PctErr", row, ss.EpcPctErr)
-	dt.SetCellFloat("PctCor", row, ss.EpcPctCor)
-	dt.SetCellFloat("CosDiff", row, ss.EpcCosDiff)
-	dt.SetCellFloat("Hid1 ActAvg", row, float64(hid1Lay.Pools[0].ActAvg.ActPAvgEff))
-	dt.SetCellFloat("Out ActAvg", row, float64(outLay.Pools[0].ActAvg.ActPAvgEff))
-	dt.SetCellFloat("BlaNeOut ActAvg", row, float64(blaNeOutLay.Pools[0].ActAvg.ActPAvgEff))
-	dt.SetCellFloat("BlaPoOut ActAvg", row, float64(blaPoOutLay.Pools[0].ActAvg.ActPAvgEff))
+	ss.Net.SyncFromDevice()
+	ss.Net.EpochStats()
+	row := ss.Logs.Log("Train", leabra.Epoch)
+	if ss.TBoard != nil {
+		ss.TBoard.LogRow(dt, row, epc, "train/", "Run", "Epoch")
+		ss.TBoard.Scalar("sleep/AvgLaySim", epc, ss.AvgLaySim)
+		if ss.TBWts {
+			ss.TBoard.WtHistograms(ss.Net, epc, 30)
+		}
+		ss.TBoard.Flush()
+	}
+	if ss.MetricsAddr != "" {
+		ss.Metrics.Set("train_sse", ss.EpcSSE)
+		ss.Metrics.Set("train_pct_cor", ss.EpcPctCor)
+		ss.Metrics.Set("train_cos_diff", ss.EpcCosDiff)
+		ss.Metrics.Set("first_zero", float64(ss.FirstZero))
+		ss.Metrics.Set("avg_lay_sim", ss.AvgLaySim)
+	}
+	if ss.DashAddr != "" {
+		ss.Dash.AddPoint("Train Epoch", "PctCor", float64(epc), ss.EpcPctCor)
+		ss.Dash.AddPoint("Train Epoch", "CosDiff", float64(epc), ss.EpcCosDiff)
+		ss.Dash.AddPoint("Train Epoch", "AvgSSE", float64(epc), ss.EpcAvgSSE)
+		ss.DashLayers("train", "ActM")
+	}
+	if ss.Net.Profile {
+		ss.Net.ProfileLog(ss.ProfLog, epc)
+	}
+
+	ss.DWtStats.Reset()
+	if ss.Progress.On {
+		ss.Progress.Epoch(ss.TrainEnv.Run.Cur, ss.TrainEnv.Run.Max, epc+1, ss.MaxEpcs, ss.EpcPctCor)
+	}
+	if ss.ActDump.On {
+		ss.ActDump.WriteIndex() // so the records so far can be found if the run does not finish
+	}
 
 	// note: essential to use Go version of update when called from another goroutine
 	ss.TrnEpcPlot.GoUpdate()
-	if ss.TrnEpcFile != nil {
-		if ss.TrainEnv.Run.Cur == 0 && epc == 0 {
-			dt.WriteCSVHeaders(ss.TrnEpcFile, etable.Tab)
-		}
-		dt.WriteCSVRow(ss.TrnEpcFile, row, etable.Tab, true)
+	if ss.TrnDWtPlot != nil {
+		ss.TrnDWtPlot.GoUpdate()
 	}
 }
 
-func (ss *Sim) ConfigTrnEpcLog(dt *etable.Table) {
-	dt.SetMetaData("name", "TrnEpcLog")
-	dt.SetMetaData("desc", "Record of performance over epochs of training")
-	dt.SetMetaData("read-only", "true")
-	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
-
-	dt.SetFromSchema(etable.Schema{
-		{"Run", etensor.INT64, nil, nil},
-		{"Epoch", etensor.INT64, nil, nil},
-		{"SSE", etensor.FLOAT64, nil, nil},
-		{"AvgSSE", etensor.FLOAT64, nil, nil},
-		{"PctErr", etensor.FLOAT64, nil, nil},
-		{"PctCor", etensor.FLOAT64, nil, nil},
-		{"CosDiff", etensor.FLOAT64, nil, nil},
-		{"Hid1 ActAvg", etensor.FLOAT64, nil, nil},
-		{"Out ActAvg", etensor.FLOAT64, nil, nil},
-		{"BlaNeOut ActAvg", etensor.FLOAT64, nil, nil},
-		{"BlaPoOut ActAvg", etensor.FLOAT64, nil, nil},
-	}, 0)
+// DashLayers sets the heatmap of each layer of the Dash to given variable
+// of its units, and its status to the counters of given state
+func (ss *Sim) DashLayers(state, vr string) {
+	ss.Dash.SetStatus(strings.Join(strings.Fields(ss.Counters(state)), " "))
+	for _, lyi := range ss.Net.Layers {
+		ly := lyi.(*leabra.Layer)
+		ss.Dash.SetHeatmap(ly.Name(), ly.UnitValsTensor(vr))
+	}
 }
 
+// ConfigTrnEpcPlot configures the plot of the TrnEpcLog, without the
+// learning of each projection, which is in the TrnDWtPlot
 func (ss *Sim) ConfigTrnEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
 	plt.Params.Title = "Leabra Random Associator 25 Epoch Plot"
 	plt.Params.XAxisCol = "Epoch"
-	plt.SetTable(dt)
-	// order of params: on, fixMin, min, fixMax, max
-	plt.SetColParams("Run", false, true, 0, false, 0)
-	plt.SetColParams("Epoch", false, true, 0, false, 0)
-	plt.SetColParams("SSE", false, true, 0, false, 0)
-	plt.SetColParams("AvgSSE", false, true, 0, false, 0)
-	plt.SetColParams("PctErr", true, true, 0, true, 1) // default plot
-	plt.SetColParams("PctCor", true, true, 0, true, 1) // default plot
-	plt.SetColParams("CosDiff", false, true, 0, true, 1)
-	plt.SetColParams("Hid1 ActAvg", false, true, 0, true, .5)
-	plt.SetColParams("Out ActAvg", false, true, 0, true, .5)
-	plt.SetColParams("BlaNeOut ActAvg", false, true, 0, true, .5)
-	plt.SetColParams("BlaPoOut ActAvg", false, true, 0, true, .5)
+	ss.ConfigLogPlot(plt, "Train", leabra.Epoch, func(it *leabra.LogItem) bool { return !IsDWtItem(it) })
 	return plt
 }
 
 //////////////////////////////////////////////
 //  TstTrlLog
 
-// LogTstTrl adds data from current trial to the TstTrlLog table.
-// log always contains number of testing items
+// LogTstTrl logs the current test trial in the TstTrlLog, in the row of the
+// trial -- log always contains number of testing items
 func (ss *Sim) LogTstTrl(dt *etable.Table) {
-	inLay := ss.Net.LayerByName("Input").(*leabra.Layer)
-	blaNeInLay := ss.Net.LayerByName("Ne").(*leabra.Layer)
-	blaPoInLay := ss.Net.LayerByName("Po").(*leabra.Layer)
-	hid1Lay := ss.Net.LayerByName("Hidden1").(*leabra.Layer)
-	outLay := ss.Net.LayerByName("Output").(*leabra.Layer)
-	blaNeOutLay := ss.Net.LayerByName("Ne_Out").(*leabra.Layer)
-	blaPoOutLay := ss.Net.LayerByName("Po_Out").(*leabra.Layer)
-
-	epc := ss.TrainEnv.Epoch.Prv // this is triggered by increment so use previous value
-
-	trl := ss.TestEnv.Trial.Cur
-
-	dt.SetCellFloat("Run", trl, float64(ss.TrainEnv.Run.Cur))
-	dt.SetCellFloat("Epoch", trl, float64(epc))
-	dt.SetCellFloat("Trial", trl, float64(trl))
-	dt.SetCellString("TrialName", trl, ss.TestEnv.TrialName)
-	dt.SetCellFloat("SSE", trl, ss.TrlSSE)
-	dt.SetCellFloat("AvgSSE", trl, ss.TrlAvgSSE)
-	dt.SetCellFloat("CosDiff", trl, ss.TrlCosDiff)
-	dt.SetCellFloat("Hid1 ActM.Avg", trl, float64(hid1Lay.Pools[0].ActM.Avg))
-	dt.SetCellFloat("Out ActM.Avg", trl, float64(outLay.Pools[0].ActM.Avg))
-	dt.SetCellFloat("BlaNeOut ActM.Avg", trl, float64(blaNeOutLay.Pools[0].ActM.Avg))
-	dt.SetCellFloat("BlaPoOut ActM.Avg", trl, float64(blaPoOutLay.Pools[0].ActM.Avg))
-
-	dt.SetCellTensor("InAct", trl, inLay.UnitValsTensor("Act"))
-	dt.SetCellTensor("BlaNeInAct", trl, blaNeInLay.UnitValsTensor("Act"))
-	dt.SetCellTensor("BlaPoInAct", trl, blaPoInLay.UnitValsTensor("Act"))
-	dt.SetCellTensor("OutActM", trl, outLay.UnitValsTensor("ActM"))
-	dt.SetCellTensor("OutActP", trl, outLay.UnitValsTensor("ActP"))
-	dt.SetCellTensor("BlaNeOutAct", trl, blaNeOutLay.UnitValsTensor("Act"))
-	dt.SetCellTensor("BlaPoOutAct", trl, blaPoOutLay.UnitValsTensor("Act"))
+	ss.Logs.LogRow("Test", leabra.Trial, ss.TestEnv.Trial.Cur)
 
 	// note: essential to use Go version of update when called from another goroutine
 	ss.TstTrlPlot.GoUpdate()
 }
 
-func (ss *Sim) ConfigTstTrlLog(dt *etable.Table) {
-	inLay := ss.Net.LayerByName("Input").(*leabra.Layer)
-	blaNeInLay := ss.Net.LayerByName("Ne").(*leabra.Layer)
-	blaPoInLay := ss.Net.LayerByName("Po").(*leabra.Layer)
-	outLay := ss.Net.LayerByName("Output").(*leabra.Layer)
-	blaNeOutLay := ss.Net.LayerByName("Ne_Out").(*leabra.Layer)
-	blaPoOutLay := ss.Net.LayerByName("Po_Out").(*leabra.Layer)
-
-	dt.SetMetaData("name", "TstTrlLog")
-	dt.SetMetaData("desc", "Record of testing per input pattern")
-	dt.SetMetaData("read-only", "true")
-	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
-
-	nt := ss.TestEnv.Table.Len() // number in view
-	dt.SetFromSchema(etable.Schema{
-		{"Run", etensor.INT64, nil, nil},
-		{"Epoch", etensor.INT64, nil, nil},
-		{"Trial", etensor.INT64, nil, nil},
-		{"TrialName", etensor.STRING, nil, nil},
-		{"SSE", etensor.FLOAT64, nil, nil},
-		{"AvgSSE", etensor.FLOAT64, nil, nil},
-		{"CosDiff", etensor.FLOAT64, nil, nil},
-		{"Hid1 ActM.Avg", etensor.FLOAT64, nil, nil},
-		{"Out ActM.Avg", etensor.FLOAT64, nil, nil},
-		{"BlaNeOut ActM.Avg", etensor.FLOAT64, nil, nil},
-		{"BlaPoOut ActM.Avg", etensor.FLOAT64, nil, nil},
-		{"InAct", etensor.FLOAT64, inLay.Shp.Shp, nil},
-		{"BlaNeInAct", etensor.FLOAT64, blaNeInLay.Shp.Shp, nil},
-		{"BlaPoInAct", etensor.FLOAT64, blaPoInLay.Shp.Shp, nil},
-		{"BlaNeOutAct", etensor.FLOAT64, blaNeOutLay.Shp.Shp, nil},
-		{"BlaPoOutAct", etensor.FLOAT64, blaPoOutLay.Shp.Shp, nil},
-		{"OutActM", etensor.FLOAT64, outLay.Shp.Shp, nil},
-		{"OutActP", etensor.FLOAT64, outLay.Shp.Shp, nil},
-	}, nt)
-}
-
 func (ss *Sim) ConfigTstTrlPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
 	plt.Params.Title = "Leabra Random Associator 25 Test Trial Plot"
 	plt.Params.XAxisCol = "Trial"
+	ss.ConfigLogPlot(plt, "Test", leabra.Trial, nil)
+	return plt
+}
+
+func (ss *Sim) ConfigRepPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Hidden1 Representation Space over Tests"
+	plt.Params.XAxisCol = "X"
+	plt.Params.LegendCol = "Name"
+	plt.Params.Points = true
+	plt.SetTable(dt)
+	// order of params: on, fixMin, min, fixMax, max
+	plt.SetColParams("Name", false, true, 0, false, 0)
+	plt.SetColParams("Stage", false, true, 0, false, 0)
+	plt.SetColParams("StageIdx", false, true, 0, false, 0)
+	plt.SetColParams("X", false, false, 0, false, 0)
+	plt.SetColParams("Y", true, false, 0, false, 0)
+	return plt
+}
+
+func (ss *Sim) ConfigPatCompPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Pattern Completion by Cue Degradation"
+	plt.Params.XAxisCol = "Frac"
+	plt.Params.LegendCol = "Epoch"
+	plt.Params.Points = true
 	plt.SetTable(dt)
 	// order of params: on, fixMin, min, fixMax, max
 	plt.SetColParams("Run", false, true, 0, false, 0)
 	plt.SetColParams("Epoch", false, true, 0, false, 0)
-	plt.SetColParams("Trial", false, true, 0, false, 0)
-	plt.SetColParams("TrialName", false, true, 0, false, 0)
+	plt.SetColParams("Level", false, true, 0, false, 0)
+	plt.SetColParams("Frac", false, true, 0, true, 1)
+	plt.SetColParams("Noise", false, true, 0, false, 0)
+	plt.SetColParams("N", false, true, 0, false, 0)
+	plt.SetColParams("PctCor", true, true, 0, true, 1)
 	plt.SetColParams("SSE", false, true, 0, false, 0)
-	plt.SetColParams("AvgSSE", true, true, 0, false, 0)
 	plt.SetColParams("CosDiff", true, true, 0, true, 1)
-	plt.SetColParams("Hid1 ActM.Avg", true, true, 0, true, .5)
-	plt.SetColParams("Out ActM.Avg", true, true, 0, true, .5)
-	plt.SetColParams("BlaNeOut ActM.Avg", true, true, 0, true, .5)
-	plt.SetColParams("BlaPoOut ActM.Avg", true, true, 0, true, .5)
-
-	plt.SetColParams("InAct", false, true, 0, true, 1)
-	plt.SetColParams("BlaNeInAct", false, true, 0, true, 1)
-	plt.SetColParams("BlaPoInAct", false, true, 0, true, 1)
-	plt.SetColParams("OutActM", false, true, 0, true, 1)
-	plt.SetColParams("OutActP", false, true, 0, true, 1)
-	plt.SetColParams("BlaNeOutAct", false, true, 0, true, 1)
-	plt.SetColParams("BlaPoOutAct", false, true, 0, true, 1)
 	return plt
 }
 
 //////////////////////////////////////////////
 //  TstEpcLog
 
+// LogTstEpc logs the stats of the last test in the TstEpcLog, aggregated
+// from the TstTrlLog (see ConfigLogs), and collects the error trials
 func (ss *Sim) LogTstEpc(dt *etable.Table) {
-	row := dt.Rows
-	dt.SetNumRows(row + 1)
-
-	trl := ss.TstTrlLog
-	tix := etable.NewIdxView(trl)
 	epc := ss.TrainEnv.Epoch.Prv // ?
+	row := ss.Logs.Log("Test", leabra.Epoch)
+	if ss.TBoard != nil {
+		ss.TBoard.LogRow(dt, row, epc, "test/", "Run", "Epoch")
+		ss.TBoard.Flush()
+	}
+	if ss.MetricsAddr != "" {
+		ss.Metrics.Set("test_pct_cor", dt.CellFloat("PctCor", row))
+	}
+	if ss.DashAddr != "" {
+		sfx := ""
+		if list := ss.TestListName(); list != "" {
+			sfx = ":" + list
+		}
+		ss.Dash.AddPoint("Test Epoch", "PctCor"+sfx, float64(epc), dt.CellFloat("PctCor", row))
+		ss.Dash.AddPoint("Test Epoch", "CosDiff"+sfx, float64(epc), dt.CellFloat("CosDiff", row))
+	}
 
-	// note: this shows how to use agg methods to compute summary data from another
-	// data table, instead of incrementing on the Sim
-	dt.SetCellFloat("Run", row, float64(ss.TrainEnv.Run.Cur))
-	dt.SetCellFloat("Epoch", row, float64(epc))
-	dt.SetCellFloat("SSE", row, agg.Sum(tix, "SSE")[0])
-	dt.SetCellFloat("AvgSSE", row, agg.Mean(tix, "AvgSSE")[0])
-	dt.SetCellFloat("PctErr", row, agg.PropIf(tix, "SSE", func(idx int, val float64) bool {
-		return val > 0
-	})[0])
-	dt.SetCellFloat("PctCor", row, agg.PropIf(tix, "SSE", func(idx int, val float64) bool {
-		return val == 0
-	})[0])
-	dt.SetCellFloat("CosDiff", row, agg.Mean(tix, "CosDiff")[0])
-
-	trlix := etable.NewIdxView(trl)
+	trlix := etable.NewIdxView(ss.TstTrlLog)
 	trlix.Filter(func(et *etable.Table, row int) bool {
 		return et.CellFloat("SSE", row) > 0 // include error trials
 	})
@@ -1388,35 +3514,17 @@ func (ss *Sim) LogTstEpc(dt *etable.Table) {
 	ss.TstEpcPlot.GoUpdate()
 }
 
-func (ss *Sim) ConfigTstEpcLog(dt *etable.Table) {
-	dt.SetMetaData("name", "TstEpcLog")
-	dt.SetMetaData("desc", "Summary stats for testing trials")
-	dt.SetMetaData("read-only", "true")
-	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
-
-	dt.SetFromSchema(etable.Schema{
-		{"Run", etensor.INT64, nil, nil},
-		{"Epoch", etensor.INT64, nil, nil},
-		{"SSE", etensor.FLOAT64, nil, nil},
-		{"AvgSSE", etensor.FLOAT64, nil, nil},
-		{"PctErr", etensor.FLOAT64, nil, nil},
-		{"PctCor", etensor.FLOAT64, nil, nil},
-		{"CosDiff", etensor.FLOAT64, nil, nil},
-	}, 0)
-}
-
 func (ss *Sim) ConfigTstEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
 	plt.Params.Title = "Leabra Random Associator 25 Testing Epoch Plot"
 	plt.Params.XAxisCol = "Epoch"
-	plt.SetTable(dt)
+	ss.ConfigLogPlot(plt, "Test", leabra.Epoch, nil)
 	// order of params: on, fixMin, min, fixMax, max
-	plt.SetColParams("Run", false, true, 0, false, 0)
-	plt.SetColParams("Epoch", false, true, 0, false, 0)
-	plt.SetColParams("SSE", false, true, 0, false, 0)
-	plt.SetColParams("AvgSSE", false, true, 0, false, 0)
-	plt.SetColParams("PctErr", true, true, 0, true, 1) // default plot
-	plt.SetColParams("PctCor", true, true, 0, true, 1) // default plot
-	plt.SetColParams("CosDiff", false, true, 0, true, 1)
+	for _, cs := range []*leabra.CatStats{&ss.CatStats, &ss.StudyStats, &ss.GenStats} {
+		for _, tag := range cs.Tags {
+			plt.SetColParams(cs.ColName("PctCor", tag), false, true, 0, true, 1)
+			plt.SetColParams(cs.ColName("CosDiff", tag), false, true, 0, true, 1)
+		}
+	}
 	return plt
 }
 
@@ -1426,24 +3534,7 @@ func (ss *Sim) ConfigTstEpcPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot
 // LogTstCyc adds data from current trial to the TstCycLog table.
 // log just has 100 cycles, is overwritten
 func (ss *Sim) LogTstCyc(dt *etable.Table, cyc int) {
-	if dt.Rows <= cyc {
-		dt.SetNumRows(cyc + 1)
-	}
-
-	hid1Lay := ss.Net.LayerByName("Hidden1").(*leabra.Layer)
-	outLay := ss.Net.LayerByName("Output").(*leabra.Layer)
-	blaNeOutLay := ss.Net.LayerByName("Ne_Out").(*leabra.Layer)
-	blaPoOutLay := ss.Net.LayerByName("Po_Out").(*leabra.Layer)
-
-	dt.SetCellFloat("Cycle", cyc, float64(cyc))
-	dt.SetCellFloat("Hid1 Ge.Avg", cyc, float64(hid1Lay.Pools[0].Ge.Avg))
-	dt.SetCellFloat("Out Ge.Avg", cyc, float64(outLay.Pools[0].Ge.Avg))
-	dt.SetCellFloat("BlaNeOut Ge.Avg", cyc, float64(blaNeOutLay.Pools[0].Ge.Avg))
-	dt.SetCellFloat("BlaPoOut Ge.Avg", cyc, float64(blaPoOutLay.Pools[0].Ge.Avg))
-	dt.SetCellFloat("Hid1 Act.Avg", cyc, float64(hid1Lay.Pools[0].Act.Avg))
-	dt.SetCellFloat("Out Act.Avg", cyc, float64(outLay.Pools[0].Act.Avg))
-	dt.SetCellFloat("BlaNeOut Act.Avg", cyc, float64(blaNeOutLay.Pools[0].Act.Avg))
-	dt.SetCellFloat("BlaPoOut Act.Avg", cyc, float64(blaPoOutLay.Pools[0].Act.Avg))
+	ss.Logs.LogRow("Test", leabra.Cycle, cyc)
 
 	if cyc%10 == 0 { // too slow to do every cyc
 		// note: essential to use Go version of update when called from another goroutine
@@ -1451,115 +3542,129 @@ func (ss *Sim) LogTstCyc(dt *etable.Table, cyc int) {
 	}
 }
 
-func (ss *Sim) ConfigTstCycLog(dt *etable.Table) {
-	dt.SetMetaData("name", "TstCycLog")
-	dt.SetMetaData("desc", "Record of activity etc over one trial by cycle")
-	dt.SetMetaData("read-only", "true")
-	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
-
-	np := 100 // max cycles
-	dt.SetFromSchema(etable.Schema{
-		{"Cycle", etensor.INT64, nil, nil},
-		{"Hid1 Ge.Avg", etensor.FLOAT64, nil, nil},
-		{"Out Ge.Avg", etensor.FLOAT64, nil, nil},
-		{"BlaNeOut Ge.Avg", etensor.FLOAT64, nil, nil},
-		{"BlaPoOut Ge.Avg", etensor.FLOAT64, nil, nil},
-		{"Hid1 Act.Avg", etensor.FLOAT64, nil, nil},
-		{"Out Act.Avg", etensor.FLOAT64, nil, nil},
-		{"BlaNeOut Act.Avg", etensor.FLOAT64, nil, nil},
-		{"BlaPoOut Act.Avg", etensor.FLOAT64, nil, nil},
-	}, np)
-}
-
 func (ss *Sim) ConfigTstCycPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
 	plt.Params.Title = "Leabra Random Associator 25 Test Cycle Plot"
 	plt.Params.XAxisCol = "Cycle"
-	plt.SetTable(dt)
-	// order of params: on, fixMin, min, fixMax, max
-	plt.SetColParams("Cycle", false, true, 0, false, 0)
-	plt.SetColParams("Hid1 Ge.Avg", true, true, 0, true, .5)
-	plt.SetColParams("Out Ge.Avg", true, true, 0, true, .5)
-	plt.SetColParams("BlaNeOut Ge.Avg", true, true, 0, true, .5)
-	plt.SetColParams("BlaPoOut Ge.Avg", true, true, 0, true, .5)
-	plt.SetColParams("Hid1 Act.Avg", true, true, 0, true, .5)
-	plt.SetColParams("Out Act.Avg", true, true, 0, true, .5)
-	plt.SetColParams("BlaNeOut Act.Avg", true, true, 0, true, .5)
-	plt.SetColParams("BlaPoOut Act.Avg", true, true, 0, true, .5)
+	ss.ConfigLogPlot(plt, "Test", leabra.Cycle, nil)
 	return plt
 }
 
 //////////////////////////////////////////////
 //  RunLog
 
-// LogRun adds data from current run to the RunLog table.
+// LogRun logs the current run in the RunLog (see ConfigLogs), and updates
+// the stats and comparisons of the runs
 func (ss *Sim) LogRun(dt *etable.Table) {
-	run := ss.TrainEnv.Run.Cur // this is NOT triggered by increment yet -- use Cur
-	row := dt.Rows
-	ss.RunLog.SetNumRows(row + 1)
-
-	epclog := ss.TrnEpcLog
-	// compute mean over last N epochs for run level
-	nlast := 10
-	epcix := etable.NewIdxView(epclog)
-	epcix.Idxs = epcix.Idxs[epcix.Len()-nlast-1:]
-
-	params := ss.ParamsName()
-
-	dt.SetCellFloat("Run", row, float64(run))
-	dt.SetCellString("Params", row, params)
-	dt.SetCellFloat("FirstZero", row, float64(ss.FirstZero))
-	dt.SetCellFloat("SSE", row, agg.Mean(epcix, "SSE")[0])
-	dt.SetCellFloat("AvgSSE", row, agg.Mean(epcix, "AvgSSE")[0])
-	dt.SetCellFloat("PctErr", row, agg.Mean(epcix, "PctErr")[0])
-	dt.SetCellFloat("PctCor", row, agg.Mean(epcix, "PctCor")[0])
-	dt.SetCellFloat("CosDiff", row, agg.Mean(epcix, "CosDiff")[0])
+	ss.Logs.Log("Train", leabra.Run)
 
 	runix := etable.NewIdxView(dt)
 	spl := split.GroupBy(runix, []string{"Params"})
 	split.Desc(spl, "FirstZero")
 	split.Desc(spl, "PctCor")
 	ss.RunStats = spl.AggsToTable(false)
+	// medians and bootstrap CIs, which hold up better than Desc for the few runs
+	// per condition typically done -- fixed seed so they are reproducible
+	rnd := rand.New(rand.NewSource(ss.RndSeed))
+	if bt, err := leabra.AddBootStats(ss.RunStats, dt, "Params", []string{"FirstZero", "PctCor"}, RunBootN, 0.95, rnd); err == nil {
+		ss.RunStats = bt
+	}
+
+	ss.RunCmp.Reset()
+	ss.RunCmp.AddRuns(dt, "")
+	ss.RunCmp.Compute()
 
 	// note: essential to use Go version of update when called from another goroutine
 	ss.RunPlot.GoUpdate()
-	if ss.RunFile != nil {
-		if row == 0 {
-			dt.WriteCSVHeaders(ss.RunFile, etable.Tab)
-		}
-		dt.WriteCSVRow(ss.RunFile, row, etable.Tab, true)
+	if ss.RunCmpPlot != nil {
+		ss.RunCmpPlot.GoUpdate()
 	}
 }
 
-func (ss *Sim) ConfigRunLog(dt *etable.Table) {
-	dt.SetMetaData("name", "RunLog")
-	dt.SetMetaData("desc", "Record of performance at end of training")
-	dt.SetMetaData("read-only", "true")
-	dt.SetMetaData("precision", strconv.Itoa(LogPrec))
-
-	dt.SetFromSchema(etable.Schema{
-		{"Run", etensor.INT64, nil, nil},
-		{"Params", etensor.STRING, nil, nil},
-		{"FirstZero", etensor.FLOAT64, nil, nil},
-		{"SSE", etensor.FLOAT64, nil, nil},
-		{"AvgSSE", etensor.FLOAT64, nil, nil},
-		{"PctErr", etensor.FLOAT64, nil, nil},
-		{"PctCor", etensor.FLOAT64, nil, nil},
-		{"CosDiff", etensor.FLOAT64, nil, nil},
-	}, 0)
+// RunParams returns the name of the params of the current run, with the
+// number of patterns if SetSize is on, as each set size is its own
+// condition in the RunStats
+func (ss *Sim) RunParams() string {
+	params := ss.ParamsName()
+	if ss.SetSize.On() {
+		params += fmt.Sprintf("_N%d", ss.TrainEnv.Table.Len())
+	}
+	return params
 }
 
 func (ss *Sim) ConfigRunPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
 	plt.Params.Title = "Leabra Random Associator 25 Run Plot"
 	plt.Params.XAxisCol = "Run"
+	ss.ConfigLogPlot(plt, "Train", leabra.Run, nil)
+	return plt
+}
+
+// ConfigRunCmp configures the comparison of the run stats across conditions
+// -- runs that never reached zero errors (FirstZero = -1) are excluded from
+// the FirstZero stats
+func (ss *Sim) ConfigRunCmp() {
+	ss.RunCmp.Stats = []string{"FirstZero", "PctCor", "TstPctCor", "SlpLaySim"}
+	ss.RunCmp.Valid = func(stat string, val float64) bool {
+		return stat != "FirstZero" || val >= 0
+	}
+	ss.RunCmp.Reset()
+	ss.RunCmp.Compute()
+}
+
+// CompareRunLogs compares the run stats of the current RunLog (if it has
+// any runs, as condition RunName) with those of the run logs saved in the
+// given files, separated by , -- the condition of each file is its name
+// without the network name prefix and the _run.csv suffix, i.e., the tag and
+// ParamSet -- or, if RunCmp.GroupCol is set (e.g., Params or Tag), the
+// conditions are the values of that column, which can differ across the
+// runs of a file.  The first condition is the baseline for the differences
+// and t-tests.  Results are in RunCmp, shown in the RunCmpPlot, with the
+// t-tests and ANOVA in RunCmp.Tests.
+func (ss *Sim) CompareRunLogs(files string) error {
+	ss.RunCmp.Reset()
+	if ss.RunLog.Rows > 0 {
+		cond := ss.RunName()
+		if ss.RunCmp.GroupCol != "" {
+			cond = ""
+		}
+		ss.RunCmp.AddRuns(ss.RunLog, cond)
+	}
+	for _, fnm := range strings.Split(files, ",") {
+		fnm = strings.TrimSpace(fnm)
+		if fnm == "" {
+			continue
+		}
+		cond := strings.TrimSuffix(filepath.Base(fnm), ".csv")
+		cond = strings.TrimSuffix(strings.TrimPrefix(cond, ss.Net.Nm+"_"), "_run")
+		if ss.RunCmp.GroupCol != "" {
+			cond = ""
+		}
+		if err := ss.RunCmp.OpenRunLog(gi.FileName(fnm), cond); err != nil {
+			return err
+		}
+	}
+	ss.RunCmp.Compute()
+	if ss.RunCmpPlot != nil {
+		ss.RunCmpPlot.GoUpdate()
+	}
+	return nil
+}
+
+func (ss *Sim) ConfigRunCmpPlot(plt *eplot.Plot2D, dt *etable.Table) *eplot.Plot2D {
+	plt.Params.Title = "Comparison of Run Stats across Conditions"
+	plt.Params.XAxisCol = "CondIdx"
+	plt.Params.Points = true
 	plt.SetTable(dt)
 	// order of params: on, fixMin, min, fixMax, max
-	plt.SetColParams("Run", false, true, 0, false, 0)
-	plt.SetColParams("FirstZero", true, true, 0, false, 0) // default plot
-	plt.SetColParams("SSE", false, true, 0, false, 0)
-	plt.SetColParams("AvgSSE", false, true, 0, false, 0)
-	plt.SetColParams("PctErr", false, true, 0, true, 1)
-	plt.SetColParams("PctCor", false, true, 0, true, 1)
-	plt.SetColParams("CosDiff", false, true, 0, true, 1)
+	plt.SetColParams("CondIdx", false, true, 0, false, 0)
+	plt.SetColParams("N", false, true, 0, false, 0)
+	for _, st := range ss.RunCmp.Stats {
+		on := st == "FirstZero"
+		plt.SetColParams(st+":Mean", on, true, 0, false, 0)
+		plt.SetColParams(st+":CILo", on, true, 0, false, 0)
+		plt.SetColParams(st+":CIHi", on, true, 0, false, 0)
+		plt.SetColParams(st+":Diff", false, false, 0, false, 0)
+		plt.SetColParams(st+":P", false, true, 0, true, 1)
+	}
 	return plt
 }
 
@@ -1619,13 +3724,39 @@ func (ss *Sim) ConfigGui() *gi.Window {
 	plt = tv.AddNewTab(eplot.KiT_Plot2D, "SlpCycPlot").(*eplot.Plot2D)
 	ss.SlpCycPlot = ss.ConfigSlpCycPlot(plt, ss.SlpCycLog)
 
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "SlpGiPlot").(*eplot.Plot2D)
+	ss.SlpGiPlot = ss.ConfigSlpGiPlot(plt, ss.SlpCycLog)
+
+	ss.SynInspPlot = tv.AddNewTab(eplot.KiT_Plot2D, "SynInspPlot").(*eplot.Plot2D) // configured by InspectSynapse
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "TrnDWtPlot").(*eplot.Plot2D)
+	ss.TrnDWtPlot = ss.ConfigTrnDWtPlot(plt, ss.TrnEpcLog)
+
 	plt = tv.AddNewTab(eplot.KiT_Plot2D, "RunPlot").(*eplot.Plot2D)
 	ss.RunPlot = ss.ConfigRunPlot(plt, ss.RunLog)
 
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "RunCmpPlot").(*eplot.Plot2D)
+	ss.RunCmpPlot = ss.ConfigRunCmpPlot(plt, ss.RunCmp.Table)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "RepPlot").(*eplot.Plot2D)
+	ss.RepPlot = ss.ConfigRepPlot(plt, ss.RepProj)
+
+	plt = tv.AddNewTab(eplot.KiT_Plot2D, "PatCompPlot").(*eplot.Plot2D)
+	ss.PatCompPlot = ss.ConfigPatCompPlot(plt, ss.PatComp.Results)
+
+	// edits to Val are applied immediately to the running network
+	ss.LiveView = tv.AddNewTab(giv.KiT_TableView, "LiveParams").(*giv.TableView)
+	ss.LiveView.SetSlice(&ss.Live.Params)
+	ss.LiveView.ViewSig.Connect(win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ss.ApplyLive()
+	})
+	ss.LiveLogView = tv.AddNewTab(giv.KiT_TableView, "ParamLog").(*giv.TableView)
+	ss.LiveLogView.SetSlice(&ss.Live.Log)
+
 	split.SetSplits(.3, .7)
 
 	tbar.AddAction(gi.ActOpts{Label: "Init", Icon: "update", Tooltip: "Initialize everything including network weights, and start over.  Also applies current params.", UpdateFunc: func(act *gi.Action) {
-		act.SetActiveStateUpdt(!ss.IsRunning)
+		act.SetActiveStateUpdt(!ss.IsRunning && !ss.SlpStepping)
 	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		ss.Init()
 		vp.SetNeedsFullRender()
@@ -1633,7 +3764,7 @@ func (ss *Sim) ConfigGui() *gi.Window {
 
 	tbar.AddAction(gi.ActOpts{Label: "Train", Icon: "run", Tooltip: "Starts the network training, picking up from wherever it may have left off.  If not stopped, training will complete the specified number of Runs through the full number of Epochs of training, with testing automatically occuring at the specified interval.",
 		UpdateFunc: func(act *gi.Action) {
-			act.SetActiveStateUpdt(!ss.IsRunning)
+			act.SetActiveStateUpdt(!ss.IsRunning && !ss.SlpStepping)
 		}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		if !ss.IsRunning {
 			ss.IsRunning = true
@@ -1650,7 +3781,7 @@ func (ss *Sim) ConfigGui() *gi.Window {
 	})
 
 	tbar.AddAction(gi.ActOpts{Label: "Step Trial", Icon: "step-fwd", Tooltip: "Advances one training trial at a time.", UpdateFunc: func(act *gi.Action) {
-		act.SetActiveStateUpdt(!ss.IsRunning)
+		act.SetActiveStateUpdt(!ss.IsRunning && !ss.SlpStepping)
 	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		if !ss.IsRunning {
 			ss.IsRunning = true
@@ -1661,7 +3792,7 @@ func (ss *Sim) ConfigGui() *gi.Window {
 	})
 
 	tbar.AddAction(gi.ActOpts{Label: "Step Epoch", Icon: "fast-fwd", Tooltip: "Advances one epoch (complete set of training patterns) at a time.", UpdateFunc: func(act *gi.Action) {
-		act.SetActiveStateUpdt(!ss.IsRunning)
+		act.SetActiveStateUpdt(!ss.IsRunning && !ss.SlpStepping)
 	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		if !ss.IsRunning {
 			ss.IsRunning = true
@@ -1671,7 +3802,7 @@ func (ss *Sim) ConfigGui() *gi.Window {
 	})
 
 	tbar.AddAction(gi.ActOpts{Label: "Step Run", Icon: "fast-fwd", Tooltip: "Advances one full training Run at a time.", UpdateFunc: func(act *gi.Action) {
-		act.SetActiveStateUpdt(!ss.IsRunning)
+		act.SetActiveStateUpdt(!ss.IsRunning && !ss.SlpStepping)
 	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		if !ss.IsRunning {
 			ss.IsRunning = true
@@ -1680,10 +3811,50 @@ func (ss *Sim) ConfigGui() *gi.Window {
 		}
 	})
 
+	tbar.AddSeparator("sleep")
+
+	tbar.AddAction(gi.ActOpts{Label: "Enter/Exit Sleep", Icon: "play", Tooltip: "Enters sleep, starting the next sleep trial to step through one cycle at a time -- or, if in sleep, ends the sleep trial and goes back to wake.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if !ss.IsRunning {
+			ss.IsRunning = true
+			if ss.SlpStepping {
+				ss.ExitSleep()
+			} else {
+				ss.EnterSleep()
+			}
+			ss.IsRunning = false
+			tbar.UpdateActions()
+			vp.SetNeedsFullRender()
+		}
+	})
+
+	tbar.AddAction(gi.ActOpts{Label: "Step Sleep Cycle", Icon: "step-fwd", Tooltip: "Advances one sleep cycle at a time, entering sleep if needed -- goes back to wake at the end of the sleep trial.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if !ss.IsRunning {
+			ss.IsRunning = true
+			ss.SleepStepCyc()
+			ss.IsRunning = false
+			tbar.UpdateActions()
+			vp.SetNeedsFullRender()
+		}
+	})
+
+	tbar.AddAction(gi.ActOpts{Label: "Step Sleep Trial", Icon: "fast-fwd", Tooltip: "Runs the rest of the sleep trial being stepped, or else one full sleep trial.", UpdateFunc: func(act *gi.Action) {
+		act.SetActiveStateUpdt(!ss.IsRunning)
+	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if !ss.IsRunning {
+			ss.IsRunning = true
+			tbar.UpdateActions()
+			go ss.SleepStepTrial()
+		}
+	})
+
 	tbar.AddSeparator("test")
 
 	tbar.AddAction(gi.ActOpts{Label: "Test Trial", Icon: "step-fwd", Tooltip: "Runs the next testing trial.", UpdateFunc: func(act *gi.Action) {
-		act.SetActiveStateUpdt(!ss.IsRunning)
+		act.SetActiveStateUpdt(!ss.IsRunning && !ss.SlpStepping)
 	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		if !ss.IsRunning {
 			ss.IsRunning = true
@@ -1694,7 +3865,7 @@ func (ss *Sim) ConfigGui() *gi.Window {
 	})
 
 	tbar.AddAction(gi.ActOpts{Label: "Test Item", Icon: "step-fwd", Tooltip: "Prompts for a specific input pattern name to run, and runs it in testing mode.", UpdateFunc: func(act *gi.Action) {
-		act.SetActiveStateUpdt(!ss.IsRunning)
+		act.SetActiveStateUpdt(!ss.IsRunning && !ss.SlpStepping)
 	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		gi.StringPromptDialog(vp, "", "Test Item",
 			gi.DlgOpts{Title: "Test Item", Prompt: "Enter the Name of a given input pattern to test (case insensitive, contains given string."},
@@ -1719,7 +3890,7 @@ func (ss *Sim) ConfigGui() *gi.Window {
 	})
 
 	tbar.AddAction(gi.ActOpts{Label: "Test All", Icon: "fast-fwd", Tooltip: "Tests all of the testing trials.", UpdateFunc: func(act *gi.Action) {
-		act.SetActiveStateUpdt(!ss.IsRunning)
+		act.SetActiveStateUpdt(!ss.IsRunning && !ss.SlpStepping)
 	}}, win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		if !ss.IsRunning {
 			ss.IsRunning = true
@@ -1738,6 +3909,11 @@ func (ss *Sim) ConfigGui() *gi.Window {
 
 	tbar.AddSeparator("misc")
 
+	tbar.AddAction(gi.ActOpts{Label: "Refresh Params", Icon: "update", Tooltip: "Update the LiveParams view to the param values currently in effect -- edit values there to apply them immediately to the running network, recorded in the ParamLog."}, win.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			ss.UpdateLive()
+		})
+
 	tbar.AddAction(gi.ActOpts{Label: "New Seed", Icon: "new", Tooltip: "Generate a new initial random seed to get different results.  By default, Init re-establishes the same initial seed every time."}, win.This(),
 		func(recv, send ki.Ki, sig int64, data interface{}) {
 			ss.NewRndSeed()
@@ -1824,14 +4000,99 @@ func (ss *Sim) ConfigGui() *gi.Window {
 var SimProps = ki.Props{
 	"CallMethods": ki.PropSlice{
 		{"SaveWeights", ki.Props{
-			"desc": "save network weights to file",
+			"desc": "save network weights to file, in json (.wts) or binary (.wtsb) format",
+			"icon": "file-save",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".wts,.wtsb",
+				}},
+			},
+		}},
+		{"SaveProfile", ki.Props{
+			"desc": "save the profile log of time per function per layer per epoch (turn on Net.Profile to record)",
 			"icon": "file-save",
 			"Args": ki.PropSlice{
 				{"File Name", ki.Props{
-					"ext": ".wts",
+					"ext": ".csv",
+				}},
+			},
+		}},
+		{"SaveCheckpoint", ki.Props{
+			"desc": "save complete training state to file, for exact resumption of training",
+			"icon": "file-save",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".ckpt",
+				}},
+			},
+		}},
+		{"OpenCheckpoint", ki.Props{
+			"desc": "restore complete training state from file saved by SaveCheckpoint",
+			"icon": "file-open",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".ckpt",
+				}},
+			},
+		}},
+		{"ProjectReps", ki.Props{
+			"desc": "project the Hidden1 representations of the test items at each test of the run into 2D with PCA (or MDS if RepSpace.MDS), shown in the RepPlot",
+			"icon": "update",
+		}},
+		{"OpenTestLists", ki.Props{
+			"desc": "open named lists of test items from a JSON file (see leabra.TestLists), to test with RunTestList",
+			"icon": "file-open",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
 				}},
 			},
 		}},
+		{"RunTestList", ki.Props{
+			"desc": "test the items of the named list of the TestLists, each with the noise and partial cue of the list or item, logged with the name of the list in the List column of the test logs",
+			"icon": "step-fwd",
+			"Args": ki.PropSlice{
+				{"List Name", ki.Props{}},
+			},
+		}},
+		{"InspectSynapse", ki.Props{
+			"desc": "inspect the synapse from the unit with given index of the sending layer to the unit of the receiving layer: all its variables (Wt, LWt, DWt, Cai, Effwt...) are shown in SynInsp, updated every sleep cycle and training trial, and traced in the SynInspPlot",
+			"icon": "search",
+			"Args": ki.PropSlice{
+				{"Send Layer", ki.Props{}},
+				{"Send Unit", ki.Props{}},
+				{"Recv Layer", ki.Props{}},
+				{"Recv Unit", ki.Props{}},
+			},
+		}},
+		{"ViewSynapses", ki.Props{
+			"desc": "view the synapses of the unit with given index of given layer in the NetView: the r. (received) and s. (sent) synapse variables of the other layers, e.g., r.Cai, r.Effwt, r.SRAvgDp, which drive the sleep dynamics",
+			"icon": "update",
+			"Args": ki.PropSlice{
+				{"Layer", ki.Props{}},
+				{"Unit", ki.Props{}},
+			},
+		}},
+		{"RunTestGen", ki.Props{
+			"desc": "run the generalization test of this run: the trained items, novel recombinations of their features and novel controls, with the stats of each type in the GenStats columns of the TstEpcLog -- GenTest must be On",
+			"icon": "step-fwd",
+		}},
+		{"RunTestPatComp", ki.Props{
+			"desc": "run the pattern-completion test: the test items with their input cues degraded at each of the PatComp.Levels, shown in the PatCompPlot",
+			"icon": "step-fwd",
+		}},
+		{"CompareRunLogs", ki.Props{
+			"desc": "compare the run stats of the current RunLog with those of saved run log files (separated by ,) -- means, confidence intervals and t-tests against the first condition, shown in the RunCmpPlot, with paired t-tests and ANOVA in RunCmp.Tests",
+			"icon": "file-open",
+			"Args": ki.PropSlice{
+				{"Run Logs", ki.Props{}},
+			},
+		}},
+		{"ResolvedParams", ki.Props{
+			"desc":        "show the current ParamSet resolved with the sets it extends: the final value of each param for each selector",
+			"icon":        "info",
+			"show-return": true,
+		}},
 		{"SaveParams", ki.Props{
 			"desc": "save parameters to file",
 			"icon": "file-save",
@@ -1841,58 +4102,1028 @@ var SimProps = ki.Props{
 				}},
 			},
 		}},
+		{"OpenCppParams", ki.Props{
+			"desc": "import params from the Leabra specs in a C++ emergent project, as the Cpp ParamSet",
+			"icon": "file-open",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".proj,.spec",
+				}},
+			},
+		}},
+		{"SaveCppParams", ki.Props{
+			"desc": "save the params applied to each layer and projection as C++ emergent specs",
+			"icon": "file-save",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".spec",
+				}},
+			},
+		}},
+		{"SaveParamLog", ki.Props{
+			"desc": "save the changelog of live param edits to file",
+			"icon": "file-save",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
 	},
 }
 
+// paramSetFlags collects the values of a repeated -set flag
+type paramSetFlags []string
+
+func (ps *paramSetFlags) String() string {
+	return strings.Join(*ps, " ")
+}
+
+func (ps *paramSetFlags) Set(val string) error {
+	*ps = append(*ps, val)
+	return nil
+}
+
+// SimCmds are the subcommands of the command line, each with its own flags
+var SimCmds = []struct{ Name, Desc string }{
+	{"train", "train the network for -runs runs, with sleep, testing and logging (the default if the first arg is a flag)"},
+	{"test", "test the network with the weights of -wts on the test items (and -testlist lists, -gentest), saving the test logs"},
+	{"sleep", "run -trials sleep trials of -cycles cycles each on the weights of -wts, saving the weights after sleep"},
+	{"analyze", "compare the run stats of the run log files of -logs, saving the comparison and its tests"},
+	{"batch", "run the train jobs of -jobs or each of -sets with each of -seeds, -procs at a time, saving their run logs collated into one file"},
+}
+
+// CmdUsage prints the subcommands
+func CmdUsage() {
+	fmt.Fprintf(os.Stderr, "usage: %v <command> [flags] -- %v <command> -h lists the flags of each command\n", filepath.Base(os.Args[0]), filepath.Base(os.Args[0]))
+	for _, cmd := range SimCmds {
+		fmt.Fprintf(os.Stderr, "  %-8s %v\n", cmd.Name, cmd.Desc)
+	}
+}
+
+// CmdArgs runs the subcommand of the command line (see SimCmds) with its
+// flags -- train if the first arg is a flag, as before the subcommands --
+// exits with status 1 if train or sleep was stopped by a signal (see
+// NotifyInterrupt) -- batch does not handle the signals itself: its train
+// jobs handle them as train does when they get them too, e.g., for Ctrl-C
+// in a terminal, which signals the whole process group
 func (ss *Sim) CmdArgs() {
+	cmd, args := "train", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+	switch cmd {
+	case "train":
+		ss.CmdTrain(args)
+	case "test":
+		ss.CmdTest(args)
+	case "sleep":
+		ss.CmdSleep(args)
+	case "analyze":
+		ss.CmdAnalyze(args)
+	case "batch":
+		ss.CmdBatch(args)
+	case "help":
+		CmdUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %v\n", cmd)
+		CmdUsage()
+		os.Exit(2)
+	}
+	if ss.IsInterrupted() {
+		os.Exit(1)
+	}
+}
+
+// CmdWts configures the params and the environments for a test or sleep
+// command, and opens the weights from given file
+func (ss *Sim) CmdWts(wts string, sets paramSetFlags) {
+	if wts == "" {
+		fmt.Fprintf(os.Stderr, "-wts weights file is required\n")
+		os.Exit(2)
+	}
+	for _, set := range sets {
+		if err := ss.Overrides.AddString(set); err != nil {
+			os.Exit(1)
+		}
+	}
+	ss.SetOverrides()
+	ss.Init()
+	if ss.ParamSet != "" {
+		fmt.Printf("Using ParamSet: %s\n", ss.ParamSet)
+	}
+	if err := ss.Net.OpenWts(gi.FileName(wts)); err != nil {
+		fmt.Fprintf(os.Stderr, "could not open weights from: %v: %v\n", wts, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Opened weights from: %v\n", wts)
+}
+
+// CmdTest tests the network with the weights of -wts: all the test items,
+// then the -testlist lists and the -gentest generalization test if set,
+// saving the test epoch and trial logs
+func (ss *Sim) CmdTest(args []string) {
+	ss.NoGui = true
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	var wts string
+	var sets paramSetFlags
+	var cue string
+	var testNoise string
+	var testLists string
+	var testListNms string
+	var genTest int
+	fs.StringVar(&wts, "wts", "", "weights file to test -- required")
+	fs.StringVar(&ss.ParamSet, "params", "", "ParamSet name to use -- must be valid name as listed in compiled-in params or loaded params")
+	fs.StringVar(&ss.Tag, "tag", "", "extra tag to add to file names saved from this test")
+	fs.Var(&sets, "set", "param value to set after the ParamSet, as [Sel ]Path=Val -- can be repeated")
+	fs.StringVar(&cue, "cue", "", "cued recall test cues, as for train -cue")
+	fs.StringVar(&testNoise, "testnoise", "", "noise added to the test inputs, as for train -testnoise")
+	fs.StringVar(&testLists, "testlists", "", "JSON file of named lists of test items, as for train")
+	fs.StringVar(&testListNms, "testlist", "", "names of -testlists lists tested after all the items, separated by ,")
+	fs.IntVar(&genTest, "gentest", 0, "if > 0, number of items of a generalization test, as for train")
+	fs.Parse(args)
+	if genTest > 0 {
+		ss.GenTest.On = true
+		ss.GenTest.NRecomb = genTest
+		ss.GenTest.NNovel = genTest
+		ss.Net = &leabra.Network{}
+		ss.Config()
+		ss.ConfigSalience()
+	}
+	if cue != "" {
+		if err := ss.CueTest.SetString(cue); err != nil {
+			os.Exit(1)
+		}
+	}
+	if testNoise != "" {
+		if err := ss.TestNoise.SetString(testNoise); err != nil {
+			os.Exit(1)
+		}
+	}
+	if testLists != "" {
+		if err := ss.OpenTestLists(gi.FileName(testLists)); err != nil {
+			os.Exit(1)
+		}
+	}
+	for _, name := range strings.Split(testListNms, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if _, err := ss.TestLists.List(name); err != nil {
+			os.Exit(1)
+		}
+		ss.TestListNms = append(ss.TestListNms, name)
+	}
+	ss.CmdWts(wts, sets)
+	ss.TestAll()
+	dt := ss.TstEpcLog
+	for row := 0; row < dt.Rows; row++ {
+		fmt.Printf("List: %v\tPctCor: %.4g\tCosDiff: %.4g\n", dt.CellString("List", row), dt.CellFloat("PctCor", row), dt.CellFloat("CosDiff", row))
+	}
+	fnm := ss.LogFileName("tstepc")
+	fmt.Printf("Saving test epoch log to: %v\n", fnm)
+	ss.TstEpcLog.SaveCSV(gi.FileName(fnm), etable.Tab, true)
+	ss.SaveProvenance(fnm)
+	fnm = ss.LogFileName("tsttrl")
+	fmt.Printf("Saving test trial log to: %v\n", fnm)
+	ss.TstTrlLog.SaveCSV(gi.FileName(fnm), etable.Tab, true)
+	ss.SaveProvenance(fnm)
+}
+
+// CmdSleep runs sleep trials on the weights of -wts, and saves the weights
+// after sleep
+func (ss *Sim) CmdSleep(args []string) {
+	ss.NoGui = true
+	fs := flag.NewFlagSet("sleep", flag.ExitOnError)
+	var wts string
+	var out string
+	var sets paramSetFlags
+	var cycles int
+	var trials int
+	var saveSlpCycLog bool
+	fs.StringVar(&wts, "wts", "", "weights file to sleep on -- required")
+	fs.StringVar(&out, "out", "", "file to save the weights after sleep to (default: -wts with _sleep)")
+	fs.StringVar(&ss.ParamSet, "params", "", "ParamSet name to use -- must be valid name as listed in compiled-in params or loaded params")
+	fs.StringVar(&ss.Tag, "tag", "", "extra tag to add to file names saved from this sleep")
+	fs.Var(&sets, "set", "param value to set after the ParamSet, as [Sel ]Path=Val -- can be repeated")
+	fs.IntVar(&cycles, "cycles", 0, "if > 0, number of cycles of each sleep trial")
+	fs.IntVar(&trials, "trials", 1, "number of sleep trials")
+	fs.BoolVar(&saveSlpCycLog, "slpcyclog", false, "if true, save the sleep cycle log to file")
+	fs.Parse(args)
+	ss.CmdWts(wts, sets)
+	if cycles > 0 {
+		ss.MaxSlpCyc = cycles
+	}
+	if saveSlpCycLog {
+		fnm := ss.LogFileName("slpcyc")
+		if err := ss.Logs.SetFile("Sleep", leabra.Cycle, fnm); err == nil {
+			fmt.Printf("Saving sleep cycle log to: %v\n", ss.LogOpts.FileName(fnm, 0))
+			ss.SaveProvenance(fnm)
+			defer ss.Logs.CloseFiles()
+		}
+	}
+	fmt.Printf("Running %d sleep trials of %d cycles\n", trials, ss.MaxSlpCyc)
+	stop := ss.NotifyInterrupt("sleep trial")
+	n := 0
+	for n < trials && !ss.StopNow && !ss.IsInterrupted() {
+		ss.Net.InitExt()
+		ss.SleepTrial()
+		if _, _, chg := ss.SleepEnv.Counter(env.Epoch); !chg { // no sleep at the end of an epoch of the SleepEnv
+			n++
+		}
+	}
+	stop()
+	if ss.IsInterrupted() {
+		fmt.Printf("Stopped after %d sleep trials\n", n)
+	}
+	if out == "" {
+		ext := filepath.Ext(wts)
+		out = strings.TrimSuffix(wts, ext) + "_sleep" + ext
+	}
+	fmt.Printf("Saving weights after sleep to: %v\n", out)
+	ss.Net.SaveWts(gi.FileName(out))
+}
+
+// CmdAnalyze compares the run stats of the run log files of -logs, as
+// train -compare does after the runs, and saves the comparison and its
+// tests
+func (ss *Sim) CmdAnalyze(args []string) {
+	ss.NoGui = true
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	var logs string
+	fs.StringVar(&logs, "logs", "", "run log files to compare, separated by , -- required")
+	fs.StringVar(&ss.RunCmp.GroupCol, "comparecol", "", "run log column whose values are the conditions compared")
+	fs.StringVar(&ss.Tag, "tag", "", "extra tag to add to file names saved from this analysis")
+	fs.Parse(args)
+	if logs == "" {
+		fmt.Fprintf(os.Stderr, "-logs run log files are required\n")
+		os.Exit(2)
+	}
+	if err := ss.CompareRunLogs(logs); err != nil {
+		os.Exit(1)
+	}
+	fnm := ss.LogFileName("compare")
+	fmt.Printf("Saving run comparison to: %v\n", fnm)
+	ss.RunCmp.SaveCSV(gi.FileName(fnm))
+	tfnm := ss.LogFileName("comparetests")
+	fmt.Printf("Saving run comparison tests to: %v\n", tfnm)
+	ss.RunCmp.SaveTestsCSV(gi.FileName(tfnm))
+}
+
+// CmdBatch runs the jobs of -jobs and each of -sets with each of -seeds, as
+// train processes of this sim, -procs at a time (see batch.Batch), and
+// saves the run logs of all the jobs collated into one _batch_run.csv file
+func (ss *Sim) CmdBatch(args []string) {
 	ss.NoGui = true
-	var nogui bool
-	var saveEpcLog bool
-	var saveRunLog bool
-	flag.StringVar(&ss.ParamSet, "params", "", "ParamSet name to use -- must be valid name as listed in compiled-in params or loaded params")
-	flag.StringVar(&ss.Tag, "tag", "", "extra tag to add to file names saved from this run")
-	flag.IntVar(&ss.MaxRuns, "runs", 10, "number of runs to do (note that MaxEpcs is in paramset)")
-	flag.BoolVar(&ss.LogSetParams, "setparams", false, "if true, print a record of each parameter that is set")
-	flag.BoolVar(&ss.SaveWts, "wts", false, "if true, save final weights after each run")
-	flag.BoolVar(&saveEpcLog, "epclog", true, "if true, save train epoch log to file")
-	flag.BoolVar(&saveRunLog, "runlog", true, "if true, save run epoch log to file")
-	flag.BoolVar(&nogui, "nogui", true, "if not passing any other args and want to run nogui, use nogui")
-	flag.Parse()
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	bt := &batch.Batch{}
+	var jobs, sets, seeds, flags string
+	fs.StringVar(&jobs, "jobs", "", "JSON file of the jobs, each with its Params, Tag, Seed and Flags")
+	fs.StringVar(&sets, "sets", "", "ParamSets run with each of -seeds, separated by ,")
+	fs.StringVar(&seeds, "seeds", "", "random seeds each of -sets is run with, separated by ,")
+	fs.StringVar(&flags, "flags", "", "extra train flags of the -sets jobs, e.g., \"-runs 5 -wts\"")
+	fs.IntVar(&bt.NProcs, "procs", 1, "number of jobs run in parallel, as worker processes")
+	fs.StringVar(&bt.LogDir, "logdir", "", "directory to save the output of each job to, as <tag>.log")
+	fs.StringVar(&ss.Tag, "tag", "", "extra tag to add to the file name of the collated run logs")
+	fs.Parse(args)
+	if jobs != "" {
+		if err := bt.Open(gi.FileName(jobs)); err != nil {
+			os.Exit(1)
+		}
+	}
+	if sets != "" || seeds != "" {
+		var setNms []string
+		for _, set := range strings.Split(sets, ",") {
+			if set = strings.TrimSpace(set); set == "Base" {
+				set = ""
+			}
+			setNms = append(setNms, set)
+		}
+		var seedVals []int64
+		for _, sd := range strings.Split(seeds, ",") {
+			if sd = strings.TrimSpace(sd); sd == "" {
+				continue
+			}
+			v, err := strconv.ParseInt(sd, 10, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "-seeds: %v is not an int\n", sd)
+				os.Exit(2)
+			}
+			seedVals = append(seedVals, v)
+		}
+		bt.AddGrid(setNms, seedVals, strings.Fields(flags))
+	}
+	if len(bt.Jobs) == 0 {
+		fmt.Fprintf(os.Stderr, "no jobs: -jobs or -sets and / or -seeds are required\n")
+		os.Exit(2)
+	}
+	bt.Args = []string{"train"}
+	bt.RunLogFun = func(job *batch.BatchJob) string {
+		params := job.Params
+		if params == "" {
+			params = "Base"
+		}
+		return ss.Net.Nm + "_" + job.Tag + "_" + params + "_run.csv"
+	}
+	fmt.Printf("Running %d batch jobs, %d at a time\n", len(bt.Jobs), bt.NProcs)
+	err := bt.Run()
+	fnm := ss.LogFileName("batch_run")
+	fmt.Printf("Saving collated run logs to: %v\n", fnm)
+	bt.Results.SaveCSV(gi.FileName(fnm), etable.Tab, true)
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// TrainArgs are the values of the flags of the train command that are not
+// set directly in fields of the Sim, named after their flags (see
+// CmdTrainFlags)
+type TrainArgs struct {
+	Nogui         bool
+	SaveEpcLog    bool
+	SaveRunLog    bool
+	Lesions       string
+	SaveNetConfig string
+	Threads       int
+	Compute       string
+	Profile       bool
+	Search        string
+	SearchN       int
+	Anneal        string
+	SaveParams    bool
+	Strict        bool
+	HyperOpt      string
+	HyperTrials   int
+	Cond          string
+	Sample        string
+	Sets          paramSetFlags
+	ImportCpp     string
+	Sens          string
+	Resolve       bool
+	Seeds         string
+	Qtr           string
+	SensPct       float64
+	SensEpcs      int
+	RecActs       string
+	EarlyStop     float64
+	RecVars       string
+	RecQtr        bool
+	DumpActs      string
+	SplitProps    string
+	SetSizes      string
+	Modalities    string
+	Contingency   float64
+	TestNoise     string
+	TestLists     string
+	TestListNms   string
+	GenTest       int
+	GenSplit      float64
+	Cue           string
+	Sched         string
+	SchedStart    string
+	CurTiers      int
+	ReplayBuf     int
+	Watchdog      bool
+	SaveSlpCycLog bool
+	LogMaxMB      int
+	RepMetric     string
+	PatComp       string
+	ErrStats      string
+	Compare       string
+	SaveDwell     bool
+	Phases        string
+	CompareOnly   bool
+	LoadWts       string
+	StartRun      int
+	StartEpc      int
+	Checkpoint    string
+	MaxEpcs       int
+	Config        string
+	PatsFile      string // PatsFile of the Sim before the flags, to detect a change
+}
+
+// CmdTrain trains the network for the runs, with the flags of the train
+// command -- also the alternative modes of training, e.g., -search or
+// -hyperopt
+func (ss *Sim) CmdTrain(args []string) {
+	ss.NoGui = true
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	ta := &TrainArgs{}
+	ss.CmdTrainFlags(fs, ta)
+	ta.PatsFile = ss.PatsFile // before -config may set it
+	if cfg := batch.ConfigFlag(args, "config"); cfg != "" {
+		sc, err := batch.OpenSimConfig(gi.FileName(cfg))
+		if err == nil {
+			err = sc.SetFlags(fs)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not use config: %v: %v\n", cfg, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Using config: %v\n", cfg)
+	}
+	fs.Parse(args)
+	if !ss.CmdTrainConfig(ta) {
+		return
+	}
+	if ss.CmdTrainAnalyses(ta) {
+		return
+	}
+	ss.CmdTrainRun(ta)
+}
+
+// CmdTrainFlags registers the flags of the train command in fs, setting
+// the fields of the Sim or of ta
+func (ss *Sim) CmdTrainFlags(fs *flag.FlagSet, ta *TrainArgs) {
+	fs.StringVar(&ss.ParamSet, "params", "", "ParamSet name to use -- must be valid name as listed in compiled-in params or loaded params")
+	fs.StringVar(&ss.Tag, "tag", "", "extra tag to add to file names saved from this run")
+	fs.Int64Var(&ss.RndSeed, "seed", ss.RndSeed, "random seed that the run seeds are derived from")
+	fs.IntVar(&ss.MaxRuns, "runs", 10, "number of runs to do (note that MaxEpcs is in paramset)")
+	fs.BoolVar(&ss.LogSetParams, "setparams", false, "if true, print a record of each parameter that is set")
+	fs.BoolVar(&ss.SaveWts, "wts", false, "if true, save final weights after each run")
+	fs.StringVar(&ta.LoadWts, "loadwts", "", "weights file to resume training from at -startrun and -startepoch")
+	fs.IntVar(&ta.StartRun, "startrun", 0, "run to resume training at with -loadwts")
+	fs.IntVar(&ta.StartEpc, "startepoch", 0, "epoch to resume training at with -loadwts")
+	fs.StringVar(&ta.Checkpoint, "checkpoint", "", "checkpoint file to resume training from with its complete state (overrides -loadwts)")
+	fs.BoolVar(&ta.SaveEpcLog, "epclog", true, "if true, save train epoch log to file, including the learning in each projection")
+	fs.BoolVar(&ta.SaveRunLog, "runlog", true, "if true, save run epoch log to file")
+	fs.StringVar(&ta.Phases, "phases", "", "layers whose ActM, ActP and ActDif to log each trial, separated by , e.g., \"Hidden1,Output\"")
+	fs.StringVar(&ss.LogSpecFile, "logspec", "", "JSON or TOML file of additional logged stats (see leabra.LogSpecs)")
+	fs.BoolVar(&ta.SaveDwell, "dwell", false, "if true, save the attractor states of each sleep trial to a _dwell.csv log")
+	fs.BoolVar(&ta.SaveSlpCycLog, "slpcyclog", false, "if true, save the sleep cycle log to file")
+	fs.BoolVar(&ss.LogOpts.Gzip, "gzip", false, "if true, gzip compress the log files and activity streams")
+	fs.IntVar(&ta.LogMaxMB, "logmaxmb", 0, "if > 0, rotate log files once this many MB have been written to each")
+	fs.DurationVar(&ss.LogOpts.MaxAge, "logmaxage", 0, "if > 0, rotate log files once each has been open this long, e.g., 1h")
+	fs.IntVar(&ss.LogWindow, "logwindow", 0, "if > 0, number of most recent rows of the file logs kept in memory (at least 11)")
+	fs.BoolVar(&ta.Nogui, "nogui", true, "if not passing any other args and want to run nogui, use nogui")
+	fs.StringVar(&ta.Lesions, "lesions", "", "lesion events separated by ; e.g., \"Epoch 20 lesion Hidden1 0.3\"")
+	fs.StringVar(&ss.NetConfigFile, "netconfig", "", "JSON or TOML file of the network architecture (see leabra.NetConfig)")
+	fs.StringVar(&ta.SaveNetConfig, "savenetconfig", "", "save the network architecture to given JSON or TOML file and exit")
+	fs.IntVar(&ta.Threads, "threads", 0, "if > 0, number of threads to auto-allocate layers to")
+	fs.StringVar(&ta.Compute, "compute", "", "compute backend for cycle-level updates, if registered")
+	fs.BoolVar(&ta.Profile, "profile", false, "if true, save the time spent in each function per layer to a _prof.csv log")
+	fs.StringVar(&ta.Search, "search", "", "param search vars separated by ; each as [Sel ]Path=v1,v2,.. or Min..Max/N")
+	fs.IntVar(&ta.SearchN, "searchn", 0, "if > 0, number of random configs sampled by -search instead of the grid")
+	fs.StringVar(&ta.Anneal, "anneal", "", "param schedules separated by ; each as [Sel ]Path: Start->End over StEpc-EdEpc")
+	fs.BoolVar(&ta.SaveParams, "saveparams", false, "if true, save a record of all params applied to a .params file")
+	fs.BoolVar(&ta.Strict, "strict", false, "if true, exit if any param selectors or paths apply to nothing")
+	fs.StringVar(&ta.HyperOpt, "hyperopt", "", "JSON file of a hyperparameter study to run or resume (see leabra.HyperStudy)")
+	fs.IntVar(&ta.HyperTrials, "hypertrials", 20, "number of hyperparameter trials to run with -hyperopt")
+	fs.StringVar(&ta.Cond, "cond", "", "conditional param sets separated by ; each as Set: Stat Op Val")
+	fs.StringVar(&ta.Sample, "sample", "", "params sampled for each run, separated by ; each as [Sel ]Path=dist(a,b)")
+	fs.Var(&ta.Sets, "set", "param value to set after the ParamSet, as [Sel ]Path=Val -- can be repeated")
+	fs.StringVar(&ta.Qtr, "qtr", "", "params per quarter separated by ; each as [Sel ]Path=v0,v1,v2,v3")
+	fs.StringVar(&ta.Seeds, "seeds", "", "fixed seeds of random streams separated by ; each as name=seed")
+	fs.BoolVar(&ta.Resolve, "resolve", false, "if true, print the ParamSet resolved with the sets it extends and exit")
+	fs.StringVar(&ta.Sens, "sens", "", "params of a sensitivity analysis separated by ; each as [sleep:][Sel ]Path")
+	fs.Float64Var(&ta.SensPct, "senspct", 10, "percent to perturb each param by with -sens")
+	fs.IntVar(&ta.SensEpcs, "sensepcs", 0, "if > 0, number of epochs per run with -sens")
+	fs.StringVar(&ta.RecActs, "recacts", "", "layers to stream unit variables of to disk, separated by ,")
+	fs.StringVar(&ta.RecVars, "recvars", "Act", "unit variables to stream for -recacts, separated by ,")
+	fs.BoolVar(&ta.RecQtr, "recqtr", false, "if true, -recacts and -dumpacts record only at the end of each quarter")
+	fs.BoolVar(&ta.Watchdog, "watchdog", false, "if true, stop the run and dump its state if NaN, Inf or exploding values are found")
+	fs.StringVar(&ta.SplitProps, "split", "", "proportions of the Train, Test and optionally Valid splits, e.g., \"0.8,0.2\"")
+	fs.BoolVar(&ss.Split.Strat, "splitstrat", true, "if true, -split is stratified by category")
+	fs.StringVar(&ss.SplitTest, "splittest", "Test", "which -split is tested: Test or Valid")
+	fs.StringVar(&ta.SetSizes, "setsizes", "", "numbers of training patterns of the runs in turn, e.g., \"10,25,50\"")
+	fs.BoolVar(&ss.SetSize.Strat, "setsizestrat", true, "if true, -setsizes subsamples are stratified by category")
+	fs.BoolVar(&ss.GenPats, "genpats", false, "if true, generate the patterns as families of distorted prototypes")
+	fs.StringVar(&ss.SynthTarget, "synthpats", "", "file of a similarity matrix to synthesize the patterns from")
+	fs.StringVar(&ss.ImageFiles, "images", "", "glob patterns of image files to use as the patterns, separated by ,")
+	fs.BoolVar(&ss.Images.Invert, "imageinvert", false, "if true, the -images are inverted")
+	fs.IntVar(&ss.PatSynth.Iters, "synthiters", 50000, "number of optimization steps for -synthpats")
+	fs.IntVar(&ss.PatGen.NFams, "genfams", 5, "number of families of patterns for -genpats")
+	fs.IntVar(&ss.PatGen.NPer, "genper", 5, "number of patterns per family for -genpats")
+	fs.Float64Var(&ss.PatGen.Distort, "gendistort", 0.25, "proportion of the prototype units moved in each pattern for -genpats")
+	fs.Float64Var(&ss.PatGen.MaxOverlap, "genoverlap", 0.34, "maximum overlap of the prototypes for -genpats")
+	fs.StringVar(&ta.Cue, "cue", "", "cued recall test cues separated by , each as Layer[:Frac], e.g., \"Input:0.5\"")
+	fs.BoolVar(&ss.CueTest.Fixed, "cuefixed", false, "if true, the -cue units are the first Frac instead of random ones")
+	fs.StringVar(&ss.Augment.Spec, "augment", "", "data augmentation of the training inputs, e.g., \"Jitter:0.1,Mask:0.2\"")
+	fs.StringVar(&ta.TestLists, "testlists", "", "JSON file of named lists of test items (see -testlist)")
+	fs.StringVar(&ta.TestListNms, "testlist", "", "names of -testlists lists tested after all the items, separated by ,")
+	fs.StringVar(&ta.TestNoise, "testnoise", "", "noise added to the test inputs, as Type:Level, e.g., \"BitFlip:0.05\"")
+	fs.StringVar(&ta.Sched, "sched", "", "training schedule: Interleaved, Blocked[:Cat,..] or Weighted:Cat=Wt,..")
+	fs.IntVar(&ss.TrainSched.BlockEpcs, "blockepcs", 10, "number of epochs of each block with -sched Blocked")
+	fs.StringVar(&ta.SchedStart, "schedstart", "", "first training epoch of each category separated by , each as Cat=Epoch")
+	fs.IntVar(&ta.CurTiers, "curriculum", 0, "if > 0, number of tiers of a curriculum of increasing difficulty")
+	fs.StringVar(&ss.Curriculum.Metric, "curmetric", "Overlap", "difficulty of each item for -curriculum: Overlap or a column name")
+	fs.Float64Var(&ss.Curriculum.CritThr, "curthr", 0.9, "PctCor at which -curriculum progresses to the next tier")
+	fs.IntVar(&ss.Curriculum.MaxEpcs, "curmaxepcs", 0, "if > 0, maximum number of epochs on each -curriculum tier")
+	fs.StringVar(&ss.Stream.Source, "trainstream", "", "file or tcp:// or unix:// source to stream the training items from")
+	fs.IntVar(&ss.StreamChunk, "streamchunk", 100, "number of -trainstream items trained per epoch")
+	fs.BoolVar(&ss.Stream.Loop, "streamloop", true, "if true, a -trainstream file is reread from its start at its end")
+	fs.BoolVar(&ss.Salience.On, "salience", false, "if true, set the Ne and Po patterns from the salience of each item")
+	fs.IntVar(&ss.Salience.Units, "salunits", 1, "number of Ne or Po units set to the arousal for -salience")
+	fs.Float64Var(&ss.Salience.AroLrate, "arolrate", 0, "if != 0, scale the learning rate by 1 + arolrate * arousal")
+	fs.IntVar(&ta.ReplayBuf, "replaybuf", 0, "if > 0, size of the buffer of wake trials replayed in sleep")
+	fs.Float64Var(&ss.ReplayBuf.ErrWt, "replayerrwt", 1, "weight of the SSE in the salience for -replaybuf")
+	fs.Float64Var(&ss.ReplayBuf.EmoWt, "replayemowt", 1, "weight of the Ne and Po values in the salience for -replaybuf")
+	fs.BoolVar(&ss.ABAC.On, "abac", false, "if true, run the A-B / A-C retroactive interference paradigm")
+	fs.IntVar(&ss.ABAC.ABEpcs, "abepcs", 10, "number of epochs the AB list is trained for -abac")
+	fs.IntVar(&ss.ABAC.ACEpcs, "acepcs", 10, "number of epochs the AC list is trained for -abac")
+	fs.BoolVar(&ss.OneShot.On, "oneshot", false, "if true, run the episodic one-shot study, sleep and test protocol")
+	fs.Float64Var(&ss.OneShot.StudyProp, "studyprop", 0.5, "proportion of the items studied with -oneshot")
+	fs.IntVar(&ss.OneShot.Reps, "studyreps", 1, "number of presentations of each studied item with -oneshot")
+	fs.BoolVar(&ss.OneShot.ImmTest, "immtest", false, "if true, -oneshot also tests right after study")
+	fs.StringVar(&ss.SeqCol, "seqcol", "", "column of the patterns with the sequence name of each item (see env.SeqEnv)")
+	fs.StringVar(&ta.Modalities, "modalities", "", "input modalities of each item starting with Input, e.g., \"Input,Audio\"")
+	fs.Float64Var(&ta.Contingency, "contingency", 1, "probability that -modalities present the pattern bound to the item")
+	fs.BoolVar(&ss.Context.On, "context", false, "if true, add a Context input layer for sequence learning")
+	fs.StringVar(&ta.DumpActs, "dumpacts", "", "layers to dump the -recvars of to a memory-mapped _dump.bin file, separated by ,")
+	fs.BoolVar(&ss.SaveSQLite, "sqlite", false, "if true, save the logs of each run to a SQLite database (needs -tags sqlite)")
+	fs.BoolVar(&ss.SaveReps, "reps", false, "if true, save the 2D projection of the Hidden1 representations at each test")
+	fs.StringVar(&ta.RepMetric, "repmds", "", "distance metric of MDS for -reps instead of PCA: Euclidean, Correlation or Cosine")
+	fs.StringVar(&ta.PatComp, "patcomp", "", "pattern-completion test levels separated by ; each as Frac[:Noise]")
+	fs.IntVar(&ss.PatComp.NReps, "patcompreps", 1, "number of repetitions of each item at each -patcomp level")
+	fs.StringVar(&ta.ErrStats, "errstats", "CrossEnt,AUC,DPrime", "additional Output error measures for the test logs, separated by ,")
+	fs.StringVar(&ss.TBDir, "tboard", "", "directory to write TensorBoard event files of the epoch logs to")
+	fs.BoolVar(&ss.TBWts, "tbwts", false, "if true, -tboard also writes weight histograms every epoch")
+	fs.StringVar(&ss.ServeAddr, "serve", "", "address (e.g., :8080) to serve the control API at instead of running")
+	fs.StringVar(&ss.GRPCAddr, "grpc", "", "address (e.g., :8081) to serve the control API over gRPC at instead of running")
+	fs.StringVar(&ss.DashAddr, "dashboard", "", "address (e.g., :8090) to serve a monitoring dashboard at")
+	fs.StringVar(&ss.MetricsAddr, "metrics", "", "address (e.g., :9090) to serve Prometheus metrics at")
+	fs.StringVar(&ta.Compare, "compare", "", "run log files of other conditions to compare the runs with, separated by ,")
+	fs.StringVar(&ss.RunCmp.GroupCol, "comparecol", "", "run log column whose values are the conditions compared by -compare")
+	fs.BoolVar(&ta.CompareOnly, "compareonly", false, "if true, only compare the -compare run logs and exit")
+	fs.Float64Var(&ta.EarlyStop, "earlystop", 0, "if > 0, stop each quarter early once the max ActDel stays below this")
+	fs.StringVar(&ta.ImportCpp, "importcpp", "", "C++ emergent .proj or .spec file to import params from, as the Cpp ParamSet")
+	fs.IntVar(&ta.GenTest, "gentest", 0, "if > 0, number of recombined and novel items of a generalization test")
+	fs.Float64Var(&ta.GenSplit, "gensplit", 0.5, "proportion of each -gentest recombination from its first item")
+	fs.IntVar(&ta.MaxEpcs, "epcs", 0, "if > 0, maximum number of epochs of each run, overriding the ParamSet")
+	fs.IntVar(&ss.MaxSlpCyc, "slpcyc", ss.MaxSlpCyc, "maximum number of cycles of each sleep trial")
+	fs.BoolVar(&ss.Sleep, "sleep", ss.Sleep, "if true, the network sleeps after training epochs")
+	fs.BoolVar(&ss.InhibOscil, "oscil", ss.InhibOscil, "if true, the inhibition oscillates during sleep")
+	fs.IntVar(&ss.TestInterval, "testinterval", ss.TestInterval, "how often to test all the items, in training epochs")
+	fs.StringVar(&ss.PatsFile, "pats", ss.PatsFile, "file of the training patterns (tab-separated, with headers)")
+	fs.BoolVar(&ss.Progress.On, "progress", true, "if true, print the progress of training every -progint epochs")
+	fs.IntVar(&ss.Progress.Interval, "progint", 1, "number of epochs between the -progress reports")
+	fs.StringVar(&ss.Progress.File, "status", "", "status file that the last -progress report is written to")
+	fs.StringVar(&ta.Config, "config", "", "TOML, YAML or JSON file of flag values by name, overridden by the command line")
+}
+
+// CmdTrainConfig configures the Sim from the parsed flags of the train
+// command, and initializes it -- returns false if there is nothing more to
+// do (-savenetconfig or -resolve)
+func (ss *Sim) CmdTrainConfig(ta *TrainArgs) bool {
+	if ta.MaxEpcs > 0 {
+		ta.Sets = append(ta.Sets, fmt.Sprintf("Sim.MaxEpcs=%d", ta.MaxEpcs))
+	}
+	if ta.SetSizes != "" {
+		if err := ss.SetSize.SetString(ta.SetSizes); err != nil {
+			os.Exit(1)
+		}
+	}
+	if ss.SynthTarget != "" || ss.ImageFiles != "" {
+		ss.GenPats = true
+	}
+	ss.Modal.Contingency = float32(ta.Contingency)
+	if ta.Modalities != "" {
+		ss.Modal.Mods = strings.Split(ta.Modalities, ",")
+		for i, mod := range ss.Modal.Mods {
+			ss.Modal.Mods[i] = strings.TrimSpace(mod)
+		}
+	}
+	if ta.GenTest > 0 {
+		ss.GenTest.On = true
+		ss.GenTest.NRecomb = ta.GenTest
+		ss.GenTest.NNovel = ta.GenTest
+	}
+	ss.GenTest.Split = float32(ta.GenSplit)
+	if ss.NetConfigFile != "" || ss.Context.On || ss.GenPats || ss.OneShot.On || ss.Modal.On() || ss.GenTest.On || ss.PatsFile != ta.PatsFile {
+		ss.Net = &leabra.Network{}
+		ss.Config()
+	}
+	ss.ConfigSalience() // -salience is on anyway with Valence patterns
+	if ta.Threads > 0 {
+		ss.Net.ThrAutoN = ta.Threads
+		ss.Net.ThrAuto = true
+		ss.Net.AutoThreads(ta.Threads)
+	}
+	if ta.Compute != "" {
+		ss.Net.SetCompute(ta.Compute)
+	}
+	if ta.Watchdog {
+		ss.Watchdog.On = true
+	}
+	if ta.Sched != "" {
+		if err := ss.TrainSched.SetString(ta.Sched); err != nil {
+			os.Exit(1)
+		}
+	}
+	if ta.ReplayBuf > 0 {
+		ss.ReplayBuf.On = true
+		ss.ReplayBuf.Size = ta.ReplayBuf
+	}
+	if ta.CurTiers > 0 {
+		ss.Curriculum.On = true
+		ss.Curriculum.NTiers = ta.CurTiers
+	}
+	if ta.SchedStart != "" {
+		if err := ss.TrainSched.SetStart(ta.SchedStart); err != nil {
+			os.Exit(1)
+		}
+	}
+	if ta.Cue != "" {
+		if err := ss.CueTest.SetString(ta.Cue); err != nil {
+			os.Exit(1)
+		}
+	}
+	if ta.TestNoise != "" {
+		if err := ss.TestNoise.SetString(ta.TestNoise); err != nil {
+			os.Exit(1)
+		}
+	}
+	if err := ss.Augment.SetString(ss.Augment.Spec); err != nil {
+		os.Exit(1)
+	}
+	if ta.TestLists != "" {
+		if err := ss.OpenTestLists(gi.FileName(ta.TestLists)); err != nil {
+			os.Exit(1)
+		}
+	}
+	for _, name := range strings.Split(ta.TestListNms, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if _, err := ss.TestLists.List(name); err != nil {
+			os.Exit(1)
+		}
+		ss.TestListNms = append(ss.TestListNms, name)
+	}
+	if ta.SplitProps != "" {
+		if err := ss.Split.SetString(ta.SplitProps); err != nil {
+			os.Exit(1)
+		}
+		ss.SaveSplit = true
+	}
+	if ta.EarlyStop > 0 {
+		ss.EarlyStop.On = true
+		ss.EarlyStop.Thr = float32(ta.EarlyStop)
+	}
+	ss.Net.Profile = ta.Profile
+	if ta.SaveNetConfig != "" {
+		nc := ss.Net.NetConfig()
+		var err error
+		if strings.HasSuffix(ta.SaveNetConfig, ".toml") {
+			err = nc.SaveTOML(gi.FileName(ta.SaveNetConfig))
+		} else {
+			err = nc.SaveJSON(gi.FileName(ta.SaveNetConfig))
+		}
+		if err != nil {
+			os.Exit(1)
+		}
+		fmt.Printf("Saved network config to: %v\n", ta.SaveNetConfig)
+		return false
+	}
+	if ta.ImportCpp != "" {
+		if err := ss.OpenCppParams(gi.FileName(ta.ImportCpp)); err != nil {
+			os.Exit(1)
+		}
+	}
+	for _, set := range ta.Sets {
+		if err := ss.Overrides.AddString(set); err != nil {
+			os.Exit(1)
+		}
+	}
+	ss.SetOverrides()
+	if ta.Qtr != "" {
+		if err := ss.Net.QtrParams.AddString(ta.Qtr); err != nil {
+			os.Exit(1)
+		}
+	}
+	if ta.Seeds != "" {
+		if err := ss.Seeds.AddString(ta.Seeds); err != nil {
+			os.Exit(1)
+		}
+		ss.Seeds.Master = ss.RndSeed
+		fmt.Printf("Random seeds: %v\n", ss.Seeds.String())
+	}
+	if ta.Resolve {
+		fmt.Print(ss.ResolvedParams())
+		return false
+	}
+	if ta.Strict {
+		ss.Net.ParamsStrict = true
+		if err := ss.ValidateParams(); err != nil {
+			os.Exit(1)
+		}
+	}
+	if ta.Sample != "" {
+		if err := ss.RunSample.AddString(ta.Sample); err != nil {
+			os.Exit(1)
+		}
+		ss.RunSample.Seed = ss.RndSeed
+		ss.ConfigLogs() // add columns for sampled values
+	}
+	if ta.Cond != "" {
+		if err := ss.CondSets.AddString(ta.Cond); err != nil {
+			os.Exit(1)
+		}
+	}
+	if ta.Anneal != "" {
+		if err := ss.Anneal.AddString(ta.Anneal); err != nil {
+			os.Exit(1)
+		}
+	}
+	if ta.Lesions != "" {
+		if err := ss.Lesions.AddEventsString(ta.Lesions); err != nil {
+			os.Exit(1)
+		}
+	}
 	ss.Init()
+	if ta.Checkpoint != "" {
+		if err := ss.OpenCheckpoint(gi.FileName(ta.Checkpoint)); err != nil {
+			os.Exit(1)
+		}
+	} else if ta.LoadWts != "" {
+		if err := ss.ResumeWts(gi.FileName(ta.LoadWts), ta.StartRun, ta.StartEpc); err != nil {
+			os.Exit(1)
+		}
+	}
 
 	if ss.ParamSet != "" {
 		fmt.Printf("Using ParamSet: %s\n", ss.ParamSet)
 	}
+	if len(ss.Overrides) > 0 {
+		fmt.Printf("Using param overrides: %v\n", ss.Overrides)
+	}
+	if ta.SaveParams {
+		fnm := ss.Net.Nm + "_" + ss.RunName() + ".params"
+		fmt.Printf("Saving params record to: %v\n", fnm)
+		ss.SaveParams(gi.FileName(fnm))
+	}
+	return true
+}
 
-	if saveEpcLog {
-		var err error
+// CmdTrainAnalyses runs the alternative modes of the train command instead
+// of the runs: -compareonly, -hyperopt, -search or -sens -- returns true
+// if one of them was run
+func (ss *Sim) CmdTrainAnalyses(ta *TrainArgs) bool {
+	if ta.Compare != "" && ta.CompareOnly {
+		if err := ss.CompareRunLogs(ta.Compare); err != nil {
+			os.Exit(1)
+		}
+		fnm := ss.LogFileName("compare")
+		fmt.Printf("Saving run comparison to: %v\n", fnm)
+		ss.RunCmp.SaveCSV(gi.FileName(fnm))
+		tfnm := ss.LogFileName("comparetests")
+		fmt.Printf("Saving run comparison tests to: %v\n", tfnm)
+		ss.RunCmp.SaveTestsCSV(gi.FileName(tfnm))
+		return true
+	}
+	if ta.HyperOpt != "" {
+		hs := &leabra.HyperStudy{}
+		if _, err := os.Stat(ta.HyperOpt); err == nil {
+			if err := hs.OpenJSON(gi.FileName(ta.HyperOpt)); err != nil {
+				os.Exit(1)
+			}
+		}
+		if hs.Name == "" {
+			hs.Name = "Hyper"
+		}
+		if len(hs.Params) == 0 {
+			hs.Params = HyperParams
+			hs.Obj = "TstPctCor"
+			hs.Maximize = true
+			hs.Seed = ss.RndSeed
+		}
+		fmt.Printf("Running hyperparameter study: %v: %d trials x %d runs\n", ta.HyperOpt, ta.HyperTrials, ss.MaxRuns)
+		ss.RunHyperOpt(hs, ta.HyperTrials, gi.FileName(ta.HyperOpt))
+		return true
+	}
+	if ta.Search != "" {
+		ps := &leabra.ParamSearch{}
+		ps.Defaults()
+		ps.NSeeds = ss.MaxRuns
+		if ta.SearchN > 0 {
+			ps.Random = true
+			ps.NSamples = ta.SearchN
+			ps.RndSeed = ss.RndSeed
+		}
+		if err := ps.AddVarsString(ta.Search); err != nil {
+			os.Exit(1)
+		}
+		ps.Generate()
+		fmt.Printf("Running param search: %d configs x %d seeds\n", len(ps.Configs), ps.NSeeds)
+		ss.RunParamSearch(ps)
+		fnm := ss.LogFileName("search")
+		fmt.Printf("Saving param search results to: %v\n", fnm)
+		ps.SaveResults(gi.FileName(fnm))
+		ss.SaveProvenance(fnm)
+		return true
+	}
+	if ta.Sens != "" {
+		sa := &leabra.Sensitivity{}
+		sa.Defaults()
+		sa.Pct = ta.SensPct
+		sa.NSeeds = ss.MaxRuns
+		sa.Stats = []string{"TstPctCor", "PctCor", "FirstZero"}
+		if ta.Sens == "sleep" {
+			sa.Params = HyperParams
+		} else if err := sa.AddString(ta.Sens); err != nil {
+			os.Exit(1)
+		}
+		fmt.Printf("Running sensitivity analysis: %d params x 2 + base configs x %d seeds\n", len(sa.Params), sa.NSeeds)
+		ss.RunSensitivity(sa, ta.SensEpcs)
+		fnm := ss.LogFileName("sens")
+		fmt.Printf("Saving sensitivity results to: %v\n", fnm)
+		sa.SaveResults(gi.FileName(fnm))
+		ss.SaveProvenance(fnm)
+		return true
+	}
+	return false
+}
+
+// CmdTrainRun opens the log files, activity streams and servers of the
+// train command, and runs the runs
+func (ss *Sim) CmdTrainRun(ta *TrainArgs) {
+	ss.LogOpts.MaxSize = int64(ta.LogMaxMB) << 20
+	ss.Logs.FileOpts = ss.LogOpts
+	ss.ActStream.Opts = ss.LogOpts
+	if ta.SaveEpcLog {
 		fnm := ss.LogFileName("epc")
-		ss.TrnEpcFile, err = os.Create(fnm)
-		if err != nil {
-			log.Println(err)
-			ss.TrnEpcFile = nil
-		} else {
-			fmt.Printf("Saving epoch log to: %v\n", fnm)
-			defer ss.TrnEpcFile.Close()
+		if err := ss.Logs.SetFile("Train", leabra.Epoch, fnm); err == nil {
+			fmt.Printf("Saving epoch log to: %v\n", ss.LogOpts.FileName(fnm, 0))
+			ss.SaveProvenance(fnm)
+			defer ss.Logs.CloseFiles()
 		}
 	}
-	if saveRunLog {
-		var err error
+	if ta.SaveRunLog {
 		fnm := ss.LogFileName("run")
-		ss.RunFile, err = os.Create(fnm)
-		if err != nil {
-			log.Println(err)
-			ss.RunFile = nil
-		} else {
-			fmt.Printf("Saving run log to: %v\n", fnm)
-			defer ss.RunFile.Close()
+		if err := ss.Logs.SetFile("Train", leabra.Run, fnm); err == nil {
+			fmt.Printf("Saving run log to: %v\n", ss.LogOpts.FileName(fnm, 0))
+			ss.SaveProvenance(fnm)
+			defer ss.Logs.CloseFiles()
+		}
+	}
+	if ta.ErrStats != strings.Join(ss.ErrStats, ",") {
+		ss.ErrStats = nil
+		for _, nm := range strings.Split(ta.ErrStats, ",") {
+			if nm = strings.TrimSpace(nm); nm == "" {
+				continue
+			}
+			var es leabra.ErrStats
+			if _, err := es.Stat(nm); err != nil {
+				os.Exit(1)
+			}
+			ss.ErrStats = append(ss.ErrStats, nm)
+		}
+		if err := ss.ConfigLogs(); err != nil {
+			os.Exit(1)
+		}
+	}
+	if ta.Phases != "" || ss.LogSpecFile != "" {
+		ss.PhaseLays = nil
+		for _, lnm := range strings.Split(ta.Phases, ",") {
+			if lnm = strings.TrimSpace(lnm); lnm != "" {
+				ss.PhaseLays = append(ss.PhaseLays, lnm)
+			}
+		}
+		if err := ss.ConfigLogs(); err != nil {
+			os.Exit(1)
+		}
+		for _, ml := range [][2]string{{"Train", "trnphs"}, {"Test", "tsttrl"}} {
+			fnm := ss.LogFileName(ml[1])
+			if err := ss.Logs.SetFile(ml[0], leabra.Trial, fnm); err == nil {
+				fmt.Printf("Saving %v trial log to: %v\n", strings.ToLower(ml[0]), ss.LogOpts.FileName(fnm, 0))
+				ss.SaveProvenance(fnm)
+				defer ss.Logs.CloseFiles()
+			}
+		}
+	}
+	if ta.PatComp != "" {
+		if err := ss.PatComp.AddString(ta.PatComp); err != nil {
+			os.Exit(1)
+		}
+		var err error
+		fnm := ss.LogFileName("patcomp")
+		ss.PatCompFile, err = leabra.CreateLogFile(fnm, ss.LogOpts, true)
+		if err == nil {
+			fmt.Printf("Saving pattern completion log to: %v\n", ss.LogOpts.FileName(fnm, 0))
+			ss.SaveProvenance(fnm)
+			ss.PatComp.Results.WriteCSVHeaders(ss.PatCompFile, etable.Tab)
+			defer ss.PatCompFile.Close()
+		}
+	}
+	if ta.RepMetric != "" {
+		ss.RepSpace.MDS = true
+		ss.RepSpace.Metric = ta.RepMetric
+	}
+	if ta.SaveDwell {
+		var err error
+		fnm := ss.LogFileName("dwell")
+		ss.DwellFile, err = leabra.CreateLogFile(fnm, ss.LogOpts, true)
+		if err == nil {
+			fmt.Printf("Saving attractor dwell log to: %v\n", ss.LogOpts.FileName(fnm, 0))
+			ss.SaveProvenance(fnm)
+			defer ss.DwellFile.Close()
+		}
+	}
+	if ta.SaveSlpCycLog {
+		fnm := ss.LogFileName("slpcyc")
+		if err := ss.Logs.SetFile("Sleep", leabra.Cycle, fnm); err == nil {
+			fmt.Printf("Saving sleep cycle log to: %v\n", ss.LogOpts.FileName(fnm, 0))
+			ss.SaveProvenance(fnm)
+			defer ss.Logs.CloseFiles()
 		}
 	}
+	if ss.LogWindow > 0 {
+		if ss.LogWindow < 11 {
+			ss.LogWindow = 11 // LogRun uses the last 10 epochs + 1
+		}
+		ss.Logs.Window = ss.LogWindow
+	}
+	if ta.RecActs != "" {
+		if ta.RecQtr {
+			ss.ActStream.Time = leabra.Quarter
+		}
+		prefix := strings.TrimSuffix(ss.LogFileName("acts"), ".csv")
+		if err := ss.ActStream.Open(ss.Net, prefix, strings.Split(ta.RecActs, ","), strings.Split(ta.RecVars, ",")...); err != nil {
+			os.Exit(1)
+		}
+		fmt.Printf("Streaming unit variables %v of layers %v to: %v_*.f32\n", ta.RecVars, ta.RecActs, prefix)
+		defer ss.ActStream.Close()
+	}
+	if ta.DumpActs != "" {
+		if ta.RecQtr {
+			ss.ActDump.Time = leabra.Quarter
+		}
+		prefix := strings.TrimSuffix(ss.LogFileName("dump"), ".csv")
+		if err := ss.ActDump.Open(ss.Net, prefix, strings.Split(ta.DumpActs, ","), strings.Split(ta.RecVars, ",")...); err != nil {
+			os.Exit(1)
+		}
+		fmt.Printf("Dumping unit variables %v of layers %v to: %v.bin\n", ta.RecVars, ta.DumpActs, prefix)
+		defer ss.ActDump.Close()
+	}
+	if ss.MetricsAddr != "" {
+		ss.Metrics.Prefix = "leabra_"
+		ss.Metrics.Labels = map[string]string{"params": ss.RunName()}
+		if err := ss.Metrics.Start(ss.MetricsAddr); err != nil {
+			os.Exit(1)
+		}
+		fmt.Printf("Serving metrics at: http://%v/metrics\n", ss.MetricsAddr)
+		defer ss.Metrics.Stop()
+	}
+	if ss.DashAddr != "" {
+		ss.Dash.Title = "Leabra Dashboard: " + ss.RunName()
+		ss.Dash.MaxPts = 10000
+		if err := ss.Dash.Start(ss.DashAddr); err != nil {
+			os.Exit(1)
+		}
+		fmt.Printf("Serving dashboard at: http://%v/\n", ss.DashAddr)
+		defer ss.Dash.Stop()
+	}
 	if ss.SaveWts {
 		fmt.Printf("Saving final weights per run\n")
 	}
+	if ss.ServeAddr != "" || ss.GRPCAddr != "" {
+		ss.ConfigServer()
+		if ss.ServeAddr != "" {
+			if err := ss.Server.Start(ss.ServeAddr); err != nil {
+				os.Exit(1)
+			}
+			fmt.Printf("Serving the control API at: http://%v/ -- /quit to exit\n", ss.ServeAddr)
+		}
+		if ss.GRPCAddr != "" {
+			if err := ss.Server.StartGRPC(ss.GRPCAddr); err != nil {
+				ss.Server.Stop()
+				os.Exit(1)
+			}
+			fmt.Printf("Serving the control API over gRPC at: %v (%v) -- Quit to exit\n", ss.GRPCAddr, server.GRPCService)
+		}
+		ss.Server.Wait()
+		ss.Server.Stop()
+		return
+	}
+	if ss.Progress.File != "" {
+		fmt.Printf("Writing progress to: %v\n", ss.Progress.File)
+	}
 	fmt.Printf("Running %d Runs\n", ss.MaxRuns)
+	ss.Progress.Init()
+	stop := ss.NotifyInterrupt("trial")
 	ss.Train()
+	stop()
+	if ss.IsInterrupted() {
+		ss.SaveInterrupted()
+		return // the log files are closed by the defers above
+	}
+	if ta.Compare != "" {
+		if err := ss.CompareRunLogs(ta.Compare); err == nil {
+			fnm := ss.LogFileName("compare")
+			fmt.Printf("Saving run comparison to: %v\n", fnm)
+			ss.RunCmp.SaveCSV(gi.FileName(fnm))
+			ss.SaveProvenance(fnm)
+			tfnm := ss.LogFileName("comparetests")
+			fmt.Printf("Saving run comparison tests to: %v\n", tfnm)
+			ss.RunCmp.SaveTestsCSV(gi.FileName(tfnm))
+			ss.SaveProvenance(tfnm)
+		}
+	}
+	if ta.Profile {
+		fnm := ss.LogFileName("prof")
+		fmt.Printf("Saving profile log to: %v\n", fnm)
+		ss.SaveProfile(gi.FileName(fnm))
+	}
 }
 
 func mainrun() {
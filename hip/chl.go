@@ -118,10 +118,10 @@ func (pj *CHLPrjn) DWtCHL() {
 	}
 	for si := range slay.Neurons {
 		sn := &slay.Neurons[si]
-		nc := int(pj.SConN[si])
-		st := int(pj.SConIdxSt[si])
-		syns := pj.Syns[st : st+nc]
-		scons := pj.SConIdx[st : st+nc]
+		nc := pj.SConN[si]
+		st := pj.SConIdxSt[si]
+		syns := pj.Syns.Range(st, nc)
+		scons := pj.SConIdx[st : st+int64(nc)]
 
 		savgCor := pj.SAvgCor(slay)
 
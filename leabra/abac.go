@@ -0,0 +1,139 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// ABACLists are the names of the lists of the A-B / A-C paradigm, in the
+// order they are trained
+var ABACLists = []string{"AB", "AC"}
+
+// ABAC configures the classic paired-associate A-B / A-C retroactive
+// interference paradigm: the AB list (the patterns as is, each A cue with
+// its B associate in the OutCols) is trained for ABEpcs epochs, then the AC
+// list, which pairs each A cue with the associate of another item (C), for
+// ACEpcs epochs -- and both lists are tested, so the interference of AC
+// learning with the recall of AB (e.g., with and without sleep) can be
+// measured.  The items of both lists keep the names of their A cues.
+type ABAC struct {
+	On       bool          `desc:"if true, training follows the A-B / A-C paradigm"`
+	ABEpcs   int           `desc:"number of epochs the AB list is trained"`
+	ACEpcs   int           `desc:"number of epochs the AC list is trained, after AB"`
+	OutCols  []string      `desc:"columns of the patterns that are the associates (B or C) -- the others are the A cues"`
+	AB       *etable.Table `view:"-" desc:"the AB list: the patterns"`
+	AC       *etable.Table `view:"-" desc:"the AC list: each A with the associate of another item of the AB list"`
+	Pairs    []int         `inactive:"+" desc:"for each item, the item of the AB list whose associate is its C"`
+	List     string        `inactive:"+" desc:"list currently trained"`
+	TestList string        `inactive:"+" desc:"list currently tested"`
+}
+
+func (ab *ABAC) Defaults() {
+	ab.ABEpcs = 10
+	ab.ACEpcs = 10
+	ab.OutCols = []string{"Output"}
+}
+
+// Config makes the AB and AC lists from given patterns, pairing each A cue
+// with the associate of another item at random (a random cyclic
+// permutation, so no item keeps its own) using given random number stream
+func (ab *ABAC) Config(pats *etable.Table, rnd *rand.Rand) error {
+	n := pats.Rows
+	if n < 2 {
+		err := fmt.Errorf("ABAC: at least 2 items are needed for the AC list, have: %d", n)
+		log.Println(err)
+		return err
+	}
+	for _, oc := range ab.OutCols {
+		if pats.ColByName(oc) == nil {
+			err := fmt.Errorf("ABAC: associate column: %v not found in table: %v", oc, pats.MetaData["name"])
+			log.Println(err)
+			return err
+		}
+	}
+	ab.Pairs = make([]int, n)
+	for i := range ab.Pairs {
+		ab.Pairs[i] = i
+	}
+	for i := n - 1; i > 0; i-- { // Sattolo's algorithm
+		j := rnd.Intn(i)
+		ab.Pairs[i], ab.Pairs[j] = ab.Pairs[j], ab.Pairs[i]
+	}
+	ab.AB = pats
+	ab.AC = &etable.Table{}
+	ab.AC.SetMetaData("name", "ACPats")
+	ab.AC.SetMetaData("desc", "A-C list of the A-B / A-C paradigm")
+	ab.AC.SetFromSchema(SchemaOf(pats), n)
+	for ci, col := range pats.Cols {
+		nm := pats.ColNames[ci]
+		isOut := false
+		for _, oc := range ab.OutCols {
+			if oc == nm {
+				isOut = true
+			}
+		}
+		csz := col.Len() / n
+		for row := 0; row < n; row++ {
+			src := row
+			if isOut {
+				src = ab.Pairs[row]
+			}
+			if col.DataType() == etensor.STRING {
+				ab.AC.SetCellString(nm, row, col.StringVal1D(src*csz))
+				continue
+			}
+			for i := 0; i < csz; i++ {
+				ab.AC.Cols[ci].SetFloat1D(row*csz+i, col.FloatVal1D(src*csz+i))
+			}
+		}
+	}
+	ab.List = ABACLists[0]
+	return nil
+}
+
+// ListAt returns the list trained at given epoch
+func (ab *ABAC) ListAt(epc int) string {
+	if epc < ab.ABEpcs {
+		return ABACLists[0]
+	}
+	return ABACLists[1]
+}
+
+// Done returns true if training of both lists is done at given epoch
+func (ab *ABAC) Done(epc int) bool {
+	return epc >= ab.ABEpcs+ab.ACEpcs
+}
+
+// Table returns the table of given list (nil if not a list)
+func (ab *ABAC) Table(list string) *etable.Table {
+	switch list {
+	case ABACLists[0]:
+		return ab.AB
+	case ABACLists[1]:
+		return ab.AC
+	}
+	return nil
+}
+
+// SchemaOf returns the schema of given table, e.g., to make a table of the
+// same columns, or for the Schema of an env.StreamEnv of rows of the same
+// patterns
+func SchemaOf(dt *etable.Table) etable.Schema {
+	sch := make(etable.Schema, len(dt.Cols))
+	for ci, col := range dt.Cols {
+		var shp []int
+		if col.NumDims() > 1 {
+			shp = col.Shapes()[1:]
+		}
+		sch[ci] = etable.Column{dt.ColNames[ci], col.DataType(), shp, nil}
+	}
+	return sch
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestABAC(t *testing.T) {
+	n := 5
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, []int{n}, nil},
+		{"Output", etensor.FLOAT32, []int{n}, nil},
+	}, n)
+	for row := 0; row < n; row++ {
+		dt.SetCellString("Name", row, fmt.Sprintf("p%d", row))
+		dt.CellTensor("Input", row).SetFloat1D(row, 1)
+		dt.CellTensor("Output", row).SetFloat1D(row, 1)
+	}
+	ab := &ABAC{On: true}
+	ab.Defaults()
+	if err := ab.Config(dt, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatal(err)
+	}
+	for row := 0; row < n; row++ {
+		pr := ab.Pairs[row]
+		if pr == row {
+			t.Errorf("item %d paired with itself\n", row)
+		}
+		if ab.AC.CellString("Name", row) != dt.CellString("Name", row) || ab.AC.CellTensor("Input", row).FloatVal1D(row) != 1 {
+			t.Errorf("A cue of item %d not kept\n", row)
+		}
+		if ab.AC.CellTensor("Output", row).FloatVal1D(pr) != 1 || ab.AC.CellTensor("Output", row).FloatVal1D(row) != 0 {
+			t.Errorf("C of item %d is not the associate of item %d\n", row, pr)
+		}
+	}
+	if ab.ListAt(9) != "AB" || ab.ListAt(10) != "AC" || ab.Done(19) || !ab.Done(20) {
+		t.Errorf("phases wrong\n")
+	}
+	if ab.Table("AC") != ab.AC || ab.Table("AB") != dt || ab.Table("X") != nil {
+		t.Errorf("tables wrong\n")
+	}
+	ab.OutCols = []string{"Nope"}
+	if ab.Config(dt, rand.New(rand.NewSource(1))) == nil {
+		t.Errorf("expected error for unknown associate column\n")
+	}
+}
@@ -131,8 +131,19 @@ func (ac *ActParams) GRawFmInc(nrn *Neuron) {
 }
 
 // GeGiFmInc integrates Ge excitatory conductance from GeInc delta-increment sent
-// and also GiRaw and GiSyn from GiInc.
+// and also GiRaw and GiSyn from GiInc.  Non-Fixed noise is generated here, from
+// the global random number generator -- Layer.GFmInc instead generates it for
+// all neurons in advance (see Layer.GenNoiseOn) and calls GeGiFmIncNoise.
 func (ac *ActParams) GeGiFmInc(nrn *Neuron) {
+	if ac.Noise.Type != NoNoise && !ac.Noise.Fixed && ac.Noise.Dist != erand.Mean {
+		nrn.Noise = float32(ac.Noise.Gen(-1))
+	}
+	ac.GeGiFmIncNoise(nrn)
+}
+
+// GeGiFmIncNoise is GeGiFmInc using the noise value already in nrn.Noise,
+// without generating it.
+func (ac *ActParams) GeGiFmIncNoise(nrn *Neuron) {
 	ac.GRawFmInc(nrn)
 
 	geRaw := nrn.GeRaw
@@ -148,10 +159,7 @@ func (ac *ActParams) GeGiFmInc(nrn *Neuron) {
 	ac.Dt.GFmRaw(nrn.GiRaw, &nrn.GiSyn)
 	nrn.GiSyn = math32.Max(nrn.GiSyn, 0) // negative inhib G doesn't make any sense
 
-	// first place noise is required -- generate here!
-	if ac.Noise.Type != NoNoise && !ac.Noise.Fixed && ac.Noise.Dist != erand.Mean {
-		nrn.Noise = float32(ac.Noise.Gen(-1))
-	}
+	// first place noise is required
 	if ac.Noise.Type == GeNoise {
 		nrn.Ge += nrn.Noise
 	}
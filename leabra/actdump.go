@@ -0,0 +1,294 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+
+	"github.com/emer/etable/etensor"
+)
+
+// ActDump writes unit variables (e.g., Act) of a set of layers to a single
+// binary file of fixed-size records, for runs that record the full activity
+// over hundreds of thousands of cycles: each record holds the values of
+// each layer and variable in turn, as little-endian float32 in flat index
+// order.  The data file, <Prefix>.bin, is memory-mapped and grown in chunks
+// of ChunkMB as it is written, and an index of the record at which each
+// trial (or other segment, see Mark) starts is written with the layout of
+// the records to <Prefix>.idx (JSON) on Close, so that OpenActDump can find
+// e.g., a given sleep trial of a given epoch and read only its records.
+type ActDump struct {
+	On      bool           `desc:"if false, Record does nothing"`
+	Time    TimeScales     `desc:"resolution of the records for RecordCycle: Cycle for every cycle, Quarter for the last cycle of each quarter"`
+	ChunkMB int            `min:"1" desc:"size of the chunks by which the data file grows as it is written, in MB"`
+	Prefix  string         `desc:"prefix of the file names, including any directory"`
+	Layers  []string       `desc:"names of layers to record"`
+	Vars    []string       `desc:"names of neuron variables to record (e.g., Act, Ge)"`
+	NRecs   int            `inactive:"+" desc:"number of records written since Open"`
+	Index   []ActDumpEntry `desc:"the record at which each trial or other segment starts -- see Mark"`
+	hdr     ActDumpHeader
+	vidxs   []int
+	df      *dumpFile
+	buf     []byte
+}
+
+// ActDumpEntry is an entry of the index of an ActDump: the first record of
+// a trial or other segment, e.g., a sleep trial
+type ActDumpEntry struct {
+	Rec   int    `desc:"index of the first record"`
+	Mode  string `desc:"mode, e.g., train, test, sleep"`
+	Run   int    `desc:"run counter"`
+	Epoch int    `desc:"epoch counter"`
+	Trial int    `desc:"trial counter"`
+	Label string `desc:"label, e.g., the name of the trial"`
+}
+
+// ActDumpHeader describes the records of an ActDump, with its index,
+// as written to the index file
+type ActDumpHeader struct {
+	Network string           `desc:"name of the network"`
+	Time    string           `desc:"resolution of the records"`
+	NRecs   int              `desc:"number of records"`
+	RecSize int              `desc:"number of float32 values in each record"`
+	Layers  []string         `desc:"names of the recorded layers"`
+	Vars    []string         `desc:"names of the recorded variables"`
+	Shapes  map[string][]int `desc:"shape of each layer"`
+	Offsets map[string]int   `desc:"offset in values of each Layer_Var within a record"`
+	Index   []ActDumpEntry   `desc:"first record of each trial or other segment"`
+}
+
+func (ad *ActDump) Defaults() {
+	ad.ChunkMB = 64
+}
+
+// Open creates the data file for recording given variables from given
+// layers of the network to files with given prefix, and turns On recording
+// -- if no vars are given, Act is recorded.  Any previous files are closed.
+func (ad *ActDump) Open(nt *Network, prefix string, lays []string, vars ...string) error {
+	ad.Close()
+	if len(vars) == 0 {
+		vars = []string{"Act"}
+	}
+	if ad.ChunkMB < 1 {
+		ad.ChunkMB = 64
+	}
+	ad.Prefix = prefix
+	ad.Layers = lays
+	ad.Vars = vars
+	ad.NRecs = 0
+	ad.Index = nil
+	ad.vidxs = make([]int, len(vars))
+	for i, vnm := range vars {
+		vidx, err := NeuronVarByName(vnm)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		ad.vidxs[i] = vidx
+	}
+	ad.hdr = ActDumpHeader{Network: nt.Nm, Layers: lays, Vars: vars, Shapes: make(map[string][]int), Offsets: make(map[string]int)}
+	for _, lnm := range lays {
+		ly, err := nt.LayerByNameTry(lnm)
+		if err != nil {
+			return err
+		}
+		lly := ly.(LeabraLayer).AsLeabra()
+		ad.hdr.Shapes[lnm] = lly.Shp.Shp
+		for _, vnm := range vars {
+			ad.hdr.Offsets[lnm+"_"+vnm] = ad.hdr.RecSize
+			ad.hdr.RecSize += len(lly.Neurons)
+		}
+	}
+	ad.buf = make([]byte, 4*ad.hdr.RecSize)
+	df, err := createDumpFile(prefix+".bin", int64(ad.ChunkMB)<<20)
+	if err != nil {
+		return err
+	}
+	ad.df = df
+	ad.On = true
+	return nil
+}
+
+// Record writes a record of the current values of the variables for each layer
+func (ad *ActDump) Record(nt *Network) error {
+	if !ad.On || ad.df == nil {
+		return nil
+	}
+	bi := 0
+	for _, lnm := range ad.Layers {
+		lly := nt.LayerByName(lnm).(LeabraLayer).AsLeabra()
+		for _, vidx := range ad.vidxs {
+			for ni := range lly.Neurons {
+				binary.LittleEndian.PutUint32(ad.buf[bi:], math.Float32bits(lly.Neurons[ni].VarByIndex(vidx)))
+				bi += 4
+			}
+		}
+	}
+	if err := ad.df.write(ad.buf); err != nil {
+		ad.On = false
+		return err
+	}
+	ad.NRecs++
+	return nil
+}
+
+// RecordCycle records at the resolution given by Time: every cycle for
+// Cycle, or at the last cycle of each quarter for Quarter -- call after
+// Network.Cycle and before Time.CycleInc
+func (ad *ActDump) RecordCycle(nt *Network, ltime *Time) error {
+	if ad.Time == Quarter && (ltime.Cycle+1)%ltime.CycPerQtr != 0 {
+		return nil
+	}
+	return ad.Record(nt)
+}
+
+// Mark adds an entry to the Index for the next record, e.g., at the start
+// of a trial
+func (ad *ActDump) Mark(mode string, run, epoch, trial int, label string) {
+	if !ad.On {
+		return
+	}
+	ad.Index = append(ad.Index, ActDumpEntry{Rec: ad.NRecs, Mode: mode, Run: run, Epoch: epoch, Trial: trial, Label: label})
+}
+
+// Header returns the header describing the records, with the Index
+func (ad *ActDump) Header() *ActDumpHeader {
+	hd := ad.hdr
+	hd.Time = ad.Time.String()
+	hd.NRecs = ad.NRecs
+	hd.Index = ad.Index
+	return &hd
+}
+
+// WriteIndex writes the header and Index to <Prefix>.idx -- called by
+// Close, and can be called periodically so that the records written so far
+// can be found if the run does not finish
+func (ad *ActDump) WriteIndex() error {
+	b, err := json.MarshalIndent(ad.Header(), "", "  ")
+	if err == nil {
+		err = ioutil.WriteFile(ad.Prefix+".idx", b, 0644)
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// Close closes the data file, truncated to the records written, writes
+// the index, and turns recording off
+func (ad *ActDump) Close() error {
+	if ad.df == nil {
+		return nil
+	}
+	err := ad.df.close()
+	ad.df = nil
+	ad.On = false
+	if ierr := ad.WriteIndex(); err == nil {
+		err = ierr
+	}
+	return err
+}
+
+// ActDumpReader reads the records of an ActDump, with the data file
+// memory-mapped so that only the records that are read are loaded
+type ActDumpReader struct {
+	Header ActDumpHeader `desc:"header and index of the dump"`
+	dd     *dumpData
+}
+
+// OpenActDump opens the ActDump with given prefix for reading
+func OpenActDump(prefix string) (*ActDumpReader, error) {
+	ar := &ActDumpReader{}
+	b, err := ioutil.ReadFile(prefix + ".idx")
+	if err == nil {
+		err = json.Unmarshal(b, &ar.Header)
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	ar.dd, err = openDumpData(prefix + ".bin")
+	if err != nil {
+		return nil, err
+	}
+	if need := int64(4 * ar.Header.RecSize * ar.Header.NRecs); ar.dd.size < need {
+		ar.dd.close()
+		err = fmt.Errorf("OpenActDump: data file of: %v has %v bytes, want %v for %v records", prefix, ar.dd.size, need, ar.Header.NRecs)
+		log.Println(err)
+		return nil, err
+	}
+	return ar, nil
+}
+
+// Close closes the data file
+func (ar *ActDumpReader) Close() error {
+	if ar.dd == nil {
+		return nil
+	}
+	err := ar.dd.close()
+	ar.dd = nil
+	return err
+}
+
+// Find returns the indexes in the Header.Index of the entries that match
+// given mode, run, epoch and trial, where "" or -1 matches anything
+func (ar *ActDumpReader) Find(mode string, run, epoch, trial int) []int {
+	var idxs []int
+	for i, e := range ar.Header.Index {
+		if (mode == "" || e.Mode == mode) && (run < 0 || e.Run == run) && (epoch < 0 || e.Epoch == epoch) && (trial < 0 || e.Trial == trial) {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
+
+// EntryRecs returns the range of records [st, ed) of the entry with given
+// index in the Header.Index: up to the start of the next entry
+func (ar *ActDumpReader) EntryRecs(idx int) (st, ed int) {
+	st = ar.Header.Index[idx].Rec
+	ed = ar.Header.NRecs
+	if idx+1 < len(ar.Header.Index) {
+		ed = ar.Header.Index[idx+1].Rec
+	}
+	return
+}
+
+// Read returns the values of given variable for given layer in records
+// [st, ed), as a tensor of shape ed-st x layer shape
+func (ar *ActDumpReader) Read(lay, vnm string, st, ed int) (*etensor.Float32, error) {
+	shp, has := ar.Header.Shapes[lay]
+	off, hasv := ar.Header.Offsets[lay+"_"+vnm]
+	if !has || !hasv {
+		err := fmt.Errorf("ActDumpReader: layer: %v var: %v not recorded", lay, vnm)
+		log.Println(err)
+		return nil, err
+	}
+	if st < 0 || ed > ar.Header.NRecs || st > ed {
+		err := fmt.Errorf("ActDumpReader: records: %v-%v out of range of: %v records", st, ed, ar.Header.NRecs)
+		log.Println(err)
+		return nil, err
+	}
+	nu := 1
+	for _, d := range shp {
+		nu *= d
+	}
+	tsr := etensor.NewFloat32(append([]int{ed - st}, shp...), nil, nil)
+	b := make([]byte, 4*nu)
+	for ri := st; ri < ed; ri++ {
+		if err := ar.dd.readAt(b, 4*(int64(ri)*int64(ar.Header.RecSize)+int64(off))); err != nil {
+			return nil, err
+		}
+		vals := tsr.Values[(ri-st)*nu:]
+		for i := 0; i < nu; i++ {
+			vals[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+		}
+	}
+	return tsr, nil
+}
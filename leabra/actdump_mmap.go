@@ -0,0 +1,156 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"syscall"
+)
+
+// dumpFile is the data file of an ActDump, written through memory-mapped
+// chunks that are added to the end of the file as needed
+type dumpFile struct {
+	f     *os.File
+	chunk int64
+	off   int64
+	data  []byte
+	pos   int
+	size  int64
+}
+
+// createDumpFile creates the data file with given name, to be grown in
+// chunks of given size (rounded up to a whole number of pages)
+func createDumpFile(fn string, chunk int64) (*dumpFile, error) {
+	f, err := os.Create(fn)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	if pg := int64(os.Getpagesize()); chunk%pg != 0 {
+		chunk = (chunk/pg + 1) * pg
+	}
+	return &dumpFile{f: f, chunk: chunk}, nil
+}
+
+// next unmaps the current chunk and maps the next one, extending the file
+func (df *dumpFile) next() error {
+	if df.data != nil {
+		if err := syscall.Munmap(df.data); err != nil {
+			log.Println(err)
+			return err
+		}
+		df.off += int64(len(df.data))
+		df.data = nil
+	}
+	if err := df.f.Truncate(df.off + df.chunk); err != nil {
+		log.Println(err)
+		return err
+	}
+	data, err := syscall.Mmap(int(df.f.Fd()), df.off, int(df.chunk), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	df.data = data
+	df.pos = 0
+	return nil
+}
+
+// write appends given bytes to the file
+func (df *dumpFile) write(p []byte) error {
+	for len(p) > 0 {
+		if df.pos == len(df.data) {
+			if err := df.next(); err != nil {
+				return err
+			}
+		}
+		n := copy(df.data[df.pos:], p)
+		df.pos += n
+		df.size += int64(n)
+		p = p[n:]
+	}
+	return nil
+}
+
+// close unmaps the current chunk and truncates the file to the bytes written
+func (df *dumpFile) close() error {
+	var err error
+	if df.data != nil {
+		err = syscall.Munmap(df.data)
+		df.data = nil
+	}
+	if terr := df.f.Truncate(df.size); err == nil {
+		err = terr
+	}
+	if cerr := df.f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// dumpData is the data file of an ActDump, memory-mapped read-only
+type dumpData struct {
+	f    *os.File
+	data []byte
+	size int64
+}
+
+// openDumpData opens and maps the data file with given name
+func openDumpData(fn string) (*dumpData, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		log.Println(err)
+		f.Close()
+		return nil, err
+	}
+	dd := &dumpData{f: f, size: fi.Size()}
+	if dd.size == 0 { // empty files cannot be mapped
+		return dd, nil
+	}
+	dd.data, err = syscall.Mmap(int(f.Fd()), 0, int(dd.size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		log.Println(err)
+		f.Close()
+		return nil, err
+	}
+	return dd, nil
+}
+
+// readAt reads len(p) bytes at given offset
+func (dd *dumpData) readAt(p []byte, off int64) error {
+	if off < 0 || off+int64(len(p)) > dd.size {
+		err := fmt.Errorf("ActDumpReader: read of %v bytes at: %v past the end of the data: %v bytes", len(p), off, dd.size)
+		log.Println(err)
+		return err
+	}
+	copy(p, dd.data[off:])
+	return nil
+}
+
+// close unmaps and closes the file
+func (dd *dumpData) close() error {
+	var err error
+	if dd.data != nil {
+		err = syscall.Munmap(dd.data)
+		dd.data = nil
+	}
+	if cerr := dd.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
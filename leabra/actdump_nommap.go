@@ -0,0 +1,88 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package leabra
+
+import (
+	"bufio"
+	"log"
+	"os"
+)
+
+// dumpFile is the data file of an ActDump -- on windows it is written with
+// a buffered writer instead of being memory-mapped
+type dumpFile struct {
+	f  *os.File
+	bw *bufio.Writer
+}
+
+// createDumpFile creates the data file with given name -- chunk is ignored
+func createDumpFile(fn string, chunk int64) (*dumpFile, error) {
+	f, err := os.Create(fn)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return &dumpFile{f: f, bw: bufio.NewWriterSize(f, 1<<20)}, nil
+}
+
+// write appends given bytes to the file
+func (df *dumpFile) write(p []byte) error {
+	_, err := df.bw.Write(p)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// close flushes and closes the file
+func (df *dumpFile) close() error {
+	err := df.bw.Flush()
+	if cerr := df.f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// dumpData is the data file of an ActDump, read with ReadAt
+type dumpData struct {
+	f    *os.File
+	size int64
+}
+
+// openDumpData opens the data file with given name
+func openDumpData(fn string) (*dumpData, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		log.Println(err)
+		f.Close()
+		return nil, err
+	}
+	return &dumpData{f: f, size: fi.Size()}, nil
+}
+
+// readAt reads len(p) bytes at given offset
+func (dd *dumpData) readAt(p []byte, off int64) error {
+	_, err := dd.f.ReadAt(p, off)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// close closes the file
+func (dd *dumpData) close() error {
+	return dd.f.Close()
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestActDump(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "DumpNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 3, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	net.InitWts()
+
+	dir, err := ioutil.TempDir("", "dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	prefix := filepath.Join(dir, "dump")
+	ad := &ActDump{}
+	ad.Defaults()
+	if err := ad.Open(net, prefix, []string{"Input", "Hidden"}, "Act", "Ge"); err != nil {
+		t.Fatal(err)
+	}
+	hid := hidLay.(*Layer)
+	for trl := 0; trl < 3; trl++ {
+		ad.Mark("sleep", 0, 1, trl, "")
+		for cyc := 0; cyc < 4; cyc++ {
+			hid.Neurons[2].Act = float32(10*trl + cyc)
+			if err := ad.Record(net); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := ad.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ar, err := OpenActDump(prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ar.Close()
+	if ar.Header.NRecs != 12 || ar.Header.RecSize != 2*4+2*6 || ar.Header.Network != "DumpNet" {
+		t.Errorf("header wrong: %+v\n", ar.Header)
+	}
+	idxs := ar.Find("sleep", -1, 1, 2)
+	if len(idxs) != 1 {
+		t.Fatalf("found: %v, want 1 entry\n", idxs)
+	}
+	st, ed := ar.EntryRecs(idxs[0])
+	if st != 8 || ed != 12 {
+		t.Errorf("records: %v-%v, want 8-12\n", st, ed)
+	}
+	tsr, err := ar.Read("Hidden", "Act", st, ed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shp := tsr.Shapes(); len(shp) != 3 || shp[0] != 4 || shp[1] != 3 || shp[2] != 2 {
+		t.Errorf("shape wrong: %v\n", shp)
+	}
+	if v := tsr.Value([]int{3, 1, 0}); v != 23 {
+		t.Errorf("value wrong: %v, want 23\n", v)
+	}
+	if len(ar.Find("train", -1, -1, -1)) != 0 {
+		t.Errorf("found train entries\n")
+	}
+	if _, err := ar.Read("Hidden", "Vm", 0, 1); err == nil {
+		t.Errorf("expected error for unrecorded var\n")
+	}
+}
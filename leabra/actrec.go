@@ -0,0 +1,98 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+)
+
+// ActRecord records unit variables (e.g., Act) for a set of layers over time,
+// typically every cycle, for later export (e.g., export.WriteHDF5) and analysis.
+// Values are stored in flat slices for each layer and variable, growing by the
+// number of units in the layer for each call to Record.
+type ActRecord struct {
+	On     bool                 `desc:"if false, Record does nothing"`
+	Layers []string             `desc:"names of layers to record"`
+	Vars   []string             `desc:"names of neuron variables to record (e.g., Act, Ge)"`
+	NRecs  int                  `inactive:"+" desc:"number of records (time steps) recorded since last Reset"`
+	Data   map[string][]float32 `view:"-" desc:"recorded data, keyed by Layer/Var -- NRecs x number of units in layer"`
+	NUnits map[string]int       `view:"-" desc:"number of units in each layer"`
+	vidxs  []int
+}
+
+// Init initializes the recorder to record given variables from given layers
+// in the network -- if no vars are given, Act is recorded.
+func (ar *ActRecord) Init(nt *Network, lays []string, vars ...string) error {
+	if len(vars) == 0 {
+		vars = []string{"Act"}
+	}
+	ar.Layers = lays
+	ar.Vars = vars
+	ar.vidxs = make([]int, len(vars))
+	for i, vnm := range vars {
+		vidx, err := NeuronVarByName(vnm)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		ar.vidxs[i] = vidx
+	}
+	ar.NUnits = make(map[string]int, len(lays))
+	for _, lnm := range lays {
+		ly, err := nt.LayerByNameTry(lnm)
+		if err != nil {
+			return err
+		}
+		ar.NUnits[lnm] = len(ly.(LeabraLayer).AsLeabra().Neurons)
+	}
+	ar.Reset()
+	return nil
+}
+
+// Reset clears any recorded data
+func (ar *ActRecord) Reset() {
+	ar.NRecs = 0
+	ar.Data = make(map[string][]float32, len(ar.Layers)*len(ar.Vars))
+}
+
+// Key returns the key into Data for given layer and variable
+func (ar *ActRecord) Key(lay, vnm string) string {
+	return lay + "/" + vnm
+}
+
+// Record records the current values of the variables for each layer
+func (ar *ActRecord) Record(nt *Network) {
+	if !ar.On {
+		return
+	}
+	for _, lnm := range ar.Layers {
+		ly := nt.LayerByName(lnm)
+		if ly == nil {
+			continue
+		}
+		lly := ly.(LeabraLayer).AsLeabra()
+		for vi, vnm := range ar.Vars {
+			key := ar.Key(lnm, vnm)
+			vs := ar.Data[key]
+			vidx := ar.vidxs[vi]
+			for ni := range lly.Neurons {
+				vs = append(vs, lly.Neurons[ni].VarByIndex(vidx))
+			}
+			ar.Data[key] = vs
+		}
+	}
+	ar.NRecs++
+}
+
+// Vals returns the recorded values for given layer and variable, as a
+// flat slice of NRecs x number of units in layer
+func (ar *ActRecord) Vals(lay, vnm string) ([]float32, error) {
+	vs, has := ar.Data[ar.Key(lay, vnm)]
+	if !has {
+		return nil, fmt.Errorf("ActRecord: no data for layer: %v var: %v", lay, vnm)
+	}
+	return vs, nil
+}
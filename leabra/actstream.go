@@ -0,0 +1,265 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"path/filepath"
+
+	"github.com/emer/etable/etensor"
+)
+
+// ActStream streams unit variables (e.g., Act, Ge, AvgL) for a set of layers
+// directly to disk as they are recorded, at Cycle or Quarter resolution, so
+// that the full dynamics of e.g., long sleep trials can be analyzed offline
+// with a minimal memory footprint -- unlike ActRecord, which keeps all the
+// records in memory.  Each layer and variable is written to its own raw
+// file of little-endian float32 values, NRecs x number of units (in flat
+// index order): <Prefix>_<Layer>_<Var>.f32, and a JSON header with the shapes
+// and the number of records is written to <Prefix>.json on Close -- see
+// ReadActStream.  In numpy: np.fromfile(f, '<f4').reshape(nrecs, *shape)
+// The files can also be gzip compressed and rotated according to Opts (see
+// LogFile), in which case the names of the files of each layer and variable
+// are listed in the header, and their records are read in order.
+type ActStream struct {
+	On     bool        `desc:"if false, Record does nothing"`
+	Time   TimeScales  `desc:"resolution of the records for RecordCycle: Cycle for every cycle, Quarter for the last cycle of each quarter"`
+	Prefix string      `desc:"prefix of the file names, including any directory"`
+	Layers []string    `desc:"names of layers to record"`
+	Vars   []string    `desc:"names of neuron variables to record (e.g., Act, Ge)"`
+	Opts   LogFileOpts `desc:"compression and rotation of the files -- set before Open"`
+	NRecs  int         `inactive:"+" desc:"number of records written since Open"`
+	Marks  []ActMark   `desc:"labeled records, e.g., the start of each trial -- see Mark"`
+	netNm  string
+	shapes map[string][]int
+	vidxs  []int
+	files  []*LogFile
+	buf    []byte
+}
+
+// ActMark labels the record at which something happened, e.g., the start
+// of a sleep trial
+type ActMark struct {
+	Rec   int    `desc:"index of the next record when the mark was made"`
+	Label string `desc:"label, e.g., sleep trial 3"`
+}
+
+// ActStreamHeader is the JSON header of an ActStream, describing the files
+type ActStreamHeader struct {
+	Network string              `desc:"name of the network"`
+	Time    string              `desc:"resolution of the records"`
+	NRecs   int                 `desc:"number of records in each file"`
+	Layers  []string            `desc:"names of the recorded layers"`
+	Vars    []string            `desc:"names of the recorded variables"`
+	Shapes  map[string][]int    `desc:"shape of each layer"`
+	Marks   []ActMark           `desc:"labeled records"`
+	Files   map[string][]string `desc:"names of the files of each Layer_Var (without directory), in order, if compressed or rotated -- otherwise there is one file per ActStreamFile"`
+}
+
+// ActStreamFile returns the name of the file for given prefix, layer and variable
+func ActStreamFile(prefix, lay, vnm string) string {
+	return prefix + "_" + lay + "_" + vnm + ".f32"
+}
+
+// Open creates the files for streaming given variables from given layers of
+// the network to files with given prefix, and turns On recording -- if no
+// vars are given, Act is recorded.  Any previous files are closed.
+func (as *ActStream) Open(nt *Network, prefix string, lays []string, vars ...string) error {
+	as.Close()
+	if len(vars) == 0 {
+		vars = []string{"Act"}
+	}
+	as.netNm = nt.Nm
+	as.Prefix = prefix
+	as.Layers = lays
+	as.Vars = vars
+	as.NRecs = 0
+	as.Marks = nil
+	as.vidxs = make([]int, len(vars))
+	for i, vnm := range vars {
+		vidx, err := NeuronVarByName(vnm)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		as.vidxs[i] = vidx
+	}
+	as.shapes = make(map[string][]int, len(lays))
+	for _, lnm := range lays {
+		ly, err := nt.LayerByNameTry(lnm)
+		if err != nil {
+			return err
+		}
+		as.shapes[lnm] = ly.(LeabraLayer).AsLeabra().Shp.Shp
+	}
+	for _, lnm := range lays {
+		for _, vnm := range vars {
+			f, err := CreateLogFile(ActStreamFile(prefix, lnm, vnm), as.Opts, false)
+			if err != nil {
+				as.Close()
+				return err
+			}
+			as.files = append(as.files, f)
+		}
+	}
+	as.On = true
+	return nil
+}
+
+// Record writes the current values of the variables for each layer
+func (as *ActStream) Record(nt *Network) {
+	if !as.On || len(as.files) == 0 {
+		return
+	}
+	fi := 0
+	for _, lnm := range as.Layers {
+		lly := nt.LayerByName(lnm).(LeabraLayer).AsLeabra()
+		for _, vidx := range as.vidxs {
+			if need := 4 * len(lly.Neurons); len(as.buf) < need {
+				as.buf = make([]byte, need)
+			}
+			for ni := range lly.Neurons {
+				binary.LittleEndian.PutUint32(as.buf[4*ni:], math.Float32bits(lly.Neurons[ni].VarByIndex(vidx)))
+			}
+			as.files[fi].Write(as.buf[:4*len(lly.Neurons)])
+			fi++
+		}
+	}
+	as.NRecs++
+}
+
+// RecordCycle records at the resolution given by Time: every cycle for
+// Cycle, or at the last cycle of each quarter for Quarter -- call after
+// Network.Cycle and before Time.CycleInc
+func (as *ActStream) RecordCycle(nt *Network, ltime *Time) {
+	if as.Time == Quarter && (ltime.Cycle+1)%ltime.CycPerQtr != 0 {
+		return
+	}
+	as.Record(nt)
+}
+
+// Mark labels the next record, e.g., with the start of a trial
+func (as *ActStream) Mark(label string) {
+	if !as.On {
+		return
+	}
+	as.Marks = append(as.Marks, ActMark{Rec: as.NRecs, Label: label})
+}
+
+// Header returns the header describing the files
+func (as *ActStream) Header() *ActStreamHeader {
+	hd := &ActStreamHeader{Network: as.netNm, Time: as.Time.String(), NRecs: as.NRecs, Layers: as.Layers, Vars: as.Vars, Shapes: as.shapes, Marks: as.Marks}
+	if as.Opts.Gzip || as.Opts.Rotate() {
+		hd.Files = make(map[string][]string)
+		fi := 0
+		for _, lnm := range as.Layers {
+			for _, vnm := range as.Vars {
+				if fi >= len(as.files) {
+					break
+				}
+				var fns []string
+				for _, fn := range as.files[fi].Files() {
+					fns = append(fns, filepath.Base(fn))
+				}
+				hd.Files[lnm+"_"+vnm] = fns
+				fi++
+			}
+		}
+	}
+	return hd
+}
+
+// Flush writes any buffered records to the files
+func (as *ActStream) Flush() error {
+	for _, f := range as.files {
+		if err := f.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes and closes the files, writes the header to <Prefix>.json,
+// and turns recording off
+func (as *ActStream) Close() error {
+	if len(as.files) == 0 {
+		return nil
+	}
+	var err error
+	for _, f := range as.files {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	hd := as.Header()
+	as.files = nil
+	as.On = false
+	b, jerr := json.MarshalIndent(hd, "", "  ")
+	if jerr == nil {
+		jerr = ioutil.WriteFile(as.Prefix+".json", b, 0644)
+	}
+	if jerr != nil {
+		log.Println(jerr)
+		return jerr
+	}
+	return err
+}
+
+// ReadActStream reads the records for given layer and variable from the
+// files of an ActStream with given prefix, returning a tensor of shape
+// NRecs x layer shape, and the header -- compressed and rotated files are
+// read from the Files listed in the header
+func ReadActStream(prefix, lay, vnm string) (*etensor.Float32, *ActStreamHeader, error) {
+	hd := &ActStreamHeader{}
+	b, err := ioutil.ReadFile(prefix + ".json")
+	if err == nil {
+		err = json.Unmarshal(b, hd)
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, nil, err
+	}
+	shp, has := hd.Shapes[lay]
+	if !has {
+		err := fmt.Errorf("ReadActStream: layer: %v not recorded in: %v", lay, prefix)
+		log.Println(err)
+		return nil, hd, err
+	}
+	fns := []string{ActStreamFile(prefix, lay, vnm)}
+	if hfns, has := hd.Files[lay+"_"+vnm]; has {
+		fns = make([]string, len(hfns))
+		for i, fn := range hfns {
+			fns[i] = filepath.Join(filepath.Dir(prefix), fn)
+		}
+	}
+	rd, err := OpenLogFiles(fns)
+	if err == nil {
+		b, err = ioutil.ReadAll(rd)
+		rd.Close()
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, hd, err
+	}
+	nu := 1
+	for _, d := range shp {
+		nu *= d
+	}
+	if len(b) != 4*nu*hd.NRecs {
+		err := fmt.Errorf("ReadActStream: file for layer: %v var: %v has %v bytes, want %v for %v records", lay, vnm, len(b), 4*nu*hd.NRecs, hd.NRecs)
+		log.Println(err)
+		return nil, hd, err
+	}
+	tsr := etensor.NewFloat32(append([]int{hd.NRecs}, shp...), nil, nil)
+	for i := range tsr.Values {
+		tsr.Values[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return tsr, hd, nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestActStream(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "StreamNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 3, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	net.InitWts()
+
+	dir, err := ioutil.TempDir("", "acts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	prefix := filepath.Join(dir, "acts")
+	as := &ActStream{Time: Quarter}
+	if err := as.Open(net, prefix, []string{"Hidden"}, "Act", "Ge"); err != nil {
+		t.Fatal(err)
+	}
+	hid := hidLay.(*Layer)
+	ltime := NewTime()
+	as.Mark("trial 0")
+	for cyc := 0; cyc < 2*ltime.CycPerQtr; cyc++ {
+		hid.Neurons[1].Act = float32(cyc)
+		as.RecordCycle(net, ltime)
+		ltime.CycleInc()
+	}
+	if err := as.Close(); err != nil {
+		t.Fatal(err)
+	}
+	tsr, hd, err := ReadActStream(prefix, "Hidden", "Act")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hd.NRecs != 2 || len(hd.Marks) != 1 || hd.Network != "StreamNet" {
+		t.Errorf("header wrong: %+v\n", hd)
+	}
+	if shp := tsr.Shapes(); len(shp) != 3 || shp[0] != 2 || shp[1] != 3 || shp[2] != 2 {
+		t.Errorf("shape wrong: %v\n", shp)
+	}
+	if v := tsr.Value([]int{1, 0, 1}); v != float32(2*ltime.CycPerQtr-1) {
+		t.Errorf("value wrong: %v\n", v)
+	}
+	if _, _, err := ReadActStream(prefix, "Input", "Act"); err == nil {
+		t.Errorf("expected error for unrecorded layer\n")
+	}
+}
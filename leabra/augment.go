@@ -0,0 +1,215 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/ki/kit"
+)
+
+// AugTypes are the types of data-augmentation transforms of AugmentEnv
+type AugTypes int32
+
+//go:generate stringer -type=AugTypes
+
+var KiT_AugTypes = kit.Enums.AddEnum(AugTypesN, false, nil)
+
+func (ev AugTypes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *AugTypes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// The augmentation transforms
+const (
+	// AugJitter moves each active unit, with probability Level, to a random
+	// inactive neighbor (one unit up, down, left or right over the last two
+	// dimensions of the pattern), if it has one
+	AugJitter AugTypes = iota
+
+	// AugMask sets each unit to 0 with probability Level
+	AugMask
+
+	// AugScale multiplies all the values of the pattern by a random factor
+	// uniform in 1 +/- Level, clipped to 0-1
+	AugScale
+
+	AugTypesN
+)
+
+// AugTransform is one transform of the pipeline of an AugmentEnv
+type AugTransform struct {
+	Type  AugTypes `desc:"type of transform"`
+	Level float32  `min:"0" desc:"level of the transform: probability of moving each active unit for AugJitter, of masking each unit for AugMask, range of the scaling factor for AugScale"`
+}
+
+// AugmentEnv wraps an environment for training, applying a pipeline of
+// data-augmentation Transforms (jitter, masking, value scaling), in order,
+// to the input patterns of the Layers at presentation time, as a
+// regularization / generalization manipulation -- State returns a new
+// transformed copy of the pattern on each call.  The pipeline is set from
+// the Spec string, e.g., "Jitter:0.1,Mask:0.2", so it can be set by params.
+type AugmentEnv struct {
+	env.Env
+	Spec       string         `desc:"pipeline of transforms as Type:Level separated by , applied in order, e.g., Jitter:0.1,Mask:0.2,Scale:0.3 -- types are Jitter, Mask and Scale (case insensitive) -- empty for none"`
+	Layers     []string       `desc:"names of the elements (input layers) whose patterns are transformed -- the others are passed through"`
+	Transforms []AugTransform `inactive:"+" desc:"the transforms of the pipeline, from the Spec"`
+	Rand       *rand.Rand     `view:"-" desc:"random number stream for the transforms -- global generator if nil"`
+
+	spec string // Spec of the current Transforms
+}
+
+func (ae *AugmentEnv) Defaults() {
+	ae.Layers = []string{"Input"}
+}
+
+// On returns true if any transforms are applied
+func (ae *AugmentEnv) On() bool {
+	return ae.Env != nil && len(ae.Transforms) > 0
+}
+
+// Update sets the Transforms from the Spec if it has changed, e.g., by params
+func (ae *AugmentEnv) Update() error {
+	if ae.Spec == ae.spec {
+		return nil
+	}
+	return ae.SetString(ae.Spec)
+}
+
+// SetString sets the Spec and the Transforms from given spec: Type:Level
+// transforms separated by , e.g., "Jitter:0.1,Mask:0.2" -- no transforms
+// (and an error) if any of them is invalid
+func (ae *AugmentEnv) SetString(spec string) error {
+	ae.Spec, ae.spec = spec, spec
+	ae.Transforms = nil
+	if strings.TrimSpace(spec) == "" {
+		return nil
+	}
+	var trs []AugTransform
+	for _, ts := range strings.Split(spec, ",") {
+		parts := strings.Split(ts, ":")
+		if len(parts) != 2 {
+			err := fmt.Errorf("AugmentEnv: expected Type:Level in: %v", spec)
+			log.Println(err)
+			return err
+		}
+		tnm := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(parts[0])), "aug")
+		tr := AugTransform{Type: AugTypesN}
+		for at := AugJitter; at < AugTypesN; at++ {
+			if strings.TrimPrefix(strings.ToLower(at.String()), "aug") == tnm {
+				tr.Type = at
+			}
+		}
+		if tr.Type == AugTypesN {
+			err := fmt.Errorf("AugmentEnv: transform type: %v not one of: Jitter, Mask, Scale", parts[0])
+			log.Println(err)
+			return err
+		}
+		lev, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 32)
+		if err != nil || lev < 0 {
+			err = fmt.Errorf("AugmentEnv: level must be a number >= 0 in: %v", spec)
+			log.Println(err)
+			return err
+		}
+		tr.Level = float32(lev)
+		trs = append(trs, tr)
+	}
+	ae.Transforms = trs
+	return nil
+}
+
+// IsAugmented returns true if the element with given name is transformed
+func (ae *AugmentEnv) IsAugmented(element string) bool {
+	for _, l := range ae.Layers {
+		if l == element {
+			return true
+		}
+	}
+	return false
+}
+
+// State returns the state of given element of the Env, transformed by the
+// pipeline if it is one of the Layers
+func (ae *AugmentEnv) State(element string) etensor.Tensor {
+	pat := ae.Env.State(element)
+	if pat == nil || len(ae.Transforms) == 0 || !ae.IsAugmented(element) {
+		return pat
+	}
+	return ae.Augment(pat)
+}
+
+// Augment returns a copy of given pattern transformed by each of the
+// Transforms in turn
+func (ae *AugmentEnv) Augment(pat etensor.Tensor) *etensor.Float32 {
+	np := etensor.NewFloat32(pat.Shapes(), nil, nil)
+	for i := range np.Values {
+		np.Values[i] = float32(pat.FloatVal1D(i))
+	}
+	flt := rand.Float32
+	intn := rand.Intn
+	if ae.Rand != nil {
+		flt = ae.Rand.Float32
+		intn = ae.Rand.Intn
+	}
+	shp := pat.Shapes()
+	ny, nx := 1, shp[len(shp)-1]
+	if len(shp) > 1 {
+		ny = shp[len(shp)-2]
+	}
+	for _, tr := range ae.Transforms {
+		switch tr.Type {
+		case AugJitter:
+			moved := make([]bool, len(np.Values)) // each unit moves at most once
+			var nbrs []int
+			for i, v := range np.Values {
+				if v <= 0 || moved[i] || flt() >= tr.Level {
+					continue
+				}
+				y := (i / nx) % ny
+				x := i % nx
+				nbrs = nbrs[:0]
+				for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+					yy, xx := y+d[0], x+d[1]
+					if yy < 0 || yy >= ny || xx < 0 || xx >= nx {
+						continue
+					}
+					ni := i + d[0]*nx + d[1]
+					if np.Values[ni] <= 0 {
+						nbrs = append(nbrs, ni)
+					}
+				}
+				if len(nbrs) == 0 {
+					continue
+				}
+				ni := nbrs[intn(len(nbrs))]
+				np.Values[ni] = v
+				np.Values[i] = 0
+				moved[ni] = true
+			}
+		case AugMask:
+			for i := range np.Values {
+				if flt() < tr.Level {
+					np.Values[i] = 0
+				}
+			}
+		case AugScale:
+			f := 1 + tr.Level*(2*flt()-1)
+			for i, v := range np.Values {
+				v *= f
+				if v < 0 {
+					v = 0
+				} else if v > 1 {
+					v = 1
+				}
+				np.Values[i] = v
+			}
+		}
+	}
+	return np
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestAugmentEnv(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, []int{4, 4}, nil},
+		{"Output", etensor.FLOAT32, []int{4, 4}, nil},
+	}, 1)
+	for _, i := range []int{0, 5, 10, 15} { // the diagonal
+		dt.CellTensor("Input", 0).SetFloat1D(i, 1)
+		dt.CellTensor("Output", 0).SetFloat1D(i, 1)
+	}
+	ft := &env.FixedTable{}
+	ft.Table = etable.NewIdxView(dt)
+	ft.Init(0)
+	ft.Step()
+
+	ae := &AugmentEnv{Env: ft, Rand: rand.New(rand.NewSource(1))}
+	ae.Defaults()
+	sum := func(tsr etensor.Tensor) float64 {
+		s := 0.0
+		for i := 0; i < tsr.Len(); i++ {
+			s += tsr.FloatVal1D(i)
+		}
+		return s
+	}
+	if err := ae.SetString("Jitter:1"); err != nil || !ae.On() {
+		t.Fatalf("SetString: %v %v\n", err, ae.Transforms)
+	}
+	jit := ae.State("Input")
+	if s := sum(jit); s != 4 {
+		t.Errorf("jitter changed the number of active units: %v\n", s)
+	}
+	for _, i := range []int{0, 5, 10, 15} {
+		if jit.FloatVal1D(i) != 0 {
+			t.Errorf("unit %d not jittered\n", i)
+		}
+	}
+	if s := sum(ae.State("Output")); s != 4 || ae.State("Output").FloatVal1D(0) != 1 {
+		t.Errorf("Output transformed\n")
+	}
+	if dt.CellTensor("Input", 0).FloatVal1D(0) != 1 {
+		t.Errorf("pattern modified\n")
+	}
+
+	ae.Spec = "mask:1, Scale:0.5"
+	if err := ae.Update(); err != nil || len(ae.Transforms) != 2 || ae.Transforms[1].Type != AugScale {
+		t.Fatalf("Update: %v %v\n", err, ae.Transforms)
+	}
+	if s := sum(ae.State("Input")); s != 0 {
+		t.Errorf("all units not masked: %v\n", s)
+	}
+	ae.SetString("Scale:0.5")
+	if s := sum(ae.State("Input")); s > 4 || s < 2 {
+		t.Errorf("scaled sum: %v, want 2-4 (clipped to 1)\n", s)
+	}
+	if ae.SetString("Rotate:0.5") == nil || ae.On() {
+		t.Errorf("expected error for unknown transform\n")
+	}
+}
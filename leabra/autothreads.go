@@ -0,0 +1,229 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// NeurThrMin is the minimum number of neurons in a layer for its neuron-level
+// loops to be split across multiple go routines by AutoThreads
+var NeurThrMin = 1024
+
+// NeurRangeFun calls given function on the neurons of this layer, as ranges
+// st..ed, splitting the neurons across NeurThr parallel go routines if NeurThr > 1,
+// and otherwise calling it once on the full range.  The function must only
+// modify state for the neurons in its range.
+func (ly *Layer) NeurRangeFun(fun func(st, ed int)) {
+	nn := len(ly.Neurons)
+	nthr := ly.NeurThr
+	if nthr <= 1 || nn < 2*nthr {
+		fun(0, nn)
+		return
+	}
+	var wg sync.WaitGroup
+	per := (nn + nthr - 1) / nthr
+	for st := 0; st < nn; st += per {
+		ed := st + per
+		if ed > nn {
+			ed = nn
+		}
+		wg.Add(1)
+		go func(st, ed int) {
+			fun(st, ed)
+			wg.Done()
+		}(st, ed)
+	}
+	wg.Wait()
+}
+
+// LayCosts returns the estimated computational cost for each layer (0 for Off layers).
+// If measured per-layer times are available (from LayTiming) then those are used,
+// otherwise the cost is estimated as the number of neurons plus receiving synapses.
+func (nt *NetworkStru) LayCosts() []float64 {
+	costs := make([]float64, len(nt.Layers))
+	meas := false
+	if len(nt.LayTimes) == len(nt.Layers) {
+		for li := range nt.LayTimes {
+			if nt.LayTimes[li].TotalSecs() > 0 {
+				meas = true
+				break
+			}
+		}
+	}
+	for li, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		if meas {
+			costs[li] = nt.LayTimes[li].TotalSecs()
+			continue
+		}
+		lly := ly.(LeabraLayer).AsLeabra()
+		c := len(lly.Neurons)
+		for _, p := range lly.RcvPrjns {
+			if p.IsOff() {
+				continue
+			}
+			c += int(p.(LeabraPrjn).AsLeabra().Syns.Len())
+		}
+		costs[li] = float64(c)
+	}
+	return costs
+}
+
+// threadAlloc computes an allocation of layers to nthr threads, balancing the
+// given per-layer costs, using the longest-processing-time-first heuristic:
+// layers are assigned in order of decreasing cost to the least-loaded thread.
+// Layers whose cost exceeds the average per-thread load are also split across
+// multiple go routines for their neuron-level loops (if large enough), and their
+// cost is reduced accordingly.  Returns the thread and neuron-thread count for
+// each layer, and the resulting maximum thread load.
+func (nt *NetworkStru) threadAlloc(costs []float64, nthr int) (thrs, nthrs []int, maxLoad float64) {
+	nl := len(nt.Layers)
+	thrs = make([]int, nl)
+	nthrs = make([]int, nl)
+	tot := 0.0
+	for _, c := range costs {
+		tot += c
+	}
+	if nthr < 1 {
+		nthr = 1
+	}
+	target := tot / float64(nthr)
+	ecosts := make([]float64, nl)
+	idxs := make([]int, 0, nl)
+	for li, ly := range nt.Layers {
+		ecosts[li] = costs[li]
+		if ly.IsOff() {
+			continue
+		}
+		idxs = append(idxs, li)
+		nthrs[li] = 1
+		nn := len(ly.(LeabraLayer).AsLeabra().Neurons)
+		if nthr > 1 && target > 0 && costs[li] > target && nn >= NeurThrMin {
+			n := int(costs[li]/target + 0.5)
+			if n > nthr {
+				n = nthr
+			}
+			if n > 1 {
+				nthrs[li] = n
+				ecosts[li] = costs[li] / float64(n)
+			}
+		}
+	}
+	sort.SliceStable(idxs, func(i, j int) bool {
+		return ecosts[idxs[i]] > ecosts[idxs[j]]
+	})
+	loads := make([]float64, nthr)
+	for _, li := range idxs {
+		mth := 0
+		for th := 1; th < nthr; th++ {
+			if loads[th] < loads[mth] {
+				mth = th
+			}
+		}
+		thrs[li] = mth
+		loads[mth] += ecosts[li]
+	}
+	for _, ld := range loads {
+		if ld > maxLoad {
+			maxLoad = ld
+		}
+	}
+	return
+}
+
+// curMaxLoad returns the maximum thread load given the current thread allocation
+func (nt *NetworkStru) curMaxLoad(costs []float64) float64 {
+	loads := make(map[int]float64)
+	maxLoad := 0.0
+	for li, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		c := costs[li]
+		if n := ly.(LeabraLayer).AsLeabra().NeurThr; n > 1 {
+			c /= float64(n)
+		}
+		loads[ly.Thread()] += c
+		if loads[ly.Thread()] > maxLoad {
+			maxLoad = loads[ly.Thread()]
+		}
+	}
+	return maxLoad
+}
+
+// AutoThreads automatically allocates layers to nthr threads (0 = runtime.NumCPU()),
+// balancing the per-layer cost (measured if LayTiming data is available, otherwise
+// estimated from the number of neurons and synapses -- see LayCosts), and splits
+// the neuron-level loops of layers that are larger than the per-thread average
+// across multiple go routines (NeurThr).  Replaces any manual SetThread settings,
+// and rebuilds the threads.  Must be called after Build.
+func (nt *NetworkStru) AutoThreads(nthr int) {
+	if nthr <= 0 {
+		nthr = runtime.NumCPU()
+	}
+	costs := nt.LayCosts()
+	thrs, nthrs, _ := nt.threadAlloc(costs, nthr)
+	nt.applyThreads(thrs, nthrs)
+}
+
+// applyThreads sets the given thread allocation and rebuilds the threads
+func (nt *NetworkStru) applyThreads(thrs, nthrs []int) {
+	for li, ly := range nt.Layers {
+		ly.SetThread(thrs[li])
+		ly.(LeabraLayer).AsLeabra().NeurThr = nthrs[li]
+	}
+	nt.StopThreads()
+	nt.BuildThreads() // also resets LayTimes
+	nt.StartThreads()
+}
+
+// ThrAutoTune is called every cycle when ThrAuto is on, and re-tunes the threads
+// every ThrTuneCycles, based on the measured per-layer costs, if the new allocation
+// would reduce the maximum thread load by at least 5%.
+func (nt *NetworkStru) ThrAutoTune() {
+	nt.LayTiming = true
+	nt.thrTuneCtr++
+	tc := nt.ThrTuneCycles
+	if tc <= 0 {
+		tc = 200
+	}
+	if nt.thrTuneCtr < tc {
+		return
+	}
+	nt.thrTuneCtr = 0
+	nthr := nt.ThrAutoN
+	if nthr <= 0 {
+		nthr = runtime.NumCPU()
+	}
+	costs := nt.LayCosts()
+	thrs, nthrs, maxLoad := nt.threadAlloc(costs, nthr)
+	if maxLoad < 0.95*nt.curMaxLoad(costs) {
+		nt.applyThreads(thrs, nthrs)
+		return
+	}
+	for li := range nt.LayTimes {
+		nt.LayTimes[li].Reset()
+	}
+}
+
+// ThreadReport returns a report of the current allocation of layers to threads,
+// including the number of neuron-level threads and measured cost per layer
+func (nt *NetworkStru) ThreadReport() string {
+	str := fmt.Sprintf("Threads: %v, NThreads: %v\n", nt.Nm, nt.NThreads)
+	costs := nt.LayCosts()
+	for th := 0; th < nt.NThreads; th++ {
+		str += fmt.Sprintf("Thread: %v\n", th)
+		for _, ly := range nt.ThrLay[th] {
+			str += fmt.Sprintf("\t%v\tNeurThr: %v\tCost: %g\n", ly.Name(), ly.(LeabraLayer).AsLeabra().NeurThr, costs[ly.Index()])
+		}
+	}
+	return str
+}
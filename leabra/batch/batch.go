@@ -0,0 +1,257 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+	"github.com/goki/gi/gi"
+)
+
+// The Status of a BatchJob
+const (
+	BatchPending = "pending"
+	BatchRunning = "running"
+	BatchDone    = "done"
+	BatchFailed  = "failed"
+)
+
+// BatchJob is one job of a Batch: a run of the sim, as a separate process,
+// with given ParamSet, tag, seed and extra flags
+type BatchJob struct {
+	Params string   `json:",omitempty" desc:"ParamSet name, passed as -params -- Base if empty"`
+	Tag    string   `json:",omitempty" desc:"tag passed as -tag, which names the log files of the job -- set to job_<index> by Run if empty, so each job has its own files"`
+	Seed   int64    `json:",omitempty" desc:"random seed, passed as -seed -- not passed if 0"`
+	Flags  []string `json:",omitempty" desc:"extra flags, e.g., -runs 5"`
+	Status string   `json:"-" inactive:"+" desc:"status of the job: pending, running, done or failed"`
+	Err    string   `json:"-" inactive:"+" desc:"error of the job if failed"`
+	RunLog string   `json:"-" inactive:"+" desc:"run log file of the job, collated into the Results of the Batch"`
+}
+
+// Args returns the flags of the job
+func (bj *BatchJob) Args() []string {
+	var args []string
+	if bj.Params != "" {
+		args = append(args, "-params", bj.Params)
+	}
+	if bj.Tag != "" {
+		args = append(args, "-tag", bj.Tag)
+	}
+	if bj.Seed != 0 {
+		args = append(args, "-seed", strconv.FormatInt(bj.Seed, 10))
+	}
+	return append(args, bj.Flags...)
+}
+
+// Batch runs a list of jobs, e.g., each ParamSet with each of a set of
+// seeds, as separate processes of the sim, sequentially or NProcs in
+// parallel, and collates the run logs of the jobs into one master Results
+// table -- replacing ad-hoc shell loops.  The jobs are opened from a JSON
+// file with an array of the jobs, e.g., [{"Params": "HighInhib", "Seed": 2,
+// "Flags": ["-runs", "5"]}], or added with AddGrid.  The output of each job
+// is saved to <Tag>.log in LogDir.
+type Batch struct {
+	Jobs      []*BatchJob                `desc:"the jobs, run in order"`
+	NProcs    int                        `desc:"number of jobs run in parallel, as worker processes -- sequentially if <= 1"`
+	Cmd       string                     `desc:"executable run for each job -- the one running (os.Args[0]) if empty"`
+	Args      []string                   `desc:"args before the flags of each job, e.g., the train subcommand"`
+	LogDir    string                     `desc:"directory of the output of each job -- the current one if empty"`
+	RunLogFun func(job *BatchJob) string `view:"-" json:"-" desc:"returns the name of the run log file written by given job, which is collated into the Results"`
+	Results   *etable.Table              `view:"no-inline" desc:"the rows of the run logs of all the jobs done, with the Job, Params, Tag and Seed of each"`
+}
+
+// Open opens the jobs from given JSON file, adding them to the Jobs
+func (bt *Batch) Open(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	var jobs []*BatchJob
+	if err = json.Unmarshal(b, &jobs); err != nil {
+		err = fmt.Errorf("Batch: %v: %v", filename, err)
+		log.Println(err)
+		return err
+	}
+	bt.Jobs = append(bt.Jobs, jobs...)
+	return nil
+}
+
+// AddGrid adds a job for each of given ParamSets with each of given seeds
+// (the seed of the sim if none), all with given extra flags
+func (bt *Batch) AddGrid(sets []string, seeds []int64, flags []string) {
+	if len(sets) == 0 {
+		sets = []string{""}
+	}
+	if len(seeds) == 0 {
+		seeds = []int64{0}
+	}
+	for _, set := range sets {
+		for _, seed := range seeds {
+			bt.Jobs = append(bt.Jobs, &BatchJob{Params: set, Seed: seed, Flags: flags})
+		}
+	}
+}
+
+// Run runs all the jobs, NProcs at a time, and collates their run logs
+// into the Results -- returns an error if any of the jobs failed, after
+// running all of them
+func (bt *Batch) Run() error {
+	cmd := bt.Cmd
+	if cmd == "" {
+		cmd = os.Args[0]
+	}
+	for i, job := range bt.Jobs {
+		if job.Tag == "" {
+			job.Tag = fmt.Sprintf("job_%03d", i)
+		}
+		job.Status, job.Err, job.RunLog = BatchPending, "", ""
+	}
+	nprocs := bt.NProcs
+	if nprocs < 1 {
+		nprocs = 1
+	}
+	jobc := make(chan *BatchJob)
+	var wg sync.WaitGroup
+	for w := 0; w < nprocs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobc {
+				bt.runJob(cmd, job)
+			}
+		}()
+	}
+	for _, job := range bt.Jobs {
+		jobc <- job
+	}
+	close(jobc)
+	wg.Wait()
+	nfail := 0
+	for _, job := range bt.Jobs {
+		if job.Status == BatchFailed {
+			nfail++
+		}
+	}
+	cerr := bt.Collate()
+	if nfail > 0 {
+		err := fmt.Errorf("Batch: %d of %d jobs failed", nfail, len(bt.Jobs))
+		log.Println(err)
+		return err
+	}
+	return cerr
+}
+
+// runJob runs given job with given executable, saving its output to its
+// log file
+func (bt *Batch) runJob(cmd string, job *BatchJob) {
+	job.Status = BatchRunning
+	fmt.Printf("Batch: starting %v: %v\n", job.Tag, job.Args())
+	lognm := filepath.Join(bt.LogDir, job.Tag+".log")
+	lf, err := os.Create(lognm)
+	if err != nil {
+		job.Status, job.Err = BatchFailed, err.Error()
+		log.Println(err)
+		return
+	}
+	defer lf.Close()
+	args := append(append([]string{}, bt.Args...), job.Args()...)
+	c := exec.Command(cmd, args...)
+	c.Stdout = lf
+	c.Stderr = lf
+	if err := c.Run(); err != nil {
+		job.Status, job.Err = BatchFailed, err.Error()
+		fmt.Printf("Batch: %v failed: %v -- see: %v\n", job.Tag, err, lognm)
+		return
+	}
+	job.Status = BatchDone
+	if bt.RunLogFun != nil {
+		job.RunLog = bt.RunLogFun(job)
+	}
+	fmt.Printf("Batch: %v done\n", job.Tag)
+}
+
+// Collate collates the run logs of the jobs done into the Results, with
+// the columns of the first one (other than the Job, Params, Tag and Seed
+// of the job) -- returns an error if any could not be opened
+func (bt *Batch) Collate() error {
+	var err error
+	var dts []*etable.Table
+	var jobs []int
+	for ji, job := range bt.Jobs {
+		if job.Status != BatchDone || job.RunLog == "" {
+			continue
+		}
+		dt := &etable.Table{}
+		if oerr := dt.OpenCSV(gi.FileName(job.RunLog), etable.Tab); oerr != nil {
+			err = fmt.Errorf("Batch: %v: run log: %v: %v", job.Tag, job.RunLog, oerr)
+			log.Println(err)
+			continue
+		}
+		dts = append(dts, dt)
+		jobs = append(jobs, ji)
+	}
+	sch := etable.Schema{
+		{"Job", etensor.INT64, nil, nil},
+		{"Params", etensor.STRING, nil, nil},
+		{"Tag", etensor.STRING, nil, nil},
+		{"Seed", etensor.INT64, nil, nil},
+	}
+	nfix := len(sch)
+	if len(dts) > 0 {
+		for _, sc := range leabra.SchemaOf(dts[0]) {
+			switch sc.Name {
+			case "Job", "Params", "Tag", "Seed": // those of the job
+			default:
+				sch = append(sch, sc)
+			}
+		}
+	}
+	if bt.Results == nil {
+		bt.Results = &etable.Table{}
+	}
+	rt := bt.Results
+	rt.SetMetaData("name", "BatchRuns")
+	rt.SetMetaData("desc", "run logs of all the jobs of the batch")
+	rt.SetFromSchema(sch, 0)
+	for di, dt := range dts {
+		job := bt.Jobs[jobs[di]]
+		params := job.Params
+		if params == "" {
+			params = "Base"
+		}
+		for row := 0; row < dt.Rows; row++ {
+			orow := rt.Rows
+			rt.SetNumRows(orow + 1)
+			rt.SetCellFloat("Job", orow, float64(jobs[di]))
+			rt.SetCellString("Params", orow, params)
+			rt.SetCellString("Tag", orow, job.Tag)
+			rt.SetCellFloat("Seed", orow, float64(job.Seed))
+			for _, sc := range sch[nfix:] {
+				col := dt.ColByName(sc.Name)
+				if col == nil || col.Len() != dt.Rows {
+					continue
+				}
+				if col.DataType() == etensor.STRING {
+					rt.SetCellString(sc.Name, orow, col.StringVal1D(row))
+				} else {
+					rt.SetCellFloat(sc.Name, orow, col.FloatVal1D(row))
+				}
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package batch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+func TestBatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh for the jobs")
+	}
+	dir, err := ioutil.TempDir("", "batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	js := `[{"Params": "HighInhib", "Seed": 2, "Flags": ["-runs", "5"]}]`
+	fnm := filepath.Join(dir, "jobs.json")
+	if err := ioutil.WriteFile(fnm, []byte(js), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bt := &Batch{}
+	if err := bt.Open(gi.FileName(fnm)); err != nil {
+		t.Fatal(err)
+	}
+	bt.AddGrid([]string{"", "LowInhib"}, []int64{1, 2}, []string{"-runs", "2"})
+	if len(bt.Jobs) != 5 {
+		t.Fatalf("jobs: %v, want 5\n", len(bt.Jobs))
+	}
+	if args := bt.Jobs[0].Args(); len(args) != 6 || args[1] != "HighInhib" || args[3] != "2" {
+		t.Errorf("job args: %v\n", args)
+	}
+	bt.Jobs = append(bt.Jobs, &BatchJob{Tag: "bad", Flags: []string{"bad"}})
+	// each job writes a run log with its seed, and fails if given bad
+	bt.Cmd = "sh"
+	bt.Args = []string{"-c", `for a; do [ "$a" = bad ] && exit 1; done; exit 0`, "job"}
+	bt.LogDir = dir
+	bt.NProcs = 2
+	bt.RunLogFun = func(job *BatchJob) string {
+		dt := &etable.Table{}
+		dt.SetFromSchema(etable.Schema{
+			{"Params", etensor.STRING, nil, nil},
+			{"Run", etensor.INT64, nil, nil},
+			{"FirstZero", etensor.FLOAT64, nil, nil},
+		}, 2)
+		for row := 0; row < dt.Rows; row++ {
+			dt.SetCellString("Params", row, "ignored")
+			dt.SetCellFloat("Run", row, float64(row))
+			dt.SetCellFloat("FirstZero", row, float64(job.Seed*10))
+		}
+		fnm := filepath.Join(dir, job.Tag+"_run.csv")
+		dt.SaveCSV(gi.FileName(fnm), etable.Tab, true)
+		return fnm
+	}
+	if err := bt.Run(); err == nil {
+		t.Errorf("expected error for the failed job\n")
+	}
+	if st := bt.Jobs[5].Status; st != BatchFailed {
+		t.Errorf("bad job status: %v\n", st)
+	}
+	if st := bt.Jobs[1].Status; st != BatchDone || bt.Jobs[1].Tag != "job_001" {
+		t.Errorf("job status: %v tag: %v\n", st, bt.Jobs[1].Tag)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "job_001.log")); err != nil {
+		t.Errorf("job log not saved: %v\n", err)
+	}
+	rt := bt.Results
+	if rt.Rows != 10 {
+		t.Fatalf("results rows: %v, want 10\n", rt.Rows)
+	}
+	if rt.CellString("Params", 0) != "HighInhib" || rt.CellFloat("FirstZero", 0) != 20 {
+		t.Errorf("first job results: %v %v\n", rt.CellString("Params", 0), rt.CellFloat("FirstZero", 0))
+	}
+	if rt.CellString("Params", 2) != "Base" || rt.CellFloat("Seed", 2) != 1 || rt.CellFloat("Run", 3) != 1 {
+		t.Errorf("second job results: %v %v %v\n", rt.CellString("Params", 2), rt.CellFloat("Seed", 2), rt.CellFloat("Run", 3))
+	}
+}
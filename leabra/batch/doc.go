@@ -0,0 +1,11 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package batch runs and configures sims from the command line: Batch runs a
+grid of jobs of a sim as separate processes and collates their run logs,
+and SimConfig sets the command-line flags of a sim from a TOML, YAML or JSON
+config file.
+*/
+package batch
@@ -0,0 +1,141 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package batch
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/goki/gi/gi"
+	"gopkg.in/yaml.v2"
+)
+
+// SimConfig is the configuration of a run of a sim from a file, as the
+// values of its command-line flags by name (without the -), e.g., in TOML:
+// runs = 5, sleep = true, pats = "summer_5x5_25.dat", epclog = false --
+// lists are joined with , as for the flags that take lists.  SetFlags sets
+// them on the flags before the command line is parsed, so that the flags
+// given on the command line override them.
+type SimConfig map[string]interface{}
+
+// OpenSimConfig opens a SimConfig from a TOML (.toml), YAML (.yaml, .yml)
+// or JSON file
+func OpenSimConfig(filename gi.FileName) (SimConfig, error) {
+	fnm := string(filename)
+	sc := SimConfig{}
+	var err error
+	switch strings.ToLower(filepath.Ext(fnm)) {
+	case ".toml":
+		_, err = toml.DecodeFile(fnm, &sc)
+	default:
+		var b []byte
+		b, err = ioutil.ReadFile(fnm)
+		if err != nil {
+			break
+		}
+		switch strings.ToLower(filepath.Ext(fnm)) {
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(b, &sc)
+		default:
+			err = json.Unmarshal(b, &sc)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("SimConfig: %v: %v", fnm, err)
+		log.Println(err)
+		return nil, err
+	}
+	return sc, nil
+}
+
+// Names returns the names of the flags of the config, sorted
+func (sc SimConfig) Names() []string {
+	nms := make([]string, 0, len(sc))
+	for nm := range sc {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	return nms
+}
+
+// Val returns the value of the flag with given name as a string, as given
+// on the command line -- an error if it is not a value or list of values
+func (sc SimConfig) Val(name string) (string, error) {
+	switch v := sc[name].(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool, int, int64:
+		return fmt.Sprint(v), nil
+	case []interface{}:
+		strs := make([]string, len(v))
+		for i, it := range v {
+			switch it.(type) {
+			case []interface{}, map[string]interface{}, map[interface{}]interface{}:
+				return "", fmt.Errorf("SimConfig: %v: list items must be values", name)
+			}
+			strs[i] = fmt.Sprint(it)
+		}
+		return strings.Join(strs, ","), nil
+	}
+	return "", fmt.Errorf("SimConfig: %v: value must be a string, number, bool or list, not: %T", name, sc[name])
+}
+
+// SetFlags sets the flags of given flag set to the values of the config --
+// call before parsing the command line, so that its flags override them --
+// an error for any flags not in the flag set, or values not valid for them
+func (sc SimConfig) SetFlags(fs *flag.FlagSet) error {
+	var errs []string
+	for _, nm := range sc.Names() {
+		if fs.Lookup(nm) == nil {
+			errs = append(errs, fmt.Sprintf("%v: not a flag of: %v", nm, fs.Name()))
+			continue
+		}
+		val, err := sc.Val(nm)
+		if err == nil {
+			err = fs.Set(nm, val)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", nm, err))
+		}
+	}
+	if len(errs) > 0 {
+		err := fmt.Errorf("SimConfig: invalid flags:\n%v", strings.Join(errs, "\n"))
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// ConfigFlag returns the value of the flag with given name (e.g., config)
+// in given command-line args, as -name val or -name=val (or with --), or
+// "" if not given, e.g., to open a SimConfig before parsing the args
+func ConfigFlag(args []string, name string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		arg = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if arg == name && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(arg, name+"=") {
+			return arg[len(name)+1:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package batch
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goki/gi/gi"
+)
+
+func TestSimConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"cfg.toml": "runs = 3\nsleep = false\npats = \"my_pats.dat\"\ntestlist = [\"lures\", \"subset\"]\nrate = 0.25\n",
+		"cfg.yaml": "runs: 3\nsleep: false\npats: my_pats.dat\ntestlist: [lures, subset]\nrate: 0.25\n",
+		"cfg.json": `{"runs": 3, "sleep": false, "pats": "my_pats.dat", "testlist": ["lures", "subset"], "rate": 0.25}`,
+	}
+	for fn, src := range files {
+		fnm := filepath.Join(dir, fn)
+		if err := ioutil.WriteFile(fnm, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		sc, err := OpenSimConfig(gi.FileName(fnm))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fs := flag.NewFlagSet("train", flag.ContinueOnError)
+		runs := fs.Int("runs", 10, "")
+		sleep := fs.Bool("sleep", true, "")
+		pats := fs.String("pats", "summer_5x5_25.dat", "")
+		testlist := fs.String("testlist", "", "")
+		rate := fs.Float64("rate", 0, "")
+		fs.String("config", "", "")
+		if err := sc.SetFlags(fs); err != nil {
+			t.Fatalf("%v: %v\n", fn, err)
+		}
+		// command line overrides the config
+		if err := fs.Parse([]string{"-config", fnm, "-runs", "5"}); err != nil {
+			t.Fatal(err)
+		}
+		if *runs != 5 || *sleep || *pats != "my_pats.dat" || *testlist != "lures,subset" || *rate != 0.25 {
+			t.Errorf("%v: flags: runs %v sleep %v pats %v testlist %v rate %v\n", fn, *runs, *sleep, *pats, *testlist, *rate)
+		}
+	}
+	sc := SimConfig{"nosuch": 1}
+	if err := sc.SetFlags(flag.NewFlagSet("train", flag.ContinueOnError)); err == nil {
+		t.Errorf("expected error for an unknown flag\n")
+	}
+	if cfg := ConfigFlag([]string{"-tag", "config", "--config=a.toml"}, "config"); cfg != "a.toml" {
+		t.Errorf("ConfigFlag: %v, want a.toml\n", cfg)
+	}
+	if cfg := ConfigFlag([]string{"-config", "b.yaml", "-runs", "2"}, "config"); cfg != "b.yaml" {
+		t.Errorf("ConfigFlag: %v, want b.yaml\n", cfg)
+	}
+}
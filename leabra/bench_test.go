@@ -0,0 +1,135 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+)
+
+// BenchNet is a larger network for benchmarking, configured by ConfigBenchNet
+var BenchNet Network
+
+// ConfigBenchNet configures BenchNet with three fully-connected layers of
+// given size (n x n), with random input activations
+func ConfigBenchNet(n int) {
+	if BenchNet.Nm != "" {
+		return
+	}
+	BenchNet.InitName(&BenchNet, "BenchNet")
+	inLay := BenchNet.AddLayer2D("Input", n, n, emer.Input)
+	hidLay := BenchNet.AddLayer2D("Hidden", n, n, emer.Hidden)
+	outLay := BenchNet.AddLayer2D("Output", n, n, emer.Target)
+	BenchNet.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	BenchNet.ConnectLayers(hidLay, outLay, prjn.NewFull(), emer.Forward)
+	BenchNet.ConnectLayers(outLay, hidLay, prjn.NewFull(), emer.Back)
+	BenchNet.Defaults()
+	BenchNet.Build()
+	BenchNet.InitWts()
+	rand.Seed(1)
+	for _, ly := range BenchNet.Layers {
+		lly := ly.(*Layer)
+		for ni := range lly.Neurons {
+			lly.Neurons[ni].Act = rand.Float32()
+		}
+	}
+}
+
+func benchSendGDelta(b *testing.B, sleep bool) {
+	ConfigBenchNet(25)
+	ltime := NewTime()
+	hidLay := BenchNet.LayerByName("Hidden").(*Layer)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for ni := range hidLay.Neurons {
+			hidLay.Neurons[ni].ActSent = 0 // force sending every time
+		}
+		hidLay.SendGDelta(ltime, sleep)
+	}
+}
+
+func BenchmarkSendGDelta(b *testing.B) {
+	benchSendGDelta(b, false)
+}
+
+func BenchmarkSendGDeltaSleep(b *testing.B) {
+	benchSendGDelta(b, true)
+}
+
+func BenchmarkRecvGInc(b *testing.B) {
+	ConfigBenchNet(25)
+	hidLay := BenchNet.LayerByName("Hidden").(*Layer)
+	pj := hidLay.RcvPrjns.SendName("Input").(*Prjn)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pj.RecvGInc()
+	}
+}
+
+func BenchmarkCycle(b *testing.B) {
+	ConfigBenchNet(25)
+	ltime := NewTime()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BenchNet.Cycle(ltime, false)
+	}
+}
+
+func BenchmarkCycleSleep(b *testing.B) {
+	ConfigBenchNet(25)
+	ltime := NewTime()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BenchNet.Cycle(ltime, true)
+	}
+}
+
+func BenchmarkCalLaySim(b *testing.B) {
+	ConfigBenchNet(25)
+	ltime := NewTime()
+	hidLay := BenchNet.LayerByName("Hidden").(*Layer)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hidLay.CalLaySim(ltime)
+	}
+}
+
+func BenchmarkCalSynDep(b *testing.B) {
+	ConfigBenchNet(25)
+	ltime := NewTime()
+	hidLay := BenchNet.LayerByName("Hidden").(*Layer)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hidLay.CalSynDep(ltime)
+	}
+}
+
+// TestLayerCycleAllocs checks that the per-cycle layer methods do not
+// allocate once their buffers have been sized
+func TestLayerCycleAllocs(t *testing.T) {
+	ConfigBenchNet(25)
+	ltime := NewTime()
+	hidLay := BenchNet.LayerByName("Hidden").(*Layer)
+	funs := map[string]func(){
+		"CalLaySim":  func() { hidLay.CalLaySim(ltime) },
+		"CalSynDep":  func() { hidLay.CalSynDep(ltime) },
+		"SendGDelta": func() { hidLay.SendGDelta(ltime, true) },
+		"GFmInc":     func() { hidLay.GFmInc(ltime) },
+		"ActFmG":     func() { hidLay.ActFmG(ltime) },
+	}
+	for nm, fun := range funs {
+		fun() // size buffers
+		if n := testing.AllocsPerRun(10, fun); n > 0 {
+			t.Errorf("%v: %v allocations per call, want 0\n", nm, n)
+		}
+	}
+}
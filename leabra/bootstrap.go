@@ -0,0 +1,140 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// Quantile returns the q quantile (0-1) of vals, interpolating linearly
+// between the closest ranks -- NaN if there are no values
+func Quantile(vals []float64, q float64) float64 {
+	n := len(vals)
+	if n == 0 {
+		return math.NaN()
+	}
+	sv := make([]float64, n)
+	copy(sv, vals)
+	sort.Float64s(sv)
+	return sortedQuantile(sv, q)
+}
+
+// sortedQuantile returns the q quantile of sorted values
+func sortedQuantile(sv []float64, q float64) float64 {
+	pos := q * float64(len(sv)-1)
+	lo := int(math.Floor(pos))
+	if lo >= len(sv)-1 {
+		return sv[len(sv)-1]
+	}
+	if lo < 0 {
+		return sv[0]
+	}
+	fr := pos - float64(lo)
+	return sv[lo] + fr*(sv[lo+1]-sv[lo])
+}
+
+// Median returns the median of vals -- NaN if there are no values
+func Median(vals []float64) float64 {
+	return Quantile(vals, 0.5)
+}
+
+// BootstrapCI returns the lower and upper bounds of the percentile bootstrap
+// confidence interval of the mean of vals with given confidence level (e.g.,
+// .95), from nboot resamples drawn with given random number stream (global
+// generator if nil) -- NaN if there are fewer than 2 values.  Unlike the t
+// interval (see TCI), it does not assume normality, e.g., for FirstZero over
+// a small number of runs.
+func BootstrapCI(vals []float64, nboot int, level float64, rnd *rand.Rand) (lo, hi float64) {
+	n := len(vals)
+	if n < 2 || nboot < 1 {
+		return math.NaN(), math.NaN()
+	}
+	intn := rand.Intn
+	if rnd != nil {
+		intn = rnd.Intn
+	}
+	means := make([]float64, nboot)
+	for b := range means {
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			sum += vals[intn(n)]
+		}
+		means[b] = sum / float64(n)
+	}
+	sort.Float64s(means)
+	alpha := (1 - level) / 2
+	return sortedQuantile(means, alpha), sortedQuantile(means, 1-alpha)
+}
+
+// AddBootStats returns a copy of st, a table of stats with one row per group
+// of the rows of dt (e.g., the RunStats, grouped by Params), with columns
+// added for the median and the bootstrap confidence interval of the mean
+// (see BootstrapCI) of each of cols in dt, over the rows of each group:
+// Col:Median, Col:BootLo, Col:BootHi.  The group of each row is the value of
+// groupCol, which must be in both tables.
+func AddBootStats(st, dt *etable.Table, groupCol string, cols []string, nboot int, level float64, rnd *rand.Rand) (*etable.Table, error) {
+	if st.ColByName(groupCol) == nil || dt.ColByName(groupCol) == nil {
+		err := fmt.Errorf("AddBootStats: group column: %v not found in both tables", groupCol)
+		log.Println(err)
+		return nil, err
+	}
+	for _, cl := range cols {
+		if dt.ColByName(cl) == nil {
+			err := fmt.Errorf("AddBootStats: column: %v not found in table: %v", cl, dt.MetaData["name"])
+			log.Println(err)
+			return nil, err
+		}
+	}
+	sch := etable.Schema{}
+	for ci, col := range st.Cols {
+		sch = append(sch, etable.Column{st.ColNames[ci], col.DataType(), nil, nil})
+	}
+	for _, cl := range cols {
+		for _, sfx := range []string{"Median", "BootLo", "BootHi"} {
+			sch = append(sch, etable.Column{cl + ":" + sfx, etensor.FLOAT64, nil, nil})
+		}
+	}
+	bt := &etable.Table{}
+	bt.SetFromSchema(sch, st.Rows)
+	for k, v := range st.MetaData {
+		bt.SetMetaData(k, v)
+	}
+	for ci, col := range st.Cols {
+		nm := st.ColNames[ci]
+		for row := 0; row < st.Rows; row++ {
+			if col.DataType() == etensor.STRING {
+				bt.SetCellString(nm, row, st.CellString(nm, row))
+			} else {
+				bt.SetCellFloat(nm, row, st.CellFloat(nm, row))
+			}
+		}
+	}
+	for row := 0; row < st.Rows; row++ {
+		grp := st.CellString(groupCol, row)
+		for _, cl := range cols {
+			var vals []float64
+			for dr := 0; dr < dt.Rows; dr++ {
+				if dt.CellString(groupCol, dr) != grp {
+					continue
+				}
+				if v := dt.CellFloat(cl, dr); !math.IsNaN(v) {
+					vals = append(vals, v)
+				}
+			}
+			lo, hi := BootstrapCI(vals, nboot, level, rnd)
+			bt.SetCellFloat(cl+":Median", row, Median(vals))
+			bt.SetCellFloat(cl+":BootLo", row, lo)
+			bt.SetCellFloat(cl+":BootHi", row, hi)
+		}
+	}
+	return bt, nil
+}
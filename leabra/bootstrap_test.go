@@ -0,0 +1,65 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestBootstrap(t *testing.T) {
+	if m := Median([]float64{4, 1, 3, 2}); m != 2.5 {
+		t.Errorf("median: %v, want 2.5\n", m)
+	}
+	if q := Quantile([]float64{5, 4, 3, 2, 1}, 0.25); q != 2 {
+		t.Errorf("quantile: %v, want 2\n", q)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	vals := []float64{5, 7, 8, 10, 12, 6, 9, 11, 30, 8}
+	lo, hi := BootstrapCI(vals, 1000, 0.95, rnd)
+	if !(lo > 5 && lo < 10.6 && hi > 10.6 && hi < 30) {
+		t.Errorf("bootstrap CI: %v - %v should contain the mean 10.6\n", lo, hi)
+	}
+	if lo, hi := BootstrapCI([]float64{3, 3, 3}, 100, 0.95, rnd); lo != 3 || hi != 3 {
+		t.Errorf("constant CI: %v - %v, want 3 - 3\n", lo, hi)
+	}
+
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Params", etensor.STRING, nil, nil},
+		{"FirstZero", etensor.FLOAT64, nil, nil},
+	}, 5)
+	for row, fz := range []float64{4, 10, 6, 20, 5} {
+		dt.SetCellString("Params", row, []string{"A", "B"}[row%2])
+		dt.SetCellFloat("FirstZero", row, fz)
+	}
+	st := &etable.Table{}
+	st.SetFromSchema(etable.Schema{
+		{"Params", etensor.STRING, nil, nil},
+		{"FirstZero:Mean", etensor.FLOAT64, nil, nil},
+	}, 2)
+	st.SetCellString("Params", 0, "A")
+	st.SetCellString("Params", 1, "B")
+	st.SetCellFloat("FirstZero:Mean", 1, 15)
+	bt, err := AddBootStats(st, dt, "Params", []string{"FirstZero"}, 200, 0.95, rnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bt.CellFloat("FirstZero:Mean", 1) != 15 || bt.CellString("Params", 1) != "B" {
+		t.Errorf("stats not copied\n")
+	}
+	if m := bt.CellFloat("FirstZero:Median", 0); m != 5 {
+		t.Errorf("A median: %v, want 5\n", m)
+	}
+	if lo, hi := bt.CellFloat("FirstZero:BootLo", 1), bt.CellFloat("FirstZero:BootHi", 1); lo < 10 || hi > 20 || lo > hi {
+		t.Errorf("B CI: %v - %v\n", lo, hi)
+	}
+	if _, err := AddBootStats(st, dt, "Params", []string{"PctCor"}, 200, 0.95, rnd); err == nil {
+		t.Errorf("expected error for missing column\n")
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/emer/etable/agg"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// CatStat is one statistic of CatStats, computed over the trials of each
+// category
+type CatStat struct {
+	Name string                           `desc:"name of the stat, the prefix of its columns in the epoch log: Name:Tag"`
+	Col  string                           `desc:"column of the trial log that is aggregated"`
+	Agg  agg.Aggs                         `desc:"aggregation of the Col values over the trials of each category"`
+	Fun  func(ix *etable.IdxView) float64 `view:"-" desc:"if set, computes the stat from the trials of each category, instead of Col and Agg"`
+}
+
+// CatStats computes epoch-level statistics from a trial log separately for
+// each category of items (e.g., emotional vs. neutral, or old vs. new), as
+// given by a category tag for each item, usually from a column of the
+// patterns.  The stats go in columns named Stat:Tag of the epoch log.
+type CatStats struct {
+	Stats    []CatStat         `desc:"stats computed for each category"`
+	Tags     []string          `inactive:"+" desc:"the categories, sorted"`
+	ItemTags map[string]string `inactive:"+" desc:"category tag of each item, by name"`
+}
+
+// SetTags sets the category tag of each item from given table of patterns,
+// with the name of each item in nameCol and its tag in string column tagCol
+// -- if the table has no tagCol, tagFun (if non-nil) returns the tag of each row
+func (cs *CatStats) SetTags(dt *etable.Table, nameCol, tagCol string, tagFun func(row int) string) error {
+	nc := dt.ColByName(nameCol)
+	tc := dt.ColByName(tagCol)
+	if nc == nil || (tc == nil && tagFun == nil) {
+		err := fmt.Errorf("CatStats SetTags: columns: %v, %v not found in table: %v", nameCol, tagCol, dt.MetaData["name"])
+		log.Println(err)
+		return err
+	}
+	cs.ItemTags = make(map[string]string, dt.Rows)
+	tags := map[string]bool{}
+	for row := 0; row < dt.Rows; row++ {
+		var tag string
+		if tc != nil {
+			tag = tc.StringVal1D(row)
+		} else {
+			tag = tagFun(row)
+		}
+		cs.ItemTags[nc.StringVal1D(row)] = tag
+		tags[tag] = true
+	}
+	cs.Tags = make([]string, 0, len(tags))
+	for tag := range tags {
+		cs.Tags = append(cs.Tags, tag)
+	}
+	sort.Strings(cs.Tags)
+	return nil
+}
+
+// Tag returns the category tag of the item with given name ("" if unknown)
+func (cs *CatStats) Tag(name string) string {
+	return cs.ItemTags[name]
+}
+
+// ColName returns the name of the epoch log column for given stat and tag
+func (cs *CatStats) ColName(stat, tag string) string {
+	return stat + ":" + tag
+}
+
+// Schema returns the columns of the epoch log for all the stats and tags
+func (cs *CatStats) Schema() etable.Schema {
+	sch := etable.Schema{}
+	for _, st := range cs.Stats {
+		for _, tag := range cs.Tags {
+			sch = append(sch, etable.Column{cs.ColName(st.Name, tag), etensor.FLOAT64, nil, nil})
+		}
+	}
+	return sch
+}
+
+// Log computes the stats for each category from the trials of given trial
+// log, whose category is in string column tagCol, and sets them in given row
+// of the epoch log -- categories without trials get 0
+func (cs *CatStats) Log(epc *etable.Table, row int, trl *etable.Table, tagCol string) error {
+	if trl.ColByName(tagCol) == nil {
+		err := fmt.Errorf("CatStats Log: column: %v not found in table: %v", tagCol, trl.MetaData["name"])
+		log.Println(err)
+		return err
+	}
+	for _, tag := range cs.Tags {
+		ix := cs.TagView(trl, tagCol, tag)
+		for si := range cs.Stats {
+			epc.SetCellFloat(cs.ColName(cs.Stats[si].Name, tag), row, cs.Stats[si].Val(ix))
+		}
+	}
+	return nil
+}
+
+// AddLogItems adds items for all the stats and tags to given log, for given
+// log mode and time scale, e.g., Test, Epoch, computed from the trials of the
+// log of the same mode at time scale from, e.g., Trial, whose category is in
+// string column tagCol -- the items of Log, computed as the rows are logged.
+func (cs *CatStats) AddLogItems(lg *Logs, mode string, tm TimeScales, from TimeScales, tagCol string) []*LogItem {
+	var its []*LogItem
+	for _, tag := range cs.Tags {
+		tag := tag
+		for si := range cs.Stats {
+			st := &cs.Stats[si]
+			its = append(its, lg.AddFun(mode, tm, cs.ColName(st.Name, tag), func(lg *Logs, row int) float64 {
+				trl := lg.Table(mode, from)
+				if trl == nil || trl.ColByName(tagCol) == nil {
+					return 0
+				}
+				return st.Val(cs.TagView(trl, tagCol, tag))
+			}))
+		}
+	}
+	return its
+}
+
+// TagView returns a view of the trials of given trial log whose category,
+// in string column tagCol, is given tag
+func (cs *CatStats) TagView(trl *etable.Table, tagCol, tag string) *etable.IdxView {
+	ix := etable.NewIdxView(trl)
+	ix.Filter(func(et *etable.Table, row int) bool {
+		return et.CellString(tagCol, row) == tag
+	})
+	return ix
+}
+
+// Val returns the value of the stat over given trials -- 0 if none
+func (st *CatStat) Val(ix *etable.IdxView) float64 {
+	switch {
+	case ix.Len() == 0:
+		return 0
+	case st.Fun != nil:
+		return st.Fun(ix)
+	}
+	return agg.Agg(ix, st.Col, st.Agg)[0]
+}
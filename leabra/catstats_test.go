@@ -0,0 +1,75 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/etable/agg"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestCatStats(t *testing.T) {
+	pats := &etable.Table{}
+	pats.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+	}, 3)
+	names := []string{"a", "b", "c"}
+	for row, nm := range names {
+		pats.SetCellString("Name", row, nm)
+	}
+	cs := &CatStats{}
+	if err := cs.SetTags(pats, "Name", "Cat", nil); err == nil {
+		t.Errorf("expected error with no tag column or function\n")
+	}
+	cs.SetTags(pats, "Name", "Cat", func(row int) string {
+		if row == 2 {
+			return "New"
+		}
+		return "Old"
+	})
+	if len(cs.Tags) != 2 || cs.Tags[0] != "New" || cs.Tag("b") != "Old" {
+		t.Fatalf("tags wrong: %v\n", cs.Tags)
+	}
+	cs.Stats = []CatStat{
+		{Name: "SSE", Col: "SSE", Agg: agg.AggMean},
+		{Name: "N", Fun: func(ix *etable.IdxView) float64 { return float64(ix.Len()) }},
+	}
+
+	trl := &etable.Table{}
+	trl.SetFromSchema(etable.Schema{
+		{"Cat", etensor.STRING, nil, nil},
+		{"SSE", etensor.FLOAT64, nil, nil},
+	}, 3)
+	for row, nm := range names {
+		trl.SetCellString("Cat", row, cs.Tag(nm))
+		trl.SetCellFloat("SSE", row, float64(row))
+	}
+	epc := &etable.Table{}
+	epc.SetFromSchema(cs.Schema(), 1)
+	if err := cs.Log(epc, 0, trl, "Cat"); err != nil {
+		t.Fatal(err)
+	}
+	if v := epc.CellFloat("SSE:Old", 0); v != 0.5 {
+		t.Errorf("SSE:Old = %v, want .5\n", v)
+	}
+	if v := epc.CellFloat("N:New", 0); v != 1 {
+		t.Errorf("N:New = %v, want 1\n", v)
+	}
+
+	// same stats as items of Logs, from the Test Trial log
+	lg := &Logs{Tables: map[LogKey]*etable.Table{{"Test", Trial}: trl}}
+	if its := cs.AddLogItems(lg, "Test", Epoch, Trial, "Cat"); len(its) != 4 {
+		t.Fatalf("got %v items, want 4\n", len(its))
+	}
+	lg.Config(nil)
+	lg.Log("Test", Epoch)
+	for _, col := range []string{"SSE:Old", "SSE:New", "N:Old", "N:New"} {
+		if v := lg.Table("Test", Epoch).CellFloat(col, 0); v != epc.CellFloat(col, 0) {
+			t.Errorf("%v item: %v, want %v\n", col, v, epc.CellFloat(col, 0))
+		}
+	}
+}
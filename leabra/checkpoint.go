@@ -0,0 +1,352 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/emer/emergent/env"
+	"github.com/goki/gi/gi"
+)
+
+// CheckpointVersion is the current version of the Checkpoint format
+const CheckpointVersion = 1
+
+// Checkpoint captures the complete training state of a simulation, so that a
+// long run can be resumed exactly where it left off: the full network state
+// (weights and all other synaptic state, neuron state including running
+// averages, pool state), the Time counters, the state of the per-layer and
+// per-projection random number streams, and the state of any number of environments, plus arbitrary named sim-level
+// values.  Parameters are not saved -- they are re-applied from the param sets
+// as usual.
+//
+// Saving a checkpoint does not change any random number state, so a run that
+// saves checkpoints is the same as one that does not.  The streams of the
+// network (see SeedLayerRnd, SeedPrjnRnd), and those of a Seeds recorded
+// with AddSeeds, are RndSources, saved as their seed and number of draws,
+// and restored exactly.  The global random number
+// generator (math/rand) state cannot be saved: if the sim reseeds it at known
+// points (e.g., at the start of each epoch), it sets RndSeed to the last seed,
+// which Restore reseeds the generator with -- exact only if there were no
+// draws from the global generator since that seed.
+//
+// The state of each environment includes its view of the table (e.g., a
+// train / test split or subsample) and permutation order, and with
+// env.AddSeqEnv its sequence state, and the contents of a ReplayBuf can be
+// saved with AddReplayBuf, so a resumed run sees exactly the same trials.
+type Checkpoint struct {
+	Version  int                  `desc:"version of the checkpoint format"`
+	Net      NetState             `desc:"full network state"`
+	Time     Time                 `desc:"timing state"`
+	RndSeed  int64                `desc:"if non-zero, seed that Restore reseeds the global random number generator with -- set by the sim to the seed it last reseeded the generator with"`
+	Rnds     map[string]RndState  `desc:"state of the random number streams of the network (see Network.RndStates), and of the Seeds streams if saved with AddSeeds"`
+	SeedsRun int                  `desc:"run of the Seeds, if saved with AddSeeds"`
+	Envs     map[string]*EnvState `desc:"state of environments, by name"`
+	Vals     map[string]float64   `desc:"arbitrary named sim-level values (e.g., stats accumulators)"`
+	Strs     map[string]string    `desc:"arbitrary named sim-level strings"`
+	Replay   []ReplayItem         `desc:"contents of the replay buffer, if saved with AddReplayBuf"`
+	RepNext  int                  `desc:"index of the next item of the replay buffer to be replaced once full"`
+}
+
+// NetState is the complete learning and activation state of a network
+type NetState struct {
+	Name     string       `desc:"name of network"`
+	WtBalCtr int          `desc:"weight balance counter"`
+	Layers   []LayerState `desc:"state for each layer"`
+}
+
+// LayerState is the complete state of a layer, including its receiving projections
+type LayerState struct {
+	Name    string       `desc:"name of layer"`
+	Neurons []Neuron     `desc:"neuron state"`
+	Pools   []Pool       `desc:"pool state"`
+	CosDiff CosDiffStats `desc:"cosine difference stats"`
+	Sim     float64      `desc:"layer similarity"`
+	Prjns   []PrjnState  `desc:"state of the receiving projections"`
+}
+
+// PrjnState is the complete connectivity and synaptic state of a projection
+type PrjnState struct {
+	Name      string          `desc:"name of projection"`
+	RConN     []int32         `desc:"recv con n"`
+	RConIdxSt []int64         `desc:"recv con idx start"`
+	RConIdx   []int32         `desc:"recv con idx"`
+	RSynIdx   []int64         `desc:"recv syn idx"`
+	SConN     []int32         `desc:"send con n"`
+	SConIdxSt []int64         `desc:"send con idx start"`
+	SConIdx   []int32         `desc:"send con idx"`
+	Syns      []Synapse       `desc:"synapse state, in one list -- only in checkpoints saved before SynChunks"`
+	SynChunks [][]Synapse     `desc:"synapse state, in the chunks of Synapses"`
+	GScale    float32         `desc:"conductance scaling"`
+	GInc      []float32       `desc:"conductance increments"`
+	WbRecv    []WtBalRecvPrjn `desc:"weight balance state"`
+}
+
+// EnvState is the counter and order state of an environment
+type EnvState struct {
+	Run       env.Ctr `desc:"run counter"`
+	Epoch     env.Ctr `desc:"epoch counter"`
+	Trial     env.Ctr `desc:"trial counter"`
+	Order     []int   `desc:"order of trials"`
+	TrialName string  `desc:"current trial name"`
+	Idxs      []int   `desc:"rows of the table in the view of the environment, e.g., a split or subsample of the patterns"`
+	Seq       env.Ctr `desc:"sequence counter (env.SeqEnv only)"`
+	SeqName   string  `desc:"name of the current sequence (env.SeqEnv only)"`
+	SeqTrial  int     `desc:"position of the current trial within its sequence (env.SeqEnv only)"`
+	SeqStart  bool    `desc:"current trial is the first of its sequence (env.SeqEnv only)"`
+	SeqEnd    bool    `desc:"current trial is the last of its sequence (env.SeqEnv only)"`
+	NPrv      int     `desc:"number of trials in the previous epoch (env.SeqEnv only)"`
+}
+
+// NewCheckpoint returns a new checkpoint capturing the current state of the
+// network and time, and the state of its random number streams -- without
+// changing any random number state.
+// Use AddFixedTable, Vals and Strs to record additional state.
+func NewCheckpoint(nt *Network, ltime *Time) *Checkpoint {
+	cp := &Checkpoint{Version: CheckpointVersion}
+	cp.Net = nt.State()
+	cp.Time = *ltime
+	cp.Rnds = nt.RndStates()
+	cp.Envs = make(map[string]*EnvState)
+	cp.Vals = make(map[string]float64)
+	cp.Strs = make(map[string]string)
+	return cp
+}
+
+// AddFixedTable records the state of given environment, under its name
+func (cp *Checkpoint) AddFixedTable(ft *env.FixedTable) {
+	es := &EnvState{Run: ft.Run, Epoch: ft.Epoch, Trial: ft.Trial, TrialName: ft.TrialName}
+	es.Order = append([]int{}, ft.Order...)
+	es.Idxs = append([]int{}, ft.Table.Idxs...)
+	cp.Envs[ft.Nm] = es
+}
+
+// RestoreFixedTable restores the state of given environment, which must have been
+// recorded with AddFixedTable, with a table of at least the rows of the saved
+// view -- without a saved view (older checkpoints), the environment must have
+// the same number of trials.
+func (cp *Checkpoint) RestoreFixedTable(ft *env.FixedTable) error {
+	es, has := cp.Envs[ft.Nm]
+	if !has {
+		err := fmt.Errorf("Checkpoint RestoreFixedTable: env: %v not found", ft.Nm)
+		log.Println(err)
+		return err
+	}
+	if len(es.Idxs) == 0 && len(es.Order) != len(ft.Order) {
+		err := fmt.Errorf("Checkpoint RestoreFixedTable: env: %v number of trials: %v != saved: %v", ft.Nm, len(ft.Order), len(es.Order))
+		log.Println(err)
+		return err
+	}
+	for _, row := range es.Idxs {
+		if row >= ft.Table.Table.Rows {
+			err := fmt.Errorf("Checkpoint RestoreFixedTable: env: %v saved row: %v is not in its table of %v rows", ft.Nm, row, ft.Table.Table.Rows)
+			log.Println(err)
+			return err
+		}
+	}
+	if len(es.Idxs) > 0 {
+		ft.Table.Idxs = append([]int{}, es.Idxs...)
+	}
+	ft.Run = es.Run
+	ft.Epoch = es.Epoch
+	ft.Trial = es.Trial
+	ft.Order = append(ft.Order[:0], es.Order...)
+	ft.TrialName = es.TrialName
+	return nil
+}
+
+// AddSeeds records the states of the streams of given Seeds, as seeds:
+// and the stream name
+func (cp *Checkpoint) AddSeeds(sd *Seeds) {
+	if cp.Rnds == nil {
+		cp.Rnds = make(map[string]RndState)
+	}
+	for nm, st := range sd.States() {
+		cp.Rnds["seeds:"+nm] = st
+	}
+	cp.SeedsRun = sd.Run
+}
+
+// RestoreSeeds restores the streams of given Seeds, as recorded with
+// AddSeeds, which must have the same Master and Fixed seeds -- any other
+// streams start afresh for the run
+func (cp *Checkpoint) RestoreSeeds(sd *Seeds) {
+	sd.Init(cp.SeedsRun)
+	for nm, st := range cp.Rnds {
+		if strings.HasPrefix(nm, "seeds:") {
+			sd.SetState(strings.TrimPrefix(nm, "seeds:"), st)
+		}
+	}
+}
+
+// AddReplayBuf records the contents of given replay buffer
+func (cp *Checkpoint) AddReplayBuf(rb *ReplayBuf) {
+	cp.Replay = append([]ReplayItem{}, rb.Items...)
+	cp.RepNext = rb.Next
+}
+
+// RestoreReplayBuf restores the contents of given replay buffer, as
+// recorded with AddReplayBuf
+func (cp *Checkpoint) RestoreReplayBuf(rb *ReplayBuf) {
+	rb.Items = append([]ReplayItem{}, cp.Replay...)
+	rb.Next = cp.RepNext
+	rb.Seeded = ""
+}
+
+// Restore restores the network and time state from the checkpoint, and the
+// random number streams of the network, and reseeds the global random number
+// generator with RndSeed if set.  The network must have the same structure
+// as the one that was saved.
+func (cp *Checkpoint) Restore(nt *Network, ltime *Time) error {
+	if err := nt.SetState(&cp.Net); err != nil {
+		return err
+	}
+	*ltime = cp.Time
+	nt.SetRndStates(cp.Rnds)
+	if cp.RndSeed != 0 {
+		rand.Seed(cp.RndSeed)
+	}
+	return nil
+}
+
+// Write writes the checkpoint to given writer in gob format
+func (cp *Checkpoint) Write(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(cp)
+}
+
+// Read reads the checkpoint from given reader in gob format
+func (cp *Checkpoint) Read(r io.Reader) error {
+	err := gob.NewDecoder(r).Decode(cp)
+	if err != nil {
+		return err
+	}
+	if cp.Version != CheckpointVersion {
+		return fmt.Errorf("Checkpoint Read: version: %v is not the current version: %v", cp.Version, CheckpointVersion)
+	}
+	return nil
+}
+
+// Save saves the checkpoint to given file (typically .ckpt extension)
+func (cp *Checkpoint) Save(filename gi.FileName) error {
+	fp, err := os.Create(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = cp.Write(fp)
+	if cerr := fp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// OpenCheckpoint opens a checkpoint from given file
+func OpenCheckpoint(filename gi.FileName) (*Checkpoint, error) {
+	fp, err := os.Open(string(filename))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer fp.Close()
+	cp := &Checkpoint{}
+	err = cp.Read(fp)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return cp, nil
+}
+
+// State returns the complete learning and activation state of the network
+func (nt *Network) State() NetState {
+	ns := NetState{Name: nt.Nm, WtBalCtr: nt.WtBalCtr}
+	for _, l := range nt.Layers {
+		ly := l.(LeabraLayer).AsLeabra()
+		ls := LayerState{Name: ly.Nm, CosDiff: ly.CosDiff, Sim: ly.Sim}
+		ls.Neurons = append([]Neuron{}, ly.Neurons...)
+		ls.Pools = append([]Pool{}, ly.Pools...)
+		for _, p := range ly.RcvPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			ps := PrjnState{Name: pj.Name(), GScale: pj.GScale}
+			ps.RConN = append([]int32{}, pj.RConN...)
+			ps.RConIdxSt = append([]int64{}, pj.RConIdxSt...)
+			ps.RConIdx = append([]int32{}, pj.RConIdx...)
+			ps.RSynIdx = append([]int64{}, pj.RSynIdx...)
+			ps.SConN = append([]int32{}, pj.SConN...)
+			ps.SConIdxSt = append([]int64{}, pj.SConIdxSt...)
+			ps.SConIdx = append([]int32{}, pj.SConIdx...)
+			for _, syns := range pj.Syns.Chunks {
+				ps.SynChunks = append(ps.SynChunks, append([]Synapse{}, syns...))
+			}
+			ps.GInc = append([]float32{}, pj.GInc...)
+			ps.WbRecv = append([]WtBalRecvPrjn{}, pj.WbRecv...)
+			ls.Prjns = append(ls.Prjns, ps)
+		}
+		ns.Layers = append(ns.Layers, ls)
+	}
+	return ns
+}
+
+// SetState sets the complete learning and activation state of the network from
+// given state, which must have been obtained from a network of the same structure.
+func (nt *Network) SetState(ns *NetState) error {
+	if len(ns.Layers) != len(nt.Layers) {
+		err := fmt.Errorf("Network SetState: %v number of layers: %v != state: %v", nt.Nm, len(nt.Layers), len(ns.Layers))
+		log.Println(err)
+		return err
+	}
+	for li, l := range nt.Layers {
+		ly := l.(LeabraLayer).AsLeabra()
+		ls := &ns.Layers[li]
+		if ls.Name != ly.Nm || len(ls.Neurons) != len(ly.Neurons) || len(ls.Prjns) != len(ly.RcvPrjns) {
+			err := fmt.Errorf("Network SetState: %v layer: %v does not match state layer: %v", nt.Nm, ly.Nm, ls.Name)
+			log.Println(err)
+			return err
+		}
+	}
+	nt.WtBalCtr = ns.WtBalCtr
+	for li, l := range nt.Layers {
+		ly := l.(LeabraLayer).AsLeabra()
+		ls := &ns.Layers[li]
+		copy(ly.Neurons, ls.Neurons)
+		ly.Pools = append(ly.Pools[:0], ls.Pools...)
+		ly.CosDiff = ls.CosDiff
+		ly.Sim = ls.Sim
+		for pi, p := range ly.RcvPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			ps := &ls.Prjns[pi]
+			pj.RConN = append(pj.RConN[:0], ps.RConN...)
+			pj.RConIdxSt = append(pj.RConIdxSt[:0], ps.RConIdxSt...)
+			pj.RConIdx = append(pj.RConIdx[:0], ps.RConIdx...)
+			pj.RSynIdx = append(pj.RSynIdx[:0], ps.RSynIdx...)
+			pj.SConN = append(pj.SConN[:0], ps.SConN...)
+			pj.SConIdxSt = append(pj.SConIdxSt[:0], ps.SConIdxSt...)
+			pj.SConIdx = append(pj.SConIdx[:0], ps.SConIdx...)
+			if ps.SynChunks != nil {
+				pj.Syns.SetChunks(ps.SynChunks)
+			} else {
+				pj.Syns.SetChunks([][]Synapse{ps.Syns})
+			}
+			for _, syns := range pj.Syns.Chunks {
+				for si := range syns {
+					pj.InitSdConstsSyn(&syns[si]) // not exported, so not saved
+				}
+			}
+			pj.GScale = ps.GScale
+			pj.GInc = append(pj.GInc[:0], ps.GInc...)
+			pj.WbRecv = append(pj.WbRecv[:0], ps.WbRecv...)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/env"
+	"github.com/emer/emergent/erand"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+func TestCheckpointEnvs(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, []int{2, 2}, nil},
+	}, 10)
+	for row := 0; row < dt.Rows; row++ {
+		dt.SetCellString("Name", row, fmt.Sprintf("p%d", row))
+	}
+	newEnv := func() *env.FixedTable {
+		ft := &env.FixedTable{}
+		ft.Nm = "TrainEnv"
+		ft.Table = etable.NewIdxView(dt)
+		ft.Table.Idxs = []int{1, 3, 4, 6, 8, 9} // a subsample
+		ft.Init(0)
+		return ft
+	}
+	ft := newEnv()
+	for i := 0; i < 3; i++ {
+		ft.Step()
+	}
+	rb := &ReplayBuf{}
+	rb.Defaults()
+	rb.Items = []ReplayItem{{Name: "p3", Pats: map[string][]float32{"Input": {1, 0, 0, 1}}, Salience: 2}}
+	rb.Next = 1
+
+	cp := &Checkpoint{Version: CheckpointVersion, Envs: make(map[string]*EnvState)}
+	cp.AddFixedTable(ft)
+	cp.AddReplayBuf(rb)
+	var b bytes.Buffer
+	if err := cp.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	rcp := &Checkpoint{}
+	if err := rcp.Read(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	rt := newEnv()
+	rt.Table.Idxs = []int{0, 1, 2, 3, 4, 5} // a different subsample before the restore
+	rt.Init(0)
+	if err := rcp.RestoreFixedTable(rt); err != nil {
+		t.Fatal(err)
+	}
+	if rt.Trial.Cur != ft.Trial.Cur || rt.TrialName != ft.TrialName {
+		t.Errorf("restored env at trial %d %v, want %d %v\n", rt.Trial.Cur, rt.TrialName, ft.Trial.Cur, ft.TrialName)
+	}
+	for ft.Trial.Cur < ft.Trial.Max-1 {
+		ft.Step()
+		rt.Step()
+		if rt.TrialName != ft.TrialName {
+			t.Errorf("restored env trial %d: %v, want %v\n", rt.Trial.Cur, rt.TrialName, ft.TrialName)
+		}
+	}
+
+	rr := &ReplayBuf{}
+	rcp.RestoreReplayBuf(rr)
+	if len(rr.Items) != 1 || rr.Next != 1 || rr.Items[0].Pats["Input"][3] != 1 || rr.Items[0].Salience != 2 {
+		t.Errorf("replay buffer not restored: %+v\n", rr)
+	}
+
+	bad := &env.FixedTable{}
+	bad.Nm = "TrainEnv"
+	bad.Table = etable.NewIdxView(dt)
+	bad.Table.Table = &etable.Table{} // too few rows for the saved view
+	if rcp.RestoreFixedTable(bad) == nil {
+		t.Errorf("expected error for saved rows not in the table\n")
+	}
+}
+
+func TestCheckpointRnd(t *testing.T) {
+	net := ensembleNet()
+	net.SeedLayerRnd(3)
+	hid := net.LayerByName("Hidden").(*Layer)
+	hid.Rnd.Float64()
+
+	rand.Seed(5)
+	want := rand.New(rand.NewSource(5)).Int63()
+	cp := NewCheckpoint(net, &Time{})
+	if got := rand.Int63(); got != want {
+		t.Errorf("NewCheckpoint changed the global generator: %v, want %v\n", got, want)
+	}
+	nxt := hid.Rnd.Float64()
+	ref := rand.New(NewRndSource(LayerRndSeed(3, "Hidden")))
+	ref.Float64()
+	if rv := ref.Float64(); nxt != rv {
+		t.Errorf("NewCheckpoint changed the layer stream: %v, want %v\n", nxt, rv)
+	}
+
+	dir, err := ioutil.TempDir("", "ckpt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fnm := filepath.Join(dir, "net.ckpt")
+	if err := cp.Save(gi.FileName(fnm)); err != nil {
+		t.Fatal(err)
+	}
+	rcp, err := OpenCheckpoint(gi.FileName(fnm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hid.Rnd.Float64() // advance past the checkpoint
+	if err := rcp.Restore(net, &Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if rv := hid.Rnd.Float64(); rv != nxt {
+		t.Errorf("restored layer stream: %v, want %v\n", rv, nxt)
+	}
+	if _, err := OpenCheckpoint(gi.FileName(filepath.Join(dir, "none.ckpt"))); err == nil {
+		t.Errorf("expected error for a missing file\n")
+	}
+}
+
+// resumeNet returns a small network with noise from its layer streams
+func resumeNet(seed int64) *Network {
+	net := &Network{}
+	net.InitName(net, "ResumeNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	hid := hidLay.(*Layer)
+	hid.Act.Noise.Type = GeNoise
+	hid.Act.Noise.Dist = erand.Gaussian
+	hid.Act.Noise.Var = 0.2
+	hid.Act.Noise.Fixed = false
+	net.Build()
+	net.SeedPrjnRnd(seed)
+	net.SeedLayerRnd(seed + 1)
+	net.InitWts()
+	return net
+}
+
+// resumeTrial runs one trial with an input drawn from the augment stream
+// of the Seeds, returning the hidden activations
+func resumeTrial(nt *Network, sd *Seeds) []float32 {
+	inpat := etensor.NewFloat32([]int{2, 2}, nil, nil)
+	inpat.Values[sd.Rand(SeedAugment).Intn(4)] = 1
+	nt.InitExt()
+	nt.LayerByName("Input").(*Layer).ApplyExt(inpat)
+	ltime := NewTime()
+	nt.AlphaCycInit()
+	ltime.AlphaCycStart()
+	for cyc := 0; cyc < ltime.CycPerQtr; cyc++ {
+		nt.Cycle(ltime, false)
+		ltime.CycleInc()
+	}
+	return nt.LayerByName("Hidden").(*Layer).UnitVals("Act")
+}
+
+func TestCheckpointResume(t *testing.T) {
+	net := resumeNet(1)
+	sd := &Seeds{Master: 7}
+	sd.Init(2)
+	for i := 0; i < 2; i++ {
+		resumeTrial(net, sd)
+	}
+	cp := NewCheckpoint(net, &Time{})
+	cp.AddSeeds(sd)
+	var b bytes.Buffer
+	if err := cp.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	var want [][]float32 // uninterrupted run
+	for i := 0; i < 3; i++ {
+		want = append(want, resumeTrial(net, sd))
+	}
+
+	rcp := &Checkpoint{}
+	if err := rcp.Read(&b); err != nil {
+		t.Fatal(err)
+	}
+	rnet := resumeNet(5) // different streams before the restore
+	rsd := &Seeds{Master: 7}
+	rsd.Init(0)
+	if err := rcp.Restore(rnet, &Time{}); err != nil {
+		t.Fatal(err)
+	}
+	rcp.RestoreSeeds(rsd)
+	if rsd.Run != 2 {
+		t.Errorf("restored Seeds run: %v, want 2\n", rsd.Run)
+	}
+	for i := 0; i < 3; i++ {
+		acts := resumeTrial(rnet, rsd)
+		for ni := range acts {
+			if acts[ni] != want[i][ni] {
+				t.Fatalf("resumed trial %d unit %d: Act: %v != uninterrupted Act: %v\n", i, ni, acts[ni], want[i][ni])
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// addClass returns the space-separated class string with given classes
+// added, if not already present
+func addClass(cls string, classes ...string) string {
+	for _, c := range classes {
+		for _, cf := range strings.Fields(c) {
+			if !hasClass(cls, cf) {
+				cls = strings.TrimSpace(cls + " " + cf)
+			}
+		}
+	}
+	return cls
+}
+
+// removeClass returns the space-separated class string without given class
+func removeClass(cls, name string) string {
+	var cs []string
+	for _, c := range strings.Fields(cls) {
+		if c != name {
+			cs = append(cs, c)
+		}
+	}
+	return strings.Join(cs, " ")
+}
+
+// AddClass adds user-defined class tag(s) for params selectors (e.g., .BLA),
+// in addition to the built-in type class (e.g., .Hidden), if not already present
+func (ls *LayerStru) AddClass(classes ...string) {
+	ls.Cls = addClass(ls.Cls, classes...)
+}
+
+// RemoveClass removes given user-defined class tag
+func (ls *LayerStru) RemoveClass(name string) {
+	ls.Cls = removeClass(ls.Cls, name)
+}
+
+// HasClass returns true if the layer has given class, including its type class
+func (ls *LayerStru) HasClass(name string) bool {
+	return hasClass(ls.Class(), name)
+}
+
+// AddClass adds user-defined class tag(s) for params selectors (e.g.,
+// .SleepPlastic), in addition to the built-in type class (e.g., .Back), if
+// not already present
+func (ps *PrjnStru) AddClass(classes ...string) {
+	ps.Cls = addClass(ps.Cls, classes...)
+}
+
+// RemoveClass removes given user-defined class tag
+func (ps *PrjnStru) RemoveClass(name string) {
+	ps.Cls = removeClass(ps.Cls, name)
+}
+
+// HasClass returns true if the projection has given class, including its
+// type class
+func (ps *PrjnStru) HasClass(name string) bool {
+	return hasClass(ps.Class(), name)
+}
+
+// AddLayerClass adds class tag cls to the named layers, for params selectors.
+// Returns an error if any of the layers are not found.
+func (nt *Network) AddLayerClass(cls string, lays ...string) error {
+	var err error
+	for _, lnm := range lays {
+		ly, lerr := nt.LayerByNameTry(lnm)
+		if lerr != nil {
+			err = lerr
+			continue
+		}
+		ly.(LeabraLayer).AsLeabra().AddClass(cls)
+	}
+	return err
+}
+
+// AddPrjnClass adds class tag cls to the named projections (e.g.,
+// "InputToHidden1"), for params selectors.
+// Returns an error if any of the projections are not found.
+func (nt *Network) AddPrjnClass(cls string, prjns ...string) error {
+	var err error
+	for _, pnm := range prjns {
+		pj := nt.PrjnByName(pnm)
+		if pj == nil {
+			err = fmt.Errorf("Network.AddPrjnClass: projection named: %v not found in Network: %v", pnm, nt.Nm)
+			log.Println(err)
+			continue
+		}
+		pj.AddClass(cls)
+	}
+	return err
+}
+
+// AddPrjnClassFunc adds class tag cls to all the projections for which fun
+// returns true, e.g., all the projections from layers in a group, and
+// returns the number tagged
+func (nt *Network) AddPrjnClassFunc(cls string, fun func(pj *Prjn) bool) int {
+	n := 0
+	for _, ly := range nt.Layers {
+		for _, p := range *ly.RecvPrjns() {
+			pj := p.(LeabraPrjn).AsLeabra()
+			if fun(pj) {
+				pj.AddClass(cls)
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// PrjnByName returns the projection with given name (e.g., "InputToHidden1"),
+// or nil if not found
+func (nt *Network) PrjnByName(name string) *Prjn {
+	for _, ly := range nt.Layers {
+		for _, p := range *ly.RecvPrjns() {
+			if p.Name() == name {
+				return p.(LeabraPrjn).AsLeabra()
+			}
+		}
+	}
+	return nil
+}
+
+// ClassNames returns the names of the layers and projections that have
+// given class, e.g., for checking which objects a .Class selector targets
+func (nt *Network) ClassNames(cls string) []string {
+	var nms []string
+	for _, ly := range nt.Layers {
+		if hasClass(ly.Class(), cls) {
+			nms = append(nms, ly.Name())
+		}
+		for _, p := range *ly.RecvPrjns() {
+			if hasClass(p.Class(), cls) {
+				nms = append(nms, p.Name())
+			}
+		}
+	}
+	return nms
+}
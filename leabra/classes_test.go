@@ -0,0 +1,57 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/params"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestClassTags(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "ClassNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	blaLay := net.AddLayer2D("BLA", 2, 2, emer.Input)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.ConnectLayers(blaLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	if err := net.AddLayerClass("Amyg BLA", "BLA"); err != nil {
+		t.Error(err)
+	}
+	if err := net.AddLayerClass("Amyg", "Nope"); err == nil {
+		t.Errorf("expected error for missing layer\n")
+	}
+	bla := blaLay.(*Layer)
+	bla.AddClass("Amyg") // not added twice
+	if bla.Cls != "Amyg BLA" || !bla.HasClass("Input") {
+		t.Errorf("layer classes wrong: %v\n", bla.Class())
+	}
+	n := net.AddPrjnClassFunc("SleepPlastic", func(pj *Prjn) bool {
+		return pj.Send.(LeabraLayer).AsLeabra().HasClass("Amyg")
+	})
+	if n != 1 {
+		t.Errorf("got %v prjns tagged, want 1\n", n)
+	}
+	if nms := net.ClassNames("SleepPlastic"); len(nms) != 1 || nms[0] != "BLAToHidden" {
+		t.Errorf("ClassNames wrong: %v\n", nms)
+	}
+	net.ApplyParams(&params.Sheet{
+		{Sel: ".SleepPlastic", Params: params.Params{"Prjn.Learn.Lrate": "0.5"}},
+	}, false)
+	if pj := net.PrjnByName("BLAToHidden"); pj == nil || pj.Learn.Lrate != 0.5 {
+		t.Errorf("class selector not applied to tagged prjn\n")
+	}
+	if pj := net.PrjnByName("InputToHidden"); pj == nil || pj.Learn.Lrate == 0.5 {
+		t.Errorf("class selector applied to untagged prjn\n")
+	}
+	bla.RemoveClass("Amyg")
+	if bla.Cls != "BLA" {
+		t.Errorf("RemoveClass wrong: %v\n", bla.Cls)
+	}
+}
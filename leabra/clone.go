@@ -0,0 +1,162 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"log"
+	"reflect"
+
+	"github.com/emer/emergent/prjn"
+)
+
+// Clone returns a new, fully independent copy of this network, including all
+// layers, projections, parameters, connectivity, weights and other synaptic state,
+// and neuron / pool state.  The new network is built and has its own threads
+// running, so it can be run in the same process as the original, e.g., to snapshot
+// a network prior to sleep and compare post-sleep weights / behavior against the
+// untouched copy.  Projection patterns are copied (see CopyPat), and the
+// connectivity itself is copied so random patterns are preserved exactly.  The
+// per-layer and per-projection random number streams are copied with their
+// state, as are the past activity of CalLaySim and the NetStats accumulators,
+// so the clone runs in lockstep with the original given the same inputs, and
+// the threading, SynView, and compute backend (a new instance of the same one)
+// are copied too.  Only the leabra.Layer and leabra.Prjn level state is
+// copied, so specialized layer / prjn types with additional state must copy
+// that separately.
+func (nt *Network) Clone(name string) *Network {
+	cn := &Network{}
+	cn.InitName(cn, name)
+	cn.WtsFile = nt.WtsFile
+	cn.WtBalInterval = nt.WtBalInterval
+	cn.WtBalCtr = nt.WtBalCtr
+	cn.Stats.CopyFrom(&nt.Stats)
+	cn.ThrAuto = nt.ThrAuto
+	cn.ThrAutoN = nt.ThrAutoN
+	cn.ThrTuneCycles = nt.ThrTuneCycles
+	cn.ParamsStrict = nt.ParamsStrict
+	cn.ModeParams = append(ParamPairs(nil), nt.ModeParams...)
+	cn.QtrParams = append(ParamQtrs(nil), nt.QtrParams...)
+	if nt.Groups != nil {
+		cn.Groups = make(map[string][]string, len(nt.Groups))
+		for nm, gl := range nt.Groups {
+			cn.Groups[nm] = append([]string{}, gl...)
+		}
+	}
+	for _, ly := range nt.Layers {
+		sl := ly.(LeabraLayer).AsLeabra()
+		shp := append([]int{}, sl.Shp.Shp...)
+		cl := cn.AddLayer(sl.Nm, shp, sl.Typ).(LeabraLayer).AsLeabra()
+		cl.CopyParamsFrom(sl)
+	}
+	for _, ly := range nt.Layers {
+		for _, p := range *ly.RecvPrjns() {
+			spj := p.(LeabraPrjn).AsLeabra()
+			send := cn.LayerByName(spj.Send.Name())
+			recv := cn.LayerByName(spj.Recv.Name())
+			cpj := cn.ConnectLayers(send, recv, CopyPat(spj.Pat), spj.Typ).(LeabraPrjn).AsLeabra()
+			cpj.CopyParamsFrom(spj)
+		}
+	}
+	if err := cn.Build(); err != nil {
+		log.Println(err)
+	}
+	cn.LayRndSeed = nt.LayRndSeed
+	cn.PrjnRndSeed = nt.PrjnRndSeed
+	cn.SetRndStates(nt.RndStates())
+	if nt.SynView.Lay != "" {
+		cn.SetSynView(nt.SynView.Lay, nt.SynView.Idx)
+	}
+	nt.SyncFromDevice()
+	for li, ly := range nt.Layers {
+		sl := ly.(LeabraLayer).AsLeabra()
+		cl := cn.Layers[li].(LeabraLayer).AsLeabra()
+		cl.CopyStateFrom(sl)
+		for pi, p := range sl.RcvPrjns {
+			cpj := cl.RcvPrjns[pi].(LeabraPrjn).AsLeabra()
+			cpj.CopyStateFrom(p.(LeabraPrjn).AsLeabra())
+		}
+	}
+	if nt.Compute != nil {
+		cn.SetCompute(nt.Compute.Name()) // syncs the copied state to the device
+	}
+	return cn
+}
+
+// CopyPat returns a copy of given projection pattern, for a pattern that is
+// a pointer to a struct (as all the standard ones are), with copies of any
+// slices of its fields -- otherwise returns the pattern itself
+func CopyPat(pat prjn.Pattern) prjn.Pattern {
+	pv := reflect.ValueOf(pat)
+	if pv.Kind() != reflect.Ptr || pv.IsNil() || pv.Elem().Kind() != reflect.Struct {
+		return pat
+	}
+	nv := reflect.New(pv.Elem().Type())
+	nv.Elem().Set(pv.Elem())
+	st := nv.Elem()
+	for fi := 0; fi < st.NumField(); fi++ {
+		fv := st.Field(fi)
+		if fv.Kind() == reflect.Slice && !fv.IsNil() && fv.CanSet() {
+			cs := reflect.MakeSlice(fv.Type(), fv.Len(), fv.Len())
+			reflect.Copy(cs, fv)
+			fv.Set(cs)
+		}
+	}
+	return nv.Interface().(prjn.Pattern)
+}
+
+// CopyParamsFrom copies the structural settings (class, thread, position, etc)
+// and all of the parameters from the given source layer.
+func (ly *Layer) CopyParamsFrom(sl *Layer) {
+	ly.Cls = sl.Cls
+	ly.Off = sl.Off
+	ly.Thr = sl.Thr
+	ly.NeurThr = sl.NeurThr
+	ly.Rel = sl.Rel
+	ly.Ps = sl.Ps
+	ly.Act = sl.Act
+	ly.Inhib = sl.Inhib
+	ly.Learn = sl.Learn
+	ly.LaySim = sl.LaySim
+}
+
+// CopyStateFrom copies all of the neuron, pool and other layer-level state,
+// including the past activity of CalLaySim, from the given source layer, which must have the same shape as this one.
+func (ly *Layer) CopyStateFrom(sl *Layer) {
+	ly.Neurons = append(ly.Neurons[:0], sl.Neurons...)
+	ly.Pools = append(ly.Pools[:0], sl.Pools...)
+	ly.CosDiff = sl.CosDiff
+	ly.Sim = sl.Sim
+	ly.simSt.CopyFrom(&sl.simSt)
+}
+
+// CopyParamsFrom copies the structural settings (class, notes, etc) and all of
+// the parameters from the given source projection.
+func (pj *Prjn) CopyParamsFrom(spj *Prjn) {
+	pj.Off = spj.Off
+	pj.Cls = spj.Cls
+	pj.Notes = spj.Notes
+	pj.WtInit = spj.WtInit
+	pj.WtScale = spj.WtScale
+	pj.Learn = spj.Learn
+	pj.SynDep = spj.SynDep
+}
+
+// CopyStateFrom copies the full connectivity and all of the synaptic state from
+// the given source projection, which must connect layers of the same shapes.
+func (pj *Prjn) CopyStateFrom(spj *Prjn) {
+	pj.RConN = append(pj.RConN[:0], spj.RConN...)
+	pj.RConNAvgMax = spj.RConNAvgMax
+	pj.RConIdxSt = append(pj.RConIdxSt[:0], spj.RConIdxSt...)
+	pj.RConIdx = append(pj.RConIdx[:0], spj.RConIdx...)
+	pj.RSynIdx = append(pj.RSynIdx[:0], spj.RSynIdx...)
+	pj.SConN = append(pj.SConN[:0], spj.SConN...)
+	pj.SConNAvgMax = spj.SConNAvgMax
+	pj.SConIdxSt = append(pj.SConIdxSt[:0], spj.SConIdxSt...)
+	pj.SConIdx = append(pj.SConIdx[:0], spj.SConIdx...)
+	pj.Syns.CopyFrom(&spj.Syns)
+	pj.GScale = spj.GScale
+	pj.GInc = append(pj.GInc[:0], spj.GInc...)
+	pj.WbRecv = append(pj.WbRecv[:0], spj.WbRecv...)
+}
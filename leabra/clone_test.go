@@ -0,0 +1,105 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/erand"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/etable/etensor"
+)
+
+func TestClone(t *testing.T) {
+	TestNet.InitWts()
+	cn := TestNet.Clone("TestNetClone")
+	hidLay := TestNet.LayerByName("Hidden").(*Layer)
+	fmIn := hidLay.RcvPrjns.SendName("Input").(*Prjn)
+	chidLay := cn.LayerByName("Hidden").(*Layer)
+	cfmIn := chidLay.RcvPrjns.SendName("Input").(*Prjn)
+
+	bfWt := fmIn.SynVal("Wt", 1, 1)
+	if cWt := cfmIn.SynVal("Wt", 1, 1); cWt != bfWt {
+		t.Errorf("clone wt: %v != orig wt: %v\n", cWt, bfWt)
+	}
+	cfmIn.SetSynVal("Wt", 1, 1, .15)
+	if afWt := fmIn.SynVal("Wt", 1, 1); afWt != bfWt {
+		t.Errorf("orig wt changed by setting clone wt: %v != %v\n", afWt, bfWt)
+	}
+	if chidLay.Act != hidLay.Act {
+		t.Errorf("clone Act params differ from orig\n")
+	}
+}
+
+func TestCloneLockstep(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "LockNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewUnifRnd(), emer.Forward)
+	net.Defaults()
+	hid := hidLay.(*Layer)
+	hid.Act.Noise.Type = GeNoise
+	hid.Act.Noise.Dist = erand.Gaussian
+	hid.Act.Noise.Var = 0.2
+	hid.Act.Noise.Fixed = false
+	hid.LaySim.Lag = 2
+	hid.LaySim.Tau = 3
+	hid.LaySim.Update()
+	net.Build()
+	net.SeedPrjnRnd(1)
+	net.SeedLayerRnd(2)
+	net.InitWts()
+	net.SetSynView("Hidden", 1)
+
+	inpat := etensor.NewFloat32([]int{2, 2}, nil, nil)
+	inpat.Set([]int{0, 0}, 1)
+	inpat.Set([]int{1, 1}, 1)
+	run := func(nt *Network) {
+		nt.LayerByName("Input").(*Layer).ApplyExt(inpat)
+		ltime := NewTime()
+		nt.AlphaCycInit()
+		ltime.AlphaCycStart()
+		for cyc := 0; cyc < ltime.CycPerQtr; cyc++ {
+			nt.Cycle(ltime, cyc%2 == 1) // sleep cycles update the LaySim history
+			ltime.CycleInc()
+		}
+	}
+	run(net) // the clone starts mid-way, with the noise streams advanced
+	net.Stats.SumSparse = map[string]float64{"Hidden": 0.5}
+	net.Stats.Cnt = 2
+
+	cn := net.Clone("LockNetClone")
+	if cn.SynView != net.SynView || cn.Layers[0].(*Layer).SynView != &cn.SynView {
+		t.Errorf("SynView not copied: %v\n", cn.SynView)
+	}
+	spat := net.PrjnByName("InputToHidden").Pat
+	cpat := cn.PrjnByName("InputToHidden").Pat
+	if spat == cpat {
+		t.Errorf("clone shares the projection pattern\n")
+	}
+	if cn.Stats.Cnt != 2 || cn.Stats.SumSparse["Hidden"] != 0.5 {
+		t.Errorf("NetStats accumulators not copied: %+v\n", cn.Stats)
+	}
+	cn.Stats.SumSparse["Hidden"] = 1
+	if net.Stats.SumSparse["Hidden"] != 0.5 {
+		t.Errorf("clone shares the NetStats accumulators\n")
+	}
+	for i := 0; i < 3; i++ {
+		run(net)
+		run(cn)
+		acts := net.LayerByName("Hidden").(*Layer).UnitVals("Act")
+		cacts := cn.LayerByName("Hidden").(*Layer).UnitVals("Act")
+		for ni := range acts {
+			if acts[ni] != cacts[ni] {
+				t.Fatalf("trial %d unit %d: clone Act: %v != orig Act: %v\n", i, ni, cacts[ni], acts[ni])
+			}
+		}
+		if sim, csim := net.LayerByName("Hidden").(*Layer).Sim, cn.LayerByName("Hidden").(*Layer).Sim; sim != csim {
+			t.Fatalf("trial %d: clone LaySim: %v != orig LaySim: %v\n", i, csim, sim)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/goki/gi/gi"
+)
+
+// ComputeBackend is an abstraction for the cycle-level updates (Network.Cycle),
+// which dominate the cost of long sleep periods, so they can be run on
+// alternative hardware such as a GPU.  This package provides only the
+// interface and the standard CPU backend: there is no GPU backend here, as
+// there are no device bindings among its dependencies -- one must be
+// implemented in a separate package with the relevant bindings, and
+// registered with RegisterComputeBackend.
+//
+// A backend runs entire cycles: all of the steps of Network.CycleCPU,
+// including the inhibition and the layer stats, so that no step runs on the
+// CPU between steps on the device -- a backend that runs only some steps on
+// the device must sync the state around the others itself.
+//
+// Between cycles, the backend is responsible for keeping any device-side copy
+// of the state and params in sync with the Network: SyncToDevice is called
+// after Init and whenever the network state or params are changed on the CPU
+// (InitWts, InitActs, InitGInc, InitSdEffWt, AlphaCycInit -- which also picks
+// up the external inputs applied before it -- QuarterFinal, WtFmDWt, Sleep,
+// Wake, InhibOscil, OpenWts, SetWts), and SyncFromDevice whenever the CPU
+// needs to read the state (QuarterFinal, DWt, SaveWts, Logs.LogRow, Clone).
+// Code that reads or changes the neuron or synapse state directly, e.g.,
+// recording the activity on each cycle, must call these itself.  The
+// standard CPU code is used when no backend is set.
+type ComputeBackend interface {
+	// Name returns the name of the backend, as registered
+	Name() string
+
+	// Init initializes the backend for given network, which must be built --
+	// returns an error if the backend is not available (e.g., no device), in
+	// which case the CPU is used instead
+	Init(nt *Network) error
+
+	// SyncToDevice copies the network state to the device
+	SyncToDevice(nt *Network)
+
+	// SyncFromDevice copies the network state back from the device
+	SyncFromDevice(nt *Network)
+
+	// Cycle runs one cycle of updating, as in Network.CycleCPU
+	Cycle(nt *Network, ltime *Time, sleep bool)
+}
+
+// ComputeBackends is the registry of available compute backends, by name,
+// as functions that return a new instance of the backend.  CPU is always
+// available -- other backends (e.g., implemented in a separate package with
+// the relevant device bindings) register themselves with RegisterComputeBackend.
+var ComputeBackends = map[string]func() ComputeBackend{
+	"CPU": func() ComputeBackend { return &CPUCompute{} },
+}
+
+// RegisterComputeBackend registers a compute backend under given name
+func RegisterComputeBackend(name string, fun func() ComputeBackend) {
+	ComputeBackends[name] = fun
+}
+
+// ComputeBackendNames returns the sorted names of the registered compute backends
+func ComputeBackendNames() []string {
+	nms := make([]string, 0, len(ComputeBackends))
+	for nm := range ComputeBackends {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	return nms
+}
+
+// SetCompute sets the compute backend for the cycle-level updates to the
+// registered backend of given name, which is initialized for this network
+// (must already be built).  If the backend is not registered or fails to
+// initialize, the standard CPU code is used, and the error is returned.
+func (nt *Network) SetCompute(name string) error {
+	nt.Compute = nil
+	fun, has := ComputeBackends[name]
+	if !has {
+		err := fmt.Errorf("Network.SetCompute: compute backend: %v not registered, using CPU -- available: %v", name, ComputeBackendNames())
+		log.Println(err)
+		return err
+	}
+	cb := fun()
+	if err := cb.Init(nt); err != nil {
+		err = fmt.Errorf("Network.SetCompute: compute backend: %v could not be initialized, using CPU: %v", name, err)
+		log.Println(err)
+		return err
+	}
+	cb.SyncToDevice(nt)
+	nt.Compute = cb
+	return nil
+}
+
+// SyncToDevice copies the network state to the compute device, if a
+// non-CPU compute backend is in use -- call after changing the state
+// on the CPU (e.g., InitWts, loading weights).
+func (nt *Network) SyncToDevice() {
+	if nt.Compute != nil {
+		nt.Compute.SyncToDevice(nt)
+	}
+}
+
+// SyncFromDevice copies the network state back from the compute device,
+// if a non-CPU compute backend is in use -- call before reading the state.
+func (nt *Network) SyncFromDevice() {
+	if nt.Compute != nil {
+		nt.Compute.SyncFromDevice(nt)
+	}
+}
+
+// OpenWts opens network weights (see NetworkStru.OpenWts), and copies
+// them to the compute device
+func (nt *Network) OpenWts(filename gi.FileName) error {
+	err := nt.NetworkStru.OpenWts(filename)
+	nt.SyncToDevice()
+	return err
+}
+
+// SaveWts saves network weights (see NetworkStru.SaveWts), after copying
+// them back from the compute device
+func (nt *Network) SaveWts(filename gi.FileName) error {
+	nt.SyncFromDevice()
+	return nt.NetworkStru.SaveWts(filename)
+}
+
+// SetWts sets the weights from NetWts decoded values (see
+// NetworkStru.SetWts), and copies them to the compute device
+func (nt *Network) SetWts(nw *NetWts) error {
+	err := nt.NetworkStru.SetWts(nw)
+	nt.SyncToDevice()
+	return err
+}
+
+// CPUCompute is the standard CPU compute backend, using the layer-level threads
+type CPUCompute struct {
+}
+
+func (cc *CPUCompute) Name() string               { return "CPU" }
+func (cc *CPUCompute) Init(nt *Network) error     { return nil }
+func (cc *CPUCompute) SyncToDevice(nt *Network)   {}
+func (cc *CPUCompute) SyncFromDevice(nt *Network) {}
+
+func (cc *CPUCompute) Cycle(nt *Network, ltime *Time, sleep bool) {
+	nt.CycleCPU(ltime, sleep)
+}
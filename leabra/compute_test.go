@@ -0,0 +1,100 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goki/gi/gi"
+)
+
+// syncCompute is a CPU backend that counts the syncs
+type syncCompute struct {
+	CPUCompute
+	nTo, nFrom int
+	nCyc       int
+}
+
+func (sc *syncCompute) Name() string               { return "Sync" }
+func (sc *syncCompute) SyncToDevice(nt *Network)   { sc.nTo++ }
+func (sc *syncCompute) SyncFromDevice(nt *Network) { sc.nFrom++ }
+
+func (sc *syncCompute) Cycle(nt *Network, ltime *Time, sleep bool) {
+	sc.nCyc++
+	nt.CycleCPU(ltime, sleep)
+}
+
+func TestComputeSync(t *testing.T) {
+	net := ensembleNet()
+	if err := net.SetCompute("NoSuch"); err == nil || net.Compute != nil {
+		t.Errorf("expected error and CPU for an unregistered backend\n")
+	}
+	sc := &syncCompute{}
+	RegisterComputeBackend("Sync", func() ComputeBackend { return sc })
+	defer delete(ComputeBackends, "Sync")
+	if err := net.SetCompute("Sync"); err != nil {
+		t.Fatal(err)
+	}
+	if sc.nTo != 1 {
+		t.Errorf("SetCompute syncs to device: %v, want 1\n", sc.nTo)
+	}
+
+	net.InitWts()
+	if sc.nTo != 2 {
+		t.Errorf("InitWts syncs to device: %v, want 2\n", sc.nTo)
+	}
+	net.DWt()
+	net.WtFmDWt()
+	if sc.nFrom != 1 || sc.nTo != 3 {
+		t.Errorf("learning syncs: from %v to %v, want 1 3\n", sc.nFrom, sc.nTo)
+	}
+
+	dir, err := ioutil.TempDir("", "compute")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fnm := gi.FileName(filepath.Join(dir, "net.wtsb"))
+	if err := net.SaveWts(fnm); err != nil {
+		t.Fatal(err)
+	}
+	if err := net.OpenWts(fnm); err != nil {
+		t.Fatal(err)
+	}
+	if sc.nFrom != 2 || sc.nTo != 4 {
+		t.Errorf("weight file syncs: from %v to %v, want 2 4\n", sc.nFrom, sc.nTo)
+	}
+
+	// whole cycles run on the backend, with syncs only around them
+	ltime := NewTime()
+	net.AlphaCycInit()
+	if sc.nTo != 5 {
+		t.Errorf("AlphaCycInit syncs to device: %v, want 5\n", sc.nTo)
+	}
+	for cyc := 0; cyc < ltime.CycPerQtr; cyc++ {
+		net.Cycle(ltime, false)
+		ltime.CycleInc()
+	}
+	if sc.nCyc != ltime.CycPerQtr || sc.nFrom != 2 || sc.nTo != 5 {
+		t.Errorf("cycles: %v, syncs from %v to %v, want %v 2 5\n", sc.nCyc, sc.nFrom, sc.nTo, ltime.CycPerQtr)
+	}
+	net.QuarterFinal(ltime)
+	if sc.nFrom != 3 || sc.nTo != 6 {
+		t.Errorf("QuarterFinal syncs: from %v to %v, want 3 6\n", sc.nFrom, sc.nTo)
+	}
+
+	lg := &Logs{}
+	lg.AddLayer("Train", Trial, "Hid Sim", "Hidden", "Sim")
+	if err := lg.Config(net); err != nil {
+		t.Fatal(err)
+	}
+	lg.Log("Train", Trial)
+	if sc.nFrom != 4 {
+		t.Errorf("logging syncs from device: %v, want 4\n", sc.nFrom)
+	}
+}
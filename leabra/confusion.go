@@ -0,0 +1,159 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// Confusion is a pattern-by-pattern confusion matrix over test trials: the
+// output of each trial is compared (by cosine) with the target output
+// patterns of all the items, and the trial is counted in the row of the
+// item that was presented and the column of the item whose pattern the
+// output most resembles.  Off-diagonal counts thus show which items
+// interfere with each other.
+type Confusion struct {
+	Names  []string         `desc:"names of the items, in the order of the rows and columns"`
+	Pats   [][]float64      `view:"-" desc:"target output pattern of each item"`
+	Counts *etensor.Float64 `view:"no-inline" desc:"number of trials of each presented item (row) whose output was closest to each item (column)"`
+	N      int              `inactive:"+" desc:"number of trials counted since the last Reset"`
+}
+
+// SetPats sets the items from given table of patterns, with the name of each
+// item in nameCol and its target output pattern in patCol, and calls Reset
+func (cf *Confusion) SetPats(dt *etable.Table, nameCol, patCol string) error {
+	names, pats, err := tablePats(dt, nameCol, patCol)
+	if err != nil {
+		return err
+	}
+	cf.Names = names
+	cf.Pats = pats
+	cf.Reset()
+	return nil
+}
+
+// tablePats returns the names in string column nameCol and the patterns in
+// tensor column patCol of each row of given table
+func tablePats(dt *etable.Table, nameCol, patCol string) ([]string, [][]float64, error) {
+	nc := dt.ColByName(nameCol)
+	if nc == nil || dt.ColByName(patCol) == nil {
+		err := fmt.Errorf("columns: %v, %v not found in table: %v", nameCol, patCol, dt.MetaData["name"])
+		log.Println(err)
+		return nil, nil, err
+	}
+	names := make([]string, dt.Rows)
+	pats := make([][]float64, dt.Rows)
+	for row := 0; row < dt.Rows; row++ {
+		names[row] = nc.StringVal1D(row)
+		pt := dt.CellTensor(patCol, row)
+		pat := make([]float64, pt.Len())
+		for i := range pat {
+			pat[i] = pt.FloatVal1D(i)
+		}
+		pats[row] = pat
+	}
+	return names, pats, nil
+}
+
+// Reset zeros the counts
+func (cf *Confusion) Reset() {
+	n := len(cf.Names)
+	cf.Counts = etensor.NewFloat64([]int{n, n}, nil, []string{"Presented", "Closest"})
+	cf.N = 0
+}
+
+// ItemIdx returns the index of the item with given name, -1 if not found
+func (cf *Confusion) ItemIdx(name string) int {
+	for i, nm := range cf.Names {
+		if nm == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Closest returns the index of the item whose pattern has the highest
+// cosine with given output activity, -1 if there are no items
+func (cf *Confusion) Closest(act etensor.Tensor) int {
+	vals := make([]float64, act.Len())
+	for i := range vals {
+		vals[i] = act.FloatVal1D(i)
+	}
+	best := -1
+	bestCos := 0.0
+	for i, pat := range cf.Pats {
+		if len(pat) != len(vals) {
+			continue
+		}
+		cs := repCos(vals, pat, 0, 0)
+		if best < 0 || cs > bestCos {
+			best = i
+			bestCos = cs
+		}
+	}
+	return best
+}
+
+// Incr counts a trial in which the item with given name was presented and
+// the output activity was act, returning the index of the closest item
+func (cf *Confusion) Incr(name string, act etensor.Tensor) (int, error) {
+	pi := cf.ItemIdx(name)
+	if pi < 0 {
+		err := fmt.Errorf("Confusion: item: %v not found", name)
+		log.Println(err)
+		return -1, err
+	}
+	ci := cf.Closest(act)
+	if ci < 0 {
+		return -1, nil
+	}
+	if cf.Counts == nil || cf.Counts.Len() != len(cf.Names)*len(cf.Names) {
+		cf.Reset()
+	}
+	cf.Counts.Values[pi*len(cf.Names)+ci]++
+	cf.N++
+	return ci, nil
+}
+
+// Probs returns the counts normalized within each row: the probability that
+// the output of each presented item was closest to each item
+func (cf *Confusion) Probs() *etensor.Float64 {
+	n := len(cf.Names)
+	pr := etensor.NewFloat64([]int{n, n}, nil, []string{"Presented", "Closest"})
+	if cf.Counts == nil || cf.Counts.Len() != n*n {
+		return pr
+	}
+	for r := 0; r < n; r++ {
+		sum := 0.0
+		for c := 0; c < n; c++ {
+			sum += cf.Counts.Values[r*n+c]
+		}
+		if sum == 0 {
+			continue
+		}
+		for c := 0; c < n; c++ {
+			pr.Values[r*n+c] = cf.Counts.Values[r*n+c] / sum
+		}
+	}
+	return pr
+}
+
+// PctConfused returns the proportion of the trials counted whose output was
+// closest to an item other than the one presented
+func (cf *Confusion) PctConfused() float64 {
+	if cf.N == 0 {
+		return 0
+	}
+	n := len(cf.Names)
+	diag := 0.0
+	for i := 0; i < n; i++ {
+		diag += cf.Counts.Values[i*n+i]
+	}
+	return 1 - diag/float64(cf.N)
+}
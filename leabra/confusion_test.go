@@ -0,0 +1,51 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestConfusion(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Output", etensor.FLOAT32, []int{3}, nil},
+	}, 3)
+	for row, nm := range []string{"a", "b", "c"} {
+		dt.SetCellString("Name", row, nm)
+		dt.CellTensor("Output", row).SetFloat1D(row, 1)
+	}
+	cf := &Confusion{}
+	if err := cf.SetPats(dt, "Name", "Output"); err != nil {
+		t.Fatal(err)
+	}
+	act := etensor.NewFloat32([]int{3}, nil, nil)
+	act.Values = []float32{0.9, 0.1, 0}
+	if ci, _ := cf.Incr("a", act); ci != 0 {
+		t.Errorf("a closest to %v, want 0\n", ci)
+	}
+	act.Values = []float32{0.2, 0, 0.7}
+	cf.Incr("b", act)
+	cf.Incr("b", act)
+	act.Values = []float32{0, 0.6, 0}
+	cf.Incr("b", act)
+	if _, err := cf.Incr("nope", act); err == nil {
+		t.Errorf("expected error for unknown item\n")
+	}
+	if cf.N != 4 || cf.Counts.Value([]int{1, 2}) != 2 {
+		t.Errorf("counts wrong: N %v b->c %v\n", cf.N, cf.Counts.Value([]int{1, 2}))
+	}
+	pr := cf.Probs()
+	if v := pr.Value([]int{1, 1}); v < 0.333 || v > 0.334 {
+		t.Errorf("P(b->b) = %v, want 1/3\n", v)
+	}
+	if v := cf.PctConfused(); v != 0.5 {
+		t.Errorf("PctConfused = %v, want 0.5\n", v)
+	}
+}
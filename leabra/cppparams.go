@@ -0,0 +1,431 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/emer/emergent/params"
+	"github.com/goki/gi/gi"
+)
+
+// CppParam maps a param path to the corresponding member of a spec in C++
+// emergent (version 8), for converting params to and from .proj files
+type CppParam struct {
+	Path   string `desc:"param path, e.g., Layer.Inhib.Layer.Gi"`
+	Spec   string `desc:"C++ spec type: LeabraLayerSpec, LeabraUnitSpec or LeabraConSpec"`
+	Member string `desc:"member path within the C++ spec, e.g., lay_inhib.gi"`
+	Bool   bool   `desc:"value is a bool, which is 0 / 1 in C++"`
+}
+
+// CppParams is the mapping between params and the members of the C++
+// Leabra / CtLeabra specs, for the params that have the same meaning in both
+var CppParams = []CppParam{
+	{"Layer.Inhib.Layer.On", "LeabraLayerSpec", "lay_inhib.on", true},
+	{"Layer.Inhib.Layer.Gi", "LeabraLayerSpec", "lay_inhib.gi", false},
+	{"Layer.Inhib.Layer.FF", "LeabraLayerSpec", "lay_inhib.ff", false},
+	{"Layer.Inhib.Layer.FB", "LeabraLayerSpec", "lay_inhib.fb", false},
+	{"Layer.Inhib.Layer.FBTau", "LeabraLayerSpec", "lay_inhib.fb_tau", false},
+	{"Layer.Inhib.Layer.MaxVsAvg", "LeabraLayerSpec", "lay_inhib.max_vs_avg", false},
+	{"Layer.Inhib.Layer.FF0", "LeabraLayerSpec", "lay_inhib.ff0", false},
+	{"Layer.Inhib.Pool.On", "LeabraLayerSpec", "unit_gp_inhib.on", true},
+	{"Layer.Inhib.Pool.Gi", "LeabraLayerSpec", "unit_gp_inhib.gi", false},
+	{"Layer.Inhib.Pool.FF", "LeabraLayerSpec", "unit_gp_inhib.ff", false},
+	{"Layer.Inhib.Pool.FB", "LeabraLayerSpec", "unit_gp_inhib.fb", false},
+	{"Layer.Inhib.Pool.FBTau", "LeabraLayerSpec", "unit_gp_inhib.fb_tau", false},
+	{"Layer.Inhib.Pool.MaxVsAvg", "LeabraLayerSpec", "unit_gp_inhib.max_vs_avg", false},
+	{"Layer.Inhib.Pool.FF0", "LeabraLayerSpec", "unit_gp_inhib.ff0", false},
+	{"Layer.Inhib.ActAvg.Init", "LeabraLayerSpec", "avg_act.init", false},
+	{"Layer.Inhib.ActAvg.Fixed", "LeabraLayerSpec", "avg_act.fixed", true},
+	{"Layer.Inhib.ActAvg.UseExtAct", "LeabraLayerSpec", "avg_act.use_ext_act", true},
+	{"Layer.Inhib.ActAvg.UseFirst", "LeabraLayerSpec", "avg_act.use_first", true},
+	{"Layer.Inhib.ActAvg.Tau", "LeabraLayerSpec", "avg_act.tau", false},
+	{"Layer.Inhib.ActAvg.Adjust", "LeabraLayerSpec", "avg_act.adjust", false},
+	{"Layer.Inhib.Self.On", "LeabraUnitSpec", "self_inhib.on", true},
+	{"Layer.Inhib.Self.Gi", "LeabraUnitSpec", "self_inhib.gi", false},
+	{"Layer.Inhib.Self.Tau", "LeabraUnitSpec", "self_inhib.tau", false},
+	{"Layer.Act.XX1.Thr", "LeabraUnitSpec", "act.thr", false},
+	{"Layer.Act.XX1.Gain", "LeabraUnitSpec", "act.gain", false},
+	{"Layer.Act.XX1.NVar", "LeabraUnitSpec", "act.nvar", false},
+	{"Layer.Act.XX1.VmActThr", "LeabraUnitSpec", "act.vm_act_thr", false},
+	{"Layer.Act.OptThresh.Send", "LeabraUnitSpec", "opt_thresh.send", false},
+	{"Layer.Act.OptThresh.Delta", "LeabraUnitSpec", "opt_thresh.delta", false},
+	{"Layer.Act.Init.Decay", "LeabraUnitSpec", "init.decay", false},
+	{"Layer.Act.Init.Vm", "LeabraUnitSpec", "init.v_m", false},
+	{"Layer.Act.Init.Act", "LeabraUnitSpec", "init.act", false},
+	{"Layer.Act.Init.Ge", "LeabraUnitSpec", "init.netin", false},
+	{"Layer.Act.Dt.Integ", "LeabraUnitSpec", "dt.integ", false},
+	{"Layer.Act.Dt.VmTau", "LeabraUnitSpec", "dt.vm_tau", false},
+	{"Layer.Act.Dt.GTau", "LeabraUnitSpec", "dt.net_tau", false},
+	{"Layer.Act.Dt.AvgTau", "LeabraUnitSpec", "dt.avg_tau", false},
+	{"Layer.Act.Gbar.E", "LeabraUnitSpec", "g_bar.e", false},
+	{"Layer.Act.Gbar.L", "LeabraUnitSpec", "g_bar.l", false},
+	{"Layer.Act.Gbar.I", "LeabraUnitSpec", "g_bar.i", false},
+	{"Layer.Act.Gbar.K", "LeabraUnitSpec", "g_bar.k", false},
+	{"Layer.Act.Erev.E", "LeabraUnitSpec", "e_rev.e", false},
+	{"Layer.Act.Erev.L", "LeabraUnitSpec", "e_rev.l", false},
+	{"Layer.Act.Erev.I", "LeabraUnitSpec", "e_rev.i", false},
+	{"Layer.Act.Erev.K", "LeabraUnitSpec", "e_rev.k", false},
+	{"Layer.Act.Clamp.Hard", "LeabraUnitSpec", "clamp.hard", true},
+	{"Layer.Act.Clamp.Range.Min", "LeabraUnitSpec", "clamp.range.min", false},
+	{"Layer.Act.Clamp.Range.Max", "LeabraUnitSpec", "clamp.range.max", false},
+	{"Layer.Act.Clamp.Gain", "LeabraUnitSpec", "clamp.gain", false},
+	{"Layer.Act.Clamp.Avg", "LeabraUnitSpec", "clamp.avg", true},
+	{"Layer.Act.Clamp.AvgGain", "LeabraUnitSpec", "clamp.avg_gain", false},
+	{"Layer.Learn.ActAvg.SSTau", "LeabraUnitSpec", "act_avg.ss_tau", false},
+	{"Layer.Learn.ActAvg.STau", "LeabraUnitSpec", "act_avg.s_tau", false},
+	{"Layer.Learn.ActAvg.MTau", "LeabraUnitSpec", "act_avg.m_tau", false},
+	{"Layer.Learn.ActAvg.LrnM", "LeabraUnitSpec", "act_avg.lrn_m", false},
+	{"Layer.Learn.ActAvg.Init", "LeabraUnitSpec", "act_avg.init", false},
+	{"Layer.Learn.AvgL.Init", "LeabraUnitSpec", "avg_l.init", false},
+	{"Layer.Learn.AvgL.Gain", "LeabraUnitSpec", "avg_l.gain", false},
+	{"Layer.Learn.AvgL.Min", "LeabraUnitSpec", "avg_l.min", false},
+	{"Layer.Learn.AvgL.Tau", "LeabraUnitSpec", "avg_l.tau", false},
+	{"Layer.Learn.AvgL.LrnMax", "LeabraUnitSpec", "avg_l.lrn_max", false},
+	{"Layer.Learn.AvgL.LrnMin", "LeabraUnitSpec", "avg_l.lrn_min", false},
+	{"Layer.Learn.AvgL.ErrMod", "LeabraUnitSpec", "avg_l.err_mod", true},
+	{"Layer.Learn.AvgL.ModMin", "LeabraUnitSpec", "avg_l.err_mod_min", false},
+	{"Prjn.Learn.Learn", "LeabraConSpec", "learn", true},
+	{"Prjn.Learn.Lrate", "LeabraConSpec", "lrate", false},
+	{"Prjn.WtInit.Mean", "LeabraConSpec", "rnd.mean", false},
+	{"Prjn.WtInit.Var", "LeabraConSpec", "rnd.var", false},
+	{"Prjn.WtScale.Abs", "LeabraConSpec", "wt_scale.abs", false},
+	{"Prjn.WtScale.Rel", "LeabraConSpec", "wt_scale.rel", false},
+	{"Prjn.Learn.XCal.MLrn", "LeabraConSpec", "xcal.m_lrn", false},
+	{"Prjn.Learn.XCal.SetLLrn", "LeabraConSpec", "xcal.set_l_lrn", true},
+	{"Prjn.Learn.XCal.LLrn", "LeabraConSpec", "xcal.l_lrn", false},
+	{"Prjn.Learn.XCal.DRev", "LeabraConSpec", "xcal.d_rev", false},
+	{"Prjn.Learn.XCal.DThr", "LeabraConSpec", "xcal.d_thr", false},
+	{"Prjn.Learn.XCal.LrnThr", "LeabraConSpec", "xcal.lrn_thr", false},
+	{"Prjn.Learn.WtSig.Gain", "LeabraConSpec", "wt_sig.gain", false},
+	{"Prjn.Learn.WtSig.Off", "LeabraConSpec", "wt_sig.off", false},
+	{"Prjn.Learn.WtSig.SoftBound", "LeabraConSpec", "wt_sig.soft_bound", true},
+	{"Prjn.Learn.Norm.On", "LeabraConSpec", "dwt_norm.on", true},
+	{"Prjn.Learn.Norm.DecayTau", "LeabraConSpec", "dwt_norm.decay_tau", false},
+	{"Prjn.Learn.Norm.NormMin", "LeabraConSpec", "dwt_norm.norm_min", false},
+	{"Prjn.Learn.Norm.LrComp", "LeabraConSpec", "dwt_norm.lr_comp", false},
+	{"Prjn.Learn.Momentum.On", "LeabraConSpec", "momentum.on", true},
+	{"Prjn.Learn.Momentum.MTau", "LeabraConSpec", "momentum.m_tau", false},
+	{"Prjn.Learn.Momentum.LrComp", "LeabraConSpec", "momentum.lr_comp", false},
+	{"Prjn.Learn.WtBal.On", "LeabraConSpec", "wt_bal.on", true},
+	{"Prjn.Learn.WtBal.AvgThr", "LeabraConSpec", "wt_bal.avg_thr", false},
+	{"Prjn.Learn.WtBal.HiThr", "LeabraConSpec", "wt_bal.hi_thr", false},
+	{"Prjn.Learn.WtBal.HiGain", "LeabraConSpec", "wt_bal.hi_gain", false},
+	{"Prjn.Learn.WtBal.LoThr", "LeabraConSpec", "wt_bal.lo_thr", false},
+	{"Prjn.Learn.WtBal.LoGain", "LeabraConSpec", "wt_bal.lo_gain", false},
+	{"Prjn.SynDep.CaInc", "LeabraConSpec", "ca_dep.ca_inc", false},
+	{"Prjn.SynDep.CaDec", "LeabraConSpec", "ca_dep.ca_dec", false},
+	{"Prjn.SynDep.CaThr", "LeabraConSpec", "ca_dep.sd_ca_thr", false},
+	{"Prjn.SynDep.CaGain", "LeabraConSpec", "ca_dep.sd_ca_gain", false},
+}
+
+// cppMemberAliases are older names for C++ members, accepted on import
+var cppMemberAliases = map[string]string{
+	"avg_act.targ_init": "avg_act.init",
+	"inhib.gi":          "lay_inhib.gi",
+}
+
+// CppSpecKind returns the base spec type (LeabraLayerSpec, LeabraUnitSpec,
+// LeabraConSpec) for a C++ spec type, including derived types such as
+// SRAvgCaiSynDepConSpec -- returns "" for other types, including bias specs
+func CppSpecKind(typ string) string {
+	switch {
+	case strings.HasSuffix(typ, "LayerSpec"):
+		return "LeabraLayerSpec"
+	case strings.HasSuffix(typ, "UnitSpec"):
+		return "LeabraUnitSpec"
+	case strings.HasSuffix(typ, "ConSpec") && !strings.Contains(typ, "Bias"):
+		return "LeabraConSpec"
+	}
+	return ""
+}
+
+// CppSpec is a spec read from a C++ emergent project, with all of its
+// member values flattened to dotted paths, e.g., lay_inhib.gi
+type CppSpec struct {
+	Type string            `desc:"C++ type of the spec, e.g., LeabraLayerSpec"`
+	Name string            `desc:"name of the spec"`
+	Vals map[string]string `desc:"member values, by dotted member path"`
+}
+
+// ParseCppSpecs parses the Leabra layer, unit and connection specs in the
+// text of a C++ emergent .proj (or .spec) file.  Objects are dumped one
+// member per line, as name=value; with struct values as {a=1: b=2: }.
+// Declarations and values for the same object path are merged.
+func ParseCppSpecs(src string) []*CppSpec {
+	type obj struct {
+		spec *CppSpec
+		path string
+	}
+	var specs []*CppSpec
+	bypath := map[string]*CppSpec{}
+	var stack []obj
+	for _, ln := range strings.Split(src, "\n") {
+		ln = strings.TrimSpace(ln)
+		switch {
+		case ln == "}" || ln == "};":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case strings.HasSuffix(ln, "{") && !strings.Contains(ln, "="):
+			flds := strings.Fields(strings.TrimSuffix(ln, "{"))
+			o := obj{}
+			if len(flds) > 0 && CppSpecKind(flds[0]) != "" {
+				if len(flds) > 1 && strings.HasPrefix(flds[1], "@") {
+					o.path = flds[1]
+				}
+				o.spec = bypath[o.path]
+				if o.spec == nil || o.path == "" {
+					o.spec = &CppSpec{Type: flds[0], Vals: map[string]string{}}
+					specs = append(specs, o.spec)
+					if o.path != "" {
+						bypath[o.path] = o.spec
+					}
+				}
+			}
+			stack = append(stack, o)
+		default:
+			if len(stack) == 0 {
+				continue
+			}
+			sp := stack[len(stack)-1].spec
+			ei := strings.Index(ln, "=")
+			if sp == nil || ei <= 0 || !strings.HasSuffix(ln, ";") {
+				continue
+			}
+			key := strings.TrimSpace(ln[:ei])
+			val := strings.TrimSpace(strings.TrimSuffix(ln[ei+1:], ";"))
+			switch key {
+			case "name":
+				sp.Name = strings.Trim(val, `"`)
+				continue
+			case "desc":
+				continue
+			}
+			cppFlatten(key, val, sp.Vals)
+		}
+	}
+	var named []*CppSpec
+	for _, sp := range specs {
+		if sp.Name != "" {
+			named = append(named, sp)
+		}
+	}
+	return named
+}
+
+// cppFlatten adds the value for given key to vals, recursing into struct
+// values of the form {a=1: b={c=2: }: }
+func cppFlatten(key, val string, vals map[string]string) {
+	if !strings.HasPrefix(val, "{") {
+		vals[key] = strings.Trim(val, `"`)
+		return
+	}
+	val = strings.TrimSuffix(strings.TrimPrefix(val, "{"), "}")
+	depth := 0
+	st := 0
+	for i := 0; i <= len(val); i++ {
+		if i < len(val) {
+			switch val[i] {
+			case '{':
+				depth++
+				continue
+			case '}':
+				depth--
+				continue
+			case ':':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		item := strings.TrimSpace(val[st:i])
+		st = i + 1
+		if ei := strings.Index(item, "="); ei > 0 {
+			cppFlatten(key+"."+strings.TrimSpace(item[:ei]), strings.TrimSpace(item[ei+1:]), vals)
+		}
+	}
+}
+
+// cppParamByMember returns the CppParam for given spec kind and member
+func cppParamByMember(kind, member string) *CppParam {
+	if al, ok := cppMemberAliases[member]; ok {
+		member = al
+	}
+	for i := range CppParams {
+		cp := &CppParams[i]
+		if cp.Spec == kind && cp.Member == member {
+			return cp
+		}
+	}
+	return nil
+}
+
+// cppParamByPath returns the CppParam for given param path
+func cppParamByPath(path string) *CppParam {
+	for i := range CppParams {
+		if CppParams[i].Path == path {
+			return &CppParams[i]
+		}
+	}
+	return nil
+}
+
+// CppSpecsSheet returns a params.Sheet with the values of the mapped members
+// in given C++ specs, with one Sel per spec, using the spec name as a class
+// selector (e.g., .HiddenLayerSpec) -- add that class to the layers or
+// projections that the spec applied to in the C++ project.
+func CppSpecsSheet(specs []*CppSpec) *params.Sheet {
+	sh := &params.Sheet{}
+	for _, sp := range specs {
+		kind := CppSpecKind(sp.Type)
+		pars := params.Params{}
+		for mem, val := range sp.Vals {
+			cp := cppParamByMember(kind, mem)
+			if cp == nil {
+				continue
+			}
+			if cp.Bool {
+				val = fmt.Sprint(val == "1" || strings.ToLower(val) == "true")
+			}
+			pars[cp.Path] = val
+		}
+		if len(pars) == 0 {
+			continue
+		}
+		*sh = append(*sh, &params.Sel{Sel: "." + sp.Name, Desc: "from C++ " + sp.Type + " " + sp.Name, Params: pars})
+	}
+	return sh
+}
+
+// OpenCppParams opens a C++ emergent .proj or .spec file and returns its
+// Leabra spec values as a params.Set with given name, with a Network sheet
+// (see CppSpecsSheet)
+func OpenCppParams(filename gi.FileName, setNm string) (*params.Set, error) {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	sh := CppSpecsSheet(ParseCppSpecs(string(b)))
+	if len(*sh) == 0 {
+		err = fmt.Errorf("OpenCppParams: no Leabra specs with convertible params found in: %v", filename)
+		log.Println(err)
+		return nil, err
+	}
+	return &params.Set{Name: setNm, Desc: "imported from C++ emergent: " + string(filename),
+		Sheets: params.Sheets{"Network": sh}}, nil
+}
+
+// cppSpecName returns a C++ spec name for a selector -- the selector without
+// the . or #, so that it converts back to the same class selector
+func cppSpecName(sel string) string {
+	if strings.HasPrefix(sel, ".") || strings.HasPrefix(sel, "#") {
+		sel = sel[1:]
+	}
+	return sel
+}
+
+// cppMember returns the top-level member name and value for the member at
+// index i in given sorted dotted paths, grouping all the paths within the
+// same struct member as C++ struct syntax {a=1: b={c=2: }: }, and the index
+// of the next member
+func cppMember(mems []string, vals map[string]string, i int) (string, string, int) {
+	di := strings.Index(mems[i], ".")
+	if di < 0 {
+		return mems[i], vals[mems[i]], i + 1
+	}
+	pfx := mems[i][:di+1]
+	var sub []string
+	svals := map[string]string{}
+	for ; i < len(mems) && strings.HasPrefix(mems[i], pfx); i++ {
+		sub = append(sub, mems[i][di+1:])
+		svals[mems[i][di+1:]] = vals[mems[i]]
+	}
+	var b strings.Builder
+	b.WriteString("{")
+	for si := 0; si < len(sub); {
+		var mem, val string
+		mem, val, si = cppMember(sub, svals, si)
+		b.WriteString(mem + "=" + val + ": ")
+	}
+	b.WriteString("}")
+	return pfx[:di], b.String(), i
+}
+
+// CppSheetString returns the params in given sheet as C++ emergent spec
+// objects, in the format of .proj and .spec files, with one spec of each
+// kind (layer, unit, con) per Sel, named for the selector.  Params that have
+// no C++ equivalent (see CppParams) are returned in unmapped.
+func CppSheetString(pars *params.Sheet) (str string, unmapped []string) {
+	var b bytes.Buffer
+	idx := 0
+	for _, sl := range *pars {
+		kvals := map[string]map[string]string{}
+		for path, val := range sl.Params {
+			cp := cppParamByPath(path)
+			if cp == nil {
+				unmapped = append(unmapped, sl.Sel+" "+path)
+				continue
+			}
+			if cp.Bool {
+				if val == "true" {
+					val = "1"
+				} else {
+					val = "0"
+				}
+			}
+			if kvals[cp.Spec] == nil {
+				kvals[cp.Spec] = map[string]string{}
+			}
+			kvals[cp.Spec][cp.Member] = val
+		}
+		for _, kind := range []string{"LeabraLayerSpec", "LeabraUnitSpec", "LeabraConSpec"} {
+			vals, ok := kvals[kind]
+			if !ok {
+				continue
+			}
+			mems := make([]string, 0, len(vals))
+			for mem := range vals {
+				mems = append(mems, mem)
+			}
+			sort.Strings(mems)
+			fmt.Fprintf(&b, "%v @.specs[%d] {\n", kind, idx)
+			fmt.Fprintf(&b, " name=\"%v\";\n", cppSpecName(sl.Sel))
+			fmt.Fprintf(&b, " desc=\"%v\";\n", strings.Replace(sl.Desc, `"`, `'`, -1))
+			for i := 0; i < len(mems); {
+				var mem, val string
+				mem, val, i = cppMember(mems, vals, i)
+				fmt.Fprintf(&b, " %v=%v;\n", mem, val)
+			}
+			b.WriteString("};\n")
+			idx++
+		}
+	}
+	sort.Strings(unmapped)
+	return b.String(), unmapped
+}
+
+// SaveCppParams saves the params in given sheet to a file as C++ emergent
+// spec objects (see CppSheetString) -- params without a C++ equivalent are
+// logged
+func SaveCppParams(pars *params.Sheet, filename gi.FileName) error {
+	str, unmapped := CppSheetString(pars)
+	if len(unmapped) > 0 {
+		log.Printf("SaveCppParams: params with no C++ equivalent not saved:\n\t%v\n", strings.Join(unmapped, "\n\t"))
+	}
+	err := ioutil.WriteFile(string(filename), []byte(str), 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+)
+
+const cppProjTest = `
+LeabraLayerSpec @.specs.gp[0][1] {
+ UserDataItem_List @*(.user_data_) {
+  name=;
+ };
+ name="HiddenLayer";
+ desc=;
+ unique{ };
+ lay_inhib={on=1: gi=1.9: ff=1: fb=1: fb_tau=1.4: };
+ avg_act={targ_init=0.2: fixed=0: };
+};
+SRAvgCaiSynDepConSpec @.specs.gp[0][2] {
+ name="SynDep";
+ lrate=0.02;
+ wt_scale={abs=1: rel=0.5: };
+};
+LeabraBiasSpec @.specs[3] {
+ name="Bias";
+ lrate=0.1;
+};
+`
+
+func TestCppParams(t *testing.T) {
+	specs := ParseCppSpecs(cppProjTest)
+	if len(specs) != 2 {
+		t.Fatalf("got %v specs, want 2 (bias spec skipped)\n", len(specs))
+	}
+	if specs[0].Name != "HiddenLayer" || specs[0].Vals["lay_inhib.gi"] != "1.9" {
+		t.Errorf("layer spec wrong: %+v\n", specs[0])
+	}
+	sh := CppSpecsSheet(specs)
+	if len(*sh) != 2 {
+		t.Fatalf("got %v sels, want 2\n", len(*sh))
+	}
+	lsl := (*sh)[0]
+	if lsl.Sel != ".HiddenLayer" || lsl.Params["Layer.Inhib.Layer.Gi"] != "1.9" ||
+		lsl.Params["Layer.Inhib.Layer.On"] != "true" || lsl.Params["Layer.Inhib.ActAvg.Init"] != "0.2" {
+		t.Errorf("layer sel wrong: %+v\n", lsl)
+	}
+	if csl := (*sh)[1]; csl.Params["Prjn.WtScale.Rel"] != "0.5" || csl.Params["Prjn.Learn.Lrate"] != "0.02" {
+		t.Errorf("con sel wrong: %+v\n", csl)
+	}
+
+	(*sh)[1].Params["Prjn.Learn.SRAvgCal.On"] = "true" // no C++ equivalent
+	str, unmapped := CppSheetString(sh)
+	if len(unmapped) != 1 {
+		t.Errorf("unmapped wrong: %v\n", unmapped)
+	}
+	sh2 := CppSpecsSheet(ParseCppSpecs(str))
+	if len(*sh2) != 2 {
+		t.Fatalf("round trip: got %v sels, want 2:\n%v\n", len(*sh2), str)
+	}
+	for si, sl := range *sh2 {
+		osl := (*sh)[si]
+		if sl.Sel != osl.Sel {
+			t.Errorf("round trip sel: %v != %v\n", sl.Sel, osl.Sel)
+		}
+		for path, val := range sl.Params {
+			if osl.Params[path] != val {
+				t.Errorf("round trip: %v %v: %v != %v\n", sl.Sel, path, val, osl.Params[path])
+			}
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+
+	"github.com/emer/etable/etable"
+)
+
+// Curriculum trains items in tiers of increasing difficulty: the items
+// are ranked by a difficulty metric (e.g., their overlap with the other
+// items) and divided into NTiers equal tiers, and training progresses
+// from the easiest tier to the next once performance on the items trained
+// stays at the criterion CritThr for CritEpcs epochs (or after MaxEpcs).
+// The order of each epoch is given by Order, as indexes of the items.
+type Curriculum struct {
+	On         bool      `desc:"if true, training follows the curriculum"`
+	Metric     string    `desc:"difficulty of each item: Overlap for the mean proportion of the active units of its Col pattern shared with each other item, or the name of a numeric column of the patterns"`
+	Col        string    `desc:"column of the patterns for the Overlap metric"`
+	NTiers     int       `min:"1" desc:"number of tiers of difficulty"`
+	Cumulative bool      `desc:"if true, each tier trains the items of all the tiers up to it, else only its own items"`
+	Ordered    bool      `desc:"if true, the items of each epoch are presented in order of increasing difficulty, else in a random order"`
+	CritThr    float64   `desc:"performance (e.g., proportion correct of the items trained in the epoch) at or above which the criterion is met"`
+	CritEpcs   int       `min:"1" desc:"number of consecutive epochs the criterion must be met to progress to the next tier"`
+	MaxEpcs    int       `desc:"if > 0, maximum number of epochs on a tier, after which training progresses to the next tier regardless"`
+	Difficulty []float64 `inactive:"+" desc:"difficulty of each item"`
+	Tiers      []int     `inactive:"+" desc:"tier of each item"`
+	Tier       int       `inactive:"+" desc:"current tier"`
+	TierEpcs   int       `inactive:"+" desc:"number of epochs trained on the current tier"`
+	NCrit      int       `inactive:"+" desc:"number of consecutive epochs the criterion has been met on the current tier"`
+}
+
+func (cu *Curriculum) Defaults() {
+	cu.Metric = "Overlap"
+	cu.Col = "Input"
+	cu.NTiers = 3
+	cu.Cumulative = true
+	cu.CritThr = 0.9
+	cu.CritEpcs = 1
+}
+
+// Init starts the curriculum from the first tier
+func (cu *Curriculum) Init() {
+	cu.Tier = 0
+	cu.TierEpcs = 0
+	cu.NCrit = 0
+}
+
+// SetDifficulty computes the Difficulty of each item of given view of the
+// patterns according to the Metric, and divides them into the Tiers
+func (cu *Curriculum) SetDifficulty(ix *etable.IdxView) error {
+	n := ix.Len()
+	cu.Difficulty = make([]float64, n)
+	if cu.Metric == "Overlap" {
+		col := ix.Table.ColByName(cu.Col)
+		if col == nil {
+			err := fmt.Errorf("Curriculum: column: %v not found for the Overlap metric", cu.Col)
+			log.Println(err)
+			return err
+		}
+		acts := make([][]int, n)
+		for i := range acts {
+			tsr := ix.Table.CellTensor(cu.Col, ix.Idxs[i])
+			for u := 0; u < tsr.Len(); u++ {
+				if tsr.FloatVal1D(u) > 0 {
+					acts[i] = append(acts[i], u)
+				}
+			}
+		}
+		for i, ai := range acts {
+			if len(ai) == 0 || n < 2 {
+				continue
+			}
+			sum := 0.0
+			for j, aj := range acts {
+				if j != i {
+					sum += float64(PatOverlap(ai, aj)) / float64(len(ai))
+				}
+			}
+			cu.Difficulty[i] = sum / float64(n-1)
+		}
+	} else {
+		col := ix.Table.ColByName(cu.Metric)
+		if col == nil {
+			err := fmt.Errorf("Curriculum: difficulty metric: %v is not Overlap or a column of the patterns", cu.Metric)
+			log.Println(err)
+			return err
+		}
+		for i := range cu.Difficulty {
+			cu.Difficulty[i] = col.FloatVal1D(ix.Idxs[i])
+		}
+	}
+	cu.SetTiers()
+	return nil
+}
+
+// SetTiers divides the items into NTiers tiers of equal size by the rank
+// of their Difficulty
+func (cu *Curriculum) SetTiers() {
+	n := len(cu.Difficulty)
+	nt := cu.NTiers
+	if nt < 1 {
+		nt = 1
+	}
+	rank := cu.ByDifficulty(nil)
+	cu.Tiers = make([]int, n)
+	for r, i := range rank {
+		cu.Tiers[i] = r * nt / n
+	}
+}
+
+// ByDifficulty returns given items (all if nil) sorted by increasing
+// Difficulty, with ties in the order of the items
+func (cu *Curriculum) ByDifficulty(items []int) []int {
+	if items == nil {
+		items = make([]int, len(cu.Difficulty))
+		for i := range items {
+			items[i] = i
+		}
+	}
+	srt := append([]int{}, items...)
+	sort.SliceStable(srt, func(a, b int) bool {
+		return cu.Difficulty[srt[a]] < cu.Difficulty[srt[b]]
+	})
+	return srt
+}
+
+// Items returns the items trained in the current tier
+func (cu *Curriculum) Items() []int {
+	var items []int
+	for i, t := range cu.Tiers {
+		if t == cu.Tier || (cu.Cumulative && t < cu.Tier) {
+			items = append(items, i)
+		}
+	}
+	return items
+}
+
+// Order returns the order of the items of the current tier for an epoch,
+// using given random number stream (global generator if nil) unless Ordered
+func (cu *Curriculum) Order(rnd *rand.Rand) []int {
+	items := cu.Items()
+	if cu.Ordered {
+		return cu.ByDifficulty(items)
+	}
+	perm := rand.Perm
+	if rnd != nil {
+		perm = rnd.Perm
+	}
+	ord := make([]int, len(items))
+	for i, pi := range perm(len(items)) {
+		ord[i] = items[pi]
+	}
+	return ord
+}
+
+// Update records the performance of an epoch on the current tier, and
+// progresses to the next tier if the criterion has been met for CritEpcs
+// epochs or after MaxEpcs -- returns true if it did
+func (cu *Curriculum) Update(perf float64) bool {
+	cu.TierEpcs++
+	if perf >= cu.CritThr {
+		cu.NCrit++
+	} else {
+		cu.NCrit = 0
+	}
+	if cu.Tier >= cu.NTiers-1 {
+		return false
+	}
+	if cu.NCrit >= cu.CritEpcs || (cu.MaxEpcs > 0 && cu.TierEpcs >= cu.MaxEpcs) {
+		cu.Tier++
+		cu.TierEpcs = 0
+		cu.NCrit = 0
+		return true
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestCurriculum(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, []int{6}, nil},
+	}, 3)
+	// item 0 shares one unit with each of the others, which share none
+	for i, units := range [][]int{{0, 1, 2}, {0, 3}, {1, 4}} {
+		for _, u := range units {
+			dt.CellTensor("Input", i).SetFloat1D(u, 1)
+		}
+	}
+	cu := &Curriculum{}
+	cu.Defaults()
+	if err := cu.SetDifficulty(etable.NewIdxView(dt)); err != nil {
+		t.Fatal(err)
+	}
+	if cu.Difficulty[0] != 1.0/3 || cu.Difficulty[1] != 0.25 || cu.Difficulty[2] != 0.25 {
+		t.Errorf("difficulty: %v\n", cu.Difficulty)
+	}
+	if cu.Tiers[1] != 0 || cu.Tiers[2] != 1 || cu.Tiers[0] != 2 {
+		t.Errorf("tiers: %v\n", cu.Tiers)
+	}
+	cu.Init()
+	cu.CritEpcs = 2
+	if ord := cu.Order(nil); len(ord) != 1 || ord[0] != 1 {
+		t.Errorf("first tier order: %v\n", ord)
+	}
+	if cu.Update(0.95) || cu.Update(0.5) || cu.Update(0.9) {
+		t.Errorf("progressed before 2 epochs at criterion\n")
+	}
+	if !cu.Update(1) || cu.Tier != 1 {
+		t.Errorf("did not progress after 2 epochs at criterion\n")
+	}
+	cu.Ordered = true
+	if ord := cu.Order(nil); len(ord) != 2 || ord[0] != 1 || ord[1] != 2 {
+		t.Errorf("cumulative second tier order: %v\n", ord)
+	}
+	cu.MaxEpcs = 1
+	cu.Update(0)
+	if cu.Tier != 2 || cu.Update(1) || cu.Update(1) {
+		t.Errorf("progress past the last tier or not after MaxEpcs: %d\n", cu.Tier)
+	}
+	cu.Metric = "Nope"
+	if cu.SetDifficulty(etable.NewIdxView(dt)) == nil {
+		t.Errorf("expected error for unknown metric\n")
+	}
+}
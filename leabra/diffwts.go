@@ -0,0 +1,205 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"gonum.org/v1/gonum/stat"
+)
+
+// SynDiff records the difference in weight for one synapse between two networks
+type SynDiff struct {
+	Prjn string  `desc:"name of projection, as Recv.Prjn"`
+	Si   int     `desc:"sending unit index"`
+	Ri   int     `desc:"receiving unit index"`
+	Wt   float32 `desc:"weight in this network"`
+	OWt  float32 `desc:"weight in the other network"`
+	Diff float32 `desc:"Wt - OWt"`
+}
+
+// DiffWts compares the synaptic weights of this network with those of the other
+// network, which must have the same layer and projection names (e.g., a Clone made
+// prior to sleep).  Projections that are not present in both networks are skipped.
+// Returns a summary table with one row per projection, containing the number of
+// synapses compared (N), mean |ΔWt| (MeanAbsDiff), max |ΔWt| (MaxAbsDiff), the
+// signed mean difference (MeanDiff), and the correlation between the two sets
+// of weights (Corr -- see WtCorr), and a second table listing the ntop (>= 0)
+// largest individual weight changes across the entire network, sorted by
+// decreasing |ΔWt|.
+func (nt *Network) DiffWts(on *Network, ntop int) (sum, top *etable.Table) {
+	sum = &etable.Table{}
+	sum.SetFromSchema(etable.Schema{
+		{"Prjn", etensor.STRING, nil, nil},
+		{"N", etensor.INT64, nil, nil},
+		{"MeanAbsDiff", etensor.FLOAT64, nil, nil},
+		{"MaxAbsDiff", etensor.FLOAT64, nil, nil},
+		{"MeanDiff", etensor.FLOAT64, nil, nil},
+		{"Corr", etensor.FLOAT64, nil, nil},
+	}, 0)
+	var all []SynDiff
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		oly := on.LayerByName(ly.Name())
+		if oly == nil {
+			continue
+		}
+		for _, p := range *ly.RecvPrjns() {
+			if p.IsOff() {
+				continue
+			}
+			opj := oly.RecvPrjns().SendName(p.SendLay().Name())
+			if opj == nil || opj.IsOff() {
+				continue
+			}
+			pj := p.(LeabraPrjn).AsLeabra()
+			pnm := ly.Name() + "." + pj.Name()
+			sds := pj.DiffWts(opj.(LeabraPrjn).AsLeabra(), pnm)
+			if len(sds) == 0 {
+				continue
+			}
+			wts := make([]float64, len(sds))
+			owts := make([]float64, len(sds))
+			sabs := 0.0
+			sdif := 0.0
+			mabs := 0.0
+			for i := range sds {
+				sd := &sds[i]
+				wts[i] = float64(sd.Wt)
+				owts[i] = float64(sd.OWt)
+				ad := math.Abs(float64(sd.Diff))
+				sabs += ad
+				sdif += float64(sd.Diff)
+				mabs = math.Max(mabs, ad)
+			}
+			n := float64(len(sds))
+			row := sum.Rows
+			sum.SetNumRows(row + 1)
+			sum.SetCellString("Prjn", row, pnm)
+			sum.SetCellFloat("N", row, n)
+			sum.SetCellFloat("MeanAbsDiff", row, sabs/n)
+			sum.SetCellFloat("MaxAbsDiff", row, mabs)
+			sum.SetCellFloat("MeanDiff", row, sdif/n)
+			sum.SetCellFloat("Corr", row, WtCorr(wts, owts))
+			all = append(all, sds...)
+		}
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return math.Abs(float64(all[i].Diff)) > math.Abs(float64(all[j].Diff))
+	})
+	if ntop > len(all) {
+		ntop = len(all)
+	}
+	if ntop < 0 {
+		ntop = 0
+	}
+	top = &etable.Table{}
+	top.SetFromSchema(etable.Schema{
+		{"Prjn", etensor.STRING, nil, nil},
+		{"Si", etensor.INT64, nil, nil},
+		{"Ri", etensor.INT64, nil, nil},
+		{"Wt", etensor.FLOAT64, nil, nil},
+		{"OWt", etensor.FLOAT64, nil, nil},
+		{"Diff", etensor.FLOAT64, nil, nil},
+	}, ntop)
+	for i := 0; i < ntop; i++ {
+		sd := &all[i]
+		top.SetCellString("Prjn", i, sd.Prjn)
+		top.SetCellFloat("Si", i, float64(sd.Si))
+		top.SetCellFloat("Ri", i, float64(sd.Ri))
+		top.SetCellFloat("Wt", i, float64(sd.Wt))
+		top.SetCellFloat("OWt", i, float64(sd.OWt))
+		top.SetCellFloat("Diff", i, float64(sd.Diff))
+	}
+	return
+}
+
+// WtCorr returns the correlation between two sets of weights, which is
+// undefined (NaN) if either set is constant (e.g., an untrained projection
+// with uniform initial weights) -- it is then 1 if the sets are identical,
+// and 0 otherwise
+func WtCorr(wts, owts []float64) float64 {
+	cor := stat.Correlation(wts, owts, nil)
+	if !math.IsNaN(cor) {
+		return cor
+	}
+	for i := range wts {
+		if wts[i] != owts[i] {
+			return 0
+		}
+	}
+	return 1
+}
+
+// DiffParams returns a listing of all the parameter lines that differ between
+// this network and the other network, for layers present in both, as generated
+// by AllParams -- empty if all params are the same.
+func (nt *Network) DiffParams(on *Network) string {
+	dif := ""
+	for _, ly := range nt.Layers {
+		oly := on.LayerByName(ly.Name())
+		if oly == nil {
+			dif += fmt.Sprintf("Layer: %v not found in other network: %v\n", ly.Name(), on.Nm)
+			continue
+		}
+		lps := strings.Split(ly.AllParams(), "\n")
+		olps := strings.Split(oly.AllParams(), "\n")
+		if len(lps) != len(olps) {
+			dif += fmt.Sprintf("Layer: %v number of param lines differs: %v != %v\n", ly.Name(), len(lps), len(olps))
+			continue
+		}
+		for i, lp := range lps {
+			if lp != olps[i] {
+				dif += fmt.Sprintf("%v:\n\t%v\n\t%v\n", ly.Name(), strings.TrimSpace(lp), strings.TrimSpace(olps[i]))
+			}
+		}
+	}
+	return dif
+}
+
+// DiffWts returns the weight differences for all synapses in this projection
+// that are also present in the other projection, which must connect layers of
+// the same sizes.  pnm is the projection name to record in the SynDiff.
+func (pj *Prjn) DiffWts(opj *Prjn, pnm string) []SynDiff {
+	slay := pj.Send.(LeabraLayer).AsLeabra()
+	ns := len(slay.Neurons)
+	if len(pj.RConN) != len(opj.RConN) || len(pj.SConN) != len(opj.SConN) {
+		log.Printf("leabra.Prjn DiffWts: %v layer sizes differ in other projection: %v\n", pj.String(), opj.String())
+		return nil
+	}
+	owts := make(map[int]float32, opj.Syns.Len())
+	for si := 0; si < ns; si++ {
+		st := opj.SConIdxSt[si]
+		syns := opj.Syns.Range(st, opj.SConN[si])
+		for ci := range syns {
+			ri := int(opj.SConIdx[st+int64(ci)])
+			owts[ri*ns+si] = syns[ci].Wt
+		}
+	}
+	sds := make([]SynDiff, 0, pj.Syns.Len())
+	for si := 0; si < ns; si++ {
+		st := pj.SConIdxSt[si]
+		syns := pj.Syns.Range(st, pj.SConN[si])
+		for ci := range syns {
+			ri := int(pj.SConIdx[st+int64(ci)])
+			ow, has := owts[ri*ns+si]
+			if !has {
+				continue
+			}
+			wt := syns[ci].Wt
+			sds = append(sds, SynDiff{Prjn: pnm, Si: si, Ri: ri, Wt: wt, OWt: ow, Diff: wt - ow})
+		}
+	}
+	return sds
+}
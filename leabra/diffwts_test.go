@@ -0,0 +1,58 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDiffWts(t *testing.T) {
+	net := ensembleNet()
+	cn := net.Clone("EnsNetClone")
+	pj := net.PrjnByName("InputToHidden")
+	owt := pj.SynVal("Wt", 1, 2)
+	pj.SetSynVal("Wt", 1, 2, owt+0.2)
+	pj.SetSynVal("Wt", 3, 0, pj.SynVal("Wt", 3, 0)-0.1)
+
+	sum, top := net.DiffWts(cn, 5)
+	if sum.Rows != 1 || sum.CellString("Prjn", 0) != "Hidden.InputToHidden" || sum.CellFloat("N", 0) != 16 {
+		t.Fatalf("summary: rows %v prjn %v N %v\n", sum.Rows, sum.CellString("Prjn", 0), sum.CellFloat("N", 0))
+	}
+	if mx := sum.CellFloat("MaxAbsDiff", 0); math.Abs(mx-0.2) > 1e-6 {
+		t.Errorf("MaxAbsDiff: %v, want 0.2\n", mx)
+	}
+	if md := sum.CellFloat("MeanDiff", 0); math.Abs(md-0.1/16) > 1e-6 {
+		t.Errorf("MeanDiff: %v, want %v\n", md, 0.1/16)
+	}
+	if cor := sum.CellFloat("Corr", 0); cor <= 0 || cor >= 1 {
+		t.Errorf("Corr: %v, want in (0, 1)\n", cor)
+	}
+	if top.Rows != 5 || top.CellFloat("Si", 0) != 1 || top.CellFloat("Ri", 0) != 2 || top.CellFloat("Si", 1) != 3 {
+		t.Errorf("top: rows %v first Si %v Ri %v second Si %v\n", top.Rows, top.CellFloat("Si", 0), top.CellFloat("Ri", 0), top.CellFloat("Si", 1))
+	}
+
+	if _, top := net.DiffWts(cn, -1); top.Rows != 0 {
+		t.Errorf("top rows for ntop -1: %v, want 0\n", top.Rows)
+	}
+	// constant weights: the correlation is defined
+	cpj := cn.PrjnByName("InputToHidden")
+	for si := 0; si < 4; si++ {
+		for ri := 0; ri < 4; ri++ {
+			pj.SetSynVal("Wt", si, ri, 0.5)
+			cpj.SetSynVal("Wt", si, ri, 0.5)
+		}
+	}
+	if sum, _ := net.DiffWts(cn, 0); sum.CellFloat("Corr", 0) != 1 {
+		t.Errorf("Corr of identical constant weights: %v, want 1\n", sum.CellFloat("Corr", 0))
+	}
+	pj.SetSynVal("Wt", 0, 0, 0.6)
+	if sum, _ := net.DiffWts(cn, 0); sum.CellFloat("Corr", 0) != 0 {
+		t.Errorf("Corr with constant other weights: %v, want 0\n", sum.CellFloat("Corr", 0))
+	}
+	if dif := net.DiffParams(cn); dif != "" {
+		t.Errorf("params of clone differ: %v\n", dif)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// DwellSeg is one visit to an attractor state during a sleep trial
+type DwellSeg struct {
+	State int `desc:"index of the state visited"`
+	Start int `desc:"cycle at which the visit started"`
+	Dwell int `desc:"number of cycles spent in the state"`
+}
+
+// AttractorDwell segments the activity of a layer during a sleep trial
+// into discrete attractor states: the activity is in a state while its
+// similarity with the previous activity (Layer.Sim, see LaySim) stays at or
+// above DipThr, and a dip below DipThr ends the visit.  Visits of at least
+// MinDwell cycles are clustered into states by the cosine of their mean
+// activity with that of the states found so far in the trial.  Log records
+// the number of visits and states, the dwell times, and the matrix of
+// transitions from one state to the next, for each sleep trial.
+type AttractorDwell struct {
+	Layer     string        `desc:"name of the layer whose activity is segmented"`
+	DipThr    float64       `desc:"similarity (Layer.Sim) below which the activity is in transition between states"`
+	MinDwell  int           `min:"1" desc:"minimum number of cycles above DipThr for a visit to a state to count"`
+	ClustSim  float64       `desc:"minimum cosine of the mean activity of a visit with that of a state for the visit to be to that state -- otherwise it is a new state"`
+	MaxStates int           `min:"1" desc:"maximum number of states in the transition matrix of the Results -- states beyond this are counted in the last one"`
+	Results   *etable.Table `view:"no-inline" desc:"one row per sleep trial: Run, Epoch, SleepTrial, NVisits, NStates, MeanDwell, MaxDwell, NTrans, and the Trans matrix [MaxStates, MaxStates] of transitions from (row) one state to (col) the next"`
+	Segs      []DwellSeg    `inactive:"+" desc:"visits to states in the current trial"`
+	Protos    [][]float64   `view:"-" desc:"mean activity of each state in the current trial"`
+	protoN    []int
+	sum       []float64
+	start     int
+	n         int
+	cyc       int
+}
+
+func (ad *AttractorDwell) Defaults() {
+	ad.Layer = "Hidden1"
+	ad.DipThr = 0.9
+	ad.MinDwell = 5
+	ad.ClustSim = 0.9
+	ad.MaxStates = 10
+}
+
+// Reset clears the states and visits, e.g., at the start of a sleep trial
+func (ad *AttractorDwell) Reset() {
+	ad.Segs = nil
+	ad.Protos = nil
+	ad.protoN = nil
+	ad.n = 0
+	ad.cyc = 0
+}
+
+// Cycle adds the current cycle of activity of the Layer in given network
+func (ad *AttractorDwell) Cycle(nt *Network) error {
+	lyi, err := nt.LayerByNameTry(ad.Layer)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	ly, ok := lyi.(*Layer)
+	if !ok {
+		err = fmt.Errorf("AttractorDwell: layer: %v is not a leabra.Layer", ad.Layer)
+		log.Println(err)
+		return err
+	}
+	if len(ad.sum) != len(ly.Neurons) {
+		ad.sum = make([]float64, len(ly.Neurons))
+		ad.n = 0
+	}
+	acts := make([]float32, len(ly.Neurons))
+	for ni := range ly.Neurons {
+		acts[ni] = ly.Neurons[ni].Act
+	}
+	ad.AddCycle(ly.Sim, acts)
+	return nil
+}
+
+// AddCycle adds a cycle with given similarity with the previous activity
+// and activity
+func (ad *AttractorDwell) AddCycle(sim float64, acts []float32) {
+	cyc := ad.cyc
+	ad.cyc++
+	if sim < ad.DipThr {
+		ad.endVisit()
+		return
+	}
+	if len(ad.sum) != len(acts) {
+		ad.sum = make([]float64, len(acts))
+		ad.n = 0
+	}
+	if ad.n == 0 {
+		ad.start = cyc
+		for i := range ad.sum {
+			ad.sum[i] = 0
+		}
+	}
+	for i, a := range acts {
+		ad.sum[i] += float64(a)
+	}
+	ad.n++
+}
+
+// endVisit ends the current visit, assigning it to a state if it is long
+// enough
+func (ad *AttractorDwell) endVisit() {
+	n := ad.n
+	ad.n = 0
+	if n == 0 || n < ad.MinDwell {
+		return
+	}
+	mean := make([]float64, len(ad.sum))
+	for i, s := range ad.sum {
+		mean[i] = s / float64(n)
+	}
+	st := -1
+	best := 0.0
+	for pi, pr := range ad.Protos {
+		if len(pr) != len(mean) {
+			continue
+		}
+		if cs := repCos(mean, pr, 0, 0); cs >= ad.ClustSim && (st < 0 || cs > best) {
+			st = pi
+			best = cs
+		}
+	}
+	if st < 0 {
+		st = len(ad.Protos)
+		ad.Protos = append(ad.Protos, mean)
+		ad.protoN = append(ad.protoN, 1)
+	} else { // running mean of the visits to the state
+		ad.protoN[st]++
+		pr := ad.Protos[st]
+		for i, m := range mean {
+			pr[i] += (m - pr[i]) / float64(ad.protoN[st])
+		}
+	}
+	ad.Segs = append(ad.Segs, DwellSeg{State: st, Start: ad.start, Dwell: n})
+}
+
+// Final ends the current visit, at the end of a sleep trial
+func (ad *AttractorDwell) Final() {
+	ad.endVisit()
+}
+
+// Trans returns the matrix of transitions from (first index) each state to
+// (second) the next, over the visits of the current trial -- the diagonal
+// counts returns to the same state after a dip
+func (ad *AttractorDwell) Trans() [][]int {
+	ns := len(ad.Protos)
+	tr := make([][]int, ns)
+	for i := range tr {
+		tr[i] = make([]int, ns)
+	}
+	for i := 1; i < len(ad.Segs); i++ {
+		tr[ad.Segs[i-1].State][ad.Segs[i].State]++
+	}
+	return tr
+}
+
+// ConfigResults configures the Results table
+func (ad *AttractorDwell) ConfigResults() {
+	if ad.Results == nil {
+		ad.Results = &etable.Table{}
+	}
+	if ad.MaxStates < 1 {
+		ad.MaxStates = 1
+	}
+	dt := ad.Results
+	dt.SetMetaData("name", "DwellLog")
+	dt.SetMetaData("desc", "attractor states visited in each sleep trial, with dwell times and transitions")
+	dt.SetFromSchema(etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"SleepTrial", etensor.INT64, nil, nil},
+		{"NVisits", etensor.INT64, nil, nil},
+		{"NStates", etensor.INT64, nil, nil},
+		{"MeanDwell", etensor.FLOAT64, nil, nil},
+		{"MaxDwell", etensor.FLOAT64, nil, nil},
+		{"NTrans", etensor.INT64, nil, nil},
+		{"Trans", etensor.FLOAT64, []int{ad.MaxStates, ad.MaxStates}, []string{"From", "To"}},
+	}, 0)
+}
+
+// Log calls Final and adds a row to the Results for the current trial --
+// returns the row
+func (ad *AttractorDwell) Log(run, epoch, trial int) int {
+	ad.Final()
+	if ad.Results == nil || ad.Results.ColByName("Trans") == nil {
+		ad.ConfigResults()
+	}
+	dt := ad.Results
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+	sum, max := 0, 0
+	for _, sg := range ad.Segs {
+		sum += sg.Dwell
+		if sg.Dwell > max {
+			max = sg.Dwell
+		}
+	}
+	mean := 0.0
+	if len(ad.Segs) > 0 {
+		mean = float64(sum) / float64(len(ad.Segs))
+	}
+	dt.SetCellFloat("Run", row, float64(run))
+	dt.SetCellFloat("Epoch", row, float64(epoch))
+	dt.SetCellFloat("SleepTrial", row, float64(trial))
+	dt.SetCellFloat("NVisits", row, float64(len(ad.Segs)))
+	dt.SetCellFloat("NStates", row, float64(len(ad.Protos)))
+	dt.SetCellFloat("MeanDwell", row, mean)
+	dt.SetCellFloat("MaxDwell", row, float64(max))
+	ms := dt.ColByName("Trans").Dim(1) // as configured
+	tsr := etensor.NewFloat64([]int{ms, ms}, nil, []string{"From", "To"})
+	ntr := 0
+	for i, tr := range ad.Trans() {
+		for j, c := range tr {
+			if c == 0 {
+				continue
+			}
+			if i != j {
+				ntr += c
+			}
+			fi, ti := i, j
+			if fi >= ms {
+				fi = ms - 1
+			}
+			if ti >= ms {
+				ti = ms - 1
+			}
+			tsr.Values[fi*ms+ti] += float64(c)
+		}
+	}
+	dt.SetCellFloat("NTrans", row, float64(ntr))
+	dt.SetCellTensor("Trans", row, tsr)
+	return row
+}
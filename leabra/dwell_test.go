@@ -0,0 +1,53 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/etable/etensor"
+)
+
+func TestAttractorDwell(t *testing.T) {
+	ad := &AttractorDwell{}
+	ad.Defaults()
+	ad.MaxStates = 3
+	pa := []float32{1, 0, 0, 0}
+	pb := []float32{0, 0, 1, 1}
+	visit := func(pat []float32, n int) {
+		for i := 0; i < n; i++ {
+			ad.AddCycle(1, pat)
+		}
+		ad.AddCycle(0.2, pat) // dip
+	}
+	ad.Reset()
+	visit(pa, 6)
+	visit(pb, 6)
+	visit(pa, 3) // too short to count
+	for i := 0; i < 7; i++ {
+		ad.AddCycle(1, pa)
+	}
+	row := ad.Log(0, 1, 2)
+	want := []DwellSeg{{0, 0, 6}, {1, 7, 6}, {0, 18, 7}}
+	if len(ad.Segs) != len(want) {
+		t.Fatalf("visits: %v, want: %v\n", ad.Segs, want)
+	}
+	for i, sg := range ad.Segs {
+		if sg != want[i] {
+			t.Errorf("visit %d: %v, want: %v\n", i, sg, want[i])
+		}
+	}
+	dt := ad.Results
+	if dt.CellFloat("NStates", row) != 2 || dt.CellFloat("NVisits", row) != 3 || dt.CellFloat("NTrans", row) != 2 {
+		t.Errorf("counts wrong: states %v visits %v trans %v\n", dt.CellFloat("NStates", row), dt.CellFloat("NVisits", row), dt.CellFloat("NTrans", row))
+	}
+	if dt.CellFloat("MaxDwell", row) != 7 || dt.CellFloat("SleepTrial", row) != 2 {
+		t.Errorf("MaxDwell: %v SleepTrial: %v\n", dt.CellFloat("MaxDwell", row), dt.CellFloat("SleepTrial", row))
+	}
+	tr := dt.CellTensor("Trans", row).(*etensor.Float64)
+	if tr.Value([]int{0, 1}) != 1 || tr.Value([]int{1, 0}) != 1 || tr.Value([]int{0, 0}) != 0 {
+		t.Errorf("transitions wrong: %v\n", tr.Values)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/chewxy/math32"
+)
+
+// DWtStats summarizes the learning in each projection of a network over a
+// period, e.g., an epoch, separately for each of a set of modes of learning,
+// e.g., Wake and Sleep: the sum over synapses of the absolute change in
+// linear weight (LWt), and the fraction of synapses whose absolute change
+// over the period exceeds Thr.  Changes are measured from the weights
+// themselves, between Begin and End, so any learning rule is covered.
+type DWtStats struct {
+	Modes []string `desc:"modes of learning, e.g., Wake, Sleep"`
+	Thr   float32  `desc:"threshold on the absolute change in LWt of a synapse over the period for it to count as changed"`
+	Prjns []string `inactive:"+" desc:"names of the projections, set by Init"`
+	pjs   []*Prjn
+	prev  [][]float32            // LWt of each prjn at Begin
+	acc   map[string][][]float32 // mode, prjn, synapse: absolute change since Reset
+}
+
+func (ds *DWtStats) Defaults() {
+	ds.Modes = []string{"Wake", "Sleep"}
+	ds.Thr = 0.001
+}
+
+// Init gets the projections of given network and resets the stats
+func (ds *DWtStats) Init(nt *Network) {
+	ds.Prjns = nil
+	ds.pjs = nil
+	for _, ly := range nt.Layers {
+		for _, p := range ly.(LeabraLayer).AsLeabra().RcvPrjns {
+			if p.IsOff() {
+				continue
+			}
+			pj := p.(LeabraPrjn).AsLeabra()
+			ds.Prjns = append(ds.Prjns, pj.Name())
+			ds.pjs = append(ds.pjs, pj)
+		}
+	}
+	ds.prev = make([][]float32, len(ds.pjs))
+	for pi, pj := range ds.pjs {
+		ds.prev[pi] = make([]float32, pj.Syns.Len())
+	}
+	ds.Reset()
+}
+
+// Reset zeros the accumulated changes, e.g., at the start of an epoch
+func (ds *DWtStats) Reset() {
+	if ds.acc == nil {
+		ds.acc = make(map[string][][]float32)
+	}
+	for _, md := range ds.Modes {
+		ac := ds.acc[md]
+		if len(ac) != len(ds.pjs) {
+			ac = make([][]float32, len(ds.pjs))
+			ds.acc[md] = ac
+		}
+		for pi, pj := range ds.pjs {
+			if int64(len(ac[pi])) != pj.Syns.Len() {
+				ac[pi] = make([]float32, pj.Syns.Len())
+				continue
+			}
+			for si := range ac[pi] {
+				ac[pi][si] = 0
+			}
+		}
+	}
+}
+
+// Begin records the current weights, before learning
+func (ds *DWtStats) Begin() {
+	for pi, pj := range ds.pjs {
+		pv := ds.prev[pi]
+		for c, syns := range pj.Syns.Chunks {
+			cpv := pv[pj.Syns.St[c]:]
+			for si := range syns {
+				cpv[si] = syns[si].LWt
+			}
+		}
+	}
+}
+
+// End accumulates the changes in the weights since Begin, for given mode
+func (ds *DWtStats) End(mode string) error {
+	ac, has := ds.acc[mode]
+	if !has {
+		err := fmt.Errorf("DWtStats: mode: %v not one of the Modes: %v", mode, ds.Modes)
+		log.Println(err)
+		return err
+	}
+	for pi, pj := range ds.pjs {
+		pv := ds.prev[pi]
+		pa := ac[pi]
+		for c, syns := range pj.Syns.Chunks {
+			st := pj.Syns.St[c]
+			cpv, cpa := pv[st:], pa[st:]
+			for si := range syns {
+				cpa[si] += math32.Abs(syns[si].LWt - cpv[si])
+			}
+		}
+	}
+	return nil
+}
+
+// SumDWt returns the sum over the synapses of given projection of the
+// absolute change in weight since Reset, for given mode
+func (ds *DWtStats) SumDWt(mode string, pi int) float64 {
+	sum := 0.0
+	for _, d := range ds.acc[mode][pi] {
+		sum += float64(d)
+	}
+	return sum
+}
+
+// FracDWt returns the fraction of the synapses of given projection whose
+// absolute change in weight since Reset is over Thr, for given mode
+func (ds *DWtStats) FracDWt(mode string, pi int) float64 {
+	pa := ds.acc[mode][pi]
+	if len(pa) == 0 {
+		return 0
+	}
+	n := 0
+	for _, d := range pa {
+		if d > ds.Thr {
+			n++
+		}
+	}
+	return float64(n) / float64(len(pa))
+}
+
+// AddLogItems adds items for the SumDWt and FracDWt of each projection for
+// each mode to given log, for given log mode and time scale, e.g., Train,
+// Epoch -- named as Prjn Mode SumDWt, e.g., Hidden1ToOutput Sleep SumDWt.
+// Init must have been called.
+func (ds *DWtStats) AddLogItems(lg *Logs, mode string, tm TimeScales) {
+	for _, md := range ds.Modes {
+		md := md
+		for pi, pnm := range ds.Prjns {
+			pi := pi
+			lg.AddFun(mode, tm, pnm+" "+md+" SumDWt", func(lg *Logs, row int) float64 { return ds.SumDWt(md, pi) })
+			lg.AddFun(mode, tm, pnm+" "+md+" FracDWt", func(lg *Logs, row int) float64 { return ds.FracDWt(md, pi) }).SetRange(0, 1)
+		}
+	}
+}
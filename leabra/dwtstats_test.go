@@ -0,0 +1,64 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestDWtStats(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "DWtNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	net.InitWts()
+
+	ds := &DWtStats{}
+	ds.Defaults()
+	ds.Init(net)
+	if len(ds.Prjns) != 1 || ds.Prjns[0] != "InputToHidden" {
+		t.Fatalf("prjns: %v\n", ds.Prjns)
+	}
+	pj := ds.pjs[0]
+	nsyn := int(pj.Syns.Len())
+	ds.Begin()
+	pj.Syns.At(0).LWt += 0.1
+	pj.Syns.At(1).LWt -= 0.2
+	if err := ds.End("Wake"); err != nil {
+		t.Fatal(err)
+	}
+	ds.Begin()
+	pj.Syns.At(0).LWt -= 0.1 // changed back: still counts as changed
+	ds.End("Wake")
+	if v := ds.SumDWt("Wake", 0); math.Abs(v-0.4) > 1.0e-5 {
+		t.Errorf("Wake SumDWt = %v, want .4\n", v)
+	}
+	if v := ds.FracDWt("Wake", 0); v != 2/float64(nsyn) {
+		t.Errorf("Wake FracDWt = %v, want %v\n", v, 2/float64(nsyn))
+	}
+	if v := ds.SumDWt("Sleep", 0); v != 0 {
+		t.Errorf("Sleep SumDWt = %v, want 0\n", v)
+	}
+	if err := ds.End("Dream"); err == nil {
+		t.Errorf("expected error for unknown mode\n")
+	}
+	ds.Reset()
+	if v := ds.SumDWt("Wake", 0); v != 0 {
+		t.Errorf("SumDWt after Reset = %v, want 0\n", v)
+	}
+
+	lg := &Logs{}
+	ds.AddLogItems(lg, "Train", Epoch)
+	if n := len(lg.ModeItems("Train", Epoch)); n != 4 {
+		t.Errorf("log items: %d, want 4\n", n)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"github.com/chewxy/math32"
+)
+
+// EarlyStop cuts the cycle loop of a quarter short once the network has
+// converged: when the maximum absolute change in activation (ActDel) over
+// the units of all layers stays below Thr for K consecutive cycles, after at
+// least MinCyc cycles of the quarter.  The number of cycles actually run in
+// each quarter of the current trial is recorded in QtrCycs, so that stats
+// that depend on the number of cycles can be interpreted.
+type EarlyStop struct {
+	On      bool    `desc:"if true, quarters are stopped early when converged -- otherwise the cycles are still counted"`
+	Thr     float32 `desc:"threshold on the maximum absolute ActDel of the units of all layers, below which the network is stable on a cycle"`
+	MinCyc  int     `min:"1" desc:"minimum number of cycles run in each quarter before it can be stopped"`
+	K       int     `min:"1" desc:"number of consecutive stable cycles for the network to have converged"`
+	QtrCycs [4]int  `inactive:"+" desc:"number of cycles run in each quarter of the current trial"`
+	NCyc    int     `inactive:"+" desc:"total number of cycles run on the current trial"`
+	nstable int
+}
+
+func (es *EarlyStop) Defaults() {
+	es.Thr = 0.005
+	es.MinCyc = 10
+	es.K = 3
+}
+
+// Init resets for the start of a trial
+func (es *EarlyStop) Init() {
+	es.QtrCycs = [4]int{}
+	es.NCyc = 0
+	es.nstable = 0
+}
+
+// Cycle records the cycle just run in given network, and returns true if
+// the rest of the current quarter should be skipped
+func (es *EarlyStop) Cycle(nt *Network, ltime *Time) bool {
+	if !es.On {
+		es.AddCycle(ltime.Quarter, 0)
+		return false
+	}
+	return es.AddCycle(ltime.Quarter, nt.MaxActDel())
+}
+
+// AddCycle records a cycle in given quarter with given maximum absolute
+// change in activation, and returns true if the quarter has converged
+func (es *EarlyStop) AddCycle(qtr int, maxDel float32) bool {
+	if qtr < 0 || qtr > 3 {
+		return false
+	}
+	if es.QtrCycs[qtr] == 0 {
+		es.nstable = 0
+	}
+	es.QtrCycs[qtr]++
+	es.NCyc++
+	if !es.On {
+		return false
+	}
+	if maxDel >= es.Thr {
+		es.nstable = 0
+		return false
+	}
+	es.nstable++
+	return es.nstable >= es.K && es.QtrCycs[qtr] >= es.MinCyc
+}
+
+// MaxActDel returns the maximum absolute change in activation (ActDel) over
+// the units of all the layers that are not off
+func (nt *Network) MaxActDel() float32 {
+	max := float32(0)
+	for _, l := range nt.Layers {
+		if l.IsOff() {
+			continue
+		}
+		ly := l.(LeabraLayer).AsLeabra()
+		for ni := range ly.Neurons {
+			nrn := &ly.Neurons[ni]
+			if nrn.IsOff() {
+				continue
+			}
+			if d := math32.Abs(nrn.ActDel); d > max {
+				max = d
+			}
+		}
+	}
+	return max
+}
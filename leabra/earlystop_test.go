@@ -0,0 +1,50 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import "testing"
+
+func TestEarlyStop(t *testing.T) {
+	es := &EarlyStop{}
+	es.Defaults()
+	es.On = true
+	es.MinCyc = 4
+	es.Init()
+	// stable from the start, but not stopped until MinCyc
+	stop := 0
+	for i := 0; i < 10; i++ {
+		if es.AddCycle(0, 0.001) {
+			stop = i + 1
+			break
+		}
+	}
+	if stop != 4 {
+		t.Errorf("quarter 0 stopped after: %v cycles, want 4\n", stop)
+	}
+	// stability does not carry over into the next quarter
+	stop = 0
+	for i, d := range []float32{0.001, 0.1, 0.05, 0.001, 0.001, 0.002, 0.001} {
+		if es.AddCycle(1, d) {
+			stop = i + 1
+			break
+		}
+	}
+	if stop != 6 {
+		t.Errorf("quarter 1 stopped after: %v cycles, want 6\n", stop)
+	}
+	if es.QtrCycs != [4]int{4, 6, 0, 0} || es.NCyc != 10 {
+		t.Errorf("cycles: %v total %v, want [4 6 0 0] total 10\n", es.QtrCycs, es.NCyc)
+	}
+	es.On = false
+	es.Init()
+	for i := 0; i < 5; i++ {
+		if es.AddCycle(2, 0) {
+			t.Errorf("stopped when not On\n")
+		}
+	}
+	if es.NCyc != 5 {
+		t.Errorf("cycles when not On: %v, want 5\n", es.NCyc)
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// Ensemble manages a set of independent copies of the same network, each initialized
+// with a different random seed, which are stepped in parallel across goroutines
+// (one per member network) through shared environments, with stats aggregated
+// across the members.  This is typically much faster than running the same number
+// of runs sequentially.  The typical pattern is:
+//
+//	ens := leabra.NewEnsemble(net, 10, 1)
+//	for { // over trials
+//		trainEnv.Step()
+//		ens.ApplyInputs(&trainEnv, "Input", "Output")
+//		ens.AlphaCyc(true)
+//		ens.Run(func(ni int, net *leabra.Network) {
+//			sse, _ := net.LayerByName("Output").(leabra.LeabraLayer).AsLeabra().MSE(.5)
+//			ens.SetStat(ni, "SSE", sse)
+//		})
+//	}
+//	agg := ens.AggStats()
+//
+// Note that the environments are shared (read-only) across members, and must only
+// be stepped from the controlling goroutine.  If the source network uses its own
+// random number streams (see SeedPrjnRnd, SeedLayerRnd), each member's streams
+// are reseeded from its Seed, so its noise is also exactly reproducible --
+// otherwise any random noise during processing draws from the global
+// (thread-safe) random number source, so only the initial weights are.
+type Ensemble struct {
+	Nets     []*Network           `desc:"the member networks -- independent clones of the source network"`
+	Seeds    []int64              `desc:"random seed used to initialize the weights of each member network"`
+	Times    []Time               `desc:"timing state for each member network"`
+	StatVals []map[string]float64 `desc:"most recent named stat values recorded for each member network via SetStat"`
+}
+
+// NewEnsemble returns a new ensemble of n copies of given source network, which must
+// already be built.  Each member network is initialized with seed + member index.
+func NewEnsemble(src *Network, n int, seed int64) *Ensemble {
+	en := &Ensemble{}
+	en.Nets = make([]*Network, n)
+	en.Seeds = make([]int64, n)
+	en.Times = make([]Time, n)
+	en.StatVals = make([]map[string]float64, n)
+	for ni := 0; ni < n; ni++ {
+		en.Nets[ni] = src.Clone(fmt.Sprintf("%v_%d", src.Nm, ni))
+		en.Seeds[ni] = seed + int64(ni)
+		en.Times[ni].Defaults()
+		en.StatVals[ni] = make(map[string]float64)
+	}
+	en.InitWts()
+	return en
+}
+
+// N returns the number of member networks
+func (en *Ensemble) N() int {
+	return len(en.Nets)
+}
+
+// InitWts initializes the weights of each member network using its own Seed --
+// this is done sequentially so that the initial weights are fully reproducible.
+// The per-projection and per-layer random number streams of the member, if in
+// use, are reseeded with its Seed, as otherwise all the members would have the
+// same weights and noise as the source.
+// Also resets the Time state and stats for each member.
+func (en *Ensemble) InitWts() {
+	for ni, nt := range en.Nets {
+		rand.Seed(en.Seeds[ni])
+		if nt.PrjnRnd {
+			nt.SeedPrjnRnd(en.Seeds[ni])
+		}
+		if nt.LayRnd {
+			nt.SeedLayerRnd(en.Seeds[ni])
+		}
+		nt.InitWts()
+		nt.InitSdEffWt()
+		en.Times[ni].Reset()
+		en.StatVals[ni] = make(map[string]float64)
+	}
+}
+
+// Run calls given function on each member network in parallel, one goroutine
+// per member, and waits for all of them to complete.
+func (en *Ensemble) Run(fun func(ni int, nt *Network)) {
+	var wg sync.WaitGroup
+	for ni, nt := range en.Nets {
+		wg.Add(1)
+		go func(ni int, nt *Network) {
+			fun(ni, nt)
+			wg.Done()
+		}(ni, nt)
+	}
+	wg.Wait()
+}
+
+// ApplyInputs applies the current state of the given environment to the named
+// layers of each member network, after calling InitExt.
+func (en *Ensemble) ApplyInputs(ev env.Env, lays ...string) {
+	pats := make([]etensor.Tensor, len(lays))
+	for li, lnm := range lays {
+		pats[li] = ev.State(lnm)
+	}
+	en.Run(func(ni int, nt *Network) {
+		nt.InitExt()
+		for li, lnm := range lays {
+			if pats[li] == nil {
+				continue
+			}
+			ly, err := nt.LayerByNameTry(lnm)
+			if err != nil {
+				continue
+			}
+			ly.(LeabraLayer).AsLeabra().ApplyExt(pats[li])
+		}
+	})
+}
+
+// AlphaCyc runs one standard alpha-cycle (4 quarters) of processing on each member
+// network in parallel, followed by learning if train is true.
+func (en *Ensemble) AlphaCyc(train bool) {
+	en.Run(func(ni int, nt *Network) {
+		ltime := &en.Times[ni]
+		nt.AlphaCycInit()
+		ltime.AlphaCycStart()
+		for qtr := 0; qtr < 4; qtr++ {
+			for cyc := 0; cyc < ltime.CycPerQtr; cyc++ {
+				nt.Cycle(ltime, false)
+				ltime.CycleInc()
+			}
+			nt.QuarterFinal(ltime)
+			ltime.QuarterInc()
+		}
+		if train {
+			nt.DWt()
+			nt.WtFmDWt()
+		}
+	})
+}
+
+// SetStat records a named stat value for given member network -- safe to call
+// from within Run as each member has its own map.
+func (en *Ensemble) SetStat(ni int, name string, val float64) {
+	en.StatVals[ni][name] = val
+}
+
+// StatNames returns the sorted list of all stat names recorded across members
+func (en *Ensemble) StatNames() []string {
+	nms := make(map[string]bool)
+	for _, sv := range en.StatVals {
+		for nm := range sv {
+			nms[nm] = true
+		}
+	}
+	sn := make([]string, 0, len(nms))
+	for nm := range nms {
+		sn = append(sn, nm)
+	}
+	sort.Strings(sn)
+	return sn
+}
+
+// StatsTable returns a table with one row per member network, with the Net index,
+// Seed, and a column for each recorded stat.
+func (en *Ensemble) StatsTable() *etable.Table {
+	snms := en.StatNames()
+	sch := etable.Schema{
+		{"Net", etensor.INT64, nil, nil},
+		{"Seed", etensor.INT64, nil, nil},
+	}
+	for _, nm := range snms {
+		sch = append(sch, etable.Column{nm, etensor.FLOAT64, nil, nil})
+	}
+	dt := &etable.Table{}
+	dt.SetFromSchema(sch, en.N())
+	for ni := range en.Nets {
+		dt.SetCellFloat("Net", ni, float64(ni))
+		dt.SetCellFloat("Seed", ni, float64(en.Seeds[ni]))
+		for _, nm := range snms {
+			dt.SetCellFloat(nm, ni, en.StatVals[ni][nm])
+		}
+	}
+	return dt
+}
+
+// AggStats returns a table with one row per recorded stat, with the Mean, standard
+// error of the mean (SEM), Min and Max across member networks.
+func (en *Ensemble) AggStats() *etable.Table {
+	snms := en.StatNames()
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Stat", etensor.STRING, nil, nil},
+		{"N", etensor.INT64, nil, nil},
+		{"Mean", etensor.FLOAT64, nil, nil},
+		{"SEM", etensor.FLOAT64, nil, nil},
+		{"Min", etensor.FLOAT64, nil, nil},
+		{"Max", etensor.FLOAT64, nil, nil},
+	}, len(snms))
+	for si, nm := range snms {
+		n := 0
+		sum := 0.0
+		min := math.Inf(1)
+		max := math.Inf(-1)
+		for _, sv := range en.StatVals {
+			v, has := sv[nm]
+			if !has {
+				continue
+			}
+			n++
+			sum += v
+			min = math.Min(min, v)
+			max = math.Max(max, v)
+		}
+		mean := 0.0
+		sem := 0.0
+		if n > 0 {
+			mean = sum / float64(n)
+		}
+		if n > 1 {
+			ss := 0.0
+			for _, sv := range en.StatVals {
+				if v, has := sv[nm]; has {
+					ss += (v - mean) * (v - mean)
+				}
+			}
+			sem = math.Sqrt(ss/float64(n-1)) / math.Sqrt(float64(n))
+		}
+		dt.SetCellString("Stat", si, nm)
+		dt.SetCellFloat("N", si, float64(n))
+		dt.SetCellFloat("Mean", si, mean)
+		dt.SetCellFloat("SEM", si, sem)
+		dt.SetCellFloat("Min", si, min)
+		dt.SetCellFloat("Max", si, max)
+	}
+	return dt
+}
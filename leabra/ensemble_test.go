@@ -0,0 +1,114 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/erand"
+	"github.com/emer/emergent/prjn"
+)
+
+// ensembleNet returns a small built network with random initial weights
+func ensembleNet() *Network {
+	net := &Network{}
+	net.InitName(net, "EnsNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	pj := net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward).(*Prjn)
+	net.Defaults()
+	pj.WtInit.Dist = erand.Uniform
+	pj.WtInit.Var = 0.25
+	net.Build()
+	net.InitWts()
+	return net
+}
+
+// ensWts returns the weights of the projection of given member network
+func ensWts(nt *Network) []float32 {
+	return nt.PrjnByName("InputToHidden").SynVals("Wt")
+}
+
+func TestEnsemble(t *testing.T) {
+	en := NewEnsemble(ensembleNet(), 3, 10)
+	if en.N() != 3 {
+		t.Fatalf("N: %v, want 3\n", en.N())
+	}
+	for ni, seed := range en.Seeds {
+		if seed != 10+int64(ni) {
+			t.Errorf("member %d seed: %v, want %v\n", ni, seed, 10+ni)
+		}
+	}
+	w0, w1 := ensWts(en.Nets[0]), ensWts(en.Nets[1])
+	same := true
+	for i := range w0 {
+		if w0[i] != w1[i] {
+			same = false
+		}
+	}
+	if same {
+		t.Errorf("members have the same initial weights\n")
+	}
+	en.InitWts() // reproducible from the seeds
+	if rw := ensWts(en.Nets[1]); rw[0] != w1[0] || rw[len(rw)-1] != w1[len(w1)-1] {
+		t.Errorf("re-initialized weights differ: %v != %v\n", rw, w1)
+	}
+
+	en.Run(func(ni int, nt *Network) {
+		en.SetStat(ni, "SSE", float64(ni))
+		if ni > 0 {
+			en.SetStat(ni, "Cor", 1)
+		}
+	})
+	if nms := en.StatNames(); len(nms) != 2 || nms[0] != "Cor" || nms[1] != "SSE" {
+		t.Errorf("stat names: %v\n", nms)
+	}
+	st := en.StatsTable()
+	if st.Rows != 3 || st.CellFloat("SSE", 2) != 2 || st.CellFloat("Seed", 2) != 12 {
+		t.Errorf("stats table: rows %v SSE %v Seed %v\n", st.Rows, st.CellFloat("SSE", 2), st.CellFloat("Seed", 2))
+	}
+	ag := en.AggStats()
+	// rows are sorted by stat: Cor, SSE
+	if ag.CellFloat("N", 0) != 2 || ag.CellFloat("Mean", 0) != 1 || ag.CellFloat("SEM", 0) != 0 {
+		t.Errorf("Cor agg: N %v Mean %v SEM %v\n", ag.CellFloat("N", 0), ag.CellFloat("Mean", 0), ag.CellFloat("SEM", 0))
+	}
+	if ag.CellFloat("Mean", 1) != 1 || ag.CellFloat("Min", 1) != 0 || ag.CellFloat("Max", 1) != 2 {
+		t.Errorf("SSE agg: Mean %v Min %v Max %v\n", ag.CellFloat("Mean", 1), ag.CellFloat("Min", 1), ag.CellFloat("Max", 1))
+	}
+	// SEM of 0, 1, 2 = sd 1 / sqrt(3)
+	if sem := ag.CellFloat("SEM", 1); sem < 0.577 || sem > 0.578 {
+		t.Errorf("SSE SEM: %v, want 0.577\n", sem)
+	}
+}
+
+func TestEnsembleRndStreams(t *testing.T) {
+	src := ensembleNet()
+	src.SeedPrjnRnd(1)
+	src.SeedLayerRnd(2)
+	en := NewEnsemble(src, 2, 10)
+	w0, w1 := ensWts(en.Nets[0]), ensWts(en.Nets[1])
+	same := true
+	for i := range w0 {
+		if w0[i] != w1[i] {
+			same = false
+		}
+	}
+	if same {
+		t.Errorf("members with prjn streams have the same initial weights\n")
+	}
+	n0 := en.Nets[0].LayerByName("Hidden").(*Layer).Rnd.Float64()
+	n1 := en.Nets[1].LayerByName("Hidden").(*Layer).Rnd.Float64()
+	if n0 == n1 {
+		t.Errorf("members have the same noise stream\n")
+	}
+	en.InitWts() // reproducible from the seeds
+	if rw := ensWts(en.Nets[1]); rw[0] != w1[0] || rw[len(rw)-1] != w1[len(w1)-1] {
+		t.Errorf("re-initialized weights differ: %v != %v\n", rw, w1)
+	}
+	if rn := en.Nets[0].LayerByName("Hidden").(*Layer).Rnd.Float64(); rn != n0 {
+		t.Errorf("re-initialized noise stream: %v, want %v\n", rn, n0)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package env
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etensor"
+)
+
+// Cue is the part of the pattern of one input layer presented by CueEnv
+type Cue struct {
+	Layer string  `desc:"name of the input layer"`
+	Frac  float32 `min:"0" max:"1" desc:"fraction of the units of the pattern presented -- the rest are set to 0"`
+}
+
+// CueEnv wraps an environment for cued recall and pattern completion: of
+// the Inputs, only the Cues are presented (all or a fraction of the units
+// of each), and the others are blanked (all 0), while all the other
+// elements, e.g., the targets for scoring the full recall of the output,
+// are passed through -- e.g., only the Ne and Po emotional cues, or half of
+// the Input units.
+type CueEnv struct {
+	env.Env
+	Inputs []string   `desc:"names of the input elements (layers) that are cued: those without a Cue are blanked"`
+	Cues   []Cue      `desc:"the parts of the Inputs that are presented -- none if empty"`
+	Fixed  bool       `desc:"if true, the units presented for a Frac < 1 are the first Frac of the units of the pattern (e.g., the top half of the Input), instead of a random subset on each trial"`
+	Rand   *rand.Rand `view:"-" desc:"random number stream for the subsets of units -- global generator if nil"`
+}
+
+func (ce *CueEnv) Defaults() {
+	ce.Inputs = []string{"Input", "Ne", "Po"}
+}
+
+// On returns true if the inputs are cued
+func (ce *CueEnv) On() bool {
+	return ce.Env != nil && len(ce.Cues) > 0
+}
+
+// SetString sets the Cues from specs separated by , each as Layer[:Frac],
+// e.g., "Ne,Po" for only the emotional cues, or "Input:0.5" for half of
+// the Input units -- the layers must be among the Inputs
+func (ce *CueEnv) SetString(specs string) error {
+	ce.Cues = nil
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		cu := Cue{Frac: 1}
+		parts := strings.Split(spec, ":")
+		cu.Layer = strings.TrimSpace(parts[0])
+		var err error
+		if len(parts) > 2 {
+			err = fmt.Errorf("too many fields")
+		} else if len(parts) == 2 {
+			var f float64
+			f, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 32)
+			if err == nil && (f < 0 || f > 1) {
+				err = fmt.Errorf("Frac must be 0-1")
+			}
+			cu.Frac = float32(f)
+		}
+		if err == nil && !ce.IsInput(cu.Layer) {
+			err = fmt.Errorf("layer: %v is not one of the Inputs: %v", cu.Layer, strings.Join(ce.Inputs, ", "))
+		}
+		if err != nil {
+			err = fmt.Errorf("CueEnv: expected Layer[:Frac] in: %v: %v", spec, err)
+			log.Println(err)
+			return err
+		}
+		ce.Cues = append(ce.Cues, cu)
+	}
+	return nil
+}
+
+// String returns the Cues as Layer:Frac separated by ,
+func (ce *CueEnv) String() string {
+	strs := make([]string, len(ce.Cues))
+	for i, cu := range ce.Cues {
+		strs[i] = fmt.Sprintf("%v:%g", cu.Layer, cu.Frac)
+	}
+	return strings.Join(strs, ",")
+}
+
+// IsInput returns true if the element with given name is one of the Inputs
+func (ce *CueEnv) IsInput(element string) bool {
+	for _, in := range ce.Inputs {
+		if in == element {
+			return true
+		}
+	}
+	return false
+}
+
+// Cue returns the cue for the element with given name, or nil if none
+func (ce *CueEnv) Cue(element string) *Cue {
+	for i := range ce.Cues {
+		if ce.Cues[i].Layer == element {
+			return &ce.Cues[i]
+		}
+	}
+	return nil
+}
+
+// State returns the state of given element of the Env: the cued part of
+// the pattern for the Inputs, and the full pattern for the others
+func (ce *CueEnv) State(element string) etensor.Tensor {
+	pat := ce.Env.State(element)
+	if pat == nil || len(ce.Cues) == 0 || !ce.IsInput(element) {
+		return pat
+	}
+	cu := ce.Cue(element)
+	if cu != nil && cu.Frac >= 1 {
+		return pat
+	}
+	cp := etensor.NewFloat32(pat.Shapes(), nil, nil)
+	if cu == nil || cu.Frac <= 0 {
+		return cp
+	}
+	n := len(cp.Values)
+	nkeep := int(math.Floor(float64(cu.Frac)*float64(n) + 0.5))
+	if ce.Fixed {
+		for i := 0; i < nkeep; i++ {
+			cp.Values[i] = float32(pat.FloatVal1D(i))
+		}
+		return cp
+	}
+	perm := rand.Perm
+	if ce.Rand != nil {
+		perm = ce.Rand.Perm
+	}
+	for _, i := range perm(n)[:nkeep] {
+		cp.Values[i] = float32(pat.FloatVal1D(i))
+	}
+	return cp
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package env
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestCueEnv(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Ne", etensor.FLOAT32, []int{3, 1}, nil},
+		{"Input", etensor.FLOAT32, []int{4, 4}, nil},
+		{"Output", etensor.FLOAT32, []int{4, 4}, nil},
+	}, 1)
+	dt.CellTensor("Ne", 0).SetFloat1D(0, 1)
+	for i := 0; i < 16; i++ {
+		dt.CellTensor("Input", 0).SetFloat1D(i, 1)
+		dt.CellTensor("Output", 0).SetFloat1D(i, 1)
+	}
+	ft := &env.FixedTable{}
+	ft.Table = etable.NewIdxView(dt)
+	ft.Init(0)
+	ft.Step()
+
+	ce := &CueEnv{Env: ft, Rand: rand.New(rand.NewSource(1))}
+	ce.Defaults()
+	sum := func(tsr etensor.Tensor) float64 {
+		s := 0.0
+		for i := 0; i < tsr.Len(); i++ {
+			s += tsr.FloatVal1D(i)
+		}
+		return s
+	}
+	if err := ce.SetString("Ne"); err != nil || !ce.On() {
+		t.Fatal(err)
+	}
+	if sum(ce.State("Ne")) != 1 || sum(ce.State("Input")) != 0 || sum(ce.State("Output")) != 16 {
+		t.Errorf("Ne cue: %v %v %v\n", sum(ce.State("Ne")), sum(ce.State("Input")), sum(ce.State("Output")))
+	}
+	ce.SetString("Input:0.5")
+	if sum(ce.State("Input")) != 8 || sum(ce.State("Ne")) != 0 {
+		t.Errorf("half Input cue: %v %v\n", sum(ce.State("Input")), sum(ce.State("Ne")))
+	}
+	ce.Fixed = true
+	in := ce.State("Input")
+	if in.FloatVal1D(7) != 1 || in.FloatVal1D(8) != 0 {
+		t.Errorf("fixed cue is not the first half\n")
+	}
+	if ce.String() != "Input:0.5" {
+		t.Errorf("String: %v\n", ce.String())
+	}
+	if ce.SetString("Output") == nil || ce.SetString("Input:2") == nil {
+		t.Errorf("expected errors for a non-input layer and Frac > 1\n")
+	}
+}
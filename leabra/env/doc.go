@@ -0,0 +1,12 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package env provides environments for leabra sims, beyond those of the
+emergent env package: SeqEnv (ordered sequences of patterns, with the
+SeqContext for sequence learning), ImageEnv (preprocessed image files),
+StreamEnv (rows read lazily from a file or network stream), and the
+wrappers CueEnv (partial cues) and NoisyEnv (noise added to the inputs).
+*/
+package env
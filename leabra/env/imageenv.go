@@ -0,0 +1,366 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package env
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif" // register the image formats
+	_ "image/jpeg"
+	_ "image/png"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etensor"
+)
+
+// ImageEnv is an environment that presents image files to an input layer:
+// each image is converted to grayscale, resized to X by Y pixels by
+// averaging, normalized to 0-1 if Norm, and if DoG filtered by a
+// difference of gaussians into on-center-off-surround and
+// off-center-on-surround channels, like the retina / LGN -- a 4D pattern
+// with a pool of the 2 channels for each pixel (Y, X, 1, 2), for a 4D
+// layer -- otherwise it is a 2D pattern (Y, X) of the pixels.  The images
+// are presented in a random order each epoch unless Sequential, as the
+// State of the Layer element.
+type ImageEnv struct {
+	Nm         string           `desc:"name of this environment"`
+	Dsc        string           `desc:"description of this environment"`
+	Files      []string         `desc:"the image files (any of png, jpeg, gif) -- see SetFiles"`
+	Layer      string           `desc:"name of the element (input layer) the images are presented as"`
+	X          int              `desc:"width of the images presented, in pixels"`
+	Y          int              `desc:"height of the images presented, in pixels"`
+	Norm       bool             `desc:"if true, the pixels of each image are normalized to 0-1 from its minimum to its maximum, and the DoG channels by their maximum"`
+	Invert     bool             `desc:"if true, the grayscale values are inverted, so dark pixels (e.g., drawn on a light background) are the active ones"`
+	DoG        bool             `desc:"if true, the images are filtered by a difference of gaussians into on- and off-center channels"`
+	CtrSig     float64          `desc:"standard deviation of the center gaussian of the DoG, in pixels"`
+	SurrSig    float64          `desc:"standard deviation of the surround gaussian of the DoG, in pixels"`
+	Gain       float32          `desc:"gain applied to the DoG outputs, which are clipped to 1"`
+	Sequential bool             `desc:"if true, the images are presented in order, else permuted each epoch"`
+	Run        env.Ctr          `view:"inline" desc:"current run of model as provided during Init"`
+	Epoch      env.Ctr          `view:"inline" desc:"number of times through the images"`
+	Trial      env.Ctr          `view:"inline" desc:"trial is the step counter within epoch"`
+	Order      []int            `inactive:"+" desc:"order of the images in the epoch"`
+	TrialName  string           `inactive:"+" desc:"name of the current image: its file name without the extension"`
+	Img        *etensor.Float32 `view:"-" desc:"the current preprocessed image"`
+}
+
+func (ie *ImageEnv) Name() string { return ie.Nm }
+func (ie *ImageEnv) Desc() string { return ie.Dsc }
+
+func (ie *ImageEnv) Defaults() {
+	ie.Layer = "Input"
+	ie.X = 5
+	ie.Y = 5
+	ie.Norm = true
+	ie.CtrSig = 0.5
+	ie.SurrSig = 1.5
+	ie.Gain = 4
+}
+
+// SetFiles sets the Files to those matching given glob pattern(s),
+// separated by , e.g., "images/*/*.png"
+func (ie *ImageEnv) SetFiles(pats string) error {
+	ie.Files = nil
+	for _, pat := range strings.Split(pats, ",") {
+		fs, err := filepath.Glob(strings.TrimSpace(pat))
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		ie.Files = append(ie.Files, fs...)
+	}
+	if len(ie.Files) == 0 {
+		err := fmt.Errorf("ImageEnv: %v: no image files match: %v", ie.Nm, pats)
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (ie *ImageEnv) Validate() error {
+	if len(ie.Files) == 0 || ie.X <= 0 || ie.Y <= 0 {
+		err := fmt.Errorf("ImageEnv: %v: Files and size X, Y must be set", ie.Nm)
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// FileName returns the name of given image: its file name without the
+// extension
+func (ie *ImageEnv) FileName(i int) string {
+	fn := filepath.Base(ie.Files[i])
+	return strings.TrimSuffix(fn, filepath.Ext(fn))
+}
+
+// FileCat returns the category of given image: the name of its directory
+func (ie *ImageEnv) FileCat(i int) string {
+	return filepath.Base(filepath.Dir(ie.Files[i]))
+}
+
+// Image opens and preprocesses given image
+func (ie *ImageEnv) Image(i int) (*etensor.Float32, error) {
+	f, err := os.Open(ie.Files[i])
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		err = fmt.Errorf("ImageEnv: %v: %v", ie.Files[i], err)
+		log.Println(err)
+		return nil, err
+	}
+	return ie.Preprocess(img), nil
+}
+
+// Preprocess returns given image preprocessed as configured
+func (ie *ImageEnv) Preprocess(img image.Image) *etensor.Float32 {
+	gray := ImageGray(img, ie.X, ie.Y)
+	if ie.Invert {
+		for i, v := range gray.Values {
+			gray.Values[i] = 1 - v
+		}
+	}
+	if ie.Norm {
+		NormMinMax(gray.Values)
+	}
+	if !ie.DoG {
+		return gray
+	}
+	dog := DoGFilter(gray, ie.CtrSig, ie.SurrSig, ie.Gain)
+	if ie.Norm {
+		mx := float32(0)
+		for _, v := range dog.Values {
+			if v > mx {
+				mx = v
+			}
+		}
+		if mx > 0 {
+			for i := range dog.Values {
+				dog.Values[i] /= mx
+			}
+		}
+	}
+	return dog
+}
+
+// ImageGray returns the grayscale (0-1) of given image resized to w by h
+// pixels, each the average of the pixels of the image it covers (or the
+// nearest pixel when enlarging), as a Y, X tensor
+func ImageGray(img image.Image, w, h int) *etensor.Float32 {
+	tsr := etensor.NewFloat32([]int{h, w}, nil, []string{"Y", "X"})
+	bd := img.Bounds()
+	sw, sh := bd.Dx(), bd.Dy()
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := (y + 1) * sh / h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := (x + 1) * sw / w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			sum := 0.0
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					r, g, b, _ := img.At(bd.Min.X+sx, bd.Min.Y+sy).RGBA()
+					sum += (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 0xffff
+				}
+			}
+			tsr.Values[y*w+x] = float32(sum / float64((y1-y0)*(x1-x0)))
+		}
+	}
+	return tsr
+}
+
+// NormMinMax normalizes given values to 0-1 from their minimum to their
+// maximum -- all 0 if they are all the same
+func NormMinMax(vals []float32) {
+	if len(vals) == 0 {
+		return
+	}
+	mn, mx := vals[0], vals[0]
+	for _, v := range vals {
+		if v < mn {
+			mn = v
+		}
+		if v > mx {
+			mx = v
+		}
+	}
+	for i, v := range vals {
+		if mx > mn {
+			vals[i] = (v - mn) / (mx - mn)
+		} else {
+			vals[i] = 0
+		}
+	}
+}
+
+// DoGFilter filters given Y, X image by a difference of gaussians of the
+// given center and surround standard deviations (pixels at the edges are
+// extended), returning a Y, X, 1, 2 tensor of the on-center-off-surround
+// (center - surround) and off-center-on-surround channels of each pixel,
+// times gain, rectified and clipped to 1
+func DoGFilter(img *etensor.Float32, ctrSig, surrSig float64, gain float32) *etensor.Float32 {
+	h, w := img.Dim(0), img.Dim(1)
+	ctr := gaussBlur(img.Values, w, h, ctrSig)
+	surr := gaussBlur(img.Values, w, h, surrSig)
+	dog := etensor.NewFloat32([]int{h, w, 1, 2}, nil, []string{"Y", "X", "PY", "PX"})
+	for i := range ctr {
+		d := gain * (ctr[i] - surr[i])
+		if d > 0 {
+			dog.Values[2*i] = float32(math.Min(float64(d), 1))
+		} else {
+			dog.Values[2*i+1] = float32(math.Min(float64(-d), 1))
+		}
+	}
+	return dog
+}
+
+// gaussBlur returns given w by h image blurred by a gaussian of given
+// standard deviation, in two separable passes with the edges extended
+func gaussBlur(vals []float32, w, h int, sig float64) []float32 {
+	if sig <= 0 {
+		return append([]float32{}, vals...)
+	}
+	rad := int(math.Ceil(3 * sig))
+	kern := make([]float32, 2*rad+1)
+	sum := float32(0)
+	for i := range kern {
+		d := float64(i - rad)
+		kern[i] = float32(math.Exp(-d * d / (2 * sig * sig)))
+		sum += kern[i]
+	}
+	for i := range kern {
+		kern[i] /= sum
+	}
+	clamp := func(v, n int) int {
+		if v < 0 {
+			return 0
+		}
+		if v >= n {
+			return n - 1
+		}
+		return v
+	}
+	tmp := make([]float32, len(vals))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			s := float32(0)
+			for k, kv := range kern {
+				s += kv * vals[y*w+clamp(x+k-rad, w)]
+			}
+			tmp[y*w+x] = s
+		}
+	}
+	out := make([]float32, len(vals))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			s := float32(0)
+			for k, kv := range kern {
+				s += kv * tmp[clamp(y+k-rad, h)*w+x]
+			}
+			out[y*w+x] = s
+		}
+	}
+	return out
+}
+
+func (ie *ImageEnv) Init(run int) {
+	ie.Run.Scale = env.Run
+	ie.Epoch.Scale = env.Epoch
+	ie.Trial.Scale = env.Trial
+	ie.Run.Init()
+	ie.Epoch.Init()
+	ie.Trial.Init()
+	ie.Run.Cur = run
+	ie.Trial.Max = len(ie.Files)
+	ie.Trial.Cur = -1 // init state -- key so that first Step() = 0
+	ie.Order = rand.Perm(len(ie.Files))
+	if ie.Sequential {
+		for i := range ie.Order {
+			ie.Order[i] = i
+		}
+	}
+}
+
+// Step presents the next image, permuting the order of the images at the
+// start of each epoch unless Sequential -- an image that cannot be opened
+// is presented as blank
+func (ie *ImageEnv) Step() bool {
+	ie.Epoch.Same()      // good idea to just reset all non-inner-most counters at start
+	if ie.Trial.Incr() { // if true, hit max, reset to 0
+		if !ie.Sequential {
+			ie.Order = rand.Perm(len(ie.Files))
+		}
+		ie.Epoch.Incr()
+	}
+	i := ie.Order[ie.Trial.Cur]
+	ie.TrialName = ie.FileName(i)
+	img, err := ie.Image(i)
+	if err != nil {
+		shp := []int{ie.Y, ie.X}
+		if ie.DoG {
+			shp = []int{ie.Y, ie.X, 1, 2}
+		}
+		img = etensor.NewFloat32(shp, nil, nil)
+	}
+	ie.Img = img
+	return true
+}
+
+func (ie *ImageEnv) Counters() []env.TimeScales {
+	return []env.TimeScales{env.Run, env.Epoch, env.Trial}
+}
+
+func (ie *ImageEnv) Counter(scale env.TimeScales) (cur, prv int, chg bool) {
+	switch scale {
+	case env.Run:
+		return ie.Run.Query()
+	case env.Epoch:
+		return ie.Epoch.Query()
+	case env.Trial:
+		return ie.Trial.Query()
+	}
+	return -1, -1, false
+}
+
+func (ie *ImageEnv) States() env.Elements {
+	if ie.DoG {
+		return env.Elements{{ie.Layer, []int{ie.Y, ie.X, 1, 2}, []string{"Y", "X", "PY", "PX"}}}
+	}
+	return env.Elements{{ie.Layer, []int{ie.Y, ie.X}, []string{"Y", "X"}}}
+}
+
+// State returns the current image for the Layer element
+func (ie *ImageEnv) State(element string) etensor.Tensor {
+	if element != ie.Layer || ie.Img == nil {
+		return nil
+	}
+	return ie.Img
+}
+
+func (ie *ImageEnv) Actions() env.Elements {
+	return nil
+}
+
+func (ie *ImageEnv) Action(element string, input etensor.Tensor) {
+	// nop
+}
+
+// Check that ImageEnv implements env.Env interface
+var _ env.Env = (*ImageEnv)(nil)
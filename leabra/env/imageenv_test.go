@@ -0,0 +1,78 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package env
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImageEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "imageenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.Mkdir(filepath.Join(dir, "Dot"), 0755)
+	// a bright 2x2 spot in the middle of a 10x10 image, and a blank one
+	for i, nm := range []string{"spot", "blank"} {
+		img := image.NewGray(image.Rect(0, 0, 10, 10))
+		if i == 0 {
+			for y := 4; y < 6; y++ {
+				for x := 4; x < 6; x++ {
+					img.SetGray(x, y, color.Gray{255})
+				}
+			}
+		}
+		f, err := os.Create(filepath.Join(dir, "Dot", nm+".png"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		png.Encode(f, img)
+		f.Close()
+	}
+	ie := &ImageEnv{Nm: "Images"}
+	ie.Defaults()
+	ie.Sequential = true
+	if err := ie.SetFiles(filepath.Join(dir, "*", "*.png")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ie.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	ie.Init(0)
+	ie.Step()
+	if ie.TrialName != "blank" || ie.FileCat(0) != "Dot" {
+		t.Errorf("first image: %v in %v\n", ie.TrialName, ie.FileCat(0))
+	}
+	ie.Step()
+	gray := ie.State("Input")
+	if ie.TrialName != "spot" || gray.FloatVal1D(12) != 1 || gray.FloatVal1D(0) != 0 {
+		t.Errorf("spot not resized to the center pixel: %v\n", gray)
+	}
+	if ie.Step(); ie.Epoch.Cur != 1 || ie.Trial.Cur != 0 {
+		t.Errorf("epoch not advanced: %d %d\n", ie.Epoch.Cur, ie.Trial.Cur)
+	}
+
+	ie.DoG = true
+	dog, err := ie.Image(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dog.NumDims() != 4 || dog.Values[2*12] != 1 || dog.Values[2*12+1] != 0 {
+		t.Errorf("center not on: %v\n", dog.Values[2*12:2*12+2])
+	}
+	if dog.Values[2*10+1] <= 0 {
+		t.Errorf("surround not off: %v\n", dog.Values[2*10:2*10+2])
+	}
+	if ie.SetFiles(filepath.Join(dir, "*.jpg")) == nil {
+		t.Errorf("expected error for no files\n")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package env
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/ki/kit"
+)
+
+// NoiseTypes are the types of noise that NoisyEnv adds to input patterns
+type NoiseTypes int32
+
+//go:generate stringer -type=NoiseTypes
+
+var KiT_NoiseTypes = kit.Enums.AddEnum(NoiseTypesN, false, nil)
+
+func (ev NoiseTypes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *NoiseTypes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// The noise types
+const (
+	// BitFlip flips each unit (active to 0, inactive to 1) with probability Level
+	BitFlip NoiseTypes = iota
+
+	// GaussNoise adds gaussian noise with standard deviation Level to each
+	// unit, clipped to 0-1
+	GaussNoise
+
+	// Occlude sets a random rectangular patch of the units (over the last
+	// two dimensions of the pattern), covering Level of them, to 0
+	Occlude
+
+	NoiseTypesN
+)
+
+// NoisyEnv wraps an environment, e.g., for testing, adding noise of a
+// given Type and Level to the input patterns of the Layers at presentation
+// time, so that the robustness of recall (e.g., before vs. after sleep) can
+// be measured -- State returns a new noisy copy of the pattern on each call.
+type NoisyEnv struct {
+	env.Env
+	Type   NoiseTypes `desc:"type of noise"`
+	Level  float32    `min:"0" desc:"noise level: probability of flipping each unit for BitFlip, standard deviation for GaussNoise, proportion of the units occluded for Occlude -- 0 for no noise"`
+	Layers []string   `desc:"names of the elements (input layers) whose patterns get noise -- the others are passed through"`
+	Rand   *rand.Rand `view:"-" desc:"random number stream for the noise -- global generator if nil"`
+}
+
+func (ne *NoisyEnv) Defaults() {
+	ne.Layers = []string{"Input"}
+}
+
+// On returns true if noise is added
+func (ne *NoisyEnv) On() bool {
+	return ne.Env != nil && ne.Level > 0
+}
+
+// SetString sets the Type and Level from a spec as Type:Level, e.g.,
+// "BitFlip:0.05", "GaussNoise:0.1" or "Occlude:0.25" (case insensitive)
+func (ne *NoisyEnv) SetString(spec string) error {
+	parts := strings.Split(spec, ":")
+	var err error
+	if len(parts) != 2 {
+		err = fmt.Errorf("NoisyEnv: expected Type:Level in: %v", spec)
+	} else {
+		tnm := strings.ToLower(strings.TrimSpace(parts[0]))
+		found := false
+		for nt := BitFlip; nt < NoiseTypesN; nt++ {
+			if strings.ToLower(nt.String()) == tnm {
+				ne.Type = nt
+				found = true
+			}
+		}
+		lev, perr := strconv.ParseFloat(strings.TrimSpace(parts[1]), 32)
+		switch {
+		case !found:
+			err = fmt.Errorf("NoisyEnv: noise type: %v not one of: BitFlip, GaussNoise, Occlude", parts[0])
+		case perr != nil || lev < 0:
+			err = fmt.Errorf("NoisyEnv: level must be a number >= 0 in: %v", spec)
+		default:
+			ne.Level = float32(lev)
+		}
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// IsNoisy returns true if the element with given name gets noise
+func (ne *NoisyEnv) IsNoisy(element string) bool {
+	for _, l := range ne.Layers {
+		if l == element {
+			return true
+		}
+	}
+	return false
+}
+
+// State returns the state of given element of the Env, with noise added if
+// it is one of the Layers
+func (ne *NoisyEnv) State(element string) etensor.Tensor {
+	pat := ne.Env.State(element)
+	if pat == nil || ne.Level <= 0 || !ne.IsNoisy(element) {
+		return pat
+	}
+	return ne.AddNoise(pat)
+}
+
+// AddNoise returns a copy of given pattern with the noise added
+func (ne *NoisyEnv) AddNoise(pat etensor.Tensor) *etensor.Float32 {
+	np := etensor.NewFloat32(pat.Shapes(), nil, nil)
+	for i := range np.Values {
+		np.Values[i] = float32(pat.FloatVal1D(i))
+	}
+	flt := rand.Float32
+	nrm := rand.NormFloat64
+	intn := rand.Intn
+	if ne.Rand != nil {
+		flt = ne.Rand.Float32
+		nrm = ne.Rand.NormFloat64
+		intn = ne.Rand.Intn
+	}
+	switch ne.Type {
+	case BitFlip:
+		for i, v := range np.Values {
+			if flt() < ne.Level {
+				if v > 0 {
+					np.Values[i] = 0
+				} else {
+					np.Values[i] = 1
+				}
+			}
+		}
+	case GaussNoise:
+		for i, v := range np.Values {
+			v += ne.Level * float32(nrm())
+			if v < 0 {
+				v = 0
+			} else if v > 1 {
+				v = 1
+			}
+			np.Values[i] = v
+		}
+	case Occlude:
+		shp := pat.Shapes()
+		ny, nx := 1, shp[len(shp)-1]
+		if len(shp) > 1 {
+			ny = shp[len(shp)-2]
+		}
+		lev := math.Min(float64(ne.Level), 1)
+		h := int(math.Ceil(math.Sqrt(lev) * float64(ny)))
+		w := 0
+		if h > 0 {
+			w = int(math.Floor(lev*float64(ny*nx)/float64(h) + 0.5))
+		}
+		if w > nx {
+			w = nx
+		}
+		y0 := intn(ny - h + 1)
+		x0 := intn(nx - w + 1)
+		for i := range np.Values {
+			y := (i / nx) % ny
+			x := i % nx
+			if y >= y0 && y < y0+h && x >= x0 && x < x0+w {
+				np.Values[i] = 0
+			}
+		}
+	}
+	return np
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package env
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestNoisyEnv(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, []int{4, 4}, nil},
+		{"Output", etensor.FLOAT32, []int{4, 4}, nil},
+	}, 1)
+	for i := 0; i < 16; i++ {
+		dt.CellTensor("Input", 0).SetFloat1D(i, 1)
+		dt.CellTensor("Output", 0).SetFloat1D(i, 1)
+	}
+	ft := &env.FixedTable{}
+	ft.Table = etable.NewIdxView(dt)
+	ft.Init(0)
+	ft.Step()
+
+	ne := &NoisyEnv{Env: ft, Rand: rand.New(rand.NewSource(1))}
+	ne.Defaults()
+	sum := func(tsr etensor.Tensor) float64 {
+		s := 0.0
+		for i := 0; i < tsr.Len(); i++ {
+			s += tsr.FloatVal1D(i)
+		}
+		return s
+	}
+	if err := ne.SetString("bitflip:1"); err != nil || ne.Type != BitFlip {
+		t.Fatalf("SetString: %v %v\n", err, ne.Type)
+	}
+	if s := sum(ne.State("Input")); s != 0 {
+		t.Errorf("all bits not flipped: %v\n", s)
+	}
+	if s := sum(ne.State("Output")); s != 16 {
+		t.Errorf("Output got noise: %v\n", s)
+	}
+	if s := sum(dt.CellTensor("Input", 0)); s != 16 {
+		t.Errorf("pattern modified: %v\n", s)
+	}
+	ne.SetString("Occlude:0.25")
+	occ := ne.State("Input")
+	if s := sum(occ); s != 12 {
+		t.Errorf("occluded: %v units, want 4\n", 16-s)
+	}
+	ne.SetString("GaussNoise:0.2")
+	if s := sum(ne.State("Input")); s == 16 || s < 8 {
+		t.Errorf("gaussian noise sum: %v\n", s)
+	}
+	ne.Level = 0
+	if ne.On() || sum(ne.State("Input")) != 16 {
+		t.Errorf("noise with Level 0\n")
+	}
+	if ne.SetString("salt:0.1") == nil {
+		t.Errorf("expected error for unknown noise type\n")
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package env
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+// SeqEnv is a FixedTable environment that presents ordered sequences of
+// patterns: contiguous rows of the Table with the same name in the SeqCol
+// column are one sequence, whose rows are always presented in order, while
+// the order of the sequences is permuted each epoch unless Sequential.
+// The sequence boundaries are exposed in SeqStart and SeqEnd, e.g., to reset
+// a SeqContext at the start of each sequence, and the sequence counter as
+// the env.Sequence Counter.  Without a SeqCol, each row is its own sequence,
+// as in FixedTable.
+type SeqEnv struct {
+	env.FixedTable
+	SeqCol   string  `desc:"column of the Table with the name of the sequence of each row -- contiguous rows with the same name are one sequence -- if empty, each row is its own sequence"`
+	Seq      env.Ctr `view:"inline" desc:"sequence counter within the epoch"`
+	SeqName  string  `inactive:"+" desc:"name of the current sequence"`
+	SeqTrial int     `inactive:"+" desc:"position of the current trial within its sequence"`
+	SeqStart bool    `inactive:"+" desc:"true if the current trial is the first of its sequence"`
+	SeqEnd   bool    `inactive:"+" desc:"true if the current trial is the last of its sequence"`
+	Seqs     [][]int `view:"-" desc:"the indexes into Table.Idxs of the rows of each sequence, in order"`
+	NPrv     int     `inactive:"+" desc:"number of trials in the previous epoch"`
+
+	// EpochOrder, if set, returns the Order of the trials of each epoch, as
+	// indexes into Table.Idxs, e.g., from a TrainSched, instead of the
+	// sequences -- the number of trials of the epoch is its length
+	EpochOrder func(epc int) []int `view:"-" json:"-"`
+
+	seqOf []int // sequence of each index into Table.Idxs
+}
+
+// Validate checks the Table and the SeqCol
+func (se *SeqEnv) Validate() error {
+	if err := se.FixedTable.Validate(); err != nil {
+		return err
+	}
+	if se.SeqCol != "" && se.Table.Table.ColByName(se.SeqCol) == nil {
+		err := fmt.Errorf("SeqEnv: %v: sequence column: %v not found", se.Nm, se.SeqCol)
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// Init initializes the environment for given run, with the sequences
+// found in the Table
+func (se *SeqEnv) Init(run int) {
+	se.FixedTable.Init(run)
+	se.Seq.Scale = env.Sequence
+	se.Seq.Init()
+	se.Seq.Cur = -1
+	se.ConfigSeqs()
+	se.OrderSeqs(false)
+}
+
+// ConfigSeqs finds the sequences of the rows of the Table
+func (se *SeqEnv) ConfigSeqs() {
+	n := se.Table.Len()
+	se.Seqs = nil
+	se.seqOf = make([]int, n)
+	var sc etensor.Tensor
+	if se.SeqCol != "" {
+		sc = se.Table.Table.ColByName(se.SeqCol)
+	}
+	prv := ""
+	for i := 0; i < n; i++ {
+		nm := ""
+		if sc != nil {
+			nm = sc.StringVal1D(se.Table.Idxs[i])
+		}
+		if sc == nil || i == 0 || nm != prv {
+			se.Seqs = append(se.Seqs, nil)
+		}
+		si := len(se.Seqs) - 1
+		se.Seqs[si] = append(se.Seqs[si], i)
+		se.seqOf[i] = si
+		prv = nm
+	}
+	se.Seq.Max = len(se.Seqs)
+}
+
+// OrderSeqs sets the Order of the trials to the rows of each sequence in
+// turn, with the sequences permuted if shuffle
+func (se *SeqEnv) OrderSeqs(shuffle bool) {
+	ns := len(se.Seqs)
+	perm := make([]int, ns)
+	if shuffle {
+		perm = rand.Perm(ns)
+	} else {
+		for i := range perm {
+			perm[i] = i
+		}
+	}
+	se.Order = se.Order[:0]
+	for _, si := range perm {
+		se.Order = append(se.Order, se.Seqs[si]...)
+	}
+}
+
+// Step advances to the next trial, permuting the order of the sequences
+// at the start of each epoch unless Sequential (or ordering the trials by
+// EpochOrder if set), and updates the sequence state
+func (se *SeqEnv) Step() bool {
+	se.FixedTable.Step()
+	if se.Trial.Cur == 0 {
+		se.NPrv = se.Trial.Max
+		ord := []int(nil)
+		if se.EpochOrder != nil {
+			ord = se.EpochOrder(se.Epoch.Cur)
+		}
+		if len(ord) > 0 {
+			se.Order = append(se.Order[:0], ord...)
+			se.Trial.Max = len(ord)
+		} else {
+			se.OrderSeqs(!se.Sequential)
+			if se.EpochOrder != nil {
+				se.Trial.Max = len(se.Order)
+			}
+		}
+		se.SetTrialName()
+	}
+	se.SetSeq()
+	return true
+}
+
+// SetSeq updates the sequence state for the current trial
+func (se *SeqEnv) SetSeq() {
+	tr := se.Trial.Cur
+	if tr < 0 || tr >= len(se.Order) {
+		return
+	}
+	si := se.seqOf[se.Order[tr]]
+	se.SeqStart = tr == 0 || se.seqOf[se.Order[tr-1]] != si
+	se.SeqEnd = tr == len(se.Order)-1 || se.seqOf[se.Order[tr+1]] != si
+	se.Seq.Prv = se.Seq.Cur
+	se.Seq.Chg = false
+	if tr == 0 {
+		se.Seq.Cur = 0
+		se.Seq.Chg = true
+	} else if se.SeqStart {
+		se.Seq.Cur++
+		se.Seq.Chg = true
+	}
+	if se.SeqStart {
+		se.SeqTrial = 0
+	} else {
+		se.SeqTrial++
+	}
+	se.SeqName = ""
+	if se.SeqCol != "" {
+		if col := se.Table.Table.ColByName(se.SeqCol); col != nil {
+			se.SeqName = col.StringVal1D(se.Table.Idxs[se.Order[tr]])
+		}
+	}
+}
+
+// Counter returns the sequence counter for env.Sequence, and otherwise
+// those of the FixedTable
+func (se *SeqEnv) Counter(scale env.TimeScales) (cur, prv int, chg bool) {
+	if scale == env.Sequence {
+		return se.Seq.Query()
+	}
+	return se.FixedTable.Counter(scale)
+}
+
+// Check that SeqEnv implements env.Env interface
+var _ env.Env = (*SeqEnv)(nil)
+
+// AddSeqEnv records the state of given sequence environment in given
+// checkpoint, under its name, including its sequence state
+func AddSeqEnv(cp *leabra.Checkpoint, se *SeqEnv) {
+	cp.AddFixedTable(&se.FixedTable)
+	es := cp.Envs[se.Nm]
+	es.Seq = se.Seq
+	es.SeqName = se.SeqName
+	es.SeqTrial = se.SeqTrial
+	es.SeqStart = se.SeqStart
+	es.SeqEnd = se.SeqEnd
+	es.NPrv = se.NPrv
+}
+
+// RestoreSeqEnv restores the state of given sequence environment from given
+// checkpoint, which must have been recorded with AddSeqEnv (see
+// Checkpoint.RestoreFixedTable)
+func RestoreSeqEnv(cp *leabra.Checkpoint, se *SeqEnv) error {
+	if err := cp.RestoreFixedTable(&se.FixedTable); err != nil {
+		return err
+	}
+	es := cp.Envs[se.Nm]
+	se.ConfigSeqs() // for the restored view
+	se.Seq = es.Seq
+	se.SeqName = es.SeqName
+	se.SeqTrial = es.SeqTrial
+	se.SeqStart = es.SeqStart
+	se.SeqEnd = es.SeqEnd
+	se.NPrv = es.NPrv
+	return nil
+}
+
+// SeqContext maintains a context for sequence learning: the activity of the
+// From layer at the end of each trial is applied as input to the To context
+// layer (an Input layer with the same number of units) on the next trial,
+// like the context of a simple recurrent network.  Reset clears the context,
+// e.g., at the start of each sequence.
+type SeqContext struct {
+	On   bool      `desc:"if true, the context is used"`
+	From string    `desc:"layer whose activity at the end of each trial is the context for the next"`
+	To   string    `desc:"context layer, an Input layer with the same number of units as From"`
+	Var  string    `desc:"variable of the From units that is the context, e.g., ActP or ActM"`
+	Hyst float32   `min:"0" max:"1" desc:"hysteresis: fraction of the previous context retained on each update, mixed with the rest of the new activity"`
+	Ctxt []float32 `view:"-" desc:"the current context"`
+}
+
+func (sc *SeqContext) Defaults() {
+	sc.From = "Hidden1"
+	sc.To = "Context"
+	sc.Var = "ActP"
+	sc.Hyst = 0
+}
+
+// Reset clears the context
+func (sc *SeqContext) Reset() {
+	for i := range sc.Ctxt {
+		sc.Ctxt[i] = 0
+	}
+}
+
+// Update updates the context from the activity of the From layer
+func (sc *SeqContext) Update(nt *leabra.Network) error {
+	fl, err := nt.LayerByNameTry(sc.From)
+	if err != nil {
+		return err
+	}
+	vals, err := fl.(leabra.LeabraLayer).AsLeabra().UnitValsTry(sc.Var)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if len(sc.Ctxt) != len(vals) {
+		sc.Ctxt = make([]float32, len(vals))
+	}
+	for i, v := range vals {
+		sc.Ctxt[i] = sc.Hyst*sc.Ctxt[i] + (1-sc.Hyst)*v
+	}
+	return nil
+}
+
+// Apply applies the context as the external input to the To layer -- call
+// after any InitExt
+func (sc *SeqContext) Apply(nt *leabra.Network) error {
+	tl, err := nt.LayerByNameTry(sc.To)
+	if err != nil {
+		return err
+	}
+	ly := tl.(leabra.LeabraLayer).AsLeabra()
+	if len(sc.Ctxt) != len(ly.Neurons) {
+		sc.Ctxt = make([]float32, len(ly.Neurons))
+	}
+	ly.ApplyExt1D32(sc.Ctxt)
+	return nil
+}
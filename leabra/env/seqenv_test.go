@@ -0,0 +1,156 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/env"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+func TestSeqEnv(t *testing.T) {
+	seqs := []string{"A", "A", "A", "B", "B", "C"}
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Seq", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, []int{2, 2}, nil},
+	}, len(seqs))
+	for row, sq := range seqs {
+		dt.SetCellString("Seq", row, sq)
+		dt.SetCellString("Name", row, sq+string('1'+rune(row)))
+	}
+	se := &SeqEnv{SeqCol: "Seq"}
+	se.Nm = "SeqEnv"
+	se.Table = etable.NewIdxView(dt)
+	if err := se.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	se.Init(0)
+	if len(se.Seqs) != 3 || se.Seq.Max != 3 {
+		t.Fatalf("sequences: %v\n", se.Seqs)
+	}
+	for epc := 0; epc < 3; epc++ {
+		nseq := 0
+		prv := ""
+		for trl := 0; trl < len(seqs); trl++ {
+			se.Step()
+			if se.SeqStart != (se.SeqName != prv) {
+				t.Errorf("epoch %d trial %d: SeqStart %v in sequence %v after %v\n", epc, trl, se.SeqStart, se.SeqName, prv)
+			}
+			if se.SeqStart {
+				nseq++
+			}
+			if se.Seq.Cur != nseq-1 {
+				t.Errorf("sequence counter: %d, want %d\n", se.Seq.Cur, nseq-1)
+			}
+			row := se.Table.Idxs[se.Order[se.Trial.Cur]]
+			if seqs[row] != se.SeqName || row-se.SeqTrial < 0 || seqs[row-se.SeqTrial] != se.SeqName {
+				t.Errorf("rows out of order: row %d at SeqTrial %d of %v\n", row, se.SeqTrial, se.SeqName)
+			}
+			if se.SeqEnd != (se.SeqName == "C" || se.SeqTrial == 2 || (se.SeqName == "B" && se.SeqTrial == 1)) {
+				t.Errorf("SeqEnd wrong at %v %d\n", se.SeqName, se.SeqTrial)
+			}
+			prv = se.SeqName
+		}
+		if nseq != 3 {
+			t.Errorf("epoch %d: %d sequences, want 3\n", epc, nseq)
+		}
+	}
+	if cur, _, _ := se.Counter(env.Sequence); cur != 2 {
+		t.Errorf("Counter(Sequence): %d, want 2\n", cur)
+	}
+
+	net := &leabra.Network{}
+	net.InitName(net, "SeqNet")
+	hid := net.AddLayer2D("Hidden1", 2, 2, emer.Hidden)
+	ctx := net.AddLayer2D("Context", 2, 2, emer.Input)
+	net.ConnectLayers(ctx, hid, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	net.InitWts()
+	sc := &SeqContext{}
+	sc.Defaults()
+	sc.Hyst = 0.5
+	hl := hid.(*leabra.Layer)
+	for ni := range hl.Neurons {
+		hl.Neurons[ni].ActP = 1
+	}
+	if err := sc.Update(net); err != nil {
+		t.Fatal(err)
+	}
+	if sc.Ctxt[3] != 0.5 {
+		t.Errorf("context: %v, want 0.5\n", sc.Ctxt[3])
+	}
+	if err := sc.Apply(net); err != nil {
+		t.Fatal(err)
+	}
+	if ext := ctx.(*leabra.Layer).Neurons[3].Ext; ext != 0.5 {
+		t.Errorf("context ext: %v, want 0.5\n", ext)
+	}
+	sc.Reset()
+	if sc.Ctxt[0] != 0 {
+		t.Errorf("context not reset\n")
+	}
+}
+
+func TestSeqEnvCheckpoint(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Seq", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, []int{2, 2}, nil},
+	}, 10)
+	for row := 0; row < dt.Rows; row++ {
+		dt.SetCellString("Name", row, fmt.Sprintf("p%d", row))
+		dt.SetCellString("Seq", row, fmt.Sprintf("s%d", row/2))
+	}
+	newEnv := func() *SeqEnv {
+		se := &SeqEnv{SeqCol: "Seq"}
+		se.Nm = "TrainEnv"
+		se.Table = etable.NewIdxView(dt)
+		se.Table.Idxs = []int{2, 3, 4, 5, 8, 9} // a subsample of whole sequences
+		se.Init(0)
+		return se
+	}
+	se := newEnv()
+	for i := 0; i < 3; i++ {
+		se.Step()
+	}
+	cp := &leabra.Checkpoint{Version: leabra.CheckpointVersion, Envs: make(map[string]*leabra.EnvState)}
+	AddSeqEnv(cp, se)
+	var b bytes.Buffer
+	if err := cp.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	rcp := &leabra.Checkpoint{}
+	if err := rcp.Read(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	re := newEnv()
+	re.Table.Idxs = []int{0, 1, 2, 3, 4, 5} // a different subsample before the restore
+	re.Init(0)
+	if err := RestoreSeqEnv(rcp, re); err != nil {
+		t.Fatal(err)
+	}
+	if re.Trial.Cur != se.Trial.Cur || re.TrialName != se.TrialName || re.Seq.Cur != se.Seq.Cur {
+		t.Errorf("restored env at trial %d %v, want %d %v\n", re.Trial.Cur, re.TrialName, se.Trial.Cur, se.TrialName)
+	}
+	for se.Trial.Cur < se.Trial.Max-1 {
+		se.Step()
+		re.Step()
+		if re.TrialName != se.TrialName || re.SeqTrial != se.SeqTrial {
+			t.Errorf("restored env trial %d: %v, want %v\n", re.Trial.Cur, re.TrialName, se.TrialName)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package env
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// StreamEnv is an environment that reads its trials lazily, one row at a
+// time, from a text stream of rows of patterns, instead of loading a whole
+// table into memory, for online / continual learning from effectively
+// unbounded data.  The Source is a CSV or tab-separated file (optionally
+// gzipped, with a .gz extension), or a network connection given as
+// tcp://host:port or unix://path from which a server writes rows.  The
+// first line is a header naming the column of each field, as saved by
+// etable (e.g., _H: $Name %Input[2:0,0]<2:5,5> %Input[2:0,1] ..) or
+// plain names, where the successive fields of a column fill the successive
+// cells of its tensor in the Schema -- fields of columns not in the Schema
+// are skipped.  Only the current row is held, in Cur, and ReadRows reads
+// rows into a table, e.g., to train on a buffer of the stream.
+type StreamEnv struct {
+	Nm        string        `desc:"name of this environment"`
+	Dsc       string        `desc:"description of this environment"`
+	Source    string        `desc:"file (CSV or tab-separated, .gz for gzipped) or network address (tcp://host:port or unix://path) of the stream of rows"`
+	Schema    etable.Schema `view:"-" desc:"columns of the rows: names, types and cell shapes -- e.g., leabra.SchemaOf a table of patterns"`
+	Loop      bool          `desc:"if true, a file Source is reopened at its end, starting a new epoch unless EpochLen is set -- otherwise (and for network sources) the env is Done at the end of the stream"`
+	EpochLen  int           `desc:"if > 0, number of trials per epoch -- otherwise an epoch is a pass through a file Source"`
+	Run       env.Ctr       `view:"inline" desc:"current run of model as provided during Init"`
+	Epoch     env.Ctr       `view:"inline" desc:"number of times through the Source, or of EpochLen trials"`
+	Trial     env.Ctr       `view:"inline" desc:"trial within the epoch"`
+	TrialName string        `inactive:"+" desc:"Name of the current trial, if there is a Name column"`
+	NRead     int           `inactive:"+" desc:"total number of rows read since Init"`
+	Pass      int           `inactive:"+" desc:"number of times a Loop file Source has been reopened since Init"`
+	Done      bool          `inactive:"+" desc:"true at the end of the stream"`
+	Cur       *etable.Table `view:"-" desc:"the current row, with the Schema"`
+
+	rd      *bufio.Reader
+	closers []io.Closer
+	delim   string
+	dataPfx bool          // rows start with a _D: field
+	fields  []streamField // column and cell of each field of a row
+}
+
+// streamField is the column of the Schema (-1 if none) and the cell within
+// it of a field of the rows of a StreamEnv
+type streamField struct {
+	col  int
+	cell int
+}
+
+func (se *StreamEnv) Name() string { return se.Nm }
+func (se *StreamEnv) Desc() string { return se.Dsc }
+
+// Validate checks the Source and Schema
+func (se *StreamEnv) Validate() error {
+	if se.Source == "" || len(se.Schema) == 0 {
+		err := fmt.Errorf("StreamEnv: %v: Source and Schema must be set", se.Nm)
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// IsNet returns true if the Source is a network address
+func (se *StreamEnv) IsNet() bool {
+	return strings.HasPrefix(se.Source, "tcp://") || strings.HasPrefix(se.Source, "unix://")
+}
+
+// Init (re)opens the stream from its start for given run
+func (se *StreamEnv) Init(run int) {
+	se.Run.Scale = env.Run
+	se.Epoch.Scale = env.Epoch
+	se.Trial.Scale = env.Trial
+	se.Run.Init()
+	se.Epoch.Init()
+	se.Trial.Init()
+	se.Run.Cur = run
+	se.Trial.Cur = -1 // init state -- key so that first Step() = 0
+	se.NRead = 0
+	se.Pass = 0
+	se.Done = false
+	se.TrialName = ""
+	se.Cur = &etable.Table{}
+	se.Cur.SetFromSchema(se.Schema, 1)
+	if err := se.Open(); err != nil {
+		se.Done = true
+	}
+}
+
+// Open opens the Source and reads its header
+func (se *StreamEnv) Open() error {
+	se.Close()
+	var r io.Reader
+	if se.IsNet() {
+		i := strings.Index(se.Source, "://")
+		conn, err := net.Dial(se.Source[:i], se.Source[i+3:])
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		se.closers = append(se.closers, conn)
+		r = conn
+	} else {
+		f, err := os.Open(se.Source)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		se.closers = append(se.closers, f)
+		r = f
+		if strings.HasSuffix(se.Source, ".gz") {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				log.Println(err)
+				se.Close()
+				return err
+			}
+			se.closers = append(se.closers, gz)
+			r = gz
+		}
+	}
+	se.rd = bufio.NewReader(r)
+	hdr, err := se.readLine()
+	if err != nil {
+		err = fmt.Errorf("StreamEnv: %v: no header in: %v: %v", se.Nm, se.Source, err)
+		log.Println(err)
+		se.Close()
+		return err
+	}
+	se.SetHeader(hdr)
+	return nil
+}
+
+// Close closes the Source
+func (se *StreamEnv) Close() {
+	for i := len(se.closers) - 1; i >= 0; i-- {
+		se.closers[i].Close()
+	}
+	se.closers = nil
+	se.rd = nil
+}
+
+// SetHeader maps the fields of the rows to the columns of the Schema and
+// their cells from given header line
+func (se *StreamEnv) SetHeader(hdr string) {
+	se.delim = ","
+	if strings.Contains(hdr, "\t") {
+		se.delim = "\t"
+	}
+	hs := strings.Split(hdr, se.delim)
+	se.dataPfx = len(hs) > 0 && strings.HasPrefix(hs[0], "_H:")
+	if se.dataPfx {
+		hs = hs[1:]
+	}
+	ncell := make([]int, len(se.Schema))
+	se.fields = make([]streamField, len(hs))
+	for fi, h := range hs {
+		nm := strings.Trim(strings.TrimSpace(h), "\"")
+		nm = strings.TrimLeft(nm, "$%#^")
+		if i := strings.IndexAny(nm, "[<"); i >= 0 {
+			nm = nm[:i]
+		}
+		se.fields[fi] = streamField{col: -1}
+		for ci, sc := range se.Schema {
+			if sc.Name == nm {
+				se.fields[fi] = streamField{col: ci, cell: ncell[ci]}
+				ncell[ci]++
+				break
+			}
+		}
+	}
+}
+
+// readLine returns the next non-empty line of the stream
+func (se *StreamEnv) readLine() (string, error) {
+	if se.rd == nil {
+		return "", io.EOF
+	}
+	for {
+		line, err := se.rd.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line != "" {
+			return line, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// ReadRows reads up to given number of rows of the stream into the rows of
+// given table with the Schema (from row 0), reopening a Loop file Source at
+// its end, and returns the number of rows read -- fewer at the end of the
+// stream, when Done is set
+func (se *StreamEnv) ReadRows(dt *etable.Table, n int) (int, error) {
+	for r := 0; r < n; r++ {
+		line, err := se.readLine()
+		if err == io.EOF && se.Loop && !se.IsNet() && se.rd != nil {
+			if err = se.Open(); err != nil {
+				se.Done = true
+				return r, err
+			}
+			se.Pass++
+			line, err = se.readLine()
+		}
+		if err != nil {
+			se.Done = true
+			if err == io.EOF {
+				return r, nil
+			}
+			log.Println(err)
+			return r, err
+		}
+		se.SetRow(dt, r, line)
+		se.NRead++
+	}
+	return n, nil
+}
+
+// SetRow sets given row of given table with the Schema from given line of
+// the stream -- string columns take the field as is (without quotes), and
+// the others parse it as a number, 0 if not one
+func (se *StreamEnv) SetRow(dt *etable.Table, row int, line string) {
+	fs := strings.Split(line, se.delim)
+	if se.dataPfx && len(fs) > 0 && strings.HasPrefix(fs[0], "_D:") {
+		fs = fs[1:]
+	}
+	for fi, f := range fs {
+		if fi >= len(se.fields) {
+			break
+		}
+		sf := se.fields[fi]
+		if sf.col < 0 {
+			continue
+		}
+		sc := se.Schema[sf.col]
+		f = strings.TrimSpace(f)
+		if sc.Type == etensor.STRING {
+			dt.SetCellString(sc.Name, row, strings.Trim(f, "\""))
+			continue
+		}
+		v, _ := strconv.ParseFloat(f, 64)
+		if len(sc.CellShape) == 0 {
+			dt.SetCellFloat(sc.Name, row, v)
+		} else if tsr := dt.CellTensor(sc.Name, row); sf.cell < tsr.Len() {
+			tsr.SetFloat1D(sf.cell, v)
+		}
+	}
+}
+
+// Step reads the next row of the stream into Cur -- returns false at the
+// end of the stream
+func (se *StreamEnv) Step() bool {
+	se.Epoch.Same() // good idea to just reset all non-inner-most counters at start
+	if se.Done {
+		return false
+	}
+	pass := se.Pass
+	if n, _ := se.ReadRows(se.Cur, 1); n == 0 {
+		return false
+	}
+	se.Trial.Prv = se.Trial.Cur
+	se.Trial.Cur++
+	se.Trial.Chg = true
+	if (se.EpochLen > 0 && se.Trial.Cur >= se.EpochLen) || (se.EpochLen <= 0 && se.Pass != pass) {
+		se.Trial.Cur = 0
+		se.Epoch.Incr()
+	}
+	if se.Cur.ColByName("Name") != nil {
+		se.TrialName = se.Cur.CellString("Name", 0)
+	}
+	return true
+}
+
+func (se *StreamEnv) Counters() []env.TimeScales {
+	return []env.TimeScales{env.Run, env.Epoch, env.Trial}
+}
+
+func (se *StreamEnv) Counter(scale env.TimeScales) (cur, prv int, chg bool) {
+	switch scale {
+	case env.Run:
+		return se.Run.Query()
+	case env.Epoch:
+		return se.Epoch.Query()
+	case env.Trial:
+		return se.Trial.Query()
+	}
+	return -1, -1, false
+}
+
+// States returns the columns of the Schema other than strings
+func (se *StreamEnv) States() env.Elements {
+	var els env.Elements
+	for _, sc := range se.Schema {
+		if sc.Type == etensor.STRING {
+			continue
+		}
+		els = append(els, env.Element{sc.Name, sc.CellShape, sc.DimNames})
+	}
+	return els
+}
+
+// State returns the pattern of given column of the current row
+func (se *StreamEnv) State(element string) etensor.Tensor {
+	if se.Cur == nil || se.Cur.ColByName(element) == nil {
+		return nil
+	}
+	return se.Cur.CellTensor(element, 0)
+}
+
+func (se *StreamEnv) Actions() env.Elements {
+	return nil
+}
+
+func (se *StreamEnv) Action(element string, input etensor.Tensor) {
+	// nop
+}
+
+// Check that StreamEnv implements env.Env interface
+var _ env.Env = (*StreamEnv)(nil)
@@ -0,0 +1,101 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package env
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+	"github.com/goki/gi/gi"
+)
+
+func TestStreamEnv(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, []int{2, 2}, []string{"Y", "X"}},
+	}, 3)
+	for row := 0; row < 3; row++ {
+		dt.SetCellString("Name", row, fmt.Sprintf("p%d", row))
+		dt.CellTensor("Input", row).SetFloat1D(row, 1)
+	}
+	dir, err := ioutil.TempDir("", "streamenv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fnm := filepath.Join(dir, "pats.dat")
+	if err := dt.SaveCSV(gi.FileName(fnm), etable.Tab, true); err != nil {
+		t.Fatal(err)
+	}
+
+	se := &StreamEnv{Nm: "Stream", Source: fnm, Schema: leabra.SchemaOf(dt), Loop: true}
+	if err := se.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	se.Init(0)
+	for i := 0; i < 7; i++ {
+		if !se.Step() {
+			t.Fatalf("stream ended at step %d\n", i)
+		}
+		row := i % 3
+		if se.TrialName != fmt.Sprintf("p%d", row) || se.State("Input").FloatVal1D(row) != 1 {
+			t.Errorf("step %d: row %v, want p%d\n", i, se.TrialName, row)
+		}
+		if cur, _, _ := se.Counter(env.Trial); cur != row {
+			t.Errorf("step %d: trial %d\n", i, cur)
+		}
+		if cur, _, _ := se.Counter(env.Epoch); cur != i/3 {
+			t.Errorf("step %d: epoch %d\n", i, cur)
+		}
+	}
+	se.Close()
+
+	se.Loop = false
+	se.Init(0)
+	buf := &etable.Table{}
+	buf.SetFromSchema(se.Schema, 2)
+	if n, err := se.ReadRows(buf, 2); n != 2 || err != nil || buf.CellString("Name", 1) != "p1" {
+		t.Errorf("read rows: %d %v\n", n, err)
+	}
+	if n, _ := se.ReadRows(buf, 2); n != 1 || !se.Done || se.Step() {
+		t.Errorf("stream not done at its end: %d\n", n)
+	}
+	se.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("no network:", err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(conn, "Name,Input,Input,Input,Input\na,0,1,0,0\nb,0,0,0,1\n")
+		conn.Close()
+	}()
+	se = &StreamEnv{Nm: "Net", Source: "tcp://" + ln.Addr().String(), Schema: leabra.SchemaOf(dt), EpochLen: 1}
+	se.Init(0)
+	if !se.Step() || se.TrialName != "a" || se.State("Input").FloatVal1D(1) != 1 {
+		t.Errorf("network row a wrong: %v\n", se.TrialName)
+	}
+	if !se.Step() || se.TrialName != "b" || se.State("Input").FloatVal1D(3) != 1 || se.Epoch.Cur != 1 {
+		t.Errorf("network row b wrong: %v epoch %d\n", se.TrialName, se.Epoch.Cur)
+	}
+	if se.Step() {
+		t.Errorf("network stream not done\n")
+	}
+	se.Close()
+}
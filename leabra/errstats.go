@@ -0,0 +1,128 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+)
+
+// ErrStats are error measures of the minus-phase activity (ActM) of a layer
+// against its targets (Targ), in addition to MSE and CosDiff, which are
+// graded where SSE with a tolerance is all-or-none: cross-entropy, the area
+// under the ROC curve, and signal-detection measures for binary targets
+// (units with Targ > .5 are the signal).  See Layer.ErrStats.
+type ErrStats struct {
+	CrossEnt float64 `desc:"mean binary cross-entropy of ActM against Targ, in nats per unit"`
+	AUC      float64 `desc:"area under the ROC curve of ActM as a detector of the target units: the probability that a target unit is more active than a non-target unit -- .5 = chance (also if all or no units are targets), 1 = perfect"`
+	DPrime   float64 `desc:"signal-detection sensitivity d' = z(Hit) - z(FA)"`
+	Hit      float64 `desc:"hit rate: proportion of target units with ActM > threshold, with log-linear correction (+.5 / +1) so z is finite"`
+	FA       float64 `desc:"false alarm rate: proportion of non-target units with ActM > threshold, with log-linear correction (+.5 / +1) so z is finite"`
+}
+
+// ErrStatNames are the names of the ErrStats measures, for Stat
+var ErrStatNames = []string{"CrossEnt", "AUC", "DPrime", "Hit", "FA"}
+
+// Stat returns the measure with given name (see ErrStatNames)
+func (es *ErrStats) Stat(name string) (float64, error) {
+	switch name {
+	case "CrossEnt":
+		return es.CrossEnt, nil
+	case "AUC":
+		return es.AUC, nil
+	case "DPrime":
+		return es.DPrime, nil
+	case "Hit":
+		return es.Hit, nil
+	case "FA":
+		return es.FA, nil
+	}
+	err := fmt.Errorf("ErrStats: stat: %v not found, must be one of: %v", name, ErrStatNames)
+	log.Println(err)
+	return 0, err
+}
+
+// ErrStats returns the error measures of the layer's ActM against Targ,
+// with ActM > thr counted as detecting a unit for Hit and FA (e.g., .5)
+func (ly *Layer) ErrStats(thr float32) ErrStats {
+	const eps = 1.0e-6
+	var es ErrStats
+	var acts []float64
+	var targs []bool
+	npos, nhit, nfa := 0, 0, 0
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		a := math.Min(math.Max(float64(nrn.ActM), eps), 1-eps)
+		t := float64(nrn.Targ)
+		es.CrossEnt -= t*math.Log(a) + (1-t)*math.Log(1-a)
+		pos := nrn.Targ > 0.5
+		acts = append(acts, float64(nrn.ActM))
+		targs = append(targs, pos)
+		det := nrn.ActM > thr
+		switch {
+		case pos && det:
+			nhit++
+		case !pos && det:
+			nfa++
+		}
+		if pos {
+			npos++
+		}
+	}
+	n := len(acts)
+	if n == 0 {
+		es.AUC = 0.5
+		return es
+	}
+	nneg := n - npos
+	es.CrossEnt /= float64(n)
+	es.AUC = rocAUC(acts, targs, npos)
+	es.Hit = (float64(nhit) + 0.5) / (float64(npos) + 1)
+	es.FA = (float64(nfa) + 0.5) / (float64(nneg) + 1)
+	es.DPrime = zScore(es.Hit) - zScore(es.FA)
+	return es
+}
+
+// rocAUC returns the area under the ROC curve for given scores as a
+// detector of the positive items, from the Mann-Whitney U statistic
+// with average ranks for ties -- .5 if there are no positive or negative items
+func rocAUC(scores []float64, pos []bool, npos int) float64 {
+	n := len(scores)
+	nneg := n - npos
+	if npos == 0 || nneg == 0 {
+		return 0.5
+	}
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool { return scores[idx[i]] < scores[idx[j]] })
+	rankSum := 0.0
+	for st := 0; st < n; {
+		ed := st + 1
+		for ed < n && scores[idx[ed]] == scores[idx[st]] {
+			ed++
+		}
+		rank := 0.5 * float64(st+ed+1) // average of 1-based ranks st+1..ed
+		for i := st; i < ed; i++ {
+			if pos[idx[i]] {
+				rankSum += rank
+			}
+		}
+		st = ed
+	}
+	u := rankSum - float64(npos*(npos+1))/2
+	return u / float64(npos*nneg)
+}
+
+// zScore returns the inverse of the standard normal cumulative distribution at p
+func zScore(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
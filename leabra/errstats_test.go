@@ -0,0 +1,48 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+)
+
+func TestErrStats(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "ErrNet")
+	outLay := net.AddLayer2D("Output", 1, 4, emer.Target)
+	net.Defaults()
+	net.Build()
+	ly := outLay.(*Layer)
+	targs := []float32{1, 1, 0, 0}
+	acts := []float32{0.9, 0.4, 0.6, 0.1}
+	for ni := range ly.Neurons {
+		ly.Neurons[ni].Targ = targs[ni]
+		ly.Neurons[ni].ActM = acts[ni]
+	}
+	es := ly.ErrStats(0.5)
+	if es.AUC != 0.75 {
+		t.Errorf("AUC = %v, want 0.75\n", es.AUC)
+	}
+	ce := -(math.Log(0.9) + math.Log(0.4) + math.Log(0.4) + math.Log(0.9)) / 4
+	if math.Abs(es.CrossEnt-ce) > 1e-6 {
+		t.Errorf("CrossEnt = %v, want %v\n", es.CrossEnt, ce)
+	}
+	if es.Hit != 0.5 || es.FA != 0.5 || math.Abs(es.DPrime) > 1e-9 {
+		t.Errorf("Hit %v FA %v DPrime %v, want .5 .5 0\n", es.Hit, es.FA, es.DPrime)
+	}
+	for ni := range ly.Neurons {
+		ly.Neurons[ni].ActM = targs[ni]
+	}
+	es = ly.ErrStats(0.5)
+	if es.AUC != 1 || es.DPrime <= 1 {
+		t.Errorf("perfect: AUC %v DPrime %v\n", es.AUC, es.DPrime)
+	}
+	if _, err := es.Stat("Nope"); err == nil {
+		t.Errorf("expected error for unknown stat\n")
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package export writes the logs and state of a leabra sim to external formats
+for analysis: SQLite databases (WriteSQLite, with the sqlite build tag),
+HDF5 files (WriteHDF5, with the hdf5 build tag) and TensorBoard event files
+(TBWriter).
+*/
+package export
@@ -0,0 +1,272 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build hdf5
+// +build hdf5
+
+package export
+
+import (
+	"log"
+	"math"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+	"gonum.org/v1/hdf5"
+)
+
+// WriteHDF5 writes the weights of given network, any number of logs, and optional
+// recorded activity into a single HDF5 file, for analysis in e.g., Python
+// (h5py) or Matlab.  Requires the hdf5 build tag and the HDF5 C library:
+//
+//	go build -tags hdf5
+//
+// The layout of the file is:
+//
+//	/weights/<RecvLayer>/<SendLayer>/Wt   float32 [nRecv, nSend] dense matrix of
+//	                                      weights, NaN where there is no synapse,
+//	                                      with float32 attribute GScale
+//	/logs/<LogName>/<Column>              float64 [nRows, cell dims...] for numeric
+//	                                      columns, uint8 [nRows, maxLen] for string
+//	                                      columns (zero-padded)
+//	/acts/<Layer>/<Var>                   float32 [nRecs, nUnits] recorded values
+//
+// The root group has the string attribute network with the network name, and
+// the int attribute nrecs with the number of activity records.  Units are
+// always in flat (1D) index order.  logs and acts can be nil.
+func WriteHDF5(nt *leabra.Network, filename string, logs map[string]*etable.Table, acts *leabra.ActRecord) error {
+	f, err := hdf5.CreateFile(filename, hdf5.F_ACC_TRUNC)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer f.Close()
+
+	root, err := f.OpenGroup("/")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer root.Close()
+	nrecs := 0
+	if acts != nil {
+		nrecs = acts.NRecs
+	}
+	if err := h5StrAttr(root, "network", nt.Nm); err != nil {
+		return err
+	}
+	if err := h5IntAttr(root, "nrecs", nrecs); err != nil {
+		return err
+	}
+
+	if err := writeHDF5Wts(nt, f); err != nil {
+		return err
+	}
+	if len(logs) > 0 {
+		lg, err := f.CreateGroup("logs")
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		for nm, dt := range logs {
+			if err := h5WriteTable(lg, nm, dt); err != nil {
+				lg.Close()
+				return err
+			}
+		}
+		lg.Close()
+	}
+	if acts != nil && acts.NRecs > 0 {
+		ag, err := f.CreateGroup("acts")
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		defer ag.Close()
+		for _, lnm := range acts.Layers {
+			lg, err := ag.CreateGroup(lnm)
+			if err != nil {
+				log.Println(err)
+				return err
+			}
+			nu := acts.NUnits[lnm]
+			for _, vnm := range acts.Vars {
+				vs, err := acts.Vals(lnm, vnm)
+				if err != nil {
+					continue
+				}
+				if err := h5WriteFloat32(lg, vnm, []uint{uint(acts.NRecs), uint(nu)}, vs); err != nil {
+					lg.Close()
+					return err
+				}
+			}
+			lg.Close()
+		}
+	}
+	return nil
+}
+
+// writeHDF5Wts writes the /weights group
+func writeHDF5Wts(nt *leabra.Network, f *hdf5.File) error {
+	wg, err := f.CreateGroup("weights")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer wg.Close()
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		lg, err := wg.CreateGroup(ly.Name())
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		for _, p := range *ly.RecvPrjns() {
+			if p.IsOff() {
+				continue
+			}
+			pj := p.(leabra.LeabraPrjn).AsLeabra()
+			slay := pj.Send.(leabra.LeabraLayer).AsLeabra()
+			nr := len(pj.RConN)
+			ns := len(slay.Neurons)
+			wts := make([]float32, nr*ns)
+			nan := float32(math.NaN())
+			for i := range wts {
+				wts[i] = nan
+			}
+			for ri := 0; ri < nr; ri++ {
+				nc := int(pj.RConN[ri])
+				st := int(pj.RConIdxSt[ri])
+				for ci := 0; ci < nc; ci++ {
+					si := int(pj.RConIdx[st+ci])
+					wts[ri*ns+si] = pj.Syns.At(pj.RSynIdx[st+ci]).Wt
+				}
+			}
+			pg, err := lg.CreateGroup(slay.Nm)
+			if err != nil {
+				lg.Close()
+				log.Println(err)
+				return err
+			}
+			err = h5WriteFloat32(pg, "Wt", []uint{uint(nr), uint(ns)}, wts)
+			if err == nil {
+				err = h5Float32Attr(pg, "GScale", pj.GScale)
+			}
+			pg.Close()
+			if err != nil {
+				lg.Close()
+				return err
+			}
+		}
+		lg.Close()
+	}
+	return nil
+}
+
+// h5WriteTable writes given table as a group with one dataset per column
+func h5WriteTable(g *hdf5.Group, nm string, dt *etable.Table) error {
+	tg, err := g.CreateGroup(nm)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer tg.Close()
+	for ci, cl := range dt.Cols {
+		cnm := dt.ColNames[ci]
+		dims := []uint{uint(dt.Rows)}
+		if cl.NumDims() > 1 {
+			for _, d := range cl.Shapes()[1:] {
+				dims = append(dims, uint(d))
+			}
+		}
+		if cl.DataType() == etensor.STRING {
+			mx := 1
+			for ri := 0; ri < dt.Rows; ri++ {
+				if l := len(cl.StringVal1D(ri)); l > mx {
+					mx = l
+				}
+			}
+			bs := make([]uint8, dt.Rows*mx)
+			for ri := 0; ri < dt.Rows; ri++ {
+				copy(bs[ri*mx:(ri+1)*mx], cl.StringVal1D(ri))
+			}
+			if err := h5Write(tg, cnm, hdf5.T_NATIVE_UINT8, []uint{uint(dt.Rows), uint(mx)}, &bs); err != nil {
+				return err
+			}
+			continue
+		}
+		var vs []float64
+		cl.Floats(&vs)
+		if err := h5Write(tg, cnm, hdf5.T_NATIVE_DOUBLE, dims, &vs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// h5WriteFloat32 writes a float32 dataset with given dims
+func h5WriteFloat32(g *hdf5.Group, nm string, dims []uint, vs []float32) error {
+	return h5Write(g, nm, hdf5.T_NATIVE_FLOAT, dims, &vs)
+}
+
+// h5Write writes a dataset of given type and dims, from a pointer to a slice
+func h5Write(g *hdf5.Group, nm string, dtype *hdf5.Datatype, dims []uint, data interface{}) error {
+	sp, err := hdf5.CreateSimpleDataspace(dims, nil)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer sp.Close()
+	ds, err := g.CreateDataset(nm, dtype, sp)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer ds.Close()
+	if err := ds.Write(data); err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// h5Attr writes a scalar attribute of given type
+func h5Attr(g *hdf5.Group, nm string, dtype *hdf5.Datatype, val interface{}) error {
+	sp, err := hdf5.CreateDataspace(hdf5.S_SCALAR)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer sp.Close()
+	at, err := g.CreateAttribute(nm, dtype, sp)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer at.Close()
+	if err := at.Write(val, dtype); err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// h5Float32Attr writes a float32 scalar attribute
+func h5Float32Attr(g *hdf5.Group, nm string, val float32) error {
+	return h5Attr(g, nm, hdf5.T_NATIVE_FLOAT, &val)
+}
+
+// h5IntAttr writes an int scalar attribute
+func h5IntAttr(g *hdf5.Group, nm string, val int) error {
+	v := int64(val)
+	return h5Attr(g, nm, hdf5.T_NATIVE_INT64, &v)
+}
+
+// h5StrAttr writes a string scalar attribute
+func h5StrAttr(g *hdf5.Group, nm string, val string) error {
+	return h5Attr(g, nm, hdf5.T_GO_STRING, &val)
+}
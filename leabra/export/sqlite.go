@@ -0,0 +1,96 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build sqlite
+// +build sqlite
+
+package export
+
+import (
+	"database/sql"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/emer/etable/etable"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// WriteSQLite writes the given log tables into a new SQLite database file
+// (replacing any existing one), one SQL table per log, named by its key in
+// logs, with the columns typed as by SQLColType, and a meta table of the
+// given key / value pairs describing the run (e.g., Provenance.Meta), so
+// that the logs of many runs can be queried and joined with SQL.  Requires
+// the sqlite build tag and cgo, for github.com/mattn/go-sqlite3:
+//
+//	go build -tags sqlite
+//
+// Logs without any columns are skipped.
+func WriteSQLite(filename string, meta map[string]string, logs map[string]*etable.Table) error {
+	os.Remove(filename)
+	db, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer db.Close()
+	tx, err := db.Begin()
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if err := writeSQLiteTx(tx, meta, logs); err != nil {
+		tx.Rollback()
+		log.Println(err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// writeSQLiteTx creates and fills the tables within given transaction
+func writeSQLiteTx(tx *sql.Tx, meta map[string]string, logs map[string]*etable.Table) error {
+	if _, err := tx.Exec(`CREATE TABLE meta ("Key" TEXT PRIMARY KEY, "Value" TEXT)`); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, err := tx.Exec(`INSERT INTO meta VALUES (?, ?)`, k, meta[k]); err != nil {
+			return err
+		}
+	}
+	names := make([]string, 0, len(logs))
+	for nm := range logs {
+		names = append(names, nm)
+	}
+	sort.Strings(names)
+	for _, nm := range names {
+		dt := logs[nm]
+		if dt == nil || len(dt.Cols) == 0 {
+			continue
+		}
+		if _, err := tx.Exec(SQLCreateTable(nm, dt)); err != nil {
+			return err
+		}
+		st, err := tx.Prepare(SQLInsert(nm, dt))
+		if err != nil {
+			return err
+		}
+		for row := 0; row < dt.Rows; row++ {
+			if _, err := st.Exec(SQLRowVals(dt, row)...); err != nil {
+				st.Close()
+				return err
+			}
+		}
+		st.Close()
+	}
+	return nil
+}
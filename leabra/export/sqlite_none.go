@@ -0,0 +1,24 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !sqlite
+// +build !sqlite
+
+package export
+
+import (
+	"errors"
+	"log"
+
+	"github.com/emer/etable/etable"
+)
+
+// WriteSQLite writes the given log tables into a new SQLite database file
+// -- this build does not include SQLite support: build with -tags sqlite
+// (requires cgo) to enable it
+func WriteSQLite(filename string, meta map[string]string, logs map[string]*etable.Table) error {
+	err := errors.New("WriteSQLite: SQLite support not built in -- build with: go build -tags sqlite")
+	log.Println(err)
+	return err
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// SQLIdent returns given name quoted as an SQL identifier, e.g., for log
+// column names such as Hid1 ActAvg or FirstZero:Mean
+func SQLIdent(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// SQLColType returns the SQLite type of the column with given index of the
+// table: INTEGER or REAL for numeric scalars, and TEXT for strings and for
+// tensor cells, which are stored as JSON arrays of their values in flat
+// index order (e.g., for json_extract)
+func SQLColType(dt *etable.Table, ci int) string {
+	col := dt.Cols[ci]
+	if len(col.Shapes()) > 1 {
+		return "TEXT"
+	}
+	switch col.DataType() {
+	case etensor.STRING:
+		return "TEXT"
+	case etensor.FLOAT32, etensor.FLOAT64:
+		return "REAL"
+	}
+	return "INTEGER"
+}
+
+// SQLCreateTable returns the statement creating an SQL table with given
+// name and the columns of given log table
+func SQLCreateTable(name string, dt *etable.Table) string {
+	cols := make([]string, len(dt.Cols))
+	for ci, cnm := range dt.ColNames {
+		cols[ci] = SQLIdent(cnm) + " " + SQLColType(dt, ci)
+	}
+	return fmt.Sprintf("CREATE TABLE %v (%v)", SQLIdent(name), strings.Join(cols, ", "))
+}
+
+// SQLInsert returns the statement inserting a row into an SQL table with
+// given name and the columns of given log table, with ? placeholders
+func SQLInsert(name string, dt *etable.Table) string {
+	ph := make([]string, len(dt.Cols))
+	for i := range ph {
+		ph[i] = "?"
+	}
+	return fmt.Sprintf("INSERT INTO %v VALUES (%v)", SQLIdent(name), strings.Join(ph, ", "))
+}
+
+// SQLRowVals returns the values of the given row of the log table, in the
+// types given by SQLColType -- NaN values are nil (NULL)
+func SQLRowVals(dt *etable.Table, row int) []interface{} {
+	vals := make([]interface{}, len(dt.Cols))
+	for ci, cnm := range dt.ColNames {
+		switch SQLColType(dt, ci) {
+		case "TEXT":
+			if len(dt.Cols[ci].Shapes()) > 1 {
+				vals[ci] = sqlCellJSON(dt.CellTensor(cnm, row))
+			} else {
+				vals[ci] = dt.CellString(cnm, row)
+			}
+		case "REAL":
+			if v := dt.CellFloat(cnm, row); !math.IsNaN(v) {
+				vals[ci] = v
+			}
+		default:
+			vals[ci] = int64(dt.CellFloat(cnm, row))
+		}
+	}
+	return vals
+}
+
+// sqlCellJSON returns the values of given tensor cell as a JSON array, with
+// null for NaN and Inf values, which JSON does not represent
+func sqlCellJSON(tsr etensor.Tensor) string {
+	cvs := make([]interface{}, tsr.Len())
+	for i := range cvs {
+		if v := tsr.FloatVal1D(i); !math.IsNaN(v) && !math.IsInf(v, 0) {
+			cvs[i] = v
+		}
+	}
+	b, _ := json.Marshal(cvs)
+	return string(b)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package export
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestSQLSchema(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Params", etensor.STRING, nil, nil},
+		{"Hid1 ActAvg", etensor.FLOAT64, nil, nil},
+		{"InAct", etensor.FLOAT32, []int{2}, nil},
+	}, 1)
+	dt.SetCellFloat("Epoch", 0, 3)
+	dt.SetCellString("Params", 0, "Base")
+	dt.SetCellFloat("Hid1 ActAvg", 0, math.NaN())
+	inact := etensor.NewFloat32([]int{2}, nil, nil)
+	inact.Values[0], inact.Values[1] = 0.5, 1
+	dt.SetCellTensor("InAct", 0, inact)
+
+	want := `CREATE TABLE "TrnEpcLog" ("Epoch" INTEGER, "Params" TEXT, "Hid1 ActAvg" REAL, "InAct" TEXT)`
+	if s := SQLCreateTable("TrnEpcLog", dt); s != want {
+		t.Errorf("create:\n%v\nwant:\n%v\n", s, want)
+	}
+	if s := SQLInsert(`a"b`, dt); s != `INSERT INTO "a""b" VALUES (?, ?, ?, ?)` {
+		t.Errorf("insert: %v\n", s)
+	}
+	vals := SQLRowVals(dt, 0)
+	if vals[0] != int64(3) || vals[1] != "Base" || vals[2] != nil || vals[3] != "[0.5,1]" {
+		t.Errorf("row vals wrong: %#v\n", vals)
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/emer/leabra/leabra"
+)
+
+// TBWriter writes scalar metrics and histograms (e.g., of the weights) to a
+// TensorBoard event file in a directory, e.g., one per run, so that many
+// runs (e.g., on a cluster) can be monitored with TensorBoard pointed at the
+// parent directory.  The file is written directly in the TFRecord format of
+// serialized Event protocol buffers, without any TensorFlow dependency.
+type TBWriter struct {
+	Dir  string `desc:"directory of the event file"`
+	File string `desc:"name of the event file"`
+	f    *os.File
+	bw   *bufio.Writer
+	buf  []byte
+}
+
+// NewTBWriter creates a TensorBoard event file in given directory, creating
+// the directory if needed
+func NewTBWriter(dir string) (*TBWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	now := time.Now()
+	tw := &TBWriter{Dir: dir}
+	tw.File = filepath.Join(dir, fmt.Sprintf("events.out.tfevents.%d.%s", now.Unix(), host))
+	f, err := os.Create(tw.File)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	tw.f = f
+	tw.bw = bufio.NewWriter(f)
+	ev := pbDouble(nil, 1, tbWallTime(now))
+	ev = pbBytes(ev, 3, []byte("brain.Event:2"))
+	return tw, tw.writeRecord(ev)
+}
+
+// Scalar writes the value of given tag (e.g., train/SSE) at given step (e.g., epoch)
+func (tw *TBWriter) Scalar(tag string, step int, val float64) error {
+	v := pbBytes(nil, 1, []byte(tag))
+	v = pbFloat(v, 2, float32(val))
+	return tw.writeSummary(step, v)
+}
+
+// Histogram writes a histogram of given values with nbins equal-width bins
+// for given tag at given step
+func (tw *TBWriter) Histogram(tag string, step int, vals []float32, nbins int) error {
+	if len(vals) == 0 {
+		return nil
+	}
+	if nbins < 1 {
+		nbins = 1
+	}
+	min, max := math.Inf(1), math.Inf(-1)
+	sum, ssq := 0.0, 0.0
+	for _, v := range vals {
+		fv := float64(v)
+		min = math.Min(min, fv)
+		max = math.Max(max, fv)
+		sum += fv
+		ssq += fv * fv
+	}
+	lims := make([]float64, nbins)
+	cnts := make([]float64, nbins)
+	wd := (max - min) / float64(nbins)
+	for i := range lims {
+		lims[i] = min + float64(i+1)*wd
+	}
+	lims[nbins-1] = max
+	for _, v := range vals {
+		bi := nbins - 1
+		if wd > 0 {
+			bi = int((float64(v) - min) / wd)
+			if bi >= nbins {
+				bi = nbins - 1
+			}
+		}
+		cnts[bi]++
+	}
+	h := pbDouble(nil, 1, min)
+	h = pbDouble(h, 2, max)
+	h = pbDouble(h, 3, float64(len(vals)))
+	h = pbDouble(h, 4, sum)
+	h = pbDouble(h, 5, ssq)
+	h = pbPackedDoubles(h, 6, lims)
+	h = pbPackedDoubles(h, 7, cnts)
+	v := pbBytes(nil, 1, []byte(tag))
+	v = pbBytes(v, 5, h)
+	return tw.writeSummary(step, v)
+}
+
+// LogRow writes a Scalar for each numeric scalar column of given row of the
+// table (e.g., an epoch log), with tags prefix + column name, except for
+// the given columns (e.g., Run, Epoch)
+func (tw *TBWriter) LogRow(dt *etable.Table, row, step int, prefix string, except ...string) error {
+	for ci, col := range dt.Cols {
+		if col.DataType() == etensor.STRING || col.NumDims() > 1 || col.Len() != dt.Rows {
+			continue
+		}
+		nm := dt.ColNames[ci]
+		skip := false
+		for _, ex := range except {
+			if nm == ex {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+		if err := tw.Scalar(prefix+nm, step, col.FloatVal1D(row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WtHistograms writes a Histogram of the weights of each projection of the
+// network, with tags wts/ + projection name
+func (tw *TBWriter) WtHistograms(nt *leabra.Network, step, nbins int) error {
+	var wts []float32
+	for _, ly := range nt.Layers {
+		for _, p := range ly.(leabra.LeabraLayer).AsLeabra().RcvPrjns {
+			if p.IsOff() {
+				continue
+			}
+			pj := p.(leabra.LeabraPrjn).AsLeabra()
+			wts = wts[:0]
+			for _, syns := range pj.Syns.Chunks {
+				for si := range syns {
+					wts = append(wts, syns[si].Wt)
+				}
+			}
+			if err := tw.Histogram("wts/"+pj.Name(), step, wts, nbins); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered events to the file
+func (tw *TBWriter) Flush() error {
+	if tw.bw == nil {
+		return nil
+	}
+	err := tw.bw.Flush()
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// Close flushes and closes the file -- ok to call on a nil writer
+func (tw *TBWriter) Close() error {
+	if tw == nil || tw.f == nil {
+		return nil
+	}
+	err := tw.Flush()
+	tw.f.Close()
+	tw.f = nil
+	tw.bw = nil
+	return err
+}
+
+// writeSummary writes an Event with a Summary of one Value
+func (tw *TBWriter) writeSummary(step int, val []byte) error {
+	ev := pbDouble(nil, 1, tbWallTime(time.Now()))
+	ev = pbVarint(ev, 2, uint64(step))
+	ev = pbBytes(ev, 5, pbBytes(nil, 1, val))
+	return tw.writeRecord(ev)
+}
+
+// writeRecord writes data as a TFRecord: length, masked crc of the length,
+// data, masked crc of the data
+func (tw *TBWriter) writeRecord(data []byte) error {
+	if tw.bw == nil {
+		return fmt.Errorf("TBWriter: file is closed")
+	}
+	var hdr [12]byte
+	binary.LittleEndian.PutUint64(hdr[:8], uint64(len(data)))
+	binary.LittleEndian.PutUint32(hdr[8:], tbMaskedCRC(hdr[:8]))
+	var ftr [4]byte
+	binary.LittleEndian.PutUint32(ftr[:], tbMaskedCRC(data))
+	tw.bw.Write(hdr[:])
+	tw.bw.Write(data)
+	_, err := tw.bw.Write(ftr[:])
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+var tbCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+// tbMaskedCRC returns the masked crc32c used in TFRecords
+func tbMaskedCRC(b []byte) uint32 {
+	crc := crc32.Checksum(b, tbCRCTable)
+	return ((crc >> 15) | (crc << 17)) + 0xa282ead8
+}
+
+func tbWallTime(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1e9
+}
+
+// minimal protocol buffer encoding
+
+func pbUvarint(b []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(b, tmp[:n]...)
+}
+
+func pbFixed64(b []byte, v uint64) []byte {
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], v)
+	return append(b, tmp[:]...)
+}
+
+func pbKey(b []byte, field, wire int) []byte {
+	return pbUvarint(b, uint64(field<<3|wire))
+}
+
+func pbVarint(b []byte, field int, v uint64) []byte {
+	return pbUvarint(pbKey(b, field, 0), v)
+}
+
+func pbDouble(b []byte, field int, v float64) []byte {
+	return pbFixed64(pbKey(b, field, 1), math.Float64bits(v))
+}
+
+func pbFloat(b []byte, field int, v float32) []byte {
+	var tmp [4]byte
+	binary.LittleEndian.PutUint32(tmp[:], math.Float32bits(v))
+	return append(pbKey(b, field, 5), tmp[:]...)
+}
+
+func pbBytes(b []byte, field int, v []byte) []byte {
+	b = pbUvarint(pbKey(b, field, 2), uint64(len(v)))
+	return append(b, v...)
+}
+
+func pbPackedDoubles(b []byte, field int, vs []float64) []byte {
+	pk := make([]byte, 0, 8*len(vs))
+	for _, v := range vs {
+		pk = pbFixed64(pk, math.Float64bits(v))
+	}
+	return pbBytes(b, field, pk)
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package export
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+)
+
+// pbFields decodes the fields of a protocol buffer message: varint and
+// fixed values as uint64, length-delimited as []byte
+func pbFields(t *testing.T, b []byte) map[int][]interface{} {
+	fs := map[int][]interface{}{}
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		b = b[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case 0:
+			v, n := binary.Uvarint(b)
+			fs[field] = append(fs[field], v)
+			b = b[n:]
+		case 1:
+			fs[field] = append(fs[field], binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case 5:
+			fs[field] = append(fs[field], uint64(binary.LittleEndian.Uint32(b)))
+			b = b[4:]
+		case 2:
+			ln, n := binary.Uvarint(b)
+			b = b[n:]
+			fs[field] = append(fs[field], b[:ln])
+			b = b[ln:]
+		default:
+			t.Fatalf("bad wire type in key: %v\n", key)
+		}
+	}
+	return fs
+}
+
+func TestTBWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tboard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tw, err := NewTBWriter(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw.Scalar("train/SSE", 7, 0.25)
+	tw.Histogram("wts/Hid", 7, []float32{0, 0.5, 0.5, 1}, 2)
+	tw.Close()
+
+	b, err := ioutil.ReadFile(tw.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evs [][]byte
+	for len(b) > 0 {
+		ln := binary.LittleEndian.Uint64(b)
+		if binary.LittleEndian.Uint32(b[8:]) != tbMaskedCRC(b[:8]) {
+			t.Fatalf("bad length crc\n")
+		}
+		data := b[12 : 12+ln]
+		if binary.LittleEndian.Uint32(b[12+ln:]) != tbMaskedCRC(data) {
+			t.Fatalf("bad data crc\n")
+		}
+		evs = append(evs, data)
+		b = b[16+ln:]
+	}
+	if len(evs) != 3 {
+		t.Fatalf("got %v events, want 3\n", len(evs))
+	}
+	if fv := pbFields(t, evs[0])[3]; len(fv) != 1 || string(fv[0].([]byte)) != "brain.Event:2" {
+		t.Errorf("first event is not the file version: %v\n", fv)
+	}
+	ev := pbFields(t, evs[1])
+	if ev[2][0].(uint64) != 7 {
+		t.Errorf("step = %v, want 7\n", ev[2][0])
+	}
+	val := pbFields(t, pbFields(t, ev[5][0].([]byte))[1][0].([]byte))
+	if string(val[1][0].([]byte)) != "train/SSE" || math.Float32frombits(uint32(val[2][0].(uint64))) != 0.25 {
+		t.Errorf("scalar value wrong: %v\n", val)
+	}
+	hval := pbFields(t, pbFields(t, pbFields(t, evs[2])[5][0].([]byte))[1][0].([]byte))
+	h := pbFields(t, hval[5][0].([]byte))
+	cnts := h[7][0].([]byte)
+	if len(cnts) != 16 || math.Float64frombits(binary.LittleEndian.Uint64(cnts[8:])) != 3 {
+		t.Errorf("histogram counts wrong: %v\n", cnts)
+	}
+}
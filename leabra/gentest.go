@@ -0,0 +1,172 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// The types of the items of a GenTest
+const (
+	// GenTrained are the trained items, tested as is
+	GenTrained = "Trained"
+
+	// GenRecomb are novel recombinations of the features of two trained items
+	GenRecomb = "Recombined"
+
+	// GenNovel are novel items unrelated to the trained ones, as controls
+	GenNovel = "Novel"
+)
+
+// GenTypes are the types of the items of a GenTest, in order
+var GenTypes = []string{GenTrained, GenRecomb, GenNovel}
+
+// GenTest makes a generalization test, to measure whether sleep improves
+// generalization as opposed to rote retention: the trained items, NRecomb
+// novel recombinations of the features of two random trained items (the
+// first Split of the units of each of the Cols from one, e.g., the top rows
+// of the Input, and the rest from the other, with the other columns, e.g.,
+// the emotional inputs, from the first), and NNovel novel control items
+// with random patterns of the same number of active units in the Cols and
+// nothing else -- the type of each item is in the Type column of the Table.
+type GenTest struct {
+	On      bool              `desc:"if true, the generalization test is run"`
+	Cols    []string          `desc:"columns of the features that are recombined, with the same recombination for each, e.g., the Input and its (auto-associative) Output target"`
+	NRecomb int               `desc:"number of novel recombinations of trained items"`
+	NNovel  int               `desc:"number of novel control items"`
+	Split   float32           `min:"0" max:"1" desc:"proportion of the units of each of the Cols of a recombination that come from its first item -- the rest come from the second"`
+	Table   *etable.Table     `view:"-" desc:"the test items made by Make: the columns of the patterns, plus their Type and the Parents of the recombinations"`
+	Types   map[string]string `view:"-" desc:"the Type of each item of the Table, by name"`
+}
+
+func (gt *GenTest) Defaults() {
+	gt.Cols = []string{"Input", "Output"}
+	gt.NRecomb = 10
+	gt.NNovel = 10
+	gt.Split = 0.5
+}
+
+// IsCol returns true if given column is one of the Cols
+func (gt *GenTest) IsCol(col string) bool {
+	for _, c := range gt.Cols {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
+// Type returns the type of the test item with given name: one of the
+// GenTypes, or "" if not an item of the Table
+func (gt *GenTest) Type(name string) string {
+	return gt.Types[name]
+}
+
+// Make makes the Table of test items from the trained items in given view
+// of the patterns (with their names in the Name column), using given random
+// number stream
+func (gt *GenTest) Make(pats *etable.IdxView, rnd *rand.Rand) error {
+	dt := pats.Table
+	n := pats.Len()
+	if n < 2 || dt.ColByName("Name") == nil {
+		err := fmt.Errorf("GenTest: need a Name column and at least 2 trained items, have %d", n)
+		log.Println(err)
+		return err
+	}
+	for _, col := range gt.Cols {
+		if dt.ColByName(col) == nil {
+			err := fmt.Errorf("GenTest: column: %v not found in the patterns", col)
+			log.Println(err)
+			return err
+		}
+	}
+	sch := SchemaOf(dt)
+	sch = append(sch, etable.Column{"Type", etensor.STRING, nil, nil})
+	sch = append(sch, etable.Column{"Parents", etensor.STRING, nil, nil})
+	gt.Table = &etable.Table{}
+	gt.Table.SetMetaData("name", "GenTestPats")
+	gt.Table.SetMetaData("desc", "generalization test items: trained, recombined and novel")
+	gt.Table.SetFromSchema(sch, n+gt.NRecomb+gt.NNovel)
+	ot := gt.Table
+	// set sets given row of the test items to given rows of the patterns:
+	// from row a, and for the Cols the first Split of the units from a and
+	// the rest from b
+	set := func(row, a, b int) {
+		for ci, col := range dt.Cols {
+			nm := dt.ColNames[ci]
+			csz := col.Len() / dt.Rows
+			if col.DataType() == etensor.STRING {
+				ot.SetCellString(nm, row, col.StringVal1D(a*csz))
+				continue
+			}
+			nsp := csz
+			if gt.IsCol(nm) {
+				nsp = int(math.Floor(float64(gt.Split)*float64(csz) + 0.5))
+			}
+			for i := 0; i < csz; i++ {
+				src := a
+				if i >= nsp {
+					src = b
+				}
+				ot.Cols[ci].SetFloat1D(row*csz+i, col.FloatVal1D(src*csz+i))
+			}
+		}
+	}
+	nc := dt.ColByName("Name")
+	non := 0.0 // mean number of active units of the first of the Cols
+	for i, row := range pats.Idxs {
+		set(i, row, row)
+		ot.SetCellString("Type", i, GenTrained)
+		if len(gt.Cols) > 0 {
+			tsr := dt.CellTensor(gt.Cols[0], row)
+			for u := 0; u < tsr.Len(); u++ {
+				if tsr.FloatVal1D(u) > 0 {
+					non++
+				}
+			}
+		}
+	}
+	non /= float64(n)
+	for i := 0; i < gt.NRecomb; i++ {
+		row := n + i
+		pa := rnd.Intn(n)
+		pb := (pa + 1 + rnd.Intn(n-1)) % n // a different item
+		a, b := pats.Idxs[pa], pats.Idxs[pb]
+		set(row, a, b)
+		ot.SetCellString("Name", row, fmt.Sprintf("Recomb_%d", i))
+		ot.SetCellString("Type", row, GenRecomb)
+		ot.SetCellString("Parents", row, nc.StringVal1D(a)+"+"+nc.StringVal1D(b))
+	}
+	for i := 0; i < gt.NNovel; i++ {
+		row := n + gt.NRecomb + i
+		ot.SetCellString("Name", row, fmt.Sprintf("Novel_%d", i))
+		ot.SetCellString("Type", row, GenNovel)
+		var pat []int
+		for ci, col := range gt.Cols {
+			tsr := ot.CellTensor(col, row)
+			if ci == 0 {
+				nu := int(math.Floor(non + 0.5))
+				if nu > tsr.Len() {
+					nu = tsr.Len()
+				}
+				pat = rnd.Perm(tsr.Len())[:nu]
+			}
+			if tsr.Len() == ot.CellTensor(gt.Cols[0], row).Len() {
+				SetPatUnits(tsr, pat)
+			}
+		}
+	}
+	gt.Types = make(map[string]string, ot.Rows)
+	for row := 0; row < ot.Rows; row++ {
+		gt.Types[ot.CellString("Name", row)] = ot.CellString("Type", row)
+	}
+	return nil
+}
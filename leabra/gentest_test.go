@@ -0,0 +1,105 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestGenTest(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, []int{2, 4}, nil},
+		{"Output", etensor.FLOAT32, []int{2, 4}, nil},
+		{"Emotion", etensor.FLOAT32, []int{1, 2}, nil},
+	}, 4)
+	for row := 0; row < dt.Rows; row++ {
+		dt.SetCellString("Name", row, fmt.Sprintf("p%d", row))
+		for _, col := range []string{"Input", "Output"} {
+			SetPatUnits(dt.CellTensor(col, row), []int{row % 4, 4 + (row+1)%4})
+		}
+		dt.CellTensor("Emotion", row).SetFloat1D(0, float64(row))
+	}
+	gt := &GenTest{}
+	gt.Defaults()
+	gt.NRecomb = 5
+	gt.NNovel = 3
+	if err := gt.Make(etable.NewIdxView(dt), rand.New(rand.NewSource(1))); err != nil {
+		t.Fatal(err)
+	}
+	ot := gt.Table
+	if ot.Rows != 4+5+3 {
+		t.Fatalf("rows: %d, want 12\n", ot.Rows)
+	}
+	row := func(name string) int {
+		for r := 0; r < dt.Rows; r++ {
+			if dt.CellString("Name", r) == name {
+				return r
+			}
+		}
+		return -1
+	}
+	for r := 0; r < ot.Rows; r++ {
+		typ := ot.CellString("Type", r)
+		switch {
+		case r < 4:
+			if typ != GenTrained || ot.CellString("Name", r) != dt.CellString("Name", r) {
+				t.Errorf("row %d: %v %v, want trained item\n", r, typ, ot.CellString("Name", r))
+			}
+		case r < 9:
+			if typ != GenRecomb {
+				t.Errorf("row %d: type %v, want %v\n", r, typ, GenRecomb)
+			}
+			ps := strings.Split(ot.CellString("Parents", r), "+")
+			if len(ps) != 2 {
+				t.Fatalf("row %d: parents: %v\n", r, ot.CellString("Parents", r))
+			}
+			ra, rb := row(ps[0]), row(ps[1])
+			if ra < 0 || rb < 0 || ra == rb {
+				t.Fatalf("row %d: parents: %v\n", r, ot.CellString("Parents", r))
+			}
+			for _, col := range []string{"Input", "Output"} {
+				tsr := ot.CellTensor(col, r)
+				for i := 0; i < 8; i++ {
+					src := ra
+					if i >= 4 {
+						src = rb
+					}
+					if tsr.FloatVal1D(i) != dt.CellTensor(col, src).FloatVal1D(i) {
+						t.Errorf("row %d: %v unit %d not from parent %d\n", r, col, i, src)
+					}
+				}
+			}
+			if ot.CellTensor("Emotion", r).FloatVal1D(0) != float64(ra) {
+				t.Errorf("row %d: Emotion not from the first parent\n", r)
+			}
+		default:
+			if typ != GenNovel {
+				t.Errorf("row %d: type %v, want %v\n", r, typ, GenNovel)
+			}
+			n := 0
+			for i := 0; i < 8; i++ {
+				n += int(ot.CellTensor("Input", r).FloatVal1D(i))
+			}
+			if n != 2 {
+				t.Errorf("row %d: novel item has %d active units, want 2\n", r, n)
+			}
+		}
+	}
+	if gt.Type("p2") != GenTrained || gt.Type("Novel_1") != GenNovel || gt.Type("x") != "" {
+		t.Errorf("types: %v\n", gt.Types)
+	}
+	gt.Cols = []string{"Visual"}
+	if gt.Make(etable.NewIdxView(dt), rand.New(rand.NewSource(1))) == nil {
+		t.Errorf("expected error for a missing column\n")
+	}
+}
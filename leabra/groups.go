@@ -0,0 +1,162 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/emer/emergent/params"
+	"github.com/emer/etable/etensor"
+)
+
+// AddGroup adds (or extends) a named group of layers (e.g., "Cortex", "BLA"), which
+// supports collective operations (GroupApplyParams, GroupLesion, GroupSetLearn,
+// GroupSnapshot).  The group name is also added to the Class of each layer, so
+// params selectors can target the group as a class, e.g., ".BLA".
+// Returns an error if any of the layers are not found.
+func (nt *Network) AddGroup(name string, lays ...string) error {
+	if nt.Groups == nil {
+		nt.Groups = make(map[string][]string)
+	}
+	var err error
+	for _, lnm := range lays {
+		ly, lerr := nt.LayerByNameTry(lnm)
+		if lerr != nil {
+			err = lerr
+			continue
+		}
+		gl := nt.Groups[name]
+		has := false
+		for _, gnm := range gl {
+			if gnm == lnm {
+				has = true
+				break
+			}
+		}
+		if has {
+			continue
+		}
+		nt.Groups[name] = append(gl, lnm)
+		ly.(LeabraLayer).AsLeabra().AddClass(name)
+	}
+	return err
+}
+
+// hasClass returns true if the space-separated class string includes given class
+func hasClass(cls, name string) bool {
+	for _, c := range strings.Fields(cls) {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupNames returns the sorted names of all the layer groups
+func (nt *Network) GroupNames() []string {
+	nms := make([]string, 0, len(nt.Groups))
+	for nm := range nt.Groups {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	return nms
+}
+
+// Group returns the layers in given group, in the order added
+func (nt *Network) Group(name string) ([]*Layer, error) {
+	gl, has := nt.Groups[name]
+	if !has {
+		err := fmt.Errorf("Network.Group: group named: %v not found in Network: %v", name, nt.Nm)
+		log.Println(err)
+		return nil, err
+	}
+	lays := make([]*Layer, 0, len(gl))
+	for _, lnm := range gl {
+		ly := nt.LayerByName(lnm)
+		if ly == nil {
+			continue
+		}
+		lays = append(lays, ly.(LeabraLayer).AsLeabra())
+	}
+	return lays, nil
+}
+
+// GroupApplyParams applies given parameter style Sheet to the layers in the group,
+// and their receiving projections -- see Layer.ApplyParams.
+func (nt *Network) GroupApplyParams(name string, pars *params.Sheet, setMsg bool) (bool, error) {
+	lays, err := nt.Group(name)
+	if err != nil {
+		return false, err
+	}
+	applied := false
+	for _, ly := range lays {
+		app, lerr := ly.ApplyParams(pars, setMsg)
+		if app {
+			applied = true
+		}
+		if lerr != nil {
+			err = lerr
+		}
+	}
+	return applied, err
+}
+
+// GroupLesion lesions (sets the Off flag) for given proportion (0-1) of neurons
+// in each layer of the group, returning the total number lesioned.
+// A proportion of 0 un-lesions all the neurons in the group.
+func (nt *Network) GroupLesion(name string, prop float32) (int, error) {
+	lays, err := nt.Group(name)
+	if err != nil {
+		return 0, err
+	}
+	nl := 0
+	for _, ly := range lays {
+		if prop == 0 {
+			ly.UnLesionNeurons()
+			continue
+		}
+		nl += ly.LesionNeurons(prop)
+	}
+	return nl, nil
+}
+
+// GroupSetLearn turns learning on or off for all of the receiving projections
+// of the layers in the group, e.g., to freeze the weights of a group during
+// some phase of training or sleep.
+func (nt *Network) GroupSetLearn(name string, on bool) error {
+	lays, err := nt.Group(name)
+	if err != nil {
+		return err
+	}
+	for _, ly := range lays {
+		for _, p := range ly.RcvPrjns {
+			p.(LeabraPrjn).AsLeabra().Learn.Learn = on
+		}
+	}
+	return nil
+}
+
+// GroupSnapshot returns a copy of the current values of given neuron variable
+// (e.g., Act) for each layer in the group, as tensors in the shape of the layer,
+// keyed by layer name.
+func (nt *Network) GroupSnapshot(name, varNm string) (map[string]etensor.Tensor, error) {
+	lays, err := nt.Group(name)
+	if err != nil {
+		return nil, err
+	}
+	snap := make(map[string]etensor.Tensor, len(lays))
+	for _, ly := range lays {
+		tsr, lerr := ly.UnitValsTensorTry(varNm)
+		if lerr != nil {
+			log.Println(lerr)
+			return nil, lerr
+		}
+		snap[ly.Nm] = tsr
+	}
+	return snap, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/params"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestGroups(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "GroupNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	h1 := net.AddLayer2D("Hidden1", 2, 2, emer.Hidden)
+	h2 := net.AddLayer2D("Hidden2", 2, 2, emer.Hidden)
+	net.ConnectLayers(inLay, h1, prjn.NewFull(), emer.Forward)
+	net.ConnectLayers(h1, h2, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	net.InitWts()
+
+	if err := net.AddGroup("Cortex", "Hidden1", "Hidden2", "Hidden1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := net.AddGroup("BLA", "NoSuch"); err == nil {
+		t.Errorf("expected error for a layer not found\n")
+	}
+	if lays, err := net.Group("Cortex"); err != nil || len(lays) != 2 || lays[1].Nm != "Hidden2" {
+		t.Fatalf("group: %v: %v\n", lays, err)
+	}
+	net.AddGroup("Sensory", "Input")
+	if nms := net.GroupNames(); len(nms) != 2 || nms[0] != "Cortex" || nms[1] != "Sensory" {
+		t.Errorf("group names: %v\n", nms)
+	}
+	if _, err := net.Group("Thalamus"); err == nil {
+		t.Errorf("expected error for a group not found\n")
+	}
+	if !hasClass(h1.(*Layer).Cls, "Cortex") {
+		t.Errorf("group not added to the class: %v\n", h1.(*Layer).Cls)
+	}
+
+	// params selectors can use the group as a class
+	sheet := &params.Sheet{{Sel: ".Cortex", Params: params.Params{"Layer.Inhib.Layer.Gi": "2.5"}}}
+	if app, err := net.GroupApplyParams("Cortex", sheet, false); !app || err != nil {
+		t.Errorf("GroupApplyParams: applied %v: %v\n", app, err)
+	}
+	if gi := h2.(*Layer).Inhib.Layer.Gi; gi != 2.5 {
+		t.Errorf("Hidden2 Gi: %v, want 2.5\n", gi)
+	}
+	if gi := inLay.(*Layer).Inhib.Layer.Gi; gi == 2.5 {
+		t.Errorf("Input Gi set by the group params\n")
+	}
+
+	if nl, err := net.GroupLesion("Cortex", 0.5); err != nil || nl != 4 {
+		t.Errorf("GroupLesion: %v: %v, want 4\n", nl, err)
+	}
+	net.GroupLesion("Cortex", 0)
+	for ni := range h1.(*Layer).Neurons {
+		if h1.(*Layer).Neurons[ni].IsOff() {
+			t.Errorf("unit %d still lesioned\n", ni)
+		}
+	}
+
+	// a lesion event targeting the group applies to each of its layers
+	le := &LesionEvent{Scope: "Epoch", Ctr: 1, Act: LesionUnits, Target: "Cortex", Prop: 0.25}
+	if nu, units, err := le.Apply(net); err != nil || nu != 2 || !strings.Contains(units, "Hidden2: ") {
+		t.Errorf("group lesion event: %v %q: %v\n", nu, units, err)
+	}
+	net.GroupLesion("Cortex", 0)
+
+	if err := net.GroupSetLearn("Cortex", false); err != nil {
+		t.Fatal(err)
+	}
+	for _, pnm := range []string{"InputToHidden1", "Hidden1ToHidden2"} {
+		if net.PrjnByName(pnm).Learn.Learn {
+			t.Errorf("%v learning not turned off\n", pnm)
+		}
+	}
+
+	h1.(*Layer).Neurons[1].Act = 0.75
+	snap, err := net.GroupSnapshot("Cortex", "Act")
+	if err != nil || len(snap) != 2 {
+		t.Fatalf("snapshot: %v: %v\n", snap, err)
+	}
+	if v := snap["Hidden1"].FloatVal1D(1); v != 0.75 {
+		t.Errorf("snapshot Act: %v, want 0.75\n", v)
+	}
+	h1.(*Layer).Neurons[1].Act = 0
+	if v := snap["Hidden1"].FloatVal1D(1); v != 0.75 {
+		t.Errorf("snapshot not a copy: %v\n", v)
+	}
+	if _, err := net.GroupSnapshot("Cortex", "NoSuchVar"); err == nil {
+		t.Errorf("expected error for an unknown variable\n")
+	}
+}
@@ -0,0 +1,452 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/params"
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/kit"
+)
+
+// HyperPrior is the prior distribution for a hyperparameter
+type HyperPrior int32
+
+//go:generate stringer -type=HyperPrior
+
+var KiT_HyperPrior = kit.Enums.AddEnum(HyperPriorN, false, nil)
+
+func (ev HyperPrior) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *HyperPrior) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+const (
+	// HyperUniform is uniform between Min and Max
+	HyperUniform HyperPrior = iota
+
+	// HyperLogUniform is log-uniform between Min and Max, which must be > 0
+	HyperLogUniform
+
+	// HyperNormal is normal with Mean and SD, clipped to Min, Max
+	HyperNormal
+
+	HyperPriorN
+)
+
+// HyperParam is search metadata for one parameter: the range and prior to
+// search over, for use by an Optimizer.
+type HyperParam struct {
+	Sel   string     `desc:"params selector to apply value to, e.g., Layer, .Back, #Output, Sim -- defaults to the first element of Path"`
+	Path  string     `desc:"param path, e.g., Layer.Inhib.Layer.GiOscMin"`
+	Min   float64    `desc:"minimum value"`
+	Max   float64    `desc:"maximum value"`
+	Prior HyperPrior `desc:"prior distribution over the range"`
+	Mean  float64    `viewif:"Prior=HyperNormal" desc:"mean for HyperNormal prior"`
+	SD    float64    `viewif:"Prior=HyperNormal" desc:"standard deviation for HyperNormal prior"`
+	Int   bool       `desc:"value is an integer -- rounded when applied"`
+	Sleep bool       `desc:"param applies during sleep, on top of the sleep params, instead of during wake -- see HyperStudy.SleepSet"`
+}
+
+// Name returns the name of the param, including the selector if not the default
+func (hp *HyperParam) Name() string {
+	if hp.Sel == "" || hp.Sel == ParamPathType(hp.Path) {
+		return hp.Path
+	}
+	return hp.Sel + " " + hp.Path
+}
+
+// Selector returns the selector, defaulting to the type element of the Path
+func (hp *HyperParam) Selector() string {
+	if hp.Sel == "" {
+		return ParamPathType(hp.Path)
+	}
+	return hp.Sel
+}
+
+// ToUnit maps a value to the unit interval, in the space of the prior
+// (log space for HyperLogUniform)
+func (hp *HyperParam) ToUnit(val float64) float64 {
+	if hp.Max == hp.Min {
+		return 0
+	}
+	if hp.Prior == HyperLogUniform && hp.Min > 0 {
+		return (math.Log(val) - math.Log(hp.Min)) / (math.Log(hp.Max) - math.Log(hp.Min))
+	}
+	return (val - hp.Min) / (hp.Max - hp.Min)
+}
+
+// FmUnit maps a value in the unit interval back to the param range
+func (hp *HyperParam) FmUnit(u float64) float64 {
+	u = math.Max(0, math.Min(1, u))
+	var val float64
+	if hp.Prior == HyperLogUniform && hp.Min > 0 {
+		lmin := math.Log(hp.Min)
+		val = math.Exp(lmin + u*(math.Log(hp.Max)-lmin))
+	} else {
+		val = hp.Min + u*(hp.Max-hp.Min)
+	}
+	if hp.Int {
+		val = math.Round(val)
+	}
+	return val
+}
+
+// Sample returns a random sample from the prior
+func (hp *HyperParam) Sample(rnd *rand.Rand) float64 {
+	if hp.Prior == HyperNormal {
+		val := hp.Mean + hp.SD*rnd.NormFloat64()
+		val = math.Max(hp.Min, math.Min(hp.Max, val))
+		if hp.Int {
+			val = math.Round(val)
+		}
+		return val
+	}
+	return hp.FmUnit(rnd.Float64())
+}
+
+// ValString returns the value as a string for params
+func (hp *HyperParam) ValString(val float64) string {
+	if hp.Int {
+		return strconv.Itoa(int(math.Round(val)))
+	}
+	return strconv.FormatFloat(val, 'g', 6, 64)
+}
+
+// HyperTrial is one evaluated (or pending) point in a HyperStudy
+type HyperTrial struct {
+	Idx  int       `desc:"index of trial"`
+	Vals []float64 `desc:"param values, one per study param"`
+	Obj  float64   `desc:"objective value (as reported, not negated for Maximize)"`
+	Done bool      `desc:"true when the objective has been reported"`
+}
+
+// Optimizer suggests points to evaluate and is told the results, in a
+// suggest -> run -> report loop driven by a HyperStudy.  Objectives are always
+// minimized -- the study negates them when maximizing.
+// Use this to plug in external optimizers.
+type Optimizer interface {
+	// Init initializes the optimizer for given params and random seed
+	Init(hps []HyperParam, seed int64)
+
+	// Suggest returns the next point to evaluate, given all the trials so far
+	Suggest(trials []HyperTrial) []float64
+
+	// Report tells the optimizer the objective (to minimize) for a trial --
+	// optimizers that only use the trials passed to Suggest can ignore this
+	Report(trial *HyperTrial, obj float64)
+}
+
+// RandomOpt is an Optimizer that samples from the priors
+type RandomOpt struct {
+	Params []HyperParam `view:"-" desc:"params being searched"`
+	Rnd    *rand.Rand   `view:"-" desc:"random number generator"`
+}
+
+func (ro *RandomOpt) Init(hps []HyperParam, seed int64) {
+	ro.Params = hps
+	ro.Rnd = rand.New(rand.NewSource(seed))
+}
+
+func (ro *RandomOpt) Suggest(trials []HyperTrial) []float64 {
+	vals := make([]float64, len(ro.Params))
+	for i := range ro.Params {
+		vals[i] = ro.Params[i].Sample(ro.Rnd)
+	}
+	return vals
+}
+
+func (ro *RandomOpt) Report(trial *HyperTrial, obj float64) {}
+
+// TPEOpt is a Tree-structured Parzen Estimator optimizer (Bergstra et al, 2011),
+// with independent per-param densities: after NStartup random trials, the
+// completed trials are split into the best Gamma proportion and the rest,
+// a Parzen (Gaussian kernel) density is fit to each in the unit space of
+// each param, and the candidate that maximizes the ratio of good to bad
+// density is chosen from NCands samples drawn from the good density.
+// As in the hyperopt implementation, each kernel's bandwidth adapts to the
+// distance to its neighboring points, with a floor that shrinks as the
+// number of points grows, so the search can narrow in on an optimum.
+type TPEOpt struct {
+	NStartup int          `def:"10" desc:"number of initial random trials"`
+	Gamma    float64      `def:"0.25" desc:"proportion of trials considered good"`
+	NCands   int          `def:"24" desc:"number of candidates sampled from the good density"`
+	Params   []HyperParam `view:"-" desc:"params being searched"`
+	Rnd      *rand.Rand   `view:"-" desc:"random number generator"`
+	rndOpt   RandomOpt
+}
+
+// Defaults sets default params
+func (to *TPEOpt) Defaults() {
+	to.NStartup = 10
+	to.Gamma = 0.25
+	to.NCands = 24
+}
+
+func (to *TPEOpt) Init(hps []HyperParam, seed int64) {
+	if to.NStartup == 0 {
+		to.Defaults()
+	}
+	to.Params = hps
+	to.Rnd = rand.New(rand.NewSource(seed))
+	to.rndOpt.Params = hps
+	to.rndOpt.Rnd = to.Rnd
+}
+
+func (to *TPEOpt) Report(trial *HyperTrial, obj float64) {}
+
+// parzenBWs returns the kernel bandwidth for each of pts in the unit interval:
+// the larger of the distances to its neighbors (or the interval edges),
+// clipped between 1 / min(100, n+1) and 1
+func parzenBWs(pts []float64) []float64 {
+	n := len(pts)
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool { return pts[idx[i]] < pts[idx[j]] })
+	minbw := 1 / math.Min(100, float64(n+1))
+	bws := make([]float64, n)
+	for k, i := range idx {
+		lo := pts[i]
+		if k > 0 {
+			lo = pts[i] - pts[idx[k-1]]
+		}
+		hi := 1 - pts[i]
+		if k < n-1 {
+			hi = pts[idx[k+1]] - pts[i]
+		}
+		bws[i] = math.Max(minbw, math.Min(1, math.Max(lo, hi)))
+	}
+	return bws
+}
+
+// parzen returns the log density at x of a Gaussian-kernel mixture over pts
+// in the unit interval with bandwidths bws, with a uniform prior component
+func parzen(x float64, pts, bws []float64) float64 {
+	p := 1.0 // uniform prior, weight 1
+	for i, pt := range pts {
+		d := (x - pt) / bws[i]
+		p += math.Exp(-0.5*d*d) / (bws[i] * math.Sqrt(2*math.Pi))
+	}
+	return math.Log(p / float64(len(pts)+1))
+}
+
+func (to *TPEOpt) Suggest(trials []HyperTrial) []float64 {
+	var done []*HyperTrial
+	for i := range trials {
+		if trials[i].Done && !math.IsNaN(trials[i].Obj) {
+			done = append(done, &trials[i])
+		}
+	}
+	if len(done) < to.NStartup || len(done) < 2 {
+		return to.rndOpt.Suggest(trials)
+	}
+	sort.SliceStable(done, func(i, j int) bool { return done[i].Obj < done[j].Obj })
+	ng := int(math.Ceil(to.Gamma * float64(len(done))))
+	if ng >= len(done) {
+		ng = len(done) - 1
+	}
+	vals := make([]float64, len(to.Params))
+	for pi := range to.Params {
+		hp := &to.Params[pi]
+		good := make([]float64, ng)
+		bad := make([]float64, len(done)-ng)
+		for i, tr := range done {
+			u := hp.ToUnit(tr.Vals[pi])
+			if i < ng {
+				good[i] = u
+			} else {
+				bad[i-ng] = u
+			}
+		}
+		bwg := parzenBWs(good)
+		bwb := parzenBWs(bad)
+		best := math.Inf(-1)
+		bestU := 0.5
+		for ci := 0; ci < to.NCands; ci++ {
+			var u float64
+			if k := to.Rnd.Intn(len(good) + 1); k < len(good) {
+				u = good[k] + bwg[k]*to.Rnd.NormFloat64()
+			} else { // prior component
+				u = to.Rnd.Float64()
+			}
+			u = math.Max(0, math.Min(1, u))
+			if r := parzen(u, good, bwg) - parzen(u, bad, bwb); r > best {
+				best = r
+				bestU = u
+			}
+		}
+		vals[pi] = hp.FmUnit(bestU)
+	}
+	return vals
+}
+
+// HyperStudy manages a hyperparameter optimization: the params to search,
+// the Optimizer, and the record of trials, which can be saved and reopened
+// to resume.  The sim drives the loop: Suggest a trial, apply Set(trial)
+// on top of its Base params, run, and Report the objective.
+type HyperStudy struct {
+	Name     string       `desc:"name of the study"`
+	Params   []HyperParam `desc:"params to search"`
+	Obj      string       `desc:"name of the objective, e.g., TstPctCor for post-sleep test percent correct"`
+	Maximize bool         `desc:"maximize the objective instead of minimizing it"`
+	Seed     int64        `desc:"random seed for the optimizer"`
+	Trials   []HyperTrial `desc:"trials so far"`
+	Opt      Optimizer    `json:"-" view:"-" desc:"the optimizer -- defaults to TPEOpt"`
+}
+
+// Init initializes the optimizer, defaulting to TPEOpt if none set.
+// Existing trials are kept, so a reopened study resumes.
+func (hs *HyperStudy) Init() {
+	if hs.Opt == nil {
+		to := &TPEOpt{}
+		to.Defaults()
+		hs.Opt = to
+	}
+	hs.Opt.Init(hs.Params, hs.Seed+int64(len(hs.Trials)))
+}
+
+// optTrials returns trials with objectives negated if maximizing
+func (hs *HyperStudy) optTrials() []HyperTrial {
+	if !hs.Maximize {
+		return hs.Trials
+	}
+	trs := make([]HyperTrial, len(hs.Trials))
+	copy(trs, hs.Trials)
+	for i := range trs {
+		trs[i].Obj = -trs[i].Obj
+	}
+	return trs
+}
+
+// Suggest adds a new trial with values suggested by the optimizer, and returns it
+func (hs *HyperStudy) Suggest() *HyperTrial {
+	if hs.Opt == nil {
+		hs.Init()
+	}
+	vals := hs.Opt.Suggest(hs.optTrials())
+	hs.Trials = append(hs.Trials, HyperTrial{Idx: len(hs.Trials), Vals: vals})
+	return &hs.Trials[len(hs.Trials)-1]
+}
+
+// Report records the objective for given trial index
+func (hs *HyperStudy) Report(idx int, obj float64) {
+	tr := &hs.Trials[idx]
+	tr.Obj = obj
+	tr.Done = true
+	if hs.Opt != nil {
+		oobj := obj
+		if hs.Maximize {
+			oobj = -obj
+		}
+		hs.Opt.Report(tr, oobj)
+	}
+}
+
+// Best returns the best completed trial, or nil if none
+func (hs *HyperStudy) Best() *HyperTrial {
+	var best *HyperTrial
+	for i := range hs.Trials {
+		tr := &hs.Trials[i]
+		if !tr.Done || math.IsNaN(tr.Obj) {
+			continue
+		}
+		if best == nil || (hs.Maximize && tr.Obj > best.Obj) || (!hs.Maximize && tr.Obj < best.Obj) {
+			best = tr
+		}
+	}
+	return best
+}
+
+// TrialDesc returns a description of the param values for a trial
+func (hs *HyperStudy) TrialDesc(tr *HyperTrial) string {
+	strs := make([]string, len(hs.Params))
+	for i := range hs.Params {
+		strs[i] = hs.Params[i].Name() + "=" + hs.Params[i].ValString(tr.Vals[i])
+	}
+	return strings.Join(strs, " ")
+}
+
+// Set returns a params.Set with the values for given trial for the wake
+// (non-Sleep) params, with Network and Sim sheets as needed, to be applied
+// on top of the Base params
+func (hs *HyperStudy) Set(tr *HyperTrial) *params.Set {
+	return hs.set(tr, false, fmt.Sprintf("%s_%03d", hs.Name, tr.Idx))
+}
+
+// SleepSet returns a params.Set with the values for given trial for the
+// Sleep params, to be applied on top of the sleep params, or nil if none
+func (hs *HyperStudy) SleepSet(tr *HyperTrial) *params.Set {
+	pset := hs.set(tr, true, fmt.Sprintf("%s_%03d_Sleep", hs.Name, tr.Idx))
+	if len(pset.Sheets) == 0 {
+		return nil
+	}
+	return pset
+}
+
+func (hs *HyperStudy) set(tr *HyperTrial, sleep bool, name string) *params.Set {
+	return HyperParamsSet(hs.Params, tr.Vals, sleep, name, hs.TrialDesc(tr), "hyperparam "+hs.Name)
+}
+
+// HyperParamsSet returns a params.Set with given values for the params with
+// given Sleep setting, with Network and Sim sheets as needed, and given
+// set name, set description and sel description
+func HyperParamsSet(hps []HyperParam, vals []float64, sleep bool, name, desc, seldesc string) *params.Set {
+	pset := &params.Set{Name: name, Desc: desc, Sheets: params.Sheets{}}
+	for i := range hps {
+		hp := &hps[i]
+		if hp.Sleep != sleep {
+			continue
+		}
+		shnm := "Network"
+		if ParamPathType(hp.Path) == "Sim" {
+			shnm = "Sim"
+		}
+		sh, ok := pset.Sheets[shnm]
+		if !ok {
+			sh = &params.Sheet{}
+			pset.Sheets[shnm] = sh
+		}
+		*sh = append(*sh, &params.Sel{Sel: hp.Selector(), Desc: seldesc,
+			Params: params.Params{hp.Path: hp.ValString(vals[i])}})
+	}
+	return pset
+}
+
+// SaveJSON saves the study, including all trials, to a JSON file
+func (hs *HyperStudy) SaveJSON(filename gi.FileName) error {
+	b, err := json.MarshalIndent(hs, "", "  ")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// OpenJSON opens a study from a JSON file -- call Init after to resume
+func (hs *HyperStudy) OpenJSON(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = json.Unmarshal(b, hs)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
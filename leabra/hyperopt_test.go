@@ -0,0 +1,33 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHyperStudy(t *testing.T) {
+	hs := &HyperStudy{Name: "Test", Seed: 1, Params: []HyperParam{
+		{Path: "Layer.Inhib.Layer.Gi", Min: 1, Max: 3},
+		{Sel: ".Back", Path: "Prjn.WtScale.Rel", Min: 0.001, Max: 1, Prior: HyperLogUniform, Sleep: true},
+	}}
+	hs.Init()
+	for i := 0; i < 60; i++ {
+		tr := hs.Suggest()
+		x, y := tr.Vals[0], math.Log10(tr.Vals[1])
+		hs.Report(tr.Idx, (x-1.6)*(x-1.6)+(y+2)*(y+2))
+	}
+	best := hs.Best()
+	if best == nil || best.Obj > 0.01 {
+		t.Errorf("TPE did not find optimum: %+v\n", best)
+	}
+	if ws := hs.Set(best); len(*ws.Sheets["Network"]) != 1 {
+		t.Errorf("wake set should have 1 param\n")
+	}
+	if ss := hs.SleepSet(best); ss == nil || (*ss.Sheets["Network"])[0].Sel != ".Back" {
+		t.Errorf("sleep set should have the .Back param\n")
+	}
+}
@@ -22,7 +22,6 @@ import (
 	"github.com/goki/ki/ints"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
-	"gonum.org/v1/gonum/stat"
 )
 
 // leabra.Layer has parameters for running a basic rate-coded Leabra layer
@@ -31,10 +30,19 @@ type Layer struct {
 	Act     ActParams       `desc:"Activation parameters and methods for computing activations"`
 	Inhib   InhibParams     `desc:"Inhibition parameters and methods for computing layer-level inhibition"`
 	Learn   LearnNeurParams `desc:"Learning parameters and methods that operate at the neuron level"`
+	LaySim  LaySimParams    `desc:"parameters for the similarity of the activity over time computed in Sim -- metric and comparison window"`
 	Neurons []Neuron        `desc:"slice of neurons for this layer -- flat list of len = Shp.Len(). You must iterate over index and use pointer to modify values."`
 	Pools   []Pool          `desc:"inhibition and other pooled, aggregate state variables -- flat list has at least of 1 for layer, and one for each sub-pool (unit group) if shape supports that (4D).  You must iterate over index and use pointer to modify values."`
 	CosDiff CosDiffStats    `desc:"cosine difference between ActM, ActP stats"`
-	Sim     float64         `desc:"Similarity between current cycle and previous cycle."`
+	Sim     float64         `desc:"Similarity between current cycle and previous cycle (see LaySim)."`
+	NeurThr int             `desc:"number of parallel go routines to split the neuron-level loops of this layer across (GeGiFmInc, ActFmG) -- 0 or 1 = no splitting -- set automatically for large layers by Network.AutoThreads"`
+	SynView *SynView        `view:"-" json:"-" desc:"unit that the r. and s. synapse variables are viewed relative to -- shared by all the layers of the network, see Network.SetSynView"`
+
+	Rnd *rand.Rand `view:"-" json:"-" desc:"random number stream for this layer, used for noise -- nil = use global generator -- see Network.SeedLayerRnd"`
+
+	rndSrc     *RndSource   // source of Rnd, with its state for Network.RndStates
+	sndPrjnsOn []LeabraPrjn // active sending prjns, reused buffer for SendGDelta, CalSynDep
+	simSt      laySimState  // past activity for CalLaySim
 }
 
 var KiT_Layer = kit.Types.AddType(&Layer{}, LayerProps)
@@ -50,29 +58,20 @@ func (ly *Layer) Defaults() {
 	ly.Act.Defaults()
 	ly.Inhib.Defaults()
 	ly.Learn.Defaults()
+	ly.LaySim.Defaults()
 	ly.Inhib.Layer.On = true
 	for _, pj := range ly.RcvPrjns {
 		pj.Defaults()
 	}
 }
 
-// CalLaySim calculate the similarity of the PrevState and CurState of activation.
-func (ly *Layer) CalLaySim(ltime *Time) {
-	var PrevState []float64
-	var CurState []float64
-	for _, n := range ly.Neurons {
-		PrevState = append(PrevState, float64(n.ActSent))
-		CurState = append(CurState, float64(n.Act))
-	}
-	ly.Sim = stat.Correlation(PrevState, CurState, nil)
-}
-
 // UpdateParams updates all params given any changes that might have been made to individual values
 // including those in the receiving projections of this layer
 func (ly *Layer) UpdateParams() {
 	ly.Act.Update()
 	ly.Inhib.Update()
 	ly.Learn.Update()
+	ly.LaySim.Update()
 	for _, pj := range ly.RcvPrjns {
 		pj.UpdateParams()
 	}
@@ -98,6 +97,8 @@ func (ly *Layer) AllParams() string {
 	str += "Inhib: {\n " + JsonToParams(b)
 	b, _ = json.MarshalIndent(&ly.Learn, "", " ")
 	str += "Learn: {\n " + JsonToParams(b)
+	b, _ = json.MarshalIndent(&ly.LaySim, "", " ")
+	str += "LaySim: {\n " + JsonToParams(b)
 	for _, pj := range ly.RcvPrjns {
 		pstr := pj.AllParams()
 		str += pstr
@@ -106,8 +107,9 @@ func (ly *Layer) AllParams() string {
 }
 
 // UnitVarNames returns a list of variable names available on the units in this layer
+// including the r. and s. synapse variables (see SynViewVals)
 func (ly *Layer) UnitVarNames() []string {
-	return NeuronVars
+	return UnitViewVars
 }
 
 // UnitVals is emer.Layer interface method to return values of given variable
@@ -118,6 +120,9 @@ func (ly *Layer) UnitVals(varNm string) []float32 {
 
 // UnitValsTry is emer.Layer interface method to return values of given variable
 func (ly *Layer) UnitValsTry(varNm string) ([]float32, error) {
+	if IsSynViewVar(varNm) {
+		return ly.SynViewVals(varNm)
+	}
 	vidx, err := NeuronVarByName(varNm)
 	if err != nil {
 		return nil, err
@@ -162,6 +167,9 @@ func (ly *Layer) UnitValTry(varNm string, idx []int) (float32, error) {
 	if fidx < 0 || fidx >= nn {
 		return 0, fmt.Errorf("Layer UnitVal index: %v out of range, N = %v", fidx, nn)
 	}
+	if IsSynViewVar(varNm) {
+		return ly.SynViewVal(varNm, fidx)
+	}
 	nrn := &ly.Neurons[fidx]
 	return nrn.VarByName(varNm)
 }
@@ -180,6 +188,9 @@ func (ly *Layer) UnitVal1DTry(varNm string, idx int) (float32, error) {
 	if idx < 0 || idx >= nn {
 		return 0, fmt.Errorf("Layer UnitVal1D index: %v out of range, N = %v", idx, nn)
 	}
+	if IsSynViewVar(varNm) {
+		return ly.SynViewVal(varNm, idx)
+	}
 	nrn := &ly.Neurons[idx]
 	return nrn.VarByName(varNm)
 }
@@ -308,18 +319,62 @@ func (ly *Layer) WriteWtsJSON(w io.Writer, depth int) {
 }
 
 // ReadWtsJSON reads the weights from this layer from the receiver-side perspective
-// in a JSON text format.
+// in a JSON text format.  This is for a set of weights that were saved *for one layer only*
+// and is not used for the network-level ReadWtsJSON, which reads into a separate
+// structure -- see SetWts method.
 func (ly *Layer) ReadWtsJSON(r io.Reader) error {
-	return nil
+	lw, err := ReadLayWtsJSON(r)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	return ly.SetWts(lw)
 }
 
-// VarRange returns the min / max values for given variable
-// todo: support r. s. projection values
+// SetWts sets the weights for this layer from LayWts decoded values,
+// for each receiving projection in the weights.  Returns an error if a
+// projection is not found or does not match, but continues to set the rest.
+func (ly *Layer) SetWts(lw *LayWts) error {
+	var err error
+	for pi := range lw.Prjns {
+		pw := &lw.Prjns[pi]
+		p := ly.RcvPrjns.SendName(pw.From)
+		if p == nil {
+			err = fmt.Errorf("Layer.SetWts: %v projection from: %v not found", ly.Nm, pw.From)
+			log.Println(err)
+			continue
+		}
+		if perr := p.(LeabraPrjn).AsLeabra().SetWts(pw); perr != nil {
+			err = perr
+		}
+	}
+	return err
+}
+
+// VarRange returns the min / max values for given variable, including the
+// r. and s. synapse variables
 func (ly *Layer) VarRange(varNm string) (min, max float32, err error) {
 	sz := len(ly.Neurons)
 	if sz == 0 {
 		return
 	}
+	if IsSynViewVar(varNm) {
+		var vs []float32
+		vs, err = ly.SynViewVals(varNm)
+		if err != nil {
+			return
+		}
+		min, max = vs[0], vs[0]
+		for _, vl := range vs[1:] {
+			if vl < min {
+				min = vl
+			}
+			if vl > max {
+				max = vl
+			}
+		}
+		return
+	}
 	vidx := 0
 	vidx, err = NeuronVarByName(varNm)
 	if err != nil {
@@ -390,6 +445,7 @@ func (ly *Layer) InitActs() {
 		nrn := &ly.Neurons[ni]
 		ly.Act.InitActs(nrn)
 	}
+	ly.simSt.Reset()
 }
 
 // InitWtsSym initializes the weight symmetry -- higher layers copy weights from lower layers
@@ -629,11 +685,26 @@ func (ly *Layer) GScaleFmAvgAct() {
 	}
 }
 
-// GenNoise generates random noise for all neurons
+// GenNoise generates random noise for all neurons, using the layer's own
+// random number stream if set (see Network.SeedLayerRnd)
 func (ly *Layer) GenNoise() {
 	for ni := range ly.Neurons {
 		nrn := &ly.Neurons[ni]
-		nrn.Noise = float32(ly.Act.Noise.Gen(-1))
+		nrn.Noise = ly.Act.Noise.GenRnd(ly.Rnd)
+	}
+}
+
+// GenNoiseOn generates random noise for the neurons that are not Off, in
+// order, using the layer's own random number stream if set -- this is used
+// for the non-Fixed noise on each cycle, and makes the same draws, in the
+// same order, as generating it per neuron in ActParams.GeGiFmInc.
+func (ly *Layer) GenNoiseOn() {
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		nrn.Noise = ly.Act.Noise.GenRnd(ly.Rnd)
 	}
 }
 
@@ -692,37 +763,53 @@ func (ly *Layer) InitGInc() {
 }
 
 // SendGDelta sends change in activation since last sent, to increment recv
-// synaptic conductances G, if above thresholds
+// synaptic conductances G, if above thresholds.
+// The active sending projections are resolved once per call, so there are
+// no type assertions or Off checks per neuron.
 func (ly *Layer) SendGDelta(ltime *Time, sleep bool) {
+	sps := ly.activeSndPrjns()
+	if len(sps) == 0 {
+		return
+	}
+	sendThr := ly.Act.OptThresh.Send
+	delThr := ly.Act.OptThresh.Delta
 	for ni := range ly.Neurons {
 		nrn := &ly.Neurons[ni]
 		if nrn.IsOff() {
 			continue
 		}
-		if nrn.Act > ly.Act.OptThresh.Send {
+		if nrn.Act > sendThr {
 			delta := nrn.Act - nrn.ActSent
-			if math32.Abs(delta) > ly.Act.OptThresh.Delta {
-				for _, sp := range ly.SndPrjns {
-					if sp.IsOff() {
-						continue
-					}
-					sp.(LeabraPrjn).SendGDelta(ni, delta, sleep)
+			if math32.Abs(delta) > delThr {
+				for _, sp := range sps {
+					sp.SendGDelta(ni, delta, sleep)
 				}
 				nrn.ActSent = nrn.Act
 			}
-		} else if nrn.ActSent > ly.Act.OptThresh.Send {
+		} else if nrn.ActSent > sendThr {
 			delta := -nrn.ActSent // un-send the last above-threshold activation to get back to 0
-			for _, sp := range ly.SndPrjns {
-				if sp.IsOff() {
-					continue
-				}
-				sp.(LeabraPrjn).SendGDelta(ni, delta, sleep)
+			for _, sp := range sps {
+				sp.SendGDelta(ni, delta, sleep)
 			}
 			nrn.ActSent = 0
 		}
 	}
 }
 
+// activeSndPrjns returns the sending projections that are not Off, using
+// a buffer on the layer to avoid allocating every cycle
+func (ly *Layer) activeSndPrjns() []LeabraPrjn {
+	sps := ly.sndPrjnsOn[:0]
+	for _, sp := range ly.SndPrjns {
+		if sp.IsOff() {
+			continue
+		}
+		sps = append(sps, sp.(LeabraPrjn))
+	}
+	ly.sndPrjnsOn = sps
+	return sps
+}
+
 // GFmInc integrates new synaptic conductances from increments sent during last SendGDelta.
 func (ly *Layer) GFmInc(ltime *Time) {
 	for _, p := range ly.RcvPrjns {
@@ -731,12 +818,25 @@ func (ly *Layer) GFmInc(ltime *Time) {
 		}
 		p.(LeabraPrjn).RecvGInc()
 	}
-	for ni := range ly.Neurons {
+	if ly.Act.Noise.Type != NoNoise && !ly.Act.Noise.Fixed && ly.Act.Noise.Dist != erand.Mean {
+		ly.GenNoiseOn() // serially, so it does not depend on NeurThr
+	}
+	if ly.NeurThr <= 1 { // avoid allocating a method value
+		ly.GeGiFmIncRange(0, len(ly.Neurons))
+		return
+	}
+	ly.NeurRangeFun(ly.GeGiFmIncRange)
+}
+
+// GeGiFmIncRange integrates the Ge and Gi conductances from the increments,
+// for neurons in range st..ed
+func (ly *Layer) GeGiFmIncRange(st, ed int) {
+	for ni := st; ni < ed; ni++ {
 		nrn := &ly.Neurons[ni]
 		if nrn.IsOff() {
 			continue
 		}
-		ly.Act.GeGiFmInc(nrn)
+		ly.Act.GeGiFmIncNoise(nrn)
 	}
 }
 
@@ -758,18 +858,19 @@ func (ly *Layer) GFmInc(ltime *Time) {
 
 // CalSynDep computes the Sender-Receiver co-activation based synaptic depression, added by DH.
 func (ly *Layer) CalSynDep(ltime *Time) {
+	sps := ly.activeSndPrjns()
+	if len(sps) == 0 {
+		return
+	}
 	for ni := range ly.Neurons {
 		nrn := &ly.Neurons[ni]
 		if nrn.IsOff() {
 			continue
 		}
-		for _, sp := range ly.SndPrjns {
-			if sp.IsOff() {
-				continue
-			}
-			sp.(LeabraPrjn).CaUpdt(ni, nrn.Act)
-			sp.(LeabraPrjn).CalSynDep(ni)
-			//		sp.(LeabraPrjn).MonChge(ni)
+		for _, sp := range sps {
+			sp.CaUpdt(ni, nrn.Act)
+			sp.CalSynDep(ni)
+			//		sp.MonChge(ni)
 		}
 	}
 }
@@ -859,7 +960,17 @@ func (ly *Layer) InhibFmGeAct(ltime *Time) {
 // ActFmG computes rate-code activation from Ge, Gi, Gl conductances
 // and updates learning running-average activations from that Act
 func (ly *Layer) ActFmG(ltime *Time) {
-	for ni := range ly.Neurons {
+	if ly.NeurThr <= 1 { // avoid allocating a method value
+		ly.ActFmGRange(0, len(ly.Neurons))
+		return
+	}
+	ly.NeurRangeFun(ly.ActFmGRange)
+}
+
+// ActFmGRange computes rate-code activation from Ge, Gi, Gl conductances,
+// for neurons in range st..ed
+func (ly *Layer) ActFmGRange(st, ed int) {
+	for ni := st; ni < ed; ni++ {
 		nrn := &ly.Neurons[ni]
 		if nrn.IsOff() {
 			continue
@@ -0,0 +1,204 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+
+	"github.com/goki/ki/kit"
+)
+
+// SimMetrics are the metrics for the similarity of layer activity over time
+// computed by Layer.CalLaySim
+type SimMetrics int32
+
+//go:generate stringer -type=SimMetrics
+
+var KiT_SimMetrics = kit.Enums.AddEnum(SimMetricsN, false, nil)
+
+func (ev SimMetrics) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *SimMetrics) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// The similarity metrics
+const (
+	// SimCorrelation is the Pearson correlation
+	SimCorrelation SimMetrics = iota
+
+	// SimCosine is the cosine: the dot product divided by the product of the
+	// vector lengths, without subtracting the means
+	SimCosine
+
+	// SimNormDot is the dot product divided by the number of units
+	SimNormDot
+
+	SimMetricsN
+)
+
+// Similarity computes the similarity between two activity vectors with a
+// given metric -- Layer.CalLaySim uses LaySimFunc, so that other
+// implementations can be plugged in
+type Similarity interface {
+	Sim(metric SimMetrics, a, b []float64) float64
+}
+
+// LaySimFunc is the Similarity used by Layer.CalLaySim
+var LaySimFunc Similarity = StdSim{}
+
+// StdSim is the standard Similarity, computed directly.  The correlation and
+// cosine are NaN when undefined, i.e., when either vector has zero variance
+// (or length), as with gonum's stat.Correlation used originally.
+type StdSim struct{}
+
+func (sm StdSim) Sim(metric SimMetrics, a, b []float64) float64 {
+	n := len(a)
+	if n == 0 {
+		return 0
+	}
+	switch metric {
+	case SimCorrelation:
+		ma, mb := 0.0, 0.0
+		for i := range a {
+			ma += a[i]
+			mb += b[i]
+		}
+		ma /= float64(n)
+		mb /= float64(n)
+		return nanCos(a, b, ma, mb)
+	case SimCosine:
+		return nanCos(a, b, 0, 0)
+	case SimNormDot:
+		dot := 0.0
+		for i := range a {
+			dot += a[i] * b[i]
+		}
+		return dot / float64(n)
+	}
+	return 0
+}
+
+// nanCos returns the cosine of a and b with means ma and mb subtracted,
+// which is NaN if either has zero length
+func nanCos(a, b []float64, ma, mb float64) float64 {
+	ab, aa, bb := 0.0, 0.0, 0.0
+	for i := range a {
+		av := a[i] - ma
+		bv := b[i] - mb
+		ab += av * bv
+		aa += av * av
+		bb += bv * bv
+	}
+	if aa == 0 || bb == 0 {
+		return math.NaN()
+	}
+	return ab / math.Sqrt(aa*bb)
+}
+
+// LaySimParams are the params for the similarity of layer activity over
+// time computed by Layer.CalLaySim into Layer.Sim: the current Act is
+// compared with a reference of the past activity, which is Act Lag cycles
+// ago (or ActSent, the last activation sent, if Lag = 0), optionally
+// exponentially smoothed over cycles with time constant Tau
+type LaySimParams struct {
+	Metric  SimMetrics `desc:"similarity metric"`
+	Lag     int        `min:"0" desc:"number of cycles back to compare the current Act with -- 0 = ActSent, the last activation sent, which lags Act by one cycle when it changes by more than the OptThresh.Delta"`
+	Tau     float32    `min:"0" desc:"if > 1, time constant in cycles for exponentially smoothing the reference activity, so that the current Act is compared with a running average of the recent activity rather than a single cycle"`
+	NaNZero bool       `desc:"set Sim to 0 when the similarity is undefined (NaN), e.g., the correlation when the activity is constant across units, as when the layer is silent -- otherwise Sim is NaN, as it was originally"`
+
+	Dt float32 `view:"-" json:"-" inactive:"+" desc:"rate = 1 / Tau"`
+}
+
+func (ls *LaySimParams) Update() {
+	ls.Dt = 1
+	if ls.Tau > 1 {
+		ls.Dt = 1 / ls.Tau
+	}
+}
+
+func (ls *LaySimParams) Defaults() {
+	ls.Metric = SimCorrelation
+	ls.Lag = 0
+	ls.Tau = 0
+	ls.NaNZero = false
+	ls.Update()
+}
+
+// laySimState holds the past activity of a layer for CalLaySim
+type laySimState struct {
+	hist  [][]float64 // ring buffer of the last Lag Act vectors
+	pos   int         // index in hist of the oldest
+	nhist int         // number of vectors in hist
+	ref   []float64   // reference activity
+	cur   []float64   // current activity
+	nref  int         // number of cycles in ref since Reset
+}
+
+// Reset clears the past activity, e.g., at the start of a trial
+func (st *laySimState) Reset() {
+	st.pos = 0
+	st.nhist = 0
+	st.nref = 0
+}
+
+// CopyFrom sets this state to a copy of given one
+func (st *laySimState) CopyFrom(ss *laySimState) {
+	st.hist = make([][]float64, len(ss.hist))
+	for i, h := range ss.hist {
+		st.hist[i] = append([]float64(nil), h...)
+	}
+	st.ref = append([]float64(nil), ss.ref...)
+	st.cur = append([]float64(nil), ss.cur...)
+	st.pos = ss.pos
+	st.nhist = ss.nhist
+	st.nref = ss.nref
+}
+
+// CalLaySim calculates the similarity of the current activity (Act) with
+// the past activity, as specified by the LaySim params, into Sim.
+// Uses buffers on the layer to avoid allocating every cycle.
+func (ly *Layer) CalLaySim(ltime *Time) {
+	ls := &ly.LaySim
+	st := &ly.simSt
+	nn := len(ly.Neurons)
+	if len(st.cur) != nn || len(st.hist) != ls.Lag {
+		st.ref = make([]float64, nn)
+		st.cur = make([]float64, nn)
+		st.hist = make([][]float64, ls.Lag)
+		for i := range st.hist {
+			st.hist[i] = make([]float64, nn)
+		}
+		st.Reset()
+	}
+	var lag []float64
+	if ls.Lag > 0 && st.nhist > 0 {
+		lag = st.hist[st.pos] // oldest, until the buffer is full
+	}
+	dt := float64(ls.Dt)
+	if st.nref == 0 {
+		dt = 1
+	}
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		st.cur[ni] = float64(nrn.Act)
+		x := float64(nrn.ActSent)
+		if lag != nil {
+			x = lag[ni]
+		}
+		st.ref[ni] += dt * (x - st.ref[ni])
+	}
+	st.nref++
+	ly.Sim = LaySimFunc.Sim(ls.Metric, st.ref, st.cur)
+	if ls.NaNZero && math.IsNaN(ly.Sim) {
+		ly.Sim = 0
+	}
+	if ls.Lag > 0 {
+		wi := (st.pos + st.nhist) % ls.Lag
+		if st.nhist == ls.Lag {
+			st.pos = (st.pos + 1) % ls.Lag
+		} else {
+			st.nhist++
+		}
+		copy(st.hist[wi], st.cur)
+	}
+}
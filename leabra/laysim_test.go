@@ -0,0 +1,84 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+)
+
+func TestStdSim(t *testing.T) {
+	a := []float64{1, 0, 1, 0}
+	b := []float64{1, 0, 0, 1}
+	if v := (StdSim{}).Sim(SimCorrelation, a, a); math.Abs(v-1) > 1e-9 {
+		t.Errorf("corr(a,a) = %v\n", v)
+	}
+	if v := (StdSim{}).Sim(SimCorrelation, a, b); math.Abs(v) > 1e-9 {
+		t.Errorf("corr(a,b) = %v, want 0\n", v)
+	}
+	if v := (StdSim{}).Sim(SimCosine, a, b); math.Abs(v-0.5) > 1e-9 {
+		t.Errorf("cos(a,b) = %v, want .5\n", v)
+	}
+	if v := (StdSim{}).Sim(SimNormDot, a, b); v != 0.25 {
+		t.Errorf("normdot(a,b) = %v, want .25\n", v)
+	}
+	if v := (StdSim{}).Sim(SimCorrelation, a, []float64{1, 0.5, 0, 1}); math.Abs(v+0.301511344577763) > 1e-9 {
+		t.Errorf("corr(a,c) = %v, want -.3015\n", v)
+	}
+	z := []float64{0, 0, 0, 0}
+	if v := (StdSim{}).Sim(SimCorrelation, a, z); !math.IsNaN(v) {
+		t.Errorf("corr(a,0) = %v, want NaN\n", v)
+	}
+	if v := (StdSim{}).Sim(SimCosine, z, b); !math.IsNaN(v) {
+		t.Errorf("cos(0,b) = %v, want NaN\n", v)
+	}
+}
+
+func TestLaySimNaN(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "SimNet")
+	lay := net.AddLayer2D("Hidden", 1, 4, emer.Hidden)
+	net.Defaults()
+	net.Build()
+	ly := lay.(*Layer)
+	ltime := NewTime()
+	ly.CalLaySim(ltime) // silent layer
+	if !math.IsNaN(ly.Sim) {
+		t.Errorf("Sim of silent layer = %v, want NaN\n", ly.Sim)
+	}
+	ly.LaySim.NaNZero = true
+	ly.CalLaySim(ltime)
+	if ly.Sim != 0 {
+		t.Errorf("Sim of silent layer with NaNZero = %v, want 0\n", ly.Sim)
+	}
+}
+
+func TestLaySimLag(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "SimNet")
+	lay := net.AddLayer2D("Hidden", 1, 4, emer.Hidden)
+	net.Defaults()
+	net.Build()
+	ly := lay.(*Layer)
+	ly.LaySim.Metric = SimCosine
+	ly.LaySim.Lag = 2
+	ly.UpdateParams()
+	pats := [][]float32{{1, 0, 0, 0}, {0, 1, 0, 0}, {1, 0, 0, 0}, {0, 1, 0, 0}}
+	ltime := NewTime()
+	for c, pat := range pats {
+		for ni := range ly.Neurons {
+			ly.Neurons[ni].Act = pat[ni]
+		}
+		ly.CalLaySim(ltime)
+		if c >= 2 && math.Abs(ly.Sim-1) > 1e-9 {
+			t.Errorf("cycle %v: Sim with lag 2 = %v, want 1\n", c, ly.Sim)
+		}
+		if c == 1 && ly.Sim != 0 {
+			t.Errorf("cycle 1: Sim with oldest = %v, want 0\n", ly.Sim)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/ki/kit"
+)
+
+// LesionActs are the different actions that can be scheduled in a LesionSched
+type LesionActs int32
+
+//go:generate stringer -type=LesionActs
+
+var KiT_LesionActs = kit.Enums.AddEnum(LesionActsN, false, nil)
+
+func (ev LesionActs) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *LesionActs) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// The lesion actions
+const (
+	// LesionUnits lesions (sets the Off flag) for Prop proportion of neurons in target layer
+	LesionUnits LesionActs = iota
+
+	// UnLesionUnits clears the Off flag for all neurons in target layer
+	UnLesionUnits
+
+	// TurnOff turns off the target layer or projection entirely
+	TurnOff
+
+	// TurnOn turns the target layer or projection back on
+	TurnOn
+
+	LesionActsN
+)
+
+// LesionEvent is one scheduled lesion event, applied when the given Scope
+// counter (e.g., "Epoch", "SleepTrial") reaches the given Ctr value.
+type LesionEvent struct {
+	Scope  string     `desc:"name of the counter scope at which this event applies -- e.g., Epoch (at the start of given epoch), or SleepTrial (after given sleep trial) -- must match the scope names used by the sim in calling Apply"`
+	Ctr    int        `desc:"counter value at which the event applies"`
+	Act    LesionActs `desc:"what to do"`
+	Target string     `desc:"name of layer or projection to act on -- a leading # is optional, as in params selectors -- projections are named SendToRecv"`
+	Prop   float32    `desc:"for LesionUnits, proportion (0-1) of neurons in layer to lesion"`
+	Done   bool       `inactive:"+" desc:"true if this event has already been applied"`
+}
+
+// String returns the event in the text format parsed by ParseLesionEvent
+func (le *LesionEvent) String() string {
+	str := fmt.Sprintf("%v %d %v #%v", le.Scope, le.Ctr, le.Act, le.Target)
+	if le.Act == LesionUnits {
+		str += fmt.Sprintf(" %v", le.Prop)
+	}
+	return str
+}
+
+// ParseLesionEvent parses a lesion event from a text string of the form:
+// <Scope> <Ctr> <Act> <Target> [<Prop>], e.g.,
+// "Epoch 20 LesionUnits Hidden1 0.3" or "SleepTrial 3 TurnOff #OutputToHidden1".
+// The Act names are not case sensitive, and the shorter forms lesion, unlesion,
+// off, on can also be used.
+func ParseLesionEvent(str string) (*LesionEvent, error) {
+	flds := strings.Fields(str)
+	if len(flds) < 4 {
+		return nil, fmt.Errorf("ParseLesionEvent: event: %q must have at least 4 fields: Scope Ctr Act Target [Prop]", str)
+	}
+	le := &LesionEvent{Scope: flds[0], Target: strings.TrimPrefix(flds[3], "#")}
+	ctr, err := strconv.Atoi(flds[1])
+	if err != nil {
+		return nil, fmt.Errorf("ParseLesionEvent: event: %q counter value error: %v", str, err)
+	}
+	le.Ctr = ctr
+	switch strings.ToLower(flds[2]) {
+	case "lesion", "lesionunits":
+		le.Act = LesionUnits
+	case "unlesion", "unlesionunits":
+		le.Act = UnLesionUnits
+	case "off", "turnoff":
+		le.Act = TurnOff
+	case "on", "turnon":
+		le.Act = TurnOn
+	default:
+		return nil, fmt.Errorf("ParseLesionEvent: event: %q action: %v not recognized", str, flds[2])
+	}
+	if le.Act == LesionUnits {
+		if len(flds) < 5 {
+			return nil, fmt.Errorf("ParseLesionEvent: event: %q LesionUnits requires a proportion", str)
+		}
+		prop, err := strconv.ParseFloat(flds[4], 32)
+		if err != nil || prop < 0 || prop > 1 {
+			return nil, fmt.Errorf("ParseLesionEvent: event: %q proportion must be 0-1: %v", str, flds[4])
+		}
+		le.Prop = float32(prop)
+	}
+	return le, nil
+}
+
+// LesionSched is a schedule of lesion events that are applied automatically by
+// calling Apply at the relevant boundaries (e.g., at the start of each epoch,
+// after each sleep trial), with a log of everything that was done.
+type LesionSched struct {
+	Events []*LesionEvent `desc:"the scheduled events"`
+	Log    *etable.Table  `view:"no-inline" desc:"log of all the lesion events that have been applied, with the indexes of the lesioned units"`
+}
+
+// AddEvent adds a new event to the schedule
+func (ls *LesionSched) AddEvent(scope string, ctr int, act LesionActs, target string, prop float32) *LesionEvent {
+	le := &LesionEvent{Scope: scope, Ctr: ctr, Act: act, Target: strings.TrimPrefix(target, "#"), Prop: prop}
+	ls.Events = append(ls.Events, le)
+	return le
+}
+
+// AddEventsString parses events from a string with events separated by ;
+// or newlines, in the format of ParseLesionEvent, adding them to the schedule.
+func (ls *LesionSched) AddEventsString(str string) error {
+	evs := strings.FieldsFunc(str, func(r rune) bool { return r == ';' || r == '\n' })
+	for _, es := range evs {
+		if strings.TrimSpace(es) == "" {
+			continue
+		}
+		le, err := ParseLesionEvent(es)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		ls.Events = append(ls.Events, le)
+	}
+	return nil
+}
+
+// Reset marks all events as not yet done, and resets the log -- call at start of a new run
+func (ls *LesionSched) Reset() {
+	for _, le := range ls.Events {
+		le.Done = false
+	}
+	if ls.Log != nil {
+		ls.Log.SetNumRows(0)
+	}
+}
+
+// Undo reverses the effects of all events that have been applied, in reverse order:
+// lesioned units are unlesioned, and layers / projections that were turned off
+// are turned back on, and vice-versa.  Call prior to Reset at the start of a new run.
+func (ls *LesionSched) Undo(nt *Network) {
+	for i := len(ls.Events) - 1; i >= 0; i-- {
+		le := ls.Events[i]
+		if !le.Done {
+			continue
+		}
+		ue := *le
+		switch le.Act {
+		case LesionUnits:
+			ue.Act = UnLesionUnits
+		case UnLesionUnits:
+			continue
+		case TurnOff:
+			ue.Act = TurnOn
+		case TurnOn:
+			ue.Act = TurnOff
+		}
+		ue.Apply(nt)
+	}
+}
+
+// ConfigLog configures the Log table
+func (ls *LesionSched) ConfigLog() {
+	ls.Log = &etable.Table{}
+	ls.Log.SetMetaData("name", "LesionLog")
+	ls.Log.SetMetaData("desc", "Record of lesion events applied")
+	ls.Log.SetMetaData("read-only", "true")
+	ls.Log.SetFromSchema(etable.Schema{
+		{"Scope", etensor.STRING, nil, nil},
+		{"Ctr", etensor.INT64, nil, nil},
+		{"Act", etensor.STRING, nil, nil},
+		{"Target", etensor.STRING, nil, nil},
+		{"N", etensor.INT64, nil, nil},
+		{"Units", etensor.STRING, nil, nil},
+	}, 0)
+}
+
+// Apply applies all not-yet-done events for given scope and counter value
+// to the network, logging the results.  Returns number of events applied.
+func (ls *LesionSched) Apply(nt *Network, scope string, ctr int) int {
+	n := 0
+	for _, le := range ls.Events {
+		if le.Done || le.Scope != scope || le.Ctr != ctr {
+			continue
+		}
+		le.Done = true
+		nu, units, err := le.Apply(nt)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		ls.LogEvent(le, nu, units)
+		fmt.Printf("Lesion: %v  N: %d\n", le.String(), nu)
+		n++
+	}
+	return n
+}
+
+// LogEvent records given event in the log
+func (ls *LesionSched) LogEvent(le *LesionEvent, nu int, units string) {
+	if ls.Log == nil {
+		ls.ConfigLog()
+	}
+	dt := ls.Log
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+	dt.SetCellString("Scope", row, le.Scope)
+	dt.SetCellFloat("Ctr", row, float64(le.Ctr))
+	dt.SetCellString("Act", row, le.Act.String())
+	dt.SetCellString("Target", row, le.Target)
+	dt.SetCellFloat("N", row, float64(nu))
+	dt.SetCellString("Units", row, units)
+}
+
+// Apply applies this event to the network, returning the number of units
+// affected (for LesionUnits, UnLesionUnits) and a list of their indexes.
+func (le *LesionEvent) Apply(nt *Network) (int, string, error) {
+	if gl, has := nt.Groups[le.Target]; has && nt.LayerByName(le.Target) == nil {
+		nu := 0
+		var units []string
+		for _, lnm := range gl {
+			lev := *le
+			lev.Target = lnm
+			n, us, err := lev.Apply(nt)
+			if err != nil {
+				return nu, strings.Join(units, " "), err
+			}
+			nu += n
+			if us != "" {
+				units = append(units, lnm+": "+us)
+			}
+		}
+		return nu, strings.Join(units, " "), nil
+	}
+	var tly emer.Layer
+	var tpj emer.Prjn
+	tly = nt.LayerByName(le.Target)
+	if tly == nil {
+		for _, ly := range nt.Layers {
+			for _, p := range *ly.RecvPrjns() {
+				if p.Name() == le.Target {
+					tpj = p
+					break
+				}
+			}
+			if tpj != nil {
+				break
+			}
+		}
+	}
+	if tly == nil && tpj == nil {
+		return 0, "", fmt.Errorf("LesionEvent: %v target not found in network: %v", le.String(), nt.Nm)
+	}
+	switch le.Act {
+	case LesionUnits, UnLesionUnits:
+		if tly == nil {
+			return 0, "", fmt.Errorf("LesionEvent: %v target must be a layer", le.String())
+		}
+		ly := tly.(LeabraLayer).AsLeabra()
+		if le.Act == UnLesionUnits {
+			ly.UnLesionNeurons()
+			return len(ly.Neurons), "", nil
+		}
+		nl := ly.LesionNeurons(le.Prop)
+		idxs := make([]string, 0, nl)
+		for ni := range ly.Neurons {
+			if ly.Neurons[ni].IsOff() {
+				idxs = append(idxs, strconv.Itoa(ni))
+			}
+		}
+		return nl, strings.Join(idxs, " "), nil
+	case TurnOff, TurnOn:
+		off := le.Act == TurnOff
+		if tly != nil {
+			tly.SetOff(off)
+		} else {
+			tpj.SetOff(off)
+		}
+	}
+	return 0, "", nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+)
+
+func TestParseLesionEvent(t *testing.T) {
+	le, err := ParseLesionEvent("Epoch 20 lesion #Hidden 0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if le.Scope != "Epoch" || le.Ctr != 20 || le.Act != LesionUnits || le.Target != "Hidden" || le.Prop != 0.5 {
+		t.Errorf("parsed: %+v\n", le)
+	}
+	re, err := ParseLesionEvent(le.String())
+	if err != nil || *re != *le {
+		t.Errorf("String round trip: %v -> %+v: %v\n", le.String(), re, err)
+	}
+	if le, err := ParseLesionEvent("SleepTrial 3 TurnOff InputToHidden"); err != nil || le.Act != TurnOff || le.Target != "InputToHidden" {
+		t.Errorf("parsed: %+v: %v\n", le, err)
+	}
+	for _, bad := range []string{"Epoch 20 lesion", "Epoch x off Hidden", "Epoch 2 smash Hidden", "Epoch 2 lesion Hidden", "Epoch 2 lesion Hidden 1.5"} {
+		if _, err := ParseLesionEvent(bad); err == nil {
+			t.Errorf("expected error for: %q\n", bad)
+		}
+	}
+}
+
+func TestLesionSched(t *testing.T) {
+	net := ensembleNet()
+	hid := net.LayerByName("Hidden").(*Layer)
+	pj := net.PrjnByName("InputToHidden")
+	ls := &LesionSched{}
+	if err := ls.AddEventsString("Epoch 2 lesion Hidden 0.5; Epoch 3 off #InputToHidden\nEpoch 3 off NoSuch"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ls.AddEventsString("Epoch 2 lesion"); err == nil {
+		t.Errorf("expected error for a bad event\n")
+	}
+	if len(ls.Events) != 3 {
+		t.Fatalf("events: %v, want 3\n", len(ls.Events))
+	}
+	if n := ls.Apply(net, "Epoch", 1); n != 0 {
+		t.Errorf("applied at epoch 1: %v\n", n)
+	}
+	if n := ls.Apply(net, "Epoch", 2); n != 1 {
+		t.Errorf("applied at epoch 2: %v, want 1\n", n)
+	}
+	noff := 0
+	for ni := range hid.Neurons {
+		if hid.Neurons[ni].IsOff() {
+			noff++
+		}
+	}
+	if noff != 2 {
+		t.Errorf("lesioned units: %v, want 2\n", noff)
+	}
+	if n := ls.Apply(net, "Epoch", 2); n != 0 {
+		t.Errorf("re-applied at epoch 2: %v\n", n)
+	}
+	if n := ls.Apply(net, "Epoch", 3); n != 1 { // the event for NoSuch fails
+		t.Errorf("applied at epoch 3: %v, want 1\n", n)
+	}
+	if !pj.IsOff() {
+		t.Errorf("projection not turned off\n")
+	}
+	if ls.Log.Rows != 2 || ls.Log.CellFloat("N", 0) != 2 || ls.Log.CellString("Act", 1) != "TurnOff" {
+		t.Errorf("log: rows %v N %v Act %v\n", ls.Log.Rows, ls.Log.CellFloat("N", 0), ls.Log.CellString("Act", 1))
+	}
+
+	ls.Undo(net)
+	ls.Reset()
+	if pj.IsOff() {
+		t.Errorf("projection not turned back on by Undo\n")
+	}
+	for ni := range hid.Neurons {
+		if hid.Neurons[ni].IsOff() {
+			t.Errorf("unit %d still lesioned after Undo\n", ni)
+		}
+	}
+	if ls.Log.Rows != 0 || ls.Events[0].Done {
+		t.Errorf("not reset: log rows %v done %v\n", ls.Log.Rows, ls.Events[0].Done)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LogFileOpts are the options for the files that logs and streams are
+// written to (see LogFile), e.g., for long sleep simulations whose cycle
+// logs would otherwise grow to many GB
+type LogFileOpts struct {
+	Gzip    bool          `desc:"if true, the files are gzip compressed, with .gz appended to their names"`
+	MaxSize int64         `desc:"if > 0, a new file is started once this many (uncompressed) bytes have been written to the current one"`
+	MaxAge  time.Duration `desc:"if > 0, a new file is started once the current one has been open for this long"`
+}
+
+// Rotate returns true if the files are rotated, by MaxSize or MaxAge
+func (lo *LogFileOpts) Rotate() bool {
+	return lo.MaxSize > 0 || lo.MaxAge > 0
+}
+
+// FileName returns the name of the file with given index for a log file
+// with given base name: if rotating, the index is inserted before the
+// extension, e.g., sim_epc_002.csv, and .gz is appended if Gzip
+func (lo *LogFileOpts) FileName(name string, idx int) string {
+	if lo.Rotate() {
+		ext := filepath.Ext(name)
+		name = fmt.Sprintf("%s_%03d%s", strings.TrimSuffix(name, ext), idx, ext)
+	}
+	if lo.Gzip {
+		name += ".gz"
+	}
+	return name
+}
+
+// LogFile is a file that logs (e.g., the rows of a CSV log) or streams
+// (e.g., the records of an ActStream) are written to, buffered, optionally
+// gzip compressed and optionally rotated, according to its LogFileOpts.
+// Text logs (Lines) are only rotated at the end of a line, and their first
+// line (the headers) is repeated at the start of each new file, so that
+// each file can be read on its own -- otherwise, each call to Write is
+// taken to be a whole record, and files are only rotated between writes.
+type LogFile struct {
+	LogFileOpts
+	Name    string `desc:"base name of the files -- see FileName"`
+	Lines   bool   `desc:"if true, the log is text, rotated only at the end of a line, with the first line repeated at the start of each file"`
+	NFiles  int    `inactive:"+" desc:"number of files opened so far"`
+	f       *os.File
+	gz      *gzip.Writer
+	bw      *bufio.Writer
+	size    int64
+	nwrit   int64
+	start   time.Time
+	header  []byte
+	hdrDone bool
+	atEOL   bool
+}
+
+// CreateLogFile creates the first file of a LogFile with given base name,
+// options, and whether it is a text log with lines
+func CreateLogFile(name string, opts LogFileOpts, lines bool) (*LogFile, error) {
+	lf := &LogFile{LogFileOpts: opts, Name: name, Lines: lines}
+	if err := lf.open(); err != nil {
+		return nil, err
+	}
+	return lf, nil
+}
+
+// Files returns the names of all the files opened so far, in order
+func (lf *LogFile) Files() []string {
+	fns := make([]string, lf.NFiles)
+	for i := range fns {
+		fns[i] = lf.FileName(lf.Name, i)
+	}
+	return fns
+}
+
+// open creates the next file
+func (lf *LogFile) open() error {
+	f, err := os.Create(lf.FileName(lf.Name, lf.NFiles))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	lf.f = f
+	if lf.Gzip {
+		lf.gz = gzip.NewWriter(f)
+		lf.bw = bufio.NewWriter(lf.gz)
+	} else {
+		lf.bw = bufio.NewWriter(f)
+	}
+	lf.NFiles++
+	lf.start = time.Now()
+	lf.size = 0
+	lf.nwrit = 0
+	if lf.Lines && lf.hdrDone {
+		n, err := lf.bw.Write(lf.header)
+		lf.size += int64(n)
+		return err
+	}
+	return nil
+}
+
+// closeFile flushes and closes the current file
+func (lf *LogFile) closeFile() error {
+	if lf.f == nil {
+		return nil
+	}
+	err := lf.bw.Flush()
+	if lf.gz != nil {
+		if gerr := lf.gz.Close(); err == nil {
+			err = gerr
+		}
+	}
+	if cerr := lf.f.Close(); err == nil {
+		err = cerr
+	}
+	lf.f = nil
+	lf.gz = nil
+	lf.bw = nil
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// Due returns true if the current file is due to be rotated, by MaxSize or
+// MaxAge, once something other than the headers has been written to it
+func (lf *LogFile) Due() bool {
+	if lf.nwrit == 0 {
+		return false
+	}
+	return (lf.MaxSize > 0 && lf.size >= lf.MaxSize) || (lf.MaxAge > 0 && time.Since(lf.start) >= lf.MaxAge)
+}
+
+// Rotate closes the current file and starts the next one
+func (lf *LogFile) Rotate() error {
+	if err := lf.closeFile(); err != nil {
+		return err
+	}
+	return lf.open()
+}
+
+// Write writes given bytes to the current file, first rotating it if it is
+// Due and at a boundary (the end of a line for Lines) -- implements io.Writer
+func (lf *LogFile) Write(p []byte) (int, error) {
+	if lf.f == nil {
+		err := fmt.Errorf("LogFile: %v is not open", lf.Name)
+		log.Println(err)
+		return 0, err
+	}
+	if lf.Due() && (!lf.Lines || lf.atEOL) {
+		if err := lf.Rotate(); err != nil {
+			return 0, err
+		}
+	}
+	if lf.Lines && len(p) > 0 {
+		if !lf.hdrDone {
+			if i := bytes.IndexByte(p, '\n'); i >= 0 {
+				lf.header = append(lf.header, p[:i+1]...)
+				lf.hdrDone = true
+			} else {
+				lf.header = append(lf.header, p...)
+			}
+		}
+		lf.atEOL = p[len(p)-1] == '\n'
+	}
+	n, err := lf.bw.Write(p)
+	lf.size += int64(n)
+	lf.nwrit += int64(n)
+	return n, err
+}
+
+// Flush writes any buffered data to the current file -- for gzip, the data
+// written so far can then be decompressed even if the file is never closed
+func (lf *LogFile) Flush() error {
+	if lf.f == nil {
+		return nil
+	}
+	err := lf.bw.Flush()
+	if err == nil && lf.gz != nil {
+		err = lf.gz.Flush()
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// Close flushes and closes the current file -- implements io.Closer
+func (lf *LogFile) Close() error {
+	return lf.closeFile()
+}
+
+// OpenLogFiles returns a reader of the concatenated contents of given files,
+// e.g., the Files of a LogFile, decompressing those whose names end in .gz
+func OpenLogFiles(files []string) (io.ReadCloser, error) {
+	lr := &logFilesReader{}
+	for _, fn := range files {
+		f, err := os.Open(fn)
+		if err != nil {
+			log.Println(err)
+			lr.Close()
+			return nil, err
+		}
+		lr.closers = append(lr.closers, f)
+		var r io.Reader = f
+		if strings.HasSuffix(fn, ".gz") {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				err = fmt.Errorf("OpenLogFiles: %v: %v", fn, err)
+				log.Println(err)
+				lr.Close()
+				return nil, err
+			}
+			lr.closers = append(lr.closers, gz)
+			r = gz
+		}
+		lr.readers = append(lr.readers, r)
+	}
+	lr.Reader = io.MultiReader(lr.readers...)
+	return lr, nil
+}
+
+// logFilesReader reads from multiple files, closing all of them on Close
+type logFilesReader struct {
+	io.Reader
+	readers []io.Reader
+	closers []io.Closer
+}
+
+func (lr *logFilesReader) Close() error {
+	var err error
+	for i := len(lr.closers) - 1; i >= 0; i-- {
+		if cerr := lr.closers[i].Close(); err == nil {
+			err = cerr
+		}
+	}
+	lr.closers = nil
+	return err
+}
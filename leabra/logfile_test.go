@@ -0,0 +1,77 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// text log, rotated every 2 rows of 8 bytes, with headers repeated
+	opts := LogFileOpts{Gzip: true, MaxSize: 20}
+	lf, err := CreateLogFile(filepath.Join(dir, "sim_epc.csv"), opts, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(lf, "Ep")
+	fmt.Fprintf(lf, "och\n")
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(lf, "row %03d\n", i)
+	}
+	if err := lf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fns := lf.Files()
+	if len(fns) != 3 || filepath.Base(fns[1]) != "sim_epc_001.csv.gz" {
+		t.Fatalf("files wrong: %v\n", fns)
+	}
+	rd, err := OpenLogFiles(fns[1:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(rd)
+	rd.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); s != "Epoch\nrow 002\nrow 003\n" {
+		t.Errorf("second file wrong: %q\n", s)
+	}
+
+	// binary records, not split across files, not compressed
+	opts = LogFileOpts{MaxSize: 10}
+	lf, err = CreateLogFile(filepath.Join(dir, "acts.f32"), opts, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		lf.Write([]byte(strings.Repeat(fmt.Sprint(i), 8)))
+	}
+	lf.Close()
+	fns = lf.Files()
+	if len(fns) != 2 {
+		t.Fatalf("files wrong: %v\n", fns)
+	}
+	rd, err = OpenLogFiles(fns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ = ioutil.ReadAll(rd)
+	rd.Close()
+	if s := string(b); s != "00000000111111112222222233333333" {
+		t.Errorf("records wrong: %q\n", s)
+	}
+}
@@ -0,0 +1,409 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/emer/etable/agg"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// LogItem is one column of a log in Logs, declaring where its value comes
+// from: a function (Fun, StrFun, or TensorFun), a value of a layer (Layer and
+// Var), the unit values of a layer as a tensor (Tensor), or an aggregate (Agg)
+// over the rows of another log (AggMode, AggTime), e.g., the mean over trials
+// of a trial-level column for an epoch log.
+type LogItem struct {
+	Name    string                                 `desc:"name of the column"`
+	Mode    string                                 `desc:"mode of the log the item is in, e.g., Train, Test, Sleep"`
+	Time    TimeScales                             `desc:"time scale of the log the item is in, e.g., Cycle, Trial, Epoch, Run"`
+	Type    etensor.Type                           `desc:"type of the column -- FLOAT64 for all but StrFun (STRING)"`
+	Layer   string                                 `desc:"name of the layer for layer values"`
+	Var     string                                 `desc:"for layer values: field of the layer (e.g., Sim) or of its layer-level pool (e.g., ActM.Avg, ActAvg.ActPAvgEff) -- for Tensor: the unit variable (e.g., Act)"`
+	Tensor  bool                                   `desc:"log the unit values of Var for all the units of the Layer, as a tensor of the layer shape"`
+	AggOn   bool                                   `desc:"value is the Agg aggregate of column AggCol over the rows of the log at AggMode, AggTime"`
+	Agg     agg.Aggs                               `viewif:"AggOn" desc:"aggregation function, e.g., AggMean"`
+	AggMode string                                 `viewif:"AggOn" desc:"mode of the log to aggregate over -- defaults to Mode"`
+	AggTime TimeScales                             `viewif:"AggOn" desc:"time scale of the log to aggregate over, e.g., Trial for an Epoch item"`
+	AggCol  string                                 `viewif:"AggOn" desc:"column to aggregate -- defaults to Name"`
+	AggLast int                                    `viewif:"AggOn" desc:"if > 0, only aggregate over the last this many rows, e.g., the last epochs of a run"`
+	Fun     func(lg *Logs, row int) float64        `view:"-" json:"-" desc:"function computing the value"`
+	StrFun  func(lg *Logs, row int) string         `view:"-" json:"-" desc:"function computing a string value"`
+	TenFun  func(lg *Logs, row int) etensor.Tensor `view:"-" json:"-" desc:"function computing a tensor value, of shape Shape"`
+	Shape   []int                                  `desc:"for TenFun: shape of the tensor values"`
+	Dims    []string                               `desc:"for TenFun: names of the dimensions of the tensor values"`
+	Plot    bool                                   `desc:"plot the column by default"`
+	FixMin  bool                                   `desc:"fix the minimum of the plot axis to Min"`
+	Min     float64                                `desc:"minimum of the plot axis if FixMin"`
+	FixMax  bool                                   `desc:"fix the maximum of the plot axis to Max"`
+	Max     float64                                `desc:"maximum of the plot axis if FixMax"`
+}
+
+// SetRange sets a fixed plot range and turns on plotting, returning the item
+// so calls can be chained when adding items
+func (it *LogItem) SetRange(min, max float64) *LogItem {
+	it.Plot = true
+	it.FixMin, it.Min = true, min
+	it.FixMax, it.Max = true, max
+	return it
+}
+
+// LogKey is the mode and time scale of a log, e.g., Train Epoch
+type LogKey struct {
+	Mode string
+	Time TimeScales
+}
+
+// String returns the name of the log, e.g., TrainEpoch
+func (lk LogKey) String() string {
+	return lk.Mode + lk.Time.String()
+}
+
+// Logs is a set of logs, one for each mode (e.g., Train, Test, Sleep) and
+// time scale (e.g., Cycle, Trial, Epoch, Run), built from declared LogItems:
+// Config creates the tables, and Log / LogRow compute the values of the
+// items for a row and write it to the log file if one is open (see SetFile).
+// This replaces the ConfigXLog / LogX code of each sim for the standard
+// cases, and the tables can be used in the same way as hand-made ones, so a
+// sim can migrate its logs one at a time -- logs of sim-level stats use
+// AddFun / AddStr items.
+type Logs struct {
+	Items    []*LogItem               `desc:"all the items, in the order of the columns"`
+	Tables   map[LogKey]*etable.Table `view:"-" desc:"the log tables, made by Config"`
+	Files    map[LogKey]*LogFile      `view:"-" desc:"log files that rows are written to as they are logged, set by SetFile"`
+	FileOpts LogFileOpts              `desc:"compression and rotation of the log files -- set before SetFile"`
+	Prec     int                      `desc:"precision for saving float values in the log tables and files"`
+	Window   int                      `desc:"if > 0, logs written through to a file (see SetFile) only keep this many of their most recent rows in memory, for plotting, to bound memory over long runs -- see TrimRows"`
+	Net      *Network                 `view:"-" desc:"the network that the layer values come from"`
+	headers  map[LogKey]bool
+}
+
+// AddItem adds given item, returning it
+func (lg *Logs) AddItem(it *LogItem) *LogItem {
+	if it.Type == etensor.NULL {
+		it.Type = etensor.FLOAT64
+		if it.StrFun != nil {
+			it.Type = etensor.STRING
+		}
+	}
+	lg.Items = append(lg.Items, it)
+	return it
+}
+
+// AddFun adds an item whose value is computed by given function, e.g., a
+// stat of the sim or a counter such as the Epoch
+func (lg *Logs) AddFun(mode string, tm TimeScales, name string, fun func(lg *Logs, row int) float64) *LogItem {
+	return lg.AddItem(&LogItem{Name: name, Mode: mode, Time: tm, Fun: fun})
+}
+
+// AddStr adds an item whose string value is computed by given function,
+// e.g., the name of the trial
+func (lg *Logs) AddStr(mode string, tm TimeScales, name string, fun func(lg *Logs, row int) string) *LogItem {
+	return lg.AddItem(&LogItem{Name: name, Mode: mode, Time: tm, StrFun: fun})
+}
+
+// AddTenFun adds an item whose tensor value, of given shape and dimension
+// names (can be nil), is computed by given function, e.g., a confusion matrix
+func (lg *Logs) AddTenFun(mode string, tm TimeScales, name string, shp []int, dims []string, fun func(lg *Logs, row int) etensor.Tensor) *LogItem {
+	return lg.AddItem(&LogItem{Name: name, Mode: mode, Time: tm, TenFun: fun, Shape: shp, Dims: dims})
+}
+
+// AddLayer adds an item for a value of given layer: a field of the layer
+// (e.g., Sim) or of its layer-level pool (e.g., ActM.Avg, Ge.Avg,
+// ActAvg.ActPAvgEff) -- see LayerVal
+func (lg *Logs) AddLayer(mode string, tm TimeScales, name, lay, vr string) *LogItem {
+	return lg.AddItem(&LogItem{Name: name, Mode: mode, Time: tm, Layer: lay, Var: vr})
+}
+
+// AddTensor adds an item for the values of given unit variable (e.g., Act)
+// for all the units in given layer, as a tensor of the layer shape
+func (lg *Logs) AddTensor(mode string, tm TimeScales, name, lay, vr string) *LogItem {
+	return lg.AddItem(&LogItem{Name: name, Mode: mode, Time: tm, Layer: lay, Var: vr, Tensor: true})
+}
+
+// AddAgg adds an item that aggregates the column of the same name in the log
+// of the same mode at the finer time scale from, e.g., the mean over the
+// Trial log for an Epoch item -- set AggMode, AggCol, AggLast on the
+// returned item for other sources
+func (lg *Logs) AddAgg(mode string, tm TimeScales, name string, from TimeScales, ag agg.Aggs) *LogItem {
+	return lg.AddItem(&LogItem{Name: name, Mode: mode, Time: tm, AggOn: true, Agg: ag, AggTime: from})
+}
+
+// AddLayers adds a layer value item (see AddLayer) for each of given layers,
+// named <layer> <var>
+func (lg *Logs) AddLayers(mode string, tm TimeScales, lays []string, vr string) []*LogItem {
+	its := make([]*LogItem, len(lays))
+	for i, lay := range lays {
+		its[i] = lg.AddLayer(mode, tm, lay+" "+vr, lay, vr)
+	}
+	return its
+}
+
+// PhaseVars are the unit variables logged for each layer by AddPhases: the
+// minus and plus phase activations and their difference
+var PhaseVars = []string{"ActM", "ActP", "ActDif"}
+
+// AddPhases adds tensor items (see AddTensor) for the minus and plus phase
+// activations of each of given layers, and their difference (PhaseVars),
+// named <layer> <var>, e.g., for phase-difference analyses of all the
+// layers at the Trial time scale
+func (lg *Logs) AddPhases(mode string, tm TimeScales, lays []string) []*LogItem {
+	var its []*LogItem
+	for _, lay := range lays {
+		for _, vr := range PhaseVars {
+			its = append(its, lg.AddTensor(mode, tm, lay+" "+vr, lay, vr))
+		}
+	}
+	return its
+}
+
+// ModeItems returns the items of the log for given mode and time scale
+func (lg *Logs) ModeItems(mode string, tm TimeScales) []*LogItem {
+	var its []*LogItem
+	for _, it := range lg.Items {
+		if it.Mode == mode && it.Time == tm {
+			its = append(its, it)
+		}
+	}
+	return its
+}
+
+// Keys returns the mode and time scale of each log, in the order of the
+// first item of each
+func (lg *Logs) Keys() []LogKey {
+	var keys []LogKey
+	has := map[LogKey]bool{}
+	for _, it := range lg.Items {
+		k := LogKey{it.Mode, it.Time}
+		if !has[k] {
+			has[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Config makes the log tables for all the items, getting the shapes of the
+// tensor items from the layers in given network.  Returns an error listing
+// any items for layers or variables that are not found.
+func (lg *Logs) Config(nt *Network) error {
+	lg.Net = nt
+	if lg.Prec == 0 {
+		lg.Prec = 4
+	}
+	if lg.Tables == nil {
+		lg.Tables = make(map[LogKey]*etable.Table)
+	}
+	lg.headers = make(map[LogKey]bool)
+	var errs []string
+	for _, k := range lg.Keys() {
+		sch := etable.Schema{}
+		for _, it := range lg.ModeItems(k.Mode, k.Time) {
+			shp, dims := it.Shape, it.Dims
+			if it.Layer != "" {
+				ly, err := nt.LayerByNameTry(it.Layer)
+				if err != nil {
+					errs = append(errs, fmt.Sprintf("%v: %v", it.Name, err))
+					continue
+				}
+				lly := ly.(LeabraLayer).AsLeabra()
+				if it.Tensor {
+					if _, err := NeuronVarByName(it.Var); err != nil {
+						errs = append(errs, fmt.Sprintf("%v: %v", it.Name, err))
+						continue
+					}
+					shp = lly.Shp.Shp
+				} else if _, err := LayerVal(lly, it.Var); err != nil {
+					errs = append(errs, fmt.Sprintf("%v: %v", it.Name, err))
+					continue
+				}
+			}
+			sch = append(sch, etable.Column{it.Name, it.Type, shp, dims})
+		}
+		dt, has := lg.Tables[k]
+		if !has {
+			dt = &etable.Table{}
+			lg.Tables[k] = dt
+		}
+		dt.SetMetaData("name", k.String()+"Log")
+		dt.SetMetaData("desc", "Record of "+k.Mode+" data by "+k.Time.String())
+		dt.SetMetaData("read-only", "true")
+		dt.SetMetaData("precision", strconv.Itoa(lg.Prec))
+		dt.SetFromSchema(sch, 0)
+	}
+	if len(errs) > 0 {
+		err := fmt.Errorf("Logs Config: items not found:\n%v", strings.Join(errs, "\n"))
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// Table returns the log table for given mode and time scale, nil if none
+func (lg *Logs) Table(mode string, tm TimeScales) *etable.Table {
+	return lg.Tables[LogKey{mode, tm}]
+}
+
+// Log adds a new row to the log for given mode and time scale, computing
+// the values of all its items, and writes it to the log file if open, in
+// which case only the last Window rows are kept if Window > 0.
+// Returns the row.
+func (lg *Logs) Log(mode string, tm TimeScales) int {
+	dt := lg.Table(mode, tm)
+	if dt == nil {
+		return -1
+	}
+	row := dt.Rows
+	lg.LogRow(mode, tm, row)
+	if lg.Window > 0 && lg.Files[LogKey{mode, tm}] != nil {
+		row -= TrimRows(dt, lg.Window)
+	}
+	return row
+}
+
+// LogRow computes the values of all the items of the log for given mode and
+// time scale for given row, adding rows as needed, e.g., to log cycles into
+// a log that is overwritten each trial, and writes it to the log file if open
+func (lg *Logs) LogRow(mode string, tm TimeScales, row int) {
+	k := LogKey{mode, tm}
+	dt := lg.Tables[k]
+	if dt == nil {
+		return
+	}
+	if dt.Rows <= row {
+		dt.SetNumRows(row + 1)
+	}
+	if lg.Net != nil {
+		lg.Net.SyncFromDevice()
+	}
+	for _, it := range lg.ModeItems(mode, tm) {
+		lg.SetItem(dt, it, row)
+	}
+	if f := lg.Files[k]; f != nil {
+		if !lg.headers[k] {
+			dt.WriteCSVHeaders(f, etable.Tab)
+			lg.headers[k] = true
+		}
+		dt.WriteCSVRow(f, row, etable.Tab, true)
+	}
+}
+
+// SetItem computes the value of given item and sets it in given row of the table
+func (lg *Logs) SetItem(dt *etable.Table, it *LogItem, row int) {
+	switch {
+	case it.StrFun != nil:
+		dt.SetCellString(it.Name, row, it.StrFun(lg, row))
+	case it.Fun != nil:
+		dt.SetCellFloat(it.Name, row, it.Fun(lg, row))
+	case it.TenFun != nil:
+		dt.SetCellTensor(it.Name, row, it.TenFun(lg, row))
+	case it.AggOn:
+		dt.SetCellFloat(it.Name, row, lg.AggVal(it))
+	case it.Layer != "":
+		ly, err := lg.Net.LayerByNameTry(it.Layer)
+		if err != nil {
+			return
+		}
+		lly := ly.(LeabraLayer).AsLeabra()
+		if it.Tensor {
+			dt.SetCellTensor(it.Name, row, lly.UnitValsTensor(it.Var))
+			return
+		}
+		v, _ := LayerVal(lly, it.Var)
+		dt.SetCellFloat(it.Name, row, v)
+	}
+}
+
+// AggVal returns the aggregate value of given item over its source log
+func (lg *Logs) AggVal(it *LogItem) float64 {
+	mode := it.AggMode
+	if mode == "" {
+		mode = it.Mode
+	}
+	col := it.AggCol
+	if col == "" {
+		col = it.Name
+	}
+	src := lg.Table(mode, it.AggTime)
+	if src == nil || src.Rows == 0 || src.ColByName(col) == nil {
+		return 0
+	}
+	ix := etable.NewIdxView(src)
+	if it.AggLast > 0 && ix.Len() > it.AggLast {
+		ix.Idxs = ix.Idxs[ix.Len()-it.AggLast:]
+	}
+	return agg.Agg(ix, col, it.Agg)[0]
+}
+
+// Reset removes all the rows of the log for given mode and time scale
+func (lg *Logs) Reset(mode string, tm TimeScales) {
+	if dt := lg.Table(mode, tm); dt != nil {
+		dt.SetNumRows(0)
+	}
+}
+
+// SetFile creates the file with given name that the rows of the log for
+// given mode and time scale are written to as they are logged, with headers
+// before the first row, compressed and rotated according to FileOpts -- any
+// previous file is closed
+func (lg *Logs) SetFile(mode string, tm TimeScales, filename string) error {
+	k := LogKey{mode, tm}
+	if lg.Files == nil {
+		lg.Files = make(map[LogKey]*LogFile)
+	}
+	if f := lg.Files[k]; f != nil {
+		f.Close()
+	}
+	f, err := CreateLogFile(filename, lg.FileOpts, true)
+	if err != nil {
+		delete(lg.Files, k)
+		return err
+	}
+	lg.Files[k] = f
+	if lg.headers == nil {
+		lg.headers = make(map[LogKey]bool)
+	}
+	lg.headers[k] = false
+	return nil
+}
+
+// CloseFiles closes all the log files
+func (lg *Logs) CloseFiles() {
+	for k, f := range lg.Files {
+		f.Close()
+		delete(lg.Files, k)
+	}
+}
+
+// LayerVal returns the value of given variable for given layer: a field of
+// the layer (e.g., Sim), or else of its layer-level pool (e.g., ActM.Avg,
+// Ge.Avg, ActAvg.ActPAvgEff), as a float64
+func LayerVal(ly *Layer, vr string) (float64, error) {
+	v, err := ParamPathValue(ly, "Layer."+vr)
+	if err != nil && len(ly.Pools) > 0 {
+		v, err = ParamPathValue(&ly.Pools[0], "Pool."+vr)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("LayerVal: variable: %v not found as a field of the layer or its pool", vr)
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("LayerVal: variable: %v is not a number: %v", vr, v.Type())
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/etable/agg"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestLogs(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "LogNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	net.InitWts()
+
+	lg := &Logs{}
+	trl := 0
+	lg.AddFun("Test", Trial, "Trial", func(lg *Logs, row int) float64 { return float64(trl) })
+	lg.AddLayer("Test", Trial, "Hid Sim", "Hidden", "Sim")
+	lg.AddLayer("Test", Trial, "Hid ActAvg", "Hidden", "ActAvg.ActPAvgEff")
+	lg.AddTensor("Test", Trial, "HidAct", "Hidden", "Act")
+	lg.AddAgg("Test", Epoch, "Trial", Trial, agg.AggMean)
+	lg.AddTenFun("Test", Epoch, "Conf", []int{2, 2}, []string{"Row", "Col"}, func(lg *Logs, row int) etensor.Tensor {
+		tsr := etensor.NewFloat64([]int{2, 2}, nil, nil)
+		tsr.Values = []float64{1, 2, 3, float64(row)}
+		return tsr
+	})
+	if err := lg.Config(net); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fnm := filepath.Join(dir, "trl.tsv")
+	if err := lg.SetFile("Test", Trial, fnm); err != nil {
+		t.Fatal(err)
+	}
+	hid := hidLay.(*Layer)
+	hid.Sim = 0.5
+	for trl = 0; trl < 4; trl++ {
+		lg.Log("Test", Trial)
+	}
+	lg.Log("Test", Epoch)
+	lg.CloseFiles()
+
+	dt := lg.Table("Test", Trial)
+	if dt.Rows != 4 || dt.CellFloat("Trial", 3) != 3 || dt.CellFloat("Hid Sim", 0) != 0.5 {
+		t.Errorf("trial log wrong: rows %v\n", dt.Rows)
+	}
+	if v := dt.CellFloat("Hid ActAvg", 0); v != float64(hid.Pools[0].ActAvg.ActPAvgEff) {
+		t.Errorf("pool value wrong: %v\n", v)
+	}
+	if sh := dt.ColByName("HidAct").Shapes(); len(sh) != 3 || sh[1] != 2 {
+		t.Errorf("tensor shape wrong: %v\n", sh)
+	}
+	if v := lg.Table("Test", Epoch).CellFloat("Trial", 0); v != 1.5 {
+		t.Errorf("agg mean: %v, want 1.5\n", v)
+	}
+	conf := lg.Table("Test", Epoch).ColByName("Conf")
+	if conf.DimNames()[2] != "Col" || lg.Table("Test", Epoch).CellTensor("Conf", 0).FloatVal1D(2) != 3 {
+		t.Errorf("tensor function item wrong: %v\n", conf.DimNames())
+	}
+	b, err := ioutil.ReadFile(fnm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lns := strings.Split(strings.TrimSpace(string(b)), "\n"); len(lns) != 5 {
+		t.Errorf("file: got %v lines, want headers + 4 rows\n", len(lns))
+	}
+
+	bad := &Logs{}
+	bad.AddLayer("Test", Trial, "Nope", "Hidden", "NoSuchVar")
+	bad.AddLayer("Test", Trial, "NoLay", "NoSuchLayer", "Sim")
+	if err := bad.Config(net); err == nil {
+		t.Errorf("expected error for unknown layer / var\n")
+	}
+}
+
+func TestAddPhases(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "PhsNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+
+	lg := &Logs{}
+	its := lg.AddPhases("Train", Trial, []string{"Input", "Hidden"})
+	if len(its) != 2*len(PhaseVars) || its[4].Name != "Hidden ActP" {
+		t.Fatalf("phase items wrong: %v\n", len(its))
+	}
+	if err := lg.Config(net); err != nil {
+		t.Fatal(err)
+	}
+	hid := hidLay.(*Layer)
+	for ni := range hid.Neurons {
+		nrn := &hid.Neurons[ni]
+		nrn.ActM = 0.25
+		nrn.ActP = float32(ni) * 0.25
+		nrn.ActDif = nrn.ActP - nrn.ActM
+	}
+	lg.LogRow("Train", Trial, 0)
+	dt := lg.Table("Train", Trial)
+	if v := dt.CellTensor("Hidden ActM", 0).FloatVal1D(3); v != 0.25 {
+		t.Errorf("ActM: %v, want .25\n", v)
+	}
+	if v := dt.CellTensor("Hidden ActDif", 0).FloatVal1D(3); v != 0.5 {
+		t.Errorf("ActDif: %v, want .5\n", v)
+	}
+}
+
+func TestTrimRows(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Name", etensor.STRING, nil, nil},
+		{"Act", etensor.FLOAT32, []int{2}, nil},
+	}, 5)
+	for row := 0; row < 5; row++ {
+		dt.SetCellFloat("Epoch", row, float64(row))
+		dt.SetCellString("Name", row, strconv.Itoa(row))
+		dt.CellTensor("Act", row).SetFloat1D(1, float64(10*row))
+	}
+	if drop := TrimRows(dt, 2); drop != 3 || dt.Rows != 2 {
+		t.Fatalf("TrimRows: dropped %v, rows %v\n", drop, dt.Rows)
+	}
+	act := dt.CellTensor("Act", 1).FloatVal1D(1)
+	if dt.CellFloat("Epoch", 0) != 3 || dt.CellString("Name", 1) != "4" || act != 40 {
+		t.Errorf("TrimRows kept wrong rows: %v %v %v\n", dt.CellFloat("Epoch", 0), dt.CellString("Name", 1), act)
+	}
+	if drop := TrimRows(dt, 10); drop != 0 {
+		t.Errorf("TrimRows with fewer rows dropped %v\n", drop)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/emer/etable/agg"
+	"github.com/goki/gi/gi"
+)
+
+// LogSpec declares a log item (see LogItem) in a config file, so that
+// additional stats can be logged without recompiling: either a value of a
+// layer (Layer and Var), or the aggregate (Agg) of a column of the log of
+// the same mode at a finer time scale (From), e.g., the mean over the cycles
+// of a trial.
+type LogSpec struct {
+	Name   string  `desc:"name of the column -- defaults to Layer Var for layer values, or Agg Col for aggregates"`
+	Mode   string  `desc:"mode of the log, e.g., Train, Test, Sleep"`
+	Time   string  `desc:"time scale of the log, e.g., Cycle, Trial, Epoch"`
+	Layer  string  `desc:"name of the layer, for a layer value"`
+	Var    string  `desc:"for a layer value: field of the layer (e.g., Sim) or of its layer-level pool (e.g., Ge.Avg, Act.Max) -- for Tensor: the unit variable (e.g., Act)"`
+	Tensor bool    `desc:"log the unit values of Var for all the units of the Layer, as a tensor"`
+	Agg    string  `desc:"aggregation function, e.g., Mean, Max, Sum, Std -- if set, the item aggregates column Col over the rows of the log at the From time scale"`
+	From   string  `desc:"time scale of the log aggregated over, e.g., Cycle"`
+	Col    string  `desc:"column to aggregate -- defaults to Name"`
+	Plot   bool    `desc:"plot the column by default"`
+	Min    float64 `desc:"minimum of the plot axis, if less than Max"`
+	Max    float64 `desc:"maximum of the plot axis, if greater than Min"`
+}
+
+// LogSpecs is a set of log item declarations, loaded from a JSON or TOML
+// file with OpenLogSpecs, and added to Logs by Add.  In TOML, each item is
+// an [[Items]] table, e.g.:
+//
+//	[[Items]]
+//	Mode = "Sleep"
+//	Time = "Cycle"
+//	Layer = "Po"
+//	Var = "Ge.Avg"
+//	Name = "BlaPoIn Ge.Avg"
+type LogSpecs struct {
+	Items []LogSpec `desc:"the item declarations"`
+}
+
+// OpenLogSpecs opens LogSpecs from a JSON (.json) or TOML (.toml) file
+func OpenLogSpecs(filename gi.FileName) (*LogSpecs, error) {
+	ls := &LogSpecs{}
+	fnm := string(filename)
+	var err error
+	switch strings.ToLower(filepath.Ext(fnm)) {
+	case ".toml":
+		_, err = toml.DecodeFile(fnm, ls)
+	default:
+		var b []byte
+		b, err = ioutil.ReadFile(fnm)
+		if err == nil {
+			err = json.Unmarshal(b, ls)
+		}
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return ls, nil
+}
+
+// AggByName returns the agg.Aggs with given name, with or without the Agg
+// prefix, e.g., Mean or AggMean
+func AggByName(name string) (agg.Aggs, error) {
+	for ag := agg.Aggs(0); ag < agg.AggsN; ag++ {
+		if nm := ag.String(); nm == name || nm == "Agg"+name {
+			return ag, nil
+		}
+	}
+	return agg.AggsN, fmt.Errorf("aggregation: %v not found", name)
+}
+
+// Item returns the LogItem for the spec
+func (sp *LogSpec) Item() (*LogItem, error) {
+	it := &LogItem{Name: sp.Name, Mode: sp.Mode, Layer: sp.Layer, Var: sp.Var, Tensor: sp.Tensor, Plot: sp.Plot}
+	if err := it.Time.FromString(sp.Time); err != nil {
+		return nil, fmt.Errorf("time scale: %v not valid", sp.Time)
+	}
+	if sp.Mode == "" {
+		return nil, fmt.Errorf("no Mode")
+	}
+	switch {
+	case sp.Agg != "":
+		ag, err := AggByName(sp.Agg)
+		if err != nil {
+			return nil, err
+		}
+		if err := it.AggTime.FromString(sp.From); err != nil {
+			return nil, fmt.Errorf("From time scale: %v not valid", sp.From)
+		}
+		it.AggOn = true
+		it.Agg = ag
+		it.AggCol = sp.Col
+		it.Layer = ""
+		if it.Name == "" {
+			if sp.Col == "" {
+				return nil, fmt.Errorf("no Name or Col for aggregate")
+			}
+			it.Name = sp.Agg + " " + sp.Col
+		}
+	case sp.Layer != "":
+		if sp.Var == "" {
+			return nil, fmt.Errorf("no Var for layer: %v", sp.Layer)
+		}
+		if it.Name == "" {
+			it.Name = sp.Layer + " " + sp.Var
+		}
+	default:
+		return nil, fmt.Errorf("neither Layer nor Agg set")
+	}
+	if sp.Min < sp.Max {
+		it.SetRange(sp.Min, sp.Max)
+		it.Plot = sp.Plot
+	}
+	return it, nil
+}
+
+// Add adds the items to given logs, before Logs.Config -- if the log Tables
+// have already been set, the items must be for one of those logs.  Returns
+// an error listing any invalid specs, which are skipped -- layers and
+// variables are checked by Logs.Config.
+func (ls *LogSpecs) Add(lg *Logs) ([]*LogItem, error) {
+	var its []*LogItem
+	var errs []string
+	for i := range ls.Items {
+		sp := &ls.Items[i]
+		it, err := sp.Item()
+		if err == nil && lg.Tables != nil {
+			if _, has := lg.Tables[LogKey{it.Mode, it.Time}]; !has {
+				err = fmt.Errorf("no log for: %v", LogKey{it.Mode, it.Time})
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("item %d: %v: %v", i, sp.Name, err))
+			continue
+		}
+		its = append(its, lg.AddItem(it))
+	}
+	if len(errs) > 0 {
+		err := fmt.Errorf("LogSpecs Add: invalid items:\n%v", strings.Join(errs, "\n"))
+		log.Println(err)
+		return its, err
+	}
+	return its, nil
+}
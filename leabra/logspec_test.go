@@ -0,0 +1,90 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/agg"
+	"github.com/emer/etable/etable"
+	"github.com/goki/gi/gi"
+)
+
+const testLogSpecs = `
+[[Items]]
+Mode = "Test"
+Time = "Cycle"
+Layer = "Hidden"
+Var = "Sim"
+
+[[Items]]
+Mode = "Test"
+Time = "Trial"
+Agg = "Mean"
+From = "Cycle"
+Col = "Hidden Sim"
+Min = 0.0
+Max = 1.0
+`
+
+func TestLogSpecs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logspec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fnm := filepath.Join(dir, "logs.toml")
+	ioutil.WriteFile(fnm, []byte(testLogSpecs), 0644)
+	ls, err := OpenLogSpecs(gi.FileName(fnm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ls.Items) != 2 {
+		t.Fatalf("items: %v\n", ls.Items)
+	}
+
+	net := &Network{}
+	net.InitName(net, "SpecNet")
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.Defaults()
+	net.Build()
+
+	lg := &Logs{}
+	its, err := ls.Add(lg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if its[0].Name != "Hidden Sim" || its[1].Name != "Mean Hidden Sim" || its[1].Agg != agg.AggMean || !its[1].FixMax {
+		t.Errorf("items wrong: %v %v\n", its[0].Name, its[1].Name)
+	}
+	if err := lg.Config(net); err != nil {
+		t.Fatal(err)
+	}
+	hid := hidLay.(*Layer)
+	for cyc, sim := range []float64{0.2, 0.4, 0.9} {
+		hid.Sim = sim
+		lg.LogRow("Test", Cycle, cyc)
+	}
+	lg.Log("Test", Trial)
+	if v := lg.Table("Test", Trial).CellFloat("Mean Hidden Sim", 0); v != 0.5 {
+		t.Errorf("mean over cycles: %v, want .5\n", v)
+	}
+
+	bad := &LogSpecs{Items: []LogSpec{
+		{Mode: "Test", Time: "Fortnight", Layer: "Hidden", Var: "Sim"},
+		{Mode: "Test", Time: "Trial", Agg: "Mode", From: "Cycle", Col: "x"},
+		{Mode: "Sleep", Time: "Cycle", Layer: "Hidden", Var: "Sim"},
+		{Mode: "Test", Time: "Cycle", Layer: "Hidden", Var: "Act.Avg"},
+	}}
+	lg = &Logs{Tables: map[LogKey]*etable.Table{{"Test", Cycle}: &etable.Table{}}}
+	its, err = bad.Add(lg)
+	if err == nil || len(its) != 1 {
+		t.Errorf("expected 3 invalid items, got %v valid, err: %v\n", len(its), err)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// TrimRows keeps only the last n rows of given table, shifting them to the
+// start of the table in place (so plots etc. of the table remain valid),
+// and returns the number of rows removed.  This bounds the memory of logs
+// whose rows are written through to a file as they are logged, keeping a
+// window of the most recent rows for plotting.
+func TrimRows(dt *etable.Table, n int) int {
+	drop := dt.Rows - n
+	if n < 0 || drop <= 0 {
+		return 0
+	}
+	for _, col := range dt.Cols {
+		csz := col.Len() / dt.Rows
+		st := drop * csz
+		nv := n * csz
+		if col.DataType() == etensor.STRING {
+			for i := 0; i < nv; i++ {
+				col.SetString1D(i, col.StringVal1D(st+i))
+			}
+		} else {
+			for i := 0; i < nv; i++ {
+				col.SetFloat1D(i, col.FloatVal1D(st+i))
+			}
+		}
+	}
+	dt.SetNumRows(n)
+	return drop
+}
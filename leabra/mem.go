@@ -0,0 +1,159 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// NetMem records the number of units and synapses in a network, and the
+// memory used by their state, for planning networks at large scales.
+// Counts and byte totals are 64-bit so they do not overflow for very
+// large networks.
+type NetMem struct {
+	Layers    int   `desc:"number of layers (not Off)"`
+	Prjns     int   `desc:"number of projections (not Off)"`
+	Neurons   int64 `desc:"total number of neurons"`
+	Syns      int64 `desc:"total number of synapses"`
+	NeurBytes int64 `desc:"bytes used by neuron and pool state"`
+	SynBytes  int64 `desc:"bytes used by synapse state"`
+	IdxBytes  int64 `desc:"bytes used by connection indexes"`
+	PrjnBytes int64 `desc:"bytes used by other per-unit projection state (GInc, WbRecv)"`
+}
+
+// Total returns the total number of bytes
+func (nm *NetMem) Total() int64 {
+	return nm.NeurBytes + nm.SynBytes + nm.IdxBytes + nm.PrjnBytes
+}
+
+// Add adds other memory counts to this one
+func (nm *NetMem) Add(om *NetMem) {
+	nm.Layers += om.Layers
+	nm.Prjns += om.Prjns
+	nm.Neurons += om.Neurons
+	nm.Syns += om.Syns
+	nm.NeurBytes += om.NeurBytes
+	nm.SynBytes += om.SynBytes
+	nm.IdxBytes += om.IdxBytes
+	nm.PrjnBytes += om.PrjnBytes
+}
+
+func (nm *NetMem) String() string {
+	return fmt.Sprintf("Layers: %v  Prjns: %v  Neurons: %v  Syns: %v  Mem: %v (Neur: %v  Syn: %v  Idx: %v  Prjn: %v)",
+		nm.Layers, nm.Prjns, nm.Neurons, nm.Syns, MemString(nm.Total()), MemString(nm.NeurBytes),
+		MemString(nm.SynBytes), MemString(nm.IdxBytes), MemString(nm.PrjnBytes))
+}
+
+// MemString returns a human-readable string for given number of bytes
+func MemString(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// SynBytes is the number of bytes of state per synapse
+var SynBytes = int64(unsafe.Sizeof(Synapse{}))
+
+// Mem returns the memory used by this projection -- synapses are counted
+// once, on the sending side which owns them.
+func (pj *Prjn) Mem() NetMem {
+	nm := NetMem{Prjns: 1}
+	nm.Syns = pj.Syns.Len()
+	nm.SynBytes = nm.Syns * SynBytes
+	nidx32 := int64(len(pj.RConN) + len(pj.RConIdx) + len(pj.SConN) + len(pj.SConIdx))
+	nidx64 := int64(len(pj.RConIdxSt) + len(pj.RSynIdx) + len(pj.SConIdxSt))
+	nm.IdxBytes = nidx32*4 + nidx64*8
+	nm.PrjnBytes = int64(len(pj.GInc))*4 + int64(len(pj.WbRecv))*int64(unsafe.Sizeof(WtBalRecvPrjn{}))
+	return nm
+}
+
+// Mem returns the memory used by this layer's neurons and pools, and by its
+// receiving projections.
+func (ly *Layer) Mem() NetMem {
+	nm := NetMem{Layers: 1}
+	nm.Neurons = int64(len(ly.Neurons))
+	nm.NeurBytes = nm.Neurons*int64(unsafe.Sizeof(Neuron{})) + int64(len(ly.Pools))*int64(unsafe.Sizeof(Pool{}))
+	for _, p := range ly.RcvPrjns {
+		if p.IsOff() {
+			continue
+		}
+		pm := p.(LeabraPrjn).AsLeabra().Mem()
+		nm.Add(&pm)
+	}
+	return nm
+}
+
+// Mem returns the memory used by the network, after Build
+func (nt *Network) Mem() NetMem {
+	nm := NetMem{}
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		lm := ly.(LeabraLayer).AsLeabra().Mem()
+		nm.Add(&lm)
+	}
+	return nm
+}
+
+// EstMem returns an estimate of the memory that the network will use once
+// built, given the number of connections per projection, which can be
+// computed without building (e.g., nrecv * nsend * pcon).  Use this to
+// check that a large network will fit before calling Build.
+func (nt *Network) EstMem(nsyns map[string]int64) NetMem {
+	nm := NetMem{}
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		nn := int64(ly.Shape().Len())
+		nm.Layers++
+		nm.Neurons += nn
+		nm.NeurBytes += nn * int64(unsafe.Sizeof(Neuron{}))
+		for _, p := range *ly.RecvPrjns() {
+			if p.IsOff() {
+				continue
+			}
+			ns := nsyns[p.Name()]
+			nsend := int64(p.SendLay().Shape().Len())
+			nm.Prjns++
+			nm.Syns += ns
+			nm.SynBytes += ns * SynBytes
+			nm.IdxBytes += 16*ns + 12*(nn+nsend) // RConIdx, SConIdx (32-bit), RSynIdx (64-bit) + N (32), IdxSt (64)
+			nm.PrjnBytes += nn*4 + nn*int64(unsafe.Sizeof(WtBalRecvPrjn{}))
+		}
+	}
+	return nm
+}
+
+// MemReport returns a report of memory usage by layer and projection
+func (nt *Network) MemReport() string {
+	tm := nt.Mem()
+	str := fmt.Sprintf("Memory: %v\n%v\n", nt.Nm, tm.String())
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		lly := ly.(LeabraLayer).AsLeabra()
+		lm := lly.Mem()
+		str += fmt.Sprintf("\t%v\tNeurons: %v\tSyns: %v\tMem: %v\n", ly.Name(), lm.Neurons, lm.Syns, MemString(lm.Total()))
+		for _, p := range lly.RcvPrjns {
+			if p.IsOff() {
+				continue
+			}
+			pm := p.(LeabraPrjn).AsLeabra().Mem()
+			str += fmt.Sprintf("\t\t%v\tSyns: %v\tMem: %v\n", p.Name(), pm.Syns, MemString(pm.Total()))
+		}
+	}
+	return str
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics holds the current counters (e.g., run, epoch, trial) and key
+// stats (e.g., SSE) of a running sim, and serves them over HTTP at /metrics
+// in the Prometheus text format, so that long headless runs (e.g., on a
+// cluster) can be monitored, with alerts attached for stalled training (the
+// last_update_seconds timestamp stops advancing) or NaNs (the nan count is
+// > 0).  Set is safe to call from the running sim while serving.
+type Metrics struct {
+	Prefix string            `desc:"prefix of the metric names, e.g., leabra_"`
+	Labels map[string]string `desc:"labels added to all the metrics, e.g., params=Base"`
+	mu     sync.Mutex
+	vals   map[string]float64
+	upd    time.Time
+	srv    *http.Server
+}
+
+// Set sets the current value of the metric with given name, which is added
+// to the Prefix, e.g., epoch or train_sse
+func (mt *Metrics) Set(name string, val float64) {
+	mt.mu.Lock()
+	if mt.vals == nil {
+		mt.vals = make(map[string]float64)
+	}
+	mt.vals[name] = val
+	mt.upd = time.Now()
+	mt.mu.Unlock()
+}
+
+// Val returns the current value of the metric with given name
+func (mt *Metrics) Val(name string) (float64, bool) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	v, has := mt.vals[name]
+	return v, has
+}
+
+// WriteText writes all the metrics in the Prometheus text format, sorted by
+// name, followed by last_update_seconds (unix time of the last Set) and nan
+// (the number of metrics that are NaN)
+func (mt *Metrics) WriteText(w io.Writer) error {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	lbls := mt.labelStr()
+	names := make([]string, 0, len(mt.vals))
+	for nm := range mt.vals {
+		names = append(names, nm)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	nnan := 0
+	gauge := func(nm string, v float64) {
+		fmt.Fprintf(&b, "# TYPE %s gauge\n%s%s %s\n", nm, nm, lbls, strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	for _, nm := range names {
+		v := mt.vals[nm]
+		if math.IsNaN(v) {
+			nnan++
+		}
+		gauge(mt.Prefix+nm, v)
+	}
+	upd := 0.0
+	if !mt.upd.IsZero() {
+		upd = float64(mt.upd.UnixNano()) / 1e9
+	}
+	gauge(mt.Prefix+"last_update_seconds", upd)
+	gauge(mt.Prefix+"nan", float64(nnan))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// labelStr returns the Labels formatted as {name="val",...}
+func (mt *Metrics) labelStr() string {
+	if len(mt.Labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(mt.Labels))
+	for nm := range mt.Labels {
+		names = append(names, nm)
+	}
+	sort.Strings(names)
+	strs := make([]string, len(names))
+	for i, nm := range names {
+		strs[i] = fmt.Sprintf("%s=%q", nm, mt.Labels[nm])
+	}
+	return "{" + strings.Join(strs, ",") + "}"
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format
+func (mt *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	mt.WriteText(w)
+}
+
+// Start starts serving the metrics at /metrics on given address, e.g.,
+// :9090, in the background -- returns an error if it cannot listen there
+func (mt *Metrics) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", mt)
+	mt.srv = &http.Server{Handler: mux}
+	go mt.srv.Serve(ln)
+	return nil
+}
+
+// Stop stops serving
+func (mt *Metrics) Stop() {
+	if mt.srv != nil {
+		mt.srv.Close()
+		mt.srv = nil
+	}
+}
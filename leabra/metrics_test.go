@@ -0,0 +1,33 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"io/ioutil"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	mt := &Metrics{Prefix: "leabra_", Labels: map[string]string{"params": "Base"}}
+	mt.Set("epoch", 3)
+	mt.Set("train_sse", math.NaN())
+	srv := httptest.NewServer(mt)
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	txt := string(b)
+	for _, want := range []string{"leabra_epoch{params=\"Base\"} 3\n", "leabra_train_sse{params=\"Base\"} NaN\n", "leabra_nan{params=\"Base\"} 1\n", "# TYPE leabra_last_update_seconds gauge\n"} {
+		if !strings.Contains(txt, want) {
+			t.Errorf("missing: %q in:\n%v\n", want, txt)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// MultiModalEnv wraps an environment whose items each have a bound pattern
+// in each of several input modalities (layers, and columns of the Table),
+// to study how sleep consolidates cross-modal bindings: the first of the
+// Mods is the reference modality, always presented as is, while each of the
+// others presents the pattern bound to the item with probability
+// Contingency on each trial, and otherwise the pattern of that modality of
+// a random other item -- so Contingency 1 is a perfect binding and about 1 /
+// the number of items is no more than chance.  Call SetCur with the name of
+// the item at the start of each trial.
+type MultiModalEnv struct {
+	env.Env
+	Mods        []string        `desc:"the modalities: names of the input layers, and columns of the Table, of the bound patterns of each item -- the first is the reference modality"`
+	Contingency float32         `min:"0" max:"1" desc:"probability that each modality other than the reference presents the pattern bound to the item on a trial -- otherwise the pattern of a random other item"`
+	NOn         int             `desc:"number of active units of the patterns of each modality made by GenPats"`
+	Table       *etable.IdxView `view:"-" desc:"the items: their names in the Name column and their patterns in the Mods columns, from which the patterns of other items are drawn"`
+	Rand        *rand.Rand      `view:"-" desc:"random number stream for the contingency -- global generator if nil"`
+	Rows        map[string]int  `inactive:"+" desc:"for each modality, the row of the Table whose pattern is presented on the current trial -- -1 for the bound pattern"`
+	NBound      int             `inactive:"+" desc:"number of the modalities other than the reference that present the bound pattern on the current trial"`
+}
+
+func (me *MultiModalEnv) Defaults() {
+	me.Contingency = 1
+	me.NOn = 6
+}
+
+// On returns true if there are several modalities
+func (me *MultiModalEnv) On() bool {
+	return len(me.Mods) > 1
+}
+
+// OtherMods returns the Mods other than the reference modality
+func (me *MultiModalEnv) OtherMods() []string {
+	if len(me.Mods) < 2 {
+		return nil
+	}
+	return me.Mods[1:]
+}
+
+// IsMod returns true if the element with given name is one of the Mods
+// other than the reference modality
+func (me *MultiModalEnv) IsMod(element string) bool {
+	for _, m := range me.OtherMods() {
+		if m == element {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the Table has a Name column and a column for each
+// of the Mods
+func (me *MultiModalEnv) Validate() error {
+	if me.Table == nil {
+		err := fmt.Errorf("MultiModalEnv: no Table")
+		log.Println(err)
+		return err
+	}
+	for _, col := range append([]string{"Name"}, me.Mods...) {
+		if me.Table.Table.ColByName(col) == nil {
+			err := fmt.Errorf("MultiModalEnv: modality column: %v not found in the patterns", col)
+			log.Println(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// GenPats sets the patterns of each of the Mods other than the reference
+// modality of each row of given table (which must have their columns) to
+// a new random pattern with NOn active units, using given random number
+// stream -- the patterns of the different modalities are independent, so
+// their binding is arbitrary
+func (me *MultiModalEnv) GenPats(dt *etable.Table, rnd *rand.Rand) error {
+	for _, mod := range me.OtherMods() {
+		col := dt.ColByName(mod)
+		if col == nil {
+			err := fmt.Errorf("MultiModalEnv GenPats: modality column: %v not found", mod)
+			log.Println(err)
+			return err
+		}
+		for row := 0; row < dt.Rows; row++ {
+			tsr := dt.CellTensor(mod, row)
+			n := tsr.Len()
+			non := me.NOn
+			if non > n {
+				non = n
+			}
+			SetPatUnits(tsr, rnd.Perm(n)[:non])
+		}
+	}
+	return nil
+}
+
+// SetCur sets the patterns presented on the trial of the item with given
+// name: for each modality other than the reference, the bound pattern with
+// probability Contingency, else that of a random other item
+func (me *MultiModalEnv) SetCur(name string) {
+	flt := rand.Float32
+	intn := rand.Intn
+	if me.Rand != nil {
+		flt = me.Rand.Float32
+		intn = me.Rand.Intn
+	}
+	if me.Rows == nil {
+		me.Rows = make(map[string]int, len(me.Mods))
+	}
+	me.NBound = 0
+	var others []int
+	nc := me.Table.Table.ColByName("Name")
+	for _, row := range me.Table.Idxs {
+		if nc.StringVal1D(row) != name {
+			others = append(others, row)
+		}
+	}
+	for _, mod := range me.OtherMods() {
+		if len(others) == 0 || flt() < me.Contingency {
+			me.Rows[mod] = -1
+			me.NBound++
+			continue
+		}
+		me.Rows[mod] = others[intn(len(others))]
+	}
+}
+
+// State returns the state of given element of the Env, or for a modality
+// whose bound pattern is not presented on the current trial, the pattern
+// of the other item chosen by SetCur
+func (me *MultiModalEnv) State(element string) etensor.Tensor {
+	if row, has := me.Rows[element]; has && row >= 0 && me.IsMod(element) {
+		return me.Table.Table.CellTensor(element, row)
+	}
+	return me.Env.State(element)
+}
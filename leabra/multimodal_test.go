@@ -0,0 +1,84 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestMultiModalEnv(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, []int{4, 4}, nil},
+		{"Audio", etensor.FLOAT32, []int{4, 4}, nil},
+	}, 8)
+	for row := 0; row < dt.Rows; row++ {
+		dt.SetCellString("Name", row, fmt.Sprintf("p%d", row))
+	}
+	me := &MultiModalEnv{Mods: []string{"Input", "Audio"}}
+	me.Defaults()
+	me.NOn = 3
+	if err := me.GenPats(dt, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for i := 0; i < 16; i++ {
+		n += int(dt.CellTensor("Audio", 5).FloatVal1D(i))
+	}
+	if n != 3 {
+		t.Errorf("Audio pattern has %d active units, want 3\n", n)
+	}
+	ft := &env.FixedTable{}
+	ft.Table = etable.NewIdxView(dt)
+	ft.Sequential = true
+	ft.Init(0)
+	ft.Step()
+	me.Env = ft
+	me.Table = ft.Table
+	me.Rand = rand.New(rand.NewSource(1))
+	if err := me.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	same := func(a, b etensor.Tensor) bool {
+		for i := 0; i < a.Len(); i++ {
+			if a.FloatVal1D(i) != b.FloatVal1D(i) {
+				return false
+			}
+		}
+		return true
+	}
+	me.SetCur("p0")
+	if me.NBound != 1 || !same(me.State("Audio"), dt.CellTensor("Audio", 0)) {
+		t.Errorf("Contingency 1: bound pattern not presented\n")
+	}
+	me.Contingency = 0
+	me.SetCur("p0")
+	if me.NBound != 0 || me.Rows["Audio"] == 0 || !same(me.State("Audio"), dt.CellTensor("Audio", me.Rows["Audio"])) {
+		t.Errorf("Contingency 0: pattern of another item not presented: row %d\n", me.Rows["Audio"])
+	}
+	if !same(me.State("Input"), dt.CellTensor("Input", 0)) {
+		t.Errorf("reference modality changed\n")
+	}
+	me.Contingency = 0.5
+	nb := 0
+	for i := 0; i < 1000; i++ {
+		me.SetCur("p0")
+		nb += me.NBound
+	}
+	if nb < 400 || nb > 600 {
+		t.Errorf("Contingency 0.5: %d of 1000 bound\n", nb)
+	}
+	me.Mods = []string{"Input", "Smell"}
+	if me.Validate() == nil {
+		t.Errorf("expected error for a missing modality column\n")
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/emergent/relpos"
+	"github.com/goki/gi/gi"
+)
+
+// NetConfig is a declarative specification of a network architecture: its layers,
+// their shapes, types and positions, and the projections among them.  It can be
+// loaded from a JSON or TOML file (see OpenNetConfig), so architecture variants can
+// be run without recompiling, and a config can be generated from an existing
+// network (see Network.NetConfig) as a starting point.
+type NetConfig struct {
+	Name   string              `desc:"name of the network"`
+	Layers []LayerConfig       `desc:"layers, in order of creation"`
+	Prjns  []PrjnConfig        `desc:"projections among the layers"`
+	Groups map[string][]string `desc:"named groups of layers, for collective operations and params selectors -- see Network.AddGroup"`
+}
+
+// LayerConfig specifies one layer in a NetConfig
+type LayerConfig struct {
+	Name   string  `desc:"name of the layer -- must be unique"`
+	Shape  []int   `desc:"shape of the layer: 2D (Y, X) or 4D (pools Y, X, units Y, X)"`
+	Type   string  `desc:"layer type: Hidden, Input, Target, or Compare -- defaults to Hidden"`
+	Class  string  `desc:"additional class name(s) for params selectors, space separated"`
+	Rel    string  `desc:"position relative to Other layer: RightOf, LeftOf, Behind, FrontOf, Above, Below -- empty = default (Above previous)"`
+	Other  string  `desc:"name of the other layer for Rel positioning"`
+	XAlign string  `desc:"horizontal alignment relative to Other: Left, Middle, Right"`
+	YAlign string  `desc:"vertical alignment relative to Other: Front, Center, Back"`
+	Space  float32 `desc:"space between this layer and Other -- 0 = default"`
+	Off    bool    `desc:"if true, the layer is inactive"`
+}
+
+// PrjnConfig specifies one projection in a NetConfig
+type PrjnConfig struct {
+	From    string  `desc:"name of the sending layer"`
+	To      string  `desc:"name of the receiving layer"`
+	Pattern string  `desc:"connectivity pattern: Full, OneToOne, PoolOneToOne, or UnifRnd -- defaults to Full"`
+	PCon    float32 `desc:"proportion connectivity for UnifRnd pattern"`
+	SelfCon bool    `desc:"for Full pattern, if sending and receiving layer are the same, include connections to self"`
+	Type    string  `desc:"projection type: Forward, Back, Lateral, or Inhib -- defaults to Forward"`
+	Class   string  `desc:"additional class name(s) for params selectors, space separated"`
+	Off     bool    `desc:"if true, the projection is inactive"`
+}
+
+// OpenNetConfig opens a NetConfig from a JSON (.json) or TOML (.toml) file
+func OpenNetConfig(filename gi.FileName) (*NetConfig, error) {
+	nc := &NetConfig{}
+	fnm := string(filename)
+	var err error
+	switch strings.ToLower(filepath.Ext(fnm)) {
+	case ".toml":
+		_, err = toml.DecodeFile(fnm, nc)
+	default:
+		var b []byte
+		b, err = ioutil.ReadFile(fnm)
+		if err == nil {
+			err = json.Unmarshal(b, nc)
+		}
+	}
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return nc, nil
+}
+
+// SaveJSON saves the config to a JSON file
+func (nc *NetConfig) SaveJSON(filename gi.FileName) error {
+	b, err := json.MarshalIndent(nc, "", "  ")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// SaveTOML saves the config to a TOML file
+func (nc *NetConfig) SaveTOML(filename gi.FileName) error {
+	fp, err := os.Create(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer fp.Close()
+	err = toml.NewEncoder(fp).Encode(nc)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// NewPattern returns the prjn.Pattern for this projection config
+func (pc *PrjnConfig) NewPattern() (prjn.Pattern, error) {
+	switch pc.Pattern {
+	case "", "Full":
+		pat := prjn.NewFull()
+		pat.SelfCon = pc.SelfCon
+		return pat, nil
+	case "OneToOne":
+		return prjn.NewOneToOne(), nil
+	case "PoolOneToOne":
+		return prjn.NewPoolOneToOne(), nil
+	case "UnifRnd":
+		pat := prjn.NewUnifRnd()
+		if pc.PCon > 0 {
+			pat.PCon = pc.PCon
+		}
+		return pat, nil
+	}
+	return nil, fmt.Errorf("NetConfig: prjn from: %v to: %v pattern: %v not supported -- must be one of Full, OneToOne, PoolOneToOne, UnifRnd", pc.From, pc.To, pc.Pattern)
+}
+
+// Config configures the layers and projections in the network according to the
+// config.  The network should be empty (just allocated), and it is up to the
+// caller to then call Defaults, apply params, and Build, as usual.
+func (nc *NetConfig) Config(nt *Network) error {
+	if nc.Name != "" {
+		nt.InitName(nt, nc.Name)
+	} else if nt.Nm == "" {
+		nt.InitName(nt, "Network")
+	} else {
+		nt.InitName(nt, nt.Nm)
+	}
+	for i := range nc.Layers {
+		lc := &nc.Layers[i]
+		if len(lc.Shape) != 2 && len(lc.Shape) != 4 {
+			err := fmt.Errorf("NetConfig: layer: %v shape must be 2D or 4D, is: %v", lc.Name, lc.Shape)
+			log.Println(err)
+			return err
+		}
+		typ := emer.Hidden
+		if lc.Type != "" {
+			if err := typ.FromString(lc.Type); err != nil {
+				err = fmt.Errorf("NetConfig: layer: %v invalid type: %v", lc.Name, lc.Type)
+				log.Println(err)
+				return err
+			}
+		}
+		ly := nt.AddLayer(lc.Name, lc.Shape, typ)
+		if lc.Class != "" {
+			ly.SetClass(lc.Class)
+		}
+		if lc.Off {
+			ly.SetOff(true)
+		}
+		if lc.Rel != "" {
+			rel := relpos.Rel{}
+			rel.Defaults()
+			if err := rel.Rel.FromString(lc.Rel); err != nil {
+				err = fmt.Errorf("NetConfig: layer: %v invalid Rel: %v", lc.Name, lc.Rel)
+				log.Println(err)
+				return err
+			}
+			rel.Other = lc.Other
+			if lc.XAlign != "" {
+				if err := rel.XAlign.FromString(lc.XAlign); err != nil {
+					err = fmt.Errorf("NetConfig: layer: %v invalid XAlign: %v", lc.Name, lc.XAlign)
+					log.Println(err)
+					return err
+				}
+			}
+			if lc.YAlign != "" {
+				if err := rel.YAlign.FromString(lc.YAlign); err != nil {
+					err = fmt.Errorf("NetConfig: layer: %v invalid YAlign: %v", lc.Name, lc.YAlign)
+					log.Println(err)
+					return err
+				}
+			}
+			if lc.Space != 0 {
+				rel.Space = lc.Space
+			}
+			ly.SetRelPos(rel)
+		}
+	}
+	for i := range nc.Prjns {
+		pc := &nc.Prjns[i]
+		send, err := nt.LayerByNameTry(pc.From)
+		if err != nil {
+			return err
+		}
+		recv, err := nt.LayerByNameTry(pc.To)
+		if err != nil {
+			return err
+		}
+		pat, err := pc.NewPattern()
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		typ := emer.Forward
+		if pc.Type != "" {
+			if err := typ.FromString(pc.Type); err != nil {
+				err = fmt.Errorf("NetConfig: prjn from: %v to: %v invalid type: %v", pc.From, pc.To, pc.Type)
+				log.Println(err)
+				return err
+			}
+		}
+		pj := nt.ConnectLayers(send, recv, pat, typ)
+		if pc.Class != "" {
+			pj.SetClass(pc.Class)
+		}
+		if pc.Off {
+			pj.SetOff(true)
+		}
+	}
+	for gnm, gl := range nc.Groups {
+		if err := nt.AddGroup(gnm, gl...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NetConfig returns a NetConfig describing the current layers and projections
+// in the network, e.g., to save as a starting point for architecture variants.
+// The layers are in the order of the network, and the projections are those
+// received by each layer in turn, in the order of its RecvPrjns.
+func (nt *Network) NetConfig() *NetConfig {
+	nc := &NetConfig{Name: nt.Nm, Groups: nt.Groups}
+	for _, ly := range nt.Layers {
+		lc := LayerConfig{Name: ly.Name(), Shape: ly.Shape().Shapes(), Type: ly.Type().String(), Off: ly.IsOff()}
+		lc.Class = ly.(LeabraLayer).AsLeabra().Cls
+		rel := ly.RelPos()
+		if rel.Rel != relpos.NoRel {
+			lc.Rel = rel.Rel.String()
+			lc.Other = rel.Other
+			lc.XAlign = rel.XAlign.String()
+			lc.YAlign = rel.YAlign.String()
+			lc.Space = rel.Space
+		}
+		nc.Layers = append(nc.Layers, lc)
+	}
+	for _, ly := range nt.Layers {
+		for _, p := range *ly.RecvPrjns() {
+			pj := p.(LeabraPrjn).AsLeabra()
+			pc := PrjnConfig{From: pj.Send.Name(), To: ly.Name(), Pattern: pj.Pat.Name(), Type: pj.Typ.String(), Class: pj.Cls, Off: pj.Off}
+			switch pat := pj.Pat.(type) {
+			case *prjn.Full:
+				pc.SelfCon = pat.SelfCon
+			case *prjn.UnifRnd:
+				pc.PCon = pat.PCon
+			}
+			nc.Prjns = append(nc.Prjns, pc)
+		}
+	}
+	return nc
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/emergent/relpos"
+	"github.com/goki/gi/gi"
+)
+
+const netConfigTOML = `
+Name = "CfgNet"
+
+[[Layers]]
+Name = "Input"
+Shape = [2, 3]
+Type = "Input"
+
+[[Layers]]
+Name = "Hidden"
+Shape = [2, 2, 3, 3]
+Class = "Cortex"
+Rel = "Above"
+Other = "Input"
+XAlign = "Left"
+
+[[Prjns]]
+From = "Input"
+To = "Hidden"
+Pattern = "UnifRnd"
+PCon = 0.5
+
+[[Prjns]]
+From = "Hidden"
+To = "Input"
+Type = "Back"
+Off = true
+
+[Groups]
+Cortex2 = ["Hidden"]
+`
+
+func TestNetConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fnm := filepath.Join(dir, "net.toml")
+	if err := ioutil.WriteFile(fnm, []byte(netConfigTOML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	nc, err := OpenNetConfig(gi.FileName(fnm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	net := &Network{}
+	if err := nc.Config(net); err != nil {
+		t.Fatal(err)
+	}
+	net.Defaults()
+	if err := net.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if net.Nm != "CfgNet" || len(net.Layers) != 2 {
+		t.Fatalf("network: %v layers: %v\n", net.Nm, len(net.Layers))
+	}
+	hid := net.LayerByName("Hidden").(*Layer)
+	if hid.Type() != emer.Hidden || hid.Shape().NumDims() != 4 || !hasClass(hid.Cls, "Cortex") || !hasClass(hid.Cls, "Cortex2") {
+		t.Errorf("Hidden: type %v dims %v class %v\n", hid.Type(), hid.Shape().NumDims(), hid.Cls)
+	}
+	if rel := hid.RelPos(); rel.Rel != relpos.Above || rel.Other != "Input" || rel.XAlign != relpos.Left {
+		t.Errorf("Hidden rel pos: %+v\n", rel)
+	}
+	fwd := net.PrjnByName("InputToHidden")
+	if pat, ok := fwd.Pat.(*prjn.UnifRnd); !ok || pat.PCon != 0.5 {
+		t.Errorf("InputToHidden pattern: %v\n", fwd.Pat.Name())
+	}
+	back := net.PrjnByName("HiddenToInput")
+	if back.Typ != emer.Back || !back.IsOff() {
+		t.Errorf("HiddenToInput: type %v off %v\n", back.Typ, back.IsOff())
+	}
+
+	// round trip through the config of the network
+	jfnm := filepath.Join(dir, "net.json")
+	if err := net.NetConfig().SaveJSON(gi.FileName(jfnm)); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := OpenNetConfig(gi.FileName(jfnm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rc.Layers) != 2 || len(rc.Prjns) != 2 || rc.Layers[1].Rel != "Above" {
+		t.Fatalf("round trip config: %+v\n", rc)
+	}
+	// projections are by receiving layer: Input receives the Back one first
+	if pc := rc.Prjns[0]; pc.From != "Hidden" || pc.To != "Input" || !pc.Off {
+		t.Errorf("round trip HiddenToInput: %+v\n", pc)
+	}
+	if pc := rc.Prjns[1]; pc.From != "Input" || pc.To != "Hidden" || pc.PCon != 0.5 {
+		t.Errorf("round trip InputToHidden: %+v\n", pc)
+	}
+
+	bads := []*NetConfig{
+		{Layers: []LayerConfig{{Name: "A", Shape: []int{3}}}},
+		{Layers: []LayerConfig{{Name: "A", Shape: []int{2, 2}, Type: "Cortex"}}},
+		{Layers: []LayerConfig{{Name: "A", Shape: []int{2, 2}}}, Prjns: []PrjnConfig{{From: "A", To: "B"}}},
+		{Layers: []LayerConfig{{Name: "A", Shape: []int{2, 2}}}, Prjns: []PrjnConfig{{From: "A", To: "A", Pattern: "Tiled"}}},
+	}
+	for i, bc := range bads {
+		if err := bc.Config(&Network{}); err == nil {
+			t.Errorf("expected error for bad config %d\n", i)
+		}
+	}
+}
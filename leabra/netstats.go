@@ -0,0 +1,190 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// NetStats contains network-level energy and weight-change metrics, which are
+// accumulated automatically over learning trials (in Network.DWt) when On,
+// and summarized at the end of each epoch by calling Network.EpochStats,
+// after which the values can be logged directly with SetLogRow, or with the
+// items of AddLogItems.
+type NetStats struct {
+	On        bool               `desc:"if true, accumulate stats automatically on each learning trial (in Network.DWt) -- costs an extra pass through all synapses"`
+	TotWt     float64            `inactive:"+" desc:"total synaptic weight (sum of Wt) across all synapses, as of last EpochStats"`
+	AbsDWt    float64            `inactive:"+" desc:"total absolute weight change (sum of |DWt|) across all synapses and learning trials in last epoch"`
+	Energy    float64            `inactive:"+" desc:"activity energy: sum of Act across all neurons at the end of each learning trial, averaged over trials in last epoch"`
+	LaySparse map[string]float64 `inactive:"+" desc:"per-layer Treves-Rolls population sparseness of Act at the end of each learning trial, averaged over trials in last epoch -- 1 = all units equally active, 1/N = only one unit active"`
+	NTrials   int                `inactive:"+" desc:"number of learning trials in last epoch"`
+
+	SumAbsDWt float64            `view:"-" desc:"accumulator for AbsDWt"`
+	SumEnergy float64            `view:"-" desc:"accumulator for Energy"`
+	SumSparse map[string]float64 `view:"-" desc:"accumulator for LaySparse"`
+	Cnt       int                `view:"-" desc:"accumulator for NTrials"`
+}
+
+// Init resets all the stats and accumulators
+func (ns *NetStats) Init() {
+	ns.TotWt = 0
+	ns.AbsDWt = 0
+	ns.Energy = 0
+	ns.LaySparse = make(map[string]float64)
+	ns.NTrials = 0
+	ns.InitAccum()
+}
+
+// InitAccum resets the accumulators
+func (ns *NetStats) InitAccum() {
+	ns.SumAbsDWt = 0
+	ns.SumEnergy = 0
+	ns.SumSparse = make(map[string]float64)
+	ns.Cnt = 0
+}
+
+// CopyFrom sets these stats and accumulators to a copy of given ones
+func (ns *NetStats) CopyFrom(fs *NetStats) {
+	*ns = *fs
+	ns.LaySparse = copyStatMap(fs.LaySparse)
+	ns.SumSparse = copyStatMap(fs.SumSparse)
+}
+
+// copyStatMap returns a copy of given map of per-layer stats (nil if nil)
+func copyStatMap(sm map[string]float64) map[string]float64 {
+	if sm == nil {
+		return nil
+	}
+	cm := make(map[string]float64, len(sm))
+	for k, v := range sm {
+		cm[k] = v
+	}
+	return cm
+}
+
+// Accum accumulates the stats for the current trial -- called in Network.DWt
+// after the DWt values have been computed.
+func (ns *NetStats) Accum(nt *Network) {
+	if ns.SumSparse == nil {
+		ns.InitAccum()
+	}
+	for _, l := range nt.Layers {
+		if l.IsOff() {
+			continue
+		}
+		ly := l.(LeabraLayer).AsLeabra()
+		sum, ssq := 0.0, 0.0
+		for ni := range ly.Neurons {
+			nrn := &ly.Neurons[ni]
+			if nrn.IsOff() {
+				continue
+			}
+			act := float64(nrn.Act)
+			sum += act
+			ssq += act * act
+		}
+		ns.SumEnergy += sum
+		n := float64(len(ly.Neurons))
+		if ssq > 0 {
+			ns.SumSparse[ly.Nm] += (sum * sum / (n * n)) / (ssq / n)
+		}
+		for _, p := range ly.SndPrjns {
+			if p.IsOff() {
+				continue
+			}
+			pj := p.(LeabraPrjn).AsLeabra()
+			for _, syns := range pj.Syns.Chunks {
+				for si := range syns {
+					ns.SumAbsDWt += math.Abs(float64(syns[si].DWt))
+				}
+			}
+		}
+	}
+	ns.Cnt++
+}
+
+// TotalWt returns the total synaptic weight (sum of Wt) across all synapses in the network
+func (nt *Network) TotalWt() float64 {
+	tot := 0.0
+	for _, l := range nt.Layers {
+		if l.IsOff() {
+			continue
+		}
+		for _, p := range *l.SendPrjns() {
+			if p.IsOff() {
+				continue
+			}
+			pj := p.(LeabraPrjn).AsLeabra()
+			for _, syns := range pj.Syns.Chunks {
+				for si := range syns {
+					tot += float64(syns[si].Wt)
+				}
+			}
+		}
+	}
+	return tot
+}
+
+// EpochStats computes the summary NetStats for the epoch from the values
+// accumulated over learning trials, and resets the accumulators -- call at the
+// end of each epoch, prior to logging.
+func (nt *Network) EpochStats() {
+	ns := &nt.Stats
+	ns.TotWt = nt.TotalWt()
+	ns.AbsDWt = ns.SumAbsDWt
+	ns.NTrials = ns.Cnt
+	ns.LaySparse = make(map[string]float64)
+	if ns.Cnt > 0 {
+		fc := float64(ns.Cnt)
+		ns.Energy = ns.SumEnergy / fc
+		for lnm, sp := range ns.SumSparse {
+			ns.LaySparse[lnm] = sp / fc
+		}
+	} else {
+		ns.Energy = 0
+	}
+	ns.InitAccum()
+}
+
+// LogSchema returns the etable.Schema columns for logging the NetStats for given
+// network: TotWt, AbsDWt, Energy, and <Layer> Sparse for each layer.
+func (ns *NetStats) LogSchema(nt *Network) etable.Schema {
+	sch := etable.Schema{
+		{"TotWt", etensor.FLOAT64, nil, nil},
+		{"AbsDWt", etensor.FLOAT64, nil, nil},
+		{"Energy", etensor.FLOAT64, nil, nil},
+	}
+	for _, ly := range nt.Layers {
+		sch = append(sch, etable.Column{ly.Name() + " Sparse", etensor.FLOAT64, nil, nil})
+	}
+	return sch
+}
+
+// AddLogItems adds items for the NetStats of given network to given log, for
+// given log mode and time scale, e.g., Train, Epoch -- the same columns as
+// LogSchema, with the values as of the last EpochStats.
+func (ns *NetStats) AddLogItems(lg *Logs, mode string, tm TimeScales, nt *Network) {
+	lg.AddFun(mode, tm, "TotWt", func(lg *Logs, row int) float64 { return ns.TotWt })
+	lg.AddFun(mode, tm, "AbsDWt", func(lg *Logs, row int) float64 { return ns.AbsDWt })
+	lg.AddFun(mode, tm, "Energy", func(lg *Logs, row int) float64 { return ns.Energy })
+	for _, ly := range nt.Layers {
+		nm := ly.Name()
+		lg.AddFun(mode, tm, nm+" Sparse", func(lg *Logs, row int) float64 { return ns.LaySparse[nm] })
+	}
+}
+
+// SetLogRow sets the NetStats values in given row of table, which must have the
+// columns from LogSchema.
+func (ns *NetStats) SetLogRow(dt *etable.Table, row int, nt *Network) {
+	dt.SetCellFloat("TotWt", row, ns.TotWt)
+	dt.SetCellFloat("AbsDWt", row, ns.AbsDWt)
+	dt.SetCellFloat("Energy", row, ns.Energy)
+	for _, ly := range nt.Layers {
+		dt.SetCellFloat(ly.Name()+" Sparse", row, ns.LaySparse[ly.Name()])
+	}
+}
@@ -14,8 +14,18 @@ import (
 // leabra.Network has parameters for running a basic rate-coded Leabra network
 type Network struct {
 	NetworkStru
-	WtBalInterval int `def:"10" desc:"how frequently to update the weight balance average weight factor -- relatively expensive"`
-	WtBalCtr      int `inactive:"+" desc:"counter for how long it has been since last WtBal"`
+	WtBalInterval int                 `def:"10" desc:"how frequently to update the weight balance average weight factor -- relatively expensive"`
+	WtBalCtr      int                 `inactive:"+" desc:"counter for how long it has been since last WtBal"`
+	Stats         NetStats            `desc:"network-level energy and weight-change metrics, accumulated over learning trials when Stats.On, and computed per epoch in EpochStats"`
+	Groups        map[string][]string `desc:"named groups of layers (e.g., Cortex, BLA), for collective operations -- see AddGroup"`
+	LayRnd        bool                `inactive:"+" desc:"if true, each layer has its own random number stream for noise, seeded from LayRndSeed -- see SeedLayerRnd"`
+	LayRndSeed    int64               `inactive:"+" desc:"seed used for the per-layer random number streams"`
+	PrjnRnd       bool                `inactive:"+" desc:"if true, each projection has its own random number stream for initial weights, seeded from PrjnRndSeed -- see SeedPrjnRnd"`
+	PrjnRndSeed   int64               `inactive:"+" desc:"seed used for the per-projection random number streams"`
+	Compute       ComputeBackend      `view:"-" desc:"compute backend for the cycle-level updates (Cycle) -- nil = standard CPU code -- see SetCompute"`
+	ModeParams    ParamPairs          `desc:"params with paired wake and sleep values, e.g., Layer.Act.OptThresh.Send: wake 0.1, sleep 0 -- see ApplyModeParams"`
+	QtrParams     ParamQtrs           `desc:"params with a value for each quarter of the alpha cycle, e.g., Layer.Act.Clamp.Gain: 0.2, 0.2, 0.2, 1 -- applied automatically at the start of each quarter, see ApplyQtrParams"`
+	SynView       SynView             `desc:"unit that the r. and s. synapse variables (e.g., r.Cai, s.Effwt) of the layers are viewed relative to in the NetView -- see SetSynView"`
 }
 
 var KiT_Network = kit.Types.AddType(&Network{}, NetworkProps)
@@ -34,6 +44,7 @@ func (nt *Network) NewPrjn() emer.Prjn {
 func (nt *Network) Defaults() {
 	nt.WtBalInterval = 10
 	nt.WtBalCtr = 0
+	nt.Stats.Init()
 	for li, ly := range nt.Layers {
 		ly.Defaults()
 		ly.SetIndex(li)
@@ -55,6 +66,7 @@ func (nt *Network) UpdateParams() {
 // including running-average state values (e.g., layer running average activations etc)
 func (nt *Network) InitWts() {
 	nt.WtBalCtr = 0
+	nt.Stats.Init()
 	for _, ly := range nt.Layers {
 		if ly.IsOff() {
 			continue
@@ -68,6 +80,7 @@ func (nt *Network) InitWts() {
 		}
 		ly.(LeabraLayer).InitWtSym()
 	}
+	nt.SyncToDevice()
 }
 
 // InitEffWt
@@ -79,6 +92,7 @@ func (nt *Network) InitSdEffWt() {
 		}
 		ly.(LeabraLayer).InitSdEffWt()
 	}
+	nt.SyncToDevice()
 }
 
 // InitActs fully initializes activation state -- not automatically called
@@ -89,6 +103,7 @@ func (nt *Network) InitActs() {
 		}
 		ly.(LeabraLayer).InitActs()
 	}
+	nt.SyncToDevice()
 }
 
 // InitExt initializes external input state -- call prior to applying external inputs to layers
@@ -113,17 +128,22 @@ func (nt *Network) InitGInc() {
 		}
 		ly.(LeabraLayer).InitGInc()
 	}
+	nt.SyncToDevice()
 }
 
 // AlphaCycInit handles all initialization at start of new input pattern, including computing
 // input scaling from running average activation etc.
+// The state, including the external inputs applied before this, is then
+// copied to the compute device, if any.
 func (nt *Network) AlphaCycInit() {
+	nt.ApplyQtrParams(0)
 	for _, ly := range nt.Layers {
 		if ly.IsOff() {
 			continue
 		}
 		ly.(LeabraLayer).AlphaCycInit()
 	}
+	nt.SyncToDevice()
 }
 
 // GScaleFmAvgAct computes the scaling factor for synaptic input conductances G,
@@ -153,7 +173,21 @@ func (nt *Network) GScaleFmAvgAct() {
 // * Average and Max Act stats
 // This basic version doesn't use the time info, but more specialized types do, and we
 // want to keep a consistent API for end-user code.
+// The updating is done by the compute backend, if set (see SetCompute),
+// and otherwise by CycleCPU.
 func (nt *Network) Cycle(ltime *Time, sleep bool) {
+	if nt.Compute != nil {
+		nt.Compute.Cycle(nt, ltime, sleep)
+	} else {
+		nt.CycleCPU(ltime, sleep)
+	}
+	if nt.ThrAuto {
+		nt.ThrAutoTune()
+	}
+}
+
+// CycleCPU runs one cycle of activation updating on the CPU -- see Cycle
+func (nt *Network) CycleCPU(ltime *Time, sleep bool) {
 	nt.SendGDelta(ltime, sleep) // also does integ
 	nt.AvgMaxGe(ltime)
 	nt.InhibFmGeAct(ltime)
@@ -171,22 +205,25 @@ func (nt *Network) Cycle(ltime *Time, sleep bool) {
 // Sleep function set the parameters to be sleep related
 func (nt *Network) Sleep(ltime *Time) {
 	nt.ThrLayFun(func(ly LeabraLayer) { ly.Sleep(ltime) }, "Sleep")
-	nt.InitSdEffWt()
+	nt.InitSdEffWt() // syncs to device
 }
 
 // Wake function set the parameters to be sleep related
 func (nt *Network) Wake(ltime *Time) {
 	nt.ThrLayFun(func(ly LeabraLayer) { ly.Wake(ltime) }, "Wake")
+	nt.SyncToDevice()
 }
 
 // InhibOscil set the layer inhibition to oscillate according to the preset parameters.
 func (nt *Network) InhibOscil(ltime *Time, step int) {
 	nt.ThrLayFun(func(ly LeabraLayer) { ly.InhibOscil(ltime, step) }, "InhibOscil")
+	nt.SyncToDevice()
 }
 
 // InhibOscilMute set the layer inhibition back to base
 func (nt *Network) InhibOscilMute(ltime *Time) {
 	nt.ThrLayFun(func(ly LeabraLayer) { ly.InhibOscilMute(ltime) }, "InhibOscilMute")
+	nt.SyncToDevice()
 }
 
 // SendGeDelta sends change in activation since last sent, if above thresholds
@@ -238,7 +275,12 @@ func (nt *Network) AvgMaxAct(ltime *Time) {
 
 // QuarterFinal does updating after end of a quarter
 func (nt *Network) QuarterFinal(ltime *Time) {
+	nt.SyncFromDevice()
 	nt.ThrLayFun(func(ly LeabraLayer) { ly.QuarterFinal(ltime) }, "QuarterFinal")
+	if ltime.Quarter < 3 {
+		nt.ApplyQtrParams(ltime.Quarter + 1)
+	}
+	nt.SyncToDevice()
 }
 
 //////////////////////////////////////////////////////////////////////////////////////
@@ -246,7 +288,11 @@ func (nt *Network) QuarterFinal(ltime *Time) {
 
 // DWt computes the weight change (learning) based on current running-average activation values
 func (nt *Network) DWt() {
+	nt.SyncFromDevice()
 	nt.ThrLayFun(func(ly LeabraLayer) { ly.DWt() }, "DWt     ")
+	if nt.Stats.On {
+		nt.Stats.Accum(nt)
+	}
 }
 
 // WtFmDWt updates the weights from delta-weight changes.
@@ -258,6 +304,7 @@ func (nt *Network) WtFmDWt() {
 		nt.WtBalCtr = 0
 		nt.WtBalFmWt()
 	}
+	nt.SyncToDevice()
 }
 
 // WtBalFmWt updates the weight balance factors based on average recv weights
@@ -270,25 +317,35 @@ func (nt *Network) WtBalFmWt() {
 
 var NetworkProps = ki.Props{
 	"ToolBar": ki.PropSlice{
-		{"SaveWtsJSON", ki.Props{
+		{"SaveWts", ki.Props{
 			"label": "Save Wts...",
 			"icon":  "file-save",
-			"desc":  "Save json-formatted weights",
+			"desc":  "Save weights, in json (.wts) or compact binary (.wtsb) format according to extension",
 			"Args": ki.PropSlice{
 				{"Weights File Name", ki.Props{
 					"default-field": "WtsFile",
-					"ext":           ".wts",
+					"ext":           ".wts,.wtsb",
 				}},
 			},
 		}},
-		{"OpenWtsJSON", ki.Props{
+		{"OpenWts", ki.Props{
 			"label": "Open Wts...",
 			"icon":  "file-open",
-			"desc":  "Open json-formatted weights",
+			"desc":  "Open weights, in json (.wts) or compact binary (.wtsb) format according to extension",
 			"Args": ki.PropSlice{
 				{"Weights File Name", ki.Props{
 					"default-field": "WtsFile",
-					"ext":           ".wts",
+					"ext":           ".wts,.wtsb",
+				}},
+			},
+		}},
+		{"SaveTopology", ki.Props{
+			"label": "Save Topology...",
+			"icon":  "file-save",
+			"desc":  "Save the layer / projection graph in DOT (.dot, .gv) or GraphML (.graphml) format",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".dot,.gv,.graphml",
 				}},
 			},
 		}},
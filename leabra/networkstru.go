@@ -43,6 +43,16 @@ type NetworkStru struct {
 	ThrTimes []timer.Time           `view:"-" desc:"timers for each thread, so you can see how evenly the workload is being distributed"`
 	FunTimes map[string]*timer.Time `view:"-" desc:"timers for each major function (step of processing)"`
 	WaitGp   sync.WaitGroup         `view:"-" desc:"network-level wait group for synchronizing threaded layer calls"`
+
+	ThrAuto       bool                 `desc:"if true, the allocation of layers to threads, and splitting of large layers' neuron loops, is automatically re-tuned every ThrTuneCycles cycles based on measured per-layer cost -- see AutoThreads"`
+	ThrAutoN      int                  `desc:"number of threads to use for automatic threading -- 0 = runtime.NumCPU()"`
+	ThrTuneCycles int                  `def:"200" desc:"number of cycles between automatic re-tuning of threads when ThrAuto is on -- 0 = 200"`
+	LayTiming     bool                 `view:"-" desc:"if true, time spent in each layer is recorded in LayTimes -- automatically on when ThrAuto is on"`
+	LayTimes      []timer.Time         `view:"-" desc:"timers for each layer, for measuring per-layer cost when LayTiming is on"`
+	Profile       bool                 `desc:"if true, the time spent in each layer for each function is recorded in ProfTimes -- see ProfileLog and ProfileReport"`
+	ProfTimes     map[string][]float64 `view:"-" desc:"profile times in seconds for each function, for each layer, accumulated when Profile is on"`
+	ParamsStrict  bool                 `desc:"if true, ApplyParams returns an error without applying anything if any selector or param path in the sheet would not apply to anything in the network (e.g., from a typo), with suggested near matches -- see ValidateParams"`
+	thrTuneCtr    int
 }
 
 // InitName MUST be called to initialize the network's pointer to itself as an emer.Network
@@ -104,6 +114,7 @@ func (nt *NetworkStru) BuildThreads() {
 	nt.ThrChans = make([]LayFunChan, nt.NThreads)
 	nt.ThrTimes = make([]timer.Time, nt.NThreads)
 	nt.FunTimes = make(map[string]*timer.Time)
+	nt.LayTimes = make([]timer.Time, len(nt.Layers))
 	for _, ly := range nt.Layers {
 		if ly.IsOff() {
 			continue
@@ -186,8 +197,15 @@ func (nt *NetworkStru) BoundsUpdt() {
 // Calls UpdateParams to ensure derived parameters are all updated.
 // If setMsg is true, then a message is printed to confirm each parameter that is set.
 // it always prints a message if a parameter fails to be set.
+// If ParamsStrict is set, the sheet is first checked with ValidateParams and
+// nothing is applied if there are any errors.
 // returns true if any params were set, and error if there were any errors.
 func (nt *NetworkStru) ApplyParams(pars *params.Sheet, setMsg bool) (bool, error) {
+	if nt.ParamsStrict {
+		if err := nt.ValidateParams(pars); err != nil {
+			return false, err
+		}
+	}
 	applied := false
 	var rerr error
 	for _, ly := range nt.Layers {
@@ -373,10 +391,39 @@ func (nt *NetworkStru) WriteWtsJSON(w io.Writer) {
 	w.Write([]byte("}\n"))
 }
 
-// ReadWtsJSON reads the weights from this layer from the receiver-side perspective
-// in a JSON text format.
+// ReadWtsJSON reads network weights from the receiver-side perspective
+// in a JSON text format.  Reads entire file into a temporary structure
+// and then sets the weights for each layer -- see SetWts.
 func (nt *NetworkStru) ReadWtsJSON(r io.Reader) error {
-	return nil
+	nw, err := ReadNetWtsJSON(r)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	return nt.SetWts(nw)
+}
+
+// SetWts sets the weights for this network from NetWts decoded values.
+// The layers and projections must match exactly -- use Network.SetWtsMapped
+// to load weights saved from a different architecture.  Returns an error if
+// a layer is not found or does not match, but continues to set the rest.
+func (nt *NetworkStru) SetWts(nw *NetWts) error {
+	var err error
+	if nw.Network != nt.Nm {
+		log.Printf("NetworkStru.SetWts: note: weights were saved from network: %v, loading into: %v\n", nw.Network, nt.Nm)
+	}
+	for li := range nw.Layers {
+		lw := &nw.Layers[li]
+		ly, lerr := nt.LayerByNameTry(lw.Layer)
+		if lerr != nil {
+			err = lerr
+			continue
+		}
+		if lerr := ly.(LeabraLayer).AsLeabra().SetWts(lw); lerr != nil {
+			err = lerr
+		}
+	}
+	return err
 }
 
 // VarRange returns the min / max values for given variable
@@ -430,6 +477,12 @@ func (nt *NetworkStru) ThrWorker(tt int) {
 			if ly.IsOff() {
 				continue
 			}
+			if nt.LayTiming {
+				nt.LayTimes[ly.Index()].Start()
+				fun(ly.(LeabraLayer))
+				nt.LayTimes[ly.Index()].Stop()
+				continue
+			}
 			fun(ly.(LeabraLayer))
 		}
 		nt.ThrTimes[tt].Stop()
@@ -441,11 +494,20 @@ func (nt *NetworkStru) ThrWorker(tt int) {
 // and otherwise just iterates over layers in the current thread.
 func (nt *NetworkStru) ThrLayFun(fun func(ly LeabraLayer), funame string) {
 	nt.FunTimerStart(funame)
+	if nt.Profile {
+		fun = nt.profFun(fun, funame)
+	}
 	if nt.NThreads <= 1 {
-		for _, ly := range nt.Layers {
+		for li, ly := range nt.Layers {
 			if ly.IsOff() {
 				continue
 			}
+			if nt.LayTiming {
+				nt.LayTimes[li].Start()
+				fun(ly.(LeabraLayer))
+				nt.LayTimes[li].Stop()
+				continue
+			}
 			fun(ly.(LeabraLayer))
 		}
 	} else {
@@ -0,0 +1,83 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math/rand"
+)
+
+// Study status of the items in the OneShot protocol
+const (
+	// Studied are the items presented in the study phase
+	Studied = "Studied"
+
+	// Unstudied are the items only presented at test, as a baseline
+	Unstudied = "Unstudied"
+)
+
+// OneShot configures the episodic one-shot learning protocol: a random
+// StudyProp of the items are studied, each presented Reps times in a single
+// study epoch, followed by sleep and a delayed test of all the items, with
+// the unstudied items as the baseline -- optionally with an immediate test
+// before sleep.  The Status of each item (Studied or Unstudied) is tracked,
+// e.g., as the tags of a CatStats, and the test Phase (Immediate or
+// Delayed) is recorded by the caller.
+type OneShot struct {
+	On        bool            `desc:"if true, training follows the one-shot protocol"`
+	StudyProp float64         `min:"0" max:"1" desc:"proportion of the items studied -- the rest are unstudied"`
+	Reps      int             `min:"1" desc:"number of presentations of each studied item in the study phase: 1 for one-shot"`
+	ImmTest   bool            `desc:"if true, all the items are also tested immediately after study, before sleep"`
+	Studied   map[string]bool `inactive:"+" desc:"the items studied, by name"`
+	Phase     string          `inactive:"+" desc:"current test phase: Immediate or Delayed"`
+}
+
+func (ot *OneShot) Defaults() {
+	ot.StudyProp = 0.5
+	ot.Reps = 1
+}
+
+// Select chooses the items to study of those with given names, using given
+// random number stream, and returns their indexes -- the number studied is
+// StudyProp of them, rounded
+func (ot *OneShot) Select(names []string, rnd *rand.Rand) []int {
+	nst := SplitCounts([]float64{ot.StudyProp, 1 - ot.StudyProp}, len(names))[0]
+	perm := rnd.Perm(len(names))
+	idxs := append([]int{}, perm[:nst]...)
+	ot.Studied = make(map[string]bool, nst)
+	for _, i := range idxs {
+		ot.Studied[names[i]] = true
+	}
+	return idxs
+}
+
+// Status returns the study status of the item with given name: Studied or
+// Unstudied -- "" if not On
+func (ot *OneShot) Status(name string) string {
+	switch {
+	case !ot.On:
+		return ""
+	case ot.Studied[name]:
+		return Studied
+	}
+	return Unstudied
+}
+
+// Order returns the order of the study phase: each of given items Reps
+// times, permuted using given random number stream (global generator if nil)
+func (ot *OneShot) Order(items []int, rnd *rand.Rand) []int {
+	var all []int
+	for r := 0; r < ot.Reps || r == 0; r++ {
+		all = append(all, items...)
+	}
+	perm := rand.Perm
+	if rnd != nil {
+		perm = rnd.Perm
+	}
+	ord := make([]int, len(all))
+	for i, pi := range perm(len(all)) {
+		ord[i] = all[pi]
+	}
+	return ord
+}
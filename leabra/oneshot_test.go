@@ -0,0 +1,49 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestOneShot(t *testing.T) {
+	names := make([]string, 10)
+	for i := range names {
+		names[i] = fmt.Sprintf("p%d", i)
+	}
+	ot := &OneShot{On: true}
+	ot.Defaults()
+	ot.StudyProp = 0.3
+	idxs := ot.Select(names, rand.New(rand.NewSource(1)))
+	if len(idxs) != 3 || len(ot.Studied) != 3 {
+		t.Fatalf("studied: %v\n", idxs)
+	}
+	nst := 0
+	for _, nm := range names {
+		if ot.Status(nm) == Studied {
+			nst++
+		} else if ot.Status(nm) != Unstudied {
+			t.Errorf("status of %v: %v\n", nm, ot.Status(nm))
+		}
+	}
+	if nst != 3 || ot.Status(names[idxs[0]]) != Studied {
+		t.Errorf("%d studied\n", nst)
+	}
+	ot.Reps = 2
+	ord := ot.Order(idxs, rand.New(rand.NewSource(1)))
+	cnt := map[int]int{}
+	for _, i := range ord {
+		cnt[i]++
+	}
+	if len(ord) != 6 || cnt[idxs[0]] != 2 || cnt[idxs[2]] != 2 {
+		t.Errorf("study order: %v\n", ord)
+	}
+	ot.On = false
+	if ot.Status(names[idxs[0]]) != "" {
+		t.Errorf("status when off\n")
+	}
+}
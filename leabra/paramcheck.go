@@ -0,0 +1,245 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/emer/emergent/params"
+)
+
+// ParamPaths returns all the param paths for given object, starting with
+// given type name (e.g., Layer), for all the exported fields of basic types
+// (numbers, bool, string), recursing into struct fields.  Embedded structs
+// are flattened, as in param paths.
+func ParamPaths(obj interface{}, typ string) []string {
+	var paths []string
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	paramPathsType(t, typ, &paths, 0)
+	sort.Strings(paths)
+	return paths
+}
+
+func paramPathsType(t reflect.Type, prefix string, paths *[]string, depth int) {
+	if depth > 10 {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		ft := f.Type
+		switch ft.Kind() {
+		case reflect.Struct:
+			if f.Anonymous {
+				paramPathsType(ft, prefix, paths, depth+1)
+			} else {
+				paramPathsType(ft, prefix+"."+f.Name, paths, depth+1)
+			}
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64, reflect.String:
+			*paths = append(*paths, prefix+"."+f.Name)
+		}
+	}
+}
+
+// editDist returns the Levenshtein edit distance between two strings,
+// ignoring case
+func editDist(a, b string) int {
+	ar := []rune(strings.ToLower(a))
+	br := []rune(strings.ToLower(b))
+	prv := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prv {
+		prv[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prv[j]+1, cur[j-1]+1, prv[j-1]+cost)
+		}
+		prv, cur = cur, prv
+	}
+	return prv[len(br)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// isSubseq returns true if all the runes of a appear in b, in order
+// (e.g., an abbreviation such as Hid1 for Hidden1)
+func isSubseq(a, b string) bool {
+	br := []rune(b)
+	j := 0
+	for _, r := range a {
+		for j < len(br) && br[j] != r {
+			j++
+		}
+		if j == len(br) {
+			return false
+		}
+		j++
+	}
+	return true
+}
+
+// Suggest returns the closest candidates to given string, by edit distance
+// (ignoring case), within a distance of a third of its length (min 2),
+// or where one is an abbreviation of the other -- at most 3 are returned,
+// closest first.
+func Suggest(str string, cands []string) []string {
+	maxd := len(str) / 3
+	if maxd < 2 {
+		maxd = 2
+	}
+	type cand struct {
+		s string
+		d int
+	}
+	var cs []cand
+	lstr := strings.ToLower(str)
+	for _, c := range cands {
+		d := editDist(str, c)
+		lc := strings.ToLower(c)
+		if d > maxd && !isSubseq(lstr, lc) && !isSubseq(lc, lstr) {
+			continue
+		}
+		cs = append(cs, cand{c, d})
+	}
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].d < cs[j].d })
+	var sug []string
+	for i := 0; i < len(cs) && i < 3; i++ {
+		sug = append(sug, cs[i].s)
+	}
+	return sug
+}
+
+func suggestStr(str string, cands []string) string {
+	sug := Suggest(str, cands)
+	if len(sug) == 0 {
+		return ""
+	}
+	return " -- did you mean: " + strings.Join(sug, " or ") + "?"
+}
+
+// paramObj is an object that params selectors can target
+type paramObj interface {
+	TypeName() string
+	Class() string
+	Name() string
+}
+
+// paramTargets returns the layers and prjns in the network
+func (nt *NetworkStru) paramTargets() []paramObj {
+	var objs []paramObj
+	for _, ly := range nt.Layers {
+		objs = append(objs, ly)
+		for _, p := range *ly.RecvPrjns() {
+			objs = append(objs, p)
+		}
+	}
+	return objs
+}
+
+// ValidateParams checks all the selectors and param paths in given sheet
+// against the layers and projections in the network, and returns an error
+// listing any that would silently not apply: selectors that do not match
+// any layer or projection, paths that do not exist on the target type, and
+// paths whose type does not match any object the selector matches.
+// Near matches are suggested for names and paths.
+func (nt *NetworkStru) ValidateParams(pars *params.Sheet) error {
+	objs := nt.paramTargets()
+	var names, classes, types []string
+	tobjs := map[string]interface{}{} // first object of each type
+	has := map[string]bool{}
+	add := func(lst *[]string, s string) {
+		if s != "" && !has[s] {
+			has[s] = true
+			*lst = append(*lst, s)
+		}
+	}
+	for _, obj := range objs {
+		add(&names, "#"+obj.Name())
+		for _, c := range strings.Fields(obj.Class()) {
+			add(&classes, "."+c)
+		}
+		typ := obj.TypeName()
+		if _, ok := tobjs[typ]; !ok {
+			tobjs[typ] = obj
+			add(&types, typ)
+		}
+	}
+	tpaths := map[string][]string{}
+	var errs []string
+	for _, sl := range *pars {
+		mtyps := map[string]bool{} // types of objects that the selector matches
+		for _, obj := range objs {
+			if ParamSelMatch(sl.Sel, obj.TypeName(), obj.Name(), obj.Class()) {
+				mtyps[obj.TypeName()] = true
+			}
+		}
+		if len(mtyps) == 0 {
+			var cands []string
+			switch {
+			case strings.HasPrefix(sl.Sel, "#"):
+				cands = names
+			case strings.HasPrefix(sl.Sel, "."):
+				cands = classes
+			default:
+				cands = types
+			}
+			errs = append(errs, fmt.Sprintf("selector: %v does not match any layer or projection%v", sl.Sel, suggestStr(sl.Sel, cands)))
+			continue
+		}
+		for path := range sl.Params {
+			typ := ParamPathType(path)
+			tobj, ok := tobjs[typ]
+			if !ok {
+				errs = append(errs, fmt.Sprintf("selector: %v path: %v: unknown type: %v%v", sl.Sel, path, typ, suggestStr(typ, types)))
+				continue
+			}
+			if _, err := ParamPathValue(tobj, path); err != nil {
+				pths, ok := tpaths[typ]
+				if !ok {
+					pths = ParamPaths(tobj, typ)
+					tpaths[typ] = pths
+				}
+				errs = append(errs, fmt.Sprintf("selector: %v path: %v does not exist%v", sl.Sel, path, suggestStr(path, pths)))
+				continue
+			}
+			if !mtyps[typ] {
+				errs = append(errs, fmt.Sprintf("selector: %v path: %v never applies: selector does not match any %v", sl.Sel, path, typ))
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.Strings(errs)
+	err := errors.New("ValidateParams: " + nt.Nm + ":\n\t" + strings.Join(errs, "\n\t"))
+	log.Println(err)
+	return err
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/params"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestValidateParams(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "ParamNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden1", 2, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+
+	good := &params.Sheet{
+		{Sel: "Layer", Params: params.Params{"Layer.Inhib.Layer.Gi": "1.8"}},
+		{Sel: ".Forward", Params: params.Params{"Prjn.WtScale.Rel": "1"}},
+	}
+	if err := net.ValidateParams(good); err != nil {
+		t.Errorf("unexpected error: %v\n", err)
+	}
+	bad := &params.Sheet{
+		{Sel: "#Hid1", Params: params.Params{"Layer.Inhib.Layer.Gi": "1.8"}},
+		{Sel: "Layer", Params: params.Params{"Layer.Inhib.Layer.GI": "1.8"}},
+		{Sel: "#Hidden1", Params: params.Params{"Prjn.WtScale.Rel": "1"}},
+	}
+	err := net.ValidateParams(bad)
+	if err == nil {
+		t.Fatalf("expected errors\n")
+	}
+	for _, exp := range []string{"#Hid1 does not match", "did you mean: #Hidden1", "did you mean: Layer.Inhib.Layer.Gi", "never applies"} {
+		if !strings.Contains(err.Error(), exp) {
+			t.Errorf("error does not contain: %v\n%v\n", exp, err)
+		}
+	}
+	net.ParamsStrict = true
+	if app, _ := net.ApplyParams(bad, false); app {
+		t.Errorf("strict ApplyParams should not apply an invalid sheet\n")
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// StatVal returns the value of a numeric field with given name in obj
+// (a struct or pointer to struct, e.g., the Sim), as a float64, for use in
+// conditions on runtime state.  Nested fields can be specified with dots.
+func StatVal(obj interface{}, name string) (float64, error) {
+	v, err := ParamPathValue(obj, "_."+name) // first element is skipped
+	if err != nil {
+		return 0, err
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, fmt.Errorf("StatVal: field: %v is not numeric, type: %v", name, v.Type())
+}
+
+// ParamCond is a condition on a runtime stat, e.g., EpcSSE > 0.5
+type ParamCond struct {
+	Stat string  `desc:"name of the stat, a numeric field of the object the condition is evaluated on, e.g., EpcSSE in the Sim"`
+	Op   string  `desc:"comparison operator: > >= < <= == !="`
+	Val  float64 `desc:"value to compare to"`
+}
+
+func (pc *ParamCond) String() string {
+	return fmt.Sprintf("%v %v %v", pc.Stat, pc.Op, pc.Val)
+}
+
+// condOps are the comparison operators, longest first for parsing
+var condOps = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// ParseParamCond parses a condition of the form: Stat Op Val, e.g., EpcSSE > 0.5
+func ParseParamCond(str string) (ParamCond, error) {
+	pc := ParamCond{}
+	for _, op := range condOps {
+		oi := strings.Index(str, op)
+		if oi < 0 {
+			continue
+		}
+		pc.Stat = strings.TrimSpace(str[:oi])
+		pc.Op = op
+		var err error
+		pc.Val, err = strconv.ParseFloat(strings.TrimSpace(str[oi+len(op):]), 64)
+		if err != nil || pc.Stat == "" {
+			err = fmt.Errorf("ParseParamCond: expected Stat Op Val in: %v", str)
+			log.Println(err)
+			return pc, err
+		}
+		return pc, nil
+	}
+	err := fmt.Errorf("ParseParamCond: no comparison operator (%v) in: %v", strings.Join(condOps, " "), str)
+	log.Println(err)
+	return pc, err
+}
+
+// Eval evaluates the condition on given object
+func (pc *ParamCond) Eval(obj interface{}) (bool, error) {
+	sv, err := StatVal(obj, pc.Stat)
+	if err != nil {
+		log.Println(err)
+		return false, err
+	}
+	switch pc.Op {
+	case ">":
+		return sv > pc.Val, nil
+	case ">=":
+		return sv >= pc.Val, nil
+	case "<":
+		return sv < pc.Val, nil
+	case "<=":
+		return sv <= pc.Val, nil
+	case "==":
+		return sv == pc.Val, nil
+	case "!=":
+		return sv != pc.Val, nil
+	}
+	err = fmt.Errorf("ParamCond: unknown operator: %v", pc.Op)
+	log.Println(err)
+	return false, err
+}
+
+// CondParamSet is a params.Set (by name) that is in effect only while its
+// condition holds
+type CondParamSet struct {
+	Set    string    `desc:"name of the params.Set to apply"`
+	Cond   ParamCond `desc:"condition under which the set applies"`
+	Active bool      `inactive:"+" desc:"whether the set is currently active"`
+}
+
+func (cs *CondParamSet) String() string {
+	return cs.Set + ": " + cs.Cond.String()
+}
+
+// CondParamSets is a list of conditional param sets, evaluated at epoch
+// boundaries.  When any set becomes active or inactive, the sim re-applies
+// its regular params followed by all the ActiveSets in order, so that
+// inactive sets are reverted.
+type CondParamSets []CondParamSet
+
+// AddString adds conditional sets from specs separated by ; each of the form
+// Set: Stat Op Val, e.g., "HighInhib: EpcSSE > 0.5"
+func (css *CondParamSets) AddString(specs string) error {
+	for _, spec := range strings.Split(specs, ";") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		ci := strings.Index(spec, ":")
+		if ci < 0 {
+			err := fmt.Errorf("CondParamSets: expected Set: Stat Op Val in: %v", spec)
+			log.Println(err)
+			return err
+		}
+		pc, err := ParseParamCond(spec[ci+1:])
+		if err != nil {
+			return err
+		}
+		*css = append(*css, CondParamSet{Set: strings.TrimSpace(spec[:ci]), Cond: pc})
+	}
+	return nil
+}
+
+// Reset sets all to inactive
+func (css CondParamSets) Reset() {
+	for i := range css {
+		css[i].Active = false
+	}
+}
+
+// Update evaluates all the conditions on given object (e.g., the Sim) and
+// updates their Active state, returning true if any changed
+func (css CondParamSets) Update(obj interface{}) bool {
+	chg := false
+	for i := range css {
+		cs := &css[i]
+		act, err := cs.Cond.Eval(obj)
+		if err != nil {
+			act = false
+		}
+		if act != cs.Active {
+			cs.Active = act
+			chg = true
+		}
+	}
+	return chg
+}
+
+// ActiveSets returns the names of the currently active sets, in order
+func (css CondParamSets) ActiveSets() []string {
+	var sets []string
+	for i := range css {
+		if css[i].Active {
+			sets = append(sets, css[i].Set)
+		}
+	}
+	return sets
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+)
+
+type condTestSim struct {
+	EpcSSE float64
+	Epoch  int
+}
+
+func TestCondParamSets(t *testing.T) {
+	var css CondParamSets
+	if err := css.AddString("HighInhib: EpcSSE > 0.5; Late: Epoch >= 10"); err != nil {
+		t.Fatal(err)
+	}
+	sim := &condTestSim{EpcSSE: 1}
+	if !css.Update(sim) {
+		t.Errorf("expected change\n")
+	}
+	if act := css.ActiveSets(); len(act) != 1 || act[0] != "HighInhib" {
+		t.Errorf("active: %v, want [HighInhib]\n", act)
+	}
+	if css.Update(sim) {
+		t.Errorf("expected no change\n")
+	}
+	sim.EpcSSE = 0.2
+	sim.Epoch = 10
+	css.Update(sim)
+	if act := css.ActiveSets(); len(act) != 1 || act[0] != "Late" {
+		t.Errorf("active: %v, want [Late]\n", act)
+	}
+	if _, err := ParseParamCond("EpcSSE 0.5"); err == nil {
+		t.Errorf("expected error for missing operator\n")
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/emer/emergent/params"
+)
+
+// ParamExtends declares inheritance among params.Sets, as a map from the
+// name of a set to the name of the set that it extends, e.g.,
+// "SleepWeakBLA": "Sleep" -- a set that extends another only needs the
+// values that differ, and is resolved (see Resolve) to the sels of the set
+// it extends (recursively) followed by its own, so its values override.
+type ParamExtends map[string]string
+
+// Chain returns the names of the set with given name and all the sets it
+// extends, starting with the one that extends no other, i.e., in the order
+// in which they are applied.  Returns an error if the sets extend each other
+// in a cycle.
+func (pe ParamExtends) Chain(name string) ([]string, error) {
+	chain := []string{name}
+	seen := map[string]bool{name: true}
+	for nm := name; ; {
+		par, has := pe[nm]
+		if !has || par == "" {
+			break
+		}
+		if seen[par] {
+			err := fmt.Errorf("ParamExtends: cycle in params sets: %v -> %v", strings.Join(chain, " -> "), par)
+			log.Println(err)
+			return nil, err
+		}
+		seen[par] = true
+		chain = append(chain, par)
+		nm = par
+	}
+	return reverseStrs(chain), nil
+}
+
+// reverseStrs returns the strings in reverse order
+func reverseStrs(strs []string) []string {
+	rs := make([]string, len(strs))
+	for i, s := range strs {
+		rs[len(strs)-1-i] = s
+	}
+	return rs
+}
+
+// Is returns true if the set with given name is, or extends (recursively),
+// the base set
+func (pe ParamExtends) Is(name, base string) bool {
+	chain, err := pe.Chain(name)
+	if err != nil {
+		return name == base
+	}
+	for _, nm := range chain {
+		if nm == base {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the set with given name from sets, with all the sets that
+// it extends merged in: each sheet has the sels of the sets it extends,
+// followed by its own, in the order in which they are applied, so that its
+// values override theirs.  The Desc of sels from extended sets is prefixed
+// with the [set name].  Returns the set itself if it extends no other.
+func (pe ParamExtends) Resolve(sets params.Sets, name string) (*params.Set, error) {
+	chain, err := pe.Chain(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(chain) == 1 {
+		return sets.SetByNameTry(name)
+	}
+	var psets []*params.Set
+	for _, nm := range chain {
+		ps, err := sets.SetByNameTry(nm)
+		if err != nil {
+			err = fmt.Errorf("ParamExtends: set: %v extends set: %v that is not found", name, nm)
+			log.Println(err)
+			return nil, err
+		}
+		psets = append(psets, ps)
+	}
+	rs := &params.Set{Name: name, Desc: psets[len(psets)-1].Desc + " (extends " + strings.Join(chain[:len(chain)-1], ", ") + ")",
+		Sheets: params.Sheets{}}
+	for i, ps := range psets {
+		for shnm, sh := range ps.Sheets {
+			rsh, has := rs.Sheets[shnm]
+			if !has {
+				rsh = &params.Sheet{}
+				rs.Sheets[shnm] = rsh
+			}
+			for _, sl := range *sh {
+				ns := &params.Sel{Sel: sl.Sel, Desc: sl.Desc, Params: params.Params{}}
+				if i < len(psets)-1 {
+					ns.Desc = "[" + ps.Name + "] " + sl.Desc
+				}
+				for pt, v := range sl.Params {
+					ns.Params[pt] = v
+				}
+				*rsh = append(*rsh, ns)
+			}
+		}
+	}
+	return rs, nil
+}
+
+// Validate checks that all the sets declared in ParamExtends exist in sets,
+// and that there are no cycles, returning an error listing any problems
+func (pe ParamExtends) Validate(sets params.Sets) error {
+	var errs []string
+	names := make([]string, 0, len(sets))
+	for _, ps := range sets {
+		names = append(names, ps.Name)
+	}
+	for _, nm := range pe.Names() {
+		for _, snm := range []string{nm, pe[nm]} {
+			if _, err := sets.SetByNameTry(snm); err != nil {
+				errs = append(errs, fmt.Sprintf("set: %v not found%v", snm, suggestStr(snm, names)))
+			}
+		}
+		if _, err := pe.Chain(nm); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	err := fmt.Errorf("ParamExtends: %v", strings.Join(errs, "\n"))
+	log.Println(err)
+	return err
+}
+
+// Names returns the names of the sets that extend another, sorted
+func (pe ParamExtends) Names() []string {
+	nms := make([]string, 0, len(pe))
+	for nm := range pe {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	return nms
+}
+
+// ResolvedString returns a resolved view of the set with given name: for
+// each sheet, the final value of each param path for each selector, after
+// all the sets it extends, with the set that the value came from
+func (pe ParamExtends) ResolvedString(sets params.Sets, name string) (string, error) {
+	chain, err := pe.Chain(name)
+	if err != nil {
+		return "", err
+	}
+	type val struct {
+		val, set string
+	}
+	type selVals struct {
+		sel   string
+		paths []string
+		vals  map[string]val
+	}
+	sheets := map[string][]*selVals{}
+	for _, nm := range chain {
+		ps, err := sets.SetByNameTry(nm)
+		if err != nil {
+			return "", err
+		}
+		for shnm, sh := range ps.Sheets {
+			for _, sl := range *sh {
+				var sv *selVals
+				for _, s := range sheets[shnm] {
+					if s.sel == sl.Sel {
+						sv = s
+						break
+					}
+				}
+				if sv == nil {
+					sv = &selVals{sel: sl.Sel, vals: map[string]val{}}
+					sheets[shnm] = append(sheets[shnm], sv)
+				}
+				pts := make([]string, 0, len(sl.Params))
+				for pt := range sl.Params {
+					pts = append(pts, pt)
+				}
+				sort.Strings(pts)
+				for _, pt := range pts {
+					if _, has := sv.vals[pt]; !has {
+						sv.paths = append(sv.paths, pt)
+					}
+					sv.vals[pt] = val{sl.Params[pt], nm}
+				}
+			}
+		}
+	}
+	shnms := make([]string, 0, len(sheets))
+	for shnm := range sheets {
+		shnms = append(shnms, shnm)
+	}
+	sort.Strings(shnms)
+	var b strings.Builder
+	fmt.Fprintf(&b, "ParamSet: %v (applies: %v)\n", name, strings.Join(chain, ", "))
+	for _, shnm := range shnms {
+		fmt.Fprintf(&b, "  Sheet: %v\n", shnm)
+		for _, sv := range sheets[shnm] {
+			fmt.Fprintf(&b, "    %v\n", sv.sel)
+			for _, pt := range sv.paths {
+				v := sv.vals[pt]
+				fmt.Fprintf(&b, "      %v = %v  [%v]\n", pt, v.val, v.set)
+			}
+		}
+	}
+	return b.String(), nil
+}
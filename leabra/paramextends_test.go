@@ -0,0 +1,67 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/params"
+)
+
+func TestParamExtends(t *testing.T) {
+	sets := params.Sets{
+		{Name: "Sleep", Sheets: params.Sheets{
+			"Network": &params.Sheet{
+				{Sel: "Layer", Params: params.Params{"Layer.Inhib.Layer.Gi": "1.8", "Layer.Act.Gbar.L": "0.2"}},
+			},
+		}},
+		{Name: "SleepHigh", Sheets: params.Sheets{
+			"Network": &params.Sheet{
+				{Sel: "Layer", Params: params.Params{"Layer.Inhib.Layer.Gi": "2.0"}},
+			},
+		}},
+		{Name: "SleepHigher", Sheets: params.Sheets{
+			"Sim": &params.Sheet{
+				{Sel: "Sim", Params: params.Params{"Sim.MaxEpcs": "10"}},
+			},
+		}},
+	}
+	pe := ParamExtends{"SleepHigh": "Sleep", "SleepHigher": "SleepHigh"}
+	if err := pe.Validate(sets); err != nil {
+		t.Error(err)
+	}
+	chain, _ := pe.Chain("SleepHigher")
+	if strings.Join(chain, " ") != "Sleep SleepHigh SleepHigher" {
+		t.Errorf("Chain wrong: %v\n", chain)
+	}
+	if !pe.Is("SleepHigher", "Sleep") || pe.Is("Sleep", "SleepHigh") {
+		t.Errorf("Is wrong\n")
+	}
+	rs, err := pe.Resolve(sets, "SleepHigher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	netp := *rs.Sheets["Network"]
+	if len(netp) != 2 || netp[1].Params["Layer.Inhib.Layer.Gi"] != "2.0" || len(*rs.Sheets["Sim"]) != 1 {
+		t.Errorf("Resolve wrong: %v sels\n", len(netp))
+	}
+	str, _ := pe.ResolvedString(sets, "SleepHigher")
+	if !strings.Contains(str, "Layer.Inhib.Layer.Gi = 2.0  [SleepHigh]") || !strings.Contains(str, "Layer.Act.Gbar.L = 0.2  [Sleep]") {
+		t.Errorf("ResolvedString wrong:\n%v\n", str)
+	}
+
+	pe["Sleep"] = "SleepHigher"
+	if _, err := pe.Chain("SleepHigh"); err == nil {
+		t.Errorf("expected cycle error\n")
+	}
+	if _, err := pe.Resolve(sets, "SleepHigh"); err == nil {
+		t.Errorf("expected cycle error from Resolve\n")
+	}
+	pe = ParamExtends{"SleepHigh": "Slep"}
+	if err := pe.Validate(sets); err == nil || !strings.Contains(err.Error(), "did you mean: Sleep") {
+		t.Errorf("expected missing set error with suggestion: %v\n", err)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"time"
+
+	"github.com/emer/emergent/params"
+	"github.com/goki/gi/gi"
+)
+
+// LiveParam is the current value of one param on one object, which can be
+// edited (e.g., in a giv.TableView) to apply a new value to the running
+// network, without re-initializing.
+type LiveParam struct {
+	Obj  string `desc:"name of the object: layer or projection name, or Sim"`
+	Path string `desc:"param path, e.g., Layer.Inhib.Layer.Gi"`
+	Val  string `desc:"value -- edit to apply a new value to the running network"`
+	Cur  string `inactive:"+" desc:"value currently in effect"`
+	Set  string `inactive:"+" desc:"params.Set that last applied the value, or Live if edited here"`
+}
+
+// ParamEdit is a changelog entry for a param edited live
+type ParamEdit struct {
+	Time  string `desc:"wall-clock time of the edit"`
+	Ctxt  string `desc:"context of the edit, e.g., run, epoch and trial counters"`
+	Sleep bool   `desc:"edit was made during sleep, and applies only during sleep -- otherwise only during wake"`
+	Obj   string `desc:"name of the object: layer or projection name, or Sim"`
+	Path  string `desc:"param path"`
+	Prev  string `desc:"value before the edit"`
+	Val   string `desc:"new value"`
+}
+
+// LiveParams is a list of the param values currently in effect, built from
+// the record of applied params (see ParamRecs), for live editing, with a
+// changelog of all edits.  Edits are kept as overrides that are re-applied
+// on top of the regular params whenever they are re-applied (see Sheet), for
+// the same mode (sleep or wake) that they were made in.
+type LiveParams struct {
+	Params []LiveParam `desc:"current param values -- edit Val to apply"`
+	Log    []ParamEdit `desc:"changelog of all edits, in order"`
+}
+
+// Reset clears the params and the changelog
+func (lp *LiveParams) Reset() {
+	lp.Params = nil
+	lp.Log = nil
+}
+
+// liveObj returns the object with given name: sim for Sim, else the layer
+// or projection in the network
+func liveObj(nt *Network, sim interface{}, name string) (interface{}, error) {
+	if name == "Sim" {
+		if sim == nil {
+			return nil, fmt.Errorf("LiveParams: no Sim object")
+		}
+		return sim, nil
+	}
+	var names []string
+	for _, obj := range nt.paramTargets() {
+		if obj.Name() == name {
+			return obj, nil
+		}
+		names = append(names, obj.Name())
+	}
+	return nil, fmt.Errorf("LiveParams: object: %v not found%v", name, suggestStr(name, names))
+}
+
+// Update rebuilds the Params from the final applied values in given records,
+// reading the value currently in effect on each object
+func (lp *LiveParams) Update(nt *Network, sim interface{}, recs ParamRecs) {
+	fin := recs.Final()
+	lp.Params = make([]LiveParam, 0, len(fin))
+	for _, rec := range fin {
+		obj, err := liveObj(nt, sim, rec.Obj)
+		if err != nil {
+			continue
+		}
+		cur := paramValString(obj, rec.Path)
+		lp.Params = append(lp.Params, LiveParam{Obj: rec.Obj, Path: rec.Path, Val: cur, Cur: cur, Set: rec.Set})
+	}
+}
+
+// Apply applies each param whose Val has been edited (differs from Cur) to
+// its object, adding an entry to the changelog with given context, and a
+// record with Set = Live to recs (if non-nil).  Returns the number applied,
+// and the last error for any that could not be applied, which are reverted.
+func (lp *LiveParams) Apply(nt *Network, sim interface{}, sleep bool, ctxt string, recs *ParamRecs, setMsg bool) (int, error) {
+	n := 0
+	var rerr error
+	for i := range lp.Params {
+		pv := &lp.Params[i]
+		if pv.Val == pv.Cur {
+			continue
+		}
+		obj, err := liveObj(nt, sim, pv.Obj)
+		if err == nil {
+			if _, err = ParamPathValue(obj, pv.Path); err != nil {
+				err = fmt.Errorf("LiveParams: path: %v does not exist%v", pv.Path, suggestStr(pv.Path, ParamPaths(obj, ParamPathType(pv.Path))))
+			}
+		}
+		if err == nil {
+			err = lp.applyVal(nt, sim, pv.Obj, pv.Path, pv.Val, setMsg)
+		}
+		if err != nil {
+			log.Println(err)
+			rerr = err
+			pv.Val = pv.Cur
+			continue
+		}
+		lp.Log = append(lp.Log, ParamEdit{Time: time.Now().Format("15:04:05"), Ctxt: ctxt, Sleep: sleep,
+			Obj: pv.Obj, Path: pv.Path, Prev: pv.Cur, Val: pv.Val})
+		if recs != nil {
+			sheet, sel := "Network", "#"+pv.Obj
+			if pv.Obj == "Sim" {
+				sheet, sel = "Sim", "Sim"
+			}
+			*recs = append(*recs, ParamRec{Set: "Live", Sheet: sheet, Sel: sel, Obj: pv.Obj, Path: pv.Path,
+				Val: pv.Val, Prev: pv.Cur})
+		}
+		pv.Cur = paramValString(obj, pv.Path)
+		pv.Val = pv.Cur
+		pv.Set = "Live"
+		n++
+	}
+	return n, rerr
+}
+
+// applyVal applies one value to the named object
+func (lp *LiveParams) applyVal(nt *Network, sim interface{}, obj, path, val string, setMsg bool) error {
+	if obj == "Sim" {
+		sh := params.Sheet{&params.Sel{Sel: "Sim", Desc: "live edit", Params: params.Params{path: val}}}
+		app, err := sh.Apply(sim, setMsg)
+		if err == nil && !app {
+			err = fmt.Errorf("LiveParams: could not apply Sim %v = %v", path, val)
+		}
+		return err
+	}
+	sh := params.Sheet{&params.Sel{Sel: "#" + obj, Desc: "live edit", Params: params.Params{path: val}}}
+	app, err := nt.ApplyParams(&sh, setMsg)
+	if err == nil && !app {
+		err = fmt.Errorf("LiveParams: could not apply %v %v = %v", obj, path, val)
+	}
+	return err
+}
+
+// Sheet returns a params.Sheet with the final edited values for given
+// sheet (Network or Sim), made during sleep or wake as given, to re-apply
+// on top of the regular params -- returns nil if there are none
+func (lp *LiveParams) Sheet(sheet string, sleep bool) *params.Sheet {
+	sh := params.Sheet{}
+	sels := map[string]*params.Sel{}
+	for _, ed := range lp.Log {
+		if ed.Sleep != sleep || (ed.Obj == "Sim") != (sheet == "Sim") {
+			continue
+		}
+		sel := "#" + ed.Obj
+		if sheet == "Sim" {
+			sel = "Sim"
+		}
+		sl, has := sels[sel]
+		if !has {
+			sl = &params.Sel{Sel: sel, Desc: "live edits", Params: params.Params{}}
+			sels[sel] = sl
+			sh = append(sh, sl)
+		}
+		sl.Params[ed.Path] = ed.Val
+	}
+	if len(sh) == 0 {
+		return nil
+	}
+	return &sh
+}
+
+// Reapply re-applies the edited values for given mode (sleep or wake) --
+// call after re-applying the regular params
+func (lp *LiveParams) Reapply(nt *Network, sim interface{}, sleep bool, setMsg bool) {
+	if netp := lp.Sheet("Network", sleep); netp != nil {
+		nt.ApplyParams(netp, setMsg)
+	}
+	if simp := lp.Sheet("Sim", sleep); simp != nil && sim != nil {
+		simp.Apply(sim, setMsg)
+	}
+}
+
+// SaveLog saves the changelog to a JSON file
+func (lp *LiveParams) SaveLog(filename gi.FileName) error {
+	b, err := json.MarshalIndent(lp.Log, "", "  ")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/params"
+)
+
+func TestLiveParams(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "LiveNet")
+	net.AddLayer2D("Input", 2, 2, emer.Hidden)
+	net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.Defaults()
+	sh := &params.Sheet{
+		{Sel: "#Hidden", Params: params.Params{"Layer.Inhib.Layer.Gi": "1.6"}},
+	}
+	var prs ParamRecs
+	prs.RecordParams(net, "Base", sh)
+	net.ApplyParams(sh, false)
+
+	var lp LiveParams
+	lp.Update(net, nil, prs)
+	if len(lp.Params) != 1 || lp.Params[0].Cur != "1.6" {
+		t.Fatalf("Update wrong: %+v\n", lp.Params)
+	}
+	lp.Params[0].Val = "1.9"
+	n, err := lp.Apply(net, nil, false, "test", &prs, false)
+	if n != 1 || err != nil {
+		t.Fatalf("Apply: got %v, %v\n", n, err)
+	}
+	hid := net.LayerByName("Hidden").(*Layer)
+	if hid.Inhib.Layer.Gi != 1.9 {
+		t.Errorf("Gi not applied: %v\n", hid.Inhib.Layer.Gi)
+	}
+	if len(lp.Log) != 1 || lp.Log[0].Prev != "1.6" || lp.Log[0].Val != "1.9" {
+		t.Errorf("changelog wrong: %+v\n", lp.Log)
+	}
+	if fin := prs.Final(); fin[0].Set != "Live" || fin[0].Val != "1.9" {
+		t.Errorf("record wrong: %+v\n", fin)
+	}
+
+	net.ApplyParams(sh, false) // regular params re-applied, e.g., after sleep
+	lp.Reapply(net, nil, true, false)
+	if hid.Inhib.Layer.Gi != 1.6 {
+		t.Errorf("wake edit should not apply during sleep: %v\n", hid.Inhib.Layer.Gi)
+	}
+	lp.Reapply(net, nil, false, false)
+	if hid.Inhib.Layer.Gi != 1.9 {
+		t.Errorf("wake edit not re-applied: %v\n", hid.Inhib.Layer.Gi)
+	}
+
+	lp.Params[0].Path = "Layer.Inhib.Layer.Gj"
+	lp.Params[0].Val = "2"
+	if n, err = lp.Apply(net, nil, false, "test", nil, false); n != 0 || err == nil {
+		t.Errorf("bad path should not apply: %v, %v\n", n, err)
+	}
+	if lp.Params[0].Val != lp.Params[0].Cur {
+		t.Errorf("bad edit should be reverted: %+v\n", lp.Params[0])
+	}
+}
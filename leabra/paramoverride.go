@@ -0,0 +1,118 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/emer/emergent/params"
+)
+
+// ParamOverride is a single param value, e.g., from the command line, that
+// overrides the value from the compiled-in param sets
+type ParamOverride struct {
+	Sel  string `desc:"selector -- defaults to the type element of the Path (e.g., Layer) if empty"`
+	Path string `desc:"param path, e.g., Layer.Inhib.Layer.Gi"`
+	Val  string `desc:"value to set"`
+}
+
+// Selector returns the selector, defaulting to the type element of the Path
+func (po *ParamOverride) Selector() string {
+	if po.Sel != "" {
+		return po.Sel
+	}
+	return ParamPathType(po.Path)
+}
+
+// Sheet returns the name of the params sheet for this override: Sim for Sim
+// params, Network otherwise
+func (po *ParamOverride) Sheet() string {
+	if ParamPathType(po.Path) == "Sim" {
+		return "Sim"
+	}
+	return "Network"
+}
+
+func (po *ParamOverride) String() string {
+	if po.Sel == "" {
+		return po.Path + "=" + po.Val
+	}
+	return po.Sel + " " + po.Path + "=" + po.Val
+}
+
+// ParseParamOverride parses an override of the form: [Sel ]Path=Val, e.g.,
+// "Layer.Inhib.Layer.Gi=1.7" or "#Output Layer.Inhib.Layer.Gi=1.4"
+func ParseParamOverride(spec string) (ParamOverride, error) {
+	po := ParamOverride{}
+	spec = strings.TrimSpace(spec)
+	ei := strings.Index(spec, "=")
+	if ei < 0 {
+		err := fmt.Errorf("ParseParamOverride: no = in: %v -- format is: [Sel ]Path=Val", spec)
+		log.Println(err)
+		return po, err
+	}
+	lhs := strings.Fields(spec[:ei])
+	po.Val = strings.TrimSpace(spec[ei+1:])
+	switch {
+	case len(lhs) == 1:
+		po.Path = lhs[0]
+	case len(lhs) == 2:
+		po.Sel = lhs[0]
+		po.Path = lhs[1]
+	}
+	if po.Path == "" || po.Val == "" || !strings.Contains(po.Path, ".") {
+		err := fmt.Errorf("ParseParamOverride: expected [Sel ]Type.Path=Val in: %v", spec)
+		log.Println(err)
+		return po, err
+	}
+	return po, nil
+}
+
+// ParamOverrides is a list of param overrides, applied in order, so later
+// ones take precedence
+type ParamOverrides []ParamOverride
+
+// AddString adds overrides from specs separated by ; -- see ParseParamOverride
+func (pos *ParamOverrides) AddString(specs string) error {
+	for _, spec := range strings.Split(specs, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		po, err := ParseParamOverride(spec)
+		if err != nil {
+			return err
+		}
+		*pos = append(*pos, po)
+	}
+	return nil
+}
+
+func (pos ParamOverrides) String() string {
+	strs := make([]string, len(pos))
+	for i := range pos {
+		strs[i] = pos[i].String()
+	}
+	return strings.Join(strs, "; ")
+}
+
+// Set returns a params.Set with given name containing all the overrides,
+// to be applied after the other params
+func (pos ParamOverrides) Set(name string) *params.Set {
+	pset := &params.Set{Name: name, Desc: pos.String(), Sheets: params.Sheets{}}
+	for i := range pos {
+		po := &pos[i]
+		shnm := po.Sheet()
+		sh, ok := pset.Sheets[shnm]
+		if !ok {
+			sh = &params.Sheet{}
+			pset.Sheets[shnm] = sh
+		}
+		*sh = append(*sh, &params.Sel{Sel: po.Selector(), Desc: "override",
+			Params: params.Params{po.Path: po.Val}})
+	}
+	return pset
+}
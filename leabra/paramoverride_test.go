@@ -0,0 +1,37 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import "testing"
+
+func TestParamOverrides(t *testing.T) {
+	var pos ParamOverrides
+	if err := pos.AddString("Layer.Inhib.Layer.Gi=1.7; #Output Prjn.Learn.Lrate = 0.02"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pos.AddString("Sim.MaxEpcs=10"); err != nil {
+		t.Fatal(err)
+	}
+	if len(pos) != 3 {
+		t.Fatalf("got %v overrides, want 3\n", len(pos))
+	}
+	if po := pos[1]; po.Sel != "#Output" || po.Path != "Prjn.Learn.Lrate" || po.Val != "0.02" {
+		t.Errorf("parse wrong: %+v\n", po)
+	}
+	pset := pos.Set("Overrides")
+	netp, ok := pset.Sheets["Network"]
+	if !ok || len(*netp) != 2 || (*netp)[0].Sel != "Layer" {
+		t.Errorf("Network sheet wrong: %+v\n", netp)
+	}
+	simp, ok := pset.Sheets["Sim"]
+	if !ok || len(*simp) != 1 || (*simp)[0].Params["Sim.MaxEpcs"] != "10" {
+		t.Errorf("Sim sheet wrong: %+v\n", simp)
+	}
+	for _, bad := range []string{"Gi", "Layer.Inhib.Layer.Gi", "Layer.Inhib.Layer.Gi=", "a b c=1"} {
+		if _, err := ParseParamOverride(bad); err == nil {
+			t.Errorf("expected error for: %v\n", bad)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"github.com/emer/emergent/params"
+)
+
+// ParamPair is a param with both a wake and a sleep value, declared in one
+// place, so that the two values cannot drift out of sync across separate
+// wake and sleep param sets, and the param is always restored on waking.
+type ParamPair struct {
+	Sel   string `desc:"selector, e.g., Layer, .Back, #Hidden1"`
+	Path  string `desc:"param path, e.g., Layer.Act.OptThresh.Send"`
+	Wake  string `desc:"value during wake (training and testing)"`
+	Sleep string `desc:"value during sleep"`
+	Desc  string `desc:"description of the param and why it differs in sleep"`
+}
+
+// Val returns the wake or sleep value
+func (pp *ParamPair) Val(sleep bool) string {
+	if sleep {
+		return pp.Sleep
+	}
+	return pp.Wake
+}
+
+// ParamPairs is a list of params with paired wake and sleep values
+type ParamPairs []ParamPair
+
+// Sheet returns a params.Sheet with the wake or sleep values
+func (pps ParamPairs) Sheet(sleep bool) *params.Sheet {
+	sh := &params.Sheet{}
+	for i := range pps {
+		pp := &pps[i]
+		*sh = append(*sh, &params.Sel{Sel: pp.Sel, Desc: pp.Desc, Params: params.Params{pp.Path: pp.Val(sleep)}})
+	}
+	return sh
+}
+
+// ApplyModeParams applies the wake or sleep values of the ModeParams --
+// the sim calls this when switching between wake and sleep, after the
+// regular wake or sleep params, so that anything applied later (e.g., a
+// ParamSet specific to sleep) can still override them.
+func (nt *Network) ApplyModeParams(sleep bool, setMsg bool) (bool, error) {
+	if len(nt.ModeParams) == 0 {
+		return false, nil
+	}
+	return nt.ApplyParams(nt.ModeParams.Sheet(sleep), setMsg)
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+)
+
+func TestModeParams(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "ModeNet")
+	net.AddLayer2D("Input", 2, 2, emer.Hidden)
+	net.Defaults()
+	net.ModeParams = ParamPairs{
+		{Sel: "Layer", Path: "Layer.Inhib.Layer.FB", Wake: "1", Sleep: "1.2"},
+	}
+	ly := net.LayerByName("Input").(*Layer)
+	if _, err := net.ApplyModeParams(true, false); err != nil {
+		t.Fatal(err)
+	}
+	if ly.Inhib.Layer.FB != 1.2 {
+		t.Errorf("sleep value not applied: %v\n", ly.Inhib.Layer.FB)
+	}
+	net.ApplyModeParams(false, false)
+	if ly.Inhib.Layer.FB != 1 {
+		t.Errorf("wake value not restored: %v\n", ly.Inhib.Layer.FB)
+	}
+}
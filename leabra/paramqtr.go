@@ -0,0 +1,112 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/emer/emergent/params"
+)
+
+// ParamQtr is a param with a value for each quarter of the alpha cycle,
+// e.g., a higher Layer.Act.Clamp.Gain in the plus phase (quarter 3), so that
+// minus / plus phase asymmetries can be configured in params, without custom
+// AlphaCyc code.
+type ParamQtr struct {
+	Sel  string    `desc:"selector, e.g., Layer, .Back, #Output"`
+	Path string    `desc:"param path, e.g., Layer.Act.Clamp.Gain"`
+	Vals [4]string `desc:"value for each quarter"`
+	Desc string    `desc:"description of the param and why it varies over quarters"`
+}
+
+// Changed returns true if the value for given quarter differs from the
+// previous quarter, i.e., it needs to be applied at the start of the quarter
+// -- always true for quarter 0, to reset from the end of the previous cycle
+func (pq *ParamQtr) Changed(qtr int) bool {
+	return qtr == 0 || pq.Vals[qtr] != pq.Vals[qtr-1]
+}
+
+// ParseParamQtr parses a quarter param of the form [Sel ]Path=v0,v1,v2,v3
+// e.g., "#Output Layer.Act.Clamp.Gain=0.2,0.2,0.2,1"
+func ParseParamQtr(spec string) (ParamQtr, error) {
+	pq := ParamQtr{}
+	spec = strings.TrimSpace(spec)
+	errf := func() (ParamQtr, error) {
+		err := fmt.Errorf("ParseParamQtr: expected [Sel ]Path=v0,v1,v2,v3 in: %v", spec)
+		log.Println(err)
+		return pq, err
+	}
+	ei := strings.Index(spec, "=")
+	if ei < 0 {
+		return errf()
+	}
+	lhs := strings.Fields(spec[:ei])
+	vals := strings.Split(spec[ei+1:], ",")
+	switch {
+	case len(vals) != 4:
+		return errf()
+	case len(lhs) == 1:
+		pq.Path = lhs[0]
+		pq.Sel = ParamPathType(pq.Path)
+	case len(lhs) == 2:
+		pq.Sel = lhs[0]
+		pq.Path = lhs[1]
+	default:
+		return errf()
+	}
+	for i, v := range vals {
+		pq.Vals[i] = strings.TrimSpace(v)
+	}
+	return pq, nil
+}
+
+// ParamQtrs is a list of params with values for each quarter
+type ParamQtrs []ParamQtr
+
+// AddString adds quarter params from specs separated by ; -- see ParseParamQtr
+func (pqs *ParamQtrs) AddString(specs string) error {
+	for _, spec := range strings.Split(specs, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		pq, err := ParseParamQtr(spec)
+		if err != nil {
+			return err
+		}
+		*pqs = append(*pqs, pq)
+	}
+	return nil
+}
+
+// Sheet returns a params.Sheet with the values for given quarter that
+// differ from the previous quarter (all of them for quarter 0)
+func (pqs ParamQtrs) Sheet(qtr int) *params.Sheet {
+	sh := &params.Sheet{}
+	for i := range pqs {
+		pq := &pqs[i]
+		if !pq.Changed(qtr) {
+			continue
+		}
+		*sh = append(*sh, &params.Sel{Sel: pq.Sel, Desc: pq.Desc, Params: params.Params{pq.Path: pq.Vals[qtr]}})
+	}
+	return sh
+}
+
+// ApplyQtrParams applies the values of the QtrParams for given quarter that
+// differ from the previous quarter -- called automatically at the start of
+// each quarter, in AlphaCycInit for quarter 0 and in QuarterFinal for the
+// next quarter, so they override the regular params within the alpha cycle.
+func (nt *Network) ApplyQtrParams(qtr int) (bool, error) {
+	if len(nt.QtrParams) == 0 {
+		return false, nil
+	}
+	sh := nt.QtrParams.Sheet(qtr)
+	if len(*sh) == 0 {
+		return false, nil
+	}
+	return nt.ApplyParams(sh, false)
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+)
+
+func TestParamQtrs(t *testing.T) {
+	var pqs ParamQtrs
+	if err := pqs.AddString("#Output Layer.Act.Clamp.Gain=0.2,0.2,0.2,1; Layer.Inhib.Layer.Gi=1.8,1.8,2,2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pqs.AddString("Layer.Inhib.Layer.Gi=1.8,2"); err == nil {
+		t.Errorf("expected error for wrong number of values\n")
+	}
+	if pqs[1].Sel != "Layer" {
+		t.Errorf("default selector: %v\n", pqs[1].Sel)
+	}
+	if n := len(*pqs.Sheet(0)); n != 2 {
+		t.Errorf("quarter 0 sheet: %v sels, want 2\n", n)
+	}
+	if n := len(*pqs.Sheet(1)); n != 0 {
+		t.Errorf("quarter 1 sheet: %v sels, want 0\n", n)
+	}
+
+	net := &Network{}
+	net.InitName(net, "QtrNet")
+	net.AddLayer2D("Input", 2, 2, emer.Input)
+	outLay := net.AddLayer2D("Output", 2, 2, emer.Target)
+	net.Defaults()
+	if err := net.Build(); err != nil {
+		t.Fatal(err)
+	}
+	net.InitWts()
+	net.QtrParams = pqs
+	out := outLay.(*Layer)
+	ltime := NewTime()
+	net.AlphaCycInit()
+	ltime.AlphaCycStart()
+	for qtr := 0; qtr < 4; qtr++ {
+		if qtr == 3 && out.Act.Clamp.Gain != 1 {
+			t.Errorf("plus phase Gain: %v, want 1\n", out.Act.Clamp.Gain)
+		}
+		if qtr < 3 && out.Act.Clamp.Gain != 0.2 {
+			t.Errorf("quarter %v Gain: %v, want 0.2\n", qtr, out.Act.Clamp.Gain)
+		}
+		if wantGi := []float32{1.8, 1.8, 2, 2}[qtr]; out.Inhib.Layer.Gi != wantGi {
+			t.Errorf("quarter %v Gi: %v, want %v\n", qtr, out.Inhib.Layer.Gi, wantGi)
+		}
+		net.QuarterFinal(ltime)
+		ltime.QuarterInc()
+	}
+	net.AlphaCycInit()
+	if out.Act.Clamp.Gain != 0.2 {
+		t.Errorf("next cycle Gain not reset: %v\n", out.Act.Clamp.Gain)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/emer/emergent/params"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+// ParamRec records one parameter value applied to one object, with the
+// value it replaced and its default value, so that the full set of params
+// used for a run can be reconstructed.
+type ParamRec struct {
+	Set   string `desc:"name of the params.Set that applied the value"`
+	Sheet string `desc:"name of the params.Sheet within the set, e.g., Network, Sim"`
+	Sel   string `desc:"selector that matched the object"`
+	Obj   string `desc:"name of the object: layer or projection name, or Sim"`
+	Path  string `desc:"param path, e.g., Layer.Inhib.Layer.Gi"`
+	Val   string `desc:"value applied"`
+	Prev  string `desc:"value before it was applied"`
+	Def   string `desc:"default value for the object type -- empty if the type has no Defaults method"`
+}
+
+// ParamRecs is a list of applied param records, in order of application
+type ParamRecs []ParamRec
+
+// Reset resets the list
+func (pr *ParamRecs) Reset() {
+	*pr = nil
+}
+
+// has returns true if an identical record is already in the list
+func (pr *ParamRecs) has(rec *ParamRec) bool {
+	for i := range *pr {
+		if (*pr)[i] == *rec {
+			return true
+		}
+	}
+	return false
+}
+
+// ParamPathValue returns the field value at given param path for object,
+// where the first element of the path is the type (e.g., Layer) and is
+// skipped, and the rest are field names, including through embedded structs.
+func ParamPathValue(obj interface{}, path string) (reflect.Value, error) {
+	v := reflect.ValueOf(obj)
+	flds := strings.Split(path, ".")
+	for fi, fnm := range flds {
+		if fi == 0 {
+			continue
+		}
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return v, fmt.Errorf("ParamPathValue: %v is not a struct at: %v in path: %v", v.Type(), fnm, path)
+		}
+		f := v.FieldByName(fnm)
+		if !f.IsValid() {
+			return f, fmt.Errorf("ParamPathValue: field: %v not found in type: %v in path: %v", fnm, v.Type(), path)
+		}
+		v = f
+	}
+	return v, nil
+}
+
+// paramValString returns the value at given path as a string, or "" if
+// obj is nil or the path is not found
+func paramValString(obj interface{}, path string) string {
+	if obj == nil {
+		return ""
+	}
+	v, err := ParamPathValue(obj, path)
+	if err != nil || !v.CanInterface() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// ParamPathType returns the type element (first element) of a param path
+func ParamPathType(path string) string {
+	if di := strings.Index(path, "."); di > 0 {
+		return path[:di]
+	}
+	return path
+}
+
+// ParamSelMatch returns true if params selector matches an object with given
+// type, name and class(es): .Class matches any of the space-separated
+// classes, #Name matches the name, and otherwise the selector is a type name.
+func ParamSelMatch(sel, typ, name, cls string) bool {
+	switch {
+	case strings.HasPrefix(sel, "."):
+		cnm := sel[1:]
+		for _, c := range strings.Fields(cls) {
+			if c == cnm {
+				return true
+			}
+		}
+		return false
+	case strings.HasPrefix(sel, "#"):
+		return sel[1:] == name
+	default:
+		return sel == typ
+	}
+}
+
+// defaultsObj returns a new object of the same type as obj with Defaults
+// called on it, or nil if the type has no Defaults method
+func defaultsObj(obj interface{}) interface{} {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	nobj := reflect.New(t).Interface()
+	df, ok := nobj.(interface{ Defaults() })
+	if !ok {
+		return nil
+	}
+	df.Defaults()
+	return nobj
+}
+
+// RecordObj adds records for the params in given sheet that apply to an
+// object of given type, name and classes, given its current (Prev) values and
+// an object with default values (defObj, which can be nil).  Call this before
+// applying the sheet.  Later sels for the same path override earlier ones as
+// in params application, so only the last value per path is recorded, with
+// Prev being the value before the sheet is applied.
+func (pr *ParamRecs) RecordObj(setNm, sheetNm string, pars *params.Sheet, obj interface{}, typ, name, cls string, defObj interface{}) {
+	idx := map[string]int{}
+	for _, sl := range *pars {
+		if !ParamSelMatch(sl.Sel, typ, name, cls) {
+			continue
+		}
+		paths := make([]string, 0, len(sl.Params))
+		for path := range sl.Params {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths) // deterministic order
+		for _, path := range paths {
+			val := sl.Params[path]
+			if ParamPathType(path) != typ {
+				continue
+			}
+			rec := ParamRec{Set: setNm, Sheet: sheetNm, Sel: sl.Sel, Obj: name, Path: path, Val: val,
+				Prev: paramValString(obj, path), Def: paramValString(defObj, path)}
+			if i, has := idx[path]; has {
+				(*pr)[i] = rec
+				continue
+			}
+			if pr.has(&rec) { // e.g., sets re-applied when switching between sleep and wake
+				continue
+			}
+			idx[path] = len(*pr)
+			*pr = append(*pr, rec)
+		}
+	}
+}
+
+// RecordParams adds records for the params in given Network sheet that apply
+// to the layers and projections in the network -- call before ApplyParams.
+func (pr *ParamRecs) RecordParams(nt *Network, setNm string, pars *params.Sheet) {
+	var ldef, pdef interface{}
+	for _, ly := range nt.Layers {
+		lly := ly.(LeabraLayer).AsLeabra()
+		if ldef == nil {
+			ldef = defaultsObj(lly)
+		}
+		pr.RecordObj(setNm, "Network", pars, lly, ly.TypeName(), ly.Name(), ly.Class(), ldef)
+		for _, p := range lly.RcvPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			if pdef == nil {
+				pdef = defaultsObj(pj)
+			}
+			pr.RecordObj(setNm, "Network", pars, pj, p.TypeName(), p.Name(), p.Class(), pdef)
+		}
+	}
+}
+
+// Final returns the records for the last value applied for each object and
+// path, with Prev being the value before any params were applied, in order
+// of first application
+func (pr ParamRecs) Final() ParamRecs {
+	var fin ParamRecs
+	idx := map[string]int{}
+	for _, rec := range pr {
+		key := rec.Obj + ":" + rec.Path
+		if i, has := idx[key]; has {
+			prv := fin[i].Prev
+			fin[i] = rec
+			fin[i].Prev = prv
+			continue
+		}
+		idx[key] = len(fin)
+		fin = append(fin, rec)
+	}
+	return fin
+}
+
+// Table returns the records as an etable.Table
+func (pr ParamRecs) Table() *etable.Table {
+	dt := &etable.Table{}
+	dt.SetMetaData("name", "ParamRecs")
+	dt.SetMetaData("desc", "record of applied parameters")
+	sch := etable.Schema{}
+	for _, nm := range []string{"Set", "Sheet", "Sel", "Obj", "Path", "Val", "Prev", "Def"} {
+		sch = append(sch, etable.Column{nm, etensor.STRING, nil, nil})
+	}
+	dt.SetFromSchema(sch, len(pr))
+	for i, rec := range pr {
+		dt.SetCellString("Set", i, rec.Set)
+		dt.SetCellString("Sheet", i, rec.Sheet)
+		dt.SetCellString("Sel", i, rec.Sel)
+		dt.SetCellString("Obj", i, rec.Obj)
+		dt.SetCellString("Path", i, rec.Path)
+		dt.SetCellString("Val", i, rec.Val)
+		dt.SetCellString("Prev", i, rec.Prev)
+		dt.SetCellString("Def", i, rec.Def)
+	}
+	return dt
+}
+
+// SaveJSON saves the records to a JSON file
+func (pr ParamRecs) SaveJSON(filename gi.FileName) error {
+	b, err := json.MarshalIndent(pr, "", "  ")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// OpenJSON opens records from a JSON file saved by SaveJSON
+func (pr *ParamRecs) OpenJSON(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = json.Unmarshal(b, pr)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// Sheets returns params.Sheets that reproduce the final applied values, one
+// sheet per Sheet name, with a #Name selector per object (or the type name
+// for Sim sheets).  Applying these on top of defaults reconstructs the params.
+func (pr ParamRecs) Sheets() params.Sheets {
+	shs := params.Sheets{}
+	sels := map[string]*params.Sel{}
+	for _, rec := range pr.Final() {
+		sh, ok := shs[rec.Sheet]
+		if !ok {
+			sh = &params.Sheet{}
+			shs[rec.Sheet] = sh
+		}
+		sel := "#" + rec.Obj
+		if rec.Sheet == "Sim" {
+			sel = ParamPathType(rec.Path)
+		}
+		key := rec.Sheet + ":" + sel
+		sl, has := sels[key]
+		if !has {
+			sl = &params.Sel{Sel: sel, Desc: "recorded params", Params: params.Params{}}
+			sels[key] = sl
+			*sh = append(*sh, sl)
+		}
+		sl.Params[rec.Path] = rec.Val
+	}
+	return shs
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/params"
+)
+
+func TestParamRecs(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "ParamNet")
+	net.AddLayer2D("Input", 2, 2, emer.Hidden)
+	net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.Defaults()
+	sh := &params.Sheet{
+		{Sel: "Layer", Params: params.Params{"Layer.Inhib.Layer.Gi": "1.8"}},
+		{Sel: "#Hidden", Params: params.Params{"Layer.Inhib.Layer.Gi": "1.6"}},
+	}
+	var prs ParamRecs
+	prs.RecordParams(net, "Base", sh)
+	net.ApplyParams(sh, false)
+	if len(prs) != 2 {
+		t.Fatalf("got %v records, want 2\n", len(prs))
+	}
+	hid := prs[1]
+	if hid.Obj != "Hidden" || hid.Sel != "#Hidden" || hid.Val != "1.6" {
+		t.Errorf("Hidden record wrong: %+v\n", hid)
+	}
+	if hid.Def == "" || hid.Def != hid.Prev {
+		t.Errorf("default should be recorded, and equal Prev after Defaults: %+v\n", hid)
+	}
+	prs.RecordParams(net, "Base", sh) // now Prev is the applied value: new records
+	prs.RecordParams(net, "Base", sh) // identical records are not added again
+	if len(prs) != 4 {
+		t.Errorf("got %v records after re-recording, want 4\n", len(prs))
+	}
+	fin := prs.Final()
+	if len(fin) != 2 || fin[1].Prev != hid.Prev {
+		t.Errorf("Final wrong: %+v\n", fin)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/params"
+)
+
+// ParamSched is a time-varying (annealed) parameter: the value goes from Start
+// at epoch StEpc to End at epoch EdEpc, and stays at End after that.
+// Before StEpc the schedule is not applied, so the regular params are in effect.
+type ParamSched struct {
+	Sel   string  `desc:"params selector to apply value to, e.g., Layer, .Back, #Output, Sim -- defaults to the first element of Path"`
+	Path  string  `desc:"param path, e.g., Layer.Inhib.Layer.Gi"`
+	Start float64 `desc:"value at StEpc"`
+	End   float64 `desc:"value at EdEpc and after"`
+	StEpc int     `desc:"epoch at which the schedule starts"`
+	EdEpc int     `desc:"epoch at which the schedule reaches the End value"`
+	Exp   bool    `desc:"interpolate exponentially (geometrically) instead of linearly -- Start and End must have the same sign and be non-zero"`
+}
+
+// Active returns true if the schedule applies at given epoch
+func (ps *ParamSched) Active(epc int) bool {
+	return epc >= ps.StEpc
+}
+
+// Val returns the scheduled value at given epoch, which is only
+// meaningful if Active
+func (ps *ParamSched) Val(epc int) float64 {
+	if epc >= ps.EdEpc || ps.EdEpc <= ps.StEpc {
+		return ps.End
+	}
+	if epc <= ps.StEpc {
+		return ps.Start
+	}
+	p := float64(epc-ps.StEpc) / float64(ps.EdEpc-ps.StEpc)
+	if ps.Exp && ps.Start*ps.End > 0 {
+		return ps.Start * math.Pow(ps.End/ps.Start, p)
+	}
+	return ps.Start + p*(ps.End-ps.Start)
+}
+
+// Selector returns the selector, defaulting to the type element of the Path
+func (ps *ParamSched) Selector() string {
+	if ps.Sel != "" {
+		return ps.Sel
+	}
+	if di := strings.Index(ps.Path, "."); di > 0 {
+		return ps.Path[:di]
+	}
+	return ps.Path
+}
+
+// Sheet returns the name of the params sheet for this schedule: Sim for Sim
+// params, Network otherwise
+func (ps *ParamSched) Sheet() string {
+	if strings.HasPrefix(ps.Path, "Sim.") {
+		return "Sim"
+	}
+	return "Network"
+}
+
+func (ps *ParamSched) String() string {
+	str := ps.Path
+	if ps.Sel != "" {
+		str = ps.Sel + " " + str
+	}
+	str += fmt.Sprintf(": %v->%v over %v-%v", ps.Start, ps.End, ps.StEpc, ps.EdEpc)
+	if ps.Exp {
+		str += " exp"
+	}
+	return str
+}
+
+// ParseParamSched parses a schedule of the form:
+// [Sel ]Path: Start->End over StEpc-EdEpc [exp]
+// e.g., "Layer.Inhib.Layer.Gi: 2.0->1.6 over 0-20" or
+// "#Output Layer.Inhib.Layer.Gi: 1.8->1.4 over 5-25 exp".
+// The arrow can also be written as → and the epoch range dash as –.
+func ParseParamSched(spec string) (ParamSched, error) {
+	ps := ParamSched{}
+	spec = strings.NewReplacer("→", "->", "–", "-", "—", "-").Replace(strings.TrimSpace(spec))
+	errf := func(msg string) (ParamSched, error) {
+		err := fmt.Errorf("ParseParamSched: %v in: %v -- format is: [Sel ]Path: Start->End over StEpc-EdEpc [exp]", msg, spec)
+		log.Println(err)
+		return ps, err
+	}
+	ci := strings.Index(spec, ":")
+	if ci < 0 {
+		return errf("no :")
+	}
+	lhs := strings.Fields(spec[:ci])
+	switch len(lhs) {
+	case 1:
+		ps.Path = lhs[0]
+	case 2:
+		ps.Sel = lhs[0]
+		ps.Path = lhs[1]
+	default:
+		return errf("expected [Sel ]Path before :")
+	}
+	flds := strings.Fields(spec[ci+1:])
+	if len(flds) < 3 || flds[1] != "over" {
+		return errf("expected Start->End over StEpc-EdEpc")
+	}
+	if len(flds) == 4 {
+		if flds[3] != "exp" {
+			return errf("unknown option: " + flds[3])
+		}
+		ps.Exp = true
+	} else if len(flds) > 4 {
+		return errf("too many fields")
+	}
+	vals := strings.Split(flds[0], "->")
+	epcs := strings.Split(flds[2], "-")
+	if len(vals) != 2 || len(epcs) != 2 {
+		return errf("expected Start->End over StEpc-EdEpc")
+	}
+	var err error
+	if ps.Start, err = strconv.ParseFloat(vals[0], 64); err != nil {
+		return errf(err.Error())
+	}
+	if ps.End, err = strconv.ParseFloat(vals[1], 64); err != nil {
+		return errf(err.Error())
+	}
+	if ps.StEpc, err = strconv.Atoi(epcs[0]); err != nil {
+		return errf(err.Error())
+	}
+	if ps.EdEpc, err = strconv.Atoi(epcs[1]); err != nil {
+		return errf(err.Error())
+	}
+	return ps, nil
+}
+
+// ParamScheds is a list of parameter schedules, applied at epoch boundaries
+// on top of the regular params.  Later schedules take precedence over earlier
+// ones for the same selector and path.
+type ParamScheds []ParamSched
+
+// AddString adds schedules from a list of specs separated by ; -- see ParseParamSched
+func (pss *ParamScheds) AddString(specs string) error {
+	for _, spec := range strings.Split(specs, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		ps, err := ParseParamSched(spec)
+		if err != nil {
+			return err
+		}
+		*pss = append(*pss, ps)
+	}
+	return nil
+}
+
+// Sheet returns a params.Sheet with the values for all schedules for given
+// sheet name (Network or Sim) that are active at given epoch, or nil if none.
+func (pss ParamScheds) Sheet(sheet string, epc int) *params.Sheet {
+	var sh params.Sheet
+	for i := range pss {
+		ps := &pss[i]
+		if ps.Sheet() != sheet || !ps.Active(epc) {
+			continue
+		}
+		sh = append(sh, &params.Sel{Sel: ps.Selector(), Desc: "param schedule: " + ps.String(),
+			Params: params.Params{ps.Path: strconv.FormatFloat(ps.Val(epc), 'g', 6, 64)}})
+	}
+	if len(sh) == 0 {
+		return nil
+	}
+	return &sh
+}
+
+// Apply applies the Network schedules active at given epoch to the network.
+// Returns true if any were applied.  Sim schedules must be applied by the sim,
+// using Sheet("Sim", epc).
+func (pss ParamScheds) Apply(nt *Network, epc int, setMsg bool) (bool, error) {
+	sh := pss.Sheet("Network", epc)
+	if sh == nil {
+		return false, nil
+	}
+	return nt.ApplyParams(sh, setMsg)
+}
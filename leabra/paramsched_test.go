@@ -0,0 +1,39 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParamSched(t *testing.T) {
+	var pss ParamScheds
+	if err := pss.AddString("Layer.Inhib.Layer.Gi: 2.0→1.6 over 0–20; #Output Layer.Inhib.Layer.Gi: 1.8->1.4 over 5-25 exp"); err != nil {
+		t.Fatal(err)
+	}
+	gi := &pss[0]
+	for _, ev := range []struct {
+		epc int
+		val float64
+	}{{0, 2.0}, {10, 1.8}, {20, 1.6}, {30, 1.6}} {
+		if v := gi.Val(ev.epc); math.Abs(v-ev.val) > 1e-9 {
+			t.Errorf("epoch %v: got %v, want %v\n", ev.epc, v, ev.val)
+		}
+	}
+	out := &pss[1]
+	if out.Sel != "#Output" || !out.Exp || out.Active(4) {
+		t.Errorf("parse of second schedule wrong: %v\n", out.String())
+	}
+	if v := out.Val(15); math.Abs(v-math.Sqrt(1.8*1.4)) > 1e-9 {
+		t.Errorf("exp midpoint: got %v, want %v\n", v, math.Sqrt(1.8*1.4))
+	}
+	if sh := pss.Sheet("Network", 2); sh == nil || len(*sh) != 1 {
+		t.Errorf("expected one active schedule at epoch 2\n")
+	}
+	if _, err := ParseParamSched("Layer.Inhib.Layer.Gi 2.0->1.6"); err == nil {
+		t.Errorf("expected error for missing :\n")
+	}
+}
@@ -0,0 +1,407 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/params"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+// ParamSearchVar is one parameter varied in a ParamSearch, either over an
+// explicit list of values, or over a Min..Max range that is divided into N
+// grid points or sampled at random.
+type ParamSearchVar struct {
+	Sel  string   `desc:"params selector to apply value to, e.g., Layer, .Back, #Output, Sim -- defaults to the first element of Path"`
+	Path string   `desc:"param path, e.g., Layer.Inhib.Layer.Gi, Layer.Inhib.Layer.GiOscMin, Prjn.WtScale.Rel, Sim.MaxEpcs"`
+	Vals []string `desc:"explicit list of values -- if non-empty, Min, Max, N are not used"`
+	Min  float64  `desc:"minimum of range"`
+	Max  float64  `desc:"maximum of range"`
+	N    int      `desc:"number of grid points from Min to Max inclusive"`
+	Log  bool     `desc:"space grid points and random samples logarithmically between Min and Max"`
+}
+
+// Name returns the name used for the results column for this var
+func (sv *ParamSearchVar) Name() string {
+	if sv.Sel == "" || sv.Sel == sv.SheetType() {
+		return sv.Path
+	}
+	return sv.Sel + " " + sv.Path
+}
+
+// SheetType returns the type element of the Path (Layer, Prjn, Sim)
+func (sv *ParamSearchVar) SheetType() string {
+	if di := strings.Index(sv.Path, "."); di > 0 {
+		return sv.Path[:di]
+	}
+	return sv.Path
+}
+
+// Sheet returns the name of the params sheet for this var: Sim for Sim params,
+// Network otherwise
+func (sv *ParamSearchVar) Sheet() string {
+	if sv.SheetType() == "Sim" {
+		return "Sim"
+	}
+	return "Network"
+}
+
+// Selector returns the selector, defaulting to the SheetType
+func (sv *ParamSearchVar) Selector() string {
+	if sv.Sel == "" {
+		return sv.SheetType()
+	}
+	return sv.Sel
+}
+
+// rangeVal returns the value at proportion p (0-1) between Min and Max
+func (sv *ParamSearchVar) rangeVal(p float64) float64 {
+	if sv.Log && sv.Min > 0 && sv.Max > 0 {
+		lmin := math.Log(sv.Min)
+		return math.Exp(lmin + p*(math.Log(sv.Max)-lmin))
+	}
+	return sv.Min + p*(sv.Max-sv.Min)
+}
+
+// GridVals returns the list of values for a grid search
+func (sv *ParamSearchVar) GridVals() []string {
+	if len(sv.Vals) > 0 {
+		return sv.Vals
+	}
+	if sv.N <= 1 {
+		return []string{ParamValString(sv.Min)}
+	}
+	vals := make([]string, sv.N)
+	for i := range vals {
+		vals[i] = ParamValString(sv.rangeVal(float64(i) / float64(sv.N-1)))
+	}
+	return vals
+}
+
+// RndVal returns a random value: a random item if Vals is set, otherwise a
+// uniform (or log-uniform) sample between Min and Max
+func (sv *ParamSearchVar) RndVal(rnd *rand.Rand) string {
+	if len(sv.Vals) > 0 {
+		return sv.Vals[rnd.Intn(len(sv.Vals))]
+	}
+	return ParamValString(sv.rangeVal(rnd.Float64()))
+}
+
+// ParamValString returns a compact string for a param value
+func ParamValString(val float64) string {
+	return strconv.FormatFloat(val, 'g', 6, 64)
+}
+
+// ParseParamSearchVar parses a var spec of the form:
+// [Sel ]Path=Vals where Vals is either a comma-separated list of values
+// (e.g., 1.2,1.4,1.6) or a range Min..Max/N, optionally prefixed by log:
+// e.g., "#Output Layer.Inhib.Layer.Gi=1.2..1.8/4" or "Prjn.Learn.Lrate=log:0.001..0.1/5"
+func ParseParamSearchVar(spec string) (ParamSearchVar, error) {
+	sv := ParamSearchVar{}
+	spec = strings.TrimSpace(spec)
+	ei := strings.Index(spec, "=")
+	if ei < 0 {
+		err := fmt.Errorf("ParseParamSearchVar: no = in spec: %v", spec)
+		log.Println(err)
+		return sv, err
+	}
+	lhs := strings.Fields(spec[:ei])
+	switch len(lhs) {
+	case 1:
+		sv.Path = lhs[0]
+	case 2:
+		sv.Sel = lhs[0]
+		sv.Path = lhs[1]
+	default:
+		err := fmt.Errorf("ParseParamSearchVar: expected [Sel ]Path before = in spec: %v", spec)
+		log.Println(err)
+		return sv, err
+	}
+	rhs := strings.TrimSpace(spec[ei+1:])
+	if strings.HasPrefix(rhs, "log:") {
+		sv.Log = true
+		rhs = rhs[4:]
+	}
+	if ri := strings.Index(rhs, ".."); ri >= 0 {
+		var err error
+		mx := rhs[ri+2:]
+		sv.N = 1
+		if si := strings.Index(mx, "/"); si >= 0 {
+			sv.N, err = strconv.Atoi(mx[si+1:])
+			mx = mx[:si]
+		}
+		if err == nil {
+			sv.Min, err = strconv.ParseFloat(rhs[:ri], 64)
+		}
+		if err == nil {
+			sv.Max, err = strconv.ParseFloat(mx, 64)
+		}
+		if err != nil {
+			err = fmt.Errorf("ParseParamSearchVar: range must be Min..Max/N in spec: %v: %v", spec, err)
+			log.Println(err)
+			return sv, err
+		}
+		return sv, nil
+	}
+	for _, v := range strings.Split(rhs, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			sv.Vals = append(sv.Vals, v)
+		}
+	}
+	if len(sv.Vals) == 0 {
+		err := fmt.Errorf("ParseParamSearchVar: no values in spec: %v", spec)
+		log.Println(err)
+		return sv, err
+	}
+	return sv, nil
+}
+
+// ParamSearchConfig is one configuration of values, one per ParamSearch var
+type ParamSearchConfig struct {
+	Name string   `desc:"name of the configuration, used as the params.Set name"`
+	Vals []string `desc:"values for each var, in order"`
+}
+
+// ParamSearch sweeps a set of params over a grid or random samples, each
+// configuration being run with NSeeds different seeds, and records the
+// resulting stats (e.g., FirstZero, PctCor) for each run, which can then be
+// aggregated per configuration.  The sim drives the runs: for each of the
+// Configs it applies the Set for that config on top of its Base params,
+// and then calls AddResult for each completed run.
+type ParamSearch struct {
+	Vars     []ParamSearchVar    `desc:"the params to vary"`
+	Random   bool                `desc:"if true, sample NSamples random configurations instead of the full grid"`
+	NSamples int                 `desc:"number of random configurations, if Random"`
+	NSeeds   int                 `desc:"number of runs (with different random seeds) per configuration"`
+	RndSeed  int64               `desc:"random seed for sampling configurations"`
+	Stats    []string            `desc:"names of stats recorded per run, e.g., FirstZero, PctCor"`
+	Configs  []ParamSearchConfig `desc:"configurations to run, generated by Generate"`
+	Results  *etable.Table       `view:"no-inline" desc:"one row per run: config, seed, var values and stats"`
+}
+
+// Defaults sets default values
+func (ps *ParamSearch) Defaults() {
+	ps.NSeeds = 5
+	ps.NSamples = 20
+	ps.Stats = []string{"FirstZero", "PctCor"}
+}
+
+// AddVarsString adds vars from a list of specs separated by ; -- see ParseParamSearchVar
+func (ps *ParamSearch) AddVarsString(specs string) error {
+	for _, spec := range strings.Split(specs, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		sv, err := ParseParamSearchVar(spec)
+		if err != nil {
+			return err
+		}
+		ps.Vars = append(ps.Vars, sv)
+	}
+	return nil
+}
+
+// Generate generates the Configs, as the full grid (all combinations of
+// GridVals) or NSamples random samples, and configures the Results table.
+func (ps *ParamSearch) Generate() []ParamSearchConfig {
+	ps.Configs = nil
+	nv := len(ps.Vars)
+	if ps.Random {
+		rnd := rand.New(rand.NewSource(ps.RndSeed))
+		for ci := 0; ci < ps.NSamples; ci++ {
+			vals := make([]string, nv)
+			for vi := range ps.Vars {
+				vals[vi] = ps.Vars[vi].RndVal(rnd)
+			}
+			ps.Configs = append(ps.Configs, ParamSearchConfig{Name: fmt.Sprintf("Search_%03d", ci), Vals: vals})
+		}
+	} else {
+		grids := make([][]string, nv)
+		idx := make([]int, nv)
+		ncfg := 1
+		for vi := range ps.Vars {
+			grids[vi] = ps.Vars[vi].GridVals()
+			ncfg *= len(grids[vi])
+		}
+		for ci := 0; ci < ncfg; ci++ {
+			vals := make([]string, nv)
+			for vi := range grids {
+				vals[vi] = grids[vi][idx[vi]]
+			}
+			ps.Configs = append(ps.Configs, ParamSearchConfig{Name: fmt.Sprintf("Search_%03d", ci), Vals: vals})
+			for vi := nv - 1; vi >= 0; vi-- { // last var varies fastest
+				idx[vi]++
+				if idx[vi] < len(grids[vi]) {
+					break
+				}
+				idx[vi] = 0
+			}
+		}
+	}
+	ps.ConfigResults()
+	return ps.Configs
+}
+
+// Set returns the params.Set for given config index, with Network and Sim
+// sheets as needed for the vars, to be applied on top of Base params.
+func (ps *ParamSearch) Set(ci int) *params.Set {
+	cfg := &ps.Configs[ci]
+	pset := &params.Set{Name: cfg.Name, Desc: ps.ConfigDesc(ci), Sheets: params.Sheets{}}
+	for vi := range ps.Vars {
+		sv := &ps.Vars[vi]
+		shnm := sv.Sheet()
+		sh, ok := pset.Sheets[shnm]
+		if !ok {
+			sh = &params.Sheet{}
+			pset.Sheets[shnm] = sh
+		}
+		*sh = append(*sh, &params.Sel{Sel: sv.Selector(), Desc: "param search",
+			Params: params.Params{sv.Path: cfg.Vals[vi]}})
+	}
+	return pset
+}
+
+// ConfigDesc returns a description of the values for given config index
+func (ps *ParamSearch) ConfigDesc(ci int) string {
+	cfg := &ps.Configs[ci]
+	strs := make([]string, len(ps.Vars))
+	for vi := range ps.Vars {
+		strs[vi] = ps.Vars[vi].Name() + "=" + cfg.Vals[vi]
+	}
+	return strings.Join(strs, " ")
+}
+
+// ConfigResults configures the Results table
+func (ps *ParamSearch) ConfigResults() {
+	if ps.Results == nil {
+		ps.Results = &etable.Table{}
+	}
+	dt := ps.Results
+	dt.SetMetaData("name", "ParamSearch")
+	dt.SetMetaData("desc", "Parameter search results, one row per run")
+	sch := etable.Schema{
+		{"Config", etensor.STRING, nil, nil},
+		{"Seed", etensor.INT64, nil, nil},
+	}
+	for vi := range ps.Vars {
+		sch = append(sch, etable.Column{ps.Vars[vi].Name(), etensor.STRING, nil, nil})
+	}
+	for _, st := range ps.Stats {
+		sch = append(sch, etable.Column{st, etensor.FLOAT64, nil, nil})
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+// AddResult adds the stats for one run of given config index and seed
+func (ps *ParamSearch) AddResult(ci int, seed int, stats map[string]float64) {
+	dt := ps.Results
+	cfg := &ps.Configs[ci]
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+	dt.SetCellString("Config", row, cfg.Name)
+	dt.SetCellFloat("Seed", row, float64(seed))
+	for vi := range ps.Vars {
+		dt.SetCellString(ps.Vars[vi].Name(), row, cfg.Vals[vi])
+	}
+	for _, st := range ps.Stats {
+		dt.SetCellFloat(st, row, stats[st])
+	}
+}
+
+// Summary returns a table with one row per config, with the number of runs
+// and the mean and standard error of the mean of each stat across runs.
+// Note that stats such as FirstZero that use -1 for "never" are included
+// as-is, so a PctCor-type stat should be used to see how many runs failed.
+func (ps *ParamSearch) Summary() *etable.Table {
+	dt := &etable.Table{}
+	dt.SetMetaData("name", "ParamSearchSummary")
+	dt.SetMetaData("desc", "Parameter search results, aggregated per configuration")
+	sch := etable.Schema{
+		{"Config", etensor.STRING, nil, nil},
+		{"N", etensor.INT64, nil, nil},
+	}
+	for vi := range ps.Vars {
+		sch = append(sch, etable.Column{ps.Vars[vi].Name(), etensor.STRING, nil, nil})
+	}
+	for _, st := range ps.Stats {
+		sch = append(sch, etable.Column{st + ":Mean", etensor.FLOAT64, nil, nil})
+		sch = append(sch, etable.Column{st + ":SEM", etensor.FLOAT64, nil, nil})
+	}
+	dt.SetFromSchema(sch, len(ps.Configs))
+	rt := ps.Results
+	for ci := range ps.Configs {
+		cfg := &ps.Configs[ci]
+		dt.SetCellString("Config", ci, cfg.Name)
+		for vi := range ps.Vars {
+			dt.SetCellString(ps.Vars[vi].Name(), ci, cfg.Vals[vi])
+		}
+		var rows []int
+		if rt != nil {
+			for ri := 0; ri < rt.Rows; ri++ {
+				if rt.CellString("Config", ri) == cfg.Name {
+					rows = append(rows, ri)
+				}
+			}
+		}
+		n := float64(len(rows))
+		dt.SetCellFloat("N", ci, n)
+		if n == 0 {
+			continue
+		}
+		for _, st := range ps.Stats {
+			sum, ssq := 0.0, 0.0
+			for _, ri := range rows {
+				v := rt.CellFloat(st, ri)
+				sum += v
+				ssq += v * v
+			}
+			mean := sum / n
+			sem := 0.0
+			if n > 1 {
+				vr := (ssq - n*mean*mean) / (n - 1)
+				if vr > 0 {
+					sem = math.Sqrt(vr / n)
+				}
+			}
+			dt.SetCellFloat(st+":Mean", ci, mean)
+			dt.SetCellFloat(st+":SEM", ci, sem)
+		}
+	}
+	return dt
+}
+
+// SaveResults saves the per-run Results and the per-config Summary to
+// tab-separated files: filename and filename with _summary inserted before
+// the extension.
+func (ps *ParamSearch) SaveResults(filename gi.FileName) error {
+	if ps.Results == nil {
+		err := errors.New("ParamSearch: no results to save")
+		log.Println(err)
+		return err
+	}
+	fnm := string(filename)
+	if err := ps.Results.SaveCSV(gi.FileName(fnm), etable.Tab, true); err != nil {
+		log.Println(err)
+		return err
+	}
+	snm := fnm + "_summary"
+	if di := strings.LastIndex(fnm, "."); di > 0 {
+		snm = fnm[:di] + "_summary" + fnm[di:]
+	}
+	err := ps.Summary().SaveCSV(gi.FileName(snm), etable.Tab, true)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
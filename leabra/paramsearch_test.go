@@ -0,0 +1,41 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+)
+
+func TestParamSearchGrid(t *testing.T) {
+	ps := ParamSearch{}
+	ps.Defaults()
+	if err := ps.AddVarsString("#Output Layer.Inhib.Layer.Gi=1.2..1.8/4; Prjn.WtScale.Rel=0.1,0.2"); err != nil {
+		t.Fatal(err)
+	}
+	if ps.Vars[0].Sel != "#Output" || ps.Vars[1].Selector() != "Prjn" {
+		t.Errorf("selectors not parsed: %v %v\n", ps.Vars[0].Sel, ps.Vars[1].Selector())
+	}
+	cfgs := ps.Generate()
+	if len(cfgs) != 8 {
+		t.Fatalf("grid: got %v configs, want 8\n", len(cfgs))
+	}
+	if cfgs[1].Vals[0] != "1.2" || cfgs[1].Vals[1] != "0.2" || cfgs[7].Vals[0] != "1.8" {
+		t.Errorf("grid order wrong: %v %v\n", cfgs[1].Vals, cfgs[7].Vals)
+	}
+	pset := ps.Set(1)
+	if sh, ok := pset.Sheets["Network"]; !ok || len(*sh) != 2 {
+		t.Errorf("Set: expected Network sheet with 2 sels\n")
+	}
+	for seed := 0; seed < 2; seed++ {
+		ps.AddResult(1, seed, map[string]float64{"FirstZero": float64(10 + 2*seed), "PctCor": 1})
+	}
+	sum := ps.Summary()
+	if m := sum.CellFloat("FirstZero:Mean", 1); m != 11 {
+		t.Errorf("summary FirstZero mean: %v, want 11\n", m)
+	}
+	if n := sum.CellFloat("N", 0); n != 0 {
+		t.Errorf("summary N for config without results: %v, want 0\n", n)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/emer/emergent/erand"
+)
+
+// LayerParams is a snapshot of all the params of a Layer
+type LayerParams struct {
+	Act    ActParams       `desc:"activation parameters"`
+	Inhib  InhibParams     `desc:"inhibition parameters"`
+	Learn  LearnNeurParams `desc:"learning parameters at the neuron level"`
+	LaySim LaySimParams    `desc:"layer similarity parameters"`
+}
+
+// SnapshotParams returns a snapshot of all the current param values of the
+// layer (not the structural settings such as Off) -- see RestoreParams
+func (ly *Layer) SnapshotParams() *LayerParams {
+	return &LayerParams{Act: ly.Act, Inhib: ly.Inhib, Learn: ly.Learn, LaySim: ly.LaySim}
+}
+
+// RestoreParams restores all the param values from given snapshot
+func (ly *Layer) RestoreParams(lp *LayerParams) {
+	ly.Act = lp.Act
+	ly.Inhib = lp.Inhib
+	ly.Learn = lp.Learn
+	ly.LaySim = lp.LaySim
+}
+
+// PrjnParams is a snapshot of all the params of a Prjn
+type PrjnParams struct {
+	WtInit  erand.RndParams `desc:"initial random weight distribution"`
+	WtScale WtScaleParams   `desc:"weight scaling parameters"`
+	Learn   LearnSynParams  `desc:"synaptic-level learning parameters"`
+	SynDep  SynDepParams    `desc:"synaptic depression parameters"`
+}
+
+// SnapshotParams returns a snapshot of all the current param values of the
+// projection (not the structural settings such as Off) -- see RestoreParams
+func (pj *Prjn) SnapshotParams() *PrjnParams {
+	return &PrjnParams{WtInit: pj.WtInit, WtScale: pj.WtScale, Learn: pj.Learn, SynDep: pj.SynDep}
+}
+
+// RestoreParams restores all the param values from given snapshot
+func (pj *Prjn) RestoreParams(pp *PrjnParams) {
+	pj.WtInit = pp.WtInit
+	pj.WtScale = pp.WtScale
+	pj.Learn = pp.Learn
+	pj.SynDep = pp.SynDep
+}
+
+// ParamsSnapshot is a snapshot of all the params of all the layers and
+// projections in a network, by name -- a structured superset of Defaults,
+// e.g., to temporarily apply extreme params (e.g., for sleep) and reliably
+// return to the tuned state, including any params that the temporary params
+// set that the regular params do not.  Structural settings such as Off
+// (e.g., from lesions) are not included.
+type ParamsSnapshot struct {
+	Layers map[string]*LayerParams `desc:"params for each layer, by name"`
+	Prjns  map[string]*PrjnParams  `desc:"params for each projection, by name"`
+}
+
+// SnapshotParams returns a snapshot of all the current param values of all
+// the layers and projections -- see RestoreParams
+func (nt *Network) SnapshotParams() *ParamsSnapshot {
+	ps := &ParamsSnapshot{Layers: make(map[string]*LayerParams), Prjns: make(map[string]*PrjnParams)}
+	for _, ly := range nt.Layers {
+		lly := ly.(LeabraLayer).AsLeabra()
+		ps.Layers[lly.Nm] = lly.SnapshotParams()
+		for _, p := range lly.RcvPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			ps.Prjns[pj.Name()] = pj.SnapshotParams()
+		}
+	}
+	return ps
+}
+
+// RestoreParams restores all the param values from given snapshot, for the
+// layers and projections in the snapshot.  Returns an error listing any in
+// the network that are not in the snapshot, which are left as is.
+func (nt *Network) RestoreParams(ps *ParamsSnapshot) error {
+	var missing []string
+	for _, ly := range nt.Layers {
+		lly := ly.(LeabraLayer).AsLeabra()
+		if lp, has := ps.Layers[lly.Nm]; has {
+			lly.RestoreParams(lp)
+		} else {
+			missing = append(missing, lly.Nm)
+		}
+		for _, p := range lly.RcvPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			if pp, has := ps.Prjns[pj.Name()]; has {
+				pj.RestoreParams(pp)
+			} else {
+				missing = append(missing, pj.Name())
+			}
+		}
+	}
+	if len(missing) > 0 {
+		err := fmt.Errorf("RestoreParams: layers / projections not in snapshot: %v", strings.Join(missing, ", "))
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// Changed returns the names of the layers and projections in the network
+// whose params differ from those in the snapshot, sorted, e.g., to check
+// that params have been restored
+func (ps *ParamsSnapshot) Changed(nt *Network) []string {
+	var chg []string
+	for _, ly := range nt.Layers {
+		lly := ly.(LeabraLayer).AsLeabra()
+		if lp, has := ps.Layers[lly.Nm]; !has || !reflect.DeepEqual(lp, lly.SnapshotParams()) {
+			chg = append(chg, lly.Nm)
+		}
+		for _, p := range lly.RcvPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			if pp, has := ps.Prjns[pj.Name()]; !has || !reflect.DeepEqual(pp, pj.SnapshotParams()) {
+				chg = append(chg, pj.Name())
+			}
+		}
+	}
+	sort.Strings(chg)
+	return chg
+}
+
+// ResetDefaults resets all the params of all the layers and projections to
+// their default values, keeping the structural settings (e.g., Off) and all
+// the state, unlike Defaults which resets everything -- returns a snapshot of
+// the params before the reset, so they can be restored with RestoreParams
+func (nt *Network) ResetDefaults() *ParamsSnapshot {
+	ps := nt.SnapshotParams()
+	for _, ly := range nt.Layers {
+		lly := ly.(LeabraLayer).AsLeabra()
+		dl := &Layer{}
+		dl.Defaults()
+		lly.RestoreParams(dl.SnapshotParams())
+		for _, p := range lly.RcvPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			dp := &Prjn{}
+			dp.Defaults()
+			pj.RestoreParams(dp.SnapshotParams())
+		}
+	}
+	nt.UpdateParams()
+	return ps
+}
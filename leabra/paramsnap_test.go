@@ -0,0 +1,57 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/params"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestParamsSnapshot(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "SnapNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.ApplyParams(&params.Sheet{
+		{Sel: "Layer", Params: params.Params{"Layer.Inhib.Layer.Gi": "1.9"}},
+	}, false)
+	snap := net.SnapshotParams()
+
+	// extreme "sleep" params, including one the regular params do not set
+	net.ApplyParams(&params.Sheet{
+		{Sel: "Layer", Params: params.Params{"Layer.Inhib.Layer.Gi": "3", "Layer.Inhib.Layer.FB": "2"}},
+		{Sel: "Prjn", Params: params.Params{"Prjn.WtScale.Rel": "0.1"}},
+	}, false)
+	hidLay.(*Layer).Off = true // structural settings are not restored
+	if chg := snap.Changed(net); len(chg) != 3 {
+		t.Errorf("Changed: %v, want all 3\n", chg)
+	}
+	if err := net.RestoreParams(snap); err != nil {
+		t.Error(err)
+	}
+	hid := hidLay.(*Layer)
+	if hid.Inhib.Layer.Gi != 1.9 || hid.Inhib.Layer.FB != 1 || !hid.Off {
+		t.Errorf("RestoreParams wrong: Gi %v FB %v Off %v\n", hid.Inhib.Layer.Gi, hid.Inhib.Layer.FB, hid.Off)
+	}
+	if pj := net.PrjnByName("InputToHidden"); pj.WtScale.Rel != 1 {
+		t.Errorf("prjn params not restored: %v\n", pj.WtScale.Rel)
+	}
+	if chg := snap.Changed(net); len(chg) != 0 {
+		t.Errorf("Changed after restore: %v\n", chg)
+	}
+	prv := net.ResetDefaults()
+	if hid.Inhib.Layer.Gi != 1.8 {
+		t.Errorf("ResetDefaults: Gi %v, want 1.8\n", hid.Inhib.Layer.Gi)
+	}
+	net.RestoreParams(prv)
+	if hid.Inhib.Layer.Gi != 1.9 {
+		t.Errorf("restore after ResetDefaults: Gi %v\n", hid.Inhib.Layer.Gi)
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// CueLevel is one level of degradation of the input cues in PatComp
+type CueLevel struct {
+	Frac  float32 `min:"0" max:"1" desc:"fraction of the active units of each input pattern that are removed (set to 0), chosen at random for each trial"`
+	Noise float32 `min:"0" desc:"standard deviation of gaussian noise added to all the units of each input pattern, clipped to 0-1"`
+}
+
+// String returns the level as Frac:Noise
+func (cl *CueLevel) String() string {
+	return fmt.Sprintf("%g:%g", cl.Frac, cl.Noise)
+}
+
+// PatComp is a pattern-completion test: each test item is presented with
+// its input patterns degraded (see CueLevel) at each of a set of levels,
+// and the completion of the output is scored, with the results summarized
+// for each level in the Results log (see Log).
+type PatComp struct {
+	Levels  []CueLevel    `desc:"levels of degradation of the input cues, each tested on all the items"`
+	Layers  []string      `desc:"names of the input layers whose patterns are degraded -- the other layers get their full patterns"`
+	NReps   int           `desc:"number of repetitions of each item at each level, with different random degradations"`
+	Results *etable.Table `view:"no-inline" desc:"one row per level per Log: Run, Epoch, Level, Frac, Noise, N, PctCor, SSE, CosDiff"`
+	n       []int
+	nCor    []int
+	sse     []float64
+	cos     []float64
+}
+
+func (pc *PatComp) Defaults() {
+	pc.Layers = []string{"Input"}
+	pc.NReps = 1
+}
+
+// AddString adds levels from specs separated by ; each as Frac[:Noise],
+// e.g., "0.25; 0.5; 0.5:0.1"
+func (pc *PatComp) AddString(specs string) error {
+	for _, spec := range strings.Split(specs, ";") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		var cl CueLevel
+		parts := strings.Split(spec, ":")
+		var err error
+		if len(parts) > 2 {
+			err = fmt.Errorf("too many fields")
+		}
+		for i, p := range parts {
+			if err != nil {
+				break
+			}
+			var v float64
+			v, err = strconv.ParseFloat(strings.TrimSpace(p), 32)
+			if i == 0 {
+				cl.Frac = float32(v)
+			} else {
+				cl.Noise = float32(v)
+			}
+		}
+		if err == nil && (cl.Frac < 0 || cl.Frac > 1 || cl.Noise < 0) {
+			err = fmt.Errorf("Frac must be 0-1 and Noise >= 0")
+		}
+		if err != nil {
+			err = fmt.Errorf("PatComp: expected Frac[:Noise] in: %v: %v", spec, err)
+			log.Println(err)
+			return err
+		}
+		pc.Levels = append(pc.Levels, cl)
+	}
+	return nil
+}
+
+// IsCue returns true if the layer with given name is one of the Layers
+// whose patterns are degraded
+func (pc *PatComp) IsCue(lay string) bool {
+	for _, l := range pc.Layers {
+		if l == lay {
+			return true
+		}
+	}
+	return false
+}
+
+// Degrade returns a copy of given input pattern degraded according to given
+// level, using given random number stream (global generator if nil)
+func (pc *PatComp) Degrade(lvl int, pat etensor.Tensor, rnd *rand.Rand) *etensor.Float32 {
+	cl := &pc.Levels[lvl]
+	dp := etensor.NewFloat32(pat.Shapes(), nil, nil)
+	var act []int
+	for i := range dp.Values {
+		dp.Values[i] = float32(pat.FloatVal1D(i))
+		if dp.Values[i] > 0 {
+			act = append(act, i)
+		}
+	}
+	perm := rand.Perm
+	nrm := rand.NormFloat64
+	if rnd != nil {
+		perm = rnd.Perm
+		nrm = rnd.NormFloat64
+	}
+	nrem := int(math.Round(float64(cl.Frac) * float64(len(act))))
+	for _, pi := range perm(len(act))[:nrem] {
+		dp.Values[act[pi]] = 0
+	}
+	if cl.Noise > 0 {
+		for i, v := range dp.Values {
+			v += cl.Noise * float32(nrm())
+			if v < 0 {
+				v = 0
+			} else if v > 1 {
+				v = 1
+			}
+			dp.Values[i] = v
+		}
+	}
+	return dp
+}
+
+// Init resets the accumulated scores for all the levels
+func (pc *PatComp) Init() {
+	nl := len(pc.Levels)
+	pc.n = make([]int, nl)
+	pc.nCor = make([]int, nl)
+	pc.sse = make([]float64, nl)
+	pc.cos = make([]float64, nl)
+}
+
+// AddTrial accumulates the scores of the output for a trial at given level:
+// the sum squared error (correct if 0) and cosine difference
+func (pc *PatComp) AddTrial(lvl int, sse, cosDiff float64) {
+	if len(pc.n) != len(pc.Levels) {
+		pc.Init()
+	}
+	pc.n[lvl]++
+	pc.sse[lvl] += sse
+	pc.cos[lvl] += cosDiff
+	if sse == 0 {
+		pc.nCor[lvl]++
+	}
+}
+
+// ConfigResults configures the Results table
+func (pc *PatComp) ConfigResults() {
+	if pc.Results == nil {
+		pc.Results = &etable.Table{}
+	}
+	dt := pc.Results
+	dt.SetMetaData("name", "PatCompLog")
+	dt.SetMetaData("desc", "pattern completion with degraded cues, by level")
+	dt.SetFromSchema(etable.Schema{
+		{"Run", etensor.INT64, nil, nil},
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Level", etensor.INT64, nil, nil},
+		{"Frac", etensor.FLOAT64, nil, nil},
+		{"Noise", etensor.FLOAT64, nil, nil},
+		{"N", etensor.INT64, nil, nil},
+		{"PctCor", etensor.FLOAT64, nil, nil},
+		{"SSE", etensor.FLOAT64, nil, nil},
+		{"CosDiff", etensor.FLOAT64, nil, nil},
+	}, 0)
+}
+
+// Log adds a row to the Results for each level with the scores accumulated
+// since the last Init, averaged over trials, and calls Init -- returns the
+// index of the first row added
+func (pc *PatComp) Log(run, epoch int) int {
+	if pc.Results == nil || pc.Results.ColByName("PctCor") == nil {
+		pc.ConfigResults()
+	}
+	if len(pc.n) != len(pc.Levels) {
+		pc.Init()
+	}
+	dt := pc.Results
+	st := dt.Rows
+	dt.SetNumRows(st + len(pc.Levels))
+	for li := range pc.Levels {
+		cl := &pc.Levels[li]
+		row := st + li
+		n := math.Max(float64(pc.n[li]), 1)
+		dt.SetCellFloat("Run", row, float64(run))
+		dt.SetCellFloat("Epoch", row, float64(epoch))
+		dt.SetCellFloat("Level", row, float64(li))
+		dt.SetCellFloat("Frac", row, float64(cl.Frac))
+		dt.SetCellFloat("Noise", row, float64(cl.Noise))
+		dt.SetCellFloat("N", row, float64(pc.n[li]))
+		dt.SetCellFloat("PctCor", row, float64(pc.nCor[li])/n)
+		dt.SetCellFloat("SSE", row, pc.sse[li]/n)
+		dt.SetCellFloat("CosDiff", row, pc.cos[li]/n)
+	}
+	pc.Init()
+	return st
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/emer/etable/etensor"
+)
+
+func TestPatComp(t *testing.T) {
+	pc := &PatComp{}
+	pc.Defaults()
+	if err := pc.AddString("0; 0.5; 0.5:0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(pc.Levels) != 3 || pc.Levels[2].Noise != 0.1 {
+		t.Fatalf("levels wrong: %v\n", pc.Levels)
+	}
+	if err := pc.AddString("1.5"); err == nil {
+		t.Errorf("expected error for Frac > 1\n")
+	}
+
+	pat := etensor.NewFloat32([]int{2, 4}, nil, nil)
+	for i := 0; i < 4; i++ {
+		pat.Values[2*i] = 1
+	}
+	rnd := rand.New(rand.NewSource(1))
+	if dp := pc.Degrade(0, pat, rnd); dp.Values[0] != 1 || dp.Values[1] != 0 {
+		t.Errorf("level 0 changed pattern: %v\n", dp.Values)
+	}
+	dp := pc.Degrade(1, pat, rnd)
+	nact := 0
+	for i, v := range dp.Values {
+		if v > 0 {
+			nact++
+			if pat.Values[i] == 0 {
+				t.Errorf("unit %v turned on without noise\n", i)
+			}
+		}
+	}
+	if nact != 2 {
+		t.Errorf("level 0.5 kept %v of 4 active units, want 2\n", nact)
+	}
+
+	pc.AddTrial(0, 0, 0.75)
+	pc.AddTrial(0, 1, 0.25)
+	pc.AddTrial(1, 2, 0.5)
+	if st := pc.Log(0, 5); st != 0 || pc.Results.Rows != 3 {
+		t.Fatalf("Log: start %v rows %v\n", st, pc.Results.Rows)
+	}
+	dt := pc.Results
+	if dt.CellFloat("PctCor", 0) != 0.5 || dt.CellFloat("CosDiff", 0) != 0.5 || dt.CellFloat("Frac", 1) != 0.5 {
+		t.Errorf("results wrong: %v %v %v\n", dt.CellFloat("PctCor", 0), dt.CellFloat("CosDiff", 0), dt.CellFloat("Frac", 1))
+	}
+	if dt.CellFloat("N", 2) != 0 || dt.CellFloat("Epoch", 2) != 5 {
+		t.Errorf("empty level wrong: N %v\n", dt.CellFloat("N", 2))
+	}
+}
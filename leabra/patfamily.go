@@ -0,0 +1,212 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+// PatFamilies generates families of sparse binary patterns with a
+// controlled similarity structure: each family (a category) has a random
+// prototype with NOn of the units active, overlapping with the prototype
+// of every other family in at most MaxOverlap of its active units, and
+// each of its NPer members is a distortion of the prototype with Distort
+// of its active units moved to other, random units -- so members of the
+// same family share about 1 - Distort of their active units.
+type PatFamilies struct {
+	NFams      int           `desc:"number of families (categories)"`
+	NPer       int           `desc:"number of patterns per family"`
+	NOn        int           `desc:"number of active units in each pattern"`
+	Distort    float64       `min:"0" max:"1" desc:"proportion of the active units of the prototype that are moved to other units in each member of its family"`
+	MaxOverlap float64       `min:"0" max:"1" desc:"maximum proportion of active units that the prototypes of different families share"`
+	MaxTries   int           `desc:"maximum number of random prototypes tried for each family to meet MaxOverlap"`
+	Names      []string      `desc:"names of the families, used as the category labels -- Fam0, Fam1.. if not set"`
+	Protos     [][]int       `inactive:"+" desc:"active units of the prototype of each family"`
+	Pats       [][]int       `inactive:"+" desc:"active units of each pattern, family by family"`
+	Fams       []int         `inactive:"+" desc:"family of each pattern"`
+	Sims       *etable.Table `view:"-" desc:"proportion of active units shared by each pair of patterns, made by Generate -- see SaveSims"`
+}
+
+func (pf *PatFamilies) Defaults() {
+	pf.NFams = 5
+	pf.NPer = 5
+	pf.NOn = 6
+	pf.Distort = 0.25
+	pf.MaxOverlap = 0.34
+	pf.MaxTries = 1000
+}
+
+// FamName returns the name of given family
+func (pf *PatFamilies) FamName(fam int) string {
+	if fam < len(pf.Names) && pf.Names[fam] != "" {
+		return pf.Names[fam]
+	}
+	return fmt.Sprintf("Fam%d", fam)
+}
+
+// PatName returns the name of given pattern: family name _ index in family
+func (pf *PatFamilies) PatName(pi int) string {
+	return fmt.Sprintf("%v_%d", pf.FamName(pf.Fams[pi]), pi%pf.NPer)
+}
+
+// Generate generates the patterns over n units using given random number
+// stream, and their similarities
+func (pf *PatFamilies) Generate(n int, rnd *rand.Rand) error {
+	if pf.NOn <= 0 || pf.NOn > n {
+		err := fmt.Errorf("PatFamilies: NOn: %d must be in 1..%d units", pf.NOn, n)
+		log.Println(err)
+		return err
+	}
+	maxOv := int(math.Floor(pf.MaxOverlap * float64(pf.NOn)))
+	pf.Protos = make([][]int, pf.NFams)
+	for fi := range pf.Protos {
+		ok := false
+		for try := 0; try < pf.MaxTries && !ok; try++ {
+			proto := rnd.Perm(n)[:pf.NOn]
+			ok = true
+			for _, op := range pf.Protos[:fi] {
+				if PatOverlap(proto, op) > maxOv {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				pf.Protos[fi] = proto
+			}
+		}
+		if !ok {
+			err := fmt.Errorf("PatFamilies: could not generate %d prototypes of %d of %d units active with MaxOverlap: %g in %d tries", pf.NFams, pf.NOn, n, pf.MaxOverlap, pf.MaxTries)
+			log.Println(err)
+			return err
+		}
+	}
+	nmv := int(math.Floor(pf.Distort*float64(pf.NOn) + 0.5))
+	if nmv > n-pf.NOn {
+		nmv = n - pf.NOn
+	}
+	pf.Pats = make([][]int, 0, pf.NFams*pf.NPer)
+	pf.Fams = make([]int, 0, pf.NFams*pf.NPer)
+	for fi, proto := range pf.Protos {
+		on := make(map[int]bool, pf.NOn)
+		for _, u := range proto {
+			on[u] = true
+		}
+		var off []int
+		for u := 0; u < n; u++ {
+			if !on[u] {
+				off = append(off, u)
+			}
+		}
+		for pi := 0; pi < pf.NPer; pi++ {
+			pat := append([]int{}, proto...)
+			mv := rnd.Perm(pf.NOn)[:nmv]
+			to := rnd.Perm(len(off))[:nmv]
+			for i := range mv {
+				pat[mv[i]] = off[to[i]]
+			}
+			pf.Pats = append(pf.Pats, pat)
+			pf.Fams = append(pf.Fams, fi)
+		}
+	}
+	pf.ConfigSims()
+	return nil
+}
+
+// PatOverlap returns the number of active units shared by two patterns
+func PatOverlap(a, b []int) int {
+	n := 0
+	for _, ua := range a {
+		for _, ub := range b {
+			if ua == ub {
+				n++
+				break
+			}
+		}
+	}
+	return n
+}
+
+// ConfigSims computes the Sims table of the similarity of all the patterns
+// (see PatSimsTable)
+func (pf *PatFamilies) ConfigSims() {
+	names := make([]string, len(pf.Pats))
+	cats := make([]string, len(pf.Pats))
+	for pi := range pf.Pats {
+		names[pi] = pf.PatName(pi)
+		cats[pi] = pf.FamName(pf.Fams[pi])
+	}
+	pf.Sims = PatSimsTable(names, cats, pf.Pats)
+}
+
+// PatSimsTable returns a table of the similarity of all the given patterns
+// (as their active units): the Name and Cat of each pattern and the
+// proportion of its active units that it shares with each pattern, in the
+// Sim column -- the format of the target of a PatSynth
+func PatSimsTable(names, cats []string, pats [][]int) *etable.Table {
+	np := len(pats)
+	dt := &etable.Table{}
+	dt.SetMetaData("name", "PatSims")
+	dt.SetMetaData("desc", "proportion of active units shared by each pair of patterns")
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Cat", etensor.STRING, nil, nil},
+		{"Sim", etensor.FLOAT64, []int{np}, nil},
+	}, np)
+	for pi, pat := range pats {
+		dt.SetCellString("Name", pi, names[pi])
+		dt.SetCellString("Cat", pi, cats[pi])
+		sim := dt.CellTensor("Sim", pi)
+		for pj, oth := range pats {
+			if len(pat) > 0 {
+				sim.SetFloat1D(pj, float64(PatOverlap(pat, oth))/float64(len(pat)))
+			}
+		}
+	}
+	return dt
+}
+
+// SetPat sets the values of given tensor (e.g., a cell of a table of
+// patterns) to given pattern (see SetPatUnits)
+func (pf *PatFamilies) SetPat(tsr etensor.Tensor, pi int) {
+	SetPatUnits(tsr, pf.Pats[pi])
+}
+
+// SetPatUnits sets the values of given tensor to the pattern with given
+// active units: 1 for its active units and 0 for the rest
+func SetPatUnits(tsr etensor.Tensor, pat []int) {
+	for i := 0; i < tsr.Len(); i++ {
+		tsr.SetFloat1D(i, 0)
+	}
+	for _, u := range pat {
+		tsr.SetFloat1D(u, 1)
+	}
+}
+
+// SaveSims saves the Sims table to given CSV file
+func (pf *PatFamilies) SaveSims(filename gi.FileName) error {
+	return savePatSims("PatFamilies", pf.Sims, filename)
+}
+
+// savePatSims saves given table of pattern similarities, if made, to given
+// CSV file
+func savePatSims(who string, sims *etable.Table, filename gi.FileName) error {
+	if sims == nil {
+		err := fmt.Errorf("%v: no patterns generated", who)
+		log.Println(err)
+		return err
+	}
+	err := sims.SaveCSV(filename, etable.Tab, true)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/emer/etable/etensor"
+)
+
+func TestPatFamilies(t *testing.T) {
+	pf := &PatFamilies{}
+	pf.Defaults()
+	pf.Names = []string{"Neg", "Pos"}
+	if err := pf.Generate(25, rand.New(rand.NewSource(2))); err != nil {
+		t.Fatal(err)
+	}
+	if len(pf.Pats) != 25 || pf.PatName(7) != "Pos_2" || pf.PatName(12) != "Fam2_2" {
+		t.Fatalf("patterns: %d, names: %v %v\n", len(pf.Pats), pf.PatName(7), pf.PatName(12))
+	}
+	for fi, proto := range pf.Protos {
+		for _, op := range pf.Protos[:fi] {
+			if ov := PatOverlap(proto, op); ov > 2 {
+				t.Errorf("prototypes overlap: %d > 2\n", ov)
+			}
+		}
+	}
+	for pi, pat := range pf.Pats {
+		if ov := PatOverlap(pat, pf.Protos[pf.Fams[pi]]); ov != 4 {
+			t.Errorf("pattern %d overlaps its prototype in %d units, want 4\n", pi, ov)
+		}
+	}
+	if v := pf.Sims.CellTensor("Sim", 3).FloatVal1D(3); v != 1 || pf.Sims.CellString("Cat", 3) != "Neg" {
+		t.Errorf("sims wrong: %v %v\n", v, pf.Sims.CellString("Cat", 3))
+	}
+	if v := pf.Sims.CellTensor("Sim", 3).FloatVal1D(20); v > 4.0/6 {
+		t.Errorf("between-family sim: %v\n", v)
+	}
+	tsr := etensor.NewFloat32([]int{5, 5}, nil, nil)
+	pf.SetPat(tsr, 0)
+	sum := 0.0
+	for i := 0; i < tsr.Len(); i++ {
+		sum += tsr.FloatVal1D(i)
+	}
+	if sum != 6 {
+		t.Errorf("pattern has %v active units, want 6\n", sum)
+	}
+	pf.MaxOverlap = 0
+	pf.NFams = 10
+	if pf.Generate(25, rand.New(rand.NewSource(2))) == nil {
+		t.Errorf("expected error for 10 non-overlapping prototypes of 6 in 25 units\n")
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+
+	"github.com/emer/etable/etable"
+	"github.com/goki/gi/gi"
+)
+
+// PatSynth synthesizes sparse binary patterns that approximately realize a
+// Target similarity matrix, where the similarity of two patterns is the
+// proportion of their NOn active units that they share (as in the Sims of
+// PatFamilies).  Starting from random patterns, it moves one active unit of
+// one pattern at a time to an inactive unit, by simulated annealing of the
+// squared error between the Target and the similarities of the patterns,
+// with the temperature decreasing linearly from Temp to 0 over Iters moves.
+type PatSynth struct {
+	NOn    int           `desc:"number of active units in each pattern"`
+	Iters  int           `desc:"number of moves of an active unit tried"`
+	Temp   float64       `desc:"initial temperature of the annealing: the probability of accepting a move that increases the squared error by d is exp(-d / temperature)"`
+	Target [][]float64   `view:"-" desc:"target similarity of each pair of patterns: proportion of active units shared -- the diagonal is ignored, and the mean of [i][j] and [j][i] is used"`
+	Names  []string      `desc:"name of each pattern"`
+	Cats   []string      `desc:"category of each pattern"`
+	Pats   [][]int       `inactive:"+" desc:"active units of each pattern, made by Synthesize"`
+	Err    float64       `inactive:"+" desc:"root mean squared error between the Target and the similarities of the Pats over all pairs of patterns"`
+	Sims   *etable.Table `view:"-" desc:"similarities of the Pats, made by Synthesize -- see SaveSims"`
+}
+
+func (ps *PatSynth) Defaults() {
+	ps.NOn = 6
+	ps.Iters = 50000
+	ps.Temp = 0.05
+}
+
+// OpenTarget opens the Target similarities from given CSV file of a table
+// in the format of PatSimsTable: the Name and Cat of each pattern, and its
+// similarity to each pattern in the Sim column -- e.g., the Sims saved by
+// PatFamilies, or a matrix designed by hand
+func (ps *PatSynth) OpenTarget(filename gi.FileName) error {
+	dt := &etable.Table{}
+	if err := dt.OpenCSV(filename, etable.Tab); err != nil {
+		log.Println(err)
+		return err
+	}
+	return ps.SetTargetTable(dt)
+}
+
+// SetTargetTable sets the Target, Names and Cats from given table in the
+// format of PatSimsTable -- Name and Cat are optional
+func (ps *PatSynth) SetTargetTable(dt *etable.Table) error {
+	np := dt.Rows
+	sc := dt.ColByName("Sim")
+	if sc == nil || np == 0 || sc.Len() != np*np {
+		err := fmt.Errorf("PatSynth: target table: %v must have a Sim column with a similarity to each of its %d rows", dt.MetaData["name"], np)
+		log.Println(err)
+		return err
+	}
+	nc := dt.ColByName("Name")
+	cc := dt.ColByName("Cat")
+	ps.Target = make([][]float64, np)
+	ps.Names = make([]string, np)
+	ps.Cats = make([]string, np)
+	for i := range ps.Target {
+		ps.Target[i] = make([]float64, np)
+		for j := range ps.Target[i] {
+			ps.Target[i][j] = sc.FloatVal1D(i*np + j)
+		}
+		ps.Names[i] = fmt.Sprintf("Pat%d", i)
+		if nc != nil {
+			ps.Names[i] = nc.StringVal1D(i)
+		}
+		if cc != nil {
+			ps.Cats[i] = cc.StringVal1D(i)
+		}
+	}
+	return nil
+}
+
+// Synthesize synthesizes the patterns over n units using given random
+// number stream, and computes their Err and Sims
+func (ps *PatSynth) Synthesize(n int, rnd *rand.Rand) error {
+	np := len(ps.Target)
+	if ps.NOn <= 0 || ps.NOn >= n {
+		err := fmt.Errorf("PatSynth: NOn: %d must be in 1..%d units", ps.NOn, n-1)
+		log.Println(err)
+		return err
+	}
+	for i := range ps.Target {
+		if len(ps.Target[i]) != np {
+			err := fmt.Errorf("PatSynth: Target must be a square matrix: row %d has %d of %d similarities", i, len(ps.Target[i]), np)
+			log.Println(err)
+			return err
+		}
+	}
+	non := float64(ps.NOn)
+	tgt := make([][]float64, np) // target number of shared units, symmetric
+	for i := range tgt {
+		tgt[i] = make([]float64, np)
+		for j := range tgt[i] {
+			tgt[i][j] = 0.5 * (ps.Target[i][j] + ps.Target[j][i]) * non
+		}
+	}
+	ps.Pats = make([][]int, np)
+	on := make([][]bool, np)
+	for i := range ps.Pats {
+		ps.Pats[i] = rnd.Perm(n)[:ps.NOn]
+		on[i] = make([]bool, n)
+		for _, u := range ps.Pats[i] {
+			on[i][u] = true
+		}
+	}
+	ovl := make([][]float64, np) // number of shared units
+	for i := range ovl {
+		ovl[i] = make([]float64, np)
+		for j := range ovl[i] {
+			ovl[i][j] = float64(PatOverlap(ps.Pats[i], ps.Pats[j]))
+		}
+	}
+	for it := 0; it < ps.Iters && np > 1; it++ {
+		temp := ps.Temp * (1 - float64(it)/float64(ps.Iters))
+		i := rnd.Intn(np)
+		k := rnd.Intn(ps.NOn)
+		u := ps.Pats[i][k]
+		v := rnd.Intn(n)
+		if on[i][v] {
+			continue
+		}
+		de := 0.0 // change in squared error of the proportions shared
+		for j := range ps.Pats {
+			if j == i {
+				continue
+			}
+			d := 0.0
+			if on[j][u] {
+				d--
+			}
+			if on[j][v] {
+				d++
+			}
+			if d != 0 {
+				e0 := ovl[i][j] - tgt[i][j]
+				e1 := e0 + d
+				de += (e1*e1 - e0*e0) / (non * non)
+			}
+		}
+		if de > 0 && (temp <= 0 || rnd.Float64() >= math.Exp(-de/temp)) {
+			continue
+		}
+		for j := range ps.Pats {
+			if j == i {
+				continue
+			}
+			if on[j][u] {
+				ovl[i][j]--
+				ovl[j][i]--
+			}
+			if on[j][v] {
+				ovl[i][j]++
+				ovl[j][i]++
+			}
+		}
+		on[i][u] = false
+		on[i][v] = true
+		ps.Pats[i][k] = v
+	}
+	ps.Err = 0
+	npr := 0
+	for i := range ovl {
+		for j := i + 1; j < np; j++ {
+			e := (ovl[i][j] - tgt[i][j]) / non
+			ps.Err += e * e
+			npr++
+		}
+	}
+	if npr > 0 {
+		ps.Err = math.Sqrt(ps.Err / float64(npr))
+	}
+	if len(ps.Names) != np {
+		ps.Names = make([]string, np)
+		for i := range ps.Names {
+			ps.Names[i] = fmt.Sprintf("Pat%d", i)
+		}
+	}
+	if len(ps.Cats) != np {
+		ps.Cats = make([]string, np)
+	}
+	ps.Sims = PatSimsTable(ps.Names, ps.Cats, ps.Pats)
+	return nil
+}
+
+// SaveSims saves the Sims table of the synthesized patterns to given CSV
+// file, to compare with the Target
+func (ps *PatSynth) SaveSims(filename gi.FileName) error {
+	return savePatSims("PatSynth", ps.Sims, filename)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPatSynth(t *testing.T) {
+	// two clusters of 4 patterns sharing half their units, none across
+	np := 8
+	names := make([]string, np)
+	cats := make([]string, np)
+	pats := make([][]int, np)
+	for i := range pats {
+		names[i] = string('a' + rune(i))
+		cats[i] = string('A' + rune(i/4))
+		for u := 0; u < 3; u++ {
+			pats[i] = append(pats[i], (i/4)*3+u, 6+i*3+u)
+		}
+	}
+	ps := &PatSynth{}
+	ps.Defaults()
+	if err := ps.SetTargetTable(PatSimsTable(names, cats, pats)); err != nil {
+		t.Fatal(err)
+	}
+	if ps.Target[0][3] != 0.5 || ps.Target[0][4] != 0 || ps.Names[5] != "f" || ps.Cats[5] != "B" {
+		t.Fatalf("target not read: %v\n", ps.Target[0])
+	}
+	if err := ps.Synthesize(25, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatal(err)
+	}
+	if ps.Err > 0.1 {
+		t.Errorf("error: %v\n", ps.Err)
+	}
+	for i := range ps.Pats {
+		if len(ps.Pats[i]) != ps.NOn || PatOverlap(ps.Pats[i], ps.Pats[i]) != ps.NOn {
+			t.Errorf("pattern %d does not have %d distinct active units: %v\n", i, ps.NOn, ps.Pats[i])
+		}
+	}
+	if ps.Sims.Rows != np || ps.Sims.CellString("Cat", 7) != "B" {
+		t.Errorf("sims table wrong\n")
+	}
+	ps.NOn = 25
+	if ps.Synthesize(25, rand.New(rand.NewSource(1))) == nil {
+		t.Errorf("expected error for all units active\n")
+	}
+}
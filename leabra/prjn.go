@@ -8,6 +8,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
+	"math/rand"
 	"strings"
 
 	"github.com/chewxy/math32"
@@ -22,12 +24,16 @@ type Prjn struct {
 	WtInit  erand.RndParams `view:"inline" desc:"initial random weight distribution"`
 	WtScale WtScaleParams   `desc:"weight scaling parameters: modulates overall strength of projection, using both absolute and relative factors"`
 	Learn   LearnSynParams  `desc:"synaptic-level learning parameters"`
-	Syns    []Synapse       `desc:"synaptic state values, ordered by the sending layer units which owns them -- one-to-one with SConIdx array"`
+	SynDep  SynDepParams    `desc:"synaptic depression parameters, used during sleep -- copied into each synapse in InitSdEffWt"`
+	Syns    Synapses        `desc:"synaptic state values, ordered by the sending layer units which owns them -- one-to-one with SConIdx array"`
 
 	// misc state variables below:
 	GScale float32         `desc:"scaling factor for integrating synaptic input conductances (G's) -- computed in AlphaCycInit, incorporates running-average activity levels"`
 	GInc   []float32       `desc:"local increment accumulator for synaptic conductance from sending units -- goes to either GeInc or GiInc on neuron depending on projection type -- this will be thread-safe"`
 	WbRecv []WtBalRecvPrjn `desc:"weight balance state variables for this projection, one per recv neuron"`
+	Rnd    *rand.Rand      `view:"-" json:"-" desc:"random number stream for this projection, used for initial weights -- nil = use global generator -- see Network.SeedPrjnRnd"`
+
+	rndSrc *RndSource // source of Rnd, with its state for Network.RndStates
 }
 
 // AsLeabra returns this prjn as a leabra.Prjn -- all derived prjns must redefine
@@ -43,6 +49,7 @@ func (pj *Prjn) Defaults() {
 	pj.WtInit.Dist = erand.Uniform
 	pj.WtScale.Defaults()
 	pj.Learn.Defaults()
+	pj.SynDep.Defaults()
 	pj.GScale = 1
 }
 
@@ -50,6 +57,7 @@ func (pj *Prjn) Defaults() {
 func (pj *Prjn) UpdateParams() {
 	pj.WtScale.Update()
 	pj.Learn.Update()
+	pj.SynDep.Update()
 }
 
 // AllParams returns a listing of all parameters in the Layer
@@ -57,6 +65,8 @@ func (pj *Prjn) AllParams() string {
 	str := "///////////////////////////////////////////////////\nPrjn: " + pj.Name() + "\n"
 	b, _ := json.MarshalIndent(&pj.Learn, "", " ")
 	str += "Learn: {\n " + strings.Replace(JsonToParams(b), " WtInit: {", "\n  WtInit: {", -1)
+	b, _ = json.MarshalIndent(&pj.SynDep, "", " ")
+	str += "SynDep: {\n " + JsonToParams(b)
 	return str
 }
 
@@ -68,12 +78,15 @@ func (pj *Prjn) SynVarNames() []string {
 // for each synapse in the projection using the natural ordering
 // of the synapses (sender based for Leabra)
 func (pj *Prjn) SynVals(varnm string) []float32 {
-	vl := make([]float32, len(pj.Syns))
-	for si := range pj.Syns {
-		sy := &pj.Syns[si]
-		sv, ok := sy.VarByName(varnm)
-		if ok {
-			vl[si] = sv
+	vl := make([]float32, pj.Syns.Len())
+	si := 0
+	for _, syns := range pj.Syns.Chunks {
+		for ci := range syns {
+			sv, ok := syns[ci].VarByName(varnm)
+			if ok {
+				vl[si] = sv
+			}
+			si++
 		}
 	}
 	return vl
@@ -83,15 +96,21 @@ func (pj *Prjn) SynVals(varnm string) []float32 {
 // for each synapse in the projection using the natural ordering
 // of the synapses (sender based for Leabra)
 func (pj *Prjn) SynValsTry(varnm string) ([]float32, error) {
-	vl := make([]float32, len(pj.Syns))
+	vl := make([]float32, pj.Syns.Len())
 	notOk := false
-	for si := range pj.Syns {
-		sy := &pj.Syns[si]
-		sv, ok := sy.VarByName(varnm)
-		if ok {
-			vl[si] = sv
-		} else {
-			notOk = true
+	si := 0
+	for _, syns := range pj.Syns.Chunks {
+		for ci := range syns {
+			sv, ok := syns[ci].VarByName(varnm)
+			if ok {
+				vl[si] = sv
+			} else {
+				notOk = true
+				break
+			}
+			si++
+		}
+		if notOk {
 			break
 		}
 	}
@@ -131,7 +150,7 @@ func (pj *Prjn) SynValTry(varnm string, sidx, ridx int) (float32, error) {
 			continue
 		}
 		rsi := pj.RSynIdx[st+ci]
-		sy := &pj.Syns[rsi]
+		sy := pj.Syns.At(rsi)
 		sv, ok := sy.VarByName(varnm)
 		if ok {
 			return sv, nil
@@ -162,7 +181,7 @@ func (pj *Prjn) SetSynVal(varnm string, sidx, ridx int, val float32) error {
 			continue
 		}
 		rsi := pj.RSynIdx[st+ci]
-		sy := &pj.Syns[rsi]
+		sy := pj.Syns.At(rsi)
 		ok := sy.SetVarByName(varnm, float64(val))
 		if ok {
 			if varnm == "Wt" {
@@ -211,7 +230,7 @@ func (pj *Prjn) WriteWtsJSON(w io.Writer, depth int) {
 		w.Write([]byte("\"Wt\": ["))
 		for ci := 0; ci < nc; ci++ {
 			rsi := pj.RSynIdx[st+ci]
-			sy := &pj.Syns[rsi]
+			sy := pj.Syns.At(rsi)
 			w.Write([]byte(fmt.Sprintf("%v ", sy.Wt)))
 		}
 		w.Write([]byte("],\n"))
@@ -232,9 +251,59 @@ func (pj *Prjn) WriteWtsJSON(w io.Writer, depth int) {
 }
 
 // ReadWtsJSON reads the weights for this projection from the receiver-side perspective
-// in a JSON text format.
+// in a JSON text format.  This is for a set of weights that were saved *for one prjn only*
+// and is not used for the network-level ReadWtsJSON, which reads into a separate
+// structure -- see SetWts method.
 func (pj *Prjn) ReadWtsJSON(r io.Reader) error {
-	return nil
+	pw, err := ReadPrjnWtsJSON(r)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	return pj.SetWts(pw)
+}
+
+// SetWts sets the weights for this projection from PrjnWts decoded values.
+// The weights must match the connectivity of the projection exactly -- use
+// SetWtsMapped to load weights from a projection with a different architecture.
+// Returns an error for any mismatch, but sets all the weights that do match.
+func (pj *Prjn) SetWts(pw *PrjnWts) error {
+	var err error
+	nr := len(pj.RConN)
+	pj.GScale = pw.GScale
+	for i := range pw.Rs {
+		rw := &pw.Rs[i]
+		if rw.Ri < 0 || rw.Ri >= nr {
+			err = fmt.Errorf("Prjn.SetWts: %v recv unit index %v is out of range of recv layer size: %v", pj.String(), rw.Ri, nr)
+			continue
+		}
+		nc := int(pj.RConN[rw.Ri])
+		st := int(pj.RConIdxSt[rw.Ri])
+		if len(rw.Si) != nc {
+			err = fmt.Errorf("Prjn.SetWts: %v recv unit index %v number of weights: %v != number of connections: %v", pj.String(), rw.Ri, len(rw.Si), nc)
+		}
+		for j, si := range rw.Si {
+			ci := 0
+			if j < nc && int(pj.RConIdx[st+j]) == si { // usual case: same order
+				ci = j
+			} else {
+				for ci = 0; ci < nc; ci++ {
+					if int(pj.RConIdx[st+ci]) == si {
+						break
+					}
+				}
+				if ci == nc {
+					err = fmt.Errorf("Prjn.SetWts: %v recv unit index %v does not recv from send unit index %v", pj.String(), rw.Ri, si)
+					continue
+				}
+			}
+			pj.SetSynWt(pj.Syns.At(pj.RSynIdx[st+ci]), rw.Wt[j])
+		}
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return err
 }
 
 // Build constructs the full connectivity among the layers as specified in this projection.
@@ -243,7 +312,7 @@ func (pj *Prjn) Build() error {
 	if err := pj.BuildStru(); err != nil {
 		return err
 	}
-	pj.Syns = make([]Synapse, len(pj.SConIdx))
+	pj.Syns.Alloc(pj.SConN)
 	rsh := pj.Recv.Shape()
 	//	ssh := pj.Send.Shape()
 	rlen := rsh.Len()
@@ -262,7 +331,7 @@ func (pj *Prjn) InitWtsSyn(syn *Synapse) {
 	if syn.Scale == 0 {
 		syn.Scale = 1
 	}
-	syn.Wt = float32(pj.WtInit.Gen(-1))
+	syn.Wt = float32(RndGen(&pj.WtInit, pj.Rnd))
 	syn.LWt = pj.Learn.WtSig.LinFmSigWt(syn.Wt)
 	syn.Wt *= syn.Scale // note: scale comes after so LWt is always "pure" non-scaled value
 	syn.DWt = 0
@@ -273,9 +342,10 @@ func (pj *Prjn) InitWtsSyn(syn *Synapse) {
 
 // InitWts initializes weight values according to Learn.WtInit params
 func (pj *Prjn) InitWts() {
-	for si := range pj.Syns {
-		sy := &pj.Syns[si]
-		pj.InitWtsSyn(sy)
+	for _, syns := range pj.Syns.Chunks {
+		for si := range syns {
+			pj.InitWtsSyn(&syns[si])
+		}
 	}
 	for wi := range pj.WbRecv {
 		wb := &pj.WbRecv[wi]
@@ -286,19 +356,33 @@ func (pj *Prjn) InitWts() {
 
 // InitEffwt initializes effective weight values and all synaptic depression related variables according to default
 func (pj *Prjn) InitSdEffWt() {
-	for si := range pj.Syns {
-		sy := &pj.Syns[si]
-		sy.Effwt = sy.Wt
-		sy.Cai = 0.0
-		sy.Rec = 0.002
-		sy.Ca_dec = 0.25
-		sy.Ca_inc = 0.6
-		sy.sd_ca_thr = 0.0
-		sy.sd_ca_gain = 3.0
-		sy.sd_ca_thr_rescale = sy.sd_ca_gain / (1.0 - sy.sd_ca_thr)
+	for _, syns := range pj.Syns.Chunks {
+		for si := range syns {
+			pj.InitSdEffWtSyn(&syns[si])
+		}
 	}
 }
 
+// InitSdEffWtSyn initializes the effective weight and synaptic depression
+// variables for an individual synapse, with the rates from SynDep params.
+func (pj *Prjn) InitSdEffWtSyn(sy *Synapse) {
+	sy.Effwt = sy.Wt
+	sy.Cai = 0.0
+	sy.Rec = pj.SynDep.Rec
+	sy.Ca_dec = pj.SynDep.CaDec
+	sy.Ca_inc = pj.SynDep.CaInc
+	pj.InitSdConstsSyn(sy)
+}
+
+// InitSdConstsSyn initializes the fixed (non-exported) synaptic depression
+// constants for an individual synapse from the SynDep params, without
+// affecting any of its state.
+func (pj *Prjn) InitSdConstsSyn(sy *Synapse) {
+	sy.sd_ca_thr = pj.SynDep.CaThr
+	sy.sd_ca_gain = pj.SynDep.CaGain
+	sy.sd_ca_thr_rescale = pj.SynDep.CaRescale
+}
+
 // InitWtSym initializes weight symmetry -- is given the reciprocal projection where
 // the Send and Recv layers are reversed.
 func (pj *Prjn) InitWtSym(rpjp LeabraPrjn) {
@@ -306,20 +390,20 @@ func (pj *Prjn) InitWtSym(rpjp LeabraPrjn) {
 	slay := pj.Send.(LeabraLayer).AsLeabra()
 	ns := len(slay.Neurons)
 	for si := 0; si < ns; si++ {
-		nc := int(pj.SConN[si])
-		st := int(pj.SConIdxSt[si])
-		for ci := 0; ci < nc; ci++ {
-			sy := &pj.Syns[st+ci]
-			ri := pj.SConIdx[st+ci]
+		st := pj.SConIdxSt[si]
+		syns := pj.Syns.Range(st, pj.SConN[si])
+		for ci := range syns {
+			sy := &syns[ci]
+			ri := pj.SConIdx[st+int64(ci)]
 			// now we need to find the reciprocal synapse on rpj!
 			// look in ri for sending connections
 			rsi := ri
-			rsnc := int(rpj.SConN[rsi])
-			rsst := int(rpj.SConIdxSt[rsi])
-			for rci := 0; rci < rsnc; rci++ {
-				rri := int(rpj.SConIdx[rsst+rci])
+			rsst := rpj.SConIdxSt[rsi]
+			rsyns := rpj.Syns.Range(rsst, rpj.SConN[rsi])
+			for rci := range rsyns {
+				rri := int(rpj.SConIdx[rsst+int64(rci)])
 				if rri == si {
-					rsy := &rpj.Syns[rsst+rci]
+					rsy := &rsyns[rci]
 					rsy.Wt = sy.Wt
 					rsy.LWt = sy.LWt
 					rsy.Scale = sy.Scale
@@ -342,9 +426,7 @@ func (pj *Prjn) InitGInc() {
 //  Act methods
 
 func (pj *Prjn) MonChge(si int) {
-	nc := pj.SConN[si]
-	st := pj.SConIdxSt[si]
-	syns := pj.Syns[st : st+nc]
+	syns := pj.Syns.Range(pj.SConIdxSt[si], pj.SConN[si])
 	for ci := range syns {
 		sy := &syns[ci]
 		fmt.Println("The current Wt and Effwt are: %d, %d.", sy.Wt, sy.Effwt)
@@ -355,23 +437,20 @@ func (pj *Prjn) MonChge(si int) {
 func (pj *Prjn) CaUpdt(si int, preSynAct float32) {
 	nc := pj.SConN[si]
 	st := pj.SConIdxSt[si]
-	counter := make([]int32, nc)
-	for ci := range counter {
-		ri := pj.SConIdx[st+int32(ci)]
-		rn := &pj.Recv.(LeabraLayer).AsLeabra().Neurons[ri]
-		sy := &pj.Syns[int32(ci)+st]
-		sy.CaUpdt(rn.Act, preSynAct)
+	syns := pj.Syns.Range(st, nc)
+	scons := pj.SConIdx[st : st+int64(nc)]
+	rns := pj.Recv.(LeabraLayer).AsLeabra().Neurons
+	for ci := range syns {
+		syns[ci].CaUpdt(rns[scons[ci]].Act, preSynAct)
 	}
 }
 
 // CalSynDep calculated the synaptic depression variable for each synapse.
 func (pj *Prjn) CalSynDep(si int) {
 	// fmt.Println("Step into the real CalSynDep")
-	nc := pj.SConN[si]
-	st := pj.SConIdxSt[si]
-	counter := make([]int32, nc)
-	for ci := range counter {
-		sy := &pj.Syns[int32(ci)+st]
+	syns := pj.Syns.Range(pj.SConIdxSt[si], pj.SConN[si])
+	for ci := range syns {
+		sy := &syns[ci]
 		sy.Effwt = sy.Wt * sy.SynDep()
 		// Final checking if the Effwt is out of bounds
 		if sy.Effwt > sy.Wt {
@@ -384,21 +463,25 @@ func (pj *Prjn) CalSynDep(si int) {
 }
 
 // SendGDelta sends the delta-activation from sending neuron index si,
-// to integrate synaptic conductances on receivers
+// to integrate synaptic conductances on receivers.
+// The inner loops are kept branch-free over contiguous slices (sleep is tested
+// once outside the loop, and the slices are re-sliced to the same length so
+// bounds checks can be eliminated), as this is the main cost of each cycle.
 func (pj *Prjn) SendGDelta(si int, delta float32, sleep bool) {
 	scdel := delta * pj.GScale
 	nc := pj.SConN[si]
 	st := pj.SConIdxSt[si]
-	syns := pj.Syns[st : st+nc]
-	scons := pj.SConIdx[st : st+nc]
-	for ci := range syns {
-		ri := scons[ci]
-		if sleep {
-			//			fmt.Println("Current Effwt and Wt: %d; %d.", syns[ci].Effwt, syns[ci].Wt)
-			pj.GInc[ri] += scdel * syns[ci].Effwt // Switch to Effwt!!! By Diheng DONE
-			//			fmt.Println("Current delta and pj.GInc is: %d, %d", delta, pj.GInc[ri])
-		} else {
-			pj.GInc[ri] += scdel * syns[ci].Wt //  Original update rule.
+	syns := pj.Syns.Range(st, nc)
+	scons := pj.SConIdx[st : st+int64(nc)]
+	scons = scons[:len(syns)]
+	ginc := pj.GInc
+	if sleep {
+		for ci := range syns {
+			ginc[scons[ci]] += scdel * syns[ci].Effwt // Switch to Effwt!!! By Diheng DONE
+		}
+	} else {
+		for ci := range syns {
+			ginc[scons[ci]] += scdel * syns[ci].Wt //  Original update rule.
 		}
 	}
 }
@@ -406,17 +489,17 @@ func (pj *Prjn) SendGDelta(si int, delta float32, sleep bool) {
 // RecvGInc increments the receiver's GeInc or GiInc from that of all the projections.
 func (pj *Prjn) RecvGInc() {
 	rlay := pj.Recv.(LeabraLayer).AsLeabra()
+	nrns := rlay.Neurons
+	ginc := pj.GInc[:len(nrns)]
 	if pj.Typ == emer.Inhib {
-		for ri := range rlay.Neurons {
-			rn := &rlay.Neurons[ri]
-			rn.GiInc += pj.GInc[ri]
-			pj.GInc[ri] = 0
+		for ri := range nrns {
+			nrns[ri].GiInc += ginc[ri]
+			ginc[ri] = 0
 		}
 	} else {
-		for ri := range rlay.Neurons {
-			rn := &rlay.Neurons[ri]
-			rn.GeInc += pj.GInc[ri]
-			pj.GInc[ri] = 0
+		for ri := range nrns {
+			nrns[ri].GeInc += ginc[ri]
+			ginc[ri] = 0
 		}
 	}
 }
@@ -436,10 +519,10 @@ func (pj *Prjn) DWt() {
 		if sn.AvgS < pj.Learn.XCal.LrnThr && sn.AvgM < pj.Learn.XCal.LrnThr {
 			continue
 		}
-		nc := int(pj.SConN[si])
-		st := int(pj.SConIdxSt[si])
-		syns := pj.Syns[st : st+nc]
-		scons := pj.SConIdx[st : st+nc]
+		nc := pj.SConN[si]
+		st := pj.SConIdxSt[si]
+		syns := pj.Syns.Range(st, nc)
+		scons := pj.SConIdx[st : st+int64(nc)]
 		for ci := range syns {
 			sy := &syns[ci]
 			ri := scons[ci]
@@ -483,16 +566,20 @@ func (pj *Prjn) WtFmDWt() {
 		return
 	}
 	if pj.Learn.WtBal.On {
-		for si := range pj.Syns {
-			sy := &pj.Syns[si]
-			ri := pj.SConIdx[si]
-			wb := &pj.WbRecv[ri]
-			pj.Learn.WtFmDWt(wb.Inc, wb.Dec, &sy.DWt, &sy.Wt, &sy.LWt, sy.Scale)
+		for c, syns := range pj.Syns.Chunks {
+			scons := pj.SConIdx[pj.Syns.St[c] : pj.Syns.St[c]+int64(len(syns))]
+			for si := range syns {
+				sy := &syns[si]
+				wb := &pj.WbRecv[scons[si]]
+				pj.Learn.WtFmDWt(wb.Inc, wb.Dec, &sy.DWt, &sy.Wt, &sy.LWt, sy.Scale)
+			}
 		}
 	} else {
-		for si := range pj.Syns {
-			sy := &pj.Syns[si]
-			pj.Learn.WtFmDWt(1, 1, &sy.DWt, &sy.Wt, &sy.LWt, sy.Scale)
+		for _, syns := range pj.Syns.Chunks {
+			for si := range syns {
+				sy := &syns[si]
+				pj.Learn.WtFmDWt(1, 1, &sy.DWt, &sy.Wt, &sy.LWt, sy.Scale)
+			}
 		}
 	}
 }
@@ -522,8 +609,7 @@ func (pj *Prjn) WtBalFmWt() {
 		sumWt := float32(0)
 		sumN := 0
 		for ci := range rsidxs {
-			rsi := rsidxs[ci]
-			sy := &pj.Syns[rsi]
+			sy := pj.Syns.At(rsidxs[ci])
 			if sy.Wt >= pj.Learn.WtBal.AvgThr {
 				sumWt += sy.Wt
 				sumN++
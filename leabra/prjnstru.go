@@ -31,12 +31,12 @@ type PrjnStru struct {
 	Typ         emer.PrjnType   `desc:"type of projection -- Forward, Back, Lateral, or extended type in specialized algorithms -- matches against .Cls parameter styles (e.g., .Back etc)"`
 	RConN       []int32         `view:"-" desc:"number of recv connections for each neuron in the receiving layer, as a flat list"`
 	RConNAvgMax minmax.AvgMax32 `inactive:"+" desc:"average and maximum number of recv connections in the receiving layer"`
-	RConIdxSt   []int32         `view:"-" desc:"starting index into ConIdx list for each neuron in receiving layer -- just a list incremented by ConN"`
+	RConIdxSt   []int64         `view:"-" desc:"starting index into ConIdx list for each neuron in receiving layer -- just a list incremented by ConN"`
 	RConIdx     []int32         `view:"-" desc:"index of other neuron on sending side of projection, ordered by the receiving layer's order of units as the outer loop (each start is in ConIdxSt), and then by the sending layer's units within that"`
-	RSynIdx     []int32         `view:"-" desc:"index of synaptic state values for each recv unit x connection, for the receiver projection which does not own the synapses, and instead indexes into sender-ordered list"`
+	RSynIdx     []int64         `view:"-" desc:"index of synaptic state values for each recv unit x connection, for the receiver projection which does not own the synapses, and instead indexes into sender-ordered list"`
 	SConN       []int32         `view:"-" desc:"number of sending connections for each neuron in the sending layer, as a flat list"`
 	SConNAvgMax minmax.AvgMax32 `inactive:"+" desc:"average and maximum number of sending connections in the sending layer"`
-	SConIdxSt   []int32         `view:"-" desc:"starting index into ConIdx list for each neuron in sending layer -- just a list incremented by ConN"`
+	SConIdxSt   []int64         `view:"-" desc:"starting index into ConIdx list for each neuron in sending layer -- just a list incremented by ConN"`
 	SConIdx     []int32         `view:"-" desc:"index of other neuron on receiving side of projection, ordered by the sending layer's order of units as the outer loop (each start is in ConIdxSt), and then by the sending layer's units within that"`
 }
 
@@ -110,7 +110,13 @@ func (ps *PrjnStru) BuildStru() error {
 	}
 	ssh := ps.Send.Shape()
 	rsh := ps.Recv.Shape()
+	if sp, ok := ps.Pat.(SparsePattern); ok {
+		return ps.BuildStruSparse(sp)
+	}
 	sendn, recvn, cons := ps.Pat.Connect(ssh, rsh, ps.Recv == ps.Send)
+	if err := ps.CheckConTotal(sendn); err != nil {
+		return err
+	}
 	slen := ssh.Len()
 	rlen := rsh.Len()
 	tcons := ps.SetNIdxSt(&ps.SConN, &ps.SConNAvgMax, &ps.SConIdxSt, sendn)
@@ -119,7 +125,7 @@ func (ps *PrjnStru) BuildStru() error {
 		log.Printf("%v programmer error: total recv cons %v != total send cons %v\n", ps.String(), tconr, tcons)
 	}
 	ps.RConIdx = make([]int32, tconr)
-	ps.RSynIdx = make([]int32, tconr)
+	ps.RSynIdx = make([]int64, tconr)
 	ps.SConIdx = make([]int32, tcons)
 
 	sconN := make([]int32, slen) // temporary mem needed to tracks cur n of sending cons
@@ -129,13 +135,13 @@ func (ps *PrjnStru) BuildStru() error {
 		rbi := ri * slen     // recv bit index
 		rtcn := ps.RConN[ri] // number of cons
 		rst := ps.RConIdxSt[ri]
-		rci := int32(0)
+		rci := int64(0)
 		for si := 0; si < slen; si++ {
 			if !cbits.Index(rbi + si) { // no connection
 				continue
 			}
 			sst := ps.SConIdxSt[si]
-			if rci >= rtcn {
+			if rci >= int64(rtcn) {
 				log.Printf("%v programmer error: recv target total con number: %v exceeded at recv idx: %v, send idx: %v\n", ps.String(), rtcn, ri, si)
 				break
 			}
@@ -147,8 +153,8 @@ func (ps *PrjnStru) BuildStru() error {
 				log.Printf("%v programmer error: send target total con number: %v exceeded at recv idx: %v, send idx: %v\n", ps.String(), stcn, ri, si)
 				break
 			}
-			ps.SConIdx[sst+sci] = int32(ri)
-			ps.RSynIdx[rst+rci] = sst + sci
+			ps.SConIdx[sst+int64(sci)] = int32(ri)
+			ps.RSynIdx[rst+rci] = sst + int64(sci)
 			(sconN[si])++
 			rci++
 		}
@@ -158,18 +164,18 @@ func (ps *PrjnStru) BuildStru() error {
 
 // SetNIdxSt sets the *ConN and *ConIdxSt values given n tensor from Pat.
 // Returns total number of connections for this direction.
-func (ps *PrjnStru) SetNIdxSt(n *[]int32, avgmax *minmax.AvgMax32, idxst *[]int32, tn *etensor.Int32) int32 {
+func (ps *PrjnStru) SetNIdxSt(n *[]int32, avgmax *minmax.AvgMax32, idxst *[]int64, tn *etensor.Int32) int64 {
 	ln := tn.Len()
 	tnv := tn.Values
 	*n = make([]int32, ln)
-	*idxst = make([]int32, ln)
-	idx := int32(0)
+	*idxst = make([]int64, ln)
+	idx := int64(0)
 	avgmax.Init()
 	for i := 0; i < ln; i++ {
 		nv := tnv[i]
 		(*n)[i] = nv
 		(*idxst)[i] = idx
-		idx += nv
+		idx += int64(nv)
 		avgmax.UpdateVal(float32(nv), i)
 	}
 	avgmax.CalcAvg()
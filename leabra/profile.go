@@ -0,0 +1,133 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// profFun returns a wrapper around given layer function that records the time
+// spent in each layer for the function in ProfTimes.  The ProfTimes entry for the
+// function is created here, in the calling thread, so the workers only write
+// to their own layers' elements.
+func (nt *NetworkStru) profFun(fun func(ly LeabraLayer), funame string) func(ly LeabraLayer) {
+	if nt.ProfTimes == nil {
+		nt.ProfTimes = make(map[string][]float64)
+	}
+	pt, has := nt.ProfTimes[funame]
+	if !has || len(pt) != len(nt.Layers) {
+		pt = make([]float64, len(nt.Layers))
+		nt.ProfTimes[funame] = pt
+	}
+	return func(ly LeabraLayer) {
+		st := time.Now()
+		fun(ly)
+		pt[ly.Index()] += time.Since(st).Seconds()
+	}
+}
+
+// ProfileReset resets the accumulated per-layer, per-function profile times
+func (nt *NetworkStru) ProfileReset() {
+	nt.ProfTimes = nil
+}
+
+// ProfileFunNames returns the sorted names of the functions with profile times
+func (nt *NetworkStru) ProfileFunNames() []string {
+	fnms := make([]string, 0, len(nt.ProfTimes))
+	for fn := range nt.ProfTimes {
+		fnms = append(fnms, fn)
+	}
+	sort.Strings(fnms)
+	return fnms
+}
+
+// ConfigProfileLog configures a table for logging the profile times, with
+// one row per epoch, function and layer -- see ProfileLog.
+func (nt *NetworkStru) ConfigProfileLog(dt *etable.Table) {
+	dt.SetMetaData("name", "ProfileLog")
+	dt.SetMetaData("desc", "Time spent in each function for each layer, per epoch")
+	dt.SetFromSchema(etable.Schema{
+		{"Epoch", etensor.INT64, nil, nil},
+		{"Function", etensor.STRING, nil, nil},
+		{"Layer", etensor.STRING, nil, nil},
+		{"Secs", etensor.FLOAT64, nil, nil},
+		{"Pct", etensor.FLOAT64, nil, nil},
+	}, 0)
+}
+
+// ProfileLog adds rows to the given profile log table (see ConfigProfileLog) for
+// the times accumulated since the last call (or ProfileReset), for the given epoch,
+// with the percent of total time across all functions and layers, and then resets
+// the accumulated times.  Profile must be on for times to be recorded.
+func (nt *NetworkStru) ProfileLog(dt *etable.Table, epoch int) {
+	tot := 0.0
+	for _, pt := range nt.ProfTimes {
+		for _, t := range pt {
+			tot += t
+		}
+	}
+	if tot == 0 {
+		tot = 1
+	}
+	for _, fn := range nt.ProfileFunNames() {
+		pt := nt.ProfTimes[fn]
+		for li, t := range pt {
+			if t == 0 {
+				continue
+			}
+			row := dt.Rows
+			dt.SetNumRows(row + 1)
+			dt.SetCellFloat("Epoch", row, float64(epoch))
+			dt.SetCellString("Function", row, strings.TrimSpace(fn))
+			dt.SetCellString("Layer", row, nt.Layers[li].Name())
+			dt.SetCellFloat("Secs", row, t)
+			dt.SetCellFloat("Pct", row, 100*t/tot)
+		}
+	}
+	nt.ProfileReset()
+}
+
+// ProfileReport returns a report of the accumulated profile times, as the total
+// time for each function (sorted by decreasing time), broken down by layer.
+func (nt *NetworkStru) ProfileReport() string {
+	type funTime struct {
+		fn  string
+		tot float64
+	}
+	fts := make([]funTime, 0, len(nt.ProfTimes))
+	tot := 0.0
+	for fn, pt := range nt.ProfTimes {
+		ft := funTime{fn: fn}
+		for _, t := range pt {
+			ft.tot += t
+		}
+		tot += ft.tot
+		fts = append(fts, ft)
+	}
+	if tot == 0 {
+		return fmt.Sprintf("ProfileReport: %v: no profile times recorded -- set Profile = true\n", nt.Nm)
+	}
+	sort.Slice(fts, func(i, j int) bool { return fts[i].tot > fts[j].tot })
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("ProfileReport: %v\n\tFunction\tLayer\tTotal Secs\tPct\n", nt.Nm))
+	for _, ft := range fts {
+		b.WriteString(fmt.Sprintf("\t%v\t\t%6.4g\t%6.4g\n", strings.TrimSpace(ft.fn), ft.tot, 100*ft.tot/tot))
+		pt := nt.ProfTimes[ft.fn]
+		for li, t := range pt {
+			if t == 0 {
+				continue
+			}
+			b.WriteString(fmt.Sprintf("\t\t%v\t%6.4g\t%6.4g\n", nt.Layers[li].Name(), t, 100*t/tot))
+		}
+	}
+	b.WriteString(fmt.Sprintf("\tTotal\t\t%6.4g\n", tot))
+	return b.String()
+}
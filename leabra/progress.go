@@ -0,0 +1,81 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+)
+
+// Progress reports the progress of training in command-line (nogui) runs,
+// instead of silence between start and finish: at the end of every
+// Interval epochs it prints a line with the run and epoch, the PctCor of
+// the epoch, the elapsed time, and the estimated time remaining (ETA) from
+// the mean duration of the epochs so far (including any testing and sleep
+// between them), and optionally writes it to a status File, replaced each
+// time, e.g., for checking on cluster jobs.  The ETA assumes that all runs
+// train for the max number of epochs.
+type Progress struct {
+	On       bool          `desc:"if true, the progress is reported"`
+	Interval int           `desc:"progress is reported every this many epochs"`
+	File     string        `desc:"if set, the last progress line is written to this status file, replacing its contents"`
+	Start    time.Time     `inactive:"+" desc:"time training started, set by Init"`
+	Epcs     int           `inactive:"+" desc:"number of epochs done since Start"`
+	EpcDur   time.Duration `inactive:"+" desc:"mean duration of the epochs done"`
+	ETA      time.Duration `inactive:"+" desc:"estimated time remaining at the last epoch"`
+	Line     string        `inactive:"+" desc:"the last progress line"`
+}
+
+func (pg *Progress) Defaults() {
+	pg.Interval = 1
+}
+
+// Init starts the timing of training, e.g., just before it starts
+func (pg *Progress) Init() {
+	pg.Start = time.Now()
+	pg.Epcs = 0
+	pg.EpcDur = 0
+	pg.ETA = 0
+	pg.Line = ""
+}
+
+// Epoch records the end of an epoch: given number of epochs done (epc) of
+// the max number (nepcs) of given run (from 0) of the max number (nruns),
+// with given proportion correct, and reports the progress if On and at
+// the Interval -- returns an error if the status File could not be written
+func (pg *Progress) Epoch(run, nruns, epc, nepcs int, pctCor float64) error {
+	if pg.Start.IsZero() {
+		pg.Init()
+	}
+	pg.Epcs++
+	elapsed := time.Since(pg.Start)
+	pg.EpcDur = elapsed / time.Duration(pg.Epcs)
+	left := (nruns-1-run)*nepcs + nepcs - epc
+	if left < 0 {
+		left = 0
+	}
+	pg.ETA = pg.EpcDur * time.Duration(left)
+	pg.Line = fmt.Sprintf("Progress: Run: %d/%d\tEpoch: %d/%d\tPctCor: %.3f\tElapsed: %v\tETA: %v\t(%v/epoch)", run, nruns, epc, nepcs, pctCor, elapsed.Round(time.Second), pg.ETA.Round(time.Second), pg.EpcDur.Round(time.Millisecond))
+	if !pg.On || (pg.Interval > 1 && pg.Epcs%pg.Interval != 0 && left > 0) {
+		return nil
+	}
+	fmt.Println(pg.Line)
+	if pg.File == "" {
+		return nil
+	}
+	// written to a temp file and renamed, so it is never seen partly written
+	tmp := pg.File + ".tmp"
+	err := ioutil.WriteFile(tmp, []byte(pg.Line+"\n"), 0644)
+	if err == nil {
+		err = os.Rename(tmp, pg.File)
+	}
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
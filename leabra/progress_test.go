@@ -0,0 +1,53 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pg := &Progress{}
+	pg.Defaults()
+	pg.On = true
+	pg.Interval = 2
+	pg.File = filepath.Join(dir, "status.txt")
+	pg.Init()
+	pg.Start = pg.Start.Add(-10 * time.Second) // as if each epoch took 5s
+	if err := pg.Epoch(0, 2, 1, 10, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(pg.File); err == nil {
+		t.Errorf("status file written before the Interval\n")
+	}
+	if err := pg.Epoch(0, 2, 2, 10, 0.75); err != nil {
+		t.Fatal(err)
+	}
+	// 18 epochs left at 5s each
+	if eta := pg.ETA.Round(time.Second); eta != 90*time.Second {
+		t.Errorf("ETA: %v, want 1m30s\n", eta)
+	}
+	b, err := ioutil.ReadFile(pg.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st := string(b); !strings.Contains(st, "Epoch: 2/10") || !strings.Contains(st, "PctCor: 0.750") || !strings.Contains(st, "ETA: 1m30s") {
+		t.Errorf("status: %v\n", st)
+	}
+	pg.Epoch(1, 2, 10, 10, 1)
+	if pg.ETA != 0 {
+		t.Errorf("ETA at the end: %v, want 0\n", pg.ETA)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+)
+
+// Provenance is the metadata for a run, saved alongside each log file (see
+// ProvenanceFile), so that saved logs remain interpretable later: the
+// command line, param sets, final applied param values, seeds and tag.
+type Provenance struct {
+	Time      string           `desc:"time the provenance was recorded, e.g., when the log was started"`
+	Model     string           `desc:"name of the network"`
+	Version   string           `desc:"leabra version and commit"`
+	Host      string           `desc:"host name of the machine"`
+	Args      []string         `desc:"command line arguments"`
+	Tag       string           `desc:"extra tag for the run, as used in the file names"`
+	ParamSets []string         `desc:"names of the param sets applied during wake, in order"`
+	SleepSets []string         `desc:"names of the param sets applied during sleep on top of the wake sets, in order"`
+	Seed      int64            `desc:"master random seed"`
+	Seeds     map[string]int64 `desc:"seeds of the named random number streams -- see Seeds"`
+	Params    ParamRecs        `desc:"final value of each param applied to each object, with the set that applied it, the value it replaced and the default"`
+}
+
+// NewProvenance returns a new Provenance for given network with the time,
+// version, host, command line and the final values of the given records of
+// applied params
+func NewProvenance(nt *Network, recs ParamRecs) *Provenance {
+	pv := &Provenance{Time: time.Now().Format(time.RFC3339), Model: nt.Nm, Version: Version + " " + GitCommit}
+	pv.Host, _ = os.Hostname()
+	pv.Args = append([]string{}, os.Args...)
+	pv.Params = recs.Final()
+	return pv
+}
+
+// SetSeeds sets the master Seed and the Seeds of all the named streams,
+// from given seeds
+func (pv *Provenance) SetSeeds(sd *Seeds) {
+	pv.Seed = sd.Master
+	pv.Seeds = make(map[string]int64)
+	for _, nm := range SeedStreams {
+		pv.Seeds[nm] = sd.Seed(nm)
+	}
+	for nm := range sd.Fixed {
+		pv.Seeds[nm] = sd.Seed(nm)
+	}
+}
+
+// ProvenanceFile returns the name of the sidecar provenance file for given
+// log file: the extension is replaced with .meta.json, e.g., Summer_Base_run.csv
+// -> Summer_Base_run.meta.json
+func ProvenanceFile(logfile string) string {
+	return strings.TrimSuffix(logfile, filepath.Ext(logfile)) + ".meta.json"
+}
+
+// SaveJSON saves the provenance to a JSON file
+func (pv *Provenance) SaveJSON(filename gi.FileName) error {
+	b, err := json.MarshalIndent(pv, "", "  ")
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// SaveSidecar saves the provenance to the sidecar file for given log file
+// -- see ProvenanceFile
+func (pv *Provenance) SaveSidecar(logfile string) error {
+	return pv.SaveJSON(gi.FileName(ProvenanceFile(logfile)))
+}
+
+// OpenJSON opens the provenance from a JSON file
+func (pv *Provenance) OpenJSON(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	err = json.Unmarshal(b, pv)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// Meta returns the provenance as key / value pairs, e.g., for the meta
+// table of an SQLite database (see export.WriteSQLite) -- the lists are joined
+// with spaces (Args) or commas, and the Params are omitted
+func (pv *Provenance) Meta() map[string]string {
+	meta := map[string]string{
+		"Time":      pv.Time,
+		"Model":     pv.Model,
+		"Version":   pv.Version,
+		"Host":      pv.Host,
+		"Args":      strings.Join(pv.Args, " "),
+		"Tag":       pv.Tag,
+		"ParamSets": strings.Join(pv.ParamSets, ","),
+		"SleepSets": strings.Join(pv.SleepSets, ","),
+		"Seed":      fmt.Sprint(pv.Seed),
+	}
+	for nm, sd := range pv.Seeds {
+		meta["Seed:"+nm] = fmt.Sprint(sd)
+	}
+	return meta
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goki/gi/gi"
+)
+
+func TestProvenance(t *testing.T) {
+	if fn := ProvenanceFile("Summer_Base_run.csv"); fn != "Summer_Base_run.meta.json" {
+		t.Errorf("ProvenanceFile: %v\n", fn)
+	}
+	net := &Network{}
+	net.InitName(net, "ProvNet")
+	recs := ParamRecs{
+		{Set: "Base", Sheet: "Network", Sel: "Layer", Obj: "Hidden", Path: "Layer.Inhib.Layer.Gi", Val: "1.8", Prev: "1.8"},
+		{Set: "Strong", Sheet: "Network", Sel: "#Hidden", Obj: "Hidden", Path: "Layer.Inhib.Layer.Gi", Val: "2.0", Prev: "1.8"},
+	}
+	pv := NewProvenance(net, recs)
+	pv.ParamSets = []string{"Base", "Strong"}
+	pv.SetSeeds(&Seeds{Master: 3, Fixed: map[string]int64{SeedWtsInit: 5}})
+	if len(pv.Params) != 1 || pv.Params[0].Val != "2.0" {
+		t.Errorf("Params should be the final values: %+v\n", pv.Params)
+	}
+	if pv.Seeds[SeedWtsInit] != 5 || len(pv.Seeds) != len(SeedStreams) {
+		t.Errorf("Seeds wrong: %v\n", pv.Seeds)
+	}
+
+	dir, err := ioutil.TempDir("", "prov")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logf := filepath.Join(dir, "ProvNet_Strong_run.csv")
+	if err := pv.SaveSidecar(logf); err != nil {
+		t.Fatal(err)
+	}
+	var rp Provenance
+	if err := rp.OpenJSON(gi.FileName(ProvenanceFile(logf))); err != nil {
+		t.Fatal(err)
+	}
+	if rp.Model != "ProvNet" || rp.Seed != 3 || len(rp.ParamSets) != 2 || rp.Params[0].Set != "Strong" {
+		t.Errorf("round trip wrong: %+v\n", rp)
+	}
+
+	mp := &Provenance{Model: "Summer", Args: []string{"summer", "-tag", "x"}, ParamSets: []string{"Base", "NoSleep"}, Seed: 7, Seeds: map[string]int64{"Env": 8}}
+	meta := mp.Meta()
+	if meta["Args"] != "summer -tag x" || meta["ParamSets"] != "Base,NoSleep" || meta["Seed"] != "7" || meta["Seed:Env"] != "8" {
+		t.Errorf("meta wrong: %v\n", meta)
+	}
+}
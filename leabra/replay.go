@@ -0,0 +1,114 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"github.com/emer/etable/etable"
+)
+
+// ReplayDecoder decodes which of a set of stored patterns (e.g., the
+// training items) the activity of a layer most resembles, e.g., on each
+// cycle of sleep, to track what is being replayed: the best-matching item,
+// the strength of the match (cosine), and transitions from one replayed
+// item to another.
+type ReplayDecoder struct {
+	Layer  string      `desc:"name of the layer whose activity is decoded"`
+	Var    string      `desc:"neuron variable that is decoded, e.g., Act"`
+	MinSim float64     `desc:"minimum cosine of the best match for it to count as a replay of that item"`
+	Names  []string    `desc:"names of the stored patterns"`
+	Pats   [][]float64 `view:"-" desc:"the stored patterns"`
+	Best   int         `inactive:"+" desc:"index of the best-matching pattern on the last Decode, -1 if none has a cosine >= MinSim"`
+	Match  float64     `inactive:"+" desc:"cosine of the best-matching pattern on the last Decode, even if < MinSim"`
+	Trans  bool        `inactive:"+" desc:"true if the last Decode replayed a different item than the one replayed before it"`
+	NTrans int         `inactive:"+" desc:"number of transitions since Reset"`
+	Counts []int       `inactive:"+" desc:"number of Decodes on which each item was replayed since Reset"`
+	last   int
+	vals   []float64
+}
+
+func (rd *ReplayDecoder) Defaults() {
+	rd.Var = "Act"
+	rd.MinSim = 0.5
+}
+
+// SetPats sets the stored patterns from given table, with the name of each
+// in nameCol and its pattern in patCol, e.g., the Output column of the
+// training patterns, or the hidden activity of each item in a test log --
+// and calls Reset
+func (rd *ReplayDecoder) SetPats(dt *etable.Table, nameCol, patCol string) error {
+	names, pats, err := tablePats(dt, nameCol, patCol)
+	if err != nil {
+		return err
+	}
+	rd.Names = names
+	rd.Pats = pats
+	rd.Reset()
+	return nil
+}
+
+// Reset resets the counts and transitions, e.g., at the start of a sleep trial
+func (rd *ReplayDecoder) Reset() {
+	rd.Best = -1
+	rd.Match = 0
+	rd.Trans = false
+	rd.NTrans = 0
+	rd.Counts = make([]int, len(rd.Names))
+	rd.last = -1
+}
+
+// Decode finds the stored pattern that best matches the current activity of
+// the layer, updating Best, Match, Trans, and the counts -- returns Best
+func (rd *ReplayDecoder) Decode(nt *Network) (int, error) {
+	ly, err := nt.LayerByNameTry(rd.Layer)
+	if err != nil {
+		return -1, err
+	}
+	uv, err := ly.UnitValsTry(rd.Var)
+	if err != nil {
+		return -1, err
+	}
+	if len(rd.vals) != len(uv) {
+		rd.vals = make([]float64, len(uv))
+	}
+	for i, v := range uv {
+		rd.vals[i] = float64(v)
+	}
+	if len(rd.Counts) != len(rd.Names) {
+		rd.Reset()
+	}
+	rd.Best = -1
+	rd.Match = 0
+	rd.Trans = false
+	bi := -1
+	for i, pat := range rd.Pats {
+		if len(pat) != len(rd.vals) {
+			continue
+		}
+		cs := repCos(rd.vals, pat, 0, 0)
+		if bi < 0 || cs > rd.Match {
+			bi = i
+			rd.Match = cs
+		}
+	}
+	if bi < 0 || rd.Match < rd.MinSim {
+		return -1, nil
+	}
+	rd.Best = bi
+	rd.Counts[bi]++
+	if rd.last >= 0 && rd.last != bi {
+		rd.Trans = true
+		rd.NTrans++
+	}
+	rd.last = bi
+	return bi, nil
+}
+
+// BestName returns the name of the item replayed on the last Decode, "" if none
+func (rd *ReplayDecoder) BestName() string {
+	if rd.Best < 0 {
+		return ""
+	}
+	return rd.Names[rd.Best]
+}
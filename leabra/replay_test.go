@@ -0,0 +1,55 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestReplayDecoder(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "ReplayNet")
+	lay := net.AddLayer2D("Output", 1, 3, emer.Target)
+	net.Defaults()
+	net.Build()
+	ly := lay.(*Layer)
+
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Output", etensor.FLOAT32, []int{1, 3}, nil},
+	}, 3)
+	for row, nm := range []string{"a", "b", "c"} {
+		dt.SetCellString("Name", row, nm)
+		dt.CellTensor("Output", row).SetFloat1D(row, 1)
+	}
+	rd := &ReplayDecoder{}
+	rd.Defaults()
+	rd.Layer = "Output"
+	rd.MinSim = 0.7
+	if err := rd.SetPats(dt, "Name", "Output"); err != nil {
+		t.Fatal(err)
+	}
+	acts := [][]float32{{0.9, 0.1, 0}, {0.8, 0, 0.2}, {0.3, 0.3, 0.3}, {0, 0.1, 0.9}}
+	want := []string{"a", "a", "", "c"}
+	for c, act := range acts {
+		for ni := range ly.Neurons {
+			ly.Neurons[ni].Act = act[ni]
+		}
+		if _, err := rd.Decode(net); err != nil {
+			t.Fatal(err)
+		}
+		if rd.BestName() != want[c] {
+			t.Errorf("cycle %v: replayed %q, want %q (match %v)\n", c, rd.BestName(), want[c], rd.Match)
+		}
+	}
+	if !rd.Trans || rd.NTrans != 1 || rd.Counts[0] != 2 || rd.Counts[1] != 0 {
+		t.Errorf("transitions / counts wrong: %v %v %v\n", rd.Trans, rd.NTrans, rd.Counts)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+
+	"github.com/emer/emergent/env"
+)
+
+// ReplayItem is one wake trial held in a ReplayBuf
+type ReplayItem struct {
+	Name     string               `desc:"name of the trial"`
+	Pats     map[string][]float32 `desc:"patterns of the Layers of the ReplayBuf in the trial"`
+	Salience float64              `desc:"salience of the trial: its weight in the sampling of the trials that seed sleep"`
+}
+
+// ReplayBuf is a replay buffer of the most recent Size wake trials, with a
+// salience weight for each, from which a trial is sampled (with a
+// probability proportional to its salience) to seed the activity of each
+// sleep trial, instead of sleep starting from purely random activity.  The
+// salience of a trial is Base plus ErrWt times its error (e.g., SSE) plus
+// EmoWt times the total activity of its EmoLayers (e.g., Ne and Po).
+type ReplayBuf struct {
+	On        bool         `desc:"if true, wake trials are stored and seed sleep"`
+	Size      int          `desc:"number of the most recent wake trials held"`
+	Layers    []string     `desc:"layers whose patterns are stored and seed the sleep activity"`
+	EmoLayers []string     `desc:"layers whose activity in a trial is its emotional value, e.g., the BLA inputs Ne and Po"`
+	Base      float64      `min:"0" desc:"baseline salience of every trial"`
+	ErrWt     float64      `desc:"weight of the error of a trial in its salience"`
+	EmoWt     float64      `desc:"weight of the emotional value of a trial in its salience"`
+	Mix       float32      `min:"0" max:"1" desc:"proportion of the initial sleep activity of the units of the Layers from the seed pattern -- the rest is the random activity"`
+	Items     []ReplayItem `view:"-" desc:"the stored trials, a ring buffer"`
+	Next      int          `inactive:"+" desc:"index of Items where the next trial is stored once full"`
+	Seeded    string       `inactive:"+" desc:"name of the trial that seeded the last sleep trial"`
+}
+
+func (rb *ReplayBuf) Defaults() {
+	rb.Size = 100
+	rb.Layers = []string{"Input", "Ne", "Po"}
+	rb.EmoLayers = []string{"Ne", "Po"}
+	rb.Base = 0.1
+	rb.ErrWt = 1
+	rb.EmoWt = 1
+	rb.Mix = 1
+}
+
+// Reset empties the buffer
+func (rb *ReplayBuf) Reset() {
+	rb.Items = nil
+	rb.Next = 0
+	rb.Seeded = ""
+}
+
+// Add stores the current trial of given environment, with given name and
+// error, replacing the oldest trial once the buffer is full
+func (rb *ReplayBuf) Add(name string, ev env.Env, err float64) {
+	it := ReplayItem{Name: name, Pats: make(map[string][]float32, len(rb.Layers))}
+	for _, lnm := range rb.Layers {
+		pat := ev.State(lnm)
+		if pat == nil {
+			continue
+		}
+		vals := make([]float32, pat.Len())
+		for i := range vals {
+			vals[i] = float32(pat.FloatVal1D(i))
+		}
+		it.Pats[lnm] = vals
+	}
+	emo := 0.0
+	for _, lnm := range rb.EmoLayers {
+		if pat := ev.State(lnm); pat != nil {
+			for i := 0; i < pat.Len(); i++ {
+				emo += pat.FloatVal1D(i)
+			}
+		}
+	}
+	it.Salience = rb.Base + rb.ErrWt*err + rb.EmoWt*emo
+	if it.Salience < 0 {
+		it.Salience = 0
+	}
+	if len(rb.Items) < rb.Size {
+		rb.Items = append(rb.Items, it)
+		return
+	}
+	if rb.Size <= 0 {
+		return
+	}
+	rb.Items[rb.Next] = it
+	rb.Next = (rb.Next + 1) % rb.Size
+}
+
+// Sample returns the index of a stored trial sampled with a probability
+// proportional to its salience (uniformly if all are 0), using given random
+// number stream -- -1 if empty
+func (rb *ReplayBuf) Sample(rnd *rand.Rand) int {
+	if len(rb.Items) == 0 {
+		return -1
+	}
+	sum := 0.0
+	for _, it := range rb.Items {
+		sum += it.Salience
+	}
+	if sum <= 0 {
+		return rnd.Intn(len(rb.Items))
+	}
+	r := rnd.Float64() * sum
+	for i, it := range rb.Items {
+		r -= it.Salience
+		if r < 0 {
+			return i
+		}
+	}
+	return len(rb.Items) - 1
+}
+
+// Seed sets the activity of the units of the Layers of given network from
+// a trial sampled from the buffer using given random number stream, mixed
+// with their current (e.g., random) activity by Mix -- call after the sleep
+// activity is initialized.  The Layers are seeded in order, and those that
+// are not in the network (or not stored in the trial) are skipped.
+func (rb *ReplayBuf) Seed(nt *Network, rnd *rand.Rand) error {
+	idx := rb.Sample(rnd)
+	if idx < 0 {
+		err := fmt.Errorf("ReplayBuf: no wake trials stored to seed sleep")
+		log.Println(err)
+		return err
+	}
+	it := &rb.Items[idx]
+	for _, lnm := range rb.Layers {
+		pat, ok := it.Pats[lnm]
+		if !ok {
+			continue
+		}
+		ly := nt.LayerByName(lnm)
+		if ly == nil {
+			continue
+		}
+		lay := ly.(LeabraLayer).AsLeabra()
+		for ni := range lay.Neurons {
+			nrn := &lay.Neurons[ni]
+			if nrn.IsOff() || ni >= len(pat) {
+				continue
+			}
+			nrn.Act = rb.Mix*pat[ni] + (1-rb.Mix)*nrn.Act
+		}
+	}
+	rb.Seeded = it.Name
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestReplayBuf(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Input", etensor.FLOAT32, []int{2, 2}, nil},
+		{"Ne", etensor.FLOAT32, []int{1, 1}, nil},
+		{"Po", etensor.FLOAT32, []int{1, 1}, nil},
+	}, 4)
+	for row := 0; row < 4; row++ {
+		dt.SetCellString("Name", row, fmt.Sprintf("p%d", row))
+		dt.CellTensor("Input", row).SetFloat1D(row, 1)
+	}
+	dt.CellTensor("Ne", 3).SetFloat1D(0, 1) // p3 is emotional
+	ev := &env.FixedTable{}
+	ev.Nm = "Wake"
+	ev.Table = etable.NewIdxView(dt)
+	ev.Sequential = true
+	ev.Init(0)
+
+	rb := &ReplayBuf{On: true}
+	rb.Defaults()
+	rb.Size = 3
+	rb.Base = 0
+	rb.ErrWt = 0
+	for row := 0; row < 4; row++ {
+		ev.Step()
+		rb.Add(ev.TrialName, ev, 0)
+	}
+	if len(rb.Items) != 3 || rb.Items[0].Name != "p3" || rb.Items[1].Name != "p1" {
+		t.Fatalf("oldest trial not replaced: %v %v\n", rb.Items[0].Name, rb.Items[1].Name)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		if idx := rb.Sample(rnd); rb.Items[idx].Name != "p3" {
+			t.Errorf("sampled %v without salience\n", rb.Items[idx].Name)
+		}
+	}
+
+	net := &Network{}
+	net.InitName(net, "ReplayNet")
+	net.AddLayer2D("Input", 2, 2, emer.Input)
+	net.Defaults()
+	net.Build()
+	if err := rb.Seed(net, rnd); err != nil {
+		t.Fatal(err)
+	}
+	ly := net.LayerByName("Input").(*Layer)
+	if rb.Seeded != "p3" || ly.Neurons[3].Act != 1 || ly.Neurons[0].Act != 0 {
+		t.Errorf("sleep not seeded with p3: %v\n", rb.Seeded)
+	}
+	rb.Reset()
+	if rb.Seed(net, rnd) == nil {
+		t.Errorf("expected error for empty buffer\n")
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+// Distance metrics for RepSpace MDS
+const (
+	// RepEuclidean is the Euclidean distance
+	RepEuclidean = "Euclidean"
+
+	// RepCorrelation is 1 - the correlation
+	RepCorrelation = "Correlation"
+
+	// RepCosine is 1 - the cosine
+	RepCosine = "Cosine"
+)
+
+// RepSpace records the representations of a set of patterns in a layer
+// (e.g., hidden-layer ActM for each test item) at successive stages (e.g.,
+// each test epoch, before and after sleep), and projects all of them into a
+// common 2D space with PCA or MDS, so the trajectory of each pattern shows
+// how learning and sleep reorganize the representational space.
+type RepSpace struct {
+	MDS    bool        `desc:"use classical multidimensional scaling (MDS) of the Metric distances, instead of PCA -- MDS with Euclidean distances is the same as PCA"`
+	Metric string      `viewif:"MDS" desc:"distance metric for MDS: Euclidean, Correlation (1 - correlation) or Cosine (1 - cosine)"`
+	Stages []string    `desc:"label of each stage, e.g., Epoch 5"`
+	Names  []string    `desc:"name of the pattern for each recorded representation"`
+	Stage  []int       `desc:"stage index for each recorded representation"`
+	Data   [][]float64 `view:"-" desc:"recorded representations, one row per pattern per stage"`
+	VarExp []float64   `inactive:"+" desc:"proportion of the variance explained by each of the 2 dimensions in the last Project"`
+}
+
+// Reset removes all the recorded representations
+func (rs *RepSpace) Reset() {
+	rs.Stages = nil
+	rs.Names = nil
+	rs.Stage = nil
+	rs.Data = nil
+	rs.VarExp = nil
+}
+
+// AddStage records the representations of given named patterns, as one
+// stage with given label
+func (rs *RepSpace) AddStage(label string, names []string, acts [][]float32) error {
+	if len(names) != len(acts) {
+		err := fmt.Errorf("RepSpace AddStage: %v names for %v patterns", len(names), len(acts))
+		log.Println(err)
+		return err
+	}
+	si := len(rs.Stages)
+	rs.Stages = append(rs.Stages, label)
+	for i, act := range acts {
+		if len(rs.Data) > 0 && len(act) != len(rs.Data[0]) {
+			err := fmt.Errorf("RepSpace AddStage: pattern: %v has %v values, want %v", names[i], len(act), len(rs.Data[0]))
+			log.Println(err)
+			return err
+		}
+		vs := make([]float64, len(act))
+		for j, v := range act {
+			vs[j] = float64(v)
+		}
+		rs.Names = append(rs.Names, names[i])
+		rs.Stage = append(rs.Stage, si)
+		rs.Data = append(rs.Data, vs)
+	}
+	return nil
+}
+
+// AddTable records the representations in the tensor column actCol of each
+// row of given table, named by the string column nameCol, e.g., the
+// TstTrlLog, as one stage with given label
+func (rs *RepSpace) AddTable(label string, dt *etable.Table, nameCol, actCol string) error {
+	if dt.ColByName(nameCol) == nil || dt.ColByName(actCol) == nil {
+		err := fmt.Errorf("RepSpace AddTable: columns: %v, %v not found in table", nameCol, actCol)
+		log.Println(err)
+		return err
+	}
+	names := make([]string, dt.Rows)
+	acts := make([][]float32, dt.Rows)
+	for row := 0; row < dt.Rows; row++ {
+		names[row] = dt.CellString(nameCol, row)
+		ct := dt.CellTensor(actCol, row)
+		acts[row] = make([]float32, ct.Len())
+		for i := range acts[row] {
+			acts[row][i] = float32(ct.FloatVal1D(i))
+		}
+	}
+	return rs.AddStage(label, names, acts)
+}
+
+// Project projects all the recorded representations into 2D, returning a
+// table with one row per pattern per stage, ordered by pattern and then
+// stage so each pattern's rows are its trajectory: Name, Stage, StageIdx, X, Y
+func (rs *RepSpace) Project() (*etable.Table, error) {
+	n := len(rs.Data)
+	if n < 2 {
+		err := fmt.Errorf("RepSpace Project: need at least 2 representations, have %v", n)
+		log.Println(err)
+		return nil, err
+	}
+	var b [][]float64
+	if rs.MDS {
+		b = mdsMatrix(rs.distances())
+	} else {
+		b = gramMatrix(rs.Data)
+	}
+	vals, vecs := symEigen(b)
+	tot := 0.0
+	for _, v := range vals {
+		if v > 0 {
+			tot += v
+		}
+	}
+	rs.VarExp = make([]float64, 2)
+	coords := make([][2]float64, n)
+	for d := 0; d < 2 && d < n; d++ {
+		lv := math.Max(vals[d], 0)
+		if tot > 0 {
+			rs.VarExp[d] = lv / tot
+		}
+		sl := math.Sqrt(lv)
+		for i := 0; i < n; i++ {
+			coords[i][d] = sl * vecs[d][i]
+		}
+	}
+
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		if rs.Names[idx[i]] != rs.Names[idx[j]] {
+			return rs.Names[idx[i]] < rs.Names[idx[j]]
+		}
+		return rs.Stage[idx[i]] < rs.Stage[idx[j]]
+	})
+	dt := &etable.Table{}
+	dt.SetMetaData("name", "RepSpace")
+	dt.SetMetaData("desc", "2D projection of the representations of each pattern at each stage")
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Stage", etensor.STRING, nil, nil},
+		{"StageIdx", etensor.INT64, nil, nil},
+		{"X", etensor.FLOAT64, nil, nil},
+		{"Y", etensor.FLOAT64, nil, nil},
+	}, n)
+	for row, i := range idx {
+		dt.SetCellString("Name", row, rs.Names[i])
+		dt.SetCellString("Stage", row, rs.Stages[rs.Stage[i]])
+		dt.SetCellFloat("StageIdx", row, float64(rs.Stage[i]))
+		dt.SetCellFloat("X", row, coords[i][0])
+		dt.SetCellFloat("Y", row, coords[i][1])
+	}
+	return dt, nil
+}
+
+// SaveCSV projects the representations (see Project) and saves the table to
+// given file
+func (rs *RepSpace) SaveCSV(filename gi.FileName) error {
+	dt, err := rs.Project()
+	if err != nil {
+		return err
+	}
+	err = dt.SaveCSV(filename, etable.Tab, true)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// distances returns the matrix of Metric distances between all the
+// recorded representations
+func (rs *RepSpace) distances() [][]float64 {
+	n := len(rs.Data)
+	d := make([][]float64, n)
+	for i := range d {
+		d[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			dv := repDist(rs.Metric, rs.Data[i], rs.Data[j])
+			d[i][j] = dv
+			d[j][i] = dv
+		}
+	}
+	return d
+}
+
+// repDist returns the distance between a and b for given metric, Euclidean
+// by default
+func repDist(metric string, a, b []float64) float64 {
+	switch metric {
+	case RepCorrelation:
+		ma, mb := 0.0, 0.0
+		for i := range a {
+			ma += a[i]
+			mb += b[i]
+		}
+		ma /= float64(len(a))
+		mb /= float64(len(b))
+		return 1 - repCos(a, b, ma, mb)
+	case RepCosine:
+		return 1 - repCos(a, b, 0, 0)
+	}
+	ss := 0.0
+	for i := range a {
+		d := a[i] - b[i]
+		ss += d * d
+	}
+	return math.Sqrt(ss)
+}
+
+// repCos returns the cosine between a and b after subtracting given means
+func repCos(a, b []float64, ma, mb float64) float64 {
+	ab, aa, bb := 0.0, 0.0, 0.0
+	for i := range a {
+		av := a[i] - ma
+		bv := b[i] - mb
+		ab += av * bv
+		aa += av * av
+		bb += bv * bv
+	}
+	if aa == 0 || bb == 0 {
+		return 0
+	}
+	return ab / math.Sqrt(aa*bb)
+}
+
+// gramMatrix returns the Gram matrix of the column-centered data, whose
+// eigenvectors scaled by the square root of the eigenvalues are the PCA
+// scores of the rows
+func gramMatrix(data [][]float64) [][]float64 {
+	n := len(data)
+	nd := len(data[0])
+	mean := make([]float64, nd)
+	for _, r := range data {
+		for j, v := range r {
+			mean[j] += v
+		}
+	}
+	for j := range mean {
+		mean[j] /= float64(n)
+	}
+	g := make([][]float64, n)
+	for i := range g {
+		g[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for k := i; k < n; k++ {
+			s := 0.0
+			for j := 0; j < nd; j++ {
+				s += (data[i][j] - mean[j]) * (data[k][j] - mean[j])
+			}
+			g[i][k] = s
+			g[k][i] = s
+		}
+	}
+	return g
+}
+
+// mdsMatrix returns the double-centered matrix of squared distances for
+// classical MDS: B = -1/2 J D^2 J
+func mdsMatrix(d [][]float64) [][]float64 {
+	n := len(d)
+	rm := make([]float64, n)
+	tm := 0.0
+	b := make([][]float64, n)
+	for i := range b {
+		b[i] = make([]float64, n)
+		for j := range b[i] {
+			b[i][j] = d[i][j] * d[i][j]
+			rm[i] += b[i][j]
+		}
+		tm += rm[i]
+		rm[i] /= float64(n)
+	}
+	tm /= float64(n * n)
+	for i := range b {
+		for j := range b[i] {
+			b[i][j] = -0.5 * (b[i][j] - rm[i] - rm[j] + tm)
+		}
+	}
+	return b
+}
+
+// symEigen returns the eigenvalues of symmetric matrix a, in descending
+// order, and the corresponding eigenvectors (vecs[k] is the k-th), using
+// the cyclic Jacobi method.  The sign of each eigenvector is set so its
+// largest magnitude element is positive, for reproducible projections.
+func symEigen(a [][]float64) ([]float64, [][]float64) {
+	n := len(a)
+	m := make([][]float64, n)
+	v := make([][]float64, n)
+	for i := range m {
+		m[i] = append([]float64{}, a[i]...)
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+	for sweep := 0; sweep < 100; sweep++ {
+		off, diag := 0.0, 0.0
+		for p := 0; p < n; p++ {
+			diag += m[p][p] * m[p][p]
+			for q := p + 1; q < n; q++ {
+				off += m[p][q] * m[p][q]
+			}
+		}
+		if off <= 1e-24*(diag+1e-300) {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if m[p][q] == 0 {
+					continue
+				}
+				theta := (m[q][q] - m[p][p]) / (2 * m[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < n; k++ {
+					mkp, mkq := m[k][p], m[k][q]
+					m[k][p] = c*mkp - s*mkq
+					m[k][q] = s*mkp + c*mkq
+				}
+				for k := 0; k < n; k++ {
+					mpk, mqk := m[p][k], m[q][k]
+					m[p][k] = c*mpk - s*mqk
+					m[q][k] = s*mpk + c*mqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return m[idx[i]][idx[i]] > m[idx[j]][idx[j]] })
+	vals := make([]float64, n)
+	vecs := make([][]float64, n)
+	for k, i := range idx {
+		vals[k] = m[i][i]
+		vec := make([]float64, n)
+		mx := 0.0
+		for r := 0; r < n; r++ {
+			vec[r] = v[r][i]
+			if math.Abs(vec[r]) > math.Abs(mx) {
+				mx = vec[r]
+			}
+		}
+		if mx < 0 {
+			for r := range vec {
+				vec[r] = -vec[r]
+			}
+		}
+		vecs[k] = vec
+	}
+	return vals, vecs
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRepSpace(t *testing.T) {
+	rs := &RepSpace{}
+	names := []string{"b", "a"}
+	// a and b start out overlapping and separate along the first unit
+	rs.AddStage("Epoch 0", names, [][]float32{{0.6, 0.5, 0}, {0.4, 0.5, 0}})
+	rs.AddStage("Epoch 5", names, [][]float32{{1, 0.5, 0}, {0, 0.5, 0}})
+	if err := rs.AddStage("Bad", names, [][]float32{{1}}); err == nil {
+		t.Errorf("expected error for mismatched patterns\n")
+	}
+	for _, mds := range []bool{false, true} {
+		rs.MDS = mds
+		dt, err := rs.Project()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dt.Rows != 4 || dt.CellString("Name", 0) != "a" || dt.CellString("Stage", 1) != "Epoch 5" {
+			t.Errorf("MDS %v: rows not in trajectory order\n", mds)
+		}
+		ax0, ax5 := dt.CellFloat("X", 0), dt.CellFloat("X", 1)
+		bx5 := dt.CellFloat("X", 3)
+		if math.Abs(bx5-ax5) < 0.99 || math.Abs(ax0) > math.Abs(ax5) {
+			t.Errorf("MDS %v: projection wrong: a %v -> %v, b -> %v\n", mds, ax0, ax5, bx5)
+		}
+		if rs.VarExp[0] < 0.99 {
+			t.Errorf("MDS %v: all variance is along one dimension: %v\n", mds, rs.VarExp)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/emer/emergent/prjn"
+)
+
+// Rewire re-runs the existing connectivity pattern (Pat) on this projection and
+// rebuilds its connections, preserving the full synaptic state of every
+// connection that survives in the new pattern.  Connections that are new in the
+// new pattern are initialized according to WtInit, as in InitWts.
+// This is mainly useful for patterns with a random component (e.g., prjn.UnifRnd)
+// to study structural rewiring over the course of training / sleep.
+// Returns the number of connections kept, added, and removed.
+func (pj *Prjn) Rewire() (kept, added, removed int, err error) {
+	return pj.RewirePat(pj.Pat)
+}
+
+// RewirePat sets the connectivity pattern for this projection to given pattern,
+// and rebuilds its connections, preserving the full synaptic state of every
+// connection that exists in both the old and new patterns.  Connections that are
+// new in the new pattern are initialized according to WtInit, by the InitWts and
+// InitSdEffWt of the LeabraPrj, so that derived projection types initialize
+// them as usual.  The receiving and sending layers must already be built, and their shapes are
+// not changed.  Returns the number of connections kept, added, and removed.
+func (pj *Prjn) RewirePat(pat prjn.Pattern) (kept, added, removed int, err error) {
+	if pat == nil {
+		err = fmt.Errorf("leabra.Prjn RewirePat: %v new pattern is nil", pj.String())
+		log.Println(err)
+		return
+	}
+	slay := pj.Send.(LeabraLayer).AsLeabra()
+	ns := len(slay.Neurons)
+
+	// save existing synapses, keyed by recv * nsend + send
+	old := make(map[int]Synapse, pj.Syns.Len())
+	for si := 0; si < len(pj.SConN) && si < ns; si++ {
+		st := pj.SConIdxSt[si]
+		syns := pj.Syns.Range(st, pj.SConN[si])
+		for ci := range syns {
+			ri := int(pj.SConIdx[st+int64(ci)])
+			old[ri*ns+si] = syns[ci]
+		}
+	}
+	wbs := pj.WbRecv
+
+	pj.Pat = pat
+	if err = pj.Build(); err != nil {
+		log.Println(err)
+		return
+	}
+	// all are initialized, then the kept ones restored
+	pj.LeabraPrj.InitWts()
+	pj.LeabraPrj.InitSdEffWt()
+	if len(wbs) == len(pj.WbRecv) {
+		copy(pj.WbRecv, wbs)
+	}
+
+	for si := 0; si < ns; si++ {
+		st := pj.SConIdxSt[si]
+		syns := pj.Syns.Range(st, pj.SConN[si])
+		for ci := range syns {
+			ri := int(pj.SConIdx[st+int64(ci)])
+			if osy, has := old[ri*ns+si]; has {
+				syns[ci] = osy
+				kept++
+				continue
+			}
+			added++
+		}
+	}
+	removed = len(old) - kept
+	pj.LeabraPrj.InitGInc()
+	return
+}
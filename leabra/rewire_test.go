@@ -0,0 +1,62 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestRewire(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "RewireNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	net.InitWts()
+	pj := net.PrjnByName("InputToHidden")
+	for i := 0; i < 4; i++ {
+		pj.SetSynVal("Wt", i, i, 0.1*float32(i+1))
+		pj.SetSynVal("Cai", i, i, 0.5)
+	}
+
+	kept, added, removed, err := pj.RewirePat(prjn.NewOneToOne())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept != 4 || added != 0 || removed != 12 {
+		t.Errorf("to OneToOne: kept %v added %v removed %v, want 4 0 12\n", kept, added, removed)
+	}
+	if pj.Syns.Len() != 4 {
+		t.Errorf("synapses: %v, want 4\n", pj.Syns.Len())
+	}
+
+	kept, added, removed, err = pj.RewirePat(prjn.NewFull())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept != 4 || added != 12 || removed != 0 {
+		t.Errorf("to Full: kept %v added %v removed %v, want 4 12 0\n", kept, added, removed)
+	}
+	for i := 0; i < 4; i++ {
+		if wt := pj.SynVal("Wt", i, i); wt != 0.1*float32(i+1) {
+			t.Errorf("kept synapse %d Wt: %v, want %v\n", i, wt, 0.1*float32(i+1))
+		}
+		if cai := pj.SynVal("Cai", i, i); cai != 0.5 {
+			t.Errorf("kept synapse %d Cai: %v, want 0.5\n", i, cai)
+		}
+	}
+	// added synapses are initialized, including their Effwt
+	if cai, eff, wt := pj.SynVal("Cai", 0, 1), pj.SynVal("Effwt", 0, 1), pj.SynVal("Wt", 0, 1); cai != 0 || eff != wt {
+		t.Errorf("added synapse: Cai %v Effwt %v Wt %v\n", cai, eff, wt)
+	}
+	if _, _, _, err := pj.RewirePat(nil); err == nil {
+		t.Errorf("expected error for a nil pattern\n")
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/emer/etable/etensor"
+)
+
+// synRFVal returns the value of given variable for receptive field display --
+// uses direct field access for the weight variables
+func synRFVal(sy *Synapse, varNm string) float32 {
+	switch varNm {
+	case "Wt":
+		return sy.Wt
+	case "LWt":
+		return sy.LWt
+	case "DWt":
+		return sy.DWt
+	case "Effwt":
+		return sy.Effwt
+	}
+	v, _ := sy.VarByName(varNm)
+	return v
+}
+
+// RecvFields returns the receptive fields of the given receiving units (flat 1D
+// indexes) for given synaptic variable (e.g., Wt, LWt, Effwt), as a tensor with
+// the first dimension over the units, and the remaining dimensions in the shape
+// of the sending layer, so each sub-tensor shows the weights in the sending
+// layer's geometry.  Sending units that are not connected are set to NaN.
+func (pj *Prjn) RecvFields(ris []int, varNm string) (*etensor.Float32, error) {
+	slay := pj.Send.(LeabraLayer).AsLeabra()
+	nr := len(pj.RConN)
+	ns := len(slay.Neurons)
+	shp := append([]int{len(ris)}, slay.Shp.Shapes()...)
+	nms := append([]string{"Unit"}, slay.Shp.DimNames()...)
+	tsr := etensor.NewFloat32(shp, nil, nms)
+	nan := float32(math.NaN())
+	for i := range tsr.Values {
+		tsr.Values[i] = nan
+	}
+	for ui, ri := range ris {
+		if ri < 0 || ri >= nr {
+			err := fmt.Errorf("Prjn.RecvFields: %v recv unit index %v is out of range of recv layer size: %v", pj.String(), ri, nr)
+			log.Println(err)
+			return nil, err
+		}
+		nc := int(pj.RConN[ri])
+		st := int(pj.RConIdxSt[ri])
+		for ci := 0; ci < nc; ci++ {
+			si := int(pj.RConIdx[st+ci])
+			tsr.Values[ui*ns+si] = synRFVal(pj.Syns.At(pj.RSynIdx[st+ci]), varNm)
+		}
+	}
+	return tsr, nil
+}
+
+// RecvFields returns the receptive fields of the given receiving units (flat 1D
+// indexes, all units if nil) in the recv layer, from the send layer, for given
+// synaptic variable (e.g., Wt, LWt, Effwt) -- see Prjn.RecvFields for details.
+func (nt *Network) RecvFields(recv, send string, ris []int, varNm string) (*etensor.Float32, error) {
+	rly, err := nt.LayerByNameTry(recv)
+	if err != nil {
+		return nil, err
+	}
+	p := rly.RecvPrjns().SendName(send)
+	if p == nil {
+		err := fmt.Errorf("Network.RecvFields: projection from: %v to: %v not found", send, recv)
+		log.Println(err)
+		return nil, err
+	}
+	pj := p.(LeabraPrjn).AsLeabra()
+	if ris == nil {
+		ris = make([]int, len(pj.RConN))
+		for i := range ris {
+			ris[i] = i
+		}
+	}
+	return pj.RecvFields(ris, varNm)
+}
@@ -0,0 +1,285 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"hash/fnv"
+	"log"
+	"math"
+	"math/rand"
+	"strings"
+
+	"github.com/emer/emergent/erand"
+)
+
+// LayerRndSeed returns the seed for the random number stream of the layer
+// with given name, derived from the network-level seed.  The seed depends only
+// on the layer name, so it is the same regardless of layer order or threading.
+func LayerRndSeed(seed int64, name string) int64 {
+	return StreamSeed(seed, name)
+}
+
+// StreamSeed returns the seed for a named random number stream (e.g., a layer
+// or projection name, or a stream name in Seeds), derived from given seed --
+// different names give independent streams from the same seed.
+func StreamSeed(seed int64, name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return seed ^ int64(h.Sum64())
+}
+
+// SeedLayerRnd gives each layer its own random number stream (Layer.Rnd),
+// seeded from given seed and the layer name, which is used for all noise
+// generation, so that results are bit-reproducible regardless of the number
+// of threads (and splitting of neuron loops) -- the global random number
+// generator is otherwise used, in which case results depend on the order
+// in which the threads run.  Call this again to re-seed, e.g., at the start
+// of each run.
+func (nt *Network) SeedLayerRnd(seed int64) {
+	nt.LayRnd = true
+	nt.LayRndSeed = seed
+	for _, ly := range nt.Layers {
+		lly := ly.(LeabraLayer).AsLeabra()
+		lly.rndSrc = NewRndSource(LayerRndSeed(seed, lly.Nm))
+		lly.Rnd = rand.New(lly.rndSrc)
+	}
+}
+
+// ClearLayerRnd removes the per-layer random number streams, so that the
+// global random number generator is used for noise.
+func (nt *Network) ClearLayerRnd() {
+	nt.LayRnd = false
+	for _, ly := range nt.Layers {
+		lly := ly.(LeabraLayer).AsLeabra()
+		lly.Rnd, lly.rndSrc = nil, nil
+	}
+}
+
+// SeedPrjnRnd gives each projection its own random number stream (Prjn.Rnd),
+// seeded from given seed and the projection name, which is used for the
+// initial weights, so that they are independent of the noise and of any
+// other use of the global random number generator.  Call this again to
+// re-seed, e.g., at the start of each run, before InitWts.
+func (nt *Network) SeedPrjnRnd(seed int64) {
+	nt.PrjnRnd = true
+	nt.PrjnRndSeed = seed
+	for _, ly := range nt.Layers {
+		for _, p := range *ly.RecvPrjns() {
+			pj := p.(LeabraPrjn).AsLeabra()
+			pj.rndSrc = NewRndSource(StreamSeed(seed, pj.Name()))
+			pj.Rnd = rand.New(pj.rndSrc)
+		}
+	}
+}
+
+// ClearPrjnRnd removes the per-projection random number streams, so that the
+// global random number generator is used for initial weights.
+func (nt *Network) ClearPrjnRnd() {
+	nt.PrjnRnd = false
+	for _, ly := range nt.Layers {
+		for _, p := range *ly.RecvPrjns() {
+			pj := p.(LeabraPrjn).AsLeabra()
+			pj.Rnd, pj.rndSrc = nil, nil
+		}
+	}
+}
+
+// RndState is the state of a RndSource: the seed it was seeded with, and
+// the number of values drawn from it since
+type RndState struct {
+	Seed   int64 `desc:"seed that the source was seeded with"`
+	NDraws int64 `desc:"number of values drawn from the source since it was seeded"`
+}
+
+// RndSource is a random number source (for rand.New) that keeps track of
+// its state as its seed and the number of values drawn from it, so that,
+// unlike the standard sources, its state can be saved, e.g., in a
+// Checkpoint, and restored or copied by SetState, which reseeds it and
+// skips the values drawn -- without changing the state it is saved from.
+type RndSource struct {
+	St  RndState `desc:"the state of the source"`
+	src rand.Source64
+}
+
+// NewRndSource returns a new RndSource seeded with given seed
+func NewRndSource(seed int64) *RndSource {
+	rs := &RndSource{src: rand.NewSource(seed).(rand.Source64)}
+	rs.St.Seed = seed
+	return rs
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer
+func (rs *RndSource) Int63() int64 {
+	rs.St.NDraws++
+	return rs.src.Int63()
+}
+
+// Uint64 returns a pseudo-random 64-bit integer
+func (rs *RndSource) Uint64() uint64 {
+	rs.St.NDraws++
+	return rs.src.Uint64()
+}
+
+// Seed reseeds the source with given seed
+func (rs *RndSource) Seed(seed int64) {
+	rs.src.Seed(seed)
+	rs.St = RndState{Seed: seed}
+}
+
+// State returns the current state of the source
+func (rs *RndSource) State() RndState {
+	return rs.St
+}
+
+// SetState sets the source to given state, by reseeding it and skipping the
+// number of values drawn
+func (rs *RndSource) SetState(st RndState) {
+	rs.Seed(st.Seed)
+	for i := int64(0); i < st.NDraws; i++ {
+		rs.src.Uint64()
+	}
+	rs.St.NDraws = st.NDraws
+}
+
+// RndStates returns the states of the per-layer and per-projection random
+// number streams in use (see SeedLayerRnd, SeedPrjnRnd), by layer: or prjn:
+// and the name of the layer or projection -- for saving, e.g., in a
+// Checkpoint, without changing them
+func (nt *Network) RndStates() map[string]RndState {
+	sts := make(map[string]RndState)
+	for _, ly := range nt.Layers {
+		lly := ly.(LeabraLayer).AsLeabra()
+		if lly.rndSrc != nil {
+			sts["layer:"+lly.Nm] = lly.rndSrc.State()
+		}
+		for _, p := range lly.RcvPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			if pj.rndSrc != nil {
+				sts["prjn:"+pj.Name()] = pj.rndSrc.State()
+			}
+		}
+	}
+	return sts
+}
+
+// SetRndStates sets the per-layer and per-projection random number streams
+// to given states, as returned by RndStates, creating the streams as needed
+// -- those not in the states are left as they are
+func (nt *Network) SetRndStates(sts map[string]RndState) {
+	for nm := range sts {
+		if strings.HasPrefix(nm, "layer:") {
+			nt.LayRnd = true
+		} else if strings.HasPrefix(nm, "prjn:") {
+			nt.PrjnRnd = true
+		}
+	}
+	for _, ly := range nt.Layers {
+		lly := ly.(LeabraLayer).AsLeabra()
+		if st, has := sts["layer:"+lly.Nm]; has {
+			if lly.rndSrc == nil {
+				lly.rndSrc = NewRndSource(st.Seed)
+				lly.Rnd = rand.New(lly.rndSrc)
+			}
+			lly.rndSrc.SetState(st)
+		}
+		for _, p := range lly.RcvPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			if st, has := sts["prjn:"+pj.Name()]; has {
+				if pj.rndSrc == nil {
+					pj.rndSrc = NewRndSource(st.Seed)
+					pj.Rnd = rand.New(pj.rndSrc)
+				}
+				pj.rndSrc.SetState(st)
+			}
+		}
+	}
+}
+
+// RndGen generates a random value from given distribution params using given
+// random number stream, with the same parameterization as erand.RndParams.Gen
+// (see erand.RndDists): Binomial is the number of successes in Par trials of
+// probability Var, Poisson has rate Var, Gamma has scale Var and integer shape
+// Par, and Beta has shapes Var and Par -- each plus Mean.  If rnd is nil, the
+// global random number generator is used, via erand.RndParams.Gen.  An invalid
+// distribution is logged and gives the Mean.
+func RndGen(rp *erand.RndParams, rnd *rand.Rand) float64 {
+	if rnd == nil {
+		return rp.Gen(-1)
+	}
+	switch rp.Dist {
+	case erand.Uniform:
+		return rp.Mean + rp.Var*2*(rnd.Float64()-0.5)
+	case erand.Gaussian:
+		return rp.Mean + rp.Var*rnd.NormFloat64()
+	case erand.Binomial:
+		n := 0
+		for i := 0; i < int(rp.Par); i++ {
+			if rnd.Float64() < rp.Var {
+				n++
+			}
+		}
+		return rp.Mean + float64(n)
+	case erand.Poisson:
+		return rp.Mean + rndPoisson(rnd, rp.Var)
+	case erand.Gamma:
+		return rp.Mean + rp.Var*rndGamma(rnd, float64(int(rp.Par)))
+	case erand.Beta:
+		x := rndGamma(rnd, rp.Var)
+		y := rndGamma(rnd, rp.Par)
+		if x+y == 0 {
+			return rp.Mean
+		}
+		return rp.Mean + x/(x+y)
+	case erand.Mean:
+		return rp.Mean
+	}
+	log.Printf("leabra.RndGen: invalid distribution: %v\n", rp.Dist)
+	return rp.Mean
+}
+
+// rndPoisson returns a Poisson variate with rate lmb, as the sum of variates
+// of rate at most 30 by Knuth's multiplication method
+func rndPoisson(rnd *rand.Rand, lmb float64) float64 {
+	n := 0
+	for lmb > 0 {
+		l := math.Min(lmb, 30)
+		lmb -= l
+		g := math.Exp(-l)
+		for p := rnd.Float64(); p > g; p *= rnd.Float64() {
+			n++
+		}
+	}
+	return float64(n)
+}
+
+// rndGamma returns a Gamma variate with given shape and unit scale, by the
+// method of Marsaglia and Tsang (2000) -- 0 if shape is not positive
+func rndGamma(rnd *rand.Rand, shape float64) float64 {
+	if shape <= 0 {
+		return 0
+	}
+	if shape < 1 { // boost: Gamma(a) = Gamma(a+1) * U^(1/a)
+		return rndGamma(rnd, shape+1) * math.Pow(rnd.Float64(), 1/shape)
+	}
+	d := shape - 1.0/3.0
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rnd.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rnd.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
+
+// GenRnd generates a noise value using given random number stream -- see RndGen
+func (an *ActNoiseParams) GenRnd(rnd *rand.Rand) float32 {
+	return float32(RndGen(&an.RndParams, rnd))
+}
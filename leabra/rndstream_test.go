@@ -0,0 +1,79 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/emer/emergent/erand"
+)
+
+func TestGenNoiseOn(t *testing.T) {
+	ly := &Layer{}
+	ly.Act.Defaults()
+	ly.Act.Noise.Type = GeNoise
+	ly.Act.Noise.Dist = erand.Gaussian
+	ly.Act.Noise.Var = 0.1
+	ly.Act.Noise.Fixed = false
+	ly.Neurons = make([]Neuron, 4)
+	ly.Neurons[1].SetFlag(NeurOff)
+	ly.Rnd = rand.New(NewRndSource(3))
+
+	ly.GenNoiseOn()
+	ref := rand.New(NewRndSource(3))
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			if nrn.Noise != 0 {
+				t.Errorf("noise generated for Off neuron %v: %v\n", ni, nrn.Noise)
+			}
+			continue
+		}
+		if want := float32(0.1 * ref.NormFloat64()); nrn.Noise != want {
+			t.Errorf("neuron %v noise: %v, want %v\n", ni, nrn.Noise, want)
+		}
+	}
+
+	// GeGiFmInc still generates non-Fixed noise itself
+	nrn := &Neuron{}
+	ly.Act.GeGiFmInc(nrn)
+	if nrn.Noise == 0 {
+		t.Errorf("GeGiFmInc did not generate noise\n")
+	}
+}
+
+func TestRndGenDists(t *testing.T) {
+	rnd := rand.New(NewRndSource(1))
+	tests := []struct {
+		rp   erand.RndParams
+		mean float64
+	}{
+		{erand.RndParams{Dist: erand.Uniform, Mean: 1, Var: 0.5}, 1},
+		{erand.RndParams{Dist: erand.Gaussian, Mean: -1, Var: 2}, -1},
+		{erand.RndParams{Dist: erand.Binomial, Var: 0.3, Par: 10}, 3},
+		{erand.RndParams{Dist: erand.Poisson, Var: 4}, 4},
+		{erand.RndParams{Dist: erand.Poisson, Var: 75}, 75},
+		{erand.RndParams{Dist: erand.Gamma, Mean: 1, Var: 2, Par: 3}, 7},
+		{erand.RndParams{Dist: erand.Beta, Var: 2, Par: 3}, 0.4},
+		{erand.RndParams{Dist: erand.Beta, Var: 0.5, Par: 0.5}, 0.5},
+		{erand.RndParams{Dist: erand.Mean, Mean: 2}, 2},
+	}
+	n := 20000
+	for _, tt := range tests {
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			sum += RndGen(&tt.rp, rnd)
+		}
+		if mn := sum / float64(n); math.Abs(mn-tt.mean) > 0.02*math.Max(1, math.Abs(tt.mean)) {
+			t.Errorf("%v mean: %v, want %v\n", tt.rp.Dist, mn, tt.mean)
+		}
+	}
+	bad := erand.RndParams{Dist: erand.RndDistsN, Mean: 3}
+	if v := RndGen(&bad, rnd); v != 3 {
+		t.Errorf("invalid distribution gave %v, want the Mean 3\n", v)
+	}
+}
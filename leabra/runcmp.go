@@ -0,0 +1,268 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+// RunCompare compares the run-level stats of several conditions (e.g.,
+// ParamSets or tags), from their run logs: for each stat it reports the
+// mean, the 95% confidence interval of the mean, and the difference from the
+// baseline condition (the first added) with the p value of a Welch t-test.
+// The Table has one row per condition, with columns Stat:Mean, Stat:CILo,
+// Stat:CIHi, Stat:Diff and Stat:P for each stat, so the means and CIs can be
+// plotted against CondIdx.  The Tests table has the statistical tests of
+// each stat, one per row: Welch (unpaired) and paired t-tests of each
+// condition against the baseline, with the runs paired by their Run number
+// (i.e., the same random seeds), and a one-way ANOVA across all conditions.
+type RunCompare struct {
+	Stats    []string                            `desc:"run log columns to compare, e.g., FirstZero, PctCor, TstPctCor"`
+	GroupCol string                              `desc:"column of the run logs whose values are the conditions of the runs when no condition is given to AddRuns, e.g., Params or Tag -- Params if empty"`
+	Valid    func(stat string, val float64) bool `view:"-" desc:"if set, only values for which this returns true are included, e.g., to exclude FirstZero = -1 for runs that never learned"`
+	Conds    []string                            `inactive:"+" desc:"the conditions, in the order added -- the first is the baseline"`
+	Vals     map[string]map[string][]float64     `view:"-" desc:"values of each stat for each condition, over runs"`
+	Runs     map[string]map[string][]int         `view:"-" desc:"Run number of each of the Vals (from the Run column, or else the row), for pairing the runs of the conditions"`
+	Table    *etable.Table                       `view:"no-inline" desc:"the comparison, computed by Compute"`
+	Tests    *etable.Table                       `view:"no-inline" desc:"the statistical tests of each stat, computed by Compute: Stat, Test (Welch, Paired, ANOVA), Cond, Base, N, Value (t or F), DF1, DF2, P"`
+}
+
+// Reset removes all the conditions
+func (rc *RunCompare) Reset() {
+	rc.Conds = nil
+	rc.Vals = nil
+	rc.Runs = nil
+}
+
+// AddRuns adds the stats of each run (row) of given run log to the given
+// condition -- if cond is empty, the condition of each run is the value of
+// its GroupCol column (e.g., Params)
+func (rc *RunCompare) AddRuns(dt *etable.Table, cond string) error {
+	gcol := rc.GroupCol
+	if gcol == "" {
+		gcol = "Params"
+	}
+	if cond == "" && dt.ColByName(gcol) == nil {
+		err := fmt.Errorf("RunCompare AddRuns: no condition given and no %v column in table: %v", gcol, dt.MetaData["name"])
+		log.Println(err)
+		return err
+	}
+	for _, st := range rc.Stats {
+		if dt.ColByName(st) == nil {
+			err := fmt.Errorf("RunCompare AddRuns: stat: %v not found in table: %v", st, dt.MetaData["name"])
+			log.Println(err)
+			return err
+		}
+	}
+	if rc.Vals == nil {
+		rc.Vals = make(map[string]map[string][]float64)
+		rc.Runs = make(map[string]map[string][]int)
+	}
+	hasRun := dt.ColByName("Run") != nil
+	for row := 0; row < dt.Rows; row++ {
+		cnd := cond
+		if cnd == "" {
+			cnd = dt.CellString(gcol, row)
+		}
+		run := row
+		if hasRun {
+			run = int(dt.CellFloat("Run", row))
+		}
+		cv, has := rc.Vals[cnd]
+		if !has {
+			cv = make(map[string][]float64)
+			rc.Vals[cnd] = cv
+			rc.Runs[cnd] = make(map[string][]int)
+			rc.Conds = append(rc.Conds, cnd)
+		}
+		cr := rc.Runs[cnd]
+		for _, st := range rc.Stats {
+			v := dt.CellFloat(st, row)
+			if math.IsNaN(v) || (rc.Valid != nil && !rc.Valid(st, v)) {
+				continue
+			}
+			cv[st] = append(cv[st], v)
+			cr[st] = append(cr[st], run)
+		}
+	}
+	return nil
+}
+
+// OpenRunLog adds the runs of the run log saved (with headers) in given
+// tab-separated file to given condition -- see AddRuns
+func (rc *RunCompare) OpenRunLog(filename gi.FileName, cond string) error {
+	dt := &etable.Table{}
+	dt.SetMetaData("name", string(filename))
+	if err := dt.OpenCSV(filename, etable.Tab); err != nil {
+		log.Println(err)
+		return err
+	}
+	return rc.AddRuns(dt, cond)
+}
+
+// Paired returns the values of given stat for given condition and the
+// baseline (the first condition) for the runs with the same Run number in
+// both, in the order of the condition
+func (rc *RunCompare) Paired(cond, stat string) (vals, base []float64) {
+	if len(rc.Conds) == 0 {
+		return nil, nil
+	}
+	bcnd := rc.Conds[0]
+	bidx := make(map[int]int)
+	for i, run := range rc.Runs[bcnd][stat] {
+		bidx[run] = i
+	}
+	for i, run := range rc.Runs[cond][stat] {
+		if bi, has := bidx[run]; has {
+			vals = append(vals, rc.Vals[cond][stat][i])
+			base = append(base, rc.Vals[bcnd][stat][bi])
+		}
+	}
+	return
+}
+
+// Compute computes the comparison Table, and the Tests
+func (rc *RunCompare) Compute() *etable.Table {
+	rc.ComputeTests()
+	sch := etable.Schema{
+		{"CondIdx", etensor.INT64, nil, nil},
+		{"Cond", etensor.STRING, nil, nil},
+		{"N", etensor.INT64, nil, nil},
+	}
+	for _, st := range rc.Stats {
+		for _, sfx := range []string{"Mean", "CILo", "CIHi", "Diff", "P"} {
+			sch = append(sch, etable.Column{st + ":" + sfx, etensor.FLOAT64, nil, nil})
+		}
+	}
+	if rc.Table == nil {
+		rc.Table = &etable.Table{}
+	}
+	dt := rc.Table
+	dt.SetMetaData("name", "RunCompare")
+	dt.SetMetaData("desc", "comparison of run stats across conditions, against the first")
+	dt.SetFromSchema(sch, len(rc.Conds))
+	for ci, cnd := range rc.Conds {
+		cv := rc.Vals[cnd]
+		dt.SetCellFloat("CondIdx", ci, float64(ci))
+		dt.SetCellString("Cond", ci, cnd)
+		n := 0
+		for _, st := range rc.Stats {
+			vals := cv[st]
+			if len(vals) > n {
+				n = len(vals)
+			}
+			mean, _ := MeanSD(vals)
+			lo, hi := TCI(vals, 0.95)
+			bmean, _ := MeanSD(rc.Vals[rc.Conds[0]][st])
+			diff := mean - bmean
+			p := math.NaN()
+			if ci == 0 {
+				diff = 0
+			} else {
+				_, _, p = WelchTTest(vals, rc.Vals[rc.Conds[0]][st])
+			}
+			dt.SetCellFloat(st+":Mean", ci, mean)
+			dt.SetCellFloat(st+":CILo", ci, lo)
+			dt.SetCellFloat(st+":CIHi", ci, hi)
+			dt.SetCellFloat(st+":Diff", ci, diff)
+			dt.SetCellFloat(st+":P", ci, p)
+		}
+		dt.SetCellFloat("N", ci, float64(n))
+	}
+	return dt
+}
+
+// ComputeTests computes the Tests table
+func (rc *RunCompare) ComputeTests() *etable.Table {
+	if rc.Tests == nil {
+		rc.Tests = &etable.Table{}
+	}
+	dt := rc.Tests
+	dt.SetMetaData("name", "RunCompareTests")
+	dt.SetMetaData("desc", "t-tests of the run stats of each condition against the first, and ANOVA across conditions")
+	dt.SetFromSchema(etable.Schema{
+		{"Stat", etensor.STRING, nil, nil},
+		{"Test", etensor.STRING, nil, nil},
+		{"Cond", etensor.STRING, nil, nil},
+		{"Base", etensor.STRING, nil, nil},
+		{"N", etensor.INT64, nil, nil},
+		{"Value", etensor.FLOAT64, nil, nil},
+		{"DF1", etensor.FLOAT64, nil, nil},
+		{"DF2", etensor.FLOAT64, nil, nil},
+		{"P", etensor.FLOAT64, nil, nil},
+	}, 0)
+	if len(rc.Conds) < 2 {
+		return dt
+	}
+	bcnd := rc.Conds[0]
+	add := func(st, test, cnd string, n int, val, df1, df2, p float64) {
+		row := dt.Rows
+		dt.SetNumRows(row + 1)
+		dt.SetCellString("Stat", row, st)
+		dt.SetCellString("Test", row, test)
+		dt.SetCellString("Cond", row, cnd)
+		if cnd != "" {
+			dt.SetCellString("Base", row, bcnd)
+		}
+		dt.SetCellFloat("N", row, float64(n))
+		dt.SetCellFloat("Value", row, val)
+		dt.SetCellFloat("DF1", row, df1)
+		dt.SetCellFloat("DF2", row, df2)
+		dt.SetCellFloat("P", row, p)
+	}
+	for _, st := range rc.Stats {
+		bvals := rc.Vals[bcnd][st]
+		groups := make([][]float64, len(rc.Conds))
+		n := 0
+		for ci, cnd := range rc.Conds {
+			groups[ci] = rc.Vals[cnd][st]
+			n += len(groups[ci])
+			if ci == 0 {
+				continue
+			}
+			vals := rc.Vals[cnd][st]
+			t, df, p := WelchTTest(vals, bvals)
+			add(st, "Welch", cnd, len(vals)+len(bvals), t, df, math.NaN(), p)
+			pv, pb := rc.Paired(cnd, st)
+			t, df, p = PairedTTest(pv, pb)
+			add(st, "Paired", cnd, len(pv), t, df, math.NaN(), p)
+		}
+		f, df1, df2, p := OneWayANOVA(groups)
+		add(st, "ANOVA", "", n, f, df1, df2, p)
+	}
+	return dt
+}
+
+// SaveTestsCSV saves the Tests table (computing it if needed) to given
+// tab-separated file, with headers
+func (rc *RunCompare) SaveTestsCSV(filename gi.FileName) error {
+	if rc.Tests == nil {
+		rc.ComputeTests()
+	}
+	err := rc.Tests.SaveCSV(filename, etable.Tab, true)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// SaveCSV saves the comparison Table (computing it if needed) to given
+// tab-separated file, with headers
+func (rc *RunCompare) SaveCSV(filename gi.FileName) error {
+	if rc.Table == nil || rc.Table.Rows != len(rc.Conds) {
+		rc.Compute()
+	}
+	err := rc.Table.SaveCSV(filename, etable.Tab, true)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestTStats(t *testing.T) {
+	if q := TQuantile(0.975, 10); math.Abs(q-2.2281) > 1.0e-3 {
+		t.Errorf("TQuantile(0.975, 10) = %v, want 2.2281\n", q)
+	}
+	if p := TTwoSidedP(2.2281, 10); math.Abs(p-0.05) > 1.0e-3 {
+		t.Errorf("TTwoSidedP(2.2281, 10) = %v, want 0.05\n", p)
+	}
+	tv, df, p := WelchTTest([]float64{1, 2, 3, 4, 5}, []float64{3, 4, 5, 6, 9})
+	if math.Abs(tv+1.9215) > 1.0e-3 || math.Abs(df-7.087) > 1.0e-2 || math.Abs(p-0.0957) > 1.0e-3 {
+		t.Errorf("WelchTTest: t = %v df = %v p = %v, want -1.9215 7.087 0.0957\n", tv, df, p)
+	}
+	tv, df, p = PairedTTest([]float64{1, 2, 3, 4, 5}, []float64{2, 4, 3, 6, 8})
+	if math.Abs(tv+3.1379) > 1.0e-3 || df != 4 || math.Abs(p-0.0349) > 1.0e-3 {
+		t.Errorf("PairedTTest: t = %v df = %v p = %v, want -3.1379 4 0.0349\n", tv, df, p)
+	}
+	f, df1, df2, p := OneWayANOVA([][]float64{{1, 2, 3, 4, 5}, {3, 4, 5, 6, 9}, {2, 2, 3, 3}, nil})
+	if math.Abs(f-3.8700) > 1.0e-3 || df1 != 2 || df2 != 11 || math.Abs(p-0.0534) > 1.0e-3 {
+		t.Errorf("OneWayANOVA: F = %v df = %v, %v p = %v, want 3.8700 2, 11 0.0534\n", f, df1, df2, p)
+	}
+	lo, hi := TCI([]float64{1, 2, 3, 4, 5}, 0.95)
+	if math.Abs(lo-1.0368) > 1.0e-3 || math.Abs(hi-4.9632) > 1.0e-3 {
+		t.Errorf("TCI: %v - %v, want 1.0368 - 4.9632\n", lo, hi)
+	}
+}
+
+func TestRunCompare(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Params", etensor.STRING, nil, nil},
+		{"FirstZero", etensor.FLOAT64, nil, nil},
+	}, 6)
+	for row, fz := range []float64{1, 2, 3, 4, 5, -1} {
+		cnd := "A"
+		if row%2 == 1 {
+			cnd = "B"
+		}
+		dt.SetCellString("Params", row, cnd)
+		dt.SetCellFloat("FirstZero", row, fz)
+	}
+	rc := &RunCompare{Stats: []string{"FirstZero"}}
+	rc.Valid = func(stat string, val float64) bool { return val >= 0 }
+	if err := rc.AddRuns(dt, ""); err != nil {
+		t.Fatal(err)
+	}
+	ct := rc.Compute()
+	if ct.Rows != 2 || ct.CellString("Cond", 0) != "A" || ct.CellString("Cond", 1) != "B" {
+		t.Fatalf("conditions wrong: %v\n", rc.Conds)
+	}
+	// A: 1, 3, 5 -- B: 2, 4 (-1 excluded)
+	if n := ct.CellFloat("N", 1); n != 2 {
+		t.Errorf("B N = %v, want 2\n", n)
+	}
+	if m := ct.CellFloat("FirstZero:Mean", 1); m != 3 {
+		t.Errorf("B mean = %v, want 3\n", m)
+	}
+	if d := ct.CellFloat("FirstZero:Diff", 1); d != 0 {
+		t.Errorf("B diff = %v, want 0\n", d)
+	}
+	if p := ct.CellFloat("FirstZero:P", 1); math.Abs(p-1) > 1.0e-6 {
+		t.Errorf("B p = %v, want 1\n", p)
+	}
+	if !math.IsNaN(ct.CellFloat("FirstZero:P", 0)) {
+		t.Errorf("baseline p should be NaN\n")
+	}
+	// Welch, Paired (no runs in common: rows differ), ANOVA
+	if tt := rc.Tests; tt.Rows != 3 || tt.CellString("Test", 1) != "Paired" || tt.CellFloat("N", 1) != 0 || tt.CellString("Cond", 2) != "" {
+		t.Errorf("tests wrong: %v rows\n", tt.Rows)
+	}
+
+	rc.Reset()
+	rc.Valid = nil
+	rc.AddRuns(dt, "A")
+	dt.SetCellFloat("FirstZero", 5, 6)
+	for row := 0; row < dt.Rows; row++ {
+		dt.SetCellFloat("FirstZero", row, dt.CellFloat("FirstZero", row)+float64(row%3))
+	}
+	rc.AddRuns(dt, "B")
+	rc.Compute()
+	if pv, pb := rc.Paired("B", "FirstZero"); len(pv) != 6 || pv[2]-pb[2] != 2 {
+		t.Errorf("paired wrong: %v %v\n", pv, pb)
+	}
+	rc.Stats = []string{"PctCor"}
+	if err := rc.AddRuns(dt, "C"); err == nil {
+		t.Errorf("expected error for missing stat column\n")
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/emer/emergent/params"
+)
+
+// RunSampler samples selected params independently for each run from their
+// prior distributions (see HyperParam), to simulate individual differences
+// across a population of simulated subjects.  The values for a run depend
+// only on Seed and the run number, so any run can be reproduced.
+type RunSampler struct {
+	Params []HyperParam `desc:"params to sample, with the distribution for each given by its Prior"`
+	Seed   int64        `desc:"random seed -- values for each run are sampled from a generator seeded with Seed + run"`
+	Run    int          `inactive:"+" desc:"run for the current Vals"`
+	Vals   []float64    `inactive:"+" desc:"sampled values for the current run, one per param"`
+}
+
+// Names returns the names of the params, for log columns
+func (rs *RunSampler) Names() []string {
+	nms := make([]string, len(rs.Params))
+	for i := range rs.Params {
+		nms[i] = rs.Params[i].Name()
+	}
+	return nms
+}
+
+// Sample samples new values for given run, and returns them
+func (rs *RunSampler) Sample(run int) []float64 {
+	rnd := rand.New(rand.NewSource(rs.Seed + int64(run)))
+	rs.Run = run
+	rs.Vals = make([]float64, len(rs.Params))
+	for i := range rs.Params {
+		rs.Vals[i] = rs.Params[i].Sample(rnd)
+	}
+	return rs.Vals
+}
+
+// Desc returns a description of the current values
+func (rs *RunSampler) Desc() string {
+	strs := make([]string, len(rs.Params))
+	for i := range rs.Params {
+		strs[i] = rs.Params[i].Name() + "=" + rs.Params[i].ValString(rs.Vals[i])
+	}
+	return strings.Join(strs, " ")
+}
+
+// Set returns a params.Set with the current values of the wake (non-Sleep)
+// params, to be applied on top of the other params
+func (rs *RunSampler) Set() *params.Set {
+	return HyperParamsSet(rs.Params, rs.Vals, false, fmt.Sprintf("RunSample_%03d", rs.Run), rs.Desc(), "run sample")
+}
+
+// SleepSet returns a params.Set with the current values of the Sleep params,
+// or nil if none
+func (rs *RunSampler) SleepSet() *params.Set {
+	pset := HyperParamsSet(rs.Params, rs.Vals, true, fmt.Sprintf("RunSample_%03d_Sleep", rs.Run), rs.Desc(), "run sample")
+	if len(pset.Sheets) == 0 {
+		return nil
+	}
+	return pset
+}
+
+// ParseHyperParam parses a param distribution spec of the form:
+// [Sel ]Path=dist(a,b) where dist is uniform(min,max), loguniform(min,max)
+// or normal(mean,sd) -- normal is clipped to mean +/- 4 sd.  e.g.,
+// "#Hidden1 Layer.Inhib.Layer.Gi=normal(1.9,0.1)"
+func ParseHyperParam(spec string) (HyperParam, error) {
+	hp := HyperParam{}
+	spec = strings.TrimSpace(spec)
+	errf := func(msg string) (HyperParam, error) {
+		err := fmt.Errorf("ParseHyperParam: %v in: %v -- format is: [Sel ]Path=dist(a,b), dist = uniform, loguniform, normal", msg, spec)
+		log.Println(err)
+		return hp, err
+	}
+	ei := strings.Index(spec, "=")
+	if ei < 0 {
+		return errf("no =")
+	}
+	lhs := strings.Fields(spec[:ei])
+	switch len(lhs) {
+	case 1:
+		hp.Path = lhs[0]
+	case 2:
+		hp.Sel = lhs[0]
+		hp.Path = lhs[1]
+	default:
+		return errf("expected [Sel ]Path before =")
+	}
+	rhs := strings.TrimSpace(spec[ei+1:])
+	pi := strings.Index(rhs, "(")
+	if pi < 0 || !strings.HasSuffix(rhs, ")") {
+		return errf("expected dist(a,b)")
+	}
+	args := strings.Split(rhs[pi+1:len(rhs)-1], ",")
+	if len(args) != 2 {
+		return errf("expected 2 args")
+	}
+	a, err := strconv.ParseFloat(strings.TrimSpace(args[0]), 64)
+	if err != nil {
+		return errf(err.Error())
+	}
+	b, err := strconv.ParseFloat(strings.TrimSpace(args[1]), 64)
+	if err != nil {
+		return errf(err.Error())
+	}
+	switch strings.ToLower(strings.TrimSpace(rhs[:pi])) {
+	case "uniform":
+		hp.Prior = HyperUniform
+		hp.Min, hp.Max = a, b
+	case "loguniform":
+		hp.Prior = HyperLogUniform
+		hp.Min, hp.Max = a, b
+		if a <= 0 || b <= 0 {
+			return errf("loguniform range must be > 0")
+		}
+	case "normal":
+		hp.Prior = HyperNormal
+		hp.Mean, hp.SD = a, b
+		hp.Min, hp.Max = a-4*b, a+4*b
+	default:
+		return errf("unknown distribution: " + rhs[:pi])
+	}
+	return hp, nil
+}
+
+// AddString adds params from specs separated by ; -- see ParseHyperParam
+func (rs *RunSampler) AddString(specs string) error {
+	for _, spec := range strings.Split(specs, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		hp, err := ParseHyperParam(spec)
+		if err != nil {
+			return err
+		}
+		rs.Params = append(rs.Params, hp)
+	}
+	return nil
+}
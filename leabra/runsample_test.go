@@ -0,0 +1,37 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+)
+
+func TestRunSampler(t *testing.T) {
+	rs := RunSampler{Seed: 5}
+	if err := rs.AddString("#Hidden1 Layer.Inhib.Layer.Gi=normal(1.9,0.1); Prjn.Learn.Lrate=loguniform(0.01,0.1)"); err != nil {
+		t.Fatal(err)
+	}
+	v1 := append([]float64{}, rs.Sample(1)...)
+	rs.Sample(2)
+	v1b := rs.Sample(1)
+	for i := range v1 {
+		if v1[i] != v1b[i] {
+			t.Errorf("run 1 values not reproducible: %v != %v\n", v1, v1b)
+		}
+	}
+	if v1[1] < 0.01 || v1[1] > 0.1 {
+		t.Errorf("loguniform value out of range: %v\n", v1[1])
+	}
+	pset := rs.Set()
+	if sh := pset.Sheets["Network"]; sh == nil || len(*sh) != 2 || (*sh)[0].Sel != "#Hidden1" {
+		t.Errorf("Set wrong: %+v\n", pset)
+	}
+	if rs.SleepSet() != nil {
+		t.Errorf("SleepSet should be nil\n")
+	}
+	if _, err := ParseHyperParam("Layer.Inhib.Layer.Gi=gamma(1,2)"); err == nil {
+		t.Errorf("expected error for unknown distribution\n")
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/emer/emergent/env"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// Salience is the affective value of a pattern
+type Salience struct {
+	Valence float64 `min:"-1" max:"1" desc:"valence: -1 for negative, +1 for positive, 0 for neutral"`
+	Arousal float64 `min:"0" max:"1" desc:"arousal: intensity of the emotion, 0 for neutral"`
+}
+
+// Cat returns the category of the salience: Neg, Pos or Neutral
+func (sl Salience) Cat() string {
+	switch {
+	case sl.Arousal <= 0 || sl.Valence == 0:
+		return "Neutral"
+	case sl.Valence < 0:
+		return "Neg"
+	}
+	return "Pos"
+}
+
+// SalienceEnv wraps an environment with a salience channel: each pattern
+// carries a Salience -- from the Valence and Arousal columns of its table
+// if it has them, otherwise from the activity of its first NegLayers and
+// PosLayers patterns (e.g., Ne and Po) -- which populates the patterns of
+// the NegLayers (negative valence) and PosLayers (positive valence): the
+// first Units units of the layer of the valence are set to the arousal and
+// the rest to 0.  The salience of the current pattern (see SetCur) is also
+// the category of items for stats (see Salience.Cat) and modulates the
+// learning rate by arousal (see LrateMod).
+type SalienceEnv struct {
+	env.Env
+	On        bool                `desc:"if true, the salience channel populates the NegLayers and PosLayers patterns"`
+	NegLayers []string            `desc:"layers (BLA inputs and outputs) coding negative valence"`
+	PosLayers []string            `desc:"layers (BLA inputs and outputs) coding positive valence"`
+	Units     int                 `min:"1" desc:"number of units of the layer of the valence set to the arousal"`
+	AroLrate  float64             `desc:"gain of the modulation of the learning rate by arousal: the learning rate is multiplied by 1 + AroLrate * Arousal -- 0 for none"`
+	Sals      map[string]Salience `view:"-" desc:"salience of each item, by name"`
+	Cur       Salience            `inactive:"+" desc:"salience of the current pattern"`
+}
+
+func (se *SalienceEnv) Defaults() {
+	se.NegLayers = []string{"Ne", "Ne_Out"}
+	se.PosLayers = []string{"Po", "Po_Out"}
+	se.Units = 1
+}
+
+// SetTable sets the salience of each item of given table of patterns, with
+// the names of the items in nameCol -- from its Valence and Arousal columns
+// if it has them, else from its first NegLayers and PosLayers columns
+// (the maximum activity of the first that has any is the arousal)
+func (se *SalienceEnv) SetTable(dt *etable.Table, nameCol string) error {
+	nc := dt.ColByName(nameCol)
+	if nc == nil {
+		err := fmt.Errorf("SalienceEnv: name column: %v not found in table: %v", nameCol, dt.MetaData["name"])
+		log.Println(err)
+		return err
+	}
+	vc, ac := dt.ColByName("Valence"), dt.ColByName("Arousal")
+	se.Sals = make(map[string]Salience, dt.Rows)
+	for row := 0; row < dt.Rows; row++ {
+		var sl Salience
+		if vc != nil && ac != nil {
+			sl.Valence = vc.FloatVal1D(row)
+			sl.Arousal = ac.FloatVal1D(row)
+		} else {
+			for _, vl := range []struct {
+				lays []string
+				val  float64
+			}{{se.NegLayers, -1}, {se.PosLayers, 1}} {
+				if len(vl.lays) == 0 || dt.ColByName(vl.lays[0]) == nil {
+					continue
+				}
+				tsr := dt.CellTensor(vl.lays[0], row)
+				mx := 0.0
+				for i := 0; i < tsr.Len(); i++ {
+					if v := tsr.FloatVal1D(i); v > mx {
+						mx = v
+					}
+				}
+				if mx > 0 {
+					sl = Salience{Valence: vl.val, Arousal: mx}
+					break
+				}
+			}
+		}
+		se.Sals[nc.StringVal1D(row)] = sl
+	}
+	return nil
+}
+
+// Of returns the salience of the item with given name (neutral if unknown)
+func (se *SalienceEnv) Of(name string) Salience {
+	return se.Sals[name]
+}
+
+// SetCur sets the current pattern to the item with given name
+func (se *SalienceEnv) SetCur(name string) {
+	se.Cur = se.Of(name)
+}
+
+// LrateMod returns the multiplier of the learning rate for the current
+// pattern: 1 + AroLrate * Arousal
+func (se *SalienceEnv) LrateMod() float32 {
+	return float32(1 + se.AroLrate*se.Cur.Arousal)
+}
+
+// State returns the pattern of the current salience for the NegLayers and
+// PosLayers if On, and the pattern of the wrapped environment otherwise
+func (se *SalienceEnv) State(element string) etensor.Tensor {
+	pat := se.Env.State(element)
+	if !se.On || pat == nil {
+		return pat
+	}
+	val := 0.0
+	switch {
+	case inStrings(element, se.NegLayers):
+		val = -1
+	case inStrings(element, se.PosLayers):
+		val = 1
+	default:
+		return pat
+	}
+	sp := etensor.NewFloat32(pat.Shapes(), nil, nil)
+	if se.Cur.Arousal > 0 && se.Cur.Valence*val > 0 {
+		for i := 0; i < se.Units && i < sp.Len(); i++ {
+			sp.Values[i] = float32(se.Cur.Arousal)
+		}
+	}
+	return sp
+}
+
+// inStrings returns true if given string is in given list
+func inStrings(s string, list []string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ScaleLrates multiplies the learning rate of all the projections of given
+// network by given multiplier, e.g., LrateMod for a trial, and returns a
+// function that restores them
+func ScaleLrates(nt *Network, mult float32) (restore func()) {
+	var lrs []float32
+	for _, l := range nt.Layers {
+		for _, p := range l.(LeabraLayer).AsLeabra().RcvPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			lrs = append(lrs, pj.Learn.Lrate)
+			pj.Learn.Lrate *= mult
+		}
+	}
+	return func() {
+		i := 0
+		for _, l := range nt.Layers {
+			for _, p := range l.(LeabraLayer).AsLeabra().RcvPrjns {
+				p.(LeabraPrjn).AsLeabra().Learn.Lrate = lrs[i]
+				i++
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/env"
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestSalienceEnv(t *testing.T) {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+		{"Ne", etensor.FLOAT32, []int{3, 1}, nil},
+		{"Po", etensor.FLOAT32, []int{3, 1}, nil},
+	}, 3)
+	for row, nm := range []string{"neg", "pos", "neu"} {
+		dt.SetCellString("Name", row, nm)
+	}
+	dt.CellTensor("Ne", 0).SetFloat1D(0, 1)
+	dt.CellTensor("Po", 1).SetFloat1D(0, 0.5)
+	se := &SalienceEnv{On: true}
+	se.Defaults()
+	se.Units = 2
+	se.AroLrate = 1
+	if err := se.SetTable(dt, "Name"); err != nil {
+		t.Fatal(err)
+	}
+	if sl := se.Of("neg"); sl.Valence != -1 || sl.Arousal != 1 || sl.Cat() != "Neg" {
+		t.Errorf("neg salience: %v\n", sl)
+	}
+	if sl := se.Of("pos"); sl.Valence != 1 || sl.Arousal != 0.5 || sl.Cat() != "Pos" {
+		t.Errorf("pos salience: %v\n", sl)
+	}
+	if se.Of("neu").Cat() != "Neutral" {
+		t.Errorf("neutral salience: %v\n", se.Of("neu"))
+	}
+
+	ft := &env.FixedTable{}
+	ft.Table = etable.NewIdxView(dt)
+	ft.Sequential = true
+	ft.Init(0)
+	ft.Step()
+	ft.Step() // pos
+	se.Env = ft
+	se.SetCur(ft.TrialName)
+	po, ne := se.State("Po"), se.State("Ne")
+	if po.FloatVal1D(0) != 0.5 || po.FloatVal1D(1) != 0.5 || po.FloatVal1D(2) != 0 || ne.FloatVal1D(0) != 0 {
+		t.Errorf("BLA patterns not from salience: Po %v Ne %v\n", po, ne)
+	}
+	if se.LrateMod() != 1.5 {
+		t.Errorf("lrate mod: %v\n", se.LrateMod())
+	}
+
+	net := &Network{}
+	net.InitName(net, "SalNet")
+	in := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hid := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.ConnectLayers(in, hid, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	pj := hid.(*Layer).RcvPrjns[0].(*Prjn)
+	lr := pj.Learn.Lrate
+	restore := ScaleLrates(net, 2)
+	if pj.Learn.Lrate != 2*lr {
+		t.Errorf("lrate not scaled: %v\n", pj.Learn.Lrate)
+	}
+	restore()
+	if pj.Learn.Lrate != lr {
+		t.Errorf("lrate not restored: %v\n", pj.Learn.Lrate)
+	}
+}
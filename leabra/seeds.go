@@ -0,0 +1,211 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Names of the standard random number streams in Seeds
+const (
+	// SeedWtsInit is the stream for the initial weights (see Network.SeedPrjnRnd)
+	SeedWtsInit = "weights-init"
+
+	// SeedNoise is the stream for activation noise (see Network.SeedLayerRnd)
+	SeedNoise = "noise"
+
+	// SeedEnvShuffle is the stream for the order of trials in the environments
+	SeedEnvShuffle = "env-shuffle"
+
+	// SeedSleepInit is the stream for the random initial activations in sleep
+	SeedSleepInit = "sleep-init"
+
+	// SeedDataSplit is the stream for the split of the patterns into train
+	// and test sets (see DataSplit) -- not one of the SeedStreams
+	SeedDataSplit = "data-split"
+
+	// SeedCueDegrade is the stream for the degradation of the input cues in
+	// pattern-completion tests (see PatComp) -- not one of the SeedStreams
+	SeedCueDegrade = "cue-degrade"
+
+	// SeedTestNoise is the stream for the noise added to the test inputs
+	// (see env.NoisyEnv) -- not one of the SeedStreams
+	SeedTestNoise = "test-noise"
+
+	// SeedStudy is the stream for the choice of the items studied in the
+	// one-shot protocol (see OneShot) -- not one of the SeedStreams
+	SeedStudy = "study"
+
+	// SeedLists is the stream for the pairing of the items of the A-C list
+	// in the A-B / A-C paradigm (see ABAC) -- not one of the SeedStreams
+	SeedLists = "lists"
+
+	// SeedSetSize is the stream for the subsample of the patterns trained in
+	// each run for a set size (see SetSize) -- not one of the SeedStreams
+	SeedSetSize = "set-size"
+
+	// SeedAugment is the stream for the data-augmentation transforms of the
+	// training inputs (see AugmentEnv) -- not one of the SeedStreams
+	SeedAugment = "augment"
+
+	// SeedModal is the stream for the cross-modal contingency of the
+	// training inputs (see MultiModalEnv) -- not one of the SeedStreams
+	SeedModal = "modal"
+
+	// SeedGenTest is the stream for the recombined and novel items of the
+	// generalization test (see GenTest) -- not one of the SeedStreams
+	SeedGenTest = "gen-test"
+)
+
+// SeedStreams are the names of the standard streams
+var SeedStreams = []string{SeedWtsInit, SeedNoise, SeedEnvShuffle, SeedSleepInit}
+
+// Seeds manages named random number streams (e.g., weights-init, noise,
+// env-shuffle, sleep-init), each with its own seed derived from one Master
+// seed and the run, so that each source of randomness can be controlled
+// separately: a stream can be given a Fixed seed, e.g., to combine the same
+// initial weights with different sleep noise sequences for controlled
+// comparisons, while the others vary with the Master seed.
+type Seeds struct {
+	Master  int64                 `desc:"master seed from which the seeds of all the streams are derived"`
+	Fixed   map[string]int64      `desc:"streams with a fixed seed, used instead of the one derived from Master -- still combined with the run"`
+	Run     int                   `inactive:"+" desc:"run that the streams are currently seeded for -- see Init"`
+	Streams map[string]*rand.Rand `view:"-" json:"-" desc:"the random number streams for the current run, created as needed by Rand"`
+	srcs    map[string]*RndSource
+}
+
+// Seed returns the base seed for stream with given name: the Fixed seed if
+// set, otherwise derived from the Master seed and the name
+func (sd *Seeds) Seed(name string) int64 {
+	if fs, has := sd.Fixed[name]; has {
+		return fs
+	}
+	return StreamSeed(sd.Master, name)
+}
+
+// RunSeed returns the seed for stream with given name for given run
+func (sd *Seeds) RunSeed(name string, run int) int64 {
+	return sd.Seed(name) + int64(run)
+}
+
+// EpochSeed returns the seed for stream with given name for given epoch of
+// the current run, e.g., for the sim to reseed the global random number
+// generator at the start of each epoch, so that a Checkpoint can restore it
+// (see Checkpoint.RndSeed)
+func (sd *Seeds) EpochSeed(name string, epc int) int64 {
+	return StreamSeed(sd.RunSeed(name, sd.Run), "epoch-"+strconv.Itoa(epc))
+}
+
+// Init starts new streams for given run -- streams are then created with
+// the seed for the run as needed by Rand
+func (sd *Seeds) Init(run int) {
+	sd.Run = run
+	sd.Streams = nil
+	sd.srcs = nil
+}
+
+// Rand returns the random number stream with given name for the current
+// run, creating it if needed
+func (sd *Seeds) Rand(name string) *rand.Rand {
+	if rnd, has := sd.Streams[name]; has {
+		return rnd
+	}
+	if sd.Streams == nil {
+		sd.Streams = make(map[string]*rand.Rand)
+		sd.srcs = make(map[string]*RndSource)
+	}
+	src := NewRndSource(sd.RunSeed(name, sd.Run))
+	rnd := rand.New(src)
+	sd.Streams[name] = rnd
+	sd.srcs[name] = src
+	return rnd
+}
+
+// States returns the states of the streams created so far for the current
+// run, by name -- for saving, e.g., in a Checkpoint (see AddSeeds), without
+// changing them
+func (sd *Seeds) States() map[string]RndState {
+	sts := make(map[string]RndState, len(sd.srcs))
+	for nm, src := range sd.srcs {
+		sts[nm] = src.State()
+	}
+	return sts
+}
+
+// SetState sets the stream with given name to given state, as returned by
+// States, creating it if needed
+func (sd *Seeds) SetState(name string, st RndState) {
+	sd.Rand(name)
+	sd.srcs[name].SetState(st)
+}
+
+// SetFixed sets a fixed seed for stream with given name
+func (sd *Seeds) SetFixed(name string, seed int64) {
+	if sd.Fixed == nil {
+		sd.Fixed = make(map[string]int64)
+	}
+	sd.Fixed[name] = seed
+	delete(sd.Streams, name)
+	delete(sd.srcs, name)
+}
+
+// AddString sets Fixed seeds from specs separated by ; each of the form
+// name=seed, e.g., "weights-init=3; sleep-init=7" -- names other than the
+// standard SeedStreams are allowed, for other uses of Rand
+func (sd *Seeds) AddString(specs string) error {
+	for _, spec := range strings.Split(specs, ";") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		ei := strings.Index(spec, "=")
+		if ei < 0 {
+			err := fmt.Errorf("Seeds: expected name=seed in: %v -- standard names are: %v", spec, strings.Join(SeedStreams, ", "))
+			log.Println(err)
+			return err
+		}
+		seed, err := strconv.ParseInt(strings.TrimSpace(spec[ei+1:]), 10, 64)
+		if err != nil {
+			err = fmt.Errorf("Seeds: seed must be an integer in: %v", spec)
+			log.Println(err)
+			return err
+		}
+		sd.SetFixed(strings.TrimSpace(spec[:ei]), seed)
+	}
+	return nil
+}
+
+// String returns the seed for each standard stream and any other Fixed ones
+func (sd *Seeds) String() string {
+	nms := append([]string{}, SeedStreams...)
+	var other []string
+	for nm := range sd.Fixed {
+		isStd := false
+		for _, sn := range SeedStreams {
+			if nm == sn {
+				isStd = true
+				break
+			}
+		}
+		if !isStd {
+			other = append(other, nm)
+		}
+	}
+	sort.Strings(other)
+	nms = append(nms, other...)
+	strs := make([]string, len(nms))
+	for i, nm := range nms {
+		strs[i] = fmt.Sprintf("%v=%v", nm, sd.Seed(nm))
+		if _, has := sd.Fixed[nm]; has {
+			strs[i] += " (fixed)"
+		}
+	}
+	return strings.Join(strs, " ")
+}
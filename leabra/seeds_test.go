@@ -0,0 +1,43 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSeeds(t *testing.T) {
+	sd := Seeds{Master: 1}
+	if sd.Seed(SeedWtsInit) == sd.Seed(SeedSleepInit) {
+		t.Errorf("streams should have different seeds\n")
+	}
+	sd.Init(0)
+	wt0 := sd.Rand(SeedWtsInit).Int63()
+	sl0 := sd.Rand(SeedSleepInit).Int63()
+
+	// same weights, different sleep noise
+	sd2 := Seeds{Master: 2}
+	if err := sd2.AddString("weights-init=" + strconv.FormatInt(sd.Seed(SeedWtsInit), 10)); err != nil {
+		t.Fatal(err)
+	}
+	sd2.Init(0)
+	if sd2.Rand(SeedWtsInit).Int63() != wt0 {
+		t.Errorf("fixed stream should match\n")
+	}
+	if sd2.Rand(SeedSleepInit).Int63() == sl0 {
+		t.Errorf("unfixed stream should differ with master seed\n")
+	}
+	sd.Init(1)
+	if sd.Rand(SeedWtsInit).Int63() == wt0 {
+		t.Errorf("stream should differ across runs\n")
+	}
+	if err := sd.AddString("noise"); err == nil {
+		t.Errorf("expected error for missing seed\n")
+	}
+	if sd.EpochSeed(SeedEnvShuffle, 3) != sd.EpochSeed(SeedEnvShuffle, 3) || sd.EpochSeed(SeedEnvShuffle, 3) == sd.EpochSeed(SeedEnvShuffle, 4) {
+		t.Errorf("epoch seeds should be the same for the same epoch, and differ across epochs\n")
+	}
+}
@@ -0,0 +1,403 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/emer/emergent/params"
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+// ParamCurVal returns the value currently in effect for given param
+// selector and path: on the first layer or projection in the network that
+// the selector matches, or on sim for Sim paths -- the value must be numeric.
+func ParamCurVal(nt *Network, sim interface{}, sel, path string) (float64, error) {
+	typ := ParamPathType(path)
+	fld := strings.TrimPrefix(path, typ+".")
+	if typ == "Sim" {
+		if sim == nil {
+			return 0, fmt.Errorf("ParamCurVal: no Sim object for: %v", path)
+		}
+		return StatVal(sim, fld)
+	}
+	if sel == "" {
+		sel = typ
+	}
+	for _, obj := range nt.paramTargets() {
+		if obj.TypeName() != typ || !ParamSelMatch(sel, obj.TypeName(), obj.Name(), obj.Class()) {
+			continue
+		}
+		return StatVal(obj, fld)
+	}
+	return 0, fmt.Errorf("ParamCurVal: selector: %v does not match any %v in the network", sel, typ)
+}
+
+// SensConfig is one configuration of a Sensitivity analysis: the base
+// params, or one param perturbed in one direction
+type SensConfig struct {
+	Name  string  `desc:"name of the configuration, used as the params.Set name"`
+	Param int     `desc:"index of the perturbed param, or -1 for the base configuration"`
+	Sign  int     `desc:"direction of the perturbation: -1 or +1, or 0 for the base configuration"`
+	Val   float64 `desc:"perturbed value of the param"`
+}
+
+// Sensitivity is a one-at-a-time parameter sensitivity analysis: each param
+// is perturbed by -Pct and +Pct percent of its base value (the value in
+// effect with the regular params), keeping all the others at their base
+// values, and each such configuration (plus the base configuration) is run
+// for NSeeds runs, typically as a short training / sleep probe.  The Table
+// then reports the effect of each perturbation on each of the Stats,
+// relative to the base configuration, as an effect size, to show which
+// params matter.  The sim drives the runs: after SetBase and Generate, for
+// each of the Configs it applies the Set (and SleepSet, during sleep) on top
+// of its regular params, and then calls AddResult for each completed run.
+type Sensitivity struct {
+	Params  []HyperParam  `desc:"params to perturb -- only Sel, Path, Int and Sleep are used"`
+	Pct     float64       `def:"10" desc:"percent of the base value to perturb each param by, in each direction -- params with a base value of 0 are perturbed by Pct / 100"`
+	NSeeds  int           `desc:"number of runs (with different random seeds) per configuration"`
+	Stats   []string      `desc:"names of stats recorded per run, e.g., TstPctCor, PctCor"`
+	Base    []float64     `inactive:"+" desc:"base value of each param, as set by SetBase"`
+	Configs []SensConfig  `desc:"configurations to run, generated by Generate: the base configuration, then -Pct and +Pct for each param"`
+	Results *etable.Table `view:"no-inline" desc:"one row per run: config, param, value, seed and stats"`
+}
+
+// Defaults sets default values
+func (sa *Sensitivity) Defaults() {
+	sa.Pct = 10
+	sa.NSeeds = 5
+	sa.Stats = []string{"FirstZero", "PctCor"}
+}
+
+// AddString adds params from specs separated by ; each of the form
+// [sleep:][Sel ]Path, e.g., "#Hidden1 Layer.Inhib.Layer.Gi; sleep:.Back Prjn.WtScale.Rel"
+// where sleep: marks a param that applies during sleep
+func (sa *Sensitivity) AddString(specs string) error {
+	for _, spec := range strings.Split(specs, ";") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		hp := HyperParam{}
+		if strings.HasPrefix(spec, "sleep:") {
+			hp.Sleep = true
+			spec = strings.TrimSpace(spec[6:])
+		}
+		flds := strings.Fields(spec)
+		switch len(flds) {
+		case 1:
+			hp.Path = flds[0]
+		case 2:
+			hp.Sel = flds[0]
+			hp.Path = flds[1]
+		default:
+			err := fmt.Errorf("Sensitivity: expected [sleep:][Sel ]Path in: %v", spec)
+			log.Println(err)
+			return err
+		}
+		sa.Params = append(sa.Params, hp)
+	}
+	return nil
+}
+
+// SetBase sets the Base values of the params with given Sleep setting, from
+// the values currently in effect (see ParamCurVal) -- call with sleep =
+// false with the regular params applied, and with sleep = true with the
+// sleep params applied.  Returns the last error for any that could not be
+// found, which are left at 0.
+func (sa *Sensitivity) SetBase(nt *Network, sim interface{}, sleep bool) error {
+	if len(sa.Base) != len(sa.Params) {
+		sa.Base = make([]float64, len(sa.Params))
+	}
+	var rerr error
+	for i := range sa.Params {
+		hp := &sa.Params[i]
+		if hp.Sleep != sleep {
+			continue
+		}
+		val, err := ParamCurVal(nt, sim, hp.Selector(), hp.Path)
+		if err != nil {
+			log.Println(err)
+			rerr = err
+			continue
+		}
+		sa.Base[i] = val
+	}
+	return rerr
+}
+
+// Generate generates the Configs: the base configuration, then -Pct and
+// +Pct for each param, and configures the Results table
+func (sa *Sensitivity) Generate() []SensConfig {
+	if len(sa.Base) != len(sa.Params) {
+		sa.Base = make([]float64, len(sa.Params))
+	}
+	sa.Configs = []SensConfig{{Name: "Sens_Base", Param: -1}}
+	for pi := range sa.Params {
+		hp := &sa.Params[pi]
+		base := sa.Base[pi]
+		del := math.Abs(base) * sa.Pct / 100
+		if base == 0 {
+			del = sa.Pct / 100
+		}
+		for _, sign := range []int{-1, 1} {
+			val := base + float64(sign)*del
+			if hp.Int {
+				val = math.Round(val)
+			}
+			sa.Configs = append(sa.Configs, SensConfig{Name: fmt.Sprintf("Sens_%02d_%s", pi, sensSignStr(sign)),
+				Param: pi, Sign: sign, Val: val})
+		}
+	}
+	sa.ConfigResults()
+	return sa.Configs
+}
+
+// sensSignStr returns Minus or Plus for the sign of a perturbation
+func sensSignStr(sign int) string {
+	if sign < 0 {
+		return "Minus"
+	}
+	return "Plus"
+}
+
+// ConfigDesc returns a description of given config index
+func (sa *Sensitivity) ConfigDesc(ci int) string {
+	cfg := &sa.Configs[ci]
+	if cfg.Param < 0 {
+		return "base params"
+	}
+	hp := &sa.Params[cfg.Param]
+	return fmt.Sprintf("%v=%v (base %v %+g%%)", hp.Name(), hp.ValString(cfg.Val), hp.ValString(sa.Base[cfg.Param]), float64(cfg.Sign)*sa.Pct)
+}
+
+// set returns the params.Set for given config index and Sleep setting
+func (sa *Sensitivity) set(ci int, sleep bool, name string) *params.Set {
+	cfg := &sa.Configs[ci]
+	if cfg.Param < 0 {
+		return &params.Set{Name: name, Desc: sa.ConfigDesc(ci), Sheets: params.Sheets{}}
+	}
+	return HyperParamsSet(sa.Params[cfg.Param:cfg.Param+1], []float64{cfg.Val}, sleep, name, sa.ConfigDesc(ci), "sensitivity")
+}
+
+// Set returns the params.Set for given config index with the perturbed
+// wake (non-Sleep) param if any, to be applied on top of the regular params
+// -- it has no sheets for the base configuration
+func (sa *Sensitivity) Set(ci int) *params.Set {
+	return sa.set(ci, false, sa.Configs[ci].Name)
+}
+
+// SleepSet returns the params.Set for given config index with the perturbed
+// Sleep param, to be applied on top of the sleep params, or nil if none
+func (sa *Sensitivity) SleepSet(ci int) *params.Set {
+	pset := sa.set(ci, true, sa.Configs[ci].Name+"_Sleep")
+	if len(pset.Sheets) == 0 {
+		return nil
+	}
+	return pset
+}
+
+// ConfigResults configures the Results table
+func (sa *Sensitivity) ConfigResults() {
+	if sa.Results == nil {
+		sa.Results = &etable.Table{}
+	}
+	dt := sa.Results
+	dt.SetMetaData("name", "Sensitivity")
+	dt.SetMetaData("desc", "Parameter sensitivity results, one row per run")
+	sch := etable.Schema{
+		{"Config", etensor.STRING, nil, nil},
+		{"Param", etensor.STRING, nil, nil},
+		{"Val", etensor.FLOAT64, nil, nil},
+		{"Seed", etensor.INT64, nil, nil},
+	}
+	for _, st := range sa.Stats {
+		sch = append(sch, etable.Column{st, etensor.FLOAT64, nil, nil})
+	}
+	dt.SetFromSchema(sch, 0)
+}
+
+// AddResult adds the stats for one run of given config index and seed
+func (sa *Sensitivity) AddResult(ci int, seed int, stats map[string]float64) {
+	dt := sa.Results
+	cfg := &sa.Configs[ci]
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+	dt.SetCellString("Config", row, cfg.Name)
+	if cfg.Param >= 0 {
+		dt.SetCellString("Param", row, sa.Params[cfg.Param].Name())
+	}
+	dt.SetCellFloat("Val", row, cfg.Val)
+	dt.SetCellFloat("Seed", row, float64(seed))
+	for _, st := range sa.Stats {
+		dt.SetCellFloat(st, row, stats[st])
+	}
+}
+
+// configVals returns the values of given stat for all the runs of given config
+func (sa *Sensitivity) configVals(ci int, stat string) []float64 {
+	var vals []float64
+	rt := sa.Results
+	if rt == nil {
+		return nil
+	}
+	for ri := 0; ri < rt.Rows; ri++ {
+		if rt.CellString("Config", ri) == sa.Configs[ci].Name {
+			vals = append(vals, rt.CellFloat(stat, ri))
+		}
+	}
+	return vals
+}
+
+// meanVar returns the mean and (sample) variance of vals
+func meanVar(vals []float64) (mean, vr float64) {
+	n := float64(len(vals))
+	if n == 0 {
+		return 0, 0
+	}
+	for _, v := range vals {
+		mean += v
+	}
+	mean /= n
+	if n < 2 {
+		return mean, 0
+	}
+	for _, v := range vals {
+		vr += (v - mean) * (v - mean)
+	}
+	return mean, vr / (n - 1)
+}
+
+// CohensD returns the effect size of the difference in means of b relative
+// to a, in units of their pooled standard deviation -- 0 if the pooled
+// standard deviation is 0
+func CohensD(a, b []float64) float64 {
+	na, nb := float64(len(a)), float64(len(b))
+	if na+nb <= 2 {
+		return 0
+	}
+	ma, va := meanVar(a)
+	mb, vb := meanVar(b)
+	sd := math.Sqrt(((na-1)*va + (nb-1)*vb) / (na + nb - 2))
+	if sd == 0 {
+		return 0
+	}
+	return (mb - ma) / sd
+}
+
+// sensRow is one row of the sensitivity Table
+type sensRow struct {
+	param, stat                   string
+	base, minus, plus             float64
+	baseM, minusM, plusM          float64
+	minusD, plusD, elast, maxAbsD float64
+}
+
+// Table returns the sensitivity table, with one row per param and stat:
+// the base and perturbed values of the param, the mean of the stat for the
+// base and each perturbation, the effect size (Cohen's d) of each
+// perturbation relative to the base, and the elasticity: the relative
+// change in the stat over the relative change in the param, from -Pct to
+// +Pct.  Rows are sorted by the largest absolute effect size, so the params
+// that matter most are at the top.
+func (sa *Sensitivity) Table() *etable.Table {
+	var rows []sensRow
+	cidx := map[[2]int]int{}
+	for ci := range sa.Configs {
+		cfg := &sa.Configs[ci]
+		cidx[[2]int{cfg.Param, cfg.Sign}] = ci
+	}
+	for pi := range sa.Params {
+		mci, mok := cidx[[2]int{pi, -1}]
+		pci, pok := cidx[[2]int{pi, 1}]
+		if !mok || !pok {
+			continue
+		}
+		for _, st := range sa.Stats {
+			bv := sa.configVals(0, st)
+			mv := sa.configVals(mci, st)
+			pv := sa.configVals(pci, st)
+			sr := sensRow{param: sa.Params[pi].Name(), stat: st, base: sa.Base[pi],
+				minus: sa.Configs[mci].Val, plus: sa.Configs[pci].Val}
+			sr.baseM, _ = meanVar(bv)
+			sr.minusM, _ = meanVar(mv)
+			sr.plusM, _ = meanVar(pv)
+			sr.minusD = CohensD(bv, mv)
+			sr.plusD = CohensD(bv, pv)
+			sr.maxAbsD = math.Max(math.Abs(sr.minusD), math.Abs(sr.plusD))
+			if sr.baseM != 0 && sr.base != 0 && sr.plus != sr.minus {
+				sr.elast = ((sr.plusM - sr.minusM) / sr.baseM) / ((sr.plus - sr.minus) / sr.base)
+			}
+			rows = append(rows, sr)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].maxAbsD > rows[j].maxAbsD
+	})
+	dt := &etable.Table{}
+	dt.SetMetaData("name", "SensitivityTable")
+	dt.SetMetaData("desc", fmt.Sprintf("Parameter sensitivity to +/- %g%% perturbations, sorted by effect size", sa.Pct))
+	dt.SetFromSchema(etable.Schema{
+		{"Param", etensor.STRING, nil, nil},
+		{"Stat", etensor.STRING, nil, nil},
+		{"Base", etensor.FLOAT64, nil, nil},
+		{"Minus", etensor.FLOAT64, nil, nil},
+		{"Plus", etensor.FLOAT64, nil, nil},
+		{"BaseMean", etensor.FLOAT64, nil, nil},
+		{"MinusMean", etensor.FLOAT64, nil, nil},
+		{"PlusMean", etensor.FLOAT64, nil, nil},
+		{"MinusD", etensor.FLOAT64, nil, nil},
+		{"PlusD", etensor.FLOAT64, nil, nil},
+		{"MaxAbsD", etensor.FLOAT64, nil, nil},
+		{"Elasticity", etensor.FLOAT64, nil, nil},
+	}, len(rows))
+	for i, sr := range rows {
+		dt.SetCellString("Param", i, sr.param)
+		dt.SetCellString("Stat", i, sr.stat)
+		dt.SetCellFloat("Base", i, sr.base)
+		dt.SetCellFloat("Minus", i, sr.minus)
+		dt.SetCellFloat("Plus", i, sr.plus)
+		dt.SetCellFloat("BaseMean", i, sr.baseM)
+		dt.SetCellFloat("MinusMean", i, sr.minusM)
+		dt.SetCellFloat("PlusMean", i, sr.plusM)
+		dt.SetCellFloat("MinusD", i, sr.minusD)
+		dt.SetCellFloat("PlusD", i, sr.plusD)
+		dt.SetCellFloat("MaxAbsD", i, sr.maxAbsD)
+		dt.SetCellFloat("Elasticity", i, sr.elast)
+	}
+	return dt
+}
+
+// SaveResults saves the per-run Results and the sensitivity Table to
+// tab-separated files: filename and filename with _table inserted before
+// the extension.
+func (sa *Sensitivity) SaveResults(filename gi.FileName) error {
+	if sa.Results == nil {
+		err := errors.New("Sensitivity: no results to save")
+		log.Println(err)
+		return err
+	}
+	fnm := string(filename)
+	if err := sa.Results.SaveCSV(gi.FileName(fnm), etable.Tab, true); err != nil {
+		log.Println(err)
+		return err
+	}
+	tnm := fnm + "_table"
+	if di := strings.LastIndex(fnm, "."); di > 0 {
+		tnm = fnm[:di] + "_table" + fnm[di:]
+	}
+	err := sa.Table().SaveCSV(gi.FileName(tnm), etable.Tab, true)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
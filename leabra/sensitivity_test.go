@@ -0,0 +1,71 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+)
+
+func TestSensitivity(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "SensNet")
+	net.AddLayer2D("Input", 2, 2, emer.Hidden)
+	hid := net.AddLayer2D("Hidden", 2, 2, emer.Hidden).(*Layer)
+	net.Defaults()
+	hid.Inhib.Layer.Gi = 2
+
+	sa := Sensitivity{}
+	sa.Defaults()
+	sa.Stats = []string{"PctCor"}
+	if err := sa.AddString("#Hidden Layer.Inhib.Layer.Gi; sleep:Layer.Act.Gbar.L"); err != nil {
+		t.Fatal(err)
+	}
+	if err := sa.AddString("a b c"); err == nil {
+		t.Errorf("expected error for bad spec\n")
+	}
+	if err := sa.SetBase(net, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if sa.Base[0] != 2 || sa.Base[1] != 0 {
+		t.Errorf("SetBase wake: %v\n", sa.Base)
+	}
+	sa.SetBase(net, nil, true)
+	if sa.Base[1] != float64(hid.Act.Gbar.L) {
+		t.Errorf("SetBase sleep: %v\n", sa.Base)
+	}
+	cfgs := sa.Generate()
+	if len(cfgs) != 5 || cfgs[1].Val != 1.8 || cfgs[2].Val != 2.2 {
+		t.Fatalf("Generate wrong: %+v\n", cfgs)
+	}
+	if sh := sa.Set(1).Sheets["Network"]; sh == nil || (*sh)[0].Params["Layer.Inhib.Layer.Gi"] != "1.8" {
+		t.Errorf("Set wrong for wake param\n")
+	}
+	if sa.SleepSet(1) != nil || sa.SleepSet(3) == nil || len(sa.Set(3).Sheets) != 0 {
+		t.Errorf("sleep param should only be in SleepSet\n")
+	}
+	for seed := 0; seed < 3; seed++ {
+		sa.AddResult(0, seed, map[string]float64{"PctCor": 0.5 + 0.01*float64(seed)})
+		sa.AddResult(1, seed, map[string]float64{"PctCor": 0.5 + 0.01*float64(seed)})
+		sa.AddResult(2, seed, map[string]float64{"PctCor": 0.6 + 0.01*float64(seed)})
+		sa.AddResult(3, seed, map[string]float64{"PctCor": 0.4 + 0.01*float64(seed)})
+		sa.AddResult(4, seed, map[string]float64{"PctCor": 0.8 + 0.01*float64(seed)})
+	}
+	dt := sa.Table()
+	if dt.Rows != 2 {
+		t.Fatalf("Table rows: %v, want 2\n", dt.Rows)
+	}
+	if dt.CellString("Param", 0) != "Layer.Act.Gbar.L" {
+		t.Errorf("Table not sorted by effect size: %v first\n", dt.CellString("Param", 0))
+	}
+	if d := dt.CellFloat("MinusD", 1); d != 0 {
+		t.Errorf("MinusD for no change: %v, want 0\n", d)
+	}
+	if d := dt.CellFloat("PlusD", 1); math.Abs(d-10) > 1e-6 {
+		t.Errorf("PlusD: %v, want 10\n", d)
+	}
+}
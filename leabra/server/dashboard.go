@@ -0,0 +1,330 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/emer/etable/etensor"
+)
+
+// DashSeries is a series of points of a DashPlot
+type DashSeries struct {
+	Name string    `desc:"name of the series, e.g., PctCor"`
+	X    []float64 `desc:"x values of the points"`
+	Y    []float64 `desc:"y values of the points"`
+}
+
+// DashPlot is a line plot of a Dashboard
+type DashPlot struct {
+	Name   string        `desc:"name of the plot, e.g., Train Epoch"`
+	Series []*DashSeries `desc:"the series of the plot, in order of addition"`
+}
+
+// series returns the series with given name, adding it if needed
+func (dp *DashPlot) series(name string) *DashSeries {
+	for _, sr := range dp.Series {
+		if sr.Name == name {
+			return sr
+		}
+	}
+	sr := &DashSeries{Name: name}
+	dp.Series = append(dp.Series, sr)
+	return sr
+}
+
+// DashHeatmap is a heatmap of a Dashboard, e.g., of the activity of a layer
+type DashHeatmap struct {
+	Name  string    `desc:"name of the heatmap, e.g., the name of the layer"`
+	Shape []int     `desc:"number of rows and columns"`
+	Vals  []float64 `desc:"the values, row by row"`
+}
+
+// Dashboard holds line plots (e.g., of the epoch stats and the sleep LaySim
+// traces) and heatmaps (e.g., of the activity of each layer) of a running
+// sim, and serves them over HTTP as a browser-based monitoring dashboard: a
+// page at / that polls the data at /data (as JSON), so that headless runs,
+// e.g., on cluster nodes where the GUI cannot run, can be monitored.  All
+// the methods are safe to call from the running sim while serving.
+type Dashboard struct {
+	Title  string `desc:"title of the page"`
+	MaxPts int    `desc:"maximum number of points of each series -- the oldest are dropped -- no limit if 0"`
+	mu     sync.Mutex
+	status string
+	plots  []*DashPlot
+	heats  []*DashHeatmap
+	srv    *http.Server
+}
+
+// SetStatus sets the status line shown under the title, e.g., the counters
+func (db *Dashboard) SetStatus(status string) {
+	db.mu.Lock()
+	db.status = status
+	db.mu.Unlock()
+}
+
+// plot returns the plot with given name, adding it if needed
+func (db *Dashboard) plot(name string) *DashPlot {
+	for _, dp := range db.plots {
+		if dp.Name == name {
+			return dp
+		}
+	}
+	dp := &DashPlot{Name: name}
+	db.plots = append(db.plots, dp)
+	return dp
+}
+
+// AddPoint adds a point to the series with given name of the plot with
+// given name, which are added as needed -- NaN and Inf values are skipped,
+// but still add the series, so that it is shown from the start
+func (db *Dashboard) AddPoint(plot, series string, x, y float64) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	sr := db.plot(plot).series(series)
+	if math.IsNaN(y) || math.IsInf(y, 0) {
+		return
+	}
+	sr.X = append(sr.X, x)
+	sr.Y = append(sr.Y, y)
+	if db.MaxPts > 0 && len(sr.X) > db.MaxPts {
+		n := len(sr.X) - db.MaxPts
+		sr.X = append(sr.X[:0], sr.X[n:]...)
+		sr.Y = append(sr.Y[:0], sr.Y[n:]...)
+	}
+}
+
+// ResetPlot removes the points of all the series of the plot with given
+// name, e.g., at the start of each sleep trial for its traces
+func (db *Dashboard) ResetPlot(plot string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, sr := range db.plot(plot).Series {
+		sr.X = sr.X[:0]
+		sr.Y = sr.Y[:0]
+	}
+}
+
+// SetHeatmap sets the heatmap with given name (added if needed) to the
+// values of given tensor: 2D as is, 4D (pools of units) as one 2D grid of
+// the units of the pools, and otherwise as one row
+func (db *Dashboard) SetHeatmap(name string, tsr etensor.Tensor) {
+	var shp []int
+	var vals []float64
+	val := func(i int) float64 {
+		v := tsr.FloatVal1D(i)
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0
+		}
+		return v
+	}
+	switch tsr.NumDims() {
+	case 2:
+		shp = []int{tsr.Dim(0), tsr.Dim(1)}
+		vals = make([]float64, tsr.Len())
+		for i := range vals {
+			vals[i] = val(i)
+		}
+	case 4:
+		npy, npx, nuy, nux := tsr.Dim(0), tsr.Dim(1), tsr.Dim(2), tsr.Dim(3)
+		shp = []int{npy * nuy, npx * nux}
+		vals = make([]float64, tsr.Len())
+		for py := 0; py < npy; py++ {
+			for px := 0; px < npx; px++ {
+				for uy := 0; uy < nuy; uy++ {
+					for ux := 0; ux < nux; ux++ {
+						i := ((py*npx+px)*nuy+uy)*nux + ux
+						vals[(py*nuy+uy)*shp[1]+px*nux+ux] = val(i)
+					}
+				}
+			}
+		}
+	default:
+		shp = []int{1, tsr.Len()}
+		vals = make([]float64, tsr.Len())
+		for i := range vals {
+			vals[i] = val(i)
+		}
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, hm := range db.heats {
+		if hm.Name == name {
+			hm.Shape = shp
+			hm.Vals = vals
+			return
+		}
+	}
+	db.heats = append(db.heats, &DashHeatmap{Name: name, Shape: shp, Vals: vals})
+}
+
+// WriteData writes the current status, plots and heatmaps as JSON
+func (db *Dashboard) WriteData(w io.Writer) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	b, err := json.Marshal(struct {
+		Title    string
+		Status   string
+		Plots    []*DashPlot
+		Heatmaps []*DashHeatmap
+	}{db.Title, db.status, db.plots, db.heats})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// ServeHTTP serves the dashboard page at / and its data at /data
+func (db *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/data") {
+		w.Header().Set("Content-Type", "application/json")
+		db.WriteData(w)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, dashPage)
+}
+
+// Start starts serving the dashboard on given address, e.g., :8090, in the
+// background -- returns an error if it cannot listen there
+func (db *Dashboard) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	db.srv = &http.Server{Handler: db}
+	go db.srv.Serve(ln)
+	return nil
+}
+
+// Stop stops serving
+func (db *Dashboard) Stop() {
+	if db.srv != nil {
+		db.srv.Close()
+		db.srv = nil
+	}
+}
+
+// dashPage is the dashboard page: it polls the data every 2 seconds, and
+// draws each plot and heatmap on a canvas
+const dashPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Leabra Dashboard</title>
+<style>
+body { font-family: sans-serif; margin: 16px; }
+.panel { display: inline-block; vertical-align: top; margin: 8px; }
+.panel h3 { font-size: 14px; margin: 4px 0; }
+canvas { border: 1px solid #ccc; }
+</style>
+</head>
+<body>
+<h2 id="title">Leabra Dashboard</h2>
+<div id="status"></div>
+<div id="plots"></div>
+<div id="heatmaps"></div>
+<script>
+var colors = ["#1f77b4", "#d62728", "#2ca02c", "#ff7f0e", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf"];
+
+function panel(parent, id, name, w, h) {
+	var cv = document.getElementById(id);
+	if (!cv) {
+		var div = document.createElement("div");
+		div.className = "panel";
+		var h3 = document.createElement("h3");
+		h3.textContent = name;
+		div.appendChild(h3);
+		cv = document.createElement("canvas");
+		cv.id = id;
+		div.appendChild(cv);
+		document.getElementById(parent).appendChild(div);
+	}
+	cv.width = w;
+	cv.height = h;
+	return cv;
+}
+
+function drawPlot(pl, idx) {
+	var cv = panel("plots", "plot" + idx, pl.Name, 480, 260);
+	var ctx = cv.getContext("2d");
+	var x0 = Infinity, x1 = -Infinity, y0 = Infinity, y1 = -Infinity;
+	pl.Series.forEach(function(sr) {
+		for (var i = 0; i < sr.X.length; i++) {
+			x0 = Math.min(x0, sr.X[i]); x1 = Math.max(x1, sr.X[i]);
+			y0 = Math.min(y0, sr.Y[i]); y1 = Math.max(y1, sr.Y[i]);
+		}
+	});
+	if (x0 > x1) { return; }
+	if (x1 == x0) { x1 = x0 + 1; }
+	if (y1 == y0) { y1 = y0 + 1; }
+	var l = 50, r = 110, t = 10, b = 25, w = cv.width - l - r, h = cv.height - t - b;
+	ctx.strokeStyle = "#000";
+	ctx.strokeRect(l, t, w, h);
+	ctx.fillStyle = "#000";
+	ctx.font = "11px sans-serif";
+	ctx.fillText(y1.toPrecision(3), 2, t + 10);
+	ctx.fillText(y0.toPrecision(3), 2, t + h);
+	ctx.fillText(x0.toPrecision(4), l, t + h + 15);
+	ctx.fillText(x1.toPrecision(4), l + w - 30, t + h + 15);
+	pl.Series.forEach(function(sr, si) {
+		var col = colors[si % colors.length];
+		ctx.strokeStyle = col;
+		ctx.beginPath();
+		for (var i = 0; i < sr.X.length; i++) {
+			var px = l + (sr.X[i] - x0) / (x1 - x0) * w;
+			var py = t + h - (sr.Y[i] - y0) / (y1 - y0) * h;
+			if (i == 0) { ctx.moveTo(px, py); } else { ctx.lineTo(px, py); }
+		}
+		ctx.stroke();
+		ctx.fillStyle = col;
+		ctx.fillText(sr.Name, l + w + 8, t + 12 + si * 14);
+	});
+}
+
+function drawHeatmap(hm, idx) {
+	var ny = hm.Shape[0], nx = hm.Shape[1];
+	var sz = Math.max(4, Math.min(24, Math.floor(240 / Math.max(nx, ny))));
+	var cv = panel("heatmaps", "heat" + idx, hm.Name, nx * sz, ny * sz);
+	var ctx = cv.getContext("2d");
+	var mx = 1;
+	hm.Vals.forEach(function(v) { mx = Math.max(mx, Math.abs(v)); });
+	for (var y = 0; y < ny; y++) {
+		for (var x = 0; x < nx; x++) {
+			var v = hm.Vals[y * nx + x] / mx;
+			var c = Math.round(255 * (1 - Math.abs(v)));
+			ctx.fillStyle = v >= 0 ? "rgb(255," + c + "," + c + ")" : "rgb(" + c + "," + c + ",255)";
+			ctx.fillRect(x * sz, (ny - 1 - y) * sz, sz, sz);
+		}
+	}
+}
+
+function update() {
+	fetch("data").then(function(resp) { return resp.json(); }).then(function(d) {
+		if (d.Title) { document.getElementById("title").textContent = d.Title; document.title = d.Title; }
+		document.getElementById("status").textContent = d.Status;
+		(d.Plots || []).forEach(drawPlot);
+		(d.Heatmaps || []).forEach(drawHeatmap);
+	}).catch(function(err) {
+		document.getElementById("status").textContent = "not connected: " + err;
+	});
+}
+
+update();
+setInterval(update, 2000);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,81 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/emer/etable/etensor"
+)
+
+func TestDashboard(t *testing.T) {
+	db := &Dashboard{Title: "Test", MaxPts: 3}
+	for epc := 0; epc < 5; epc++ {
+		db.AddPoint("Train Epoch", "PctCor", float64(epc), float64(epc)/10)
+	}
+	db.AddPoint("Train Epoch", "CosDiff", 5, math.NaN())
+	db.AddPoint("Sleep LaySim", "Hidden1", 0, 0.5)
+	db.ResetPlot("Sleep LaySim")
+	// 2x2 pools of 1x2 units: unit (py, px, uy, ux) has value 1D index
+	act := etensor.NewFloat32([]int{2, 2, 1, 2}, nil, nil)
+	for i := 0; i < act.Len(); i++ {
+		act.SetFloat1D(i, float64(i))
+	}
+	db.SetHeatmap("Hidden1", act)
+	db.SetStatus("Run: 0 Epoch: 5")
+	srv := httptest.NewServer(db)
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL + "/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d struct {
+		Title    string
+		Status   string
+		Plots    []*DashPlot
+		Heatmaps []*DashHeatmap
+	}
+	err = json.NewDecoder(resp.Body).Decode(&d)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Title != "Test" || d.Status != "Run: 0 Epoch: 5" || len(d.Plots) != 2 || len(d.Heatmaps) != 1 {
+		t.Fatalf("data: %+v\n", d)
+	}
+	if len(d.Plots[0].Series) != 2 || len(d.Plots[1].Series) != 1 {
+		t.Fatalf("series: %+v %+v\n", d.Plots[0], d.Plots[1])
+	}
+	pc := d.Plots[0].Series[0]
+	if len(pc.X) != 3 || pc.X[0] != 2 || pc.Y[2] != 0.4 {
+		t.Errorf("PctCor series not the last 3 points: %+v\n", pc)
+	}
+	if len(d.Plots[0].Series[1].X) != 0 {
+		t.Errorf("NaN point not skipped\n")
+	}
+	if len(d.Plots[1].Series[0].X) != 0 {
+		t.Errorf("sleep plot not reset\n")
+	}
+	hm := d.Heatmaps[0]
+	// row 0: pool (0,0) units 0,1 then pool (0,1) units 2,3
+	// row 1: pool (1,0) units 4,5 then pool (1,1) units 6,7
+	if hm.Shape[0] != 2 || hm.Shape[1] != 4 || hm.Vals[2] != 2 || hm.Vals[4] != 4 || hm.Vals[7] != 7 {
+		t.Errorf("heatmap: %+v\n", hm)
+	}
+	resp, err = srv.Client().Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(b), "fetch(\"data\")") {
+		t.Errorf("dashboard page not served\n")
+	}
+}
@@ -0,0 +1,12 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package server provides the HTTP services of a sim run headless: Server, a
+control API (REST over HTTP, with JSON responses, and gRPC, with the
+well-known protobuf Struct types) to run the commands of the sim, e.g., from
+Python or a workflow manager, and Dashboard, a browser-based monitoring page
+of plots and activity heatmaps.
+*/
+package server
@@ -0,0 +1,190 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// GRPCService is the full name of the gRPC service of a Server, which uses
+// only the well-known protobuf types, so that clients need no generated
+// code -- its methods, equivalent to those of the HTTP API, are:
+//
+//	rpc Run(google.protobuf.Struct) returns (google.protobuf.Struct);
+//	rpc Status(google.protobuf.Empty) returns (google.protobuf.Struct);
+//	rpc Names(google.protobuf.Empty) returns (google.protobuf.ListValue);
+//	rpc Quit(google.protobuf.Empty) returns (google.protobuf.Empty);
+//
+// Run takes the name of the command as Cmd and its args as the fields of
+// Args (e.g., {"Cmd": "train", "Args": {"epochs": 5}}), and returns the
+// ServerResp, Status the ServerStatus, and Names the names of the commands.
+// An unknown command is a NotFound error, and a failed one an Internal error.
+const GRPCService = "leabra.server.Server"
+
+// grpcService is the handler type of the gRPC service, implemented by Server
+type grpcService interface {
+	grpcRun(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error)
+	grpcStatus(ctx context.Context, in *emptypb.Empty) (*structpb.Struct, error)
+	grpcNames(ctx context.Context, in *emptypb.Empty) (*structpb.ListValue, error)
+	grpcQuit(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error)
+}
+
+// grpcDesc describes the gRPC service, as generated by protoc from the rpcs
+// listed in GRPCService
+var grpcDesc = grpc.ServiceDesc{
+	ServiceName: GRPCService,
+	HandlerType: (*grpcService)(nil),
+	Methods: []grpc.MethodDesc{
+		grpcMethod("Run", func() interface{} { return new(structpb.Struct) }, func(sv grpcService, ctx context.Context, in interface{}) (interface{}, error) {
+			return sv.grpcRun(ctx, in.(*structpb.Struct))
+		}),
+		grpcMethod("Status", func() interface{} { return new(emptypb.Empty) }, func(sv grpcService, ctx context.Context, in interface{}) (interface{}, error) {
+			return sv.grpcStatus(ctx, in.(*emptypb.Empty))
+		}),
+		grpcMethod("Names", func() interface{} { return new(emptypb.Empty) }, func(sv grpcService, ctx context.Context, in interface{}) (interface{}, error) {
+			return sv.grpcNames(ctx, in.(*emptypb.Empty))
+		}),
+		grpcMethod("Quit", func() interface{} { return new(emptypb.Empty) }, func(sv grpcService, ctx context.Context, in interface{}) (interface{}, error) {
+			return sv.grpcQuit(ctx, in.(*emptypb.Empty))
+		}),
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// grpcMethod returns the description of the unary method with given name,
+// which decodes its request into a new message from newIn and calls fun
+func grpcMethod(name string, newIn func() interface{}, fun func(sv grpcService, ctx context.Context, in interface{}) (interface{}, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := newIn()
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return fun(srv.(grpcService), ctx, in)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + GRPCService + "/" + name}
+			return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return fun(srv.(grpcService), ctx, req)
+			})
+		},
+	}
+}
+
+// RegisterGRPC registers the gRPC service of the commands on given gRPC
+// server, e.g., to serve it along with other services -- see StartGRPC
+func (sv *Server) RegisterGRPC(gs *grpc.Server) {
+	gs.RegisterService(&grpcDesc, sv)
+}
+
+// StartGRPC starts serving the gRPC service (see GRPCService) on given
+// address, e.g., :8081, in the background -- returns an error if it cannot
+// listen there.  It can be served along with the HTTP API of Start.
+func (sv *Server) StartGRPC(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	sv.ServeGRPC(ln)
+	return nil
+}
+
+// ServeGRPC serves the gRPC service on given listener in the background
+func (sv *Server) ServeGRPC(ln net.Listener) {
+	sv.quitChan()
+	sv.gsrv = grpc.NewServer()
+	sv.RegisterGRPC(sv.gsrv)
+	go sv.gsrv.Serve(ln)
+}
+
+func (sv *Server) grpcRun(ctx context.Context, in *structpb.Struct) (*structpb.Struct, error) {
+	name := in.GetFields()["Cmd"].GetStringValue()
+	if _, has := sv.Cmds[name]; !has {
+		return nil, status.Errorf(codes.NotFound, "unknown command: %v", name)
+	}
+	res, err := sv.Run(name, ArgsOfStruct(in.GetFields()["Args"].GetStructValue()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return toStruct(ServerResp{Cmd: name, Result: res})
+}
+
+func (sv *Server) grpcStatus(ctx context.Context, in *emptypb.Empty) (*structpb.Struct, error) {
+	return toStruct(sv.Status())
+}
+
+func (sv *Server) grpcNames(ctx context.Context, in *emptypb.Empty) (*structpb.ListValue, error) {
+	nms := sv.Names()
+	vals := make([]interface{}, len(nms))
+	for i, nm := range nms {
+		vals[i] = nm
+	}
+	return structpb.NewList(vals)
+}
+
+func (sv *Server) grpcQuit(ctx context.Context, in *emptypb.Empty) (*emptypb.Empty, error) {
+	sv.Quit()
+	return &emptypb.Empty{}, nil
+}
+
+// toStruct converts given value to a Struct via its JSON encoding, as sent
+// by the HTTP API
+func toStruct(val interface{}) (*structpb.Struct, error) {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	st := &structpb.Struct{}
+	if err := st.UnmarshalJSON(b); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return st, nil
+}
+
+// ArgsOfStruct returns the args of a command from the fields of given
+// Struct, as they would be given in the query of the HTTP API: numbers,
+// strings and bools as their string values, and lists as multiple values
+func ArgsOfStruct(st *structpb.Struct) url.Values {
+	args := url.Values{}
+	for nm, v := range st.GetFields() {
+		if lv := v.GetListValue(); lv != nil {
+			for _, iv := range lv.GetValues() {
+				args.Add(nm, argString(iv))
+			}
+			continue
+		}
+		args.Set(nm, argString(v))
+	}
+	return args
+}
+
+// argString returns the string value of an arg
+func argString(v *structpb.Value) string {
+	switch k := v.GetKind().(type) {
+	case *structpb.Value_StringValue:
+		return k.StringValue
+	case *structpb.Value_NumberValue:
+		return strconv.FormatFloat(k.NumberValue, 'f', -1, 64)
+	case *structpb.Value_BoolValue:
+		return strconv.FormatBool(k.BoolValue)
+	case *structpb.Value_NullValue, nil:
+		return ""
+	}
+	b, _ := v.MarshalJSON()
+	return string(b)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestServerGRPC(t *testing.T) {
+	sv := &Server{}
+	epc := 0
+	sv.Handle("train", func(args url.Values) (interface{}, error) {
+		n, err := ArgInt(args, "epochs", 1)
+		if err != nil {
+			return nil, err
+		}
+		epc += n
+		return map[string]int{"Epoch": epc}, nil
+	})
+	sv.Handle("fail", func(args url.Values) (interface{}, error) {
+		return nil, fmt.Errorf("failed")
+	})
+	ln := bufconn.Listen(1 << 20)
+	sv.ServeGRPC(ln)
+	defer sv.Stop()
+	cc, err := grpc.NewClient("passthrough:///bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+	ctx := context.Background()
+	run := func(cmd string, args map[string]interface{}, want codes.Code) *structpb.Struct {
+		in, err := structpb.NewStruct(map[string]interface{}{"Cmd": cmd, "Args": args})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := &structpb.Struct{}
+		err = cc.Invoke(ctx, "/"+GRPCService+"/Run", in, out)
+		if status.Code(err) != want {
+			t.Errorf("%v %v: error %v, want code %v\n", cmd, args, err, want)
+		}
+		return out
+	}
+	res := run("train", map[string]interface{}{"epochs": 3}, codes.OK)
+	if r := res.Fields["Result"].GetStructValue(); r.GetFields()["Epoch"].GetNumberValue() != 3 {
+		t.Errorf("train result: %v\n", res)
+	}
+	run("train", nil, codes.OK)
+	if epc != 4 {
+		t.Errorf("epochs: %d, want 4\n", epc)
+	}
+	run("train", map[string]interface{}{"epochs": "x"}, codes.Internal)
+	run("fail", nil, codes.Internal)
+	run("none", nil, codes.NotFound)
+
+	nms := &structpb.ListValue{}
+	if err := cc.Invoke(ctx, "/"+GRPCService+"/Names", &emptypb.Empty{}, nms); err != nil {
+		t.Fatal(err)
+	}
+	if got := nms.AsSlice(); len(got) != 2 || got[0] != "fail" || got[1] != "train" {
+		t.Errorf("names: %v\n", got)
+	}
+	st := &structpb.Struct{}
+	if err := cc.Invoke(ctx, "/"+GRPCService+"/Status", &emptypb.Empty{}, st); err != nil {
+		t.Fatal(err)
+	}
+	if st.Fields["NCmds"].GetNumberValue() != 4 || st.Fields["Cmd"] != nil {
+		t.Errorf("status: %v\n", st)
+	}
+
+	done := make(chan bool)
+	go func() {
+		sv.Wait()
+		done <- true
+	}()
+	if err := cc.Invoke(ctx, "/"+GRPCService+"/Quit", &emptypb.Empty{}, &emptypb.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Wait did not return after Quit\n")
+	}
+}
+
+func TestArgsOfStruct(t *testing.T) {
+	st, _ := structpb.NewStruct(map[string]interface{}{
+		"epochs": 5, "lrate": 0.04, "name": "a", "on": true, "lays": []interface{}{"Hidden1", "Hidden2"},
+	})
+	args := ArgsOfStruct(st)
+	want := url.Values{"epochs": {"5"}, "lrate": {"0.04"}, "name": {"a"}, "on": {"true"}, "lays": {"Hidden1", "Hidden2"}}
+	if args.Encode() != want.Encode() {
+		t.Errorf("args: %v, want %v\n", args, want)
+	}
+	if len(ArgsOfStruct(nil)) != 0 {
+		t.Errorf("args of nil Struct should be empty\n")
+	}
+}
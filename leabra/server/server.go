@@ -0,0 +1,224 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// ServerCmd is a command of a Server: it is called with the values of the
+// query (and form) of the request, and returns a result that is sent back
+// as JSON
+type ServerCmd func(args url.Values) (interface{}, error)
+
+// ServerResp is the JSON response of a Server to a command
+type ServerResp struct {
+	Cmd    string      `desc:"name of the command"`
+	Result interface{} `json:",omitempty" desc:"result of the command"`
+	Error  string      `json:",omitempty" desc:"error of the command, if any"`
+}
+
+// ServerStatus is the JSON response of a Server to /status
+type ServerStatus struct {
+	Cmd   string `json:",omitempty" desc:"name of the command currently running, if any"`
+	NCmds int    `desc:"number of commands run so far"`
+}
+
+// Server serves a control API of a sim over HTTP, so that experiments can
+// be orchestrated headless, e.g., from Python or a workflow manager,
+// without the GUI: each of the Cmds is served at /<name> (e.g.,
+// /train?epochs=5) and runs to completion before the request returns, one
+// command at a time, with its ServerResp sent back as JSON.  In addition,
+// / lists the commands, /status returns the ServerStatus without waiting
+// for the command running, and /quit ends Wait.  The same commands can also
+// be served over gRPC, with StartGRPC -- see GRPCService.
+type Server struct {
+	Cmds  map[string]ServerCmd `desc:"the commands, by name"`
+	mu    sync.Mutex           // serializes the commands
+	stmu  sync.Mutex           // protects the status and quit
+	cur   string
+	ncmds int
+	quit  chan struct{}
+	srv   *http.Server
+	gsrv  *grpc.Server
+}
+
+// Handle adds a command with given name, served at /<name>
+func (sv *Server) Handle(name string, fun ServerCmd) {
+	if sv.Cmds == nil {
+		sv.Cmds = make(map[string]ServerCmd)
+	}
+	sv.Cmds[name] = fun
+}
+
+// Names returns the names of the commands, sorted
+func (sv *Server) Names() []string {
+	nms := make([]string, 0, len(sv.Cmds))
+	for nm := range sv.Cmds {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	return nms
+}
+
+// Run runs the command with given name with given args, after any command
+// currently running -- an error if there is no such command
+func (sv *Server) Run(name string, args url.Values) (interface{}, error) {
+	fun, has := sv.Cmds[name]
+	if !has {
+		err := fmt.Errorf("Server: command: %v not found in: %v", name, strings.Join(sv.Names(), ", "))
+		log.Println(err)
+		return nil, err
+	}
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	sv.setCur(name)
+	defer sv.setCur("")
+	return fun(args)
+}
+
+// setCur sets the command currently running, counting it if any
+func (sv *Server) setCur(name string) {
+	sv.stmu.Lock()
+	sv.cur = name
+	if name != "" {
+		sv.ncmds++
+	}
+	sv.stmu.Unlock()
+}
+
+// Status returns the current status
+func (sv *Server) Status() ServerStatus {
+	sv.stmu.Lock()
+	defer sv.stmu.Unlock()
+	return ServerStatus{Cmd: sv.cur, NCmds: sv.ncmds}
+}
+
+// ServeHTTP serves the commands, and /, /status and /quit
+func (sv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.Trim(r.URL.Path, "/")
+	switch name {
+	case "":
+		writeJSON(w, http.StatusOK, sv.Names())
+		return
+	case "status":
+		writeJSON(w, http.StatusOK, sv.Status())
+		return
+	case "quit":
+		writeJSON(w, http.StatusOK, ServerResp{Cmd: name})
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		sv.Quit()
+		return
+	}
+	if _, has := sv.Cmds[name]; !has {
+		writeJSON(w, http.StatusNotFound, ServerResp{Cmd: name, Error: "unknown command"})
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, ServerResp{Cmd: name, Error: err.Error()})
+		return
+	}
+	res, err := sv.Run(name, r.Form)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, ServerResp{Cmd: name, Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, ServerResp{Cmd: name, Result: res})
+}
+
+// writeJSON writes given value as the JSON response with given status code
+func writeJSON(w http.ResponseWriter, code int, val interface{}) {
+	b, err := json.Marshal(val)
+	if err != nil {
+		code = http.StatusInternalServerError
+		b, _ = json.Marshal(ServerResp{Error: err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(b)
+}
+
+// Start starts serving on given address, e.g., :8080, in the background --
+// returns an error if it cannot listen there
+func (sv *Server) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	sv.quitChan()
+	sv.srv = &http.Server{Handler: sv}
+	go sv.srv.Serve(ln)
+	return nil
+}
+
+// quitChan returns the channel closed by Quit, making it if needed
+func (sv *Server) quitChan() chan struct{} {
+	sv.stmu.Lock()
+	defer sv.stmu.Unlock()
+	if sv.quit == nil {
+		sv.quit = make(chan struct{})
+	}
+	return sv.quit
+}
+
+// Quit ends Wait -- the commands are still served until Stop
+func (sv *Server) Quit() {
+	qc := sv.quitChan()
+	sv.stmu.Lock()
+	defer sv.stmu.Unlock()
+	select {
+	case <-qc:
+	default:
+		close(qc)
+	}
+}
+
+// Wait waits until Quit is called, e.g., by /quit or the Quit rpc
+func (sv *Server) Wait() {
+	<-sv.quitChan()
+}
+
+// Stop stops serving, over HTTP and gRPC -- any rpc running, e.g., Quit,
+// is completed first
+func (sv *Server) Stop() {
+	if sv.srv != nil {
+		sv.srv.Close()
+		sv.srv = nil
+	}
+	if sv.gsrv != nil {
+		sv.gsrv.GracefulStop()
+		sv.gsrv = nil
+	}
+}
+
+// ArgInt returns the int value of the arg with given name, or def if not
+// set -- an error if it is not an int
+func ArgInt(args url.Values, name string, def int) (int, error) {
+	str := args.Get(name)
+	if str == "" {
+		return def, nil
+	}
+	v, err := strconv.Atoi(str)
+	if err != nil {
+		err = fmt.Errorf("arg: %v: %v is not an int", name, str)
+		log.Println(err)
+		return def, err
+	}
+	return v, nil
+}
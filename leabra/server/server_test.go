@@ -0,0 +1,75 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	sv := &Server{}
+	epc := 0
+	sv.Handle("train", func(args url.Values) (interface{}, error) {
+		n, err := ArgInt(args, "epochs", 1)
+		if err != nil {
+			return nil, err
+		}
+		epc += n
+		return map[string]int{"Epoch": epc}, nil
+	})
+	sv.Handle("fail", func(args url.Values) (interface{}, error) {
+		return nil, fmt.Errorf("failed")
+	})
+	srv := httptest.NewServer(sv)
+	defer srv.Close()
+	get := func(path string, want int) map[string]interface{} {
+		resp, err := srv.Client().Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%v: status %d, want %d\n", path, resp.StatusCode, want)
+		}
+		res := map[string]interface{}{}
+		json.NewDecoder(resp.Body).Decode(&res)
+		return res
+	}
+	res := get("/train?epochs=3", http.StatusOK)
+	if r, ok := res["Result"].(map[string]interface{}); !ok || r["Epoch"] != 3.0 {
+		t.Errorf("train result: %v\n", res)
+	}
+	get("/train", http.StatusOK)
+	if epc != 4 {
+		t.Errorf("epochs: %d, want 4\n", epc)
+	}
+	if res = get("/train?epochs=x", http.StatusInternalServerError); res["Error"] == nil {
+		t.Errorf("expected error for a bad arg: %v\n", res)
+	}
+	if res = get("/fail", http.StatusInternalServerError); res["Error"] != "failed" {
+		t.Errorf("fail: %v\n", res)
+	}
+	get("/none", http.StatusNotFound)
+	if res = get("/status", http.StatusOK); res["NCmds"] != 4.0 || res["Cmd"] != nil {
+		t.Errorf("status: %v\n", res)
+	}
+	done := make(chan bool)
+	go func() {
+		sv.Wait()
+		done <- true
+	}()
+	get("/quit", http.StatusOK)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Wait did not return after /quit\n")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emer/etable/etable"
+)
+
+// SetSize varies the number of training patterns across runs, e.g., to
+// sweep the interaction of memory load (capacity) with sleep in one batch:
+// each run trains on a random subsample of Sizes[run % len(Sizes)] of the
+// patterns, stratified by the category of each pattern if Strat, so each
+// subsample has the same mix of categories as the whole set.
+type SetSize struct {
+	Sizes []int `desc:"number of training patterns of each run, cycling through the list over runs -- empty for all the patterns"`
+	Strat bool  `desc:"stratify the subsample by category: the rows of each category are subsampled in proportion to their number"`
+	Size  int   `inactive:"+" desc:"number of training patterns of the current run, as set by Subsample"`
+	Rows  []int `inactive:"+" desc:"rows of the table in the subsample of the current run"`
+}
+
+// On returns true if set sizes are configured
+func (sz *SetSize) On() bool {
+	return len(sz.Sizes) > 0
+}
+
+// SetString sets the Sizes from given numbers separated by , e.g.,
+// "10,25,50,100"
+func (sz *SetSize) SetString(sizes string) error {
+	fs := strings.Split(sizes, ",")
+	sz.Sizes = make([]int, 0, len(fs))
+	for _, f := range fs {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || n <= 0 {
+			sz.Sizes = nil
+			err = fmt.Errorf("SetSize: sizes must be numbers > 0 in: %v", sizes)
+			log.Println(err)
+			return err
+		}
+		sz.Sizes = append(sz.Sizes, n)
+	}
+	return nil
+}
+
+// Of returns the set size of given run
+func (sz *SetSize) Of(run int) int {
+	if !sz.On() {
+		return 0
+	}
+	return sz.Sizes[run%len(sz.Sizes)]
+}
+
+// Max returns the largest of the Sizes
+func (sz *SetSize) Max() int {
+	mx := 0
+	for _, n := range sz.Sizes {
+		if n > mx {
+			mx = n
+		}
+	}
+	return mx
+}
+
+// Subsample returns a view of n of the rows of given view using given
+// random number stream, stratified by the category of each row of its
+// table in tags (may be nil if not Strat) -- the rows are kept in their
+// order in the view, and all of them if n is not less than their number
+func (sz *SetSize) Subsample(ix *etable.IdxView, tags []string, n int, rnd *rand.Rand) (*etable.IdxView, error) {
+	if sz.Strat && len(tags) != ix.Table.Rows {
+		err := fmt.Errorf("SetSize: %d category tags for %d rows", len(tags), ix.Table.Rows)
+		log.Println(err)
+		return nil, err
+	}
+	if n > ix.Len() {
+		log.Printf("SetSize: set size: %d is more than the %d patterns -- using all of them\n", n, ix.Len())
+		n = ix.Len()
+	}
+	cats := map[string][]int{} // positions in the view of each category
+	var catNms []string
+	for i, row := range ix.Idxs {
+		tag := ""
+		if sz.Strat {
+			tag = tags[row]
+		}
+		if _, has := cats[tag]; !has {
+			catNms = append(catNms, tag)
+		}
+		cats[tag] = append(cats[tag], i)
+	}
+	sort.Strings(catNms) // the order of using rnd must not depend on the map
+	props := make([]float64, len(catNms))
+	for ci, cat := range catNms {
+		props[ci] = float64(len(cats[cat]))
+	}
+	ns := SplitCounts(props, n)
+	var sel []int
+	for ci, cat := range catNms {
+		pos := cats[cat]
+		perm := rnd.Perm(len(pos))
+		for _, pi := range perm[:ns[ci]] {
+			sel = append(sel, pos[pi])
+		}
+	}
+	sort.Ints(sel)
+	sub := etable.NewIdxView(ix.Table)
+	sub.Idxs = make([]int, len(sel))
+	for i, pi := range sel {
+		sub.Idxs[i] = ix.Idxs[pi]
+	}
+	sz.Size = n
+	sz.Rows = sub.Idxs
+	return sub, nil
+}
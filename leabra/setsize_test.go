@@ -0,0 +1,62 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestSetSize(t *testing.T) {
+	sz := &SetSize{Strat: true}
+	if err := sz.SetString("10, 20,40"); err != nil {
+		t.Fatal(err)
+	}
+	if sz.Of(0) != 10 || sz.Of(4) != 20 || sz.Max() != 40 {
+		t.Errorf("sizes: %v\n", sz.Sizes)
+	}
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+	}, 30)
+	tags := make([]string, 30)
+	for row := range tags {
+		dt.SetCellString("Name", row, fmt.Sprintf("p%d", row))
+		tags[row] = "Neutral"
+		if row%3 == 0 {
+			tags[row] = "Neg"
+		}
+	}
+	ix := etable.NewIdxView(dt)
+	sub, err := sz.Subsample(ix, tags, sz.Of(0), rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Len() != 10 || sz.Size != 10 {
+		t.Fatalf("subsample size: %d, want 10\n", sub.Len())
+	}
+	nneg := 0
+	for i, row := range sub.Idxs {
+		if tags[row] == "Neg" {
+			nneg++
+		}
+		if i > 0 && row <= sub.Idxs[i-1] {
+			t.Errorf("subsample rows not in order: %v\n", sub.Idxs)
+		}
+	}
+	if nneg != 3 {
+		t.Errorf("not stratified: %d Neg of 10, want 3\n", nneg)
+	}
+	if sub, _ = sz.Subsample(ix, tags, sz.Of(2), rand.New(rand.NewSource(1))); sub.Len() != 30 {
+		t.Errorf("set size larger than the patterns: %d, want all 30\n", sub.Len())
+	}
+	if sz.SetString("10,x") == nil || sz.On() {
+		t.Errorf("expected error for a bad size\n")
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/chewxy/math32"
+)
+
+// SettleRT measures the number of cycles a layer takes to settle in a trial,
+// as a reaction-time-like measure: the first cycle of the first run of K
+// cycles in which the maximum absolute change in activation of its units
+// (ActDel) stays below Thr.  Only the minus phase (the first 3 quarters) is
+// measured by Cycle, as the plus phase reflects the outcome rather than the
+// response.
+type SettleRT struct {
+	Layer   string  `desc:"name of the layer, e.g., the output layer"`
+	Thr     float32 `desc:"threshold on the maximum absolute ActDel of the units of the layer, below which the activity is stable on a cycle"`
+	K       int     `min:"1" desc:"number of consecutive stable cycles for the layer to have settled"`
+	Cyc     int     `inactive:"+" desc:"cycle at which the layer settled on the current trial, -1 if it has not"`
+	NCyc    int     `inactive:"+" desc:"number of cycles measured on the current trial"`
+	nstable int
+}
+
+func (sr *SettleRT) Defaults() {
+	sr.Layer = "Output"
+	sr.Thr = 0.01
+	sr.K = 5
+}
+
+// Init resets for the start of a trial
+func (sr *SettleRT) Init() {
+	sr.Cyc = -1
+	sr.NCyc = 0
+	sr.nstable = 0
+}
+
+// Cycle measures the current cycle of the Layer in given network, if it is
+// in the minus phase
+func (sr *SettleRT) Cycle(nt *Network, ltime *Time) error {
+	if ltime.Quarter >= 3 {
+		return nil
+	}
+	lyi, err := nt.LayerByNameTry(sr.Layer)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	ly, ok := lyi.(*Layer)
+	if !ok {
+		err = fmt.Errorf("SettleRT: layer: %v is not a leabra.Layer", sr.Layer)
+		log.Println(err)
+		return err
+	}
+	max := float32(0)
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		if nrn.IsOff() {
+			continue
+		}
+		if d := math32.Abs(nrn.ActDel); d > max {
+			max = d
+		}
+	}
+	sr.AddCycle(max)
+	return nil
+}
+
+// AddCycle adds a cycle with given maximum absolute change in activation
+func (sr *SettleRT) AddCycle(maxDel float32) {
+	cyc := sr.NCyc
+	sr.NCyc++
+	if sr.Cyc >= 0 {
+		return
+	}
+	if maxDel >= sr.Thr {
+		sr.nstable = 0
+		return
+	}
+	sr.nstable++
+	if sr.nstable >= sr.K {
+		sr.Cyc = cyc - sr.K + 1
+	}
+}
+
+// RT returns the settling cycle for the current trial, or the number of
+// cycles measured if the layer did not settle (i.e., the RT is censored at
+// the end of the minus phase)
+func (sr *SettleRT) RT() int {
+	if sr.Cyc < 0 {
+		return sr.NCyc
+	}
+	return sr.Cyc
+}
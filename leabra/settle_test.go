@@ -0,0 +1,28 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import "testing"
+
+func TestSettleRT(t *testing.T) {
+	sr := &SettleRT{}
+	sr.Defaults()
+	sr.K = 3
+	sr.Init()
+	// stable for 2 cycles (too few), then changes, then stable from cycle 5
+	for _, d := range []float32{0.2, 0.001, 0.001, 0.05, 0.02, 0.001, 0.002, 0.001, 0.3, 0.001} {
+		sr.AddCycle(d)
+	}
+	if sr.Cyc != 5 || sr.RT() != 5 {
+		t.Errorf("settled at: %v, want 5\n", sr.Cyc)
+	}
+	sr.Init()
+	for i := 0; i < 10; i++ {
+		sr.AddCycle(0.1)
+	}
+	if sr.Cyc != -1 || sr.RT() != 10 {
+		t.Errorf("not settled: Cyc %v RT %v, want -1, 10\n", sr.Cyc, sr.RT())
+	}
+}
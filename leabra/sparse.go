@@ -0,0 +1,191 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/emer/emergent/prjn"
+	"github.com/emer/etable/etensor"
+)
+
+// SparsePattern is a prjn.Pattern that can generate its connectivity directly
+// as lists of sending indexes per receiving unit, without going through the
+// full recv x send bit matrix that Connect returns.  For large, sparsely
+// connected layers the bit matrix dominates memory at build time (e.g.,
+// 100k x 100k units = 1.25 GB of bits for any density), so BuildStru uses
+// ConnectSparse whenever the pattern supports it.
+type SparsePattern interface {
+	prjn.Pattern
+
+	// ConnectSparse returns the number of connections per sending and receiving
+	// unit, and for each receiving unit the sorted list of sending unit indexes
+	// it receives from.
+	ConnectSparse(send, recv *etensor.Shape, same bool) (sendn, recvn *etensor.Int32, rcons [][]int32)
+}
+
+// MaxCons is the maximum total number of connections in a projection: the
+// connection and synapse indexes are 64-bit, but the index lists are slices,
+// so they are limited to the maximum int, which is only 32-bit on 32-bit
+// platforms.
+const MaxCons = int64(^uint(0) >> 1)
+
+// CheckConTotal returns an error if the total number of connections in given
+// per-unit counts exceeds MaxCons.  Totals are accumulated at 64 bits so
+// overflow is detected rather than wrapping.
+func (ps *PrjnStru) CheckConTotal(tn *etensor.Int32) error {
+	tot := int64(0)
+	for _, n := range tn.Values {
+		tot += int64(n)
+	}
+	if tot > MaxCons {
+		err := fmt.Errorf("PrjnStru: %v total connections %v exceeds index limit of %v on this platform -- split the projection", ps.String(), tot, MaxCons)
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// BuildStruSparse constructs the connectivity using the per-receiver
+// connection lists from a SparsePattern, which are processed one receiver at
+// a time so there is never a full recv x send matrix in memory.
+// The resulting index ordering is identical to BuildStru.
+func (ps *PrjnStru) BuildStruSparse(sp SparsePattern) error {
+	ssh := ps.Send.Shape()
+	rsh := ps.Recv.Shape()
+	sendn, recvn, rcons := sp.ConnectSparse(ssh, rsh, ps.Recv == ps.Send)
+	if err := ps.CheckConTotal(sendn); err != nil {
+		return err
+	}
+	slen := ssh.Len()
+	rlen := rsh.Len()
+	tcons := ps.SetNIdxSt(&ps.SConN, &ps.SConNAvgMax, &ps.SConIdxSt, sendn)
+	tconr := ps.SetNIdxSt(&ps.RConN, &ps.RConNAvgMax, &ps.RConIdxSt, recvn)
+	if tconr != tcons {
+		err := fmt.Errorf("PrjnStru: %v programmer error: total recv cons %v != total send cons %v", ps.String(), tconr, tcons)
+		log.Println(err)
+		return err
+	}
+	ps.RConIdx = make([]int32, tconr)
+	ps.RSynIdx = make([]int64, tconr)
+	ps.SConIdx = make([]int32, tcons)
+
+	sconN := make([]int32, slen) // temporary mem needed to tracks cur n of sending cons
+	for ri := 0; ri < rlen; ri++ {
+		rst := ps.RConIdxSt[ri]
+		if int32(len(rcons[ri])) != ps.RConN[ri] {
+			err := fmt.Errorf("PrjnStru: %v programmer error: recv idx: %v has %v cons, expected %v", ps.String(), ri, len(rcons[ri]), ps.RConN[ri])
+			log.Println(err)
+			return err
+		}
+		for rci, si := range rcons[ri] {
+			sst := ps.SConIdxSt[si]
+			sci := sconN[si]
+			if sci >= ps.SConN[si] {
+				err := fmt.Errorf("PrjnStru: %v programmer error: send target total con number: %v exceeded at recv idx: %v, send idx: %v", ps.String(), ps.SConN[si], ri, si)
+				log.Println(err)
+				return err
+			}
+			ps.RConIdx[rst+int64(rci)] = si
+			ps.SConIdx[sst+int64(sci)] = int32(ri)
+			ps.RSynIdx[rst+int64(rci)] = sst + int64(sci)
+			sconN[si]++
+		}
+		rcons[ri] = nil // release as we go
+	}
+	return nil
+}
+
+// SparseRnd is a uniform random connectivity pattern for large, sparsely
+// connected projections: each receiving unit gets round(PCon * nsend)
+// distinct senders.  It implements SparsePattern so that building does not
+// require a full recv x send bit matrix.
+type SparseRnd struct {
+	PCon    float32 `min:"0" max:"1" desc:"probability of connection (0-1)"`
+	SelfCon bool    `desc:"if true, and connecting layer to itself (self projection), then make a self-connection from unit to itself"`
+	RndSeed int64   `desc:"random number seed for the connectivity -- 0 = use global random number generator"`
+}
+
+// NewSparseRnd returns a new SparseRnd pattern with given probability of connection
+func NewSparseRnd(pcon float32) *SparseRnd {
+	return &SparseRnd{PCon: pcon}
+}
+
+func (sr *SparseRnd) Name() string {
+	return "SparseRnd"
+}
+
+// Connect satisfies prjn.Pattern, returning the full bit matrix -- only
+// suitable for smaller layers, as BuildStru uses ConnectSparse.
+func (sr *SparseRnd) Connect(send, recv *etensor.Shape, same bool) (sendn, recvn *etensor.Int32, cons *etensor.Bits) {
+	sendn, recvn, rcons := sr.ConnectSparse(send, recv, same)
+	slen := send.Len()
+	rlen := recv.Len()
+	cons = &etensor.Bits{}
+	cons.SetShape([]int{rlen, slen}, nil, []string{"Recv", "Send"})
+	for ri, scs := range rcons {
+		for _, si := range scs {
+			cons.Values.Set(ri*slen+int(si), true)
+		}
+	}
+	return
+}
+
+// ConnectSparse satisfies SparsePattern.  Senders for each receiver are drawn
+// by a partial shuffle of a single permutation buffer, so the cost per receiver
+// is proportional to its number of connections.
+func (sr *SparseRnd) ConnectSparse(send, recv *etensor.Shape, same bool) (sendn, recvn *etensor.Int32, rcons [][]int32) {
+	slen := send.Len()
+	rlen := recv.Len()
+	sendn = etensor.NewInt32([]int{slen}, nil, nil)
+	recvn = etensor.NewInt32([]int{rlen}, nil, nil)
+	rcons = make([][]int32, rlen)
+
+	rnd := rand.Intn
+	if sr.RndSeed != 0 {
+		rnd = rand.New(rand.NewSource(sr.RndSeed)).Intn
+	}
+	noself := same && !sr.SelfCon
+	navail := slen
+	if noself {
+		navail--
+	}
+	nsend := int(math.Round(float64(sr.PCon) * float64(navail)))
+	if nsend > navail {
+		nsend = navail
+	}
+	if nsend <= 0 {
+		return
+	}
+	perm := make([]int32, slen)
+	for i := range perm {
+		perm[i] = int32(i)
+	}
+	for ri := 0; ri < rlen; ri++ {
+		n := 0
+		for i := 0; n < nsend && i < slen; i++ {
+			j := i + rnd(slen-i)
+			perm[i], perm[j] = perm[j], perm[i]
+			if noself && int(perm[i]) == ri {
+				continue
+			}
+			perm[n], perm[i] = perm[i], perm[n]
+			n++
+		}
+		scs := make([]int32, n)
+		copy(scs, perm[:n])
+		sort.Slice(scs, func(a, b int) bool { return scs[a] < scs[b] })
+		rcons[ri] = scs
+		recvn.Values[ri] = int32(n)
+		for _, si := range scs {
+			sendn.Values[si]++
+		}
+	}
+	return
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+)
+
+// densePat hides ConnectSparse so that BuildStru uses the bit matrix
+type densePat struct {
+	prjn.Pattern
+}
+
+func sparseTestNet(pat prjn.Pattern) *Network {
+	net := &Network{}
+	net.InitName(net, "SparseNet")
+	inLay := net.AddLayer2D("Input", 10, 10, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 10, 10, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, pat, emer.Forward)
+	net.ConnectLayers(hidLay, hidLay, pat, emer.Lateral)
+	net.Defaults()
+	net.Build()
+	return net
+}
+
+func TestSparseRnd(t *testing.T) {
+	spat := &SparseRnd{PCon: 0.2, RndSeed: 1}
+	snet := sparseTestNet(spat)
+	dnet := sparseTestNet(&densePat{&SparseRnd{PCon: 0.2, RndSeed: 1}})
+	shid := snet.LayerByName("Hidden").(*Layer)
+	dhid := dnet.LayerByName("Hidden").(*Layer)
+	for pi := range shid.RcvPrjns {
+		spj := shid.RcvPrjns[pi].(*Prjn)
+		dpj := dhid.RcvPrjns[pi].(*Prjn)
+		for ri, n := range spj.RConN {
+			if n != 20 {
+				t.Errorf("%v recv %v: %v cons, want 20\n", spj.Name(), ri, n)
+			}
+		}
+		if !reflect.DeepEqual(spj.RConIdx, dpj.RConIdx) || !reflect.DeepEqual(spj.SConIdx, dpj.SConIdx) ||
+			!reflect.DeepEqual(spj.RSynIdx, dpj.RSynIdx) {
+			t.Errorf("%v sparse and dense build indexes differ\n", spj.Name())
+		}
+	}
+	lat := shid.RcvPrjns.SendName("Hidden").(*Prjn)
+	for ri := range lat.RConN {
+		st := lat.RConIdxSt[ri]
+		for _, si := range lat.RConIdx[st : st+int64(lat.RConN[ri])] {
+			if int(si) == ri {
+				t.Errorf("self connection at recv %v with SelfCon off\n", ri)
+			}
+		}
+	}
+	nm := snet.Mem()
+	if nm.Syns != 4000 || nm.Neurons != 200 {
+		t.Errorf("Mem: got %v syns, %v neurons, want 4000, 200\n", nm.Syns, nm.Neurons)
+	}
+}
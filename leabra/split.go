@@ -0,0 +1,197 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+// SplitNames are the standard names of the splits of a DataSplit, in order
+var SplitNames = []string{"Train", "Test", "Valid"}
+
+// DataSplit splits the rows of a table of patterns at random into Train,
+// Test and optionally Valid (validation) subsets with given proportions,
+// stratified by the category of each row if Strat: the rows of each
+// category are split separately, so each split has the same mix of
+// categories as the whole table.  The split of each row is recorded, to be
+// saved for reproducibility (see SaveCSV).
+type DataSplit struct {
+	Props  []float64         `desc:"proportion of the rows in each split, in the order of SplitNames (Train, Test, Valid) -- normalized to sum to 1 -- empty for no split"`
+	Strat  bool              `desc:"stratify by category: split the rows of each category separately in the Props proportions"`
+	Splits []*etable.IdxView `view:"-" desc:"the views of the table for each split, made by Split"`
+	Tags   []string          `inactive:"+" desc:"category of each row of the table, as used by Split"`
+	Of     []int             `inactive:"+" desc:"index of the split of each row of the table"`
+}
+
+// On returns true if a split is configured
+func (ds *DataSplit) On() bool {
+	return len(ds.Props) > 1
+}
+
+// SetString sets the Props from given proportions separated by , e.g.,
+// "0.8,0.2" for Train and Test, or ".7,.15,.15" with a Valid split too
+func (ds *DataSplit) SetString(props string) error {
+	fs := strings.Split(props, ",")
+	if len(fs) < 2 || len(fs) > len(SplitNames) {
+		err := fmt.Errorf("DataSplit: expected 2 or 3 proportions for %v, got: %v", strings.Join(SplitNames, ", "), props)
+		log.Println(err)
+		return err
+	}
+	ds.Props = make([]float64, len(fs))
+	for i, f := range fs {
+		p, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil || p < 0 {
+			err = fmt.Errorf("DataSplit: proportion must be a number >= 0 in: %v", props)
+			log.Println(err)
+			return err
+		}
+		ds.Props[i] = p
+	}
+	return nil
+}
+
+// Split splits the rows of given table in the Props proportions using given
+// random number stream, with the category of each row in tags (may be nil
+// if not Strat) -- the number of rows of each split (per category) is the
+// proportion times the number of rows, rounded so they add up to it
+func (ds *DataSplit) Split(dt *etable.Table, tags []string, rnd *rand.Rand) error {
+	if !ds.On() {
+		err := fmt.Errorf("DataSplit: no proportions set")
+		log.Println(err)
+		return err
+	}
+	if ds.Strat && len(tags) != dt.Rows {
+		err := fmt.Errorf("DataSplit: %d category tags for %d rows", len(tags), dt.Rows)
+		log.Println(err)
+		return err
+	}
+	ds.Tags = make([]string, dt.Rows)
+	if ds.Strat {
+		copy(ds.Tags, tags)
+	}
+	cats := map[string][]int{}
+	var catNms []string
+	for row, tag := range ds.Tags {
+		if _, has := cats[tag]; !has {
+			catNms = append(catNms, tag)
+		}
+		cats[tag] = append(cats[tag], row)
+	}
+	sort.Strings(catNms) // the order of using rnd must not depend on the map
+	ds.Of = make([]int, dt.Rows)
+	ds.Splits = make([]*etable.IdxView, len(ds.Props))
+	for si := range ds.Splits {
+		ds.Splits[si] = etable.NewIdxView(dt)
+		ds.Splits[si].Idxs = nil
+	}
+	for _, cat := range catNms {
+		rows := cats[cat]
+		ns := SplitCounts(ds.Props, len(rows))
+		perm := rnd.Perm(len(rows))
+		pi := 0
+		for si, n := range ns {
+			for i := 0; i < n; i++ {
+				row := rows[perm[pi]]
+				ds.Of[row] = si
+				ds.Splits[si].Idxs = append(ds.Splits[si].Idxs, row)
+				pi++
+			}
+		}
+	}
+	for _, sp := range ds.Splits {
+		sort.Ints(sp.Idxs)
+	}
+	return nil
+}
+
+// SplitCounts returns the number of items of each split for n items in the
+// given proportions, rounded by largest remainder so they add up to n
+func SplitCounts(props []float64, n int) []int {
+	sum := 0.0
+	for _, p := range props {
+		sum += p
+	}
+	ns := make([]int, len(props))
+	if sum <= 0 {
+		return ns
+	}
+	rems := make([]float64, len(props))
+	tot := 0
+	for i, p := range props {
+		v := p / sum * float64(n)
+		ns[i] = int(math.Floor(v))
+		rems[i] = v - float64(ns[i])
+		tot += ns[i]
+	}
+	for ; tot < n; tot++ {
+		mx := 0
+		for i := range rems {
+			if rems[i] > rems[mx] {
+				mx = i
+			}
+		}
+		ns[mx]++
+		rems[mx] = -1
+	}
+	return ns
+}
+
+// View returns the view of the split with given name (Train, Test or
+// Valid), or nil if not split
+func (ds *DataSplit) View(name string) *etable.IdxView {
+	for si, nm := range SplitNames {
+		if nm == name && si < len(ds.Splits) {
+			return ds.Splits[si]
+		}
+	}
+	return nil
+}
+
+// Table returns a table with the split of each row of the table: Row, the
+// Name in given column of the table, Cat, and Split
+func (ds *DataSplit) Table(nameCol string) *etable.Table {
+	dt := &etable.Table{}
+	dt.SetMetaData("name", "DataSplit")
+	dt.SetFromSchema(etable.Schema{
+		{"Row", etensor.INT64, nil, nil},
+		{"Name", etensor.STRING, nil, nil},
+		{"Cat", etensor.STRING, nil, nil},
+		{"Split", etensor.STRING, nil, nil},
+	}, len(ds.Of))
+	if len(ds.Splits) == 0 {
+		return dt
+	}
+	pats := ds.Splits[0].Table
+	nc := pats.ColByName(nameCol)
+	for row, si := range ds.Of {
+		dt.SetCellFloat("Row", row, float64(row))
+		if nc != nil {
+			dt.SetCellString("Name", row, nc.StringVal1D(row))
+		}
+		dt.SetCellString("Cat", row, ds.Tags[row])
+		dt.SetCellString("Split", row, SplitNames[si])
+	}
+	return dt
+}
+
+// SaveCSV saves the split of each row of the table (see Table) to given
+// CSV file
+func (ds *DataSplit) SaveCSV(nameCol string, filename gi.FileName) error {
+	err := ds.Table(nameCol).SaveCSV(filename, etable.Tab, true)
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
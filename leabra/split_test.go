@@ -0,0 +1,71 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+func TestDataSplit(t *testing.T) {
+	if ns := SplitCounts([]float64{.7, .15, .15}, 10); ns[0] != 7 || ns[1]+ns[2] != 3 {
+		t.Errorf("counts: %v\n", ns)
+	}
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Name", etensor.STRING, nil, nil},
+	}, 25)
+	tags := make([]string, 25)
+	for row := range tags {
+		dt.SetCellString("Name", row, fmt.Sprintf("p%d", row))
+		tags[row] = "Neutral"
+		if row < 10 {
+			tags[row] = "Neg"
+		}
+	}
+	ds := &DataSplit{Strat: true}
+	if err := ds.SetString("0.8, 0.2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.Split(dt, tags, rand.New(rand.NewSource(1))); err != nil {
+		t.Fatal(err)
+	}
+	trn, tst := ds.View("Train"), ds.View("Test")
+	if trn.Len() != 20 || tst.Len() != 5 || ds.View("Valid") != nil {
+		t.Fatalf("split sizes: %v %v\n", trn.Len(), tst.Len())
+	}
+	nneg := 0
+	for _, row := range tst.Idxs {
+		if tags[row] == "Neg" {
+			nneg++
+		}
+		if ds.Of[row] != 1 {
+			t.Errorf("row %d in Test has split %d\n", row, ds.Of[row])
+		}
+	}
+	if nneg != 2 {
+		t.Errorf("not stratified: %d Neg of 5 in Test, want 2\n", nneg)
+	}
+
+	ds2 := &DataSplit{Strat: true, Props: ds.Props}
+	ds2.Split(dt, tags, rand.New(rand.NewSource(1)))
+	for i, row := range ds2.View("Test").Idxs {
+		if row != tst.Idxs[i] {
+			t.Errorf("split not reproducible: %v vs %v\n", ds2.View("Test").Idxs, tst.Idxs)
+			break
+		}
+	}
+	st := ds.Table("Name")
+	if st.Rows != 25 || st.CellString("Name", 3) != "p3" || st.CellString("Split", tst.Idxs[0]) != "Test" {
+		t.Errorf("split table wrong\n")
+	}
+	if ds.SetString("1") == nil {
+		t.Errorf("expected error for one proportion\n")
+	}
+}
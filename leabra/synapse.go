@@ -31,12 +31,15 @@ type Synapse struct {
 
 var SynapseVars = []string{"Wt", "LWt", "DWt", "Norm", "Moment", "Scale", "SRAvgDp", "Cai", "Effwt", "Ca_inc", "Ca_dec", "sd_ca_thr", "sd_ca_gain", "sd_ca_thr_rescale"}
 
+// SynapseVarsMap maps the SynapseVars to the index of their field in Synapse
 var SynapseVarsMap map[string]int
 
 func init() {
 	SynapseVarsMap = make(map[string]int, len(SynapseVars))
-	for i, v := range SynapseVars {
-		SynapseVarsMap[v] = i
+	typ := reflect.TypeOf(Synapse{})
+	for _, v := range SynapseVars {
+		fld, _ := typ.FieldByName(v) // note: not all fields are vars, e.g., PDW
+		SynapseVarsMap[v] = fld.Index[0]
 	}
 }
 
@@ -51,7 +54,7 @@ func (sy *Synapse) VarByName(varNm string) (float32, bool) {
 	}
 	// todo: would be ideal to avoid having to use reflect here..
 	v := reflect.ValueOf(sy)
-	return v.Elem().Field(i).Interface().(float32), true
+	return float32(v.Elem().Field(i).Float()), true
 }
 
 func (sy *Synapse) SetVarByName(varNm string, val float64) bool {
@@ -60,8 +63,11 @@ func (sy *Synapse) SetVarByName(varNm string, val float64) bool {
 		return false
 	}
 	// todo: would be ideal to avoid having to use reflect here..
-	v := reflect.ValueOf(sy)
-	v.Elem().Field(i).SetFloat(val)
+	v := reflect.ValueOf(sy).Elem().Field(i)
+	if !v.CanSet() { // unexported, e.g., sd_ca_thr
+		return false
+	}
+	v.SetFloat(val)
 	return true
 }
 
@@ -0,0 +1,96 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import "sort"
+
+// SynChunkSize is the maximum number of synapses allocated together in one
+// chunk of Synapses -- a chunk always holds all of the synapses of each of
+// its sending units, so it is larger if a single unit has more.
+var SynChunkSize = 1 << 20
+
+// Synapses holds the synaptic state of a projection, ordered by the sending
+// units which own them, and addressed by 64-bit index in that order (as in
+// SConIdxSt and RSynIdx).  They are allocated in chunks of up to SynChunkSize
+// synapses of whole sending units, so that very large projections do not need
+// a single contiguous allocation, while the synapses of each sending unit
+// are still contiguous, for the sending-side loops (see Range).
+type Synapses struct {
+	Chunks [][]Synapse `desc:"the synapses, in chunks of whole sending units"`
+	St     []int64     `view:"-" desc:"index of the first synapse of each chunk"`
+	N      int64       `desc:"total number of synapses"`
+}
+
+// Alloc allocates new synapses for sending units with given numbers of
+// synapses, in chunks of up to SynChunkSize synapses of whole units.
+func (sy *Synapses) Alloc(sendn []int32) {
+	sy.Chunks, sy.St, sy.N = nil, nil, 0
+	cn := int64(0) // synapses in current chunk
+	for _, n := range sendn {
+		if cn > 0 && cn+int64(n) > int64(SynChunkSize) {
+			sy.addChunk(cn)
+			cn = 0
+		}
+		cn += int64(n)
+	}
+	if cn > 0 {
+		sy.addChunk(cn)
+	}
+}
+
+// addChunk adds a new chunk of n synapses
+func (sy *Synapses) addChunk(n int64) {
+	sy.Chunks = append(sy.Chunks, make([]Synapse, n))
+	sy.St = append(sy.St, sy.N)
+	sy.N += n
+}
+
+// SetChunks sets the synapses to a copy of given chunks
+func (sy *Synapses) SetChunks(chs [][]Synapse) {
+	sy.Chunks, sy.St, sy.N = nil, nil, 0
+	for _, ch := range chs {
+		if len(ch) == 0 {
+			continue
+		}
+		sy.addChunk(int64(len(ch)))
+		copy(sy.Chunks[len(sy.Chunks)-1], ch)
+	}
+}
+
+// CopyFrom sets the synapses to a copy of given ones, with the same chunks
+func (sy *Synapses) CopyFrom(fs *Synapses) {
+	sy.SetChunks(fs.Chunks)
+}
+
+// Len returns the total number of synapses
+func (sy *Synapses) Len() int64 {
+	return sy.N
+}
+
+// chunk returns the chunk holding the synapse at given index
+func (sy *Synapses) chunk(i int64) int {
+	if len(sy.St) == 1 {
+		return 0
+	}
+	return sort.Search(len(sy.St), func(c int) bool { return sy.St[c] > i }) - 1
+}
+
+// At returns the synapse at given index
+func (sy *Synapses) At(i int64) *Synapse {
+	c := sy.chunk(i)
+	return &sy.Chunks[c][i-sy.St[c]]
+}
+
+// Range returns the n synapses starting at given index, which must be within
+// one chunk, as are all of the synapses of a sending unit, e.g.,
+// Range(SConIdxSt[si], SConN[si]).
+func (sy *Synapses) Range(st int64, n int32) []Synapse {
+	if n == 0 {
+		return nil
+	}
+	c := sy.chunk(st)
+	ci := st - sy.St[c]
+	return sy.Chunks[c][ci : ci+int64(n)]
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/etable/etensor"
+)
+
+// chunkNet returns a sparseTestNet built with given SynChunkSize
+func chunkNet(chunk int) *Network {
+	defer func(cs int) { SynChunkSize = cs }(SynChunkSize)
+	SynChunkSize = chunk
+	net := sparseTestNet(&SparseRnd{PCon: 0.2, RndSeed: 1})
+	net.SeedPrjnRnd(1)
+	net.InitWts()
+	return net
+}
+
+// chunkTrial runs one learning trial with given input
+func chunkTrial(nt *Network, inpat *etensor.Float32) {
+	nt.InitExt()
+	nt.LayerByName("Input").(*Layer).ApplyExt(inpat)
+	ltime := NewTime()
+	nt.AlphaCycInit()
+	ltime.AlphaCycStart()
+	for qtr := 0; qtr < 4; qtr++ {
+		for cyc := 0; cyc < ltime.CycPerQtr; cyc++ {
+			nt.Cycle(ltime, false)
+			ltime.CycleInc()
+		}
+		nt.QuarterFinal(ltime)
+		ltime.QuarterInc()
+	}
+	nt.DWt()
+	nt.WtFmDWt()
+}
+
+func TestSynChunks(t *testing.T) {
+	net := chunkNet(1 << 20)
+	cnet := chunkNet(50) // 2-3 sending units of 20 synapses per chunk
+	for _, nm := range []string{"InputToHidden", "HiddenToHidden"} {
+		pj := net.PrjnByName(nm)
+		cpj := cnet.PrjnByName(nm)
+		if len(pj.Syns.Chunks) != 1 || len(cpj.Syns.Chunks) < 40 || cpj.Syns.Len() != pj.Syns.Len() {
+			t.Fatalf("%v: %v and %v chunks of %v and %v synapses\n", nm, len(pj.Syns.Chunks), len(cpj.Syns.Chunks), pj.Syns.Len(), cpj.Syns.Len())
+		}
+		for si, n := range cpj.SConN {
+			if len(cpj.Syns.Range(cpj.SConIdxSt[si], n)) != int(n) {
+				t.Errorf("%v: send unit %v synapses not in one chunk\n", nm, si)
+			}
+		}
+	}
+
+	inpat := etensor.NewFloat32([]int{10, 10}, nil, nil)
+	for i := 0; i < 3; i++ {
+		for j := range inpat.Values {
+			inpat.Values[j] = 0
+		}
+		for j := 0; j < 10; j++ {
+			inpat.Values[(i*37+j*11)%100] = 1
+		}
+		chunkTrial(net, inpat)
+		chunkTrial(cnet, inpat)
+	}
+	for _, nm := range []string{"InputToHidden", "HiddenToHidden"} {
+		wts := net.PrjnByName(nm).SynVals("Wt")
+		cwts := cnet.PrjnByName(nm).SynVals("Wt")
+		for si := range wts {
+			if wts[si] != cwts[si] {
+				t.Fatalf("%v syn %v: chunked Wt: %v != Wt: %v\n", nm, si, cwts[si], wts[si])
+			}
+		}
+	}
+
+	// chunks are kept through the state, e.g., in a Checkpoint
+	st := cnet.State()
+	cpj := cnet.PrjnByName("HiddenToHidden")
+	nch := len(cpj.Syns.Chunks)
+	cpj.Syns.At(7).Wt = 2
+	if err := cnet.SetState(&st); err != nil {
+		t.Fatal(err)
+	}
+	if len(cpj.Syns.Chunks) != nch || cpj.Syns.At(7).Wt == 2 {
+		t.Errorf("SetState: %v chunks, want %v, syn 7 Wt: %v\n", len(cpj.Syns.Chunks), nch, cpj.Syns.At(7).Wt)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+// SynDepParams are the synaptic depression parameters for a projection,
+// based on the intracellular calcium (Cai) driven by sender-receiver
+// co-activation, which depletes the effective weight (Effwt) during sleep.
+// These are set through the usual params selectors (e.g., Prjn.SynDep.CaDec)
+// and copied into each synapse in InitSdEffWt, at the start of sleep.
+type SynDepParams struct {
+	CaInc     float32 `def:"0.6" desc:"rate of increase in Cai (from NMDA etc currents), as a function of sender-receiver co-activation times the effective weight"`
+	CaDec     float32 `def:"0.25" desc:"rate of decrease in Cai (from Ca pumps pushing Ca back out into the synapse)"`
+	Rec       float32 `def:"0.002" desc:"rate of recovery from depression"`
+	CaThr     float32 `def:"0" min:"0" max:"1" desc:"synaptic depression Cai threshold: only when Cai has increased by this amount (thus synaptic Ca depleted) does it affect the effective weight"`
+	CaGain    float32 `def:"3" desc:"multiplier on Cai value for computing synaptic depression -- modulates overall level of depression independent of rate parameters"`
+	CaRescale float32 `inactive:"+" view:"-" json:"-" desc:"rescaling factor taking into account CaGain and CaThr (= CaGain / (1 - CaThr))"`
+}
+
+func (sd *SynDepParams) Update() {
+	sd.CaRescale = sd.CaGain / (1 - sd.CaThr)
+}
+
+func (sd *SynDepParams) Defaults() {
+	sd.CaInc = 0.6
+	sd.CaDec = 0.25
+	sd.Rec = 0.002
+	sd.CaThr = 0
+	sd.CaGain = 3
+	sd.Update()
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/params"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestSynDepParams(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "SynDepNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	net.InitWts()
+	net.InitSdEffWt()
+	pj := net.PrjnByName("InputToHidden")
+	if sy := pj.Syns.At(0); sy.Ca_dec != 0.25 || sy.Ca_inc != 0.6 || sy.sd_ca_thr_rescale != 3 {
+		t.Errorf("default syndep wrong: %+v\n", *sy)
+	}
+	net.ApplyParams(&params.Sheet{
+		{Sel: "Prjn", Params: params.Params{"Prjn.SynDep.CaDec": "0.05", "Prjn.SynDep.CaThr": "0.5"}},
+	}, false)
+	net.InitSdEffWt()
+	for si := int64(0); si < pj.Syns.Len(); si++ {
+		sy := pj.Syns.At(si)
+		if sy.Ca_dec != 0.05 || sy.sd_ca_thr != 0.5 || sy.sd_ca_thr_rescale != 6 {
+			t.Fatalf("syndep params not applied: %+v\n", *sy)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// SynInspectVal is the current value of a variable of the synapse of a
+// SynInspect
+type SynInspectVal struct {
+	Var string  `desc:"name of the variable"`
+	Val float32 `desc:"current value"`
+}
+
+// SynInspect inspects a single synapse, from a sending to a receiving unit,
+// e.g., for debugging the synaptic depression dynamics at single-synapse
+// resolution: Update reads all of its SynapseVars into Vals, and adds them
+// as a row of the Trace, e.g., every cycle of sleep and every training
+// trial, for plotting.
+type SynInspect struct {
+	On      bool            `desc:"if true, Update records the synapse"`
+	SendLay string          `inactive:"+" desc:"name of the sending layer"`
+	SendIdx int             `inactive:"+" desc:"1D index of the sending unit"`
+	RecvLay string          `inactive:"+" desc:"name of the receiving layer"`
+	RecvIdx int             `inactive:"+" desc:"1D index of the receiving unit"`
+	MaxRows int             `desc:"maximum number of rows of the Trace -- the oldest are dropped -- no limit if 0"`
+	Vals    []SynInspectVal `inactive:"+" desc:"current values of the SynapseVars of the synapse, as of the last Update"`
+	Trace   *etable.Table   `view:"no-inline" desc:"the values of each Update: the Step (number of the update), its Mode (e.g., Sleep) and Cycle, and the SynapseVars"`
+	pj      *Prjn
+	syi     int64
+	step    int
+}
+
+func (si *SynInspect) Defaults() {
+	si.MaxRows = 5000
+}
+
+// Name returns the name of the synapse, e.g., Input[3] -> Hidden1[5], or
+// "" if none is set
+func (si *SynInspect) Name() string {
+	if si.pj == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v[%d] -> %v[%d]", si.SendLay, si.SendIdx, si.RecvLay, si.RecvIdx)
+}
+
+// Set sets the synapse to inspect, from the sending unit with given 1D
+// index of layer send to the receiving unit of layer recv, in given
+// network, turning it On and resetting the Trace -- an error if there is
+// no such synapse
+func (si *SynInspect) Set(nt *Network, send string, sidx int, recv string, ridx int) error {
+	rl, err := nt.LayerByNameTry(recv)
+	if err != nil {
+		return err
+	}
+	rlay := rl.(LeabraLayer).AsLeabra()
+	if ridx < 0 || ridx >= len(rlay.Neurons) {
+		err = fmt.Errorf("SynInspect: recv unit index: %v out of range for layer: %v, N = %v", ridx, recv, len(rlay.Neurons))
+		log.Println(err)
+		return err
+	}
+	for _, p := range rlay.RcvPrjns {
+		pj := p.(LeabraPrjn).AsLeabra()
+		if pj.Send.Name() != send {
+			continue
+		}
+		nc := int(pj.RConN[ridx])
+		st := int(pj.RConIdxSt[ridx])
+		for ci := 0; ci < nc; ci++ {
+			if int(pj.RConIdx[st+ci]) != sidx {
+				continue
+			}
+			si.pj = pj
+			si.syi = pj.RSynIdx[st+ci]
+			si.SendLay, si.SendIdx = send, sidx
+			si.RecvLay, si.RecvIdx = recv, ridx
+			si.On = true
+			si.config()
+			return nil
+		}
+	}
+	err = fmt.Errorf("SynInspect: no synapse from %v[%d] to %v[%d]", send, sidx, recv, ridx)
+	log.Println(err)
+	return err
+}
+
+// config configures the Vals and the Trace
+func (si *SynInspect) config() {
+	si.Vals = make([]SynInspectVal, len(SynapseVars))
+	sch := etable.Schema{
+		{"Step", etensor.INT64, nil, nil},
+		{"Mode", etensor.STRING, nil, nil},
+		{"Cycle", etensor.INT64, nil, nil},
+	}
+	for i, v := range SynapseVars {
+		si.Vals[i].Var = v
+		sch = append(sch, etable.Column{v, etensor.FLOAT64, nil, nil})
+	}
+	if si.Trace == nil {
+		si.Trace = &etable.Table{}
+	}
+	si.Trace.SetMetaData("name", "SynInspect")
+	si.Trace.SetMetaData("desc", "values of the synapse "+si.Name()+" by update")
+	si.Trace.SetFromSchema(sch, 0)
+	si.step = 0
+	si.Read()
+}
+
+// Synapse returns the synapse, or nil if none is set
+func (si *SynInspect) Synapse() *Synapse {
+	if si.pj == nil || si.syi >= si.pj.Syns.Len() {
+		return nil
+	}
+	return si.pj.Syns.At(si.syi)
+}
+
+// Read reads the current values of the synapse into Vals
+func (si *SynInspect) Read() {
+	sy := si.Synapse()
+	if sy == nil {
+		return
+	}
+	for i := range si.Vals {
+		si.Vals[i].Val, _ = sy.VarByName(si.Vals[i].Var)
+	}
+}
+
+// Update reads the current values of the synapse into Vals and adds them
+// to the Trace, for given mode (e.g., Sleep) and cycle, if On
+func (si *SynInspect) Update(mode string, cyc int) {
+	if !si.On || si.Synapse() == nil {
+		return
+	}
+	si.Read()
+	dt := si.Trace
+	row := dt.Rows
+	dt.SetNumRows(row + 1)
+	dt.SetCellFloat("Step", row, float64(si.step))
+	dt.SetCellString("Mode", row, mode)
+	dt.SetCellFloat("Cycle", row, float64(cyc))
+	for _, v := range si.Vals {
+		dt.SetCellFloat(v.Var, row, float64(v.Val))
+	}
+	si.step++
+	if si.MaxRows > 0 {
+		TrimRows(dt, si.MaxRows)
+	}
+}
+
+// Reset resets the Trace
+func (si *SynInspect) Reset() {
+	if si.Trace != nil {
+		si.Trace.SetNumRows(0)
+	}
+	si.step = 0
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestSynInspect(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "SynInspectNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	net.InitWts()
+	pj := net.PrjnByName("InputToHidden")
+	if err := pj.SetSynVal("Cai", 1, 2, 0.25); err != nil {
+		t.Fatal(err)
+	}
+	si := &SynInspect{}
+	si.Defaults()
+	si.MaxRows = 3
+	if err := si.Set(net, "Input", 1, "Hidden", 2); err != nil {
+		t.Fatal(err)
+	}
+	if nm := si.Name(); nm != "Input[1] -> Hidden[2]" {
+		t.Errorf("name: %v\n", nm)
+	}
+	for _, v := range si.Vals {
+		if v.Var == "Cai" && v.Val != 0.25 {
+			t.Errorf("Cai value: %v, want 0.25\n", v.Val)
+		}
+	}
+	for cyc := 0; cyc < 5; cyc++ {
+		pj.SetSynVal("Cai", 1, 2, float32(cyc))
+		si.Update("Sleep", cyc)
+	}
+	dt := si.Trace
+	if dt.Rows != 3 {
+		t.Fatalf("trace rows: %v, want 3 (MaxRows)\n", dt.Rows)
+	}
+	if dt.CellFloat("Cai", 2) != 4 || dt.CellFloat("Step", 0) != 2 || dt.CellString("Mode", 0) != "Sleep" {
+		t.Errorf("trace: Cai %v Step %v Mode %v\n", dt.CellFloat("Cai", 2), dt.CellFloat("Step", 0), dt.CellString("Mode", 0))
+	}
+	si.On = false
+	si.Update("Sleep", 5)
+	if dt.Rows != 3 {
+		t.Errorf("updated while off\n")
+	}
+	if err := si.Set(net, "Hidden", 0, "Input", 0); err == nil {
+		t.Errorf("expected error for no synapse\n")
+	}
+	if err := si.Set(net, "Input", 0, "Hidden", 9); err == nil {
+		t.Errorf("expected error for a unit index out of range\n")
+	}
+}
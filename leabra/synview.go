@@ -0,0 +1,167 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// SynViewVars are the synapse variables that can be viewed per projection
+// in the NetView, as unit variables relative to the SynView unit of the
+// network: r.<var> on each unit of a layer is the value of the synapse that
+// the SynView unit receives from it (e.g., r.Wt are its received weights),
+// and s.<var> that of the synapse that it sends to it -- in particular the
+// Cai, Effwt and SRAvgDp that drive the sleep dynamics
+var SynViewVars = []string{"Wt", "LWt", "DWt", "Cai", "Effwt", "SRAvgDp"}
+
+// SynViewVarNames are the r. and s. unit variable names of the SynViewVars
+var SynViewVarNames []string
+
+// UnitViewVars are the variables available on the units of a Layer: the
+// NeuronVars and the SynViewVarNames
+var UnitViewVars []string
+
+func init() {
+	for _, pfx := range []string{"r.", "s."} {
+		for _, v := range SynViewVars {
+			SynViewVarNames = append(SynViewVarNames, pfx+v)
+		}
+	}
+	UnitViewVars = make([]string, 0, len(NeuronVars)+len(SynViewVarNames))
+	UnitViewVars = append(UnitViewVars, NeuronVars...)
+	UnitViewVars = append(UnitViewVars, SynViewVarNames...)
+}
+
+// IsSynViewVar returns true if given unit variable name is one of the r. or
+// s. synapse variables
+func IsSynViewVar(varNm string) bool {
+	return strings.HasPrefix(varNm, "r.") || strings.HasPrefix(varNm, "s.")
+}
+
+// SynView is the unit that the r. and s. synapse variables are viewed
+// relative to, e.g., the unit selected in the NetView -- see
+// Network.SetSynView
+type SynView struct {
+	Lay string `desc:"name of the layer of the unit -- none if empty"`
+	Idx int    `desc:"1D index of the unit in the layer"`
+}
+
+// synViewVar returns the synapse variable name of given r. or s. variable,
+// and whether it is an r. variable -- fun is the caller for the errors
+func synViewVar(fun, varNm string) (string, bool, error) {
+	recv := strings.HasPrefix(varNm, "r.")
+	if !recv && !strings.HasPrefix(varNm, "s.") {
+		return "", false, fmt.Errorf("Layer %v: variable: %v is not an r. or s. synapse variable", fun, varNm)
+	}
+	synNm := varNm[2:]
+	if _, ok := SynapseVarsMap[synNm]; !ok {
+		return "", false, fmt.Errorf("Layer %v: variable: %v not found in synapse", fun, synNm)
+	}
+	return synNm, recv, nil
+}
+
+// SynViewVals returns the values of given r. or s. synapse variable on each
+// unit of the layer, relative to the SynView unit of the network: from the
+// projection from the layer to the SynView unit (r.) or to the layer from
+// it (s.) -- 0 for units without such a synapse (or if there is no SynView
+// unit or projection)
+func (ly *Layer) SynViewVals(varNm string) ([]float32, error) {
+	vs := make([]float32, len(ly.Neurons))
+	synNm, recv, err := synViewVar("SynViewVals", varNm)
+	if err != nil {
+		return vs, err
+	}
+	if ly.SynView == nil || ly.SynView.Lay == "" {
+		return vs, nil
+	}
+	sv := ly.SynView
+	if recv {
+		for _, p := range ly.SndPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			if pj.Recv.Name() != sv.Lay {
+				continue
+			}
+			for si := range vs {
+				if v, err := pj.SynValTry(synNm, si, sv.Idx); err == nil {
+					vs[si] = v
+				}
+			}
+		}
+		return vs, nil
+	}
+	for _, p := range ly.RcvPrjns {
+		pj := p.(LeabraPrjn).AsLeabra()
+		if pj.Send.Name() != sv.Lay {
+			continue
+		}
+		for ri := range vs {
+			if v, err := pj.SynValTry(synNm, sv.Idx, ri); err == nil {
+				vs[ri] = v
+			}
+		}
+	}
+	return vs, nil
+}
+
+// SynViewVal returns the value of given r. or s. synapse variable on the
+// unit with given 1D index, as in SynViewVals, without computing it for
+// the other units
+func (ly *Layer) SynViewVal(varNm string, idx int) (float32, error) {
+	synNm, recv, err := synViewVar("SynViewVal", varNm)
+	if err != nil {
+		return 0, err
+	}
+	if ly.SynView == nil || ly.SynView.Lay == "" {
+		return 0, nil
+	}
+	sv := ly.SynView
+	var val float32
+	if recv {
+		for _, p := range ly.SndPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			if pj.Recv.Name() != sv.Lay {
+				continue
+			}
+			if v, err := pj.SynValTry(synNm, idx, sv.Idx); err == nil {
+				val = v
+			}
+		}
+		return val, nil
+	}
+	for _, p := range ly.RcvPrjns {
+		pj := p.(LeabraPrjn).AsLeabra()
+		if pj.Send.Name() != sv.Lay {
+			continue
+		}
+		if v, err := pj.SynValTry(synNm, sv.Idx, idx); err == nil {
+			val = v
+		}
+	}
+	return val, nil
+}
+
+// SetSynView sets the unit that the r. and s. synapse variables are viewed
+// relative to, on all the layers: the unit with given 1D index of layer
+// with given name -- an empty name clears it
+func (nt *Network) SetSynView(lay string, idx int) error {
+	if lay != "" {
+		ly, err := nt.LayerByNameTry(lay)
+		if err != nil {
+			return err
+		}
+		if nn := len(ly.(LeabraLayer).AsLeabra().Neurons); idx < 0 || idx >= nn {
+			err = fmt.Errorf("Network SetSynView: unit index: %v out of range for layer: %v, N = %v", idx, lay, nn)
+			log.Println(err)
+			return err
+		}
+	}
+	nt.SynView = SynView{Lay: lay, Idx: idx}
+	for _, l := range nt.Layers {
+		l.(LeabraLayer).AsLeabra().SynView = &nt.SynView
+	}
+	return nil
+}
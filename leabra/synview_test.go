@@ -0,0 +1,72 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestSynView(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "SynViewNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	net.InitWts()
+	pj := net.PrjnByName("InputToHidden")
+	sy := pj.Syns.At(0)
+	sy.Cai, sy.Effwt, sy.SRAvgDp = 0.3, 0.4, 0.5
+	for _, v := range []string{"Cai", "Effwt", "SRAvgDp"} {
+		if val, ok := sy.VarByName(v); !ok || val != map[string]float32{"Cai": 0.3, "Effwt": 0.4, "SRAvgDp": 0.5}[v] {
+			t.Errorf("synapse VarByName: %v = %v, %v\n", v, val, ok)
+		}
+	}
+	if err := pj.SetSynVal("Cai", 1, 2, 0.7); err != nil {
+		t.Fatal(err)
+	}
+	in := inLay.(*Layer)
+	hid := hidLay.(*Layer)
+	if vs := in.UnitVals("r.Cai"); vs[1] != 0 {
+		t.Errorf("r.Cai without a SynView unit: %v\n", vs)
+	}
+	if err := net.SetSynView("Hidden", 2); err != nil {
+		t.Fatal(err)
+	}
+	if vs := in.UnitVals("r.Cai"); vs[1] != 0.7 || vs[0] != 0 {
+		t.Errorf("r.Cai received by Hidden 2: %v\n", vs)
+	}
+	if v := in.UnitVal1D("r.Cai", 1); v != 0.7 {
+		t.Errorf("UnitVal1D r.Cai: %v\n", v)
+	}
+	if v, err := in.UnitValTry("r.Cai", []int{0, 1}); err != nil || v != 0.7 {
+		t.Errorf("UnitValTry r.Cai: %v %v\n", v, err)
+	}
+	if _, err := in.UnitVal1DTry("r.None", 1); err == nil {
+		t.Errorf("expected error for an unknown synapse variable\n")
+	}
+	if vs := hid.UnitVals("s.Cai"); vs[2] != 0 {
+		t.Errorf("s.Cai with Hidden SynView: %v\n", vs)
+	}
+	if err := net.SetSynView("Input", 1); err != nil {
+		t.Fatal(err)
+	}
+	if vs := hid.UnitVals("s.Cai"); vs[2] != 0.7 {
+		t.Errorf("s.Cai sent by Input 1: %v\n", vs)
+	}
+	if min, max, err := hid.VarRange("s.Cai"); err != nil || min != 0 || max != 0.7 {
+		t.Errorf("VarRange s.Cai: %v %v %v\n", min, max, err)
+	}
+	if _, err := hid.UnitValsTry("s.None"); err == nil {
+		t.Errorf("expected error for an unknown synapse variable\n")
+	}
+	if err := net.SetSynView("Hidden", 10); err == nil {
+		t.Errorf("expected error for a unit index out of range\n")
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/emer/etable/etable"
+	"github.com/goki/gi/gi"
+)
+
+// TestListItem is one item of a TestList, with optional per-item test
+// options that override those of the list
+type TestListItem struct {
+	Name  string `desc:"name of the item: its Name in the patterns"`
+	Noise string `json:",omitempty" desc:"noise added to the inputs of the item, as for env.NoisyEnv.SetString, e.g., BitFlip:0.05 -- the Noise of the list if empty"`
+	Cue   string `json:",omitempty" desc:"partial cue of the item, as for env.CueEnv.SetString, e.g., Input:0.5 -- the Cue of the list if empty"`
+}
+
+// TestList is a named set of test items, e.g., lure items or a subset of
+// the trained items, with test options (noise, partial cues) for the whole
+// list and optionally for each item
+type TestList struct {
+	Name  string         `desc:"name of the list, e.g., lure_items"`
+	Pats  string         `json:",omitempty" desc:"if set, file of patterns (with the same columns as the training patterns) that the items are taken from, e.g., novel lures -- relative to the test lists file -- otherwise the training patterns"`
+	Noise string         `json:",omitempty" desc:"noise added to the inputs of the items, as for env.NoisyEnv.SetString -- none if empty"`
+	Cue   string         `json:",omitempty" desc:"partial cue of the items, as for env.CueEnv.SetString -- full patterns if empty"`
+	Items []TestListItem `desc:"the items of the list, tested in order"`
+}
+
+// ItemNoise returns the noise spec of item with given name
+func (tl *TestList) ItemNoise(name string) string {
+	if it := tl.Item(name); it != nil && it.Noise != "" {
+		return it.Noise
+	}
+	return tl.Noise
+}
+
+// ItemCue returns the cue spec of item with given name
+func (tl *TestList) ItemCue(name string) string {
+	if it := tl.Item(name); it != nil && it.Cue != "" {
+		return it.Cue
+	}
+	return tl.Cue
+}
+
+// Item returns the item with given name, or nil if not in the list
+func (tl *TestList) Item(name string) *TestListItem {
+	for i := range tl.Items {
+		if tl.Items[i].Name == name {
+			return &tl.Items[i]
+		}
+	}
+	return nil
+}
+
+// View returns a view of the rows of given table (of patterns with their
+// names in nameCol) of the items of the list, in the order of the list --
+// an error if any of the items is not in the table
+func (tl *TestList) View(dt *etable.Table, nameCol string) (*etable.IdxView, error) {
+	nc := dt.ColByName(nameCol)
+	if nc == nil {
+		err := fmt.Errorf("TestList: %v: name column: %v not found", tl.Name, nameCol)
+		log.Println(err)
+		return nil, err
+	}
+	rows := make(map[string]int, dt.Rows)
+	for row := 0; row < dt.Rows; row++ {
+		rows[nc.StringVal1D(row)] = row
+	}
+	ix := etable.NewIdxView(dt)
+	ix.Idxs = make([]int, 0, len(tl.Items))
+	var missing []string
+	for _, it := range tl.Items {
+		row, has := rows[it.Name]
+		if !has {
+			missing = append(missing, it.Name)
+			continue
+		}
+		ix.Idxs = append(ix.Idxs, row)
+	}
+	if len(missing) > 0 {
+		err := fmt.Errorf("TestList: %v: items not found in the patterns: %v", tl.Name, strings.Join(missing, ", "))
+		log.Println(err)
+		return nil, err
+	}
+	return ix, nil
+}
+
+// TestLists are named lists of test items, loaded from a JSON file with an
+// array of the lists, e.g., [{"Name": "lure_items", "Pats": "lures.dat",
+// "Noise": "BitFlip:0.05", "Items": [{"Name": "lure1"}, {"Name": "lure2",
+// "Cue": "Input:0.5"}]}] -- the Pats of each list are opened by Open,
+// relative to the file.
+type TestLists struct {
+	Lists []*TestList              `desc:"the lists"`
+	Pats  map[string]*etable.Table `view:"-" json:"-" desc:"patterns opened for the Pats of the lists, by file name"`
+}
+
+// Open opens the lists from given JSON file, and the patterns of their Pats
+func (ts *TestLists) Open(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	var lists []*TestList
+	if err = json.Unmarshal(b, &lists); err != nil {
+		err = fmt.Errorf("TestLists: %v: %v", filename, err)
+		log.Println(err)
+		return err
+	}
+	pats := map[string]*etable.Table{}
+	for _, tl := range lists {
+		if tl.Name == "" {
+			err = fmt.Errorf("TestLists: %v: a list has no Name", filename)
+			log.Println(err)
+			return err
+		}
+		if tl.Pats == "" {
+			continue
+		}
+		if !filepath.IsAbs(tl.Pats) {
+			tl.Pats = filepath.Join(filepath.Dir(string(filename)), tl.Pats)
+		}
+		if _, has := pats[tl.Pats]; has {
+			continue
+		}
+		dt := &etable.Table{}
+		if err = dt.OpenCSV(gi.FileName(tl.Pats), etable.Tab); err != nil {
+			log.Println(err)
+			return err
+		}
+		pats[tl.Pats] = dt
+	}
+	ts.Lists = lists
+	ts.Pats = pats
+	return nil
+}
+
+// List returns the list with given name -- an error if not found
+func (ts *TestLists) List(name string) (*TestList, error) {
+	for _, tl := range ts.Lists {
+		if tl.Name == name {
+			return tl, nil
+		}
+	}
+	err := fmt.Errorf("TestLists: list: %v not found in: %v", name, strings.Join(ts.Names(), ", "))
+	log.Println(err)
+	return nil, err
+}
+
+// Names returns the names of the lists
+func (ts *TestLists) Names() []string {
+	nms := make([]string, len(ts.Lists))
+	for i, tl := range ts.Lists {
+		nms[i] = tl.Name
+	}
+	return nms
+}
+
+// View returns the view of the patterns of the items of given list: from
+// its Pats if set, else from given (training) patterns
+func (ts *TestLists) View(tl *TestList, pats *etable.Table, nameCol string) (*etable.IdxView, error) {
+	if tl.Pats != "" {
+		if dt, has := ts.Pats[tl.Pats]; has {
+			pats = dt
+		}
+	}
+	return tl.View(pats, nameCol)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+func TestTestLists(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testlists")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mkPats := func(pfx string) *etable.Table {
+		dt := &etable.Table{}
+		dt.SetFromSchema(etable.Schema{
+			{"Name", etensor.STRING, nil, nil},
+			{"Input", etensor.FLOAT32, []int{2, 2}, nil},
+		}, 4)
+		for row := 0; row < dt.Rows; row++ {
+			dt.SetCellString("Name", row, fmt.Sprintf("%v%d", pfx, row))
+		}
+		return dt
+	}
+	pats := mkPats("p")
+	if err := mkPats("lure").SaveCSV(gi.FileName(filepath.Join(dir, "lures.dat")), etable.Tab, true); err != nil {
+		t.Fatal(err)
+	}
+	js := `[{"Name": "lure_items", "Pats": "lures.dat", "Noise": "BitFlip:0.05",
+  "Items": [{"Name": "lure2"}, {"Name": "lure0", "Noise": "GaussNoise:0.1", "Cue": "Input:0.5"}]},
+ {"Name": "subset", "Items": [{"Name": "p3"}, {"Name": "p1"}]}]`
+	fnm := filepath.Join(dir, "lists.json")
+	if err := ioutil.WriteFile(fnm, []byte(js), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ts := &TestLists{}
+	if err := ts.Open(gi.FileName(fnm)); err != nil {
+		t.Fatal(err)
+	}
+	if nms := ts.Names(); len(nms) != 2 || nms[1] != "subset" {
+		t.Errorf("list names: %v\n", nms)
+	}
+	sub, err := ts.List("subset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ix, err := ts.View(sub, pats, "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ix.Len() != 2 || ix.Idxs[0] != 3 || ix.Idxs[1] != 1 {
+		t.Errorf("subset rows: %v, want [3 1]\n", ix.Idxs)
+	}
+	lures, _ := ts.List("lure_items")
+	ix, err = ts.View(lures, pats, "Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ix.Len() != 2 || ix.Table.CellString("Name", ix.Idxs[0]) != "lure2" {
+		t.Errorf("lure items not from their Pats: %v\n", ix.Idxs)
+	}
+	if lures.ItemNoise("lure2") != "BitFlip:0.05" || lures.ItemNoise("lure0") != "GaussNoise:0.1" {
+		t.Errorf("item noise: %v %v\n", lures.ItemNoise("lure2"), lures.ItemNoise("lure0"))
+	}
+	if lures.ItemCue("lure2") != "" || lures.ItemCue("lure0") != "Input:0.5" {
+		t.Errorf("item cue: %v %v\n", lures.ItemCue("lure2"), lures.ItemCue("lure0"))
+	}
+	if _, err := lures.View(pats, "Name"); err == nil {
+		t.Errorf("expected error for items not in the patterns\n")
+	}
+	if _, err := ts.List("none"); err == nil {
+		t.Errorf("expected error for an unknown list\n")
+	}
+}
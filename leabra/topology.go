@@ -0,0 +1,135 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/emer/emergent/emer"
+	"github.com/goki/gi/gi"
+)
+
+// SaveTopology saves the layer / projection graph of the network to given file,
+// in DOT (graphviz) format if the extension is .dot or .gv, and GraphML otherwise
+// (e.g., .graphml).  This is useful for visualizing and documenting architectures
+// outside of the NetView.
+func (nt *Network) SaveTopology(filename gi.FileName) error {
+	fp, err := os.Create(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(string(filename))) {
+	case ".dot", ".gv":
+		nt.WriteDOT(fp)
+	default:
+		nt.WriteGraphML(fp)
+	}
+	if err = fp.Close(); err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// topoPrjns calls given function on all the active receiving projections in the network
+func (nt *Network) topoPrjns(fun func(pj *Prjn)) {
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		for _, p := range *ly.RecvPrjns() {
+			if p.IsOff() {
+				continue
+			}
+			fun(p.(LeabraPrjn).AsLeabra())
+		}
+	}
+}
+
+// layShapeStr returns the layer shape as a string, e.g., 5x5
+func layShapeStr(ly emer.Layer) string {
+	shp := ly.Shape().Shp
+	strs := make([]string, len(shp))
+	for i, s := range shp {
+		strs[i] = fmt.Sprintf("%d", s)
+	}
+	return strings.Join(strs, "x")
+}
+
+// WriteDOT writes the layer / projection graph of the network in the DOT
+// (graphviz) format, with layers as nodes labeled with name, type and shape,
+// and projections as directed edges from sender to receiver labeled with the
+// pattern and WtScale.Abs / Rel values.  Back projections are drawn dashed
+// and Inhib projections are drawn with a dot arrowhead.
+func (nt *Network) WriteDOT(w io.Writer) {
+	fmt.Fprintf(w, "digraph %q {\n", nt.Nm)
+	fmt.Fprintf(w, "\trankdir=BT;\n\tnode [shape=box];\n")
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		fmt.Fprintf(w, "\t%q [label=\"%v\\n%v %v (%d)\"];\n", ly.Name(), ly.Name(), ly.Type(), layShapeStr(ly), ly.Shape().Len())
+	}
+	nt.topoPrjns(func(pj *Prjn) {
+		attr := ""
+		switch pj.Typ {
+		case emer.Back:
+			attr = ", style=dashed"
+		case emer.Inhib:
+			attr = ", arrowhead=dot"
+		}
+		fmt.Fprintf(w, "\t%q -> %q [label=\"%v\\nAbs: %v Rel: %v\"%v];\n", pj.Send.Name(), pj.Recv.Name(), pj.Pat.Name(), pj.WtScale.Abs, pj.WtScale.Rel, attr)
+	})
+	fmt.Fprintf(w, "}\n")
+}
+
+// WriteGraphML writes the layer / projection graph of the network in the GraphML
+// XML format, with layer nodes having name, type, shape and number of units data,
+// and projection edges having name, type, pattern, class and WtScale.Abs / Rel data.
+func (nt *Network) WriteGraphML(w io.Writer) {
+	esc := func(s string) string {
+		var sb strings.Builder
+		xml.EscapeText(&sb, []byte(s))
+		return sb.String()
+	}
+	fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(w, "<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\">\n")
+	keys := []struct{ id, dom, typ string }{
+		{"type", "all", "string"}, {"shape", "node", "string"}, {"n", "node", "int"},
+		{"class", "all", "string"}, {"pattern", "edge", "string"},
+		{"abs", "edge", "double"}, {"rel", "edge", "double"},
+	}
+	for _, k := range keys {
+		fmt.Fprintf(w, "\t<key id=\"%v\" for=\"%v\" attr.name=\"%v\" attr.type=\"%v\"/>\n", k.id, k.dom, k.id, k.typ)
+	}
+	fmt.Fprintf(w, "\t<graph id=\"%v\" edgedefault=\"directed\">\n", esc(nt.Nm))
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		fmt.Fprintf(w, "\t\t<node id=\"%v\">\n", esc(ly.Name()))
+		fmt.Fprintf(w, "\t\t\t<data key=\"type\">%v</data>\n", ly.Type())
+		fmt.Fprintf(w, "\t\t\t<data key=\"shape\">%v</data>\n", layShapeStr(ly))
+		fmt.Fprintf(w, "\t\t\t<data key=\"n\">%d</data>\n", ly.Shape().Len())
+		fmt.Fprintf(w, "\t\t\t<data key=\"class\">%v</data>\n", esc(strings.TrimSpace(ly.Class())))
+		fmt.Fprintf(w, "\t\t</node>\n")
+	}
+	nt.topoPrjns(func(pj *Prjn) {
+		fmt.Fprintf(w, "\t\t<edge id=\"%v\" source=\"%v\" target=\"%v\">\n", esc(pj.Name()), esc(pj.Send.Name()), esc(pj.Recv.Name()))
+		fmt.Fprintf(w, "\t\t\t<data key=\"type\">%v</data>\n", pj.Typ)
+		fmt.Fprintf(w, "\t\t\t<data key=\"class\">%v</data>\n", esc(strings.TrimSpace(pj.Class())))
+		fmt.Fprintf(w, "\t\t\t<data key=\"pattern\">%v</data>\n", esc(pj.Pat.Name()))
+		fmt.Fprintf(w, "\t\t\t<data key=\"abs\">%v</data>\n", pj.WtScale.Abs)
+		fmt.Fprintf(w, "\t\t\t<data key=\"rel\">%v</data>\n", pj.WtScale.Rel)
+		fmt.Fprintf(w, "\t\t</edge>\n")
+	})
+	fmt.Fprintf(w, "\t</graph>\n</graphml>\n")
+}
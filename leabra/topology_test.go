@@ -0,0 +1,79 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+	"github.com/goki/gi/gi"
+)
+
+func TestTopology(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "TopoNet")
+	inLay := net.AddLayer2D("Input", 2, 3, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 2, 2, emer.Hidden)
+	offLay := net.AddLayer2D("Off", 1, 1, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.ConnectLayers(hidLay, inLay, prjn.NewFull(), emer.Back)
+	net.ConnectLayers(hidLay, offLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	offLay.SetOff(true)
+
+	var b bytes.Buffer
+	net.WriteDOT(&b)
+	dot := b.String()
+	for _, want := range []string{`digraph "TopoNet"`, `"Input" [label="Input\nInput 2x3 (6)"]`, `"Input" -> "Hidden"`, `"Hidden" -> "Input"`, "style=dashed"} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT missing: %v in:\n%v\n", want, dot)
+		}
+	}
+	if strings.Contains(dot, `"Off"`) {
+		t.Errorf("DOT has the off layer:\n%v\n", dot)
+	}
+
+	dir, err := ioutil.TempDir("", "topology")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fnm := filepath.Join(dir, "net.graphml")
+	if err := net.SaveTopology(gi.FileName(fnm)); err != nil {
+		t.Fatal(err)
+	}
+	gb, err := ioutil.ReadFile(fnm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gml struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(gb, &gml); err != nil {
+		t.Fatalf("GraphML not valid XML: %v\n", err)
+	}
+	if len(gml.Graph.Nodes) != 2 || len(gml.Graph.Edges) != 2 {
+		t.Errorf("GraphML nodes: %v edges: %v, want 2 2\n", len(gml.Graph.Nodes), len(gml.Graph.Edges))
+	}
+	if err := net.SaveTopology(gi.FileName(filepath.Join(dir, "nodir", "net.dot"))); err == nil {
+		t.Errorf("expected error for a file that cannot be created\n")
+	}
+}
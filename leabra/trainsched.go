@@ -0,0 +1,272 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goki/ki/kit"
+)
+
+// SchedModes are the modes of ordering the training items of a TrainSched
+type SchedModes int32
+
+//go:generate stringer -type=SchedModes
+
+var KiT_SchedModes = kit.Enums.AddEnum(SchedModesN, false, nil)
+
+func (ev SchedModes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *SchedModes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// The schedule modes
+const (
+	// Interleaved trains all the items in a random order each epoch
+	Interleaved SchedModes = iota
+
+	// Blocked trains the items of one group (block) at a time, each for
+	// BlockEpcs epochs, in the order of the Blocks
+	Blocked
+
+	// Weighted samples the group of each trial with a probability given by
+	// the Weights, and an item of the group at random
+	Weighted
+
+	SchedModesN
+)
+
+// TrainSched orders the training items of each epoch in a blocked,
+// interleaved, or weighted (custom-probability) schedule of the groups of
+// items, e.g., categories, with the items of some groups introduced only
+// from a given epoch on (see StartEpc) -- key manipulations in studies of
+// catastrophic interference and consolidation.  The order of each epoch
+// is given by Order, as indexes of the items in Groups.
+type TrainSched struct {
+	Mode      SchedModes         `desc:"mode of ordering the items"`
+	Groups    []string           `inactive:"+" desc:"group of each item, e.g., its category -- see SetGroups"`
+	Blocks    []string           `desc:"in Blocked mode, the order of the groups trained -- all the groups in sorted order if empty"`
+	BlockEpcs int                `desc:"in Blocked mode, the number of epochs each block is trained for"`
+	Cycle     bool               `desc:"in Blocked mode, cycle back to the first block after the last, instead of staying on the last"`
+	Weights   map[string]float64 `desc:"in Weighted mode, the relative probability of each group being sampled on each trial -- groups not listed have weight 1"`
+	StartEpc  map[string]int     `desc:"first epoch at which the items of each group are trained, e.g., for new items only after epoch N -- groups not listed start at 0"`
+}
+
+func (ts *TrainSched) Defaults() {
+	ts.Mode = Interleaved
+	ts.BlockEpcs = 10
+}
+
+// On returns true if the schedule differs from the plain interleaved
+// training of all the items
+func (ts *TrainSched) On() bool {
+	return ts.Mode != Interleaved || len(ts.StartEpc) > 0
+}
+
+// SetGroups sets the group of each item
+func (ts *TrainSched) SetGroups(groups []string) {
+	ts.Groups = groups
+}
+
+// GroupNames returns the names of all the groups, sorted
+func (ts *TrainSched) GroupNames() []string {
+	has := map[string]bool{}
+	var nms []string
+	for _, gp := range ts.Groups {
+		if !has[gp] {
+			has[gp] = true
+			nms = append(nms, gp)
+		}
+	}
+	sort.Strings(nms)
+	return nms
+}
+
+// Block returns the group trained at given epoch in Blocked mode
+func (ts *TrainSched) Block(epc int) string {
+	blks := ts.Blocks
+	if len(blks) == 0 {
+		blks = ts.GroupNames()
+	}
+	if len(blks) == 0 {
+		return ""
+	}
+	bepc := ts.BlockEpcs
+	if bepc < 1 {
+		bepc = 1
+	}
+	bi := epc / bepc
+	if ts.Cycle {
+		bi %= len(blks)
+	} else if bi >= len(blks) {
+		bi = len(blks) - 1
+	}
+	return blks[bi]
+}
+
+// Started returns true if the items of given group are trained at given epoch
+func (ts *TrainSched) Started(group string, epc int) bool {
+	st, has := ts.StartEpc[group]
+	return !has || epc >= st
+}
+
+// Order returns the order of the items trained at given epoch, as indexes
+// of the items in Groups, using given random number stream (global
+// generator if nil)
+func (ts *TrainSched) Order(epc int, rnd *rand.Rand) []int {
+	perm := rand.Perm
+	flt := rand.Float64
+	intn := rand.Intn
+	if rnd != nil {
+		perm = rnd.Perm
+		flt = rnd.Float64
+		intn = rnd.Intn
+	}
+	blk := ""
+	if ts.Mode == Blocked {
+		blk = ts.Block(epc)
+	}
+	var items []int
+	for i, gp := range ts.Groups {
+		if !ts.Started(gp, epc) || (ts.Mode == Blocked && gp != blk) {
+			continue
+		}
+		items = append(items, i)
+	}
+	if ts.Mode != Weighted {
+		ord := make([]int, len(items))
+		for i, pi := range perm(len(items)) {
+			ord[i] = items[pi]
+		}
+		return ord
+	}
+	gitems := map[string][]int{}
+	for _, i := range items {
+		gitems[ts.Groups[i]] = append(gitems[ts.Groups[i]], i)
+	}
+	var gps []string
+	var cum []float64
+	sum := 0.0
+	for _, gp := range ts.GroupNames() {
+		wt, has := ts.Weights[gp]
+		if !has {
+			wt = 1
+		}
+		if len(gitems[gp]) == 0 || wt <= 0 {
+			continue
+		}
+		sum += wt
+		gps = append(gps, gp)
+		cum = append(cum, sum)
+	}
+	if len(gps) == 0 {
+		return nil
+	}
+	ord := make([]int, len(items))
+	for t := range ord {
+		r := flt() * sum
+		gi := sort.SearchFloat64s(cum, r)
+		if gi >= len(gps) {
+			gi = len(gps) - 1
+		}
+		gis := gitems[gps[gi]]
+		ord[t] = gis[intn(len(gis))]
+	}
+	return ord
+}
+
+// SetString sets the Mode, and the Blocks or Weights, from a spec as
+// Interleaved, Blocked[:Group,Group..] or Weighted:Group=Wt,Group=Wt..,
+// e.g., "Blocked:Neg,Pos,Neutral" or "Weighted:Neg=2,Pos=1" (case
+// insensitive modes)
+func (ts *TrainSched) SetString(spec string) error {
+	ci := strings.Index(spec, ":")
+	mnm, args := spec, ""
+	if ci >= 0 {
+		mnm, args = spec[:ci], spec[ci+1:]
+	}
+	mnm = strings.ToLower(strings.TrimSpace(mnm))
+	var err error
+	switch mnm {
+	case "interleaved":
+		ts.Mode = Interleaved
+	case "blocked":
+		ts.Mode = Blocked
+		ts.Blocks = nil
+		for _, gp := range strings.Split(args, ",") {
+			if gp = strings.TrimSpace(gp); gp != "" {
+				ts.Blocks = append(ts.Blocks, gp)
+			}
+		}
+	case "weighted":
+		ts.Mode = Weighted
+		ts.Weights, err = parseGroupWts(args)
+	default:
+		err = fmt.Errorf("mode must be Interleaved, Blocked or Weighted")
+	}
+	if err != nil {
+		err = fmt.Errorf("TrainSched: %v in: %v", err, spec)
+		log.Println(err)
+	}
+	return err
+}
+
+// SetStart sets the StartEpc from specs separated by , each as
+// Group=Epoch, e.g., "Neutral=20"
+func (ts *TrainSched) SetStart(specs string) error {
+	st, err := parseGroupVals(specs)
+	if err != nil {
+		err = fmt.Errorf("TrainSched: %v in: %v", err, specs)
+		log.Println(err)
+		return err
+	}
+	ts.StartEpc = st
+	return nil
+}
+
+// parseGroupVals parses specs separated by , each as Group=int
+func parseGroupVals(specs string) (map[string]int, error) {
+	vals := map[string]int{}
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		ei := strings.Index(spec, "=")
+		if ei < 0 {
+			return nil, fmt.Errorf("expected Group=Val")
+		}
+		v, err := strconv.Atoi(strings.TrimSpace(spec[ei+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer Val")
+		}
+		vals[strings.TrimSpace(spec[:ei])] = v
+	}
+	return vals, nil
+}
+
+// parseGroupWts parses specs separated by , each as Group=float
+func parseGroupWts(specs string) (map[string]float64, error) {
+	wts := map[string]float64{}
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		ei := strings.Index(spec, "=")
+		if ei < 0 {
+			return nil, fmt.Errorf("expected Group=Weight")
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(spec[ei+1:]), 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("expected a Weight >= 0")
+		}
+		wts[strings.TrimSpace(spec[:ei])] = v
+	}
+	return wts, nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestTrainSched(t *testing.T) {
+	ts := &TrainSched{}
+	ts.Defaults()
+	ts.SetGroups([]string{"A", "A", "B", "B", "B", "C"})
+	rnd := rand.New(rand.NewSource(1))
+	if ord := ts.Order(0, rnd); len(ord) != 6 || ts.On() {
+		t.Errorf("interleaved order: %v\n", ord)
+	}
+	if err := ts.SetString("Blocked:B,A"); err != nil {
+		t.Fatal(err)
+	}
+	ts.BlockEpcs = 2
+	for epc, want := range []string{"B", "B", "A", "A", "A"} {
+		ord := ts.Order(epc, rnd)
+		for _, i := range ord {
+			if ts.Groups[i] != want {
+				t.Errorf("epoch %d: item of group %v in block %v\n", epc, ts.Groups[i], want)
+			}
+		}
+		if (want == "B" && len(ord) != 3) || (want == "A" && len(ord) != 2) {
+			t.Errorf("epoch %d: %d items\n", epc, len(ord))
+		}
+	}
+	ts.Cycle = true
+	if blk := ts.Block(4); blk != "B" {
+		t.Errorf("cycled block: %v, want B\n", blk)
+	}
+
+	if err := ts.SetString("weighted: A=3, B=0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.SetStart("C=5"); err != nil {
+		t.Fatal(err)
+	}
+	nA := 0
+	for epc := 0; epc < 100; epc++ {
+		ord := ts.Order(epc%10, rnd)
+		if epc%10 < 5 && len(ord) != 5 {
+			t.Fatalf("epoch %d: %d items, want 5 before C starts\n", epc%10, len(ord))
+		}
+		for _, i := range ord {
+			switch ts.Groups[i] {
+			case "A":
+				nA++
+			case "B":
+				t.Fatalf("sampled group B with weight 0\n")
+			case "C":
+				if epc%10 < 5 {
+					t.Fatalf("sampled group C before its start epoch\n")
+				}
+			}
+		}
+	}
+	if nA < 400 || nA > 560 {
+		t.Errorf("group A sampled %d times of 550, expected about 475\n", nA)
+	}
+	if ts.SetString("random") == nil || ts.SetStart("C=x") == nil {
+		t.Errorf("expected errors for bad specs\n")
+	}
+}
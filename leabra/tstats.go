@@ -0,0 +1,259 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"math"
+)
+
+// MeanSD returns the mean and the sample standard deviation (N-1) of vals
+func MeanSD(vals []float64) (mean, sd float64) {
+	n := float64(len(vals))
+	if n == 0 {
+		return math.NaN(), math.NaN()
+	}
+	for _, v := range vals {
+		mean += v
+	}
+	mean /= n
+	if n < 2 {
+		return mean, math.NaN()
+	}
+	for _, v := range vals {
+		sd += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sd / (n - 1))
+}
+
+// TCI returns the lower and upper bounds of the confidence interval of the
+// mean of vals with given confidence level (e.g., .95), from the t
+// distribution -- NaN if there are fewer than 2 values
+func TCI(vals []float64, level float64) (lo, hi float64) {
+	mean, sd := MeanSD(vals)
+	n := float64(len(vals))
+	if n < 2 {
+		return math.NaN(), math.NaN()
+	}
+	hw := TQuantile(0.5+level/2, n-1) * sd / math.Sqrt(n)
+	return mean - hw, mean + hw
+}
+
+// WelchTTest returns the t statistic, degrees of freedom, and two-sided p
+// value of Welch's unequal-variance t-test of the difference between the
+// means of a and b (mean(a) - mean(b))
+func WelchTTest(a, b []float64) (t, df, p float64) {
+	ma, sa := MeanSD(a)
+	mb, sb := MeanSD(b)
+	na, nb := float64(len(a)), float64(len(b))
+	if na < 2 || nb < 2 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	va, vb := sa*sa/na, sb*sb/nb
+	se := math.Sqrt(va + vb)
+	if se == 0 {
+		if ma == mb {
+			return 0, na + nb - 2, 1
+		}
+		return math.Inf(int(math.Copysign(1, ma-mb))), na + nb - 2, 0
+	}
+	t = (ma - mb) / se
+	df = (va + vb) * (va + vb) / (va*va/(na-1) + vb*vb/(nb-1))
+	return t, df, TTwoSidedP(t, df)
+}
+
+// PairedTTest returns the t statistic, degrees of freedom, and two-sided p
+// value of the paired t-test of the mean difference between a and b
+// (a[i] - b[i]), which must have the same length, e.g., runs of two
+// conditions with the same random seeds
+func PairedTTest(a, b []float64) (t, df, p float64) {
+	n := len(a)
+	if n != len(b) || n < 2 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	d := make([]float64, n)
+	for i := range d {
+		d[i] = a[i] - b[i]
+	}
+	md, sd := MeanSD(d)
+	df = float64(n - 1)
+	if sd == 0 {
+		if md == 0 {
+			return 0, df, 1
+		}
+		return math.Inf(int(math.Copysign(1, md))), df, 0
+	}
+	t = md / (sd / math.Sqrt(float64(n)))
+	return t, df, TTwoSidedP(t, df)
+}
+
+// OneWayANOVA returns the F statistic, the between- and within-groups
+// degrees of freedom, and the p value of a one-way analysis of variance of
+// the differences among the means of the groups -- empty groups are ignored
+func OneWayANOVA(groups [][]float64) (f, df1, df2, p float64) {
+	k, n := 0, 0
+	gm := 0.0
+	for _, g := range groups {
+		if len(g) == 0 {
+			continue
+		}
+		k++
+		n += len(g)
+		for _, v := range g {
+			gm += v
+		}
+	}
+	if k < 2 || n <= k {
+		return math.NaN(), math.NaN(), math.NaN(), math.NaN()
+	}
+	gm /= float64(n)
+	ssb, ssw := 0.0, 0.0
+	for _, g := range groups {
+		if len(g) == 0 {
+			continue
+		}
+		m, _ := MeanSD(g)
+		ssb += float64(len(g)) * (m - gm) * (m - gm)
+		for _, v := range g {
+			ssw += (v - m) * (v - m)
+		}
+	}
+	df1, df2 = float64(k-1), float64(n-k)
+	if ssw == 0 {
+		if ssb == 0 {
+			return 0, df1, df2, 1
+		}
+		return math.Inf(1), df1, df2, 0
+	}
+	f = (ssb / df1) / (ssw / df2)
+	return f, df1, df2, FUpperP(f, df1, df2)
+}
+
+// FUpperP returns the upper tail probability of the F distribution with
+// df1 and df2 degrees of freedom at f, i.e., the p value of an F test
+func FUpperP(f, df1, df2 float64) float64 {
+	if math.IsNaN(f) {
+		return math.NaN()
+	}
+	if f <= 0 {
+		return 1
+	}
+	if math.IsInf(f, 1) {
+		return 0
+	}
+	return RegIncBeta(df2/2, df1/2, df2/(df2+df1*f))
+}
+
+// TTwoSidedP returns the two-sided p value of t with df degrees of freedom
+func TTwoSidedP(t, df float64) float64 {
+	if math.IsNaN(t) {
+		return math.NaN()
+	}
+	if math.IsInf(t, 0) {
+		return 0
+	}
+	return RegIncBeta(df/2, 0.5, df/(df+t*t))
+}
+
+// TCDF returns the cumulative distribution function of Student's t
+// distribution with df degrees of freedom at t
+func TCDF(t, df float64) float64 {
+	p := 0.5 * TTwoSidedP(t, df)
+	if t < 0 {
+		return p
+	}
+	return 1 - p
+}
+
+// TQuantile returns the t value at which the cumulative distribution of
+// Student's t with df degrees of freedom is p (0 < p < 1), by bisection
+func TQuantile(p, df float64) float64 {
+	if p <= 0 || p >= 1 || df <= 0 {
+		return math.NaN()
+	}
+	lo, hi := -1.0, 1.0
+	for TCDF(lo, df) > p {
+		lo *= 2
+	}
+	for TCDF(hi, df) < p {
+		hi *= 2
+	}
+	for i := 0; i < 100; i++ {
+		mid := 0.5 * (lo + hi)
+		if TCDF(mid, df) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return 0.5 * (lo + hi)
+}
+
+// RegIncBeta returns the regularized incomplete beta function I_x(a, b),
+// computed with a continued fraction (Numerical Recipes betacf)
+func RegIncBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	bt := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return bt * betaCF(a, b, x) / a
+	}
+	return 1 - bt*betaCF(b, a, 1-x)/b
+}
+
+// betaCF evaluates the continued fraction for RegIncBeta by the modified
+// Lentz method
+func betaCF(a, b, x float64) float64 {
+	const (
+		maxIt = 300
+		eps   = 1.0e-14
+		tiny  = 1.0e-300
+	)
+	qab, qap, qam := a+b, a+1, a-1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIt; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < eps {
+			break
+		}
+	}
+	return h
+}
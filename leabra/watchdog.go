@@ -0,0 +1,229 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// WatchProblem is one value found by the Watchdog to be NaN, Inf, or
+// exploding (beyond its max)
+type WatchProblem struct {
+	Obj  string  `desc:"name of the layer or projection"`
+	Prjn bool    `desc:"Obj is a projection -- else a layer"`
+	Var  string  `desc:"name of the neuron or synapse variable"`
+	Idx  int     `desc:"index of the neuron or synapse"`
+	Val  float32 `desc:"the value"`
+	Kind string  `desc:"NaN, Inf or Exploding"`
+}
+
+// String returns the problem as Obj Var[Idx] = Val (Kind)
+func (wp *WatchProblem) String() string {
+	return fmt.Sprintf("%v %v[%d] = %g (%v)", wp.Obj, wp.Var, wp.Idx, wp.Val, wp.Kind)
+}
+
+// Watchdog checks the network for numerical instability: NaN or Inf values
+// of the neuron variables NeurVars and the synaptic weights, or values whose
+// magnitude has exploded beyond MaxAct or MaxWt, e.g., when exploring extreme
+// inhibitory oscillation parameters during sleep.  Check is called e.g., at
+// the end of every quarter, and if it finds any Problems, Dump appends the
+// state of the offending layers and projections, with any recent param
+// changes, to a diagnostics file.
+type Watchdog struct {
+	On          bool           `desc:"if true, the network is checked"`
+	NeurVars    []string       `desc:"neuron variables that are checked"`
+	MaxAct      float32        `desc:"neuron variable values with a magnitude above this are exploding -- 0 to only check for NaN and Inf"`
+	MaxWt       float32        `desc:"synaptic weights (Wt, LWt) with a magnitude above this are exploding -- 0 to only check for NaN and Inf"`
+	MaxProblems int            `desc:"maximum number of Problems recorded by Check -- all are counted in NProblems"`
+	MaxDumps    int            `desc:"maximum number of times Dump writes to the file, to bound its size if e.g., many sleep trials are unstable"`
+	Problems    []WatchProblem `inactive:"+" desc:"problems found by the last Check, up to MaxProblems"`
+	NProblems   int            `inactive:"+" desc:"total number of problems found by the last Check"`
+	NDumps      int            `inactive:"+" desc:"number of times Dump has written to the file"`
+}
+
+func (wd *Watchdog) Defaults() {
+	wd.NeurVars = []string{"Act", "Ge", "Gi", "Vm"}
+	wd.MaxAct = 100
+	wd.MaxWt = 10
+	wd.MaxProblems = 20
+	wd.MaxDumps = 10
+}
+
+// add records a problem with given value, if it is one
+func (wd *Watchdog) add(obj string, prjn bool, vr string, idx int, val, max float32) {
+	kind := ""
+	switch {
+	case math.IsNaN(float64(val)):
+		kind = "NaN"
+	case math.IsInf(float64(val), 0):
+		kind = "Inf"
+	case max > 0 && (val > max || val < -max):
+		kind = "Exploding"
+	default:
+		return
+	}
+	wd.NProblems++
+	if len(wd.Problems) < wd.MaxProblems {
+		wd.Problems = append(wd.Problems, WatchProblem{Obj: obj, Prjn: prjn, Var: vr, Idx: idx, Val: val, Kind: kind})
+	}
+}
+
+// Check checks all the layers and projections of the network that are not
+// off, returning true if any problems were found (see Problems)
+func (wd *Watchdog) Check(nt *Network) bool {
+	wd.Problems = wd.Problems[:0]
+	wd.NProblems = 0
+	for _, l := range nt.Layers {
+		if l.IsOff() {
+			continue
+		}
+		ly := l.(LeabraLayer).AsLeabra()
+		for _, vnm := range wd.NeurVars {
+			vidx, err := NeuronVarByName(vnm)
+			if err != nil {
+				continue
+			}
+			for ni := range ly.Neurons {
+				nrn := &ly.Neurons[ni]
+				if nrn.IsOff() {
+					continue
+				}
+				wd.add(ly.Nm, false, vnm, ni, nrn.VarByIndex(vidx), wd.MaxAct)
+			}
+		}
+		for _, p := range ly.RcvPrjns {
+			if p.IsOff() {
+				continue
+			}
+			pj := p.(LeabraPrjn).AsLeabra()
+			si := 0
+			for _, syns := range pj.Syns.Chunks {
+				for ci := range syns {
+					sy := &syns[ci]
+					wd.add(pj.Name(), true, "Wt", si, sy.Wt, wd.MaxWt)
+					wd.add(pj.Name(), true, "LWt", si, sy.LWt, wd.MaxWt)
+					si++
+				}
+			}
+		}
+	}
+	return wd.NProblems > 0
+}
+
+// Dump appends the Problems found by the last Check to the diagnostics file
+// with given name, with given context (e.g., the run, epoch and trial
+// counters), the params and the values of all the neuron variables of each
+// offending layer, the params and weight stats of each offending projection,
+// and given recent param changes -- does nothing after MaxDumps
+func (wd *Watchdog) Dump(nt *Network, filename string, ctxt string, changes []string) error {
+	if wd.MaxDumps > 0 && wd.NDumps >= wd.MaxDumps {
+		return nil
+	}
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer f.Close()
+	wd.NDumps++
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "==== Watchdog: %v: %v\n", time.Now().Format(time.RFC3339), ctxt)
+	fmt.Fprintf(w, "%d problems, first %d:\n", wd.NProblems, len(wd.Problems))
+	done := make(map[string]bool)
+	var objs []WatchProblem
+	for i := range wd.Problems {
+		wp := &wd.Problems[i]
+		fmt.Fprintf(w, "\t%v\n", wp.String())
+		if !done[wp.Obj] {
+			done[wp.Obj] = true
+			objs = append(objs, *wp)
+		}
+	}
+	for _, wp := range objs {
+		if wp.Prjn {
+			wd.dumpPrjn(w, nt, wp.Obj)
+		} else {
+			wd.dumpLayer(w, nt, wp.Obj)
+		}
+	}
+	fmt.Fprintf(w, "\nRecent param changes:\n")
+	for _, ch := range changes {
+		fmt.Fprintf(w, "\t%v\n", ch)
+	}
+	fmt.Fprintf(w, "\n")
+	if err := w.Flush(); err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// dumpLayer writes the params and the values of all the neuron variables of
+// the layer with given name
+func (wd *Watchdog) dumpLayer(w *bufio.Writer, nt *Network, name string) {
+	l, err := nt.LayerByNameTry(name)
+	if err != nil {
+		return
+	}
+	ly := l.(LeabraLayer).AsLeabra()
+	fmt.Fprintf(w, "\nLayer: %v\n%v\n", ly.Nm, ly.AllParams())
+	fmt.Fprintf(w, "Unit\t%v\n", strings.Join(NeuronVars, "\t"))
+	for ni := range ly.Neurons {
+		nrn := &ly.Neurons[ni]
+		vals := make([]string, len(NeuronVars))
+		for vi := range NeuronVars {
+			vals[vi] = fmt.Sprintf("%g", nrn.VarByIndex(vi))
+		}
+		fmt.Fprintf(w, "%d\t%v\n", ni, strings.Join(vals, "\t"))
+	}
+}
+
+// dumpPrjn writes the params and the stats of the weights of the projection
+// with given name
+func (wd *Watchdog) dumpPrjn(w *bufio.Writer, nt *Network, name string) {
+	for _, l := range nt.Layers {
+		for _, p := range l.(LeabraLayer).AsLeabra().RcvPrjns {
+			pj := p.(LeabraPrjn).AsLeabra()
+			if pj.Name() != name {
+				continue
+			}
+			fmt.Fprintf(w, "\nPrjn: %v\n%v\n", name, pj.AllParams())
+			fmt.Fprintf(w, "Var\tMin\tMax\tMean\tNaN\tInf\n")
+			for _, vnm := range []string{"Wt", "LWt", "DWt"} {
+				min, max, sum := math.Inf(1), math.Inf(-1), 0.0
+				nnan, ninf, n := 0, 0, 0
+				for _, syns := range pj.Syns.Chunks {
+					for si := range syns {
+						v, _ := syns[si].VarByName(vnm)
+						fv := float64(v)
+						switch {
+						case math.IsNaN(fv):
+							nnan++
+						case math.IsInf(fv, 0):
+							ninf++
+						default:
+							min = math.Min(min, fv)
+							max = math.Max(max, fv)
+							sum += fv
+							n++
+						}
+					}
+				}
+				mean := math.NaN()
+				if n > 0 {
+					mean = sum / float64(n)
+				}
+				fmt.Fprintf(w, "%v\t%g\t%g\t%g\t%d\t%d\n", vnm, min, max, mean, nnan, ninf)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/emer/emergent/emer"
+	"github.com/emer/emergent/prjn"
+)
+
+func TestWatchdog(t *testing.T) {
+	net := &Network{}
+	net.InitName(net, "WatchNet")
+	inLay := net.AddLayer2D("Input", 2, 2, emer.Input)
+	hidLay := net.AddLayer2D("Hidden", 3, 2, emer.Hidden)
+	net.ConnectLayers(inLay, hidLay, prjn.NewFull(), emer.Forward)
+	net.Defaults()
+	net.Build()
+	net.InitWts()
+
+	wd := &Watchdog{}
+	wd.Defaults()
+	if wd.Check(net) {
+		t.Fatalf("problems in a fresh network: %v\n", wd.Problems)
+	}
+	hid := hidLay.(*Layer)
+	hid.Neurons[4].Act = float32(math.NaN())
+	hid.Neurons[5].Ge = 1000
+	pj := hid.RcvPrjns[0].(LeabraPrjn).AsLeabra()
+	pj.Syns.At(2).Wt = float32(math.Inf(1))
+	if !wd.Check(net) || wd.NProblems != 3 {
+		t.Fatalf("problems: %v, want 3\n", wd.Problems)
+	}
+	if s := wd.Problems[0].String(); s != "Hidden Act[4] = NaN (NaN)" {
+		t.Errorf("problem: %v\n", s)
+	}
+	if wp := wd.Problems[2]; !wp.Prjn || wp.Var != "Wt" || wp.Idx != 2 || wp.Kind != "Inf" {
+		t.Errorf("prjn problem wrong: %+v\n", wp)
+	}
+
+	dir, err := ioutil.TempDir("", "watchdog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fnm := filepath.Join(dir, "watchdog.txt")
+	wd.MaxDumps = 1
+	if err := wd.Dump(net, fnm, "sleep trial 3", []string{"Sleep Hidden Layer.Inhib.Layer.Gi: 1.8 -> 5"}); err != nil {
+		t.Fatal(err)
+	}
+	wd.Dump(net, fnm, "sleep trial 4", nil)
+	b, err := ioutil.ReadFile(fnm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(b)
+	for _, want := range []string{"sleep trial 3", "3 problems", "Layer: Hidden", "Prjn: " + pj.Name(), "Layer.Inhib.Layer.Gi: 1.8 -> 5"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("dump does not contain: %v\n", want)
+		}
+	}
+	if strings.Contains(s, "sleep trial 4") {
+		t.Errorf("dump written after MaxDumps\n")
+	}
+}
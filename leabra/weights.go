@@ -0,0 +1,358 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode"
+)
+
+// NetWts holds the weights for a network, as read from a weights file
+// written by WriteWtsJSON.
+type NetWts struct {
+	Network string   `desc:"name of network"`
+	Layers  []LayWts `desc:"weights for each layer"`
+}
+
+// LayWts holds the weights for the receiving projections of a layer
+type LayWts struct {
+	Layer string    `desc:"name of layer"`
+	Prjns []PrjnWts `desc:"weights for each receiving projection"`
+}
+
+// PrjnWts holds the weights for one projection, from the receiver's perspective
+type PrjnWts struct {
+	From   string    `desc:"name of the sending layer"`
+	GScale float32   `desc:"conductance scaling factor"`
+	Rs     []RecvWts `desc:"weights for each receiving unit"`
+}
+
+// RecvWts holds the weights for one receiving unit, with the indexes of the
+// sending units and the corresponding weights
+type RecvWts struct {
+	Ri int       `desc:"receiving unit index"`
+	Si []int     `desc:"sending unit indexes"`
+	Wt []float32 `desc:"weight values, one-to-one with Si"`
+}
+
+// LayerByName returns the weights for given layer name, nil if not found
+func (nw *NetWts) LayerByName(name string) *LayWts {
+	for li := range nw.Layers {
+		if nw.Layers[li].Layer == name {
+			return &nw.Layers[li]
+		}
+	}
+	return nil
+}
+
+// PrjnFrom returns the weights for projection from given sending layer, nil if not found
+func (lw *LayWts) PrjnFrom(send string) *PrjnWts {
+	for pi := range lw.Prjns {
+		if lw.Prjns[pi].From == send {
+			return &lw.Prjns[pi]
+		}
+	}
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//  Parsing
+
+// The weights format written by WriteWtsJSON is JSON-like but not strictly valid
+// JSON: it has trailing commas (older files have missing commas), and arrays contain
+// keyed "ri": { } entries.  Thus we use a lenient parser that ignores commas
+// entirely, and treats objects and arrays uniformly as ordered lists of
+// optionally-keyed values.
+
+// wtsNode is a generic node in the parsed weights file
+type wtsNode struct {
+	key   string
+	str   string
+	num   float64
+	isNum bool
+	kids  []*wtsNode
+}
+
+// child returns the child with given key, nil if not found
+func (wn *wtsNode) child(key string) *wtsNode {
+	for _, k := range wn.kids {
+		if k.key == key {
+			return k
+		}
+	}
+	return nil
+}
+
+// f32 returns the number value as a float32, parsed directly from the
+// original string so that float32 values round-trip exactly
+func (wn *wtsNode) f32() float32 {
+	f, err := strconv.ParseFloat(wn.str, 32)
+	if err != nil {
+		return float32(wn.num)
+	}
+	return float32(f)
+}
+
+// wtsParser is a lenient parser for the weights file format
+type wtsParser struct {
+	rd      *bufio.Reader
+	line    int
+	pend    bool
+	pendTok string
+	pendQ   bool
+}
+
+// unread pushes back one token, to be returned by the next call to next
+func (wp *wtsParser) unread(tok string, quoted bool) {
+	wp.pend = true
+	wp.pendTok = tok
+	wp.pendQ = quoted
+}
+
+func (wp *wtsParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("leabra weights file parse error at line %d: %v", wp.line+1, fmt.Sprintf(format, args...))
+}
+
+// next returns the next token: one of { } [ ] : or a string (quoted=true) or a bare word
+func (wp *wtsParser) next() (tok string, quoted bool, err error) {
+	if wp.pend {
+		wp.pend = false
+		return wp.pendTok, wp.pendQ, nil
+	}
+	for {
+		r, _, err := wp.rd.ReadRune()
+		if err != nil {
+			return "", false, err
+		}
+		if r == '\n' {
+			wp.line++
+		}
+		if unicode.IsSpace(r) || r == ',' {
+			continue
+		}
+		switch r {
+		case '{', '}', '[', ']', ':':
+			return string(r), false, nil
+		case '"':
+			rs := []rune{}
+			for {
+				r, _, err = wp.rd.ReadRune()
+				if err != nil {
+					return "", false, wp.errorf("unterminated string")
+				}
+				if r == '"' {
+					return string(rs), true, nil
+				}
+				rs = append(rs, r)
+			}
+		}
+		rs := []rune{r}
+		for {
+			r, _, err = wp.rd.ReadRune()
+			if err != nil {
+				return string(rs), false, nil
+			}
+			if unicode.IsSpace(r) || r == ',' || r == '{' || r == '}' || r == '[' || r == ']' || r == ':' || r == '"' {
+				wp.rd.UnreadRune()
+				return string(rs), false, nil
+			}
+			rs = append(rs, r)
+		}
+	}
+}
+
+// value parses a value given its first token
+func (wp *wtsParser) value(tok string, quoted bool) (*wtsNode, error) {
+	wn := &wtsNode{}
+	if quoted {
+		wn.str = tok
+		return wn, nil
+	}
+	switch tok {
+	case "{", "[":
+		end := "}"
+		if tok == "[" {
+			end = "]"
+		}
+		for {
+			t, q, err := wp.next()
+			if err != nil {
+				return nil, wp.errorf("unexpected end of file, expecting: %v", end)
+			}
+			if !q && (t == "}" || t == "]") {
+				if t != end {
+					return nil, wp.errorf("mismatched: %v, expecting: %v", t, end)
+				}
+				return wn, nil
+			}
+			key := ""
+			if q { // could be a key
+				nt, nq, err := wp.next()
+				if err != nil {
+					return nil, wp.errorf("unexpected end of file")
+				}
+				if !nq && nt == ":" {
+					key = t
+					t, q, err = wp.next()
+					if err != nil {
+						return nil, wp.errorf("unexpected end of file after key: %v", key)
+					}
+				} else {
+					wp.unread(nt, nq) // just a string value
+				}
+			}
+			kn, err := wp.value(t, q)
+			if err != nil {
+				return nil, err
+			}
+			kn.key = key
+			wn.kids = append(wn.kids, kn)
+		}
+	case "}", "]", ":":
+		return nil, wp.errorf("unexpected: %v", tok)
+	}
+	num, err := strconv.ParseFloat(tok, 64)
+	if err != nil {
+		return nil, wp.errorf("invalid number: %v", tok)
+	}
+	wn.num = num
+	wn.str = tok
+	wn.isNum = true
+	return wn, nil
+}
+
+// parseWts parses the full contents of given reader
+func parseWts(r io.Reader) (*wtsNode, error) {
+	wp := &wtsParser{rd: bufio.NewReader(r)}
+	tok, q, err := wp.next()
+	if err != nil {
+		return nil, wp.errorf("empty weights file")
+	}
+	return wp.value(tok, q)
+}
+
+// keyedKid returns the single keyed child of given node that is not one of the
+// given other keys -- this is used for the name-keyed elements in the format
+func (wn *wtsNode) keyedKid(others ...string) *wtsNode {
+	for _, k := range wn.kids {
+		if k.key == "" {
+			continue
+		}
+		skip := false
+		for _, o := range others {
+			if k.key == o {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			return k
+		}
+	}
+	return nil
+}
+
+// netWtsFmNode converts the top-level node into NetWts
+func netWtsFmNode(wn *wtsNode) (*NetWts, error) {
+	nn := wn.keyedKid()
+	if nn == nil {
+		return nil, fmt.Errorf("leabra weights file: no network found")
+	}
+	nw := &NetWts{Network: nn.key}
+	for _, ln := range nn.kids {
+		lw, err := layWtsFmNode(ln)
+		if err != nil {
+			return nil, err
+		}
+		nw.Layers = append(nw.Layers, *lw)
+	}
+	return nw, nil
+}
+
+// layWtsFmNode converts a layer-level node into LayWts
+func layWtsFmNode(wn *wtsNode) (*LayWts, error) {
+	ln := wn.keyedKid()
+	if ln == nil {
+		return nil, fmt.Errorf("leabra weights file: layer name not found")
+	}
+	lw := &LayWts{Layer: ln.key}
+	for _, pn := range ln.kids {
+		pw, err := prjnWtsFmNode(pn)
+		if err != nil {
+			return nil, fmt.Errorf("leabra weights file: layer: %v: %v", lw.Layer, err)
+		}
+		lw.Prjns = append(lw.Prjns, *pw)
+	}
+	return lw, nil
+}
+
+// prjnWtsFmNode converts a prjn-level node into PrjnWts
+func prjnWtsFmNode(wn *wtsNode) (*PrjnWts, error) {
+	pw := &PrjnWts{GScale: 1}
+	if gs := wn.child("GScale"); gs != nil {
+		pw.GScale = gs.f32()
+	} else if gs := wn.child("GeScale"); gs != nil { // older name
+		pw.GScale = gs.f32()
+	}
+	sn := wn.keyedKid("GScale", "GeScale")
+	if sn == nil {
+		return nil, fmt.Errorf("sending layer name not found")
+	}
+	pw.From = sn.key
+	for _, rn := range sn.kids {
+		ri, err := strconv.Atoi(rn.key)
+		if err != nil {
+			return nil, fmt.Errorf("prjn from: %v invalid recv index: %v", pw.From, rn.key)
+		}
+		rw := RecvWts{Ri: ri}
+		if sis := rn.child("Si"); sis != nil {
+			for _, s := range sis.kids {
+				rw.Si = append(rw.Si, int(s.num))
+			}
+		}
+		if wts := rn.child("Wt"); wts != nil {
+			for _, w := range wts.kids {
+				rw.Wt = append(rw.Wt, w.f32())
+			}
+		}
+		if len(rw.Si) != len(rw.Wt) {
+			return nil, fmt.Errorf("prjn from: %v recv index: %v number of Si: %v != Wt: %v", pw.From, ri, len(rw.Si), len(rw.Wt))
+		}
+		pw.Rs = append(pw.Rs, rw)
+	}
+	return pw, nil
+}
+
+// ReadNetWtsJSON reads network weights from the format written by
+// Network.WriteWtsJSON.  Both the current and older (GeScale, missing
+// commas) variants of the format are supported.
+func ReadNetWtsJSON(r io.Reader) (*NetWts, error) {
+	wn, err := parseWts(r)
+	if err != nil {
+		return nil, err
+	}
+	return netWtsFmNode(wn)
+}
+
+// ReadLayWtsJSON reads layer weights from the format written by Layer.WriteWtsJSON
+func ReadLayWtsJSON(r io.Reader) (*LayWts, error) {
+	wn, err := parseWts(r)
+	if err != nil {
+		return nil, err
+	}
+	return layWtsFmNode(wn)
+}
+
+// ReadPrjnWtsJSON reads projection weights from the format written by Prjn.WriteWtsJSON
+func ReadPrjnWtsJSON(r io.Reader) (*PrjnWts, error) {
+	wn, err := parseWts(r)
+	if err != nil {
+		return nil, err
+	}
+	return prjnWtsFmNode(wn)
+}
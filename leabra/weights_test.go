@@ -0,0 +1,83 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOpenWtsJSON(t *testing.T) {
+	TestNet.InitWts()
+	hidLay := TestNet.LayerByName("Hidden").(*Layer)
+	fmIn := hidLay.RcvPrjns.SendName("Input").(*Prjn)
+	fmIn.SetSynVal("Wt", 1, 1, .15)
+	err := TestNet.OpenWtsJSON("testdata/testnet_good.wts")
+	if err != nil {
+		t.Error(err)
+	}
+	if wt := fmIn.SynVal("Wt", 1, 1); wt != 0.5 {
+		t.Errorf("loaded wt: %v != 0.5\n", wt)
+	}
+	if fmIn.GScale != 0.8333333 {
+		t.Errorf("loaded GScale: %v != 0.8333333\n", fmIn.GScale)
+	}
+}
+
+func TestWtsRoundTrip(t *testing.T) {
+	TestNet.InitWts()
+	hidLay := TestNet.LayerByName("Hidden").(*Layer)
+	outLay := TestNet.LayerByName("Output").(*Layer)
+	fmIn := hidLay.RcvPrjns.SendName("Input").(*Prjn)
+	fmHid := outLay.RcvPrjns.SendName("Hidden").(*Prjn)
+	for i := 0; i < 4; i++ {
+		fmIn.SetSynVal("Wt", i, i, 0.1+0.2*float32(i))
+		fmHid.SetSynVal("Wt", i, i, 0.9-0.2*float32(i))
+	}
+	var nb, lb bytes.Buffer
+	TestNet.WriteWtsJSON(&nb)
+	hidLay.WriteWtsJSON(&lb, 0)
+
+	TestNet.InitWts()
+	if err := TestNet.ReadWtsJSON(&nb); err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 4; i++ {
+		if wt := fmIn.SynVal("Wt", i, i); wt != 0.1+0.2*float32(i) {
+			t.Errorf("network read: hidden wt %v: %v != %v\n", i, wt, 0.1+0.2*float32(i))
+		}
+		if wt := fmHid.SynVal("Wt", i, i); wt != 0.9-0.2*float32(i) {
+			t.Errorf("network read: output wt %v: %v != %v\n", i, wt, 0.9-0.2*float32(i))
+		}
+	}
+
+	TestNet.InitWts()
+	if err := hidLay.ReadWtsJSON(&lb); err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 4; i++ {
+		if wt := fmIn.SynVal("Wt", i, i); wt != 0.1+0.2*float32(i) {
+			t.Errorf("layer read: hidden wt %v: %v != %v\n", i, wt, 0.1+0.2*float32(i))
+		}
+	}
+}
+
+func TestWtsBinRoundTrip(t *testing.T) {
+	TestNet.InitWts()
+	hidLay := TestNet.LayerByName("Hidden").(*Layer)
+	fmIn := hidLay.RcvPrjns.SendName("Input").(*Prjn)
+	fmIn.SetSynVal("Wt", 2, 2, .25)
+	var b bytes.Buffer
+	if err := TestNet.WriteWtsBin(&b); err != nil {
+		t.Error(err)
+	}
+	TestNet.InitWts()
+	if err := TestNet.ReadWtsBin(&b); err != nil {
+		t.Error(err)
+	}
+	if wt := fmIn.SynVal("Wt", 2, 2); wt != .25 {
+		t.Errorf("binary read wt: %v != .25\n", wt)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+)
+
+// WtsBinVersion is the current version of the binary weights format
+// written by WriteWtsBin -- increment when the format changes, and
+// handle older versions in ReadWtsBin.
+const WtsBinVersion = 1
+
+// WtsBinMagic identifies a binary weights file
+const WtsBinMagic = "leabra-wtsb"
+
+// WtsBinExt is the file extension for binary weights files, used by
+// SaveWts and OpenWts to select the format.  All other extensions use
+// the JSON format (.wts), which remains the interchange format.
+const WtsBinExt = ".wtsb"
+
+// wtsBinHeader is written at the start of a binary weights file
+type wtsBinHeader struct {
+	Magic   string
+	Version int
+}
+
+// Wts returns the weights for this projection, in the same receiver-based
+// form as written by WriteWtsJSON.
+func (pj *Prjn) Wts() PrjnWts {
+	slay := pj.Send.(LeabraLayer).AsLeabra()
+	nr := len(pj.RConN)
+	pw := PrjnWts{From: slay.Nm, GScale: pj.GScale, Rs: make([]RecvWts, nr)}
+	for ri := 0; ri < nr; ri++ {
+		nc := int(pj.RConN[ri])
+		st := int(pj.RConIdxSt[ri])
+		rw := &pw.Rs[ri]
+		rw.Ri = ri
+		rw.Si = make([]int, nc)
+		rw.Wt = make([]float32, nc)
+		for ci := 0; ci < nc; ci++ {
+			rw.Si[ci] = int(pj.RConIdx[st+ci])
+			rw.Wt[ci] = pj.Syns.At(pj.RSynIdx[st+ci]).Wt
+		}
+	}
+	return pw
+}
+
+// Wts returns the weights for all the receiving projections of this layer
+func (ly *Layer) Wts() LayWts {
+	lw := LayWts{Layer: ly.Nm}
+	for _, p := range ly.RcvPrjns {
+		if p.IsOff() {
+			continue
+		}
+		lw.Prjns = append(lw.Prjns, p.(LeabraPrjn).AsLeabra().Wts())
+	}
+	return lw
+}
+
+// Wts returns the weights for all the layers in the network
+func (nt *NetworkStru) Wts() *NetWts {
+	nw := &NetWts{Network: nt.Nm}
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		nw.Layers = append(nw.Layers, ly.(LeabraLayer).AsLeabra().Wts())
+	}
+	return nw
+}
+
+// WriteWtsBin writes the network weights in a compact, versioned binary (gob) format,
+// which is much smaller and faster to save and load than the JSON format.
+func (nt *NetworkStru) WriteWtsBin(w io.Writer) error {
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(&wtsBinHeader{Magic: WtsBinMagic, Version: WtsBinVersion}); err != nil {
+		log.Println(err)
+		return err
+	}
+	err := enc.Encode(nt.Wts())
+	if err != nil {
+		log.Println(err)
+	}
+	return err
+}
+
+// ReadWtsBin reads network weights in the binary format written by WriteWtsBin
+func (nt *NetworkStru) ReadWtsBin(r io.Reader) error {
+	dec := gob.NewDecoder(r)
+	hdr := wtsBinHeader{}
+	if err := dec.Decode(&hdr); err != nil {
+		err = fmt.Errorf("NetworkStru.ReadWtsBin: not a binary weights file: %v", err)
+		log.Println(err)
+		return err
+	}
+	if hdr.Magic != WtsBinMagic {
+		err := fmt.Errorf("NetworkStru.ReadWtsBin: not a binary weights file, magic: %v", hdr.Magic)
+		log.Println(err)
+		return err
+	}
+	if hdr.Version > WtsBinVersion {
+		err := fmt.Errorf("NetworkStru.ReadWtsBin: binary weights file version: %v is newer than supported version: %v", hdr.Version, WtsBinVersion)
+		log.Println(err)
+		return err
+	}
+	nw := &NetWts{}
+	if err := dec.Decode(nw); err != nil {
+		log.Println(err)
+		return err
+	}
+	return nt.SetWts(nw)
+}
+
+// SaveWtsBin saves network weights to a binary-formatted file -- see WriteWtsBin
+func (nt *NetworkStru) SaveWtsBin(filename gi.FileName) error {
+	fp, err := os.Create(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer fp.Close()
+	return nt.WriteWtsBin(fp)
+}
+
+// OpenWtsBin opens network weights from a binary-formatted file -- see ReadWtsBin
+func (nt *NetworkStru) OpenWtsBin(filename gi.FileName) error {
+	fp, err := os.Open(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	defer fp.Close()
+	return nt.ReadWtsBin(fp)
+}
+
+// IsWtsBinFile returns true if the given filename has the binary weights
+// extension (WtsBinExt)
+func IsWtsBinFile(filename gi.FileName) bool {
+	return strings.ToLower(filepath.Ext(string(filename))) == WtsBinExt
+}
+
+// SaveWts saves network weights, using the binary format if the filename
+// has the .wtsb extension, and the JSON format otherwise.
+func (nt *NetworkStru) SaveWts(filename gi.FileName) error {
+	if IsWtsBinFile(filename) {
+		return nt.SaveWtsBin(filename)
+	}
+	return nt.SaveWtsJSON(filename)
+}
+
+// OpenWts opens network weights, using the binary format if the filename
+// has the .wtsb extension, and the JSON format otherwise.
+func (nt *NetworkStru) OpenWts(filename gi.FileName) error {
+	if IsWtsBinFile(filename) {
+		return nt.OpenWtsBin(filename)
+	}
+	return nt.OpenWtsJSON(filename)
+}
@@ -0,0 +1,245 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+	"github.com/goki/gi/gi"
+)
+
+// WtsMapEntry records what happened when loading the weights for one
+// projection from a weights file into a network with a possibly different
+// architecture.
+type WtsMapEntry struct {
+	Layer   string `desc:"name of receiving layer in the weights file"`
+	From    string `desc:"name of sending layer in the weights file"`
+	Prjn    string `desc:"name of projection in the network that was loaded into, empty if skipped"`
+	Status  string `desc:"Loaded if all weights were loaded, Partial if sizes differ, or the reason for skipping"`
+	Loaded  int    `desc:"number of synapses whose weights were loaded"`
+	Skipped int    `desc:"number of weights in the file that had no corresponding synapse in the network (truncated)"`
+	Padded  int    `desc:"number of synapses in the network that had no weight in the file, and retain their initial values (padded)"`
+}
+
+// WtsMapReport is a detailed report of what was loaded by Network.SetWtsMapped
+type WtsMapReport struct {
+	Entries []WtsMapEntry `desc:"one entry per projection in the weights file, plus any network projections not present in the file"`
+}
+
+// Add adds a new entry to the report
+func (wr *WtsMapReport) Add(ent WtsMapEntry) {
+	wr.Entries = append(wr.Entries, ent)
+}
+
+// Totals returns the total number of synapses loaded, skipped and padded
+func (wr *WtsMapReport) Totals() (loaded, skipped, padded int) {
+	for i := range wr.Entries {
+		ent := &wr.Entries[i]
+		loaded += ent.Loaded
+		skipped += ent.Skipped
+		padded += ent.Padded
+	}
+	return
+}
+
+// String returns a human-readable version of the report
+func (wr *WtsMapReport) String() string {
+	var b strings.Builder
+	for i := range wr.Entries {
+		ent := &wr.Entries[i]
+		b.WriteString(fmt.Sprintf("%v <- %v\t%v\t%v", ent.Layer, ent.From, ent.Prjn, ent.Status))
+		if ent.Loaded > 0 || ent.Skipped > 0 || ent.Padded > 0 {
+			b.WriteString(fmt.Sprintf("\tloaded: %v  skipped: %v  padded: %v", ent.Loaded, ent.Skipped, ent.Padded))
+		}
+		b.WriteString("\n")
+	}
+	ld, sk, pd := wr.Totals()
+	b.WriteString(fmt.Sprintf("Total loaded: %v  skipped: %v  padded: %v\n", ld, sk, pd))
+	return b.String()
+}
+
+// Table returns the report as an etable.Table, with one row per entry
+func (wr *WtsMapReport) Table() *etable.Table {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Layer", etensor.STRING, nil, nil},
+		{"From", etensor.STRING, nil, nil},
+		{"Prjn", etensor.STRING, nil, nil},
+		{"Status", etensor.STRING, nil, nil},
+		{"Loaded", etensor.INT64, nil, nil},
+		{"Skipped", etensor.INT64, nil, nil},
+		{"Padded", etensor.INT64, nil, nil},
+	}, len(wr.Entries))
+	for i := range wr.Entries {
+		ent := &wr.Entries[i]
+		dt.SetCellString("Layer", i, ent.Layer)
+		dt.SetCellString("From", i, ent.From)
+		dt.SetCellString("Prjn", i, ent.Prjn)
+		dt.SetCellString("Status", i, ent.Status)
+		dt.SetCellFloat("Loaded", i, float64(ent.Loaded))
+		dt.SetCellFloat("Skipped", i, float64(ent.Skipped))
+		dt.SetCellFloat("Padded", i, float64(ent.Padded))
+	}
+	return dt
+}
+
+// mapWtsName returns the name in the network for given name in the weights
+// file, according to the map -- names not in the map are used as-is, and
+// names mapped to "" are skipped (returns false).
+func mapWtsName(lmap map[string]string, nm string) (string, bool) {
+	if lmap == nil {
+		return nm, true
+	}
+	mnm, has := lmap[nm]
+	if !has {
+		return nm, true
+	}
+	return mnm, mnm != ""
+}
+
+// SetWtsMapped sets the weights in this network from given weights (e.g., from
+// ReadNetWtsJSON), which can come from a network with a different architecture.
+// lmap maps layer names in the weights to layer names in this network (applies to
+// both receiving and sending layers) -- names not in the map are used as-is, and
+// names mapped to "" are skipped.  Layers and projections not present in the network
+// are skipped, and if layer sizes differ, weights for units beyond the network's
+// sizes are skipped (truncated), and synapses without weights in the file keep their
+// current values (padded), so InitWts should generally be called first.
+// Returns a detailed report of what was loaded.
+func (nt *Network) SetWtsMapped(nw *NetWts, lmap map[string]string) *WtsMapReport {
+	rep := &WtsMapReport{}
+	done := make(map[*Prjn]bool)
+	for li := range nw.Layers {
+		lw := &nw.Layers[li]
+		lnm, ok := mapWtsName(lmap, lw.Layer)
+		if !ok {
+			for pi := range lw.Prjns {
+				rep.Add(WtsMapEntry{Layer: lw.Layer, From: lw.Prjns[pi].From, Status: "Skipped: layer mapped to empty name"})
+			}
+			continue
+		}
+		ly := nt.LayerByName(lnm)
+		if ly == nil {
+			for pi := range lw.Prjns {
+				rep.Add(WtsMapEntry{Layer: lw.Layer, From: lw.Prjns[pi].From, Status: fmt.Sprintf("Skipped: layer %v not found", lnm)})
+			}
+			continue
+		}
+		for pi := range lw.Prjns {
+			pw := &lw.Prjns[pi]
+			ent := WtsMapEntry{Layer: lw.Layer, From: pw.From}
+			snm, ok := mapWtsName(lmap, pw.From)
+			if !ok {
+				ent.Status = "Skipped: sending layer mapped to empty name"
+				rep.Add(ent)
+				continue
+			}
+			p := ly.RecvPrjns().SendName(snm)
+			if p == nil {
+				ent.Status = fmt.Sprintf("Skipped: no projection from %v to %v", snm, lnm)
+				rep.Add(ent)
+				continue
+			}
+			pj := p.(LeabraPrjn).AsLeabra()
+			ent.Prjn = lnm + "." + pj.Name()
+			ent.Loaded, ent.Skipped, ent.Padded = pj.SetWtsMapped(pw)
+			switch {
+			case ent.Loaded == 0:
+				ent.Status = "Skipped: no matching synapses"
+			case ent.Skipped > 0 || ent.Padded > 0:
+				ent.Status = "Partial"
+			default:
+				ent.Status = "Loaded"
+			}
+			done[pj] = true
+			rep.Add(ent)
+		}
+	}
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		for _, p := range *ly.RecvPrjns() {
+			pj := p.(LeabraPrjn).AsLeabra()
+			if p.IsOff() || done[pj] {
+				continue
+			}
+			rep.Add(WtsMapEntry{Prjn: ly.Name() + "." + pj.Name(), Status: "Missing: not in weights", Padded: int(pj.Syns.Len())})
+		}
+	}
+	nt.SyncToDevice()
+	return rep
+}
+
+// OpenWtsJSONMapped opens weights from a JSON-formatted file, as saved by
+// SaveWtsJSON, and loads them using SetWtsMapped, which allows the network
+// to have a different architecture than the one that saved the weights --
+// see SetWtsMapped for details.  Returns a detailed report of what was loaded.
+func (nt *Network) OpenWtsJSONMapped(filename gi.FileName, lmap map[string]string) (*WtsMapReport, error) {
+	fp, err := os.Open(string(filename))
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	defer fp.Close()
+	nw, err := ReadNetWtsJSON(fp)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+	return nt.SetWtsMapped(nw, lmap), nil
+}
+
+// SetWtsMapped sets the weights for this projection from given weights,
+// which can come from a projection with different layer sizes.  Weights for
+// units that are not present (out of range or not connected) are skipped, and
+// any synapses that do not receive a weight keep their current values.
+// Returns the number of weights loaded, skipped, and synapses padded.
+func (pj *Prjn) SetWtsMapped(pw *PrjnWts) (loaded, skipped, padded int) {
+	nr := len(pj.RConN)
+	set := make([]bool, pj.Syns.Len())
+	for i := range pw.Rs {
+		rw := &pw.Rs[i]
+		if rw.Ri < 0 || rw.Ri >= nr {
+			skipped += len(rw.Si)
+			continue
+		}
+		nc := int(pj.RConN[rw.Ri])
+		st := int(pj.RConIdxSt[rw.Ri])
+		for j, si := range rw.Si {
+			rsi := int64(-1)
+			for ci := 0; ci < nc; ci++ {
+				if int(pj.RConIdx[st+ci]) == si {
+					rsi = pj.RSynIdx[st+ci]
+					break
+				}
+			}
+			if rsi < 0 {
+				skipped++
+				continue
+			}
+			pj.SetSynWt(pj.Syns.At(rsi), rw.Wt[j])
+			if !set[rsi] {
+				set[rsi] = true
+				loaded++
+			}
+		}
+	}
+	padded = int(pj.Syns.Len()) - loaded
+	return
+}
+
+// SetSynWt sets the weight of given synapse to given value, updating the
+// linear weight and effective weight accordingly.
+func (pj *Prjn) SetSynWt(sy *Synapse, wt float32) {
+	sy.Wt = wt
+	pj.Learn.LWtFmWt(sy)
+	sy.Effwt = sy.Wt
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2019, The Emergent Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package leabra
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/emer/etable/etable"
+	"github.com/emer/etable/etensor"
+)
+
+// WtStatsVars are the synaptic weight variables for which WtStats are computed
+var WtStatsVars = []string{"Wt", "LWt", "Effwt"}
+
+// WtStatsBins is the default number of histogram bins used in WtStats
+var WtStatsBins = 20
+
+// WtStatsTol is the default tolerance for counting a weight as being at
+// its lower (0) or upper (1) bound in WtStats
+var WtStatsTol = float32(0.01)
+
+// WtDist contains summary statistics for the distribution of one synaptic
+// weight variable, for detecting saturated (many weights at the bounds) or
+// collapsed (very low SD) weight distributions.  Wt and Effwt values are
+// divided by the synapse Scale for the bounds and histogram, so that they
+// are always in the 0..1 range, but the other stats are computed on the raw values.
+type WtDist struct {
+	Var    string  `desc:"name of the variable"`
+	N      int     `desc:"number of synapses"`
+	Mean   float32 `desc:"mean value"`
+	SD     float32 `desc:"standard deviation"`
+	Skew   float32 `desc:"skewness: third standardized moment -- positive = long tail toward higher values"`
+	Min    float32 `desc:"minimum value"`
+	Max    float32 `desc:"maximum value"`
+	FracLo float32 `desc:"fraction of synapses within tolerance of the lower bound of 0"`
+	FracHi float32 `desc:"fraction of synapses within tolerance of the upper bound of 1"`
+	Hist   []int   `desc:"histogram counts of (scale-normalized) values over equal-sized bins from 0 to 1 -- values outside that range are counted in the first or last bin"`
+}
+
+// String returns a one-line summary of the stats
+func (wd *WtDist) String() string {
+	return fmt.Sprintf("%v: N: %v  Mean: %g  SD: %g  Skew: %g  Min: %g  Max: %g  FracLo: %g  FracHi: %g", wd.Var, wd.N, wd.Mean, wd.SD, wd.Skew, wd.Min, wd.Max, wd.FracLo, wd.FracHi)
+}
+
+// wtDistAcc accumulates values for computing a WtDist, which can be
+// merged across projections
+type wtDistAcc struct {
+	n, lo, hi  int
+	s1, s2, s3 float64
+	min, max   float32
+	hist       []int
+}
+
+func (wa *wtDistAcc) init(nbins int) {
+	*wa = wtDistAcc{min: math.MaxFloat32, max: -math.MaxFloat32, hist: make([]int, nbins)}
+}
+
+// add adds a raw value v, with scale-normalized value nv
+func (wa *wtDistAcc) add(v, nv, tol float32) {
+	wa.n++
+	fv := float64(v)
+	wa.s1 += fv
+	wa.s2 += fv * fv
+	wa.s3 += fv * fv * fv
+	if v < wa.min {
+		wa.min = v
+	}
+	if v > wa.max {
+		wa.max = v
+	}
+	if nv <= tol {
+		wa.lo++
+	}
+	if nv >= 1-tol {
+		wa.hi++
+	}
+	nb := len(wa.hist)
+	bi := int(nv * float32(nb))
+	if bi < 0 {
+		bi = 0
+	} else if bi >= nb {
+		bi = nb - 1
+	}
+	wa.hist[bi]++
+}
+
+// merge adds the values from the other accumulator into this one
+func (wa *wtDistAcc) merge(oa *wtDistAcc) {
+	wa.n += oa.n
+	wa.lo += oa.lo
+	wa.hi += oa.hi
+	wa.s1 += oa.s1
+	wa.s2 += oa.s2
+	wa.s3 += oa.s3
+	if oa.min < wa.min {
+		wa.min = oa.min
+	}
+	if oa.max > wa.max {
+		wa.max = oa.max
+	}
+	for i := range wa.hist {
+		wa.hist[i] += oa.hist[i]
+	}
+}
+
+// dist returns the final stats
+func (wa *wtDistAcc) dist(varNm string) WtDist {
+	wd := WtDist{Var: varNm, N: wa.n, Hist: wa.hist}
+	if wa.n == 0 {
+		return wd
+	}
+	n := float64(wa.n)
+	mean := wa.s1 / n
+	m2 := wa.s2/n - mean*mean
+	if m2 < 0 {
+		m2 = 0
+	}
+	m3 := wa.s3/n - 3*mean*wa.s2/n + 2*mean*mean*mean
+	wd.Mean = float32(mean)
+	wd.SD = float32(math.Sqrt(m2))
+	if m2 > 1.0e-12 {
+		wd.Skew = float32(m3 / math.Pow(m2, 1.5))
+	}
+	wd.Min = wa.min
+	wd.Max = wa.max
+	wd.FracLo = float32(wa.lo) / float32(wa.n)
+	wd.FracHi = float32(wa.hi) / float32(wa.n)
+	return wd
+}
+
+// wtStatsVal returns the raw and scale-normalized value of given weight
+// variable for the synapse
+func wtStatsVal(sy *Synapse, varNm string) (v, nv float32) {
+	sc := sy.Scale
+	if sc == 0 {
+		sc = 1
+	}
+	switch varNm {
+	case "Wt":
+		return sy.Wt, sy.Wt / sc
+	case "LWt":
+		return sy.LWt, sy.LWt
+	case "Effwt":
+		return sy.Effwt, sy.Effwt / sc
+	}
+	return 0, 0
+}
+
+// accumWtStats accumulates stats for given variable into the accumulator
+func (pj *Prjn) accumWtStats(wa *wtDistAcc, varNm string, tol float32) {
+	for _, syns := range pj.Syns.Chunks {
+		for si := range syns {
+			v, nv := wtStatsVal(&syns[si], varNm)
+			wa.add(v, nv, tol)
+		}
+	}
+}
+
+// WtStatsVar returns the distribution statistics for given weight variable
+// (Wt, LWt, or Effwt), with given number of histogram bins, and tolerance
+// for counting values as being at the 0 or 1 bounds.
+func (pj *Prjn) WtStatsVar(varNm string, nbins int, tol float32) WtDist {
+	var wa wtDistAcc
+	wa.init(nbins)
+	pj.accumWtStats(&wa, varNm, tol)
+	return wa.dist(varNm)
+}
+
+// WtStats returns the distribution statistics for each of the WtStatsVars
+// (Wt, LWt, Effwt), using default WtStatsBins and WtStatsTol.
+func (pj *Prjn) WtStats() []WtDist {
+	wds := make([]WtDist, len(WtStatsVars))
+	for vi, vnm := range WtStatsVars {
+		wds[vi] = pj.WtStatsVar(vnm, WtStatsBins, WtStatsTol)
+	}
+	return wds
+}
+
+// WtStats returns a table of weight distribution statistics for each of the
+// WtStatsVars (Wt, LWt, Effwt), for each projection in the network (named
+// as Recv.Prjn), followed by rows for the whole network aggregated across
+// all projections (Prjn = "Network").  Uses default WtStatsBins and WtStatsTol.
+func (nt *Network) WtStats() *etable.Table {
+	dt := &etable.Table{}
+	dt.SetFromSchema(etable.Schema{
+		{"Prjn", etensor.STRING, nil, nil},
+		{"Var", etensor.STRING, nil, nil},
+		{"N", etensor.INT64, nil, nil},
+		{"Mean", etensor.FLOAT64, nil, nil},
+		{"SD", etensor.FLOAT64, nil, nil},
+		{"Skew", etensor.FLOAT64, nil, nil},
+		{"Min", etensor.FLOAT64, nil, nil},
+		{"Max", etensor.FLOAT64, nil, nil},
+		{"FracLo", etensor.FLOAT64, nil, nil},
+		{"FracHi", etensor.FLOAT64, nil, nil},
+		{"Hist", etensor.FLOAT64, []int{WtStatsBins}, []string{"Bin"}},
+	}, 0)
+	nv := len(WtStatsVars)
+	tot := make([]wtDistAcc, nv)
+	for vi := range tot {
+		tot[vi].init(WtStatsBins)
+	}
+	addRow := func(pnm string, wd *WtDist) {
+		row := dt.Rows
+		dt.SetNumRows(row + 1)
+		dt.SetCellString("Prjn", row, pnm)
+		dt.SetCellString("Var", row, wd.Var)
+		dt.SetCellFloat("N", row, float64(wd.N))
+		dt.SetCellFloat("Mean", row, float64(wd.Mean))
+		dt.SetCellFloat("SD", row, float64(wd.SD))
+		dt.SetCellFloat("Skew", row, float64(wd.Skew))
+		dt.SetCellFloat("Min", row, float64(wd.Min))
+		dt.SetCellFloat("Max", row, float64(wd.Max))
+		dt.SetCellFloat("FracLo", row, float64(wd.FracLo))
+		dt.SetCellFloat("FracHi", row, float64(wd.FracHi))
+		ht := dt.CellTensor("Hist", row)
+		for bi, c := range wd.Hist {
+			ht.SetFloat1D(bi, float64(c))
+		}
+	}
+	for _, ly := range nt.Layers {
+		if ly.IsOff() {
+			continue
+		}
+		for _, p := range *ly.RecvPrjns() {
+			if p.IsOff() {
+				continue
+			}
+			pj := p.(LeabraPrjn).AsLeabra()
+			pnm := ly.Name() + "." + pj.Name()
+			for vi, vnm := range WtStatsVars {
+				var wa wtDistAcc
+				wa.init(WtStatsBins)
+				pj.accumWtStats(&wa, vnm, WtStatsTol)
+				tot[vi].merge(&wa)
+				wd := wa.dist(vnm)
+				addRow(pnm, &wd)
+			}
+		}
+	}
+	for vi, vnm := range WtStatsVars {
+		wd := tot[vi].dist(vnm)
+		addRow("Network", &wd)
+	}
+	return dt
+}